@@ -0,0 +1,349 @@
+package accounts
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+
+	"connectrpc.com/connect"
+	"github.com/pkg/errors"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	"github.com/tochemey/cos-go-sample/gen/accounts/v1/accountsv1connect"
+)
+
+// Client is used by the internal Go callers of the accounts api service.
+// The responses are returned whole so that the callers get the revision metadata of the accounts and the standing orders.
+// The errors returned are gRPC status errors, whatever the protocol used on the wire.
+type Client interface {
+	OpenAccount(ctx context.Context, request *pb.OpenAccountRequest) (*pb.OpenAccountResponse, error)
+	DebitAccount(ctx context.Context, request *pb.DebitAccountRequest) (*pb.DebitAccountResponse, error)
+	CreditAccount(ctx context.Context, request *pb.CreditAccountRequest) (*pb.CreditAccountResponse, error)
+	GetAccount(ctx context.Context, request *pb.GetAccountRequest) (*pb.GetAccountResponse, error)
+	GetAccountAt(ctx context.Context, request *pb.GetAccountAtRequest) (*pb.GetAccountAtResponse, error)
+	WaiveFee(ctx context.Context, request *pb.WaiveFeeRequest) (*pb.WaiveFeeResponse, error)
+	CreateStandingOrder(ctx context.Context, request *pb.CreateStandingOrderRequest) (*pb.CreateStandingOrderResponse, error)
+	PauseStandingOrder(ctx context.Context, orderID string) (*pb.PauseStandingOrderResponse, error)
+	ResumeStandingOrder(ctx context.Context, orderID string) (*pb.ResumeStandingOrderResponse, error)
+	CancelStandingOrder(ctx context.Context, orderID string) (*pb.CancelStandingOrderResponse, error)
+	GetStandingOrder(ctx context.Context, orderID string) (*pb.GetStandingOrderResponse, error)
+	WatchAccount(ctx context.Context, request *pb.WatchAccountRequest, handle func(*pb.WatchAccountResponse) error) error
+	GenerateStatement(ctx context.Context, request *pb.GenerateStatementRequest, writer io.Writer) error
+	BatchGetAccounts(ctx context.Context, accountIDs []string) ([]*pb.BatchGetAccountsResult, error)
+	BulkPost(ctx context.Context, entries []*pb.BulkPostEntry) ([]*pb.BulkPostResult, error)
+	ListAuditEntries(ctx context.Context, request *pb.ListAuditEntriesRequest) (*pb.ListAuditEntriesResponse, error)
+	EraseCustomer(ctx context.Context, accountOwner string) (*pb.EraseCustomerResponse, error)
+}
+
+// client implements the Client interface
+type client struct {
+	remote accountsv1connect.BankAccountServiceClient
+}
+
+var _ Client = &client{}
+
+// NewClient creates a new instance of Client calling the accounts api service with the Connect protocol.
+// The calls are made over TLS with the given TLS configuration when it is set, e.g. the configuration presenting
+// the client certificate when the api server requires mTLS, and in plaintext otherwise.
+// The given options configure the Connect client, e.g. WithHeaders to send the bearer token and the tenant of the calls
+func NewClient(host string, port int, tlsConfig *tls.Config, opts ...connect.ClientOption) Client {
+	scheme, httpClient := "http", http.DefaultClient
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		scheme, httpClient = "https", &http.Client{Transport: transport}
+	}
+
+	return &client{
+		remote: accountsv1connect.NewBankAccountServiceClient(httpClient, fmt.Sprintf("%s://%s:%d", scheme, host, port), opts...),
+	}
+}
+
+// HeaderProvider returns the headers to send with the calls made with a given context,
+// e.g. the Authorization header carrying the bearer token of the caller and the X-Tenant-Id header
+type HeaderProvider func(ctx context.Context) (http.Header, error)
+
+// WithHeaders returns the client option sending the headers returned by the given provider with every call, streaming calls included.
+// The calls fail without being sent when the provider fails
+func WithHeaders(provider HeaderProvider) connect.ClientOption {
+	return connect.WithInterceptors(&headerInterceptor{provider})
+}
+
+// headerInterceptor sets the headers of the outgoing calls
+type headerInterceptor struct {
+	provider HeaderProvider
+}
+
+var _ connect.Interceptor = (*headerInterceptor)(nil)
+
+// WrapUnary sets the headers of the unary calls
+func (i *headerInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, request connect.AnyRequest) (connect.AnyResponse, error) {
+		headers, err := i.provider(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the headers of the call")
+		}
+		setHeaders(request.Header(), headers)
+		return next(ctx, request)
+	}
+}
+
+// WrapStreamingClient sets the headers of the streaming calls
+func (i *headerInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		conn := next(ctx, spec)
+		headers, err := i.provider(ctx)
+		if err != nil {
+			return &failedClientConn{StreamingClientConn: conn, err: errors.Wrap(err, "failed to get the headers of the call")}
+		}
+		setHeaders(conn.RequestHeader(), headers)
+		return conn
+	}
+}
+
+// WrapStreamingHandler leaves the handlers untouched since the interceptor only applies to the client
+func (i *headerInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
+
+// setHeaders sets the given headers into the headers of a request
+func setHeaders(requestHeaders, headers http.Header) {
+	for key, values := range headers {
+		requestHeaders.Del(key)
+		for _, value := range values {
+			requestHeaders.Add(key, value)
+		}
+	}
+}
+
+// failedClientConn is the connection of a streaming call whose headers could not be set.
+// The call is never sent, hence the underlying connection which is not started is left untouched
+type failedClientConn struct {
+	connect.StreamingClientConn
+	err error
+}
+
+// Send returns the error of the headers
+func (c *failedClientConn) Send(any) error {
+	return c.err
+}
+
+// CloseRequest returns the error of the headers
+func (c *failedClientConn) CloseRequest() error {
+	return c.err
+}
+
+// Receive returns the error of the headers
+func (c *failedClientConn) Receive(any) error {
+	return c.err
+}
+
+// CloseResponse returns nil since there is no response to close
+func (c *failedClientConn) CloseResponse() error {
+	return nil
+}
+
+// OpenAccount opens a bank account and returns the newly created account
+func (c client) OpenAccount(ctx context.Context, request *pb.OpenAccountRequest) (*pb.OpenAccountResponse, error) {
+	response, err := c.remote.OpenAccount(ctx, connect.NewRequest(request))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return response.Msg, nil
+}
+
+// DebitAccount debits a bank account and returns the account with the new balance
+func (c client) DebitAccount(ctx context.Context, request *pb.DebitAccountRequest) (*pb.DebitAccountResponse, error) {
+	response, err := c.remote.DebitAccount(ctx, connect.NewRequest(request))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return response.Msg, nil
+}
+
+// CreditAccount credits a bank account and returns the account with the new balance
+func (c client) CreditAccount(ctx context.Context, request *pb.CreditAccountRequest) (*pb.CreditAccountResponse, error) {
+	response, err := c.remote.CreditAccount(ctx, connect.NewRequest(request))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return response.Msg, nil
+}
+
+// GetAccount returns a given bank account read with the consistency of the request
+func (c client) GetAccount(ctx context.Context, request *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	response, err := c.remote.GetAccount(ctx, connect.NewRequest(request))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return response.Msg, nil
+}
+
+// GetAccountAt returns a given bank account as it was at a given revision or time
+func (c client) GetAccountAt(ctx context.Context, request *pb.GetAccountAtRequest) (*pb.GetAccountAtResponse, error) {
+	response, err := c.remote.GetAccountAt(ctx, connect.NewRequest(request))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return response.Msg, nil
+}
+
+// WaiveFee refunds previously charged fees and returns the account with the new balance
+func (c client) WaiveFee(ctx context.Context, request *pb.WaiveFeeRequest) (*pb.WaiveFeeResponse, error) {
+	response, err := c.remote.WaiveFee(ctx, connect.NewRequest(request))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return response.Msg, nil
+}
+
+// CreateStandingOrder creates a standing order and returns the newly created standing order
+func (c client) CreateStandingOrder(ctx context.Context, request *pb.CreateStandingOrderRequest) (*pb.CreateStandingOrderResponse, error) {
+	response, err := c.remote.CreateStandingOrder(ctx, connect.NewRequest(request))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return response.Msg, nil
+}
+
+// PauseStandingOrder pauses a given standing order
+func (c client) PauseStandingOrder(ctx context.Context, orderID string) (*pb.PauseStandingOrderResponse, error) {
+	response, err := c.remote.PauseStandingOrder(ctx, connect.NewRequest(&pb.PauseStandingOrderRequest{OrderId: orderID}))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return response.Msg, nil
+}
+
+// ResumeStandingOrder resumes a given paused standing order
+func (c client) ResumeStandingOrder(ctx context.Context, orderID string) (*pb.ResumeStandingOrderResponse, error) {
+	response, err := c.remote.ResumeStandingOrder(ctx, connect.NewRequest(&pb.ResumeStandingOrderRequest{OrderId: orderID}))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return response.Msg, nil
+}
+
+// CancelStandingOrder cancels a given standing order
+func (c client) CancelStandingOrder(ctx context.Context, orderID string) (*pb.CancelStandingOrderResponse, error) {
+	response, err := c.remote.CancelStandingOrder(ctx, connect.NewRequest(&pb.CancelStandingOrderRequest{OrderId: orderID}))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return response.Msg, nil
+}
+
+// GetStandingOrder returns a given standing order
+func (c client) GetStandingOrder(ctx context.Context, orderID string) (*pb.GetStandingOrderResponse, error) {
+	response, err := c.remote.GetStandingOrder(ctx, connect.NewRequest(&pb.GetStandingOrderRequest{OrderId: orderID}))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return response.Msg, nil
+}
+
+// WatchAccount streams the changes of a given account to the given handler until the context is done,
+// the stream is closed by the server or the handler returns an error which is then returned
+func (c client) WatchAccount(ctx context.Context, request *pb.WatchAccountRequest, handle func(*pb.WatchAccountResponse) error) error {
+	stream, err := c.remote.WatchAccount(ctx, connect.NewRequest(request))
+	if err != nil {
+		return toStatusError(err)
+	}
+	// free resources
+	defer stream.Close()
+
+	for stream.Receive() {
+		if err := handle(stream.Msg()); err != nil {
+			return err
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return toStatusError(err)
+	}
+	return nil
+}
+
+// GenerateStatement generates the statement of a given account and writes it into the given writer
+func (c client) GenerateStatement(ctx context.Context, request *pb.GenerateStatementRequest, writer io.Writer) error {
+	stream, err := c.remote.GenerateStatement(ctx, connect.NewRequest(request))
+	if err != nil {
+		return toStatusError(err)
+	}
+	// free resources
+	defer stream.Close()
+
+	for stream.Receive() {
+		if _, err := writer.Write(stream.Msg().GetChunk()); err != nil {
+			return errors.Wrap(err, "failed to write the statement")
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return toStatusError(err)
+	}
+	return nil
+}
+
+// BatchGetAccounts returns the given bank accounts in the order of the account ids with their revision.
+// The results of the accounts not found have no account
+func (c client) BatchGetAccounts(ctx context.Context, accountIDs []string) ([]*pb.BatchGetAccountsResult, error) {
+	response, err := c.remote.BatchGetAccounts(ctx, connect.NewRequest(&pb.BatchGetAccountsRequest{AccountIds: accountIDs}))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return response.Msg.GetResults(), nil
+}
+
+// BulkPost executes the given credits and debits and returns their outcomes in the order of the entries
+func (c client) BulkPost(ctx context.Context, entries []*pb.BulkPostEntry) ([]*pb.BulkPostResult, error) {
+	response, err := c.remote.BulkPost(ctx, connect.NewRequest(&pb.BulkPostRequest{Entries: entries}))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return response.Msg.GetResults(), nil
+}
+
+// ListAuditEntries returns a page of the audit trail of a given account or standing order along with the token of the next page
+func (c client) ListAuditEntries(ctx context.Context, request *pb.ListAuditEntriesRequest) (*pb.ListAuditEntriesResponse, error) {
+	response, err := c.remote.ListAuditEntries(ctx, connect.NewRequest(request))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return response.Msg, nil
+}
+
+// EraseCustomer erases the personal data of a given account owner and returns the ids of the accounts scrubbed from the read model
+func (c client) EraseCustomer(ctx context.Context, accountOwner string) (*pb.EraseCustomerResponse, error) {
+	response, err := c.remote.EraseCustomer(ctx, connect.NewRequest(&pb.EraseCustomerRequest{AccountOwner: accountOwner}))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return response.Msg, nil
+}
+
+// typeURLPrefix is the prefix of the type url of the error details
+const typeURLPrefix = "type.googleapis.com/"
+
+// toStatusError converts the error returned by the Connect client into a gRPC status error
+// with the same code, message and details
+func toStatusError(err error) error {
+	var connectErr *connect.Error
+	if !errors.As(err, &connectErr) {
+		return status.Error(codes.Unknown, err.Error())
+	}
+
+	// keep the error details so that the callers can branch on the error reason
+	st := &spb.Status{Code: int32(connectErr.Code()), Message: connectErr.Message()}
+	for _, detail := range connectErr.Details() {
+		st.Details = append(st.Details, &anypb.Any{
+			TypeUrl: typeURLPrefix + detail.Type(),
+			Value:   detail.Bytes(),
+		})
+	}
+	return status.ErrorProto(st)
+}
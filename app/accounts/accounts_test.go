@@ -0,0 +1,524 @@
+package accounts
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	"github.com/tochemey/cos-go-sample/gen/accounts/v1/accountsv1connect"
+	mocks "github.com/tochemey/cos-go-sample/mocks/gen/accounts/v1/accountsv1connect"
+)
+
+type accountsClientTestSuite struct {
+	suite.Suite
+}
+
+// In order for 'go test' to run this suite, we need to create
+// a normal test function and pass our suite to suite.Run
+func TestAccountsClient(t *testing.T) {
+	suite.Run(t, new(accountsClientTestSuite))
+}
+
+func (s *accountsClientTestSuite) TestNewClient() {
+	s.Run("happy path", func() {
+		// the connect client does not connect until the first call
+		accountsClient := NewClient("localhost", 50051, nil)
+		s.Assert().NotNil(accountsClient)
+	})
+	s.Run("with TLS", func() {
+		ctx := context.TODO()
+		expected := &pb.BankAccount{AccountId: "account-1", AccountBalance: 100}
+
+		// serve the accounts service over TLS only
+		handler := new(mocks.BankAccountServiceHandler)
+		handler.
+			On("GetAccount", mock.Anything, mock.Anything).
+			Return(connect.NewResponse(&pb.GetAccountResponse{Account: expected, RevisionNumber: 3}), nil)
+		mux := http.NewServeMux()
+		mux.Handle(accountsv1connect.NewBankAccountServiceHandler(handler))
+		server := httptest.NewTLSServer(mux)
+		defer server.Close()
+
+		address := server.Listener.Addr().(*net.TCPAddr)
+		rootCAs := x509.NewCertPool()
+		rootCAs.AddCert(server.Certificate())
+
+		accountsClient := NewClient("127.0.0.1", address.Port, &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12})
+		actual, err := accountsClient.GetAccount(ctx, &pb.GetAccountRequest{AccountId: "account-1"})
+		s.Require().NoError(err)
+		s.Assert().True(proto.Equal(expected, actual.GetAccount()))
+		s.Assert().EqualValues(3, actual.GetRevisionNumber())
+
+		// the plaintext client cannot reach the server
+		_, err = NewClient("127.0.0.1", address.Port, nil).GetAccount(ctx, &pb.GetAccountRequest{AccountId: "account-1"})
+		s.Assert().Error(err)
+	})
+}
+
+func (s *accountsClientTestSuite) TestWithHeaders() {
+	// headers returns the headers of the calls made on behalf of the caller of a given context
+	headers := func(ctx context.Context) (http.Header, error) {
+		token, ok := ctx.Value(tokenKey{}).(string)
+		if !ok {
+			return nil, errors.New("the token is not set")
+		}
+		return http.Header{"Authorization": {"Bearer " + token}, "X-Tenant-Id": {"acme"}}, nil
+	}
+	// isAuthorized checks the headers received by the server
+	isAuthorized := func(header http.Header) bool {
+		return header.Get("Authorization") == "Bearer token-1" && header.Get("X-Tenant-Id") == "acme"
+	}
+
+	s.Run("with unary call", func() {
+		ctx := context.WithValue(context.TODO(), tokenKey{}, "token-1")
+
+		handler := new(mocks.BankAccountServiceHandler)
+		handler.
+			On("GetAccount", mock.Anything, mock.MatchedBy(func(actual *connect.Request[pb.GetAccountRequest]) bool {
+				return isAuthorized(actual.Header())
+			})).
+			Return(connect.NewResponse(&pb.GetAccountResponse{Account: &pb.BankAccount{AccountId: "account-1"}}), nil)
+		mux := http.NewServeMux()
+		mux.Handle(accountsv1connect.NewBankAccountServiceHandler(handler))
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		address := server.Listener.Addr().(*net.TCPAddr)
+		accountsClient := NewClient("127.0.0.1", address.Port, nil, WithHeaders(headers))
+		actual, err := accountsClient.GetAccount(ctx, &pb.GetAccountRequest{AccountId: "account-1"})
+		s.Require().NoError(err)
+		s.Assert().Equal("account-1", actual.GetAccount().GetAccountId())
+		handler.AssertExpectations(s.T())
+	})
+	s.Run("with streaming call", func() {
+		ctx := context.WithValue(context.TODO(), tokenKey{}, "token-1")
+
+		handler := new(mocks.BankAccountServiceHandler)
+		handler.
+			On("WatchAccount", mock.Anything, mock.MatchedBy(func(actual *connect.Request[pb.WatchAccountRequest]) bool {
+				return isAuthorized(actual.Header())
+			}), mock.Anything).
+			Run(func(args mock.Arguments) {
+				stream := args.Get(2).(*connect.ServerStream[pb.WatchAccountResponse])
+				_ = stream.Send(&pb.WatchAccountResponse{RevisionNumber: 1})
+			}).
+			Return(nil)
+		mux := http.NewServeMux()
+		mux.Handle(accountsv1connect.NewBankAccountServiceHandler(handler))
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		address := server.Listener.Addr().(*net.TCPAddr)
+		accountsClient := NewClient("127.0.0.1", address.Port, nil, WithHeaders(headers))
+		var revisions []int32
+		err := accountsClient.WatchAccount(ctx, &pb.WatchAccountRequest{AccountId: "account-1"}, func(response *pb.WatchAccountResponse) error {
+			revisions = append(revisions, response.GetRevisionNumber())
+			return nil
+		})
+		s.Require().NoError(err)
+		s.Assert().Equal([]int32{1}, revisions)
+		handler.AssertExpectations(s.T())
+	})
+	s.Run("with headers not provided", func() {
+		ctx := context.TODO()
+
+		handler := new(mocks.BankAccountServiceHandler)
+		mux := http.NewServeMux()
+		mux.Handle(accountsv1connect.NewBankAccountServiceHandler(handler))
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		address := server.Listener.Addr().(*net.TCPAddr)
+		accountsClient := NewClient("127.0.0.1", address.Port, nil, WithHeaders(headers))
+		_, err := accountsClient.GetAccount(ctx, &pb.GetAccountRequest{AccountId: "account-1"})
+		s.Assert().Error(err)
+		err = accountsClient.WatchAccount(ctx, &pb.WatchAccountRequest{AccountId: "account-1"}, func(*pb.WatchAccountResponse) error {
+			return nil
+		})
+		s.Assert().Error(err)
+		// the calls are not sent
+		handler.AssertNotCalled(s.T(), "GetAccount", mock.Anything, mock.Anything)
+		handler.AssertNotCalled(s.T(), "WatchAccount", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// tokenKey is the context key of the bearer token of the caller
+type tokenKey struct{}
+
+func (s *accountsClientTestSuite) TestOpenAccount() {
+	s.Run("happy path", func() {
+		ctx := context.TODO()
+		request := &pb.OpenAccountRequest{AccountOwner: "John Doe", Balance: 100}
+		expected := &pb.BankAccount{AccountId: "account-1", AccountOwner: "John Doe", AccountBalance: 100}
+
+		remote := new(mocks.BankAccountServiceClient)
+		remote.
+			On("OpenAccount", ctx, mock.MatchedBy(func(actual *connect.Request[pb.OpenAccountRequest]) bool {
+				return proto.Equal(request, actual.Msg)
+			})).
+			Return(connect.NewResponse(&pb.OpenAccountResponse{Account: expected, RevisionNumber: 1}), nil)
+
+		accountsClient := client{remote}
+		actual, err := accountsClient.OpenAccount(ctx, request)
+		s.Assert().NoError(err)
+		s.Assert().True(proto.Equal(expected, actual.GetAccount()))
+		s.Assert().EqualValues(1, actual.GetRevisionNumber())
+		remote.AssertExpectations(s.T())
+	})
+	s.Run("with connect error", func() {
+		ctx := context.TODO()
+
+		remote := new(mocks.BankAccountServiceClient)
+		remote.
+			On("OpenAccount", ctx, mock.Anything).
+			Return(nil, connect.NewError(connect.CodeInvalidArgument, errors.New("the opening balance is negative")))
+
+		accountsClient := client{remote}
+		actual, err := accountsClient.OpenAccount(ctx, &pb.OpenAccountRequest{Balance: -1})
+		s.Assert().Error(err)
+		s.Assert().Nil(actual)
+		// the connect error is converted into a grpc status error
+		s.Assert().EqualError(err, status.Error(codes.InvalidArgument, "the opening balance is negative").Error())
+		remote.AssertExpectations(s.T())
+	})
+	s.Run("with error details", func() {
+		ctx := context.TODO()
+
+		connectErr := connect.NewError(connect.CodeInvalidArgument, errors.New("the opening balance is below the minimum required"))
+		detail, err := connect.NewErrorDetail(&errdetails.ErrorInfo{Reason: "OPENING_BALANCE_TOO_LOW", Domain: "accounts.v1"})
+		s.Require().NoError(err)
+		connectErr.AddDetail(detail)
+
+		remote := new(mocks.BankAccountServiceClient)
+		remote.
+			On("OpenAccount", ctx, mock.Anything).
+			Return(nil, connectErr)
+
+		accountsClient := client{remote}
+		actual, err := accountsClient.OpenAccount(ctx, &pb.OpenAccountRequest{Balance: 1})
+		s.Assert().Error(err)
+		s.Assert().Nil(actual)
+		// the error details are kept in the grpc status error
+		st := status.Convert(err)
+		s.Assert().Equal(codes.InvalidArgument, st.Code())
+		s.Require().Len(st.Details(), 1)
+		info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+		s.Require().True(ok)
+		s.Assert().Equal("OPENING_BALANCE_TOO_LOW", info.GetReason())
+		remote.AssertExpectations(s.T())
+	})
+}
+
+func (s *accountsClientTestSuite) TestGetAccount() {
+	s.Run("happy path", func() {
+		ctx := context.TODO()
+		expected := &pb.BankAccount{AccountId: "account-1", AccountBalance: 100}
+
+		remote := new(mocks.BankAccountServiceClient)
+		remote.
+			On("GetAccount", ctx, mock.MatchedBy(func(actual *connect.Request[pb.GetAccountRequest]) bool {
+				return actual.Msg.GetAccountId() == "account-1" && actual.Msg.GetConsistency() == pb.Consistency_STRONG
+			})).
+			Return(connect.NewResponse(&pb.GetAccountResponse{Account: expected, RevisionNumber: 4}), nil)
+
+		accountsClient := client{remote}
+		actual, err := accountsClient.GetAccount(ctx, &pb.GetAccountRequest{AccountId: "account-1", Consistency: pb.Consistency_STRONG})
+		s.Assert().NoError(err)
+		s.Assert().True(proto.Equal(expected, actual.GetAccount()))
+		// the revision metadata are returned
+		s.Assert().EqualValues(4, actual.GetRevisionNumber())
+		remote.AssertExpectations(s.T())
+	})
+	s.Run("with not found account", func() {
+		ctx := context.TODO()
+
+		remote := new(mocks.BankAccountServiceClient)
+		remote.
+			On("GetAccount", ctx, mock.Anything).
+			Return(nil, connect.NewError(connect.CodeNotFound, errors.New("account not found")))
+
+		accountsClient := client{remote}
+		actual, err := accountsClient.GetAccount(ctx, &pb.GetAccountRequest{AccountId: "account-1"})
+		s.Assert().Error(err)
+		s.Assert().Nil(actual)
+		s.Assert().Equal(codes.NotFound, status.Code(err))
+		remote.AssertExpectations(s.T())
+	})
+}
+
+func (s *accountsClientTestSuite) TestGetAccountAt() {
+	s.Run("happy path", func() {
+		ctx := context.TODO()
+		expected := &pb.BankAccount{AccountId: "account-1", AccountBalance: 100}
+
+		remote := new(mocks.BankAccountServiceClient)
+		remote.
+			On("GetAccountAt", ctx, mock.MatchedBy(func(actual *connect.Request[pb.GetAccountAtRequest]) bool {
+				return actual.Msg.GetAccountId() == "account-1" && actual.Msg.GetRevision() == 2
+			})).
+			Return(connect.NewResponse(&pb.GetAccountAtResponse{Account: expected, RevisionNumber: 2}), nil)
+
+		accountsClient := client{remote}
+		actual, err := accountsClient.GetAccountAt(ctx, &pb.GetAccountAtRequest{AccountId: "account-1", Point: &pb.GetAccountAtRequest_Revision{Revision: 2}})
+		s.Assert().NoError(err)
+		s.Assert().True(proto.Equal(expected, actual.GetAccount()))
+		s.Assert().EqualValues(2, actual.GetRevisionNumber())
+		remote.AssertExpectations(s.T())
+	})
+}
+
+func (s *accountsClientTestSuite) TestPauseStandingOrder() {
+	s.Run("happy path", func() {
+		ctx := context.TODO()
+		expected := &pb.StandingOrder{OrderId: "order-1", Status: pb.StandingOrderStatus_PAUSED}
+
+		remote := new(mocks.BankAccountServiceClient)
+		remote.
+			On("PauseStandingOrder", ctx, mock.MatchedBy(func(actual *connect.Request[pb.PauseStandingOrderRequest]) bool {
+				return actual.Msg.GetOrderId() == "order-1"
+			})).
+			Return(connect.NewResponse(&pb.PauseStandingOrderResponse{StandingOrder: expected}), nil)
+
+		accountsClient := client{remote}
+		actual, err := accountsClient.PauseStandingOrder(ctx, "order-1")
+		s.Assert().NoError(err)
+		s.Assert().True(proto.Equal(expected, actual.GetStandingOrder()))
+		remote.AssertExpectations(s.T())
+	})
+}
+
+func (s *accountsClientTestSuite) TestBatchGetAccounts() {
+	s.Run("happy path", func() {
+		ctx := context.TODO()
+		expected := &pb.BankAccount{AccountId: "account-1", AccountBalance: 100}
+
+		remote := new(mocks.BankAccountServiceClient)
+		remote.
+			On("BatchGetAccounts", ctx, mock.MatchedBy(func(actual *connect.Request[pb.BatchGetAccountsRequest]) bool {
+				return len(actual.Msg.GetAccountIds()) == 2
+			})).
+			Return(connect.NewResponse(&pb.BatchGetAccountsResponse{Results: []*pb.BatchGetAccountsResult{
+				{AccountId: "account-1", Account: expected, RevisionNumber: 2},
+				{AccountId: "account-2"},
+			}}), nil)
+
+		accountsClient := client{remote}
+		actual, err := accountsClient.BatchGetAccounts(ctx, []string{"account-1", "account-2"})
+		s.Assert().NoError(err)
+		s.Require().Len(actual, 2)
+		s.Assert().True(proto.Equal(expected, actual[0].GetAccount()))
+		s.Assert().EqualValues(2, actual[0].GetRevisionNumber())
+		// the account not found has no account
+		s.Assert().Nil(actual[1].GetAccount())
+		remote.AssertExpectations(s.T())
+	})
+}
+
+func (s *accountsClientTestSuite) TestBulkPost() {
+	s.Run("happy path", func() {
+		ctx := context.TODO()
+		entries := []*pb.BulkPostEntry{
+			{Command: &pb.BulkPostEntry_Credit{Credit: &pb.CreditAccountRequest{AccountId: "account-1", Amount: 10}}},
+		}
+		expected := []*pb.BulkPostResult{{Account: &pb.BankAccount{AccountId: "account-1", AccountBalance: 110}, RevisionNumber: 2}}
+
+		remote := new(mocks.BankAccountServiceClient)
+		remote.
+			On("BulkPost", ctx, mock.MatchedBy(func(actual *connect.Request[pb.BulkPostRequest]) bool {
+				return len(actual.Msg.GetEntries()) == 1
+			})).
+			Return(connect.NewResponse(&pb.BulkPostResponse{Results: expected}), nil)
+
+		accountsClient := client{remote}
+		actual, err := accountsClient.BulkPost(ctx, entries)
+		s.Assert().NoError(err)
+		s.Require().Len(actual, 1)
+		s.Assert().True(proto.Equal(expected[0], actual[0]))
+		remote.AssertExpectations(s.T())
+	})
+}
+
+func (s *accountsClientTestSuite) TestListAuditEntries() {
+	s.Run("happy path", func() {
+		ctx := context.TODO()
+		expected := []*pb.AuditEntry{{EntityId: "account-1", RevisionNumber: 1}}
+
+		remote := new(mocks.BankAccountServiceClient)
+		remote.
+			On("ListAuditEntries", ctx, mock.MatchedBy(func(actual *connect.Request[pb.ListAuditEntriesRequest]) bool {
+				return actual.Msg.GetAccountId() == "account-1" && actual.Msg.GetPageSize() == 1
+			})).
+			Return(connect.NewResponse(&pb.ListAuditEntriesResponse{Entries: expected, NextPageToken: "token"}), nil)
+
+		accountsClient := client{remote}
+		actual, err := accountsClient.ListAuditEntries(ctx, &pb.ListAuditEntriesRequest{
+			Entity:   &pb.ListAuditEntriesRequest_AccountId{AccountId: "account-1"},
+			PageSize: 1,
+		})
+		s.Assert().NoError(err)
+		s.Require().Len(actual.GetEntries(), 1)
+		s.Assert().True(proto.Equal(expected[0], actual.GetEntries()[0]))
+		// the token of the next page is returned
+		s.Assert().Equal("token", actual.GetNextPageToken())
+		remote.AssertExpectations(s.T())
+	})
+}
+
+func (s *accountsClientTestSuite) TestEraseCustomer() {
+	s.Run("happy path", func() {
+		ctx := context.TODO()
+
+		remote := new(mocks.BankAccountServiceClient)
+		remote.
+			On("EraseCustomer", ctx, mock.MatchedBy(func(actual *connect.Request[pb.EraseCustomerRequest]) bool {
+				return actual.Msg.GetAccountOwner() == "John Doe"
+			})).
+			Return(connect.NewResponse(&pb.EraseCustomerResponse{ErasedAccountIds: []string{"account-1"}}), nil)
+
+		accountsClient := client{remote}
+		actual, err := accountsClient.EraseCustomer(ctx, "John Doe")
+		s.Assert().NoError(err)
+		s.Assert().Equal([]string{"account-1"}, actual.GetErasedAccountIds())
+		remote.AssertExpectations(s.T())
+	})
+}
+
+func (s *accountsClientTestSuite) TestWatchAccount() {
+	s.Run("happy path", func() {
+		ctx := context.TODO()
+
+		// serve the account changes with an in-process connect server
+		handler := new(mocks.BankAccountServiceHandler)
+		handler.
+			On("WatchAccount", mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				stream := args.Get(2).(*connect.ServerStream[pb.WatchAccountResponse])
+				_ = stream.Send(&pb.WatchAccountResponse{Account: &pb.BankAccount{AccountId: "account-1"}, RevisionNumber: 3})
+				_ = stream.Send(&pb.WatchAccountResponse{Account: &pb.BankAccount{AccountId: "account-1"}, RevisionNumber: 4})
+			}).
+			Return(nil)
+		mux := http.NewServeMux()
+		mux.Handle(accountsv1connect.NewBankAccountServiceHandler(handler))
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		accountsClient := client{accountsv1connect.NewBankAccountServiceClient(http.DefaultClient, server.URL)}
+		var revisions []int32
+		err := accountsClient.WatchAccount(ctx, &pb.WatchAccountRequest{AccountId: "account-1", FromRevision: 3}, func(response *pb.WatchAccountResponse) error {
+			revisions = append(revisions, response.GetRevisionNumber())
+			return nil
+		})
+		s.Assert().NoError(err)
+		s.Assert().Equal([]int32{3, 4}, revisions)
+		handler.AssertExpectations(s.T())
+	})
+	s.Run("with handler error", func() {
+		ctx := context.TODO()
+
+		handler := new(mocks.BankAccountServiceHandler)
+		handler.
+			On("WatchAccount", mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				stream := args.Get(2).(*connect.ServerStream[pb.WatchAccountResponse])
+				_ = stream.Send(&pb.WatchAccountResponse{RevisionNumber: 3})
+				_ = stream.Send(&pb.WatchAccountResponse{RevisionNumber: 4})
+			}).
+			Return(nil)
+		mux := http.NewServeMux()
+		mux.Handle(accountsv1connect.NewBankAccountServiceHandler(handler))
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		accountsClient := client{accountsv1connect.NewBankAccountServiceClient(http.DefaultClient, server.URL)}
+		expected := errors.New("stop watching")
+		calls := 0
+		err := accountsClient.WatchAccount(ctx, &pb.WatchAccountRequest{AccountId: "account-1"}, func(*pb.WatchAccountResponse) error {
+			calls++
+			return expected
+		})
+		// the watch stops on the first error of the handler which is returned as is
+		s.Assert().ErrorIs(err, expected)
+		s.Assert().Equal(1, calls)
+	})
+	s.Run("with stream error", func() {
+		ctx := context.TODO()
+
+		handler := new(mocks.BankAccountServiceHandler)
+		handler.
+			On("WatchAccount", mock.Anything, mock.Anything, mock.Anything).
+			Return(connect.NewError(connect.CodeNotFound, errors.New("account not found")))
+		mux := http.NewServeMux()
+		mux.Handle(accountsv1connect.NewBankAccountServiceHandler(handler))
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		accountsClient := client{accountsv1connect.NewBankAccountServiceClient(http.DefaultClient, server.URL)}
+		err := accountsClient.WatchAccount(ctx, &pb.WatchAccountRequest{AccountId: "account-1"}, func(*pb.WatchAccountResponse) error {
+			return nil
+		})
+		s.Assert().Error(err)
+		s.Assert().Equal(codes.NotFound, status.Code(err))
+		handler.AssertExpectations(s.T())
+	})
+}
+
+func (s *accountsClientTestSuite) TestGenerateStatement() {
+	s.Run("happy path", func() {
+		ctx := context.TODO()
+
+		// serve the statement chunks with an in-process connect server
+		handler := new(mocks.BankAccountServiceHandler)
+		handler.
+			On("GenerateStatement", mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				stream := args.Get(2).(*connect.ServerStream[pb.GenerateStatementResponse])
+				_ = stream.Send(&pb.GenerateStatementResponse{Chunk: []byte("hello ")})
+				_ = stream.Send(&pb.GenerateStatementResponse{Chunk: []byte("world")})
+			}).
+			Return(nil)
+		mux := http.NewServeMux()
+		mux.Handle(accountsv1connect.NewBankAccountServiceHandler(handler))
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		accountsClient := client{accountsv1connect.NewBankAccountServiceClient(http.DefaultClient, server.URL)}
+		writer := new(bytes.Buffer)
+		err := accountsClient.GenerateStatement(ctx, &pb.GenerateStatementRequest{AccountId: "account-1"}, writer)
+		s.Assert().NoError(err)
+		s.Assert().Equal("hello world", writer.String())
+		handler.AssertExpectations(s.T())
+	})
+	s.Run("with stream error", func() {
+		ctx := context.TODO()
+
+		handler := new(mocks.BankAccountServiceHandler)
+		handler.
+			On("GenerateStatement", mock.Anything, mock.Anything, mock.Anything).
+			Return(connect.NewError(connect.CodeInvalidArgument, errors.New("the statement format is not set")))
+		mux := http.NewServeMux()
+		mux.Handle(accountsv1connect.NewBankAccountServiceHandler(handler))
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		accountsClient := client{accountsv1connect.NewBankAccountServiceClient(http.DefaultClient, server.URL)}
+		err := accountsClient.GenerateStatement(ctx, &pb.GenerateStatementRequest{AccountId: "account-1"}, new(bytes.Buffer))
+		s.Assert().Error(err)
+		s.Assert().Equal(codes.InvalidArgument, status.Code(err))
+		handler.AssertExpectations(s.T())
+	})
+}
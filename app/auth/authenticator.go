@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/MicahParks/keyfunc/v3"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pkg/errors"
+)
+
+// signingMethods are the asymmetric signing algorithms accepted for the tokens
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// bearerPrefix is the prefix of the authorization values carrying a bearer token
+const bearerPrefix = "bearer "
+
+// claims are the claims of the tokens read by the authenticator
+type claims struct {
+	jwt.RegisteredClaims
+	// Roles are the roles granted to the subject
+	Roles []string `json:"roles"`
+	// Tenant is the tenant the subject belongs to
+	Tenant string `json:"tenant"`
+}
+
+// Authenticator validates the JSON Web Tokens carried by the requests and resolves their principal.
+// The tokens must be signed by a key of the configured JSON Web Key Set, issued by the configured issuer
+// for the configured audience, and not expired.
+type Authenticator struct {
+	keys   jwt.Keyfunc
+	parser *jwt.Parser
+}
+
+// NewAuthenticator creates an instance of Authenticator. When the key set is read from an url, it is refreshed
+// in the background until the given context is done
+func NewAuthenticator(ctx context.Context, config *Config) (*Authenticator, error) {
+	// validate the config
+	if err := config.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid authentication config")
+	}
+
+	var (
+		keys keyfunc.Keyfunc
+		err  error
+	)
+	if config.JWKSFile != "" {
+		var raw []byte
+		raw, err = os.ReadFile(config.JWKSFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the JSON Web Key Set file (%s)", config.JWKSFile)
+		}
+		keys, err = keyfunc.NewJWKSetJSON(raw)
+	} else {
+		keys, err = keyfunc.NewDefaultCtx(ctx, []string{config.JWKSURL})
+	}
+	// handle the error
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load the JSON Web Key Set")
+	}
+
+	return &Authenticator{
+		keys: keys.Keyfunc,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods(signingMethods),
+			jwt.WithIssuer(config.Issuer),
+			jwt.WithAudience(config.Audience),
+			jwt.WithExpirationRequired(),
+			jwt.WithLeeway(config.ClockSkew),
+		),
+	}, nil
+}
+
+// Authenticate validates the bearer token of a given authorization value and returns its principal.
+// The errors are meant to be reported to the caller as unauthenticated
+func (a *Authenticator) Authenticate(authorization string) (*Principal, error) {
+	// the authorization scheme is case-insensitive
+	if len(authorization) <= len(bearerPrefix) || !strings.EqualFold(authorization[:len(bearerPrefix)], bearerPrefix) {
+		return nil, errors.New("the bearer token is not set")
+	}
+
+	tokenClaims := new(claims)
+	if _, err := a.parser.ParseWithClaims(strings.TrimSpace(authorization[len(bearerPrefix):]), tokenClaims, a.keys); err != nil {
+		return nil, errors.New("the bearer token is invalid")
+	}
+
+	// the subject identifies the caller
+	if tokenClaims.Subject == "" {
+		return nil, errors.New("the bearer token has no subject")
+	}
+
+	principal := &Principal{Subject: tokenClaims.Subject, Roles: make([]Role, len(tokenClaims.Roles)), Tenant: tokenClaims.Tenant}
+	for index, role := range tokenClaims.Roles {
+		principal.Roles[index] = Role(role)
+	}
+	return principal, nil
+}
@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "https://issuer.test"
+	testAudience = "accounts"
+	testKeyID    = "test-key"
+)
+
+// testKeys signs the tokens of the tests and publishes its public key as a JSON Web Key Set
+type testKeys struct {
+	key *rsa.PrivateKey
+}
+
+// newTestKeys creates a testKeys with a new signing key
+func newTestKeys(t *testing.T) *testKeys {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return &testKeys{key: key}
+}
+
+// jwks returns the JSON Web Key Set holding the public key
+func (k *testKeys) jwks(t *testing.T) []byte {
+	raw, err := json.Marshal(map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": testKeyID,
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(k.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.key.E)).Bytes()),
+		}},
+	})
+	require.NoError(t, err)
+	return raw
+}
+
+// writeJWKS writes the JSON Web Key Set to a file and returns its path
+func (k *testKeys) writeJWKS(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, k.jwks(t), 0o600))
+	return path
+}
+
+// mint signs a token carrying the given claims
+func (k *testKeys) mint(t *testing.T, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKeyID
+	signed, err := token.SignedString(k.key)
+	require.NoError(t, err)
+	return signed
+}
+
+// newClaims returns the claims of a valid token of the given subject and roles
+func newClaims(subject string, roles ...string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"sub":   subject,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": roles,
+	}
+}
+
+// newTestAuthenticator creates an Authenticator trusting the given keys
+func newTestAuthenticator(t *testing.T, keys *testKeys) *Authenticator {
+	authenticator, err := NewAuthenticator(context.TODO(), &Config{
+		JWKSFile:  keys.writeJWKS(t),
+		Issuer:    testIssuer,
+		Audience:  testAudience,
+		ClockSkew: time.Second,
+	})
+	require.NoError(t, err)
+	return authenticator
+}
+
+func TestAuthenticator(t *testing.T) {
+	keys := newTestKeys(t)
+	authenticator := newTestAuthenticator(t, keys)
+
+	t.Run("With valid token", func(t *testing.T) {
+		principal, err := authenticator.Authenticate("Bearer " + keys.mint(t, newClaims("John Doe", "customer")))
+		require.NoError(t, err)
+		assert.Equal(t, &Principal{Subject: "John Doe", Roles: []Role{RoleCustomer}}, principal)
+	})
+	t.Run("With tenant", func(t *testing.T) {
+		tokenClaims := newClaims("John Doe", "customer")
+		tokenClaims["tenant"] = "acme"
+		principal, err := authenticator.Authenticate("Bearer " + keys.mint(t, tokenClaims))
+		require.NoError(t, err)
+		assert.Equal(t, &Principal{Subject: "John Doe", Roles: []Role{RoleCustomer}, Tenant: "acme"}, principal)
+	})
+	t.Run("With case-insensitive scheme", func(t *testing.T) {
+		principal, err := authenticator.Authenticate("bearer " + keys.mint(t, newClaims("John Doe")))
+		require.NoError(t, err)
+		assert.Equal(t, "John Doe", principal.Subject)
+		assert.Empty(t, principal.Roles)
+	})
+	t.Run("With key set served by url", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(keys.jwks(t))
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+		remote, err := NewAuthenticator(ctx, &Config{JWKSURL: server.URL, Issuer: testIssuer, Audience: testAudience})
+		require.NoError(t, err)
+
+		principal, err := remote.Authenticate("Bearer " + keys.mint(t, newClaims("operator-1", "operator")))
+		require.NoError(t, err)
+		assert.True(t, principal.HasRole(RoleOperator))
+	})
+	t.Run("With invalid tokens", func(t *testing.T) {
+		expired := newClaims("John Doe")
+		expired["exp"] = time.Now().Add(-time.Hour).Unix()
+		withoutExpiry := newClaims("John Doe")
+		delete(withoutExpiry, "exp")
+		wrongIssuer := newClaims("John Doe")
+		wrongIssuer["iss"] = "https://other.test"
+		wrongAudience := newClaims("John Doe")
+		wrongAudience["aud"] = "payments"
+
+		unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, newClaims("John Doe")).SignedString(jwt.UnsafeAllowNoneSignatureType)
+		require.NoError(t, err)
+
+		testCases := map[string]struct {
+			authorization string
+			message       string
+		}{
+			"without authorization": {authorization: "", message: "the bearer token is not set"},
+			"with other scheme":     {authorization: "Basic dXNlcjpwYXNz", message: "the bearer token is not set"},
+			"with malformed token":  {authorization: "Bearer not-a-token", message: "the bearer token is invalid"},
+			"with expired token":    {authorization: "Bearer " + keys.mint(t, expired), message: "the bearer token is invalid"},
+			"without expiry":        {authorization: "Bearer " + keys.mint(t, withoutExpiry), message: "the bearer token is invalid"},
+			"with wrong issuer":     {authorization: "Bearer " + keys.mint(t, wrongIssuer), message: "the bearer token is invalid"},
+			"with wrong audience":   {authorization: "Bearer " + keys.mint(t, wrongAudience), message: "the bearer token is invalid"},
+			"with unknown signer":   {authorization: "Bearer " + newTestKeys(t).mint(t, newClaims("John Doe")), message: "the bearer token is invalid"},
+			"with unsigned token":   {authorization: "Bearer " + unsigned, message: "the bearer token is invalid"},
+			"without subject":       {authorization: "Bearer " + keys.mint(t, newClaims("")), message: "the bearer token has no subject"},
+		}
+		for name, testCase := range testCases {
+			t.Run(name, func(t *testing.T) {
+				principal, err := authenticator.Authenticate(testCase.authorization)
+				assert.EqualError(t, err, testCase.message)
+				assert.Nil(t, principal)
+			})
+		}
+	})
+	t.Run("With invalid config", func(t *testing.T) {
+		actual, err := NewAuthenticator(context.TODO(), &Config{Issuer: testIssuer, Audience: testAudience})
+		assert.Error(t, err)
+		assert.Nil(t, actual)
+	})
+	t.Run("With missing key set file", func(t *testing.T) {
+		actual, err := NewAuthenticator(context.TODO(), &Config{JWKSFile: filepath.Join(t.TempDir(), "jwks.json"), Issuer: testIssuer, Audience: testAudience})
+		assert.Error(t, err)
+		assert.Nil(t, actual)
+	})
+	t.Run("With invalid key set file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "jwks.json")
+		require.NoError(t, os.WriteFile(path, []byte("not-a-key-set"), 0o600))
+
+		actual, err := NewAuthenticator(context.TODO(), &Config{JWKSFile: path, Issuer: testIssuer, Audience: testAudience})
+		assert.Error(t, err)
+		assert.Nil(t, actual)
+	})
+}
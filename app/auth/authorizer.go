@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Permission is an operation on the accounts subject to authorization
+type Permission string
+
+const (
+	// PermissionRead allows reading the accounts, their history, their statements and their standing orders
+	PermissionRead Permission = "read"
+	// PermissionOpen allows opening accounts
+	PermissionOpen Permission = "open"
+	// PermissionCredit allows crediting accounts
+	PermissionCredit Permission = "credit"
+	// PermissionDebit allows debiting accounts
+	PermissionDebit Permission = "debit"
+	// PermissionWaiveFee allows refunding the fees charged to accounts
+	PermissionWaiveFee Permission = "waive_fee"
+	// PermissionManageStandingOrders allows creating, pausing, resuming and cancelling the standing orders of accounts
+	PermissionManageStandingOrders Permission = "manage_standing_orders"
+	// PermissionEraseCustomers allows erasing the personal data of the customers
+	PermissionEraseCustomers Permission = "erase_customers"
+)
+
+// scope is the extent of a permission granted to a role
+type scope int
+
+const (
+	// scopeOwned grants a permission on the accounts owned by the principal only
+	scopeOwned scope = iota + 1
+	// scopeAll grants a permission on every account
+	scopeAll
+)
+
+// defaultPolicy is the permissions granted to every role
+var defaultPolicy = map[Role]map[Permission]scope{
+	RoleCustomer: {
+		PermissionRead:                 scopeOwned,
+		PermissionOpen:                 scopeOwned,
+		PermissionDebit:                scopeOwned,
+		PermissionManageStandingOrders: scopeOwned,
+	},
+	RoleOperator: {
+		PermissionRead:                 scopeAll,
+		PermissionOpen:                 scopeAll,
+		PermissionCredit:               scopeAll,
+		PermissionDebit:                scopeAll,
+		PermissionWaiveFee:             scopeAll,
+		PermissionManageStandingOrders: scopeAll,
+		PermissionEraseCustomers:       scopeAll,
+	},
+	RoleAuditor: {
+		PermissionRead: scopeAll,
+	},
+}
+
+// OwnerFunc returns the owner of the accounts targeted by a request. It is only called when the principal
+// is allowed on its own accounts only so that the owner lookup is skipped otherwise
+type OwnerFunc func() (string, error)
+
+// OwnedBy returns the OwnerFunc of the accounts of a known owner
+func OwnedBy(owner string) OwnerFunc {
+	return func() (string, error) {
+		return owner, nil
+	}
+}
+
+// Authorizer enforces the permissions granted to the roles of the principals.
+// Customers only access their own accounts, operators can credit and debit every account and auditors can only read accounts.
+type Authorizer struct {
+	policy map[Role]map[Permission]scope
+}
+
+// NewAuthorizer creates an instance of Authorizer
+func NewAuthorizer() *Authorizer {
+	return &Authorizer{policy: defaultPolicy}
+}
+
+// Authorize checks whether the principal of the given context is granted a given permission on the accounts of the owner
+// returned by the given function. An unauthenticated error is returned when the context carries no principal and a permission denied
+// error is returned when the permission is not granted
+func (a *Authorizer) Authorize(ctx context.Context, permission Permission, owner OwnerFunc) error {
+	principal, ok := FromContext(ctx)
+	if !ok {
+		return status.Error(codes.Unauthenticated, "the caller is not authenticated")
+	}
+
+	// the widest scope granted by the roles of the principal applies
+	var granted scope
+	for _, role := range principal.Roles {
+		granted = max(granted, a.policy[role][permission])
+	}
+
+	switch granted {
+	case scopeAll:
+		return nil
+	case scopeOwned:
+		accountOwner, err := owner()
+		// handle the error
+		if err != nil {
+			return err
+		}
+		if accountOwner == principal.Subject {
+			return nil
+		}
+	}
+
+	return status.Errorf(codes.PermissionDenied, "the caller:(%s) is not allowed the %s permission on this account", principal.Subject, permission)
+}
+
+// Allowed checks whether the principal of the given context is granted a given permission on the accounts of the owner
+// returned by the given function. Unlike Authorize, it reports the permissions not granted without error
+func (a *Authorizer) Allowed(ctx context.Context, permission Permission, owner OwnerFunc) (bool, error) {
+	err := a.Authorize(ctx, permission, owner)
+	switch status.Code(err) {
+	case codes.OK:
+		return true, nil
+	case codes.PermissionDenied:
+		return false, nil
+	default:
+		return false, err
+	}
+}
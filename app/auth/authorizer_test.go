@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// unexpectedOwner is an OwnerFunc failing the test when the owner is looked up
+func unexpectedOwner(t *testing.T) OwnerFunc {
+	return func() (string, error) {
+		t.Error("the owner is not expected to be looked up")
+		return "", nil
+	}
+}
+
+func TestAuthorizer(t *testing.T) {
+	authorizer := NewAuthorizer()
+	customer := NewContext(context.TODO(), &Principal{Subject: "John Doe", Roles: []Role{RoleCustomer}})
+	operator := NewContext(context.TODO(), &Principal{Subject: "operator-1", Roles: []Role{RoleOperator}})
+	auditor := NewContext(context.TODO(), &Principal{Subject: "auditor-1", Roles: []Role{RoleAuditor}})
+
+	t.Run("With customer", func(t *testing.T) {
+		// customers access their own accounts
+		for _, permission := range []Permission{PermissionRead, PermissionOpen, PermissionDebit, PermissionManageStandingOrders} {
+			assert.NoError(t, authorizer.Authorize(customer, permission, OwnedBy("John Doe")))
+
+			err := authorizer.Authorize(customer, permission, OwnedBy("Jane Doe"))
+			assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		}
+
+		// customers can neither credit accounts, waive fees nor erase customers
+		for _, permission := range []Permission{PermissionCredit, PermissionWaiveFee, PermissionEraseCustomers} {
+			err := authorizer.Authorize(customer, permission, unexpectedOwner(t))
+			assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		}
+	})
+	t.Run("With operator", func(t *testing.T) {
+		for _, permission := range []Permission{PermissionRead, PermissionOpen, PermissionCredit, PermissionDebit, PermissionWaiveFee, PermissionManageStandingOrders, PermissionEraseCustomers} {
+			assert.NoError(t, authorizer.Authorize(operator, permission, unexpectedOwner(t)))
+		}
+	})
+	t.Run("With auditor", func(t *testing.T) {
+		assert.NoError(t, authorizer.Authorize(auditor, PermissionRead, unexpectedOwner(t)))
+
+		for _, permission := range []Permission{PermissionOpen, PermissionCredit, PermissionDebit, PermissionWaiveFee, PermissionManageStandingOrders, PermissionEraseCustomers} {
+			err := authorizer.Authorize(auditor, permission, unexpectedOwner(t))
+			assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		}
+	})
+	t.Run("With several roles", func(t *testing.T) {
+		// the widest scope applies
+		ctx := NewContext(context.TODO(), &Principal{Subject: "John Doe", Roles: []Role{RoleCustomer, RoleAuditor}})
+		assert.NoError(t, authorizer.Authorize(ctx, PermissionRead, unexpectedOwner(t)))
+	})
+	t.Run("With unknown role", func(t *testing.T) {
+		ctx := NewContext(context.TODO(), &Principal{Subject: "John Doe", Roles: []Role{"admin"}})
+		err := authorizer.Authorize(ctx, PermissionRead, unexpectedOwner(t))
+		assert.EqualError(t, err, "rpc error: code = PermissionDenied desc = the caller:(John Doe) is not allowed the read permission on this account")
+	})
+	t.Run("Without principal", func(t *testing.T) {
+		err := authorizer.Authorize(context.TODO(), PermissionRead, unexpectedOwner(t))
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+	t.Run("With owner lookup failure", func(t *testing.T) {
+		err := authorizer.Authorize(customer, PermissionDebit, func() (string, error) {
+			return "", status.Error(codes.NotFound, "the account is not found")
+		})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+	t.Run("With allowed check", func(t *testing.T) {
+		allowed, err := authorizer.Allowed(customer, PermissionRead, OwnedBy("John Doe"))
+		require.NoError(t, err)
+		assert.True(t, allowed)
+
+		allowed, err = authorizer.Allowed(customer, PermissionRead, OwnedBy("Jane Doe"))
+		require.NoError(t, err)
+		assert.False(t, allowed)
+
+		allowed, err = authorizer.Allowed(customer, PermissionRead, func() (string, error) {
+			return "", errors.New("connection refused")
+		})
+		assert.EqualError(t, err, "connection refused")
+		assert.False(t, allowed)
+	})
+}
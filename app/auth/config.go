@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/caarlos0/env/v9"
+	"github.com/pkg/errors"
+)
+
+// Config defines the authentication config
+type Config struct {
+	Enabled   bool          `env:"AUTH_ENABLED" envDefault:"true"`   // Enabled states whether the requests must carry a valid bearer token
+	JWKSFile  string        `env:"AUTH_JWKS_FILE" envDefault:""`     // JWKSFile is the local file holding the JSON Web Key Set verifying the tokens
+	JWKSURL   string        `env:"AUTH_JWKS_URL" envDefault:""`      // JWKSURL is the url serving the JSON Web Key Set verifying the tokens
+	Issuer    string        `env:"AUTH_ISSUER" envDefault:""`        // Issuer is the expected issuer of the tokens
+	Audience  string        `env:"AUTH_AUDIENCE" envDefault:""`      // Audience is the expected audience of the tokens
+	ClockSkew time.Duration `env:"AUTH_CLOCK_SKEW" envDefault:"30s"` // ClockSkew is the clock skew tolerated when checking the tokens validity
+}
+
+// LoadConfig fetches the Config from env vars
+func LoadConfig() *Config {
+	config := &Config{}
+	// all env vars are required
+	opts := env.Options{RequiredIfNoDef: true}
+	if err := env.ParseWithOptions(config, opts); err != nil {
+		panic(errors.Wrap(err, "unable to load environment variables"))
+	}
+
+	return config
+}
+
+// Validate checks that the config is complete. Exactly one of the key set file and url must be set
+// together with the expected issuer and audience
+func (c *Config) Validate() error {
+	switch {
+	case c.JWKSFile == "" && c.JWKSURL == "":
+		return errors.New("the JSON Web Key Set file or url is not set")
+	case c.JWKSFile != "" && c.JWKSURL != "":
+		return errors.New("only one of the JSON Web Key Set file and url can be set")
+	case c.Issuer == "":
+		return errors.New("the tokens issuer is not set")
+	case c.Audience == "":
+		return errors.New("the tokens audience is not set")
+	}
+	return nil
+}
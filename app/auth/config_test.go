@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("With default values", func(t *testing.T) {
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.True(t, actual.Enabled)
+		assert.Empty(t, actual.JWKSFile)
+		assert.Empty(t, actual.JWKSURL)
+		assert.Equal(t, 30*time.Second, actual.ClockSkew)
+	})
+	t.Run("With environment variables set", func(t *testing.T) {
+		assert.NoError(t, os.Setenv("AUTH_ENABLED", "false"))
+		assert.NoError(t, os.Setenv("AUTH_JWKS_URL", "https://issuer.test/jwks.json"))
+		assert.NoError(t, os.Setenv("AUTH_ISSUER", "https://issuer.test"))
+		assert.NoError(t, os.Setenv("AUTH_AUDIENCE", "accounts"))
+		assert.NoError(t, os.Setenv("AUTH_CLOCK_SKEW", "5s"))
+
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.False(t, actual.Enabled)
+		assert.Equal(t, "https://issuer.test/jwks.json", actual.JWKSURL)
+		assert.Equal(t, "https://issuer.test", actual.Issuer)
+		assert.Equal(t, "accounts", actual.Audience)
+		assert.Equal(t, 5*time.Second, actual.ClockSkew)
+
+		// free resources
+		assert.NoError(t, os.Unsetenv("AUTH_ENABLED"))
+		assert.NoError(t, os.Unsetenv("AUTH_JWKS_URL"))
+		assert.NoError(t, os.Unsetenv("AUTH_ISSUER"))
+		assert.NoError(t, os.Unsetenv("AUTH_AUDIENCE"))
+		assert.NoError(t, os.Unsetenv("AUTH_CLOCK_SKEW"))
+	})
+	t.Run("With invalid environment variables", func(t *testing.T) {
+		assert.NoError(t, os.Setenv("AUTH_CLOCK_SKEW", "not-a-duration"))
+
+		assert.Panics(t, func() {
+			LoadConfig()
+		})
+
+		// free resources
+		assert.NoError(t, os.Unsetenv("AUTH_CLOCK_SKEW"))
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Run("With complete config", func(t *testing.T) {
+		config := &Config{JWKSFile: "jwks.json", Issuer: "https://issuer.test", Audience: "accounts"}
+		assert.NoError(t, config.Validate())
+	})
+	t.Run("Without key set", func(t *testing.T) {
+		config := &Config{Issuer: "https://issuer.test", Audience: "accounts"}
+		assert.EqualError(t, config.Validate(), "the JSON Web Key Set file or url is not set")
+	})
+	t.Run("With both key set file and url", func(t *testing.T) {
+		config := &Config{JWKSFile: "jwks.json", JWKSURL: "https://issuer.test/jwks.json", Issuer: "https://issuer.test", Audience: "accounts"}
+		assert.EqualError(t, config.Validate(), "only one of the JSON Web Key Set file and url can be set")
+	})
+	t.Run("Without issuer", func(t *testing.T) {
+		config := &Config{JWKSFile: "jwks.json", Audience: "accounts"}
+		assert.EqualError(t, config.Validate(), "the tokens issuer is not set")
+	})
+	t.Run("Without audience", func(t *testing.T) {
+		config := &Config{JWKSFile: "jwks.json", Issuer: "https://issuer.test"}
+		assert.EqualError(t, config.Validate(), "the tokens audience is not set")
+	})
+}
@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// authorizationHeader is the header carrying the bearer token
+const authorizationHeader = "Authorization"
+
+// UnaryServerInterceptor returns the gRPC interceptor authenticating the unary calls to the given services.
+// The principal of the call is put into its context. The calls to other services, such as the health checks, are not authenticated
+func (a *Authenticator) UnaryServerInterceptor(serviceNames ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !protects(info.FullMethod, serviceNames) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := a.authenticateIncoming(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns the gRPC interceptor authenticating the streaming calls to the given services.
+// The principal of the call is put into the context of its stream. The calls to other services are not authenticated
+func (a *Authenticator) StreamServerInterceptor(serviceNames ...string) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !protects(info.FullMethod, serviceNames) {
+			return handler(srv, stream)
+		}
+
+		ctx, err := a.authenticateIncoming(stream.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// ConnectInterceptor returns the Connect interceptor authenticating the calls of a Connect handler.
+// The principal of the call is put into its context
+func (a *Authenticator) ConnectInterceptor() connect.Interceptor {
+	return &connectInterceptor{authenticator: a}
+}
+
+// Middleware returns the http middleware authenticating the requests. The principal of the request is put into its context
+// and the requests without a valid bearer token are answered with an unauthorized status
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := a.Authenticate(r.Header.Get(authorizationHeader))
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), principal)))
+	})
+}
+
+// authenticateIncoming authenticates the bearer token of the gRPC incoming metadata and returns the context carrying its principal
+func (a *Authenticator) authenticateIncoming(ctx context.Context) (context.Context, error) {
+	var authorization string
+	if values := metadata.ValueFromIncomingContext(ctx, strings.ToLower(authorizationHeader)); len(values) > 0 {
+		authorization = values[0]
+	}
+
+	principal, err := a.Authenticate(authorization)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return NewContext(ctx, principal), nil
+}
+
+// protects checks whether a given gRPC method belongs to one of the given services
+func protects(fullMethod string, serviceNames []string) bool {
+	for _, serviceName := range serviceNames {
+		if strings.HasPrefix(fullMethod, "/"+serviceName+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticatedStream is a gRPC server stream whose context carries the principal of the call
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context of the stream
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// connectInterceptor authenticates the calls of a Connect handler
+type connectInterceptor struct {
+	authenticator *Authenticator
+}
+
+var _ connect.Interceptor = (*connectInterceptor)(nil)
+
+// WrapUnary authenticates the unary calls
+func (i *connectInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, request connect.AnyRequest) (connect.AnyResponse, error) {
+		principal, err := i.authenticator.Authenticate(request.Header().Get(authorizationHeader))
+		if err != nil {
+			return nil, connect.NewError(connect.CodeUnauthenticated, err)
+		}
+		return next(NewContext(ctx, principal), request)
+	}
+}
+
+// WrapStreamingClient leaves the client streams unchanged
+func (i *connectInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler authenticates the streaming calls
+func (i *connectInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		principal, err := i.authenticator.Authenticate(conn.RequestHeader().Get(authorizationHeader))
+		if err != nil {
+			return connect.NewError(connect.CodeUnauthenticated, err)
+		}
+		return next(NewContext(ctx, principal), conn)
+	}
+}
@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const (
+	testService   = "accounts.v1.BankAccountService"
+	testMethod    = "/" + testService + "/GetAccount"
+	testProcedure = "/" + testService + "/WatchAccount"
+)
+
+// testServerStream is a gRPC server stream with a given context
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context of the stream
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// incomingContext returns a context carrying the given authorization as gRPC incoming metadata
+func incomingContext(authorization string) context.Context {
+	return metadata.NewIncomingContext(context.TODO(), metadata.Pairs("authorization", authorization))
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	keys := newTestKeys(t)
+	interceptor := newTestAuthenticator(t, keys).UnaryServerInterceptor(testService)
+
+	// handler returns the principal of the call
+	handler := func(ctx context.Context, _ any) (any, error) {
+		principal, _ := FromContext(ctx)
+		return principal, nil
+	}
+
+	t.Run("With valid token", func(t *testing.T) {
+		ctx := incomingContext("Bearer " + keys.mint(t, newClaims("John Doe", "customer")))
+		actual, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: testMethod}, handler)
+		require.NoError(t, err)
+		assert.Equal(t, &Principal{Subject: "John Doe", Roles: []Role{RoleCustomer}}, actual)
+	})
+	t.Run("Without token", func(t *testing.T) {
+		actual, err := interceptor(context.TODO(), nil, &grpc.UnaryServerInfo{FullMethod: testMethod}, handler)
+		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = the bearer token is not set")
+		assert.Nil(t, actual)
+	})
+	t.Run("With call to other service", func(t *testing.T) {
+		actual, err := interceptor(context.TODO(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, handler)
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	keys := newTestKeys(t)
+	interceptor := newTestAuthenticator(t, keys).StreamServerInterceptor(testService)
+	info := &grpc.StreamServerInfo{FullMethod: testProcedure, IsServerStream: true}
+
+	t.Run("With valid token", func(t *testing.T) {
+		stream := &testServerStream{ctx: incomingContext("Bearer " + keys.mint(t, newClaims("auditor-1", "auditor")))}
+		err := interceptor(nil, stream, info, func(_ any, stream grpc.ServerStream) error {
+			principal, ok := FromContext(stream.Context())
+			require.True(t, ok)
+			assert.Equal(t, "auditor-1", principal.Subject)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+	t.Run("With invalid token", func(t *testing.T) {
+		stream := &testServerStream{ctx: incomingContext("Bearer " + newTestKeys(t).mint(t, newClaims("auditor-1")))}
+		err := interceptor(nil, stream, info, func(any, grpc.ServerStream) error {
+			t.Error("the handler is not expected to be called")
+			return nil
+		})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestConnectInterceptor(t *testing.T) {
+	keys := newTestKeys(t)
+	interceptor := connect.WithInterceptors(newTestAuthenticator(t, keys).ConnectInterceptor())
+
+	// the handlers return the subject of the principal of the call
+	mux := http.NewServeMux()
+	mux.Handle(testMethod, connect.NewUnaryHandler(testMethod,
+		func(ctx context.Context, _ *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+			principal, _ := FromContext(ctx)
+			response := connect.NewResponse(new(emptypb.Empty))
+			response.Header().Set("Subject", principal.Subject)
+			return response, nil
+		}, interceptor))
+	mux.Handle(testProcedure, connect.NewServerStreamHandler(testProcedure,
+		func(ctx context.Context, _ *connect.Request[emptypb.Empty], stream *connect.ServerStream[emptypb.Empty]) error {
+			principal, _ := FromContext(ctx)
+			stream.ResponseHeader().Set("Subject", principal.Subject)
+			return stream.Send(new(emptypb.Empty))
+		}, interceptor))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	unaryClient := connect.NewClient[emptypb.Empty, emptypb.Empty](server.Client(), server.URL+testMethod)
+	streamClient := connect.NewClient[emptypb.Empty, emptypb.Empty](server.Client(), server.URL+testProcedure)
+
+	t.Run("With unary call", func(t *testing.T) {
+		request := connect.NewRequest(new(emptypb.Empty))
+		request.Header().Set("Authorization", "Bearer "+keys.mint(t, newClaims("John Doe", "customer")))
+
+		response, err := unaryClient.CallUnary(context.TODO(), request)
+		require.NoError(t, err)
+		assert.Equal(t, "John Doe", response.Header().Get("Subject"))
+	})
+	t.Run("With unary call without token", func(t *testing.T) {
+		_, err := unaryClient.CallUnary(context.TODO(), connect.NewRequest(new(emptypb.Empty)))
+		assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+	})
+	t.Run("With streaming call", func(t *testing.T) {
+		request := connect.NewRequest(new(emptypb.Empty))
+		request.Header().Set("Authorization", "Bearer "+keys.mint(t, newClaims("operator-1", "operator")))
+
+		stream, err := streamClient.CallServerStream(context.TODO(), request)
+		require.NoError(t, err)
+		defer stream.Close()
+		require.True(t, stream.Receive())
+		assert.Equal(t, "operator-1", stream.ResponseHeader().Get("Subject"))
+	})
+	t.Run("With streaming call without token", func(t *testing.T) {
+		stream, err := streamClient.CallServerStream(context.TODO(), connect.NewRequest(new(emptypb.Empty)))
+		require.NoError(t, err)
+		defer stream.Close()
+		assert.False(t, stream.Receive())
+		assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(stream.Err()))
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	keys := newTestKeys(t)
+	handler := newTestAuthenticator(t, keys).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ := FromContext(r.Context())
+		_, _ = w.Write([]byte(principal.Subject))
+	}))
+
+	t.Run("With valid token", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+		request.Header.Set("Authorization", "Bearer "+keys.mint(t, newClaims("John Doe", "customer")))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "John Doe", recorder.Body.String())
+	})
+	t.Run("Without token", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/graphql", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.Equal(t, "Bearer", recorder.Header().Get("WWW-Authenticate"))
+	})
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// Role is a role granted to a principal by its token
+type Role string
+
+const (
+	// RoleCustomer is granted to the account holders. Customers only access their own accounts
+	RoleCustomer Role = "customer"
+	// RoleOperator is granted to the back office operators. Operators can credit and debit every account
+	RoleOperator Role = "operator"
+	// RoleAuditor is granted to the auditors. Auditors can read every account but cannot change any
+	RoleAuditor Role = "auditor"
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	// Subject identifies the caller. The accounts of a customer are the accounts whose owner is its subject
+	Subject string
+	// Roles are the roles granted to the caller
+	Roles []Role
+	// Tenant is the tenant the caller belongs to. The callers are only allowed on their own tenant when multi-tenancy is enabled
+	Tenant string
+}
+
+// HasRole checks whether the principal has been granted a given role
+func (p *Principal) HasRole(role Role) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+// principalKey is the context key of the principal
+type principalKey struct{}
+
+// NewContext returns a copy of the given context carrying the given principal
+func NewContext(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext returns the principal carried by the given context
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
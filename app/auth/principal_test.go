@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrincipal(t *testing.T) {
+	t.Run("With roles", func(t *testing.T) {
+		principal := &Principal{Subject: "auditor-1", Roles: []Role{RoleAuditor}}
+		assert.True(t, principal.HasRole(RoleAuditor))
+		assert.False(t, principal.HasRole(RoleOperator))
+	})
+	t.Run("With principal in context", func(t *testing.T) {
+		principal := &Principal{Subject: "John Doe", Roles: []Role{RoleCustomer}}
+
+		actual, ok := FromContext(NewContext(context.TODO(), principal))
+		require.True(t, ok)
+		assert.Same(t, principal, actual)
+	})
+	t.Run("Without principal in context", func(t *testing.T) {
+		actual, ok := FromContext(context.TODO())
+		assert.False(t, ok)
+		assert.Nil(t, actual)
+
+		_, ok = FromContext(NewContext(context.TODO(), nil))
+		assert.False(t, ok)
+	})
+}
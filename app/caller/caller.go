@@ -0,0 +1,91 @@
+package caller
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/tochemey/cos-go-sample/app/auth"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+const (
+	// SubjectHeader is the gRPC header carrying the subject of the caller to the write side
+	SubjectHeader = "x-caller-subject"
+	// ClientIPHeader is the gRPC header carrying the IP address of the client to the write side
+	ClientIPHeader = "x-caller-ip"
+	// RequestIDHeader is the gRPC header carrying the id of the request. It is read from the incoming requests when set
+	RequestIDHeader = "x-request-id"
+)
+
+// Headers are the gRPC headers carrying the caller identity. They must be propagated by CoS to the write side
+var Headers = []string{SubjectHeader, ClientIPHeader, RequestIDHeader}
+
+// FromIncomingContext returns the identity of the caller of the request served with the given context.
+// The subject is the one of the authenticated principal and the client IP is the one resolved by the ClientIPResolver,
+// or the peer address when not resolved. A request id is generated when the request does not carry one
+func FromIncomingContext(ctx context.Context) *pb.Caller {
+	caller := &pb.Caller{
+		ClientIp:  clientIP(ctx),
+		RequestId: firstValue(metadata.ValueFromIncomingContext(ctx, RequestIDHeader)),
+	}
+	if principal, ok := auth.FromContext(ctx); ok {
+		caller.Subject = principal.Subject
+	}
+	if caller.GetRequestId() == "" {
+		caller.RequestId = uuid.NewString()
+	}
+	return caller
+}
+
+// NewOutgoingContext returns a context carrying the identity of the caller of the request served with the given context
+// as gRPC metadata so that it reaches the write side through CoS
+func NewOutgoingContext(ctx context.Context) context.Context {
+	caller := FromIncomingContext(ctx)
+	values := []string{caller.GetSubject(), caller.GetClientIp(), caller.GetRequestId()}
+	pairs := make([]string, 0, 2*len(Headers))
+	for index, header := range Headers {
+		if values[index] != "" {
+			pairs = append(pairs, header, values[index])
+		}
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+// FromPropagatedContext returns the identity of the caller propagated by CoS to the write side.
+// Nil is returned when no caller identity has been propagated
+func FromPropagatedContext(ctx context.Context) *pb.Caller {
+	caller := &pb.Caller{
+		Subject:   firstValue(metadata.ValueFromIncomingContext(ctx, SubjectHeader)),
+		ClientIp:  firstValue(metadata.ValueFromIncomingContext(ctx, ClientIPHeader)),
+		RequestId: firstValue(metadata.ValueFromIncomingContext(ctx, RequestIDHeader)),
+	}
+	if caller.GetSubject() == "" && caller.GetClientIp() == "" && caller.GetRequestId() == "" {
+		return nil
+	}
+	return caller
+}
+
+// clientIP returns the IP address of the client of the request served with the given context.
+// The forwarded for header is never read here since any client can set it
+func clientIP(ctx context.Context) string {
+	if clientIP, ok := ctx.Value(clientIPKey{}).(string); ok {
+		return clientIP
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	return hostOf(p.Addr.String())
+}
+
+// firstValue returns the first of the given values or an empty string when there is none
+func firstValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
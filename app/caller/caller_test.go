@@ -0,0 +1,84 @@
+package caller
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/tochemey/cos-go-sample/app/auth"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestFromIncomingContext(t *testing.T) {
+	t.Run("With authenticated principal and request id", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.TODO(), metadata.Pairs(RequestIDHeader, "request-1"))
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 52000}})
+		ctx = auth.NewContext(ctx, &auth.Principal{Subject: "customer-1"})
+
+		expected := &pb.Caller{Subject: "customer-1", ClientIp: "10.0.0.1", RequestId: "request-1"}
+		assert.True(t, proto.Equal(expected, FromIncomingContext(ctx)))
+	})
+	t.Run("With client IP resolved", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.TODO(), metadata.Pairs("x-forwarded-for", "192.168.1.10"))
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 52000}})
+		ctx = context.WithValue(ctx, clientIPKey{}, "192.168.1.10")
+
+		actual := FromIncomingContext(ctx)
+		assert.Equal(t, "192.168.1.10", actual.GetClientIp())
+		assert.Empty(t, actual.GetSubject())
+	})
+	t.Run("With forwarded for header not resolved", func(t *testing.T) {
+		// the header set by the client is ignored
+		ctx := metadata.NewIncomingContext(context.TODO(), metadata.Pairs("x-forwarded-for", "192.168.1.10"))
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 52000}})
+
+		assert.Equal(t, "203.0.113.7", FromIncomingContext(ctx).GetClientIp())
+	})
+	t.Run("Without request id", func(t *testing.T) {
+		first, second := FromIncomingContext(context.TODO()), FromIncomingContext(context.TODO())
+		assert.NotEmpty(t, first.GetRequestId())
+		assert.NotEqual(t, first.GetRequestId(), second.GetRequestId())
+		assert.Empty(t, first.GetClientIp())
+	})
+}
+
+func TestNewOutgoingContext(t *testing.T) {
+	t.Run("With authenticated principal", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.TODO(), metadata.Pairs(RequestIDHeader, "request-1"))
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 52000}})
+		ctx = auth.NewContext(ctx, &auth.Principal{Subject: "operator-1"})
+
+		md, ok := metadata.FromOutgoingContext(NewOutgoingContext(ctx))
+		require.True(t, ok)
+		assert.Equal(t, []string{"operator-1"}, md.Get(SubjectHeader))
+		assert.Equal(t, []string{"10.0.0.1"}, md.Get(ClientIPHeader))
+		assert.Equal(t, []string{"request-1"}, md.Get(RequestIDHeader))
+	})
+	t.Run("Without principal", func(t *testing.T) {
+		md, ok := metadata.FromOutgoingContext(NewOutgoingContext(context.TODO()))
+		require.True(t, ok)
+		assert.Empty(t, md.Get(SubjectHeader))
+		assert.Empty(t, md.Get(ClientIPHeader))
+		assert.Len(t, md.Get(RequestIDHeader), 1)
+	})
+}
+
+func TestFromPropagatedContext(t *testing.T) {
+	t.Run("With propagated caller", func(t *testing.T) {
+		md := metadata.Pairs(SubjectHeader, "customer-1", ClientIPHeader, "10.0.0.1", RequestIDHeader, "request-1")
+		actual := FromPropagatedContext(metadata.NewIncomingContext(context.TODO(), md))
+
+		expected := &pb.Caller{Subject: "customer-1", ClientIp: "10.0.0.1", RequestId: "request-1"}
+		assert.True(t, proto.Equal(expected, actual))
+	})
+	t.Run("Without propagated caller", func(t *testing.T) {
+		assert.Nil(t, FromPropagatedContext(context.TODO()))
+		assert.Nil(t, FromPropagatedContext(metadata.NewIncomingContext(context.TODO(), metadata.MD{})))
+	})
+}
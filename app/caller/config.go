@@ -0,0 +1,48 @@
+package caller
+
+import (
+	"net/netip"
+
+	"github.com/caarlos0/env/v9"
+	"github.com/pkg/errors"
+)
+
+// Config defines the config of the client IP resolution
+type Config struct {
+	TrustedProxies []string `env:"TRUSTED_PROXIES" envDefault:"" envSeparator:","` // TrustedProxies are the addresses or the CIDR ranges of the proxies whose forwarded for header is honored, along with the local gateway
+}
+
+// LoadConfig fetches the Config from env vars
+func LoadConfig() *Config {
+	config := &Config{}
+	// all env vars are required
+	opts := env.Options{RequiredIfNoDef: true}
+	if err := env.ParseWithOptions(config, opts); err != nil {
+		panic(errors.Wrap(err, "unable to load environment variables"))
+	}
+
+	return config
+}
+
+// Validate checks that the trusted proxies are valid addresses or CIDR ranges
+func (c *Config) Validate() error {
+	_, err := c.trustedPrefixes()
+	return err
+}
+
+// trustedPrefixes returns the ranges of the trusted proxies. An address is a range of a single address
+func (c *Config) trustedPrefixes() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(c.TrustedProxies))
+	for _, proxy := range c.TrustedProxies {
+		if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		address, err := netip.ParseAddr(proxy)
+		if err != nil {
+			return nil, errors.Errorf("the trusted proxy (%s) is invalid", proxy)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(address.Unmap(), address.Unmap().BitLen()))
+	}
+	return prefixes, nil
+}
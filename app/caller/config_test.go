@@ -0,0 +1,38 @@
+package caller
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("With default values", func(t *testing.T) {
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.Empty(t, actual.TrustedProxies)
+	})
+	t.Run("With environment variables set", func(t *testing.T) {
+		assert.NoError(t, os.Setenv("TRUSTED_PROXIES", "10.0.0.0/8,192.168.1.1"))
+
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.1"}, actual.TrustedProxies)
+
+		// free resources
+		assert.NoError(t, os.Unsetenv("TRUSTED_PROXIES"))
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Run("With valid config", func(t *testing.T) {
+		config := &Config{TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1", "fd00::/8"}}
+		assert.NoError(t, config.Validate())
+	})
+	t.Run("With invalid trusted proxy", func(t *testing.T) {
+		config := &Config{TrustedProxies: []string{"10.0.0.0/8", "proxy.local"}}
+		assert.EqualError(t, config.Validate(), "the trusted proxy (proxy.local) is invalid")
+	})
+}
@@ -0,0 +1,167 @@
+package caller
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"connectrpc.com/connect"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// forwardedForHeader is the header set by the proxies, such as the HTTP/JSON gateway, with the addresses of the client and the proxies
+const forwardedForHeader = "X-Forwarded-For"
+
+// clientIPKey is the context key of the client IP resolved for the request
+type clientIPKey struct{}
+
+// ClientIPResolver resolves the IP address of the clients of the requests.
+// The forwarded for header can be set by any client, hence it is only honored when the request comes from a trusted proxy:
+// the local HTTP/JSON gateway or one of the configured proxies. The client is then the right-most address of the header
+// which is not a trusted proxy, the addresses on its left being set by the client itself.
+type ClientIPResolver struct {
+	trustedProxies []netip.Prefix
+}
+
+// NewClientIPResolver creates an instance of ClientIPResolver
+func NewClientIPResolver(config *Config) (*ClientIPResolver, error) {
+	trustedProxies, err := config.trustedPrefixes()
+	if err != nil {
+		return nil, err
+	}
+	return &ClientIPResolver{trustedProxies: trustedProxies}, nil
+}
+
+// ClientIP returns the IP address of the client of a request received from a given peer address with the given forwarded for headers
+func (r *ClientIPResolver) ClientIP(peerAddress string, forwardedFor []string) string {
+	clientIP := hostOf(peerAddress)
+	if !r.trusts(clientIP) {
+		return clientIP
+	}
+
+	// walk the forwarded for addresses from the nearest proxy to the client
+	var hops []string
+	for _, value := range forwardedFor {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	for index := len(hops) - 1; index >= 0; index-- {
+		hop := strings.TrimSpace(hops[index])
+		if _, err := netip.ParseAddr(hop); err != nil {
+			// the addresses on the left of an invalid address cannot be trusted
+			return clientIP
+		}
+		clientIP = hop
+		if !r.trusts(hop) {
+			return clientIP
+		}
+	}
+	return clientIP
+}
+
+// UnaryServerInterceptor returns the gRPC interceptor resolving the client IP of the unary calls. The client IP is put into the call context
+func (r *ClientIPResolver) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(r.resolveIncoming(ctx), req)
+	}
+}
+
+// StreamServerInterceptor returns the gRPC interceptor resolving the client IP of the streaming calls. The client IP is put into the stream context
+func (r *ClientIPResolver) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &resolvedStream{ServerStream: stream, ctx: r.resolveIncoming(stream.Context())})
+	}
+}
+
+// ConnectInterceptor returns the Connect interceptor resolving the client IP of the calls of a Connect handler. The client IP is put into the call context
+func (r *ClientIPResolver) ConnectInterceptor() connect.Interceptor {
+	return &connectInterceptor{resolver: r}
+}
+
+// Middleware returns the http middleware resolving the client IP of the requests served by a given handler, e.g. the GraphQL requests
+// which do not go through the gRPC or Connect interceptors. The client IP is put into the request context
+func (r *ClientIPResolver) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		clientIP := r.ClientIP(request.RemoteAddr, request.Header.Values(forwardedForHeader))
+		next.ServeHTTP(w, request.WithContext(context.WithValue(request.Context(), clientIPKey{}, clientIP)))
+	})
+}
+
+// resolveIncoming resolves the client IP of a gRPC call from its peer and its incoming metadata and returns the context carrying it
+func (r *ClientIPResolver) resolveIncoming(ctx context.Context) context.Context {
+	var peerAddress string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		peerAddress = p.Addr.String()
+	}
+	forwardedFor := metadata.ValueFromIncomingContext(ctx, strings.ToLower(forwardedForHeader))
+	return context.WithValue(ctx, clientIPKey{}, r.ClientIP(peerAddress, forwardedFor))
+}
+
+// trusts checks whether a given address is the one of a trusted proxy. The loopback addresses are the ones of the local gateway
+func (r *ClientIPResolver) trusts(address string) bool {
+	ip, err := netip.ParseAddr(address)
+	if err != nil {
+		return false
+	}
+	ip = ip.Unmap()
+	if ip.IsLoopback() {
+		return true
+	}
+	for _, prefix := range r.trustedProxies {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostOf returns the host of a given peer address, or the address itself when it has no port
+func hostOf(address string) string {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return address
+	}
+	return host
+}
+
+// resolvedStream is a gRPC server stream whose context carries the client IP of the call
+type resolvedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the context of the stream
+func (s *resolvedStream) Context() context.Context {
+	return s.ctx
+}
+
+// connectInterceptor resolves the client IP of the calls of a Connect handler
+type connectInterceptor struct {
+	resolver *ClientIPResolver
+}
+
+var _ connect.Interceptor = (*connectInterceptor)(nil)
+
+// WrapUnary resolves the client IP of the unary calls
+func (i *connectInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, request connect.AnyRequest) (connect.AnyResponse, error) {
+		clientIP := i.resolver.ClientIP(request.Peer().Addr, request.Header().Values(forwardedForHeader))
+		return next(context.WithValue(ctx, clientIPKey{}, clientIP), request)
+	}
+}
+
+// WrapStreamingClient leaves the client streams unchanged
+func (i *connectInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler resolves the client IP of the streaming calls
+func (i *connectInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		clientIP := i.resolver.ClientIP(conn.Peer().Addr, conn.RequestHeader().Values(forwardedForHeader))
+		return next(context.WithValue(ctx, clientIPKey{}, clientIP), conn)
+	}
+}
@@ -0,0 +1,132 @@
+package caller
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestClientIP(t *testing.T) {
+	resolver, err := NewClientIPResolver(&Config{TrustedProxies: []string{"10.0.0.0/8"}})
+	require.NoError(t, err)
+
+	t.Run("With direct request", func(t *testing.T) {
+		assert.Equal(t, "203.0.113.7", resolver.ClientIP("203.0.113.7:52000", nil))
+	})
+	t.Run("With spoofed forwarded for header", func(t *testing.T) {
+		// a client which is not a trusted proxy cannot set its client IP
+		assert.Equal(t, "203.0.113.7", resolver.ClientIP("203.0.113.7:52000", []string{"198.51.100.1"}))
+	})
+	t.Run("With request forwarded by the local gateway", func(t *testing.T) {
+		assert.Equal(t, "198.51.100.1", resolver.ClientIP("127.0.0.1:52000", []string{"198.51.100.1"}))
+		assert.Equal(t, "198.51.100.1", resolver.ClientIP("[::1]:52000", []string{"198.51.100.1"}))
+	})
+	t.Run("With spoofed forwarded for header appended by the local gateway", func(t *testing.T) {
+		// the gateway appends the address of the client to the header the client sent
+		assert.Equal(t, "198.51.100.1", resolver.ClientIP("127.0.0.1:52000", []string{"192.0.2.66, 198.51.100.1"}))
+	})
+	t.Run("With request forwarded by trusted proxies", func(t *testing.T) {
+		assert.Equal(t, "198.51.100.1", resolver.ClientIP("127.0.0.1:52000", []string{"192.0.2.66, 198.51.100.1, 10.1.2.3", "10.0.0.4"}))
+	})
+	t.Run("With request forwarded by trusted proxies only", func(t *testing.T) {
+		assert.Equal(t, "10.1.2.3", resolver.ClientIP("127.0.0.1:52000", []string{"10.1.2.3"}))
+	})
+	t.Run("With invalid forwarded address", func(t *testing.T) {
+		assert.Equal(t, "10.1.2.3", resolver.ClientIP("127.0.0.1:52000", []string{"192.0.2.66, not-an-ip, 10.1.2.3"}))
+	})
+	t.Run("Without peer", func(t *testing.T) {
+		assert.Empty(t, resolver.ClientIP("", []string{"198.51.100.1"}))
+	})
+}
+
+func TestNewClientIPResolver(t *testing.T) {
+	t.Run("With invalid trusted proxy", func(t *testing.T) {
+		resolver, err := NewClientIPResolver(&Config{TrustedProxies: []string{"proxy.local"}})
+		assert.EqualError(t, err, "the trusted proxy (proxy.local) is invalid")
+		assert.Nil(t, resolver)
+	})
+}
+
+func TestClientIPInterceptors(t *testing.T) {
+	resolver, err := NewClientIPResolver(&Config{})
+	require.NoError(t, err)
+
+	t.Run("With unary call with spoofed forwarded for header", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.TODO(), metadata.Pairs("x-forwarded-for", "198.51.100.1"))
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 52000}})
+
+		var actual string
+		_, err := resolver.UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ any) (any, error) {
+			actual = FromIncomingContext(ctx).GetClientIp()
+			return nil, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "203.0.113.7", actual)
+	})
+	t.Run("With streaming call forwarded by the local gateway", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.TODO(), metadata.Pairs("x-forwarded-for", "198.51.100.1"))
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 52000}})
+
+		var actual string
+		err := resolver.StreamServerInterceptor()(nil, &resolvedStream{ctx: ctx}, &grpc.StreamServerInfo{}, func(_ any, stream grpc.ServerStream) error {
+			actual = FromIncomingContext(stream.Context()).GetClientIp()
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "198.51.100.1", actual)
+	})
+	t.Run("With Connect call forwarded by the local gateway", func(t *testing.T) {
+		var actual string
+		handler := connect.NewUnaryHandler("/test.v1.TestService/Call",
+			func(ctx context.Context, _ *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+				actual = FromIncomingContext(ctx).GetClientIp()
+				return connect.NewResponse(new(emptypb.Empty)), nil
+			},
+			connect.WithInterceptors(resolver.ConnectInterceptor()),
+		)
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		client := connect.NewClient[emptypb.Empty, emptypb.Empty](http.DefaultClient, server.URL+"/test.v1.TestService/Call")
+		request := connect.NewRequest(new(emptypb.Empty))
+		request.Header().Set("X-Forwarded-For", "198.51.100.1")
+		_, err := client.CallUnary(context.TODO(), request)
+		require.NoError(t, err)
+		// the test server listens on the loopback, hence it is the local gateway
+		assert.Equal(t, "198.51.100.1", actual)
+	})
+	t.Run("With HTTP request forwarded by a trusted proxy", func(t *testing.T) {
+		var actual string
+		handler := resolver.Middleware(http.HandlerFunc(func(_ http.ResponseWriter, request *http.Request) {
+			actual = FromIncomingContext(request.Context()).GetClientIp()
+		}))
+
+		request := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+		request.RemoteAddr = "127.0.0.1:52000"
+		request.Header.Set("X-Forwarded-For", "198.51.100.1")
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+		assert.Equal(t, "198.51.100.1", actual)
+	})
+	t.Run("With HTTP request with spoofed forwarded for header", func(t *testing.T) {
+		var actual string
+		handler := resolver.Middleware(http.HandlerFunc(func(_ http.ResponseWriter, request *http.Request) {
+			actual = FromIncomingContext(request.Context()).GetClientIp()
+		}))
+
+		request := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+		request.RemoteAddr = "203.0.113.7:52000"
+		request.Header.Set("X-Forwarded-For", "198.51.100.1")
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+		assert.Equal(t, "203.0.113.7", actual)
+	})
+}
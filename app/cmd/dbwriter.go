@@ -6,12 +6,15 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	gopack "github.com/tochemey/gopack/grpc"
 
 	"github.com/tochemey/cos-go-sample/app/dbwriter"
 	"github.com/tochemey/cos-go-sample/app/grpconfig"
+	"github.com/tochemey/cos-go-sample/app/health"
+	"github.com/tochemey/cos-go-sample/app/lifecycle"
 	"github.com/tochemey/cos-go-sample/app/log"
+	"github.com/tochemey/cos-go-sample/app/pii"
 	"github.com/tochemey/cos-go-sample/app/storage"
+	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
 )
 
 // dbWriterCmd represents the dbwriter command
@@ -25,32 +28,96 @@ var dbWriterCmd = &cobra.Command{
 		config := grpconfig.LoadConfig()
 		// get the dataStore
 		dataStore := storage.New(ctx)
+		// create the cipher decrypting the personal data of the accounts
+		var cipher *pii.Cipher
+		piiConfig := pii.LoadConfig()
+		if piiConfig.Enabled {
+			keyStore, err := pii.NewLocalKeyStore(piiConfig.KeyStoreDir)
+			if err != nil {
+				log.Panic(errors.Wrap(err, "failed to create the key store"))
+			}
+			cipher = pii.NewCipher(keyStore)
+		}
 		// create the service
-		service, err := dbwriter.NewService(dataStore)
+		service, err := dbwriter.NewService(dataStore, cipher)
 		// log the error in case there is one and panic
 		if err != nil {
 			log.Panic(errors.Wrap(err, "failed to create db writer service"))
 		}
-		// create the grpc server
-		grpcServer, err := gopack.
-			NewServerBuilderFromConfig(config.GetGrpcConfig()).
+		// create the health checker. The read side handler is serving when the read model database is available
+		healthConfig := health.LoadConfig()
+		checker := health.NewChecker(healthConfig)
+		checker.AddDependency("postgres", dataStore.Ping)
+		checker.AddService(cospb.ReadSideHandlerService_ServiceDesc.ServiceName, "postgres")
+		healthServer := health.NewServer(healthConfig, checker)
+		// create the grpc server builder. The server only accepts the TLS connections of the allowed peers when TLS is enabled
+		builder, err := grpconfig.NewServerBuilder(config)
+		// log the error in case there is one and panic
+		if err != nil {
+			log.Panic(errors.Wrap(err, "failed to configure the grpc server TLS"))
+		}
+		// create the grpc server. It is stopped by the lifecycle manager
+		grpcServer, err := builder.
 			WithService(service).
-			WithShutdownHook(func(ctx context.Context) error {
-				return dataStore.Shutdown(ctx)
-			}).
+			WithService(checker).
 			Build()
 		// log the error in case there is one and panic
 		if err != nil {
 			log.Panic(errors.Wrap(err, "failed to build a grpc server"))
 		}
-		// start the service
-		if err := grpcServer.Start(ctx); err != nil {
-			log.Panic(errors.Wrap(err, "failed to create a grpc service"))
+
+		// start the components after their dependencies and stop them in the reverse order on termination:
+		// the service is reported not serving and the in-flight RPCs are drained, before the database is closed
+		manager := lifecycle.NewManager(lifecycle.LoadConfig())
+		manager.Add(
+			lifecycle.Component{Name: "database", Stop: dataStore.Shutdown},
+			lifecycle.Component{
+				Name: "health server",
+				Start: func(context.Context) error {
+					if err := healthServer.Start(); err != nil {
+						return err
+					}
+					log.Infof("accounts dbwriter health server started on (:%d)", healthConfig.Port)
+					return nil
+				},
+				Stop: healthServer.Stop,
+			},
+			lifecycle.Component{
+				Name: "grpc server",
+				Start: func(ctx context.Context) error {
+					if err := grpcServer.Start(ctx); err != nil {
+						return err
+					}
+					log.Infof("accounts dbwriter service started on (%s)", fmt.Sprintf(":%d", config.GrpcPort))
+					return nil
+				},
+				Stop: func(ctx context.Context) error {
+					if err := grpconfig.GracefulStop(ctx, grpcServer.GetServer()); err != nil {
+						return err
+					}
+					// stop the metrics and the tracer of the server
+					return grpcServer.Stop(ctx)
+				},
+			},
+			// the checker is started last so that the service is only reported serving once started, and stopped first
+			lifecycle.Component{
+				Name: "health checker",
+				Start: func(ctx context.Context) error {
+					checker.Start(ctx)
+					return nil
+				},
+				Stop: func(ctx context.Context) error {
+					checker.Stop()
+					return manager.Drain(ctx)
+				},
+			},
+		)
+
+		// run until termination
+		if err := manager.Run(ctx); err != nil {
+			log.Panic(errors.Wrap(err, "the dbwriter service did not shut down gracefully"))
 		}
-		// add some information logging
-		log.Infof("accounts dbwriter service started on (%s)", fmt.Sprintf(":%d", config.GrpcPort))
-		// await for termination
-		grpcServer.AwaitTermination(ctx)
+		log.Info("accounts dbwriter service stopped")
 	},
 }
 
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/tochemey/cos-go-sample/app/cos"
+	"github.com/tochemey/cos-go-sample/app/dormancy"
+	"github.com/tochemey/cos-go-sample/app/grpconfig"
+	"github.com/tochemey/cos-go-sample/app/log"
+	"github.com/tochemey/cos-go-sample/app/storage"
+)
+
+// dormancy sweep command flags
+var dormancySweepOutput string
+
+// dormancySweepCmd represents the dormancy-sweep command
+var dormancySweepCmd = &cobra.Command{
+	Use:   "dormancy-sweep",
+	Short: "Mark dormant the accounts without customer activity and report the affected accounts",
+	Run: func(cmd *cobra.Command, _ []string) {
+		// create the base context cancelled on termination
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		// load the dormancy config
+		config := dormancy.LoadConfig()
+		// get the dataStore
+		dataStore := storage.New(ctx)
+		// free resources
+		defer func() {
+			if err := dataStore.Shutdown(context.Background()); err != nil {
+				log.Error(errors.Wrap(err, "failed to shutdown the data store"))
+			}
+		}()
+
+		// create the TLS configuration of the connections to CoS
+		cosTLSConfig, err := grpconfig.NewClientTLSConfig(config.CosTLS)
+		if err != nil {
+			log.Fatal(errors.Wrap(err, "failed to configure the CoS connections TLS"))
+		}
+		// create the cos client
+		cosClient, err := cos.NewClient(config.CosHost, config.CosPort, cosTLSConfig)
+		// handle the error
+		if err != nil {
+			log.Fatal(errors.Wrap(err, "failed to create the CoS client"))
+		}
+		defer func() {
+			if err := cosClient.Close(); err != nil {
+				log.Error(err)
+			}
+		}()
+
+		// create the sweeper
+		sweeper, err := dormancy.New(dataStore, cosClient, config)
+		// log the error in case there is one and panic
+		if err != nil {
+			log.Panic(errors.Wrap(err, "failed to create the dormancy sweeper"))
+		}
+
+		// sweep the inactive accounts. The report of a partial sweep is still written
+		report, sweepErr := sweeper.Sweep(ctx)
+		if sweepErr != nil {
+			log.Error(errors.Wrap(sweepErr, "the dormancy sweep did not complete"))
+		}
+
+		// set the output
+		var output io.Writer = os.Stdout
+		if dormancySweepOutput != "" {
+			file, err := os.Create(dormancySweepOutput) // #nosec G304
+			if err != nil {
+				log.Fatal(errors.Wrap(err, "failed to create the output file"))
+			}
+			defer file.Close()
+			output = file
+		}
+
+		// write the report
+		if err := report.WriteCSV(output); err != nil {
+			log.Fatal(errors.Wrap(err, "failed to write the dormancy report"))
+		}
+
+		log.Infof("dormancy sweep of the accounts inactive since (%s): %d account(s) affected, %d failure(s)",
+			report.InactiveSince, len(report.Entries), report.Failed())
+	},
+}
+
+func init() {
+	dormancySweepCmd.Flags().StringVarP(&dormancySweepOutput, "output", "o", "", "the report file. The report is written to the standard output when not set")
+	rootCmd.AddCommand(dormancySweepCmd)
+}
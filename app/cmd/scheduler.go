@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"os/signal"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/tochemey/cos-go-sample/app/cos"
+	"github.com/tochemey/cos-go-sample/app/grpconfig"
+	"github.com/tochemey/cos-go-sample/app/log"
+	"github.com/tochemey/cos-go-sample/app/scheduler"
+	"github.com/tochemey/cos-go-sample/app/storage"
+)
+
+// schedulerCmd represents the scheduler command
+var schedulerCmd = &cobra.Command{
+	Use:   "scheduler",
+	Short: "Run the standing orders scheduler",
+	Run: func(cmd *cobra.Command, _ []string) {
+		// create the base context cancelled on termination
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+		// load the scheduler config
+		config := scheduler.LoadConfig()
+		// get the dataStore
+		dataStore := storage.New(ctx)
+		// create the TLS configuration of the connections to CoS
+		cosTLSConfig, err := grpconfig.NewClientTLSConfig(config.CosTLS)
+		if err != nil {
+			log.Fatal(errors.Wrap(err, "failed to configure the CoS connections TLS"))
+		}
+		// create the cos client
+		cosClient, err := cos.NewClient(config.CosHost, config.CosPort, cosTLSConfig)
+		// handle the error
+		if err != nil {
+			log.Fatal(errors.Wrap(err, "failed to create the CoS client"))
+		}
+
+		// create the scheduler
+		standingOrdersScheduler, err := scheduler.New(dataStore, cosClient, config)
+		// log the error in case there is one and panic
+		if err != nil {
+			log.Panic(errors.Wrap(err, "failed to create the standing orders scheduler"))
+		}
+
+		log.Infof("standing orders scheduler started with a poll interval of (%s)", config.PollInterval)
+		// run the scheduler until termination
+		standingOrdersScheduler.Run(ctx)
+
+		// free resources
+		if err := cosClient.Close(); err != nil {
+			log.Error(err)
+		}
+		if err := dataStore.Shutdown(context.Background()); err != nil {
+			log.Error(errors.Wrap(err, "failed to shutdown the data store"))
+		}
+		log.Info("standing orders scheduler stopped")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schedulerCmd)
+}
@@ -2,18 +2,40 @@ package cmd
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 
+	"connectrpc.com/connect"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	gopack "github.com/tochemey/gopack/grpc"
+	"github.com/tochemey/gopack/otel/trace"
 
+	"github.com/tochemey/cos-go-sample/app/auth"
+	"github.com/tochemey/cos-go-sample/app/caller"
+	"github.com/tochemey/cos-go-sample/app/connectapi"
 	"github.com/tochemey/cos-go-sample/app/cos"
+	"github.com/tochemey/cos-go-sample/app/gateway"
+	"github.com/tochemey/cos-go-sample/app/graphapi"
+	"github.com/tochemey/cos-go-sample/app/grpconfig"
+	"github.com/tochemey/cos-go-sample/app/health"
+	"github.com/tochemey/cos-go-sample/app/lifecycle"
 	"github.com/tochemey/cos-go-sample/app/log"
+	"github.com/tochemey/cos-go-sample/app/pii"
+	"github.com/tochemey/cos-go-sample/app/ratelimit"
+	"github.com/tochemey/cos-go-sample/app/readmodel"
 	"github.com/tochemey/cos-go-sample/app/service"
+	"github.com/tochemey/cos-go-sample/app/statement"
+	"github.com/tochemey/cos-go-sample/app/storage"
 	"github.com/tochemey/cos-go-sample/app/subscription"
+	"github.com/tochemey/cos-go-sample/app/tenant"
+	"github.com/tochemey/cos-go-sample/app/validation"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
 )
 
+// serve command flags
+var serveGraphQL bool
+
 // serveCmd represents the runApi command
 var serveCmd = &cobra.Command{
 	Use:   "serve",
@@ -23,55 +45,282 @@ var serveCmd = &cobra.Command{
 		ctx := cmd.Context()
 		// load the service config
 		config := service.LoadConfig()
+		// create the TLS configuration of the connections to CoS
+		cosTLSConfig, err := grpconfig.NewClientTLSConfig(config.CosTLS)
+		if err != nil {
+			log.Fatal(errors.Wrap(err, "failed to configure the CoS connections TLS"))
+		}
 		// create the cos client
-		cosClient, err := cos.NewClient(config.CosHost, config.CosPort)
+		cosClient, err := cos.NewClient(config.CosHost, config.CosPort, cosTLSConfig)
 		// handle the error
 		if err != nil {
 			log.Fatal(errors.Wrap(err, "failed to create the CoS client"))
 		}
 
+		// get the dataStore used to build the statements and to read the accounts from the read model
+		dataStore := storage.New(ctx)
+		// create the statements generator
+		statementGenerator := statement.NewGenerator(dataStore, statement.LoadConfig().Currency)
+		// create the reader of the eventually consistent accounts reads
+		accountReader := readmodel.NewReader(dataStore, readmodel.LoadConfig())
+
+		// create the hub fanning out the CoS events to the accounts watchers
+		hub := subscription.NewHub(config.WatchBufferSize)
+
+		// create subscription handler and manager for CoS event streaming.
+		// Only the events of the watched accounts are streamed, through per-account subscriptions shared by their watchers
+		subHandler := subscription.NewSubscriptionHandler(nil, hub)
+		subManager, err := subscription.NewManager(config.CosHost, config.CosPort, cosTLSConfig, subHandler)
+		if err != nil {
+			log.Fatal(errors.Wrap(err, "failed to create subscription manager"))
+		}
+
+		// create the authenticator validating the bearer tokens and the authorizer enforcing the roles permissions
+		var (
+			authenticator *auth.Authenticator
+			authorizer    *auth.Authorizer
+		)
+		authConfig := auth.LoadConfig()
+		if authConfig.Enabled {
+			authenticator, err = auth.NewAuthenticator(ctx, authConfig)
+			if err != nil {
+				log.Fatal(errors.Wrap(err, "failed to create the authenticator"))
+			}
+			authorizer = auth.NewAuthorizer()
+		} else {
+			log.Warn("the authentication is disabled, every request is allowed")
+		}
+
+		// create the limiter throttling the api calls
+		var limiter *ratelimit.Limiter
+		rateLimitConfig := ratelimit.LoadConfig()
+		if rateLimitConfig.Enabled {
+			if err := rateLimitConfig.Validate(); err != nil {
+				log.Fatal(errors.Wrap(err, "invalid rate limit config"))
+			}
+			var backend ratelimit.Backend = ratelimit.NewMemoryBackend()
+			if rateLimitConfig.Backend == ratelimit.BackendPostgres {
+				backend = ratelimit.NewPostgresBackend(dataStore)
+			}
+			limiter = ratelimit.NewLimiter(rateLimitConfig, backend)
+		}
+
+		// create the cipher encrypting the personal data of the customers with their data keys
+		var cipher *pii.Cipher
+		piiConfig := pii.LoadConfig()
+		if piiConfig.Enabled {
+			keyStore, err := pii.NewLocalKeyStore(piiConfig.KeyStoreDir)
+			if err != nil {
+				log.Fatal(errors.Wrap(err, "failed to create the key store"))
+			}
+			cipher = pii.NewCipher(keyStore)
+		}
+
+		// create the resolver isolating the requests by tenant
+		var tenants *tenant.Resolver
+		tenancyConfig := tenant.LoadConfig()
+		if tenancyConfig.Enabled {
+			if err := tenancyConfig.Validate(); err != nil {
+				log.Fatal(errors.Wrap(err, "invalid multi-tenancy config"))
+			}
+			tenants = tenant.NewResolver(tenancyConfig.Tenants...)
+		}
+
+		// create the validator of the requests. It validates the api calls through the interceptors
+		// and the bulk post entries and the GraphQL mutations, which do not go through them
+		validator, err := validation.NewValidator()
+		if err != nil {
+			log.Fatal(err)
+		}
+
 		// create an instance of the apis service
-		apisService := service.NewService(cosClient)
+		apisService := service.NewService(cosClient, statementGenerator, accountReader, subManager, authorizer, cipher, tenants, limiter, validator)
 		// get the grpc config
 		grpcConfig := config.GRPCConfig.GetGrpcConfig()
 
-		// create subscription handler and manager for CoS event streaming
-		subHandler := subscription.NewSubscriptionHandler(nil)
-		subManager, err := subscription.NewManager(config.CosHost, config.CosPort, subHandler)
+		// create the TLS credentials of the api server
+		serverCredentials, err := grpconfig.NewServerCredentials(config.GRPCConfig.TLS)
 		if err != nil {
-			log.Fatal(errors.Wrap(err, "failed to create subscription manager"))
+			log.Fatal(errors.Wrap(err, "failed to configure the api server TLS"))
+		}
+		var serverTLSConfig, loopbackTLSConfig *tls.Config
+		if serverCredentials != nil {
+			serverTLSConfig, loopbackTLSConfig = serverCredentials.TLSConfig(), serverCredentials.LoopbackTLSConfig()
+		}
+
+		// create the HTTP/JSON gateway proxying to the grpc service
+		gatewayConfig := gateway.LoadConfig()
+		httpGateway, err := gateway.New(ctx, int(grpcConfig.GrpcPort), loopbackTLSConfig, gatewayConfig)
+		if err != nil {
+			log.Fatal(errors.Wrap(err, "failed to create the HTTP/JSON gateway"))
 		}
 
-		if err := subManager.Start(ctx); err != nil {
-			log.Fatal(errors.Wrap(err, "failed to start CoS subscription"))
+		// create the resolver of the client IP recorded in the audit trail
+		clientIPResolver, err := caller.NewClientIPResolver(caller.LoadConfig())
+		if err != nil {
+			log.Fatal(errors.Wrap(err, "invalid client IP config"))
 		}
 
-		log.Info("CoS subscription started: subscribeAll")
+		// create the optional GraphQL server reading the read model and executing the mutations through the apis service
+		var graphQLServer *graphapi.Server
+		graphQLConfig := graphapi.LoadConfig()
+		if serveGraphQL {
+			graphQLServer, err = graphapi.NewServer(graphQLConfig, dataStore, graphapi.NewResolver(dataStore, apisService, authorizer, tenants, limiter, validator), authenticator, clientIPResolver)
+			if err != nil {
+				log.Fatal(errors.Wrap(err, "failed to create the GraphQL server"))
+			}
+		}
+
+		// create the tracer. The tracing is not left to the grpc server because the requests are served by the api server
+		var tracer *trace.Provider
+		if grpcConfig.TraceEnabled {
+			tracer = trace.NewProvider(grpcConfig.TraceURL, grpcConfig.ServiceName)
+		}
 
-		// create the grpc server with shutdown hook to unsubscribe on stop
-		grpcServer, err := gopack.
-			NewServerBuilderFromConfig(grpcConfig).
+		// create the health checker probing the dependencies of the accounts api.
+		// The api is serving when the read model and CoS are available, the watch streams also depending on the subscriptions
+		healthConfig := health.LoadConfig()
+		checker := health.NewChecker(healthConfig)
+		checker.AddDependency("postgres", dataStore.Ping)
+		checker.AddDependency("cos", cosClient.Check)
+		checker.AddDependency("subscriptions", subManager.Check)
+		checker.AddService(pb.BankAccountService_ServiceDesc.ServiceName, "postgres", "cos")
+		healthServer := health.NewServer(healthConfig, checker)
+
+		// create the grpc server builder, resolve the client IP of the calls, authenticate the accounts api calls,
+		// rate limit them and then validate their requests
+		builder := gopack.NewServerBuilderFromConfig(grpcConfig).
+			WithUnaryInterceptors(clientIPResolver.UnaryServerInterceptor()).
+			WithStreamInterceptors(clientIPResolver.StreamServerInterceptor())
+		serviceName := pb.BankAccountService_ServiceDesc.ServiceName
+		connectInterceptors := []connect.Interceptor{clientIPResolver.ConnectInterceptor()}
+		if authenticator != nil {
+			builder.
+				WithUnaryInterceptors(authenticator.UnaryServerInterceptor(serviceName)).
+				WithStreamInterceptors(authenticator.StreamServerInterceptor(serviceName))
+			connectInterceptors = append(connectInterceptors, authenticator.ConnectInterceptor())
+		}
+		if limiter != nil {
+			builder.
+				WithUnaryInterceptors(limiter.UnaryServerInterceptor(serviceName)).
+				WithStreamInterceptors(limiter.StreamServerInterceptor(serviceName))
+			connectInterceptors = append(connectInterceptors, limiter.ConnectInterceptor())
+		}
+		// the invalid requests are rejected before anything reaches CoS
+		builder.
+			WithUnaryInterceptors(validator.UnaryServerInterceptor(serviceName)).
+			WithStreamInterceptors(validator.StreamServerInterceptor(serviceName))
+		connectInterceptors = append(connectInterceptors, validator.ConnectInterceptor())
+		connectOptions := []connect.HandlerOption{connect.WithInterceptors(connectInterceptors...)}
+
+		// create the grpc server. It is stopped by the lifecycle manager
+		grpcServer, err := builder.
+			WithTracingEnabled(false).
 			WithService(apisService).
-			WithShutdownHook(func(ctx context.Context) error {
-				return subManager.Stop(ctx)
-			}).
+			WithService(checker).
 			Build()
 		// log the error in case there is one and panic
 		if err != nil {
 			log.Fatal(errors.Wrap(err, "failed to build a grpc server"))
 		}
-		// start the service
-		if err := grpcServer.Start(ctx); err != nil {
-			log.Fatal(errors.Wrap(err, "failed to create a grpc service"))
-		}
 
-		log.Infof("accounts service started on (%s)", fmt.Sprintf("%s:%d", grpcConfig.GrpcHost, grpcConfig.GrpcPort))
+		// serve the grpc server along the Connect handler to accept the Connect and gRPC-Web requests on the same port
+		apiServer := connectapi.NewServer(int(grpcConfig.GrpcPort), grpcServer.GetServer(), connectapi.NewHandler(apisService), grpcConfig.ServiceName, serverTLSConfig, connectOptions...)
 
-		// await for termination
-		grpcServer.AwaitTermination(ctx)
+		// start the components after their dependencies and stop them in the reverse order on termination:
+		// the service is reported not serving, the watches are ended, the requests are drained, and then the subscriptions, CoS connections and database are closed
+		manager := lifecycle.NewManager(lifecycle.LoadConfig())
+		manager.Add(
+			lifecycle.Component{Name: "database", Stop: dataStore.Shutdown},
+			lifecycle.Component{Name: "cos client", Stop: func(context.Context) error { return cosClient.Close() }},
+			lifecycle.Component{Name: "subscriptions", Stop: subManager.Stop},
+		)
+		if tracer != nil {
+			manager.Add(lifecycle.Component{Name: "tracer", Start: tracer.Start, Stop: tracer.Stop})
+		}
+		manager.Add(
+			lifecycle.Component{
+				Name: "health server",
+				Start: func(context.Context) error {
+					if err := healthServer.Start(); err != nil {
+						return err
+					}
+					log.Infof("accounts health server started on (:%d)", healthConfig.Port)
+					return nil
+				},
+				Stop: healthServer.Stop,
+			},
+			lifecycle.Component{
+				Name: "api server",
+				Start: func(context.Context) error {
+					if err := apiServer.Start(); err != nil {
+						return err
+					}
+					log.Infof("accounts service started on (%s)", fmt.Sprintf("%s:%d", grpcConfig.GrpcHost, grpcConfig.GrpcPort))
+					return nil
+				},
+				// the gRPC requests are served by the api server, the grpc server only has to release its resources once they are drained
+				Stop: func(ctx context.Context) error {
+					err := apiServer.Stop(ctx)
+					grpcServer.GetServer().Stop()
+					return err
+				},
+			},
+			lifecycle.Component{
+				Name: "gateway",
+				Start: func(context.Context) error {
+					if err := httpGateway.Start(); err != nil {
+						return err
+					}
+					log.Infof("accounts gateway started on (:%d)", gatewayConfig.Port)
+					return nil
+				},
+				Stop: httpGateway.Stop,
+			},
+		)
+		if graphQLServer != nil {
+			manager.Add(lifecycle.Component{
+				Name: "graphql server",
+				Start: func(context.Context) error {
+					if err := graphQLServer.Start(); err != nil {
+						return err
+					}
+					log.Infof("accounts GraphQL server started on (:%d%s)", graphQLConfig.Port, graphapi.Path)
+					return nil
+				},
+				Stop: graphQLServer.Stop,
+			})
+		}
+		// the watchers are released before the servers are drained, since the open watches would otherwise never end and block the drain
+		manager.Add(lifecycle.Component{
+			Name: "watches",
+			Stop: func(context.Context) error {
+				hub.Close()
+				return nil
+			},
+		})
+		// the checker is started last so that the service is only reported serving once started, and stopped first
+		manager.Add(lifecycle.Component{
+			Name: "health checker",
+			Start: func(ctx context.Context) error {
+				checker.Start(ctx)
+				return nil
+			},
+			Stop: func(ctx context.Context) error {
+				checker.Stop()
+				return manager.Drain(ctx)
+			},
+		})
+
+		// run until termination
+		if err := manager.Run(ctx); err != nil {
+			log.Fatal(errors.Wrap(err, "the accounts service did not shut down gracefully"))
+		}
+		log.Info("accounts service stopped")
 	},
 }
 
 func init() {
+	serveCmd.Flags().BoolVar(&serveGraphQL, "graphql", false, "serve the GraphQL api along the gRPC api")
 	rootCmd.AddCommand(serveCmd)
 }
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/tochemey/cos-go-sample/app/log"
+	"github.com/tochemey/cos-go-sample/app/statement"
+	"github.com/tochemey/cos-go-sample/app/storage"
+	"github.com/tochemey/cos-go-sample/app/tenant"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// statement command flags
+var (
+	statementAccountID string
+	statementTenantID  string
+	statementFrom      string
+	statementTo        string
+	statementFormat    string
+	statementOutput    string
+)
+
+// statementCmd represents the statement command
+var statementCmd = &cobra.Command{
+	Use:   "statement",
+	Short: "Generate an account statement from the read model",
+	Run: func(cmd *cobra.Command, _ []string) {
+		// create the base context
+		ctx := context.Background()
+
+		// parse the statement period. The end date is inclusive
+		startTime, err := time.Parse(time.DateOnly, statementFrom)
+		if err != nil {
+			log.Fatal(errors.Wrap(err, "invalid --from date"))
+		}
+		endDate, err := time.Parse(time.DateOnly, statementTo)
+		if err != nil {
+			log.Fatal(errors.Wrap(err, "invalid --to date"))
+		}
+		endTime := endDate.AddDate(0, 0, 1)
+
+		// parse the statement format
+		format, ok := pb.StatementFormat_value[strings.ToUpper(statementFormat)]
+		if !ok {
+			log.Fatalf("unsupported statement format (%s)", statementFormat)
+		}
+
+		// get the dataStore
+		dataStore := storage.New(ctx)
+		// free resources
+		defer func() {
+			if err := dataStore.Shutdown(ctx); err != nil {
+				log.Error(errors.Wrap(err, "failed to shutdown the data store"))
+			}
+		}()
+
+		// build the statement
+		generator := statement.NewGenerator(dataStore, statement.LoadConfig().Currency)
+		accountStatement, err := generator.Generate(ctx, statementTenantID, tenant.EntityID(statementTenantID, statementAccountID), startTime, endTime)
+		if err != nil {
+			log.Fatal(errors.Wrap(err, "failed to generate the statement"))
+		}
+
+		// set the output
+		var output io.Writer = os.Stdout
+		if statementOutput != "" {
+			file, err := os.Create(statementOutput) // #nosec G304
+			if err != nil {
+				log.Fatal(errors.Wrap(err, "failed to create the output file"))
+			}
+			defer file.Close()
+			output = file
+		}
+
+		// render the statement
+		if err := statement.Render(output, accountStatement, pb.StatementFormat(format)); err != nil {
+			log.Fatal(errors.Wrap(err, "failed to render the statement"))
+		}
+	},
+}
+
+func init() {
+	statementCmd.Flags().StringVar(&statementAccountID, "account-id", "", "the account id")
+	statementCmd.Flags().StringVar(&statementTenantID, "tenant", "", "the tenant of the account when the multi-tenancy is enabled")
+	statementCmd.Flags().StringVar(&statementFrom, "from", "", "the first day of the statement period (YYYY-MM-DD)")
+	statementCmd.Flags().StringVar(&statementTo, "to", "", "the last day of the statement period (YYYY-MM-DD)")
+	statementCmd.Flags().StringVar(&statementFormat, "format", "csv", "the statement format: csv, json or camt053")
+	statementCmd.Flags().StringVarP(&statementOutput, "output", "o", "", "the output file. The statement is written to the standard output when not set")
+	_ = statementCmd.MarkFlagRequired("account-id")
+	_ = statementCmd.MarkFlagRequired("from")
+	_ = statementCmd.MarkFlagRequired("to")
+	rootCmd.AddCommand(statementCmd)
+}
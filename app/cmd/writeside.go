@@ -8,12 +8,17 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	gopack "github.com/tochemey/gopack/grpc"
 
 	"github.com/tochemey/cos-go-sample/app/grpconfig"
+	"github.com/tochemey/cos-go-sample/app/health"
+	"github.com/tochemey/cos-go-sample/app/lifecycle"
+	"github.com/tochemey/cos-go-sample/app/validation"
 	"github.com/tochemey/cos-go-sample/app/writeside"
+	"github.com/tochemey/cos-go-sample/app/writeside/accounttypes"
 	"github.com/tochemey/cos-go-sample/app/writeside/commands"
 	"github.com/tochemey/cos-go-sample/app/writeside/events"
+	"github.com/tochemey/cos-go-sample/app/writeside/fees"
+	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
 )
 
 // writesideCmd represents the runWriteside command
@@ -25,30 +30,96 @@ var writesideCmd = &cobra.Command{
 		ctx := context.Background()
 		// load the grpc config
 		config := grpconfig.LoadConfig()
+		// load the fee schedule
+		feeSchedule := fees.LoadSchedule()
+		// load the account types rules
+		accountRules := accounttypes.LoadRegistry()
 		// create the commands dispatcher
-		commandsDispatcher := commands.NewDispatcher()
+		commandsDispatcher := commands.NewDispatcher(feeSchedule, accountRules)
 		// create the events dispatcher
 		eventsDispatcher := events.NewDispatcher()
+		// create the standing orders commands and events dispatchers
+		standingOrderCommandsDispatcher := commands.NewStandingOrderDispatcher()
+		standingOrderEventsDispatcher := events.NewStandingOrderDispatcher()
+		// create the validator rejecting the invalid commands
+		validator, err := validation.NewValidator()
+		if err != nil {
+			log.Panic(err)
+		}
 		// create the instance of the service
-		service := writeside.NewHandlerService(commandsDispatcher, eventsDispatcher)
-		// create the grpc server
-		grpcServer, err := gopack.
-			NewServerBuilderFromConfig(config.GetGrpcConfig()).
-			WithService(service).Build()
+		service := writeside.NewHandlerService(commandsDispatcher, eventsDispatcher, standingOrderCommandsDispatcher, standingOrderEventsDispatcher, validator)
+		// create the health checker. The commands and events handler has no dependency
+		healthConfig := health.LoadConfig()
+		checker := health.NewChecker(healthConfig)
+		checker.AddService(cospb.WriteSideHandlerService_ServiceDesc.ServiceName)
+		healthServer := health.NewServer(healthConfig, checker)
+		// create the grpc server builder. The server only accepts the TLS connections of the allowed peers when TLS is enabled
+		builder, err := grpconfig.NewServerBuilder(config)
 		// log the error in case there is one and panic
 		if err != nil {
-			log.Panic(errors.Wrap(err, "failed to build a grpc server"))
+			log.Panic(errors.Wrap(err, "failed to configure the grpc server TLS"))
 		}
-		// start the service
-		if err := grpcServer.Start(ctx); err != nil {
-			log.Panic(errors.Wrap(err, "failed to create a grpc service"))
+		// create the grpc server. It is stopped by the lifecycle manager
+		grpcServer, err := builder.
+			WithService(service).
+			WithService(checker).
+			Build()
+		// log the error in case there is one and panic
+		if err != nil {
+			log.Panic(errors.Wrap(err, "failed to build a grpc server"))
 		}
 
-		// add some information logging
-		log.Infof("accounts writeside service started on (%s)", fmt.Sprintf(":%d", config.GrpcPort))
+		// start the components after their dependencies and stop them in the reverse order on termination:
+		// the service is reported not serving and the in-flight RPCs are drained
+		manager := lifecycle.NewManager(lifecycle.LoadConfig())
+		manager.Add(
+			lifecycle.Component{
+				Name: "health server",
+				Start: func(context.Context) error {
+					if err := healthServer.Start(); err != nil {
+						return err
+					}
+					log.Infof("accounts writeside health server started on (:%d)", healthConfig.Port)
+					return nil
+				},
+				Stop: healthServer.Stop,
+			},
+			lifecycle.Component{
+				Name: "grpc server",
+				Start: func(ctx context.Context) error {
+					if err := grpcServer.Start(ctx); err != nil {
+						return err
+					}
+					log.Infof("accounts writeside service started on (%s)", fmt.Sprintf(":%d", config.GrpcPort))
+					return nil
+				},
+				Stop: func(ctx context.Context) error {
+					if err := grpconfig.GracefulStop(ctx, grpcServer.GetServer()); err != nil {
+						return err
+					}
+					// stop the metrics and the tracer of the server
+					return grpcServer.Stop(ctx)
+				},
+			},
+			// the checker is started last so that the service is only reported serving once started, and stopped first
+			lifecycle.Component{
+				Name: "health checker",
+				Start: func(ctx context.Context) error {
+					checker.Start(ctx)
+					return nil
+				},
+				Stop: func(ctx context.Context) error {
+					checker.Stop()
+					return manager.Drain(ctx)
+				},
+			},
+		)
 
-		// await for termination
-		grpcServer.AwaitTermination(ctx)
+		// run until termination
+		if err := manager.Run(ctx); err != nil {
+			log.Panic(errors.Wrap(err, "the writeside service did not shut down gracefully"))
+		}
+		log.Info("accounts writeside service stopped")
 	},
 }
 
@@ -0,0 +1,223 @@
+package connectapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"connectrpc.com/connect"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	"github.com/tochemey/cos-go-sample/gen/accounts/v1/accountsv1connect"
+)
+
+// Handler adapts the gRPC accounts api service to the Connect handler interface.
+// Every call is delegated to the gRPC service implementation so that the business logic is shared by both protocols.
+type Handler struct {
+	service pb.BankAccountServiceServer
+}
+
+var _ accountsv1connect.BankAccountServiceHandler = (*Handler)(nil)
+
+// NewHandler creates an instance of Handler
+func NewHandler(service pb.BankAccountServiceServer) *Handler {
+	return &Handler{service: service}
+}
+
+// OpenAccount helps open a bank account
+func (h *Handler) OpenAccount(ctx context.Context, request *connect.Request[pb.OpenAccountRequest]) (*connect.Response[pb.OpenAccountResponse], error) {
+	return unary(ctx, request, h.service.OpenAccount)
+}
+
+// DebitAccount sends a debit account request to the service
+func (h *Handler) DebitAccount(ctx context.Context, request *connect.Request[pb.DebitAccountRequest]) (*connect.Response[pb.DebitAccountResponse], error) {
+	return unary(ctx, request, h.service.DebitAccount)
+}
+
+// CreditAccount sends a credit account request to the service
+func (h *Handler) CreditAccount(ctx context.Context, request *connect.Request[pb.CreditAccountRequest]) (*connect.Response[pb.CreditAccountResponse], error) {
+	return unary(ctx, request, h.service.CreditAccount)
+}
+
+// GetAccount returns a given account information
+func (h *Handler) GetAccount(ctx context.Context, request *connect.Request[pb.GetAccountRequest]) (*connect.Response[pb.GetAccountResponse], error) {
+	return unary(ctx, request, h.service.GetAccount)
+}
+
+// GetAccountAt returns a given account as it was at a given time or revision
+func (h *Handler) GetAccountAt(ctx context.Context, request *connect.Request[pb.GetAccountAtRequest]) (*connect.Response[pb.GetAccountAtResponse], error) {
+	return unary(ctx, request, h.service.GetAccountAt)
+}
+
+// WaiveFee refunds previously charged fees to a given account
+func (h *Handler) WaiveFee(ctx context.Context, request *connect.Request[pb.WaiveFeeRequest]) (*connect.Response[pb.WaiveFeeResponse], error) {
+	return unary(ctx, request, h.service.WaiveFee)
+}
+
+// CreateStandingOrder schedules recurring transfers or direct debits from a given account
+func (h *Handler) CreateStandingOrder(ctx context.Context, request *connect.Request[pb.CreateStandingOrderRequest]) (*connect.Response[pb.CreateStandingOrderResponse], error) {
+	return unary(ctx, request, h.service.CreateStandingOrder)
+}
+
+// PauseStandingOrder suspends the executions of a given standing order
+func (h *Handler) PauseStandingOrder(ctx context.Context, request *connect.Request[pb.PauseStandingOrderRequest]) (*connect.Response[pb.PauseStandingOrderResponse], error) {
+	return unary(ctx, request, h.service.PauseStandingOrder)
+}
+
+// ResumeStandingOrder resumes the executions of a given paused standing order
+func (h *Handler) ResumeStandingOrder(ctx context.Context, request *connect.Request[pb.ResumeStandingOrderRequest]) (*connect.Response[pb.ResumeStandingOrderResponse], error) {
+	return unary(ctx, request, h.service.ResumeStandingOrder)
+}
+
+// CancelStandingOrder definitely stops the executions of a given standing order
+func (h *Handler) CancelStandingOrder(ctx context.Context, request *connect.Request[pb.CancelStandingOrderRequest]) (*connect.Response[pb.CancelStandingOrderResponse], error) {
+	return unary(ctx, request, h.service.CancelStandingOrder)
+}
+
+// GetStandingOrder returns a given standing order with its schedule
+func (h *Handler) GetStandingOrder(ctx context.Context, request *connect.Request[pb.GetStandingOrderRequest]) (*connect.Response[pb.GetStandingOrderResponse], error) {
+	return unary(ctx, request, h.service.GetStandingOrder)
+}
+
+// GenerateStatement builds the statement of a given account for a given period and streams it back
+func (h *Handler) GenerateStatement(ctx context.Context, request *connect.Request[pb.GenerateStatementRequest], stream *connect.ServerStream[pb.GenerateStatementResponse]) error {
+	if err := h.service.GenerateStatement(request.Msg, newServerStream(ctx, request, stream)); err != nil {
+		return toConnectError(err)
+	}
+	return nil
+}
+
+// WatchAccount streams the current state of a given account and then every subsequent change of that account
+func (h *Handler) WatchAccount(ctx context.Context, request *connect.Request[pb.WatchAccountRequest], stream *connect.ServerStream[pb.WatchAccountResponse]) error {
+	if err := h.service.WatchAccount(request.Msg, newServerStream(ctx, request, stream)); err != nil {
+		return toConnectError(err)
+	}
+	return nil
+}
+
+// BatchGetAccounts returns the given accounts from the read model
+func (h *Handler) BatchGetAccounts(ctx context.Context, request *connect.Request[pb.BatchGetAccountsRequest]) (*connect.Response[pb.BatchGetAccountsResponse], error) {
+	return unary(ctx, request, h.service.BatchGetAccounts)
+}
+
+// BulkPost executes the given credits and debits concurrently
+func (h *Handler) BulkPost(ctx context.Context, request *connect.Request[pb.BulkPostRequest]) (*connect.Response[pb.BulkPostResponse], error) {
+	return unary(ctx, request, h.service.BulkPost)
+}
+
+// ListAuditEntries returns the audit trail of a given account or standing order
+func (h *Handler) ListAuditEntries(ctx context.Context, request *connect.Request[pb.ListAuditEntriesRequest]) (*connect.Response[pb.ListAuditEntriesResponse], error) {
+	return unary(ctx, request, h.service.ListAuditEntries)
+}
+
+// EraseCustomer erases the personal data of a given customer
+func (h *Handler) EraseCustomer(ctx context.Context, request *connect.Request[pb.EraseCustomerRequest]) (*connect.Response[pb.EraseCustomerResponse], error) {
+	return unary(ctx, request, h.service.EraseCustomer)
+}
+
+// unary delegates a Connect unary call to the given gRPC service method
+func unary[Req, Res any](ctx context.Context, request *connect.Request[Req], call func(context.Context, *Req) (*Res, error)) (*connect.Response[Res], error) {
+	response, err := call(incomingContext(ctx, request.Header()), request.Msg)
+	if err != nil {
+		return nil, toConnectError(err)
+	}
+	return connect.NewResponse(response), nil
+}
+
+// incomingContext exposes the Connect request headers as the gRPC incoming metadata expected by the service
+func incomingContext(ctx context.Context, header map[string][]string) context.Context {
+	md := metadata.MD{}
+	for key, values := range header {
+		md.Append(strings.ToLower(key), values...)
+	}
+	return metadata.NewIncomingContext(ctx, md)
+}
+
+// toConnectError converts the gRPC status error returned by the service into a Connect error
+// with the same code, message and details
+func toConnectError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return connect.NewError(connect.CodeUnknown, err)
+	}
+
+	connectErr := connect.NewError(connect.Code(st.Code()), errors.New(st.Message()))
+	for _, detail := range st.Proto().GetDetails() {
+		errDetail, err := connect.NewErrorDetail(detail)
+		if err != nil {
+			continue
+		}
+		connectErr.AddDetail(errDetail)
+	}
+	return connectErr
+}
+
+// serverStream adapts the Connect server stream to the gRPC server stream expected by the service
+type serverStream[Res any] struct {
+	ctx    context.Context
+	stream *connect.ServerStream[Res]
+}
+
+var (
+	_ pb.BankAccountService_GenerateStatementServer = (*serverStream[pb.GenerateStatementResponse])(nil)
+	_ pb.BankAccountService_WatchAccountServer      = (*serverStream[pb.WatchAccountResponse])(nil)
+)
+
+// newServerStream creates a serverStream exposing the Connect request headers as the gRPC incoming metadata
+func newServerStream[Req, Res any](ctx context.Context, request *connect.Request[Req], stream *connect.ServerStream[Res]) *serverStream[Res] {
+	return &serverStream[Res]{
+		ctx:    incomingContext(ctx, request.Header()),
+		stream: stream,
+	}
+}
+
+// Send sends a message to the client
+func (s *serverStream[Res]) Send(response *Res) error {
+	return s.stream.Send(response)
+}
+
+// Context returns the stream context
+func (s *serverStream[Res]) Context() context.Context {
+	return s.ctx
+}
+
+// SetHeader sets the response headers
+func (s *serverStream[Res]) SetHeader(md metadata.MD) error {
+	for key, values := range md {
+		for _, value := range values {
+			s.stream.ResponseHeader().Add(key, value)
+		}
+	}
+	return nil
+}
+
+// SendHeader sets the response headers. They are sent along the first message
+func (s *serverStream[Res]) SendHeader(md metadata.MD) error {
+	return s.SetHeader(md)
+}
+
+// SetTrailer sets the response trailers
+func (s *serverStream[Res]) SetTrailer(md metadata.MD) {
+	for key, values := range md {
+		for _, value := range values {
+			s.stream.ResponseTrailer().Add(key, value)
+		}
+	}
+}
+
+// SendMsg sends a message to the client
+func (s *serverStream[Res]) SendMsg(m any) error {
+	response, ok := m.(*Res)
+	if !ok {
+		return status.Errorf(codes.Internal, "unexpected message type %T", m)
+	}
+	return s.Send(response)
+}
+
+// RecvMsg is not supported on a server stream
+func (s *serverStream[Res]) RecvMsg(any) error {
+	return status.Error(codes.Unimplemented, "receiving messages is not supported on a server stream")
+}
@@ -0,0 +1,97 @@
+package connectapi
+
+import (
+	"context"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	"github.com/tochemey/cos-go-sample/gen/accounts/v1/accountsv1connect"
+	pbmocks "github.com/tochemey/cos-go-sample/mocks/gen/accounts/v1"
+)
+
+func TestNewHandler(t *testing.T) {
+	handler := NewHandler(new(pbmocks.BankAccountServiceServer))
+	assert.NotNil(t, handler)
+	var p interface{} = handler
+	_, ok := p.(accountsv1connect.BankAccountServiceHandler)
+	assert.True(t, ok)
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("With unary call delegated to the service", func(t *testing.T) {
+		ctx := context.TODO()
+		request := &pb.GetAccountRequest{AccountId: "account-1"}
+		expected := &pb.GetAccountResponse{Account: &pb.BankAccount{AccountId: "account-1", AccountBalance: 100}}
+
+		service := new(pbmocks.BankAccountServiceServer)
+		service.
+			On("GetAccount", mock.MatchedBy(func(ctx context.Context) bool {
+				// the request headers are exposed as the grpc incoming metadata
+				md, ok := metadata.FromIncomingContext(ctx)
+				return ok && len(md.Get("x-request-id")) == 1 && md.Get("x-request-id")[0] == "request-1"
+			}), mock.MatchedBy(func(actual *pb.GetAccountRequest) bool {
+				return proto.Equal(request, actual)
+			})).
+			Return(expected, nil)
+
+		connectRequest := connect.NewRequest(request)
+		connectRequest.Header().Set("X-Request-Id", "request-1")
+
+		handler := NewHandler(service)
+		actual, err := handler.GetAccount(ctx, connectRequest)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.True(t, proto.Equal(expected, actual.Msg))
+		service.AssertExpectations(t)
+	})
+	t.Run("With service error converted into a Connect error", func(t *testing.T) {
+		ctx := context.TODO()
+		request := &pb.DebitAccountRequest{AccountId: "account-1", Amount: 1000}
+
+		service := new(pbmocks.BankAccountServiceServer)
+		service.
+			On("DebitAccount", mock.Anything, mock.Anything).
+			Return(nil, status.Error(codes.FailedPrecondition, "insufficient balance"))
+
+		handler := NewHandler(service)
+		actual, err := handler.DebitAccount(ctx, connect.NewRequest(request))
+		require.Error(t, err)
+		assert.Nil(t, actual)
+		assert.Equal(t, connect.CodeFailedPrecondition, connect.CodeOf(err))
+		var connectErr *connect.Error
+		require.ErrorAs(t, err, &connectErr)
+		assert.Equal(t, "insufficient balance", connectErr.Message())
+		service.AssertExpectations(t)
+	})
+}
+
+func TestToConnectError(t *testing.T) {
+	t.Run("With status error details", func(t *testing.T) {
+		st, err := status.New(codes.InvalidArgument, "invalid amount").
+			WithDetails(&errdetails.BadRequest{
+				FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "amount", Description: "must be positive"}},
+			})
+		require.NoError(t, err)
+
+		actual := toConnectError(st.Err())
+		var connectErr *connect.Error
+		require.ErrorAs(t, actual, &connectErr)
+		assert.Equal(t, connect.CodeInvalidArgument, connectErr.Code())
+		require.Len(t, connectErr.Details(), 1)
+		assert.Equal(t, "google.rpc.BadRequest", connectErr.Details()[0].Type())
+	})
+	t.Run("With non status error", func(t *testing.T) {
+		actual := toConnectError(assert.AnError)
+		assert.Equal(t, connect.CodeUnknown, connect.CodeOf(actual))
+	})
+}
@@ -0,0 +1,106 @@
+package connectapi
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+	"github.com/pkg/errors"
+	"github.com/tochemey/gopack/otel/trace"
+	"google.golang.org/grpc"
+
+	"github.com/tochemey/cos-go-sample/app/log"
+	"github.com/tochemey/cos-go-sample/gen/accounts/v1/accountsv1connect"
+)
+
+// Server serves the native gRPC requests together with the Connect and gRPC-Web requests on the same port.
+// The native gRPC requests are handed over to the gRPC server so that they go through its interceptors while
+// the other requests are served by the Connect handler. Both HTTP/1.1 and HTTP/2 are accepted, over TLS when it is configured.
+type Server struct {
+	server *http.Server
+}
+
+// NewServer creates an instance of Server listening on the given port. Only TLS connections are accepted when the given
+// TLS configuration is set. The given options, such as the interceptors, are applied to the Connect handler
+func NewServer(port int, grpcServer *grpc.Server, handler accountsv1connect.BankAccountServiceHandler, serviceName string, tlsConfig *tls.Config, options ...connect.HandlerOption) *Server {
+	// accept HTTP/1.1 for the Connect and gRPC-Web requests and HTTP/2 for the gRPC requests, with prior knowledge without TLS
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	if tlsConfig != nil {
+		protocols.SetHTTP2(true)
+	} else {
+		protocols.SetUnencryptedHTTP2(true)
+	}
+
+	return &Server{
+		server: &http.Server{
+			Addr:      fmt.Sprintf(":%d", port),
+			Handler:   NewMux(grpcServer, handler, serviceName, options...),
+			Protocols: protocols,
+			TLSConfig: tlsConfig,
+		},
+	}
+}
+
+// NewMux creates the http handler routing the native gRPC requests to the gRPC server
+// and the Connect and gRPC-Web requests to the Connect handler created with the given options
+func NewMux(grpcServer *grpc.Server, handler accountsv1connect.BankAccountServiceHandler, serviceName string, options ...connect.HandlerOption) http.Handler {
+	// create the Connect routes
+	mux := http.NewServeMux()
+	mux.Handle(accountsv1connect.NewBankAccountServiceHandler(handler, options...))
+	// the native gRPC requests are traced by the gRPC server
+	connectHandler := trace.Middleware(serviceName)(mux)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isGRPC(r) {
+			grpcServer.ServeHTTP(w, r)
+			return
+		}
+		connectHandler.ServeHTTP(w, r)
+	})
+}
+
+// Start starts the server. The requests are served in the background
+func (s *Server) Start() error {
+	// listen on the server address
+	listener, err := net.Listen("tcp", s.server.Addr)
+	// handle the error
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen on (%s)", s.server.Addr)
+	}
+
+	go func() {
+		serve := s.server.Serve
+		if s.server.TLSConfig != nil {
+			// the certificates are provided by the TLS configuration
+			serve = func(listener net.Listener) error { return s.server.ServeTLS(listener, "", "") }
+		}
+		if err := serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error(errors.Wrap(err, "the api server stopped unexpectedly"))
+		}
+	}()
+
+	return nil
+}
+
+// Stop gracefully stops the server, waiting for the in-flight requests to finish. The connections are forcibly closed,
+// cancelling the remaining requests, when the given context is done before they finish
+func (s *Server) Stop(ctx context.Context) error {
+	if err := s.server.Shutdown(ctx); err != nil {
+		_ = s.server.Close()
+		return errors.Wrap(err, "failed to stop the api server")
+	}
+	return nil
+}
+
+// isGRPC checks whether the request is a native gRPC request. gRPC-Web requests are served by the Connect handler
+func isGRPC(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	return r.ProtoMajor == 2 &&
+		strings.HasPrefix(contentType, "application/grpc") &&
+		!strings.HasPrefix(contentType, "application/grpc-web")
+}
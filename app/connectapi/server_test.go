@@ -0,0 +1,249 @@
+package connectapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/tochemey/cos-go-sample/app/lifecycle"
+	"github.com/tochemey/cos-go-sample/app/service"
+	"github.com/tochemey/cos-go-sample/app/subscription"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	"github.com/tochemey/cos-go-sample/gen/accounts/v1/accountsv1connect"
+	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
+	cosmocks "github.com/tochemey/cos-go-sample/mocks/app/cos"
+	pbmocks "github.com/tochemey/cos-go-sample/mocks/gen/accounts/v1"
+)
+
+// newTestServer starts an http server serving the gRPC and Connect requests with the given service.
+// The given options are applied to the Connect handler
+func newTestServer(t *testing.T, service pb.BankAccountServiceServer, options ...connect.HandlerOption) *httptest.Server {
+	grpcServer := grpc.NewServer()
+	pb.RegisterBankAccountServiceServer(grpcServer, service)
+
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+
+	server := httptest.NewUnstartedServer(NewMux(grpcServer, NewHandler(service), "accounts", options...))
+	server.Config.Protocols = protocols
+	server.Start()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewMux(t *testing.T) {
+	account := &pb.BankAccount{AccountId: "account-1", AccountBalance: 100}
+	newService := func() *pbmocks.BankAccountServiceServer {
+		service := new(pbmocks.BankAccountServiceServer)
+		service.
+			On("GetAccount", mock.Anything, mock.Anything).
+			Return(&pb.GetAccountResponse{Account: account}, nil)
+		return service
+	}
+
+	t.Run("With native gRPC request", func(t *testing.T) {
+		ctx := context.TODO()
+		service := newService()
+		server := newTestServer(t, service)
+
+		conn, err := grpc.NewClient(strings.TrimPrefix(server.URL, "http://"), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		actual, err := pb.NewBankAccountServiceClient(conn).GetAccount(ctx, &pb.GetAccountRequest{AccountId: "account-1"})
+		require.NoError(t, err)
+		assert.Equal(t, "account-1", actual.GetAccount().GetAccountId())
+		service.AssertExpectations(t)
+	})
+	t.Run("With Connect request", func(t *testing.T) {
+		ctx := context.TODO()
+		service := newService()
+		server := newTestServer(t, service)
+
+		client := accountsv1connect.NewBankAccountServiceClient(http.DefaultClient, server.URL)
+		actual, err := client.GetAccount(ctx, connect.NewRequest(&pb.GetAccountRequest{AccountId: "account-1"}))
+		require.NoError(t, err)
+		assert.Equal(t, "account-1", actual.Msg.GetAccount().GetAccountId())
+		service.AssertExpectations(t)
+	})
+	t.Run("With Connect JSON request", func(t *testing.T) {
+		service := newService()
+		server := newTestServer(t, service)
+
+		response, err := http.Post(server.URL+accountsv1connect.BankAccountServiceGetAccountProcedure, "application/json",
+			strings.NewReader(`{"accountId": "account-1"}`))
+		require.NoError(t, err)
+		defer response.Body.Close()
+
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		service.AssertExpectations(t)
+	})
+	t.Run("With Connect handler options", func(t *testing.T) {
+		ctx := context.TODO()
+		service := new(pbmocks.BankAccountServiceServer)
+		// the interceptor rejects the request before it reaches the service
+		interceptor := connect.UnaryInterceptorFunc(func(connect.UnaryFunc) connect.UnaryFunc {
+			return func(context.Context, connect.AnyRequest) (connect.AnyResponse, error) {
+				return nil, connect.NewError(connect.CodeUnauthenticated, errors.New("the bearer token is not set"))
+			}
+		})
+		server := newTestServer(t, service, connect.WithInterceptors(interceptor))
+
+		client := accountsv1connect.NewBankAccountServiceClient(http.DefaultClient, server.URL)
+		_, err := client.GetAccount(ctx, connect.NewRequest(&pb.GetAccountRequest{AccountId: "account-1"}))
+		assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+		service.AssertExpectations(t)
+	})
+	t.Run("With gRPC-Web request", func(t *testing.T) {
+		ctx := context.TODO()
+		service := newService()
+		server := newTestServer(t, service)
+
+		client := accountsv1connect.NewBankAccountServiceClient(http.DefaultClient, server.URL, connect.WithGRPCWeb())
+		actual, err := client.GetAccount(ctx, connect.NewRequest(&pb.GetAccountRequest{AccountId: "account-1"}))
+		require.NoError(t, err)
+		assert.Equal(t, "account-1", actual.Msg.GetAccount().GetAccountId())
+		service.AssertExpectations(t)
+	})
+	t.Run("With server streaming Connect request", func(t *testing.T) {
+		ctx := context.TODO()
+		service := new(pbmocks.BankAccountServiceServer)
+		service.
+			On("GenerateStatement", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				stream := args.Get(1).(pb.BankAccountService_GenerateStatementServer)
+				_ = stream.Send(&pb.GenerateStatementResponse{Chunk: []byte("hello ")})
+				_ = stream.Send(&pb.GenerateStatementResponse{Chunk: []byte("world")})
+			}).
+			Return(nil)
+		server := newTestServer(t, service)
+
+		client := accountsv1connect.NewBankAccountServiceClient(http.DefaultClient, server.URL)
+		stream, err := client.GenerateStatement(ctx, connect.NewRequest(&pb.GenerateStatementRequest{AccountId: "account-1"}))
+		require.NoError(t, err)
+		defer stream.Close()
+
+		buffer := new(bytes.Buffer)
+		for stream.Receive() {
+			buffer.Write(stream.Msg().GetChunk())
+		}
+		require.NoError(t, stream.Err())
+		assert.Equal(t, "hello world", buffer.String())
+		service.AssertExpectations(t)
+	})
+}
+
+func TestServer(t *testing.T) {
+	t.Run("Without TLS", func(t *testing.T) {
+		ctx := context.TODO()
+		server := NewServer(0, grpc.NewServer(), NewHandler(new(pbmocks.BankAccountServiceServer)), "accounts", nil)
+		require.NotNil(t, server)
+
+		require.NoError(t, server.Start())
+		assert.NoError(t, server.Stop(ctx))
+	})
+	t.Run("With TLS", func(t *testing.T) {
+		ctx := context.TODO()
+		tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+		server := NewServer(0, grpc.NewServer(), NewHandler(new(pbmocks.BankAccountServiceServer)), "accounts", tlsConfig)
+		require.NotNil(t, server)
+		assert.True(t, server.server.Protocols.HTTP2())
+		assert.False(t, server.server.Protocols.UnencryptedHTTP2())
+
+		require.NoError(t, server.Start())
+		assert.NoError(t, server.Stop(ctx))
+	})
+	t.Run("With in-flight request not finishing", func(t *testing.T) {
+		listener, err := net.Listen("tcp", ":0")
+		require.NoError(t, err)
+		port := listener.Addr().(*net.TCPAddr).Port
+		require.NoError(t, listener.Close())
+
+		// the request only finishes once cancelled
+		started := make(chan struct{})
+		service := new(pbmocks.BankAccountServiceServer)
+		service.
+			On("GetAccount", mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) {
+				close(started)
+				<-args.Get(0).(context.Context).Done()
+			}).
+			Return(nil, context.Canceled)
+		server := NewServer(port, grpc.NewServer(), NewHandler(service), "accounts", nil)
+		require.NoError(t, server.Start())
+
+		requestErr := make(chan error, 1)
+		go func() {
+			client := accountsv1connect.NewBankAccountServiceClient(http.DefaultClient, fmt.Sprintf("http://localhost:%d", port))
+			_, err := client.GetAccount(context.TODO(), connect.NewRequest(&pb.GetAccountRequest{AccountId: "account-1"}))
+			requestErr <- err
+		}()
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, server.Stop(ctx), context.DeadlineExceeded)
+		// the connection of the request is closed
+		assert.Error(t, <-requestErr)
+	})
+	t.Run("With watch open at shutdown", func(t *testing.T) {
+		listener, err := net.Listen("tcp", ":0")
+		require.NoError(t, err)
+		port := listener.Addr().(*net.TCPAddr).Port
+		require.NoError(t, listener.Close())
+
+		// the watch streams the account state and then awaits its changes
+		hub := subscription.NewHub(10)
+		cosClient := new(cosmocks.Client)
+		cosClient.
+			On("GetState", mock.Anything, "account-1").
+			Return(&pb.BankAccount{AccountId: "account-1", AccountBalance: 100}, &cospb.MetaData{RevisionNumber: 1}, nil)
+		server := NewServer(port, grpc.NewServer(), NewHandler(service.NewService(cosClient, nil, nil, hub, nil, nil, nil, nil, nil)), "accounts", nil)
+
+		// the watchers are released before the server is drained, as on the serve shutdown
+		manager := lifecycle.NewManager(&lifecycle.Config{Timeout: time.Second})
+		manager.Add(
+			lifecycle.Component{
+				Name:  "api server",
+				Start: func(context.Context) error { return server.Start() },
+				Stop:  server.Stop,
+			},
+			lifecycle.Component{
+				Name: "watches",
+				Stop: func(context.Context) error {
+					hub.Close()
+					return nil
+				},
+			},
+		)
+		require.NoError(t, manager.Start(context.TODO()))
+
+		client := accountsv1connect.NewBankAccountServiceClient(http.DefaultClient, fmt.Sprintf("http://localhost:%d", port))
+		stream, err := client.WatchAccount(context.TODO(), connect.NewRequest(&pb.WatchAccountRequest{AccountId: "account-1"}))
+		require.NoError(t, err)
+		defer stream.Close()
+		require.True(t, stream.Receive())
+		assert.Equal(t, "account-1", stream.Msg().GetAccount().GetAccountId())
+
+		require.NoError(t, manager.Stop(context.TODO()))
+		// the watch ends gracefully
+		assert.False(t, stream.Receive())
+		assert.NoError(t, stream.Err())
+	})
+}
@@ -2,15 +2,18 @@ package cos
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 
-	gopack "github.com/tochemey/gopack/grpc"
+	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/emptypb"
 
+	"github.com/tochemey/cos-go-sample/app/caller"
+	"github.com/tochemey/cos-go-sample/app/grpconfig"
 	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
 	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
 )
@@ -19,46 +22,62 @@ import (
 type Client interface {
 	ProcessCommand(ctx context.Context, accountID string, command proto.Message) (*pb.BankAccount, *cospb.MetaData, error)
 	GetState(ctx context.Context, accountID string) (*pb.BankAccount, *cospb.MetaData, error)
+	ProcessStandingOrderCommand(ctx context.Context, orderID string, command proto.Message) (*pb.StandingOrder, *cospb.MetaData, error)
+	GetStandingOrder(ctx context.Context, orderID string) (*pb.StandingOrder, *cospb.MetaData, error)
+	// Check checks the connectivity to CoS. An error is returned when the connection is failing or closed
+	Check(ctx context.Context) error
+	// Close closes the connection to CoS
+	Close() error
 }
 
 // client implements the Client interface
 type client struct {
 	remote cospb.ChiefOfStateServiceClient
+	conn   connection
+}
+
+// connection is the client connection to CoS, e.g. *grpc.ClientConn
+type connection interface {
+	grpconfig.Connectivity
+	Close() error
 }
 
 var _ Client = &client{}
 
-// NewClient creates a new instance of Client
-func NewClient(cosHost string, cosPort int) (Client, error) {
+// NewClient creates a new instance of Client. The connection to CoS uses the given TLS configuration when it is set
+func NewClient(cosHost string, cosPort int, tlsConfig *tls.Config) (Client, error) {
 	// get the grpc client connection to CoS
-	conn, err := gopack.DefaultConn(fmt.Sprintf("%v:%v", cosHost, cosPort))
+	conn, err := grpconfig.NewConn(fmt.Sprintf("%v:%v", cosHost, cosPort), tlsConfig)
 	// handle the error
 	if err != nil {
 		return nil, err
 	}
 	return &client{
 		remote: cospb.NewChiefOfStateServiceClient(conn),
+		conn:   conn,
 	}, nil
 }
 
-// ProcessCommand sends a command to COS and returns the resulting state and metadata
-func (c client) ProcessCommand(ctx context.Context, accountID string, command proto.Message) (*pb.BankAccount, *cospb.MetaData, error) {
-	// require a command
-	if command == nil {
-		return nil, nil, status.Error(codes.Internal, "command is missing")
+// Check checks the connectivity to CoS. An error is returned when the connection is failing or closed
+func (c client) Check(context.Context) error {
+	if err := grpconfig.CheckConn(c.conn); err != nil {
+		return errors.Wrap(err, "CoS is not reachable")
 	}
+	return nil
+}
 
-	// pack command into Any
-	cmdAny, _ := anypb.New(command)
-
-	// construct COS request
-	request := &cospb.ProcessCommandRequest{
-		EntityId: accountID,
-		Command:  cmdAny,
+// Close closes the connection to CoS
+func (c client) Close() error {
+	if err := c.conn.Close(); err != nil {
+		return errors.Wrap(err, "failed to close the CoS connection")
 	}
+	return nil
+}
 
+// ProcessCommand sends a command to COS and returns the resulting state and metadata
+func (c client) ProcessCommand(ctx context.Context, accountID string, command proto.Message) (*pb.BankAccount, *cospb.MetaData, error) {
 	// call COS get response
-	response, err := c.remote.ProcessCommand(ctx, request)
+	response, err := c.processCommand(ctx, accountID, command)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -76,32 +95,97 @@ func (c client) ProcessCommand(ctx context.Context, accountID string, command pr
 // GetState retrieves the current  state of an entity and its metadata
 func (c client) GetState(ctx context.Context, accountID string) (*pb.BankAccount, *cospb.MetaData, error) {
 	// call CoS
-	response, err := c.remote.GetState(ctx, &cospb.GetStateRequest{EntityId: accountID})
+	response, err := c.getState(ctx, accountID)
+	// handle the error or a NOT_FOUND
+	if err != nil || response == nil {
+		return nil, nil, err
+	}
+
+	// unpack the resulting state
+	resultingState, err := UnmarshalState(response.GetState())
 	if err != nil {
-		if e, ok := status.FromError(err); ok {
-			if e.Code() == codes.NotFound {
-				return nil, nil, nil
-			}
-		}
+		return nil, nil, err
+	}
 
+	// return
+	return resultingState, response.GetMeta(), nil
+}
+
+// ProcessStandingOrderCommand sends a standing order command to COS and returns the resulting standing order and metadata
+func (c client) ProcessStandingOrderCommand(ctx context.Context, orderID string, command proto.Message) (*pb.StandingOrder, *cospb.MetaData, error) {
+	// call COS get response
+	response, err := c.processCommand(ctx, orderID, command)
+	if err != nil {
 		return nil, nil, err
 	}
 
-	// handle nil response like a NOT_FOUND
-	if response == nil {
-		return nil, nil, nil
+	// unpack the resulting state
+	resultingState, err := UnmarshalStandingOrder(response.GetState())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resultingState, response.GetMeta(), nil
+}
+
+// GetStandingOrder retrieves the current state of a standing order and its metadata
+func (c client) GetStandingOrder(ctx context.Context, orderID string) (*pb.StandingOrder, *cospb.MetaData, error) {
+	// call CoS
+	response, err := c.getState(ctx, orderID)
+	// handle the error or a NOT_FOUND
+	if err != nil || response == nil {
+		return nil, nil, err
 	}
 
 	// unpack the resulting state
-	resultingState, err := UnmarshalState(response.GetState())
+	resultingState, err := UnmarshalStandingOrder(response.GetState())
 	if err != nil {
 		return nil, nil, err
 	}
 
-	// return
 	return resultingState, response.GetMeta(), nil
 }
 
+// processCommand packs the command and sends it to COS
+func (c client) processCommand(ctx context.Context, entityID string, command proto.Message) (*cospb.ProcessCommandResponse, error) {
+	// require a command
+	if command == nil {
+		return nil, status.Error(codes.Internal, "command is missing")
+	}
+
+	// pack command into Any
+	cmdAny, _ := anypb.New(command)
+
+	// construct COS request
+	request := &cospb.ProcessCommandRequest{
+		EntityId: entityID,
+		Command:  cmdAny,
+	}
+
+	// call COS get response
+	// send the identity of the caller along the command so that it is stamped into the resulting event
+	return c.remote.ProcessCommand(caller.NewOutgoingContext(ctx), request)
+}
+
+// getState fetches the current state of an entity from COS.
+// A nil response is returned when the entity is not found
+func (c client) getState(ctx context.Context, entityID string) (*cospb.GetStateResponse, error) {
+	// call CoS
+	response, err := c.remote.GetState(ctx, &cospb.GetStateRequest{EntityId: entityID})
+	if err != nil {
+		if e, ok := status.FromError(err); ok {
+			if e.Code() == codes.NotFound {
+				return nil, nil
+			}
+		}
+
+		return nil, err
+	}
+
+	// a nil response is handled like a NOT_FOUND
+	return response, nil
+}
+
 // UnmarshalState unpacks the actual state from the proto any message
 func UnmarshalState(any *anypb.Any) (*pb.BankAccount, error) {
 	msg, err := any.UnmarshalNew()
@@ -119,3 +203,21 @@ func UnmarshalState(any *anypb.Any) (*pb.BankAccount, error) {
 		return nil, status.Errorf(codes.Internal, "expecting %s got %s", expected, any.GetTypeUrl())
 	}
 }
+
+// UnmarshalStandingOrder unpacks the actual standing order from the proto any message
+func UnmarshalStandingOrder(any *anypb.Any) (*pb.StandingOrder, error) {
+	msg, err := any.UnmarshalNew()
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := msg.(type) {
+	case *pb.StandingOrder:
+		return v, nil
+	case *emptypb.Empty:
+		return nil, nil
+	default:
+		expected := proto.MessageName(new(pb.StandingOrder))
+		return nil, status.Errorf(codes.Internal, "expecting %s got %s", expected, any.GetTypeUrl())
+	}
+}
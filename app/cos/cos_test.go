@@ -2,12 +2,16 @@ package cos
 
 import (
 	"context"
+	"crypto/tls"
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
@@ -15,6 +19,7 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 
+	"github.com/tochemey/cos-go-sample/app/caller"
 	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
 	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
 	mocks "github.com/tochemey/cos-go-sample/mocks/gen/chief_of_state/v1"
@@ -24,6 +29,12 @@ type cosClientTestSuite struct {
 	suite.Suite
 }
 
+// callerContext matches the contexts carrying the identity of the caller as outgoing gRPC metadata
+var callerContext = mock.MatchedBy(func(ctx context.Context) bool {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	return ok && len(md.Get(caller.RequestIDHeader)) == 1
+})
+
 // In order for 'go test' to run this suite, we need to create
 // a normal test function and pass our suite to suite.Run
 func TestCosClient(t *testing.T) {
@@ -34,12 +45,58 @@ func (s *cosClientTestSuite) TestNewClient() {
 	s.Run("happy path", func() {
 		// this will work because grpc connection won't wait for connections to be
 		// established, and connecting happens in the background
-		cosClient, err := NewClient("localhost", 50051)
+		cosClient, err := NewClient("localhost", 50051, nil)
+		s.Assert().NotNil(cosClient)
+		s.Assert().NoError(err)
+	})
+	s.Run("with TLS", func() {
+		cosClient, err := NewClient("localhost", 50051, &tls.Config{MinVersion: tls.VersionTLS12})
 		s.Assert().NotNil(cosClient)
 		s.Assert().NoError(err)
 	})
 }
 
+func (s *cosClientTestSuite) TestCheck() {
+	s.Run("with connection ready", func() {
+		cosClient := &client{conn: &testConn{state: connectivity.Ready}}
+		s.Assert().NoError(cosClient.Check(context.TODO()))
+	})
+	s.Run("with connection failing", func() {
+		cosClient := &client{conn: &testConn{state: connectivity.TransientFailure}}
+		s.Assert().EqualError(cosClient.Check(context.TODO()), "CoS is not reachable: the connection is TRANSIENT_FAILURE")
+	})
+}
+
+func (s *cosClientTestSuite) TestClose() {
+	s.Run("with open connection", func() {
+		conn := &testConn{state: connectivity.Ready}
+		cosClient := &client{conn: conn}
+		s.Assert().NoError(cosClient.Close())
+		s.Assert().Equal(connectivity.Shutdown, conn.state)
+	})
+	s.Run("with connection already closed", func() {
+		cosClient := &client{conn: &testConn{state: connectivity.Shutdown}}
+		s.Assert().EqualError(cosClient.Close(), "failed to close the CoS connection: the connection is already closed")
+	})
+}
+
+// testConn is a client connection in a given connectivity state
+type testConn struct {
+	state connectivity.State
+}
+
+func (c *testConn) GetState() connectivity.State { return c.state }
+
+func (c *testConn) Connect() {}
+
+func (c *testConn) Close() error {
+	if c.state == connectivity.Shutdown {
+		return errors.New("the connection is already closed")
+	}
+	c.state = connectivity.Shutdown
+	return nil
+}
+
 func (s *cosClientTestSuite) TestUnmarshalState() {
 	s.Run("with valid state", func() {
 		// create a new state
@@ -123,9 +180,9 @@ func (s *cosClientTestSuite) TestProcessCommand() {
 		cosResp := &cospb.ProcessCommandResponse{State: anypbState, Meta: cosMeta}
 		// create the remote client
 		mockRemoteClient := &mocks.ChiefOfStateServiceClient{}
-		mockRemoteClient.On("ProcessCommand", ctx, mock.Anything).Return(cosResp, nil)
+		mockRemoteClient.On("ProcessCommand", callerContext, mock.Anything).Return(cosResp, nil)
 		// create the CoS client
-		mockCos := client{mockRemoteClient}
+		mockCos := client{remote: mockRemoteClient}
 		// create the command
 		cmd := &pb.CreditAccount{
 			AccountId: accountID,
@@ -146,9 +203,9 @@ func (s *cosClientTestSuite) TestProcessCommand() {
 
 		// create the remote client
 		mockRemoteClient := &mocks.ChiefOfStateServiceClient{}
-		mockRemoteClient.On("ProcessCommand", ctx, mock.Anything).Return(nil, status.Error(codes.Internal, ""))
+		mockRemoteClient.On("ProcessCommand", callerContext, mock.Anything).Return(nil, status.Error(codes.Internal, ""))
 		// create the CoS client
-		mockCos := client{mockRemoteClient}
+		mockCos := client{remote: mockRemoteClient}
 		cmd := &pb.CreditAccount{
 			AccountId: accountID,
 			Amount:    amount,
@@ -178,9 +235,9 @@ func (s *cosClientTestSuite) TestProcessCommand() {
 		cosResp := &cospb.ProcessCommandResponse{State: anypbState, Meta: cosMeta}
 		// create the remote client
 		mockRemoteClient := &mocks.ChiefOfStateServiceClient{}
-		mockRemoteClient.On("ProcessCommand", ctx, mock.Anything).Return(cosResp, nil)
+		mockRemoteClient.On("ProcessCommand", callerContext, mock.Anything).Return(cosResp, nil)
 		// create the CoS client
-		mockCos := client{mockRemoteClient}
+		mockCos := client{remote: mockRemoteClient}
 		cmd := &pb.CreditAccount{
 			AccountId: accountID,
 			Amount:    amount,
@@ -302,3 +359,122 @@ func (s *cosClientTestSuite) TestGetState() {
 		mockRemoteClient.AssertExpectations(s.T())
 	})
 }
+
+func (s *cosClientTestSuite) TestUnmarshalStandingOrder() {
+	s.Run("with valid state", func() {
+		// create a new state
+		state := &pb.StandingOrder{OrderId: "order-1"}
+		// pack that state into anypb
+		anypbState, err := anypb.New(state)
+		s.Assert().NoError(err)
+
+		unpacked, err := UnmarshalStandingOrder(anypbState)
+		s.Assert().NoError(err)
+		s.Assert().True(proto.Equal(state, unpacked))
+	})
+	s.Run("with an empty proto message", func() {
+		// pack an empty proto message into anypb
+		anypbState, err := anypb.New(new(emptypb.Empty))
+		s.Assert().NoError(err)
+
+		unpacked, err := UnmarshalStandingOrder(anypbState)
+		s.Assert().NoError(err)
+		s.Assert().Nil(unpacked)
+	})
+	s.Run("with an account state", func() {
+		// pack a bank account into anypb
+		anypbState, err := anypb.New(new(pb.BankAccount))
+		s.Assert().NoError(err)
+
+		unpacked, err := UnmarshalStandingOrder(anypbState)
+		s.Assert().Error(err)
+		s.Assert().Nil(unpacked)
+	})
+}
+
+func (s *cosClientTestSuite) TestProcessStandingOrderCommand() {
+	s.Run("with happy path", func() {
+		ctx := context.TODO()
+		orderID := "order-1"
+
+		// create the current state
+		currentState := &pb.StandingOrder{OrderId: orderID, Status: pb.StandingOrderStatus_PAUSED}
+		anypbState, err := anypb.New(currentState)
+		s.Assert().NoError(err)
+		cosMeta := &cospb.MetaData{
+			EntityId:       orderID,
+			RevisionNumber: 2,
+			RevisionDate:   timestamppb.Now(),
+		}
+		// create the process command response
+		cosResp := &cospb.ProcessCommandResponse{State: anypbState, Meta: cosMeta}
+		// create the remote client
+		mockRemoteClient := &mocks.ChiefOfStateServiceClient{}
+		mockRemoteClient.On("ProcessCommand", callerContext, mock.Anything).Return(cosResp, nil)
+		// create the CoS client
+		mockCos := client{remote: mockRemoteClient}
+		state, meta, err := mockCos.ProcessStandingOrderCommand(ctx, orderID, &pb.PauseStandingOrder{OrderId: orderID})
+		s.Assert().NoError(err)
+		s.Assert().True(proto.Equal(currentState, state))
+		s.Assert().True(proto.Equal(cosMeta, meta))
+		mockRemoteClient.AssertExpectations(s.T())
+	})
+	s.Run("with remote client failure", func() {
+		ctx := context.TODO()
+		orderID := "order-1"
+
+		// create the remote client
+		mockRemoteClient := &mocks.ChiefOfStateServiceClient{}
+		mockRemoteClient.On("ProcessCommand", callerContext, mock.Anything).Return(nil, status.Error(codes.Internal, ""))
+		// create the CoS client
+		mockCos := client{remote: mockRemoteClient}
+		state, meta, err := mockCos.ProcessStandingOrderCommand(ctx, orderID, &pb.PauseStandingOrder{OrderId: orderID})
+		s.Assert().Error(err)
+		s.Assert().Nil(meta)
+		s.Assert().Nil(state)
+		mockRemoteClient.AssertExpectations(s.T())
+	})
+}
+
+func (s *cosClientTestSuite) TestGetStandingOrder() {
+	s.Run("with happy path", func() {
+		ctx := context.TODO()
+		orderID := "order-1"
+
+		// create the current state
+		currentState := &pb.StandingOrder{OrderId: orderID, Status: pb.StandingOrderStatus_ACTIVE}
+		anypbState, err := anypb.New(currentState)
+		s.Assert().NoError(err)
+		cosMeta := &cospb.MetaData{
+			EntityId:       orderID,
+			RevisionNumber: 1,
+			RevisionDate:   timestamppb.Now(),
+		}
+		// create the get state response
+		cosResp := &cospb.GetStateResponse{State: anypbState, Meta: cosMeta}
+		// create the remote client
+		mockRemoteClient := &mocks.ChiefOfStateServiceClient{}
+		mockRemoteClient.On("GetState", ctx, mock.Anything).Return(cosResp, nil)
+		// create the CoS client
+		mockCos := client{remote: mockRemoteClient}
+		state, meta, err := mockCos.GetStandingOrder(ctx, orderID)
+		s.Assert().NoError(err)
+		s.Assert().True(proto.Equal(currentState, state))
+		s.Assert().True(proto.Equal(cosMeta, meta))
+		mockRemoteClient.AssertExpectations(s.T())
+	})
+	s.Run("with not found", func() {
+		ctx := context.TODO()
+
+		// create the remote client
+		mockRemoteClient := &mocks.ChiefOfStateServiceClient{}
+		mockRemoteClient.On("GetState", ctx, mock.Anything).Return(nil, status.Error(codes.NotFound, "state not found"))
+		// create the CoS client
+		mockCos := client{remote: mockRemoteClient}
+		state, meta, err := mockCos.GetStandingOrder(ctx, "order-1")
+		s.Assert().NoError(err)
+		s.Assert().Nil(meta)
+		s.Assert().Nil(state)
+		mockRemoteClient.AssertExpectations(s.T())
+	})
+}
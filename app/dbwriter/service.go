@@ -9,19 +9,22 @@ import (
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
-	"github.com/tochemey/cos-go-sample/app/cos"
 	"github.com/tochemey/cos-go-sample/app/log"
+	"github.com/tochemey/cos-go-sample/app/pii"
 	"github.com/tochemey/cos-go-sample/app/storage"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
 	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
 )
 
 // Service is an implementation of the CoS ReadSide handler interface
 type Service struct {
 	dataStore storage.Storage
+	cipher    *pii.Cipher
 }
 
-// NewService creates a new instance of service
-func NewService(dataStore storage.Storage) (*Service, error) {
+// NewService creates a new instance of service. The personal data of the accounts is decrypted by the given cipher
+// before being persisted into the data store, and persisted as is when the cipher is not set
+func NewService(dataStore storage.Storage, cipher *pii.Cipher) (*Service, error) {
 	// check whether the data store is defined or not
 	if dataStore == nil {
 		return nil, errors.New("the dataStore is not defined")
@@ -30,6 +33,7 @@ func NewService(dataStore storage.Storage) (*Service, error) {
 	// return the new instance of Service
 	return &Service{
 		dataStore: dataStore,
+		cipher:    cipher,
 	}, nil
 }
 
@@ -51,8 +55,8 @@ func (s Service) HandleReadSide(ctx context.Context, request *cospb.HandleReadSi
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	// let us unmarshall the user
-	unpackState, err := cos.UnmarshalState(request.GetState())
+	// let us unmarshall the state
+	unpackState, err := request.GetState().UnmarshalNew()
 	// handle the error
 	if err != nil {
 		err = errors.Wrapf(err, "failed to unpack state:(%s)", request.GetState().GetTypeUrl())
@@ -60,12 +64,172 @@ func (s Service) HandleReadSide(ctx context.Context, request *cospb.HandleReadSi
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	// persist the data into the data store
-	if err = s.dataStore.PersistAccount(ctx, unpackState); err != nil {
-		err := errors.Wrap(err, "failed to persist account into the data store")
+	// persist the data into the data store according to the state type
+	switch state := unpackState.(type) {
+	case *pb.BankAccount:
+		// the read model holds the personal data in clear, and none for the erased customers
+		if state, err = s.decryptAccount(ctx, state); err != nil {
+			logger.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		if err = s.dataStore.PersistAccount(ctx, state, toRevision(request.GetMeta())); err != nil {
+			err := errors.Wrap(err, "failed to persist account into the data store")
+			logger.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		// record the transactions booked by the event and the customer activity
+		if err = s.persistEvent(ctx, request, state); err != nil {
+			logger.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	case *pb.StandingOrder:
+		if err = s.dataStore.PersistStandingOrder(ctx, state); err != nil {
+			err := errors.Wrap(err, "failed to persist standing order into the data store")
+			logger.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	default:
+		err := errors.Errorf("unhandled state:(%s)", request.GetState().GetTypeUrl())
+		logger.Error(err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// record the event and its caller into the audit trail
+	if err = s.persistAuditEntry(ctx, request, tenantOf(unpackState)); err != nil {
 		logger.Error(err)
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 	// return the successful handling of the read-side request
 	return &cospb.HandleReadSideResponse{Successful: true}, nil
 }
+
+// decryptAccount returns a given account with its personal data decrypted
+func (s Service) decryptAccount(ctx context.Context, account *pb.BankAccount) (*pb.BankAccount, error) {
+	if s.cipher == nil {
+		return account, nil
+	}
+
+	decrypted, err := s.cipher.DecryptAccount(ctx, account)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt the account")
+	}
+	return decrypted, nil
+}
+
+// persistEvent records the event of the given read side request into the account events ledger,
+// persists the transactions it books and records the customer activity it carries
+func (s Service) persistEvent(ctx context.Context, request *cospb.HandleReadSideRequest, state *pb.BankAccount) error {
+	// the event is not always sent
+	if request.GetEvent() == nil {
+		return nil
+	}
+
+	// let us unmarshall the event
+	event, err := request.GetEvent().UnmarshalNew()
+	if err != nil {
+		return errors.Wrapf(err, "failed to unpack event:(%s)", request.GetEvent().GetTypeUrl())
+	}
+
+	// record the event into the account events ledger
+	accountEvent := &storage.AccountEvent{
+		AccountID:      state.GetAccountId(),
+		TenantID:       state.GetTenantId(),
+		RevisionNumber: request.GetMeta().GetRevisionNumber(),
+		RevisionDate:   request.GetMeta().GetRevisionDate().AsTime(),
+		Event:          request.GetEvent(),
+	}
+	if err := s.dataStore.PersistAccountEvent(ctx, accountEvent); err != nil {
+		return errors.Wrap(err, "failed to persist the account event into the data store")
+	}
+
+	// persist the transactions
+	transactions := toTransactions(event, state, request.GetMeta())
+	if err := s.dataStore.PersistTransactions(ctx, state.GetTenantId(), transactions); err != nil {
+		return errors.Wrap(err, "failed to persist transactions into the data store")
+	}
+
+	// record the customer activity at the time the event has been persisted
+	if isCustomerActivity(event) && request.GetMeta().GetRevisionDate() != nil {
+		if err := s.dataStore.RecordAccountActivity(ctx, state.GetAccountId(), request.GetMeta().GetRevisionDate().AsTime()); err != nil {
+			return errors.Wrap(err, "failed to record the account activity into the data store")
+		}
+	}
+	return nil
+}
+
+// callerCarrier is implemented by the events stamped with the caller of the command that led to them
+type callerCarrier interface {
+	GetCaller() *pb.Caller
+}
+
+// tenantCarrier is implemented by the states of the entities belonging to a tenant
+type tenantCarrier interface {
+	GetTenantId() string
+}
+
+// tenantOf returns the tenant of a given state, none for the states of the entities opened before the multi-tenancy
+func tenantOf(state proto.Message) string {
+	if carrier, ok := state.(tenantCarrier); ok {
+		return carrier.GetTenantId()
+	}
+	return ""
+}
+
+// persistAuditEntry records the event of the given read side request together with the caller of the command that led to it
+// into the audit trail of the given tenant
+func (s Service) persistAuditEntry(ctx context.Context, request *cospb.HandleReadSideRequest, tenantID string) error {
+	// the event is not always sent
+	if request.GetEvent() == nil {
+		return nil
+	}
+
+	// let us unmarshall the event
+	event, err := request.GetEvent().UnmarshalNew()
+	if err != nil {
+		return errors.Wrapf(err, "failed to unpack event:(%s)", request.GetEvent().GetTypeUrl())
+	}
+
+	entry := &storage.AuditEntry{
+		EntityID:       request.GetMeta().GetEntityId(),
+		TenantID:       tenantID,
+		RevisionNumber: request.GetMeta().GetRevisionNumber(),
+		RevisionDate:   request.GetMeta().GetRevisionDate().AsTime(),
+		EventType:      string(event.ProtoReflect().Descriptor().FullName()),
+	}
+	if carrier, ok := event.(callerCarrier); ok {
+		entry.CallerSubject = carrier.GetCaller().GetSubject()
+		entry.ClientIP = carrier.GetCaller().GetClientIp()
+		entry.RequestID = carrier.GetCaller().GetRequestId()
+	}
+
+	if err := s.dataStore.PersistAuditEntry(ctx, entry); err != nil {
+		return errors.Wrap(err, "failed to persist the audit entry into the data store")
+	}
+	return nil
+}
+
+// toRevision returns the revision of the read model records built from the state of a given meta
+func toRevision(meta *cospb.MetaData) storage.Revision {
+	revision := storage.Revision{Number: meta.GetRevisionNumber()}
+	if meta.GetRevisionDate() != nil {
+		revision.Date = meta.GetRevisionDate().AsTime()
+	}
+	return revision
+}
+
+// isCustomerActivity returns true when the given event results from a customer activity.
+// The credits and debits initiated by the system do not count
+func isCustomerActivity(event proto.Message) bool {
+	switch typedEvent := event.(type) {
+	case *pb.AccountOpened:
+		return true
+	case *pb.AccountCredited:
+		return !typedEvent.GetSystemInitiated()
+	case *pb.AccountDebited:
+		return !typedEvent.GetSystemInitiated()
+	default:
+		return false
+	}
+}
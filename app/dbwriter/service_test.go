@@ -11,8 +11,11 @@ import (
 	gopack "github.com/tochemey/gopack/grpc"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 
+	"github.com/tochemey/cos-go-sample/app/pii"
+	"github.com/tochemey/cos-go-sample/app/storage"
 	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
 	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
 	mocks "github.com/tochemey/cos-go-sample/mocks/app/storage"
@@ -21,7 +24,7 @@ import (
 func TestNewService(t *testing.T) {
 	t.Run("With happy path", func(t *testing.T) {
 		dataStore := new(mocks.Storage)
-		svc, err := NewService(dataStore)
+		svc, err := NewService(dataStore, nil)
 		assert.NoError(t, err)
 		assert.NotNil(t, svc)
 
@@ -34,7 +37,7 @@ func TestNewService(t *testing.T) {
 		assert.True(t, ok)
 	})
 	t.Run("With data store not set", func(t *testing.T) {
-		svc, err := NewService(nil)
+		svc, err := NewService(nil, nil)
 		assert.Error(t, err)
 		assert.EqualError(t, err, "the dataStore is not defined")
 		assert.Nil(t, svc)
@@ -65,9 +68,258 @@ func TestHandleReadSide(t *testing.T) {
 		dataStore := new(mocks.Storage)
 		dataStore.On("PersistAccount", ctx, mock.MatchedBy(func(in *pb.BankAccount) bool {
 			return proto.Equal(in, state)
+		}), storage.Revision{}).Return(nil)
+
+		svc, err := NewService(dataStore, nil)
+		require.NoError(t, err)
+		require.NotNil(t, svc)
+
+		// create the read side request with the relevant needed info
+		req := &cospb.HandleReadSideRequest{State: anyState}
+		// handle the read side request
+		resp, err := svc.HandleReadSide(ctx, req)
+		assert.NoError(t, err)
+		assert.NotNil(t, resp)
+		assert.True(t, resp.GetSuccessful())
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With encrypted account owner", func(t *testing.T) {
+		ctx := context.TODO()
+		keyStore, err := pii.NewLocalKeyStore(t.TempDir())
+		require.NoError(t, err)
+		cipher := pii.NewCipher(keyStore)
+		encryptedOwner, err := cipher.Encrypt(ctx, "John Doe", "John Doe")
+		require.NoError(t, err)
+		anyState, err := anypb.New(&pb.BankAccount{AccountId: "account-1", AccountOwner: encryptedOwner})
+		require.NoError(t, err)
+
+		// the owner is persisted in clear
+		dataStore := new(mocks.Storage)
+		dataStore.On("PersistAccount", ctx, mock.MatchedBy(func(in *pb.BankAccount) bool {
+			return in.GetAccountOwner() == "John Doe"
+		}), storage.Revision{}).Return(nil).Once()
+
+		svc, err := NewService(dataStore, cipher)
+		require.NoError(t, err)
+		resp, err := svc.HandleReadSide(ctx, &cospb.HandleReadSideRequest{State: anyState})
+		require.NoError(t, err)
+		assert.True(t, resp.GetSuccessful())
+
+		// the owner of the erased customers is not persisted
+		require.NoError(t, cipher.Erase(ctx, "John Doe"))
+		dataStore.On("PersistAccount", ctx, mock.MatchedBy(func(in *pb.BankAccount) bool {
+			return in.GetAccountId() == "account-1" && in.GetAccountOwner() == ""
+		}), storage.Revision{}).Return(nil).Once()
+		resp, err = svc.HandleReadSide(ctx, &cospb.HandleReadSideRequest{State: anyState})
+		require.NoError(t, err)
+		assert.True(t, resp.GetSuccessful())
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With account event", func(t *testing.T) {
+		ctx := context.TODO()
+		state := &pb.BankAccount{AccountId: "account-1", AccountBalance: 100}
+		// pack the state and the event into any pb
+		anyState, err := anypb.New(state)
+		require.NoError(t, err)
+		caller := &pb.Caller{Subject: "operator-1", ClientIp: "10.0.0.1", RequestId: "request-1"}
+		anyEvent, err := anypb.New(&pb.AccountCredited{AccountId: "account-1", Amount: 50, Caller: caller})
+		require.NoError(t, err)
+		meta := &cospb.MetaData{EntityId: "account-1", RevisionNumber: 2, RevisionDate: timestamppb.Now()}
+		// create mocks
+		dataStore := new(mocks.Storage)
+		dataStore.On("PersistAccount", ctx, mock.Anything, storage.Revision{Number: 2, Date: meta.GetRevisionDate().AsTime()}).Return(nil)
+		dataStore.On("PersistAccountEvent", ctx, mock.MatchedBy(func(in *storage.AccountEvent) bool {
+			return in.AccountID == "account-1" && in.RevisionNumber == 2 && proto.Equal(anyEvent, in.Event)
+		})).Return(nil)
+		dataStore.On("PersistTransactions", ctx, "", mock.MatchedBy(func(in []*pb.Transaction) bool {
+			return len(in) == 1 && in[0].GetType() == pb.TransactionType_CREDIT && in[0].GetRevisionNumber() == 2
+		})).Return(nil)
+		dataStore.On("RecordAccountActivity", ctx, "account-1", meta.GetRevisionDate().AsTime()).Return(nil)
+		dataStore.On("PersistAuditEntry", ctx, &storage.AuditEntry{
+			EntityID:       "account-1",
+			RevisionNumber: 2,
+			RevisionDate:   meta.GetRevisionDate().AsTime(),
+			EventType:      "accounts.v1.AccountCredited",
+			CallerSubject:  "operator-1",
+			ClientIP:       "10.0.0.1",
+			RequestID:      "request-1",
+		}).Return(nil)
+
+		svc, err := NewService(dataStore, nil)
+		require.NoError(t, err)
+
+		// handle the read side request
+		resp, err := svc.HandleReadSide(ctx, &cospb.HandleReadSideRequest{Event: anyEvent, State: anyState, Meta: meta})
+		assert.NoError(t, err)
+		assert.True(t, resp.GetSuccessful())
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With account event of a tenant", func(t *testing.T) {
+		ctx := context.TODO()
+		state := &pb.BankAccount{AccountId: "acme/account-1", AccountBalance: 100, TenantId: "acme"}
+		// pack the state and the event into any pb
+		anyState, err := anypb.New(state)
+		require.NoError(t, err)
+		anyEvent, err := anypb.New(&pb.AccountCredited{AccountId: "acme/account-1", Amount: 50})
+		require.NoError(t, err)
+		meta := &cospb.MetaData{EntityId: "acme/account-1", RevisionNumber: 2, RevisionDate: timestamppb.Now()}
+		// the event, the transactions and the audit entry are recorded within the tenant of the account
+		dataStore := new(mocks.Storage)
+		dataStore.On("PersistAccount", ctx, mock.Anything, mock.Anything).Return(nil)
+		dataStore.On("PersistAccountEvent", ctx, mock.MatchedBy(func(in *storage.AccountEvent) bool {
+			return in.AccountID == "acme/account-1" && in.TenantID == "acme"
+		})).Return(nil)
+		dataStore.On("PersistTransactions", ctx, "acme", mock.Anything).Return(nil)
+		dataStore.On("RecordAccountActivity", ctx, "acme/account-1", mock.Anything).Return(nil)
+		dataStore.On("PersistAuditEntry", ctx, mock.MatchedBy(func(in *storage.AuditEntry) bool {
+			return in.EntityID == "acme/account-1" && in.TenantID == "acme"
+		})).Return(nil)
+
+		svc, err := NewService(dataStore, nil)
+		require.NoError(t, err)
+
+		// handle the read side request
+		resp, err := svc.HandleReadSide(ctx, &cospb.HandleReadSideRequest{Event: anyEvent, State: anyState, Meta: meta})
+		assert.NoError(t, err)
+		assert.True(t, resp.GetSuccessful())
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With system initiated account event", func(t *testing.T) {
+		ctx := context.TODO()
+		state := &pb.BankAccount{AccountId: "account-1", AccountBalance: 100}
+		// pack the state and the event into any pb
+		anyState, err := anypb.New(state)
+		require.NoError(t, err)
+		anyEvent, err := anypb.New(&pb.AccountCredited{AccountId: "account-1", Amount: 50, SystemInitiated: true})
+		require.NoError(t, err)
+		meta := &cospb.MetaData{EntityId: "account-1", RevisionNumber: 2, RevisionDate: timestamppb.Now()}
+		// create mocks. The activity is not recorded
+		dataStore := new(mocks.Storage)
+		dataStore.On("PersistAccount", ctx, mock.Anything, mock.Anything).Return(nil)
+		dataStore.On("PersistAccountEvent", ctx, mock.Anything).Return(nil)
+		dataStore.On("PersistTransactions", ctx, "", mock.Anything).Return(nil)
+		dataStore.On("PersistAuditEntry", ctx, mock.Anything).Return(nil)
+
+		svc, err := NewService(dataStore, nil)
+		require.NoError(t, err)
+
+		// handle the read side request
+		resp, err := svc.HandleReadSide(ctx, &cospb.HandleReadSideRequest{Event: anyEvent, State: anyState, Meta: meta})
+		assert.NoError(t, err)
+		assert.True(t, resp.GetSuccessful())
+		dataStore.AssertExpectations(t)
+		dataStore.AssertNotCalled(t, "RecordAccountActivity", mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("With audit dataStore failure", func(t *testing.T) {
+		ctx := context.TODO()
+		state := &pb.BankAccount{AccountId: "account-1", AccountBalance: 100}
+		// pack the state and the event into any pb
+		anyState, err := anypb.New(state)
+		require.NoError(t, err)
+		anyEvent, err := anypb.New(&pb.AccountCredited{AccountId: "account-1", Amount: 50, SystemInitiated: true})
+		require.NoError(t, err)
+		meta := &cospb.MetaData{EntityId: "account-1", RevisionNumber: 2, RevisionDate: timestamppb.Now()}
+		// create mocks
+		dataStore := new(mocks.Storage)
+		dataStore.On("PersistAccount", ctx, mock.Anything, mock.Anything).Return(nil)
+		dataStore.On("PersistAccountEvent", ctx, mock.Anything).Return(nil)
+		dataStore.On("PersistTransactions", ctx, "", mock.Anything).Return(nil)
+		dataStore.On("PersistAuditEntry", ctx, mock.Anything).Return(errors.New("failed"))
+
+		svc, err := NewService(dataStore, nil)
+		require.NoError(t, err)
+
+		// handle the read side request
+		resp, err := svc.HandleReadSide(ctx, &cospb.HandleReadSideRequest{Event: anyEvent, State: anyState, Meta: meta})
+		assert.EqualError(t, err, "rpc error: code = Internal desc = failed to persist the audit entry into the data store: failed")
+		assert.Nil(t, resp)
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With activity dataStore failure", func(t *testing.T) {
+		ctx := context.TODO()
+		state := &pb.BankAccount{AccountId: "account-1", AccountBalance: 100}
+		// pack the state and the event into any pb
+		anyState, err := anypb.New(state)
+		require.NoError(t, err)
+		anyEvent, err := anypb.New(&pb.AccountDebited{AccountId: "account-1", Amount: 50})
+		require.NoError(t, err)
+		meta := &cospb.MetaData{EntityId: "account-1", RevisionNumber: 2, RevisionDate: timestamppb.Now()}
+		// create mocks
+		dataStore := new(mocks.Storage)
+		dataStore.On("PersistAccount", ctx, mock.Anything, mock.Anything).Return(nil)
+		dataStore.On("PersistAccountEvent", ctx, mock.Anything).Return(nil)
+		dataStore.On("PersistTransactions", ctx, "", mock.Anything).Return(nil)
+		dataStore.On("RecordAccountActivity", ctx, "account-1", mock.Anything).Return(errors.New("failed"))
+
+		svc, err := NewService(dataStore, nil)
+		require.NoError(t, err)
+
+		// handle the read side request
+		resp, err := svc.HandleReadSide(ctx, &cospb.HandleReadSideRequest{Event: anyEvent, State: anyState, Meta: meta})
+		assert.EqualError(t, err, "rpc error: code = Internal desc = failed to record the account activity into the data store: failed")
+		assert.Nil(t, resp)
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With transactions dataStore failure", func(t *testing.T) {
+		ctx := context.TODO()
+		state := &pb.BankAccount{AccountId: "account-1", AccountBalance: 100}
+		// pack the state and the event into any pb
+		anyState, err := anypb.New(state)
+		require.NoError(t, err)
+		anyEvent, err := anypb.New(&pb.AccountCredited{AccountId: "account-1", Amount: 50})
+		require.NoError(t, err)
+		// create mocks
+		dataStore := new(mocks.Storage)
+		dataStore.On("PersistAccount", ctx, mock.Anything, mock.Anything).Return(nil)
+		dataStore.On("PersistAccountEvent", ctx, mock.Anything).Return(nil)
+		dataStore.On("PersistTransactions", ctx, "", mock.Anything).Return(errors.New("failed"))
+
+		svc, err := NewService(dataStore, nil)
+		require.NoError(t, err)
+
+		// handle the read side request
+		resp, err := svc.HandleReadSide(ctx, &cospb.HandleReadSideRequest{Event: anyEvent, State: anyState})
+		assert.EqualError(t, err, "rpc error: code = Internal desc = failed to persist transactions into the data store: failed")
+		assert.Nil(t, resp)
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With account events dataStore failure", func(t *testing.T) {
+		ctx := context.TODO()
+		state := &pb.BankAccount{AccountId: "account-1", AccountBalance: 100}
+		// pack the state and the event into any pb
+		anyState, err := anypb.New(state)
+		require.NoError(t, err)
+		anyEvent, err := anypb.New(&pb.AccountCredited{AccountId: "account-1", Amount: 50})
+		require.NoError(t, err)
+		// create mocks
+		dataStore := new(mocks.Storage)
+		dataStore.On("PersistAccount", ctx, mock.Anything, mock.Anything).Return(nil)
+		dataStore.On("PersistAccountEvent", ctx, mock.Anything).Return(errors.New("failed"))
+
+		svc, err := NewService(dataStore, nil)
+		require.NoError(t, err)
+
+		// handle the read side request
+		resp, err := svc.HandleReadSide(ctx, &cospb.HandleReadSideRequest{Event: anyEvent, State: anyState})
+		assert.EqualError(t, err, "rpc error: code = Internal desc = failed to persist the account event into the data store: failed")
+		assert.Nil(t, resp)
+		dataStore.AssertNotCalled(t, "PersistTransactions", mock.Anything, mock.Anything, mock.Anything)
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With standing order state", func(t *testing.T) {
+		ctx := context.TODO()
+		state := &pb.StandingOrder{OrderId: "order-1", AccountId: "account-1"}
+		// pack the state into any pb
+		anyState, err := anypb.New(state)
+		require.NoError(t, err)
+		require.NotNil(t, anyState)
+		// create mocks
+		dataStore := new(mocks.Storage)
+		dataStore.On("PersistStandingOrder", ctx, mock.MatchedBy(func(in *pb.StandingOrder) bool {
+			return proto.Equal(in, state)
 		})).Return(nil)
 
-		svc, err := NewService(dataStore)
+		svc, err := NewService(dataStore, nil)
 		require.NoError(t, err)
 		require.NotNil(t, svc)
 
@@ -79,12 +331,62 @@ func TestHandleReadSide(t *testing.T) {
 		assert.NotNil(t, resp)
 		assert.True(t, resp.GetSuccessful())
 		dataStore.AssertExpectations(t)
+		dataStore.AssertNotCalled(t, "PersistAccount")
+	})
+	t.Run("With standing order event", func(t *testing.T) {
+		ctx := context.TODO()
+		state := &pb.StandingOrder{OrderId: "order-1", AccountId: "account-1"}
+		// pack the state and the event into any pb
+		anyState, err := anypb.New(state)
+		require.NoError(t, err)
+		anyEvent, err := anypb.New(&pb.StandingOrderPaused{OrderId: "order-1", Caller: &pb.Caller{Subject: "customer-1"}})
+		require.NoError(t, err)
+		meta := &cospb.MetaData{EntityId: "order-1", RevisionNumber: 3, RevisionDate: timestamppb.Now()}
+		// create mocks
+		dataStore := new(mocks.Storage)
+		dataStore.On("PersistStandingOrder", ctx, mock.Anything).Return(nil)
+		dataStore.On("PersistAuditEntry", ctx, mock.MatchedBy(func(in *storage.AuditEntry) bool {
+			return in.EntityID == "order-1" && in.RevisionNumber == 3 &&
+				in.EventType == "accounts.v1.StandingOrderPaused" && in.CallerSubject == "customer-1"
+		})).Return(nil)
+
+		svc, err := NewService(dataStore, nil)
+		require.NoError(t, err)
+
+		// handle the read side request
+		resp, err := svc.HandleReadSide(ctx, &cospb.HandleReadSideRequest{Event: anyEvent, State: anyState, Meta: meta})
+		assert.NoError(t, err)
+		assert.True(t, resp.GetSuccessful())
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With standing order dataStore failure", func(t *testing.T) {
+		ctx := context.TODO()
+		state := &pb.StandingOrder{OrderId: "order-1", AccountId: "account-1"}
+		// pack the state into any pb
+		anyState, err := anypb.New(state)
+		require.NoError(t, err)
+		require.NotNil(t, anyState)
+		// create mocks
+		dataStore := new(mocks.Storage)
+		dataStore.On("PersistStandingOrder", ctx, mock.Anything).Return(errors.New("failed"))
+
+		svc, err := NewService(dataStore, nil)
+		require.NoError(t, err)
+		require.NotNil(t, svc)
+
+		// create the read side request with the relevant needed info
+		req := &cospb.HandleReadSideRequest{State: anyState}
+		// handle the read side request
+		resp, err := svc.HandleReadSide(ctx, req)
+		assert.EqualError(t, err, "rpc error: code = Internal desc = failed to persist standing order into the data store: failed")
+		assert.Nil(t, resp)
+		dataStore.AssertExpectations(t)
 	})
 	t.Run("With nil state", func(t *testing.T) {
 		ctx := context.TODO()
 		// create mocks
 		dataStore := new(mocks.Storage)
-		svc, err := NewService(dataStore)
+		svc, err := NewService(dataStore, nil)
 		require.NoError(t, err)
 		require.NotNil(t, svc)
 
@@ -107,7 +409,7 @@ func TestHandleReadSide(t *testing.T) {
 		// create mocks
 		dataStore := new(mocks.Storage)
 
-		svc, err := NewService(dataStore)
+		svc, err := NewService(dataStore, nil)
 		require.NoError(t, err)
 		require.NotNil(t, svc)
 
@@ -132,9 +434,9 @@ func TestHandleReadSide(t *testing.T) {
 		dataStore := new(mocks.Storage)
 		dataStore.On("PersistAccount", ctx, mock.MatchedBy(func(in *pb.BankAccount) bool {
 			return proto.Equal(in, state)
-		})).Return(errors.New("failed"))
+		}), storage.Revision{}).Return(errors.New("failed"))
 
-		svc, err := NewService(dataStore)
+		svc, err := NewService(dataStore, nil)
 		require.NoError(t, err)
 		require.NotNil(t, svc)
 
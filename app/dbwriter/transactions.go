@@ -0,0 +1,88 @@
+package dbwriter
+
+import (
+	"fmt"
+	"math"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
+)
+
+// transactionsBuilder builds the transactions booked by a single account event.
+// The balance after every transaction is derived backward from the resulting state
+type transactionsBuilder struct {
+	state        *pb.BankAccount
+	meta         *cospb.MetaData
+	transactions []*pb.Transaction
+}
+
+// toTransactions returns the transactions booked by the given event
+func toTransactions(event proto.Message, state *pb.BankAccount, meta *cospb.MetaData) []*pb.Transaction {
+	builder := &transactionsBuilder{state: state, meta: meta}
+
+	switch typedEvent := event.(type) {
+	case *pb.AccountOpened:
+		builder.add(pb.TransactionType_OPENING, typedEvent.GetBalance(), "account opened")
+	case *pb.AccountCredited:
+		builder.add(pb.TransactionType_CREDIT, typedEvent.GetAmount(), describe("credit", typedEvent.GetIdempotencyKey()))
+		builder.addFee(typedEvent.GetFee())
+	case *pb.AccountDebited:
+		builder.add(pb.TransactionType_DEBIT, typedEvent.GetAmount(), describe("debit", typedEvent.GetIdempotencyKey()))
+		builder.addFee(typedEvent.GetFee())
+	case *pb.FeeCharged:
+		builder.addFee(typedEvent)
+	case *pb.FeeWaived:
+		builder.add(pb.TransactionType_FEE_WAIVER, typedEvent.GetAmount(), describe("fee waiver", typedEvent.GetReason()))
+	}
+
+	return builder.build()
+}
+
+// add appends a transaction
+func (b *transactionsBuilder) add(transactionType pb.TransactionType, amount float64, description string) {
+	b.transactions = append(b.transactions, &pb.Transaction{
+		AccountId:      b.state.GetAccountId(),
+		RevisionNumber: b.meta.GetRevisionNumber(),
+		Sequence:       int32(len(b.transactions)), // #nosec G115
+		Type:           transactionType,
+		Amount:         amount,
+		Description:    description,
+		BookedAt:       b.meta.GetRevisionDate(),
+	})
+}
+
+// addFee appends the fee transaction when a fee has been charged
+func (b *transactionsBuilder) addFee(fee *pb.FeeCharged) {
+	if fee == nil {
+		return
+	}
+	b.add(pb.TransactionType_FEE, fee.GetAmount(), describe("fee", fee.GetRuleId()))
+}
+
+// build sets the balance after every transaction and returns the transactions
+func (b *transactionsBuilder) build() []*pb.Transaction {
+	balance := b.state.GetAccountBalance()
+	for i := len(b.transactions) - 1; i >= 0; i-- {
+		transaction := b.transactions[i]
+		transaction.BalanceAfter = math.Round(balance*100) / 100
+
+		// compute the balance before the transaction
+		switch transaction.GetType() {
+		case pb.TransactionType_DEBIT, pb.TransactionType_FEE:
+			balance += transaction.GetAmount()
+		default:
+			balance -= transaction.GetAmount()
+		}
+	}
+	return b.transactions
+}
+
+// describe returns the transaction description with the given reference when set
+func describe(label, reference string) string {
+	if reference == "" {
+		return label
+	}
+	return fmt.Sprintf("%s (%s)", label, reference)
+}
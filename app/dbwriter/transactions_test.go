@@ -0,0 +1,97 @@
+package dbwriter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
+)
+
+func TestToTransactions(t *testing.T) {
+	bookedAt := timestamppb.Now()
+	meta := &cospb.MetaData{EntityId: "account-1", RevisionNumber: 3, RevisionDate: bookedAt}
+
+	t.Run("With account opened", func(t *testing.T) {
+		state := &pb.BankAccount{AccountId: "account-1", AccountBalance: 100}
+		event := &pb.AccountOpened{AccountId: "account-1", Balance: 100}
+
+		expected := &pb.Transaction{
+			AccountId:      "account-1",
+			RevisionNumber: 3,
+			Type:           pb.TransactionType_OPENING,
+			Amount:         100,
+			BalanceAfter:   100,
+			Description:    "account opened",
+			BookedAt:       bookedAt,
+		}
+
+		actual := toTransactions(event, state, meta)
+		require.Len(t, actual, 1)
+		assert.True(t, proto.Equal(expected, actual[0]))
+	})
+	t.Run("With account debited with a fee", func(t *testing.T) {
+		state := &pb.BankAccount{AccountId: "account-1", AccountBalance: 49}
+		event := &pb.AccountDebited{
+			AccountId:      "account-1",
+			Amount:         50,
+			IdempotencyKey: "order-1/2024-01-01/debit",
+			Fee:            &pb.FeeCharged{AccountId: "account-1", RuleId: "debit-fee", Amount: 1},
+		}
+
+		expected := []*pb.Transaction{
+			{
+				AccountId:      "account-1",
+				RevisionNumber: 3,
+				Type:           pb.TransactionType_DEBIT,
+				Amount:         50,
+				BalanceAfter:   50,
+				Description:    "debit (order-1/2024-01-01/debit)",
+				BookedAt:       bookedAt,
+			},
+			{
+				AccountId:      "account-1",
+				RevisionNumber: 3,
+				Sequence:       1,
+				Type:           pb.TransactionType_FEE,
+				Amount:         1,
+				BalanceAfter:   49,
+				Description:    "fee (debit-fee)",
+				BookedAt:       bookedAt,
+			},
+		}
+
+		actual := toTransactions(event, state, meta)
+		require.Len(t, actual, 2)
+		assert.True(t, proto.Equal(expected[0], actual[0]))
+		assert.True(t, proto.Equal(expected[1], actual[1]))
+	})
+	t.Run("With account credited", func(t *testing.T) {
+		state := &pb.BankAccount{AccountId: "account-1", AccountBalance: 150.55}
+		event := &pb.AccountCredited{AccountId: "account-1", Amount: 50.55}
+
+		actual := toTransactions(event, state, meta)
+		require.Len(t, actual, 1)
+		assert.Equal(t, pb.TransactionType_CREDIT, actual[0].GetType())
+		assert.Equal(t, 150.55, actual[0].GetBalanceAfter())
+		assert.Equal(t, "credit", actual[0].GetDescription())
+	})
+	t.Run("With fee waived", func(t *testing.T) {
+		state := &pb.BankAccount{AccountId: "account-1", AccountBalance: 10}
+		event := &pb.FeeWaived{AccountId: "account-1", Amount: 2, Reason: "goodwill"}
+
+		actual := toTransactions(event, state, meta)
+		require.Len(t, actual, 1)
+		assert.Equal(t, pb.TransactionType_FEE_WAIVER, actual[0].GetType())
+		assert.Equal(t, "fee waiver (goodwill)", actual[0].GetDescription())
+	})
+	t.Run("With event not booking any transaction", func(t *testing.T) {
+		state := &pb.BankAccount{AccountId: "account-1"}
+		assert.Empty(t, toTransactions(&pb.StandingOrderPaused{OrderId: "order-1"}, state, meta))
+		assert.Empty(t, toTransactions(nil, state, meta))
+	})
+}
@@ -0,0 +1,29 @@
+package dormancy
+
+import (
+	"github.com/caarlos0/env/v9"
+	"github.com/pkg/errors"
+
+	"github.com/tochemey/cos-go-sample/app/grpconfig"
+)
+
+// Config defines the dormancy sweep config
+type Config struct {
+	CosHost          string                    `env:"COS_HOST"`                                   // CosHost is used to connect to ChiefOfState
+	CosPort          int                       `env:"COS_PORT"`                                   // CosPort is used to connect to ChiefOfState
+	InactivityMonths int                       `env:"DORMANCY_INACTIVITY_MONTHS" envDefault:"12"` // InactivityMonths is the number of months without customer activity after which an account is dormant
+	BatchSize        int                       `env:"DORMANCY_BATCH_SIZE" envDefault:"100"`       // BatchSize is the maximum number of accounts fetched per lookup
+	CosTLS           grpconfig.ClientTLSConfig // CosTLS is used to secure the connections to ChiefOfState
+}
+
+// LoadConfig fetches the Config from env vars
+func LoadConfig() *Config {
+	config := &Config{}
+	// all env vars are required
+	opts := env.Options{RequiredIfNoDef: true}
+	if err := env.ParseWithOptions(config, opts); err != nil {
+		panic(errors.Wrap(err, "unable to load environment variables"))
+	}
+
+	return config
+}
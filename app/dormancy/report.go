@@ -0,0 +1,64 @@
+package dormancy
+
+import (
+	"encoding/csv"
+	"io"
+	"time"
+)
+
+// Entry holds the outcome of the dormancy sweep for a single account
+type Entry struct {
+	AccountID      string
+	AccountOwner   string
+	LastActivityAt time.Time
+	Err            error // Err is set when the account could not be marked dormant
+}
+
+// Report holds the accounts affected by a dormancy sweep
+type Report struct {
+	InactiveSince time.Time // InactiveSince is the time since which the swept accounts had no customer activity
+	Entries       []*Entry
+}
+
+// Failed returns the number of accounts which could not be marked dormant
+func (r *Report) Failed() int {
+	failed := 0
+	for _, entry := range r.Entries {
+		if entry.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+// WriteCSV writes the report entries as CSV
+func (r *Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	// write the header
+	if err := writer.Write([]string{"account_id", "account_owner", "last_activity_at", "status", "error"}); err != nil {
+		return err
+	}
+
+	// write the entries
+	for _, entry := range r.Entries {
+		status, reason := "DORMANT", ""
+		if entry.Err != nil {
+			status, reason = "FAILED", entry.Err.Error()
+		}
+
+		record := []string{
+			entry.AccountID,
+			entry.AccountOwner,
+			entry.LastActivityAt.UTC().Format(time.RFC3339),
+			status,
+			reason,
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
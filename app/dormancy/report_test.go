@@ -0,0 +1,34 @@
+package dormancy
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	lastActivityAt := time.Date(2023, time.January, 10, 8, 0, 0, 0, time.UTC)
+	report := &Report{
+		Entries: []*Entry{
+			{AccountID: "account-1", AccountOwner: "John Doe", LastActivityAt: lastActivityAt},
+			{AccountID: "account-2", AccountOwner: "Mr Smith", LastActivityAt: lastActivityAt, Err: errors.New("unavailable")},
+		},
+	}
+
+	t.Run("With failed entries", func(t *testing.T) {
+		assert.Equal(t, 1, report.Failed())
+	})
+	t.Run("With CSV output", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		require.NoError(t, report.WriteCSV(buffer))
+
+		expected := "account_id,account_owner,last_activity_at,status,error\n" +
+			"account-1,John Doe,2023-01-10T08:00:00Z,DORMANT,\n" +
+			"account-2,Mr Smith,2023-01-10T08:00:00Z,FAILED,unavailable\n"
+		assert.Equal(t, expected, buffer.String())
+	})
+}
@@ -0,0 +1,104 @@
+package dormancy
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tochemey/gopack/otel/trace"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/tochemey/cos-go-sample/app/cos"
+	"github.com/tochemey/cos-go-sample/app/log"
+	"github.com/tochemey/cos-go-sample/app/storage"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// Sweeper marks dormant the accounts without customer activity for the configured number of months.
+// The inactive accounts are looked up in the read model and marked dormant on the write side, which reactivates them
+// on the next credit or debit initiated by the customer
+type Sweeper struct {
+	dataStore storage.Storage
+	cosClient cos.Client
+	config    *Config
+	now       func() time.Time
+}
+
+// New creates a new instance of Sweeper
+func New(dataStore storage.Storage, cosClient cos.Client, config *Config) (*Sweeper, error) {
+	// check whether the data store is defined or not
+	if dataStore == nil {
+		return nil, errors.New("the dataStore is not defined")
+	}
+
+	// check whether the cos client is defined or not
+	if cosClient == nil {
+		return nil, errors.New("the cosClient is not defined")
+	}
+
+	// check whether the config is defined or not
+	if config == nil {
+		return nil, errors.New("the config is not defined")
+	}
+
+	// return the new instance of Sweeper
+	return &Sweeper{
+		dataStore: dataStore,
+		cosClient: cosClient,
+		config:    config,
+		now:       time.Now,
+	}, nil
+}
+
+// Sweep marks dormant the accounts inactive at the time of the call and returns the report of the affected accounts.
+// A failure to mark an account is recorded in the report and does not stop the sweep
+func (s *Sweeper) Sweep(ctx context.Context) (*Report, error) {
+	// set the observability span
+	ctx, span := trace.SpanContext(ctx, "Sweep")
+	defer span.End()
+
+	// get the context logger
+	logger := log.WithContext(ctx)
+
+	report := &Report{InactiveSince: s.now().AddDate(0, -s.config.InactivityMonths, 0)}
+
+	// page through the inactive accounts
+	var cursor *storage.AccountActivity
+	for {
+		accounts, err := s.dataStore.GetInactiveAccounts(ctx, report.InactiveSince, cursor, s.config.BatchSize)
+		if err != nil {
+			return report, errors.Wrap(err, "failed to fetch the inactive accounts")
+		}
+
+		for _, account := range accounts {
+			// stop when the sweep is cancelled
+			if ctx.Err() != nil {
+				return report, ctx.Err()
+			}
+
+			entry := &Entry{
+				AccountID:      account.AccountID,
+				AccountOwner:   account.AccountOwner,
+				LastActivityAt: account.LastActivityAt,
+			}
+
+			// mark the account dormant
+			command := &pb.MarkAccountDormant{
+				AccountId:      account.AccountID,
+				LastActivityAt: timestamppb.New(account.LastActivityAt),
+			}
+			if _, _, err := s.cosClient.ProcessCommand(ctx, account.AccountID, command); err != nil {
+				logger.Warnf("failed to mark the account:(%s) dormant: %v", account.AccountID, err)
+				entry.Err = err
+			}
+
+			report.Entries = append(report.Entries, entry)
+		}
+
+		// this is the last page
+		if len(accounts) < s.config.BatchSize {
+			return report, nil
+		}
+		cursor = accounts[len(accounts)-1]
+	}
+}
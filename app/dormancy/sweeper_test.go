@@ -0,0 +1,128 @@
+package dormancy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/tochemey/cos-go-sample/app/storage"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	cosmocks "github.com/tochemey/cos-go-sample/mocks/app/cos"
+	mocks "github.com/tochemey/cos-go-sample/mocks/app/storage"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("With happy path", func(t *testing.T) {
+		sweeper, err := New(new(mocks.Storage), new(cosmocks.Client), &Config{})
+		assert.NoError(t, err)
+		assert.NotNil(t, sweeper)
+	})
+	t.Run("With data store not set", func(t *testing.T) {
+		sweeper, err := New(nil, new(cosmocks.Client), &Config{})
+		assert.EqualError(t, err, "the dataStore is not defined")
+		assert.Nil(t, sweeper)
+	})
+	t.Run("With cos client not set", func(t *testing.T) {
+		sweeper, err := New(new(mocks.Storage), nil, &Config{})
+		assert.EqualError(t, err, "the cosClient is not defined")
+		assert.Nil(t, sweeper)
+	})
+	t.Run("With config not set", func(t *testing.T) {
+		sweeper, err := New(new(mocks.Storage), new(cosmocks.Client), nil)
+		assert.EqualError(t, err, "the config is not defined")
+		assert.Nil(t, sweeper)
+	})
+}
+
+func TestSweep(t *testing.T) {
+	now := time.Date(2024, time.January, 10, 8, 0, 0, 0, time.UTC)
+	inactiveSince := time.Date(2023, time.January, 10, 8, 0, 0, 0, time.UTC)
+	config := &Config{InactivityMonths: 12, BatchSize: 2}
+
+	account1 := &storage.AccountActivity{AccountID: "account-1", AccountOwner: "John Doe", LastActivityAt: inactiveSince.AddDate(0, -3, 0)}
+	account2 := &storage.AccountActivity{AccountID: "account-2", AccountOwner: "Mr Smith", LastActivityAt: inactiveSince.AddDate(0, -2, 0)}
+	account3 := &storage.AccountActivity{AccountID: "account-3", AccountOwner: "Lady G.", LastActivityAt: inactiveSince.AddDate(0, -1, 0)}
+
+	// markDormant matches the mark account dormant command of the given account
+	markDormant := func(account *storage.AccountActivity) any {
+		return mock.MatchedBy(func(in *pb.MarkAccountDormant) bool {
+			return proto.Equal(in, &pb.MarkAccountDormant{
+				AccountId:      account.AccountID,
+				LastActivityAt: timestamppb.New(account.LastActivityAt),
+			})
+		})
+	}
+
+	t.Run("With inactive accounts", func(t *testing.T) {
+		ctx := context.TODO()
+		dataStore := new(mocks.Storage)
+		cosClient := new(cosmocks.Client)
+
+		// the inactive accounts are paged through
+		dataStore.On("GetInactiveAccounts", mock.Anything, inactiveSince, (*storage.AccountActivity)(nil), 2).
+			Return([]*storage.AccountActivity{account1, account2}, nil)
+		dataStore.On("GetInactiveAccounts", mock.Anything, inactiveSince, account2, 2).
+			Return([]*storage.AccountActivity{account3}, nil)
+		cosClient.On("ProcessCommand", mock.Anything, "account-1", markDormant(account1)).Return(new(pb.BankAccount), nil, nil)
+		cosClient.On("ProcessCommand", mock.Anything, "account-2", markDormant(account2)).Return(nil, nil, errors.New("unavailable"))
+		cosClient.On("ProcessCommand", mock.Anything, "account-3", markDormant(account3)).Return(new(pb.BankAccount), nil, nil)
+
+		sweeper, err := New(dataStore, cosClient, config)
+		require.NoError(t, err)
+		sweeper.now = func() time.Time { return now }
+
+		report, err := sweeper.Sweep(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, report)
+		assert.True(t, inactiveSince.Equal(report.InactiveSince))
+		require.Len(t, report.Entries, 3)
+		assert.Equal(t, "account-1", report.Entries[0].AccountID)
+		assert.NoError(t, report.Entries[0].Err)
+		assert.EqualError(t, report.Entries[1].Err, "unavailable")
+		assert.Equal(t, 1, report.Failed())
+		dataStore.AssertExpectations(t)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With no inactive account", func(t *testing.T) {
+		ctx := context.TODO()
+		dataStore := new(mocks.Storage)
+		cosClient := new(cosmocks.Client)
+
+		dataStore.On("GetInactiveAccounts", mock.Anything, inactiveSince, (*storage.AccountActivity)(nil), 2).
+			Return([]*storage.AccountActivity{}, nil)
+
+		sweeper, err := New(dataStore, cosClient, config)
+		require.NoError(t, err)
+		sweeper.now = func() time.Time { return now }
+
+		report, err := sweeper.Sweep(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, report)
+		assert.Empty(t, report.Entries)
+		cosClient.AssertNotCalled(t, "ProcessCommand", mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("With dataStore failure", func(t *testing.T) {
+		ctx := context.TODO()
+		dataStore := new(mocks.Storage)
+		cosClient := new(cosmocks.Client)
+
+		dataStore.On("GetInactiveAccounts", mock.Anything, inactiveSince, (*storage.AccountActivity)(nil), 2).
+			Return(nil, errors.New("failed"))
+
+		sweeper, err := New(dataStore, cosClient, config)
+		require.NoError(t, err)
+		sweeper.now = func() time.Time { return now }
+
+		report, err := sweeper.Sweep(ctx)
+		assert.EqualError(t, err, "failed to fetch the inactive accounts: failed")
+		require.NotNil(t, report)
+		assert.Empty(t, report.Entries)
+	})
+}
@@ -0,0 +1,23 @@
+package gateway
+
+import (
+	"github.com/caarlos0/env/v9"
+	"github.com/pkg/errors"
+)
+
+// Config defines the HTTP/JSON gateway config
+type Config struct {
+	Port int `env:"GATEWAY_PORT" envDefault:"8080"` // Port is the port used to receive and handle the HTTP/JSON requests
+}
+
+// LoadConfig fetches the Config from env vars
+func LoadConfig() *Config {
+	config := &Config{}
+	// all env vars are required
+	opts := env.Options{RequiredIfNoDef: true}
+	if err := env.ParseWithOptions(config, opts); err != nil {
+		panic(errors.Wrap(err, "unable to load environment variables"))
+	}
+
+	return config
+}
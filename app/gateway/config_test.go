@@ -0,0 +1,37 @@
+package gateway
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("With default values", func(t *testing.T) {
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.Equal(t, 8080, actual.Port)
+	})
+	t.Run("With environment variables set", func(t *testing.T) {
+		assert.NoError(t, os.Setenv("GATEWAY_PORT", "9090"))
+
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.Equal(t, 9090, actual.Port)
+
+		// free resources
+		assert.NoError(t, os.Unsetenv("GATEWAY_PORT"))
+	})
+	t.Run("With invalid environment variables", func(t *testing.T) {
+		assert.NoError(t, os.Setenv("GATEWAY_PORT", "not-a-port"))
+
+		assert.Panics(t, func() {
+			LoadConfig()
+		})
+
+		// free resources
+		assert.NoError(t, os.Unsetenv("GATEWAY_PORT"))
+	})
+}
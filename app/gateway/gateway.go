@@ -0,0 +1,132 @@
+package gateway
+
+import (
+	"context"
+	"crypto/tls"
+	_ "embed"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/tochemey/cos-go-sample/app/grpconfig"
+	"github.com/tochemey/cos-go-sample/app/log"
+	"github.com/tochemey/cos-go-sample/app/tenant"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// OpenAPIPath is the well-known path serving the OpenAPI document of the HTTP/JSON routes
+const OpenAPIPath = "/openapi.json"
+
+// openAPIDocument is the OpenAPI document generated from the service protobuf definitions
+//
+//go:embed apidocs/accounts.swagger.json
+var openAPIDocument []byte
+
+// Gateway serves the accounts api service over HTTP/JSON.
+// Every request is proxied to the gRPC service so that it goes through the same interceptors.
+type Gateway struct {
+	server *http.Server
+	conn   *grpc.ClientConn
+}
+
+// New creates an instance of Gateway proxying the HTTP/JSON requests to the gRPC service
+// listening on the given port of the same process. The connection to the gRPC service uses the given TLS configuration when it is set
+func New(ctx context.Context, grpcPort int, tlsConfig *tls.Config, config *Config) (*Gateway, error) {
+	// get the grpc client connection to the local service
+	conn, err := grpconfig.NewConn(fmt.Sprintf("localhost:%d", grpcPort), tlsConfig)
+	// handle the error
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to the grpc service")
+	}
+
+	// create the http handler
+	handler, err := NewHandler(ctx, conn)
+	// handle the error
+	if err != nil {
+		return nil, err
+	}
+
+	return &Gateway{
+		server: &http.Server{
+			Addr:    fmt.Sprintf(":%d", config.Port),
+			Handler: handler,
+		},
+		conn: conn,
+	}, nil
+}
+
+// NewHandler creates the http handler serving the HTTP/JSON routes over the given grpc client connection.
+// The messages are encoded with protojson and the gRPC status codes are mapped to their HTTP equivalent.
+func NewHandler(ctx context.Context, conn *grpc.ClientConn) (http.Handler, error) {
+	// create the routes multiplexer
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				UseProtoNames:   true,
+				EmitUnpopulated: true,
+			},
+			UnmarshalOptions: protojson.UnmarshalOptions{
+				DiscardUnknown: true,
+			},
+		}),
+		runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher),
+	)
+
+	// register the service routes
+	if err := pb.RegisterBankAccountServiceHandler(ctx, mux, conn); err != nil {
+		return nil, errors.Wrap(err, "failed to register the service routes")
+	}
+
+	// serve the OpenAPI document
+	if err := mux.HandlePath(http.MethodGet, OpenAPIPath, serveOpenAPIDocument); err != nil {
+		return nil, errors.Wrap(err, "failed to register the OpenAPI document route")
+	}
+
+	return mux, nil
+}
+
+// incomingHeaderMatcher forwards the tenant header to the grpc service along with the headers forwarded by default
+func incomingHeaderMatcher(key string) (string, bool) {
+	if strings.EqualFold(key, tenant.Header) {
+		return tenant.Header, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// Start starts the gateway. The requests are served in the background
+func (g *Gateway) Start() error {
+	// listen on the gateway address
+	listener, err := net.Listen("tcp", g.server.Addr)
+	// handle the error
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen on (%s)", g.server.Addr)
+	}
+
+	go func() {
+		if err := g.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error(errors.Wrap(err, "the gateway stopped unexpectedly"))
+		}
+	}()
+
+	return nil
+}
+
+// Stop gracefully stops the gateway and releases the grpc client connection
+func (g *Gateway) Stop(ctx context.Context) error {
+	if err := g.server.Shutdown(ctx); err != nil {
+		return errors.Wrap(err, "failed to stop the gateway")
+	}
+	return g.conn.Close()
+}
+
+// serveOpenAPIDocument writes the OpenAPI document
+func serveOpenAPIDocument(w http.ResponseWriter, _ *http.Request, _ map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(openAPIDocument)
+}
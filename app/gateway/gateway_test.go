@@ -0,0 +1,220 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	gopack "github.com/tochemey/gopack/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/tochemey/cos-go-sample/app/service"
+	"github.com/tochemey/cos-go-sample/app/tenant"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
+	mocks "github.com/tochemey/cos-go-sample/mocks/app/cos"
+)
+
+// newTestHandler creates the gateway handler proxying to an in-process accounts service isolating the given tenants
+func newTestHandler(t *testing.T, cosClient *mocks.Client, tenantIDs ...string) http.Handler {
+	ctx := context.TODO()
+	// create the in-process grpc server
+	testServer := gopack.NewInProcessServerBuilder().Build()
+	var tenants *tenant.Resolver
+	if len(tenantIDs) > 0 {
+		tenants = tenant.NewResolver(tenantIDs...)
+	}
+	testServer.RegisterService(service.NewService(cosClient, nil, nil, nil, nil, nil, tenants, nil, nil).RegisterService)
+	require.NoError(t, testServer.Start())
+	t.Cleanup(testServer.Cleanup)
+
+	// create the grpc client connection
+	conn, err := gopack.TestClientConn(ctx, testServer.GetListener(), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	handler, err := NewHandler(ctx, conn)
+	require.NoError(t, err)
+	return handler
+}
+
+func TestNewHandler(t *testing.T) {
+	t.Run("With POST /v1/accounts", func(t *testing.T) {
+		accountID := "account-1"
+		// create the expected state
+		account := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 200,
+			AccountOwner:   "John Doe",
+			AccountType:    pb.AccountType_SAVINGS,
+		}
+		// create the expected command
+		command := &pb.OpenAccount{
+			AccountId:      accountID,
+			AccountOwner:   "John Doe",
+			OpeningBalance: 200,
+			AccountType:    pb.AccountType_SAVINGS,
+		}
+
+		cosClient := new(mocks.Client)
+		cosClient.
+			On("ProcessCommand", mock.Anything, accountID, mock.MatchedBy(func(actual *pb.OpenAccount) bool {
+				return proto.Equal(command, actual)
+			})).
+			Return(account, &cospb.MetaData{}, nil)
+
+		handler := newTestHandler(t, cosClient)
+
+		body := `{"account_id": "account-1", "account_owner": "John Doe", "balance": 200, "account_type": "SAVINGS"}`
+		request := httptest.NewRequest(http.MethodPost, "/v1/accounts", strings.NewReader(body))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		var response accountResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, accountID, response.Account["account_id"])
+		assert.Equal(t, "SAVINGS", response.Account["account_type"])
+		// unpopulated fields are emitted
+		assert.Equal(t, false, response.Account["is_closed"])
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With POST /v1/accounts/{account_id}:credit", func(t *testing.T) {
+		accountID := "account-1"
+		account := &pb.BankAccount{AccountId: accountID, AccountBalance: 250}
+		command := &pb.CreditAccount{AccountId: accountID, Amount: 50}
+
+		cosClient := new(mocks.Client)
+		cosClient.
+			On("ProcessCommand", mock.Anything, accountID, mock.MatchedBy(func(actual *pb.CreditAccount) bool {
+				return proto.Equal(command, actual)
+			})).
+			Return(account, &cospb.MetaData{}, nil)
+
+		handler := newTestHandler(t, cosClient)
+
+		request := httptest.NewRequest(http.MethodPost, "/v1/accounts/account-1:credit", strings.NewReader(`{"amount": 50}`))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		var response accountResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.EqualValues(t, 250, response.Account["account_balance"])
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With GET /v1/accounts/{account_id}", func(t *testing.T) {
+		accountID := "account-1"
+		account := &pb.BankAccount{AccountId: accountID, AccountBalance: 250}
+
+		cosClient := new(mocks.Client)
+		cosClient.On("GetState", mock.Anything, accountID).Return(account, &cospb.MetaData{RevisionNumber: 3}, nil)
+
+		handler := newTestHandler(t, cosClient)
+
+		request := httptest.NewRequest(http.MethodGet, "/v1/accounts/account-1", nil)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		var response accountResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, accountID, response.Account["account_id"])
+		assert.EqualValues(t, 3, response.RevisionNumber)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With tenant header", func(t *testing.T) {
+		account := &pb.BankAccount{AccountId: "acme/account-1", AccountBalance: 250, TenantId: "acme"}
+
+		cosClient := new(mocks.Client)
+		cosClient.On("GetState", mock.Anything, "acme/account-1").Return(account, &cospb.MetaData{}, nil)
+
+		handler := newTestHandler(t, cosClient, "acme")
+
+		// the tenant header is forwarded to the service
+		request := httptest.NewRequest(http.MethodGet, "/v1/accounts/account-1", nil)
+		request.Header.Set("X-Tenant-Id", "acme")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		var response accountResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, "account-1", response.Account["account_id"])
+		cosClient.AssertExpectations(t)
+
+		// the requests without tenant are rejected
+		request = httptest.NewRequest(http.MethodGet, "/v1/accounts/account-1", nil)
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+	t.Run("With gRPC status code mapped to the HTTP status code", func(t *testing.T) {
+		cosClient := new(mocks.Client)
+		cosClient.
+			On("GetState", mock.Anything, "account-1").
+			Return(nil, nil, status.Error(codes.NotFound, "account not found"))
+
+		handler := newTestHandler(t, cosClient)
+
+		request := httptest.NewRequest(http.MethodGet, "/v1/accounts/account-1", nil)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		require.Equal(t, http.StatusNotFound, recorder.Code)
+		var response map[string]any
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.EqualValues(t, codes.NotFound, response["code"])
+		assert.Equal(t, "account not found", response["message"])
+	})
+	t.Run("With invalid request body", func(t *testing.T) {
+		handler := newTestHandler(t, new(mocks.Client))
+
+		request := httptest.NewRequest(http.MethodPost, "/v1/accounts", strings.NewReader(`{"balance": "invalid"`))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+	t.Run("With OpenAPI document", func(t *testing.T) {
+		handler := newTestHandler(t, new(mocks.Client))
+
+		request := httptest.NewRequest(http.MethodGet, OpenAPIPath, nil)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		require.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		var document map[string]any
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &document))
+		paths, ok := document["paths"].(map[string]any)
+		require.True(t, ok)
+		assert.Contains(t, paths, "/v1/accounts")
+		assert.Contains(t, paths, "/v1/accounts/{account_id}")
+	})
+}
+
+func TestGateway(t *testing.T) {
+	ctx := context.TODO()
+	gateway, err := New(ctx, 50051, nil, &Config{Port: 0})
+	require.NoError(t, err)
+	require.NotNil(t, gateway)
+
+	require.NoError(t, gateway.Start())
+	assert.NoError(t, gateway.Stop(ctx))
+}
+
+// accountResponse is the JSON body of the account responses
+type accountResponse struct {
+	Account        map[string]any `json:"account"`
+	RevisionNumber int32          `json:"revision_number"`
+}
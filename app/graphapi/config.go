@@ -0,0 +1,24 @@
+package graphapi
+
+import (
+	"github.com/caarlos0/env/v9"
+	"github.com/pkg/errors"
+)
+
+// Config defines the GraphQL server config
+type Config struct {
+	Port     int `env:"GRAPHQL_PORT" envDefault:"8090"`   // Port is the port used to receive and handle the GraphQL requests
+	MaxDepth int `env:"GRAPHQL_MAX_DEPTH" envDefault:"8"` // MaxDepth is the maximum nesting depth of a GraphQL query
+}
+
+// LoadConfig fetches the Config from env vars
+func LoadConfig() *Config {
+	config := &Config{}
+	// all env vars are required
+	opts := env.Options{RequiredIfNoDef: true}
+	if err := env.ParseWithOptions(config, opts); err != nil {
+		panic(errors.Wrap(err, "unable to load environment variables"))
+	}
+
+	return config
+}
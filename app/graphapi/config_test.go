@@ -0,0 +1,41 @@
+package graphapi
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("With default values", func(t *testing.T) {
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.Equal(t, 8090, actual.Port)
+		assert.Equal(t, 8, actual.MaxDepth)
+	})
+	t.Run("With environment variables set", func(t *testing.T) {
+		assert.NoError(t, os.Setenv("GRAPHQL_PORT", "9090"))
+		assert.NoError(t, os.Setenv("GRAPHQL_MAX_DEPTH", "5"))
+
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.Equal(t, 9090, actual.Port)
+		assert.Equal(t, 5, actual.MaxDepth)
+
+		// free resources
+		assert.NoError(t, os.Unsetenv("GRAPHQL_PORT"))
+		assert.NoError(t, os.Unsetenv("GRAPHQL_MAX_DEPTH"))
+	})
+	t.Run("With invalid environment variables", func(t *testing.T) {
+		assert.NoError(t, os.Setenv("GRAPHQL_PORT", "not-a-port"))
+
+		assert.Panics(t, func() {
+			LoadConfig()
+		})
+
+		// free resources
+		assert.NoError(t, os.Unsetenv("GRAPHQL_PORT"))
+	})
+}
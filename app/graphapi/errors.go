@@ -0,0 +1,36 @@
+package graphapi
+
+import (
+	"google.golang.org/grpc/status"
+
+	"github.com/tochemey/cos-go-sample/app/writeside/commands"
+)
+
+// queryError is the error returned by the resolvers. It exposes the gRPC code and the reason of the errors
+// returned by the accounts api service as GraphQL error extensions
+type queryError struct {
+	status *status.Status
+	reason commands.Reason
+}
+
+// toQueryError converts the given error into a queryError
+func toQueryError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &queryError{status: status.Convert(err), reason: commands.ReasonOf(err)}
+}
+
+// Error returns the error message
+func (e *queryError) Error() string {
+	return e.status.Message()
+}
+
+// Extensions returns the GraphQL error extensions
+func (e *queryError) Extensions() map[string]any {
+	extensions := map[string]any{"code": e.status.Code().String()}
+	if e.reason != "" {
+		extensions["reason"] = string(e.reason)
+	}
+	return extensions
+}
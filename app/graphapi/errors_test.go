@@ -0,0 +1,36 @@
+package graphapi
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToQueryError(t *testing.T) {
+	t.Run("With domain error", func(t *testing.T) {
+		st, err := status.New(codes.FailedPrecondition, "insufficient balance").
+			WithDetails(&errdetails.ErrorInfo{Reason: "INSUFFICIENT_BALANCE", Domain: "accounts.v1"})
+		assert.NoError(t, err)
+
+		actual := toQueryError(st.Err())
+		assert.EqualError(t, actual, "insufficient balance")
+		assert.Equal(t, map[string]any{"code": "FailedPrecondition", "reason": "INSUFFICIENT_BALANCE"}, actual.(*queryError).Extensions())
+	})
+	t.Run("With status error", func(t *testing.T) {
+		actual := toQueryError(status.Error(codes.NotFound, "the account:(account-1) is not found"))
+		assert.EqualError(t, actual, "the account:(account-1) is not found")
+		assert.Equal(t, map[string]any{"code": "NotFound"}, actual.(*queryError).Extensions())
+	})
+	t.Run("With other error", func(t *testing.T) {
+		actual := toQueryError(errors.New("boom"))
+		assert.EqualError(t, actual, "boom")
+		assert.Equal(t, map[string]any{"code": "Unknown"}, actual.(*queryError).Extensions())
+	})
+	t.Run("With no error", func(t *testing.T) {
+		assert.NoError(t, toQueryError(nil))
+	})
+}
@@ -0,0 +1,65 @@
+package graphapi
+
+import (
+	"context"
+
+	"github.com/graph-gophers/dataloader/v7"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/tochemey/cos-go-sample/app/service"
+	"github.com/tochemey/cos-go-sample/app/storage"
+)
+
+// accountLoader batches the accounts lookups of a GraphQL request into single storage.GetAccounts calls
+// so that resolving the accounts of a list does not issue a query per account
+type accountLoader = dataloader.Loader[string, *storage.AccountRecord]
+
+// accountLoaderKey is the context key of the accountLoader of a request
+type accountLoaderKey struct{}
+
+// newAccountLoader creates an accountLoader fetching the accounts from the given data store within the tenant
+// resolved by the given function. A batch holds at most service.MaxBatchSize accounts
+func newAccountLoader(dataStore storage.Storage, tenantID func(ctx context.Context) (string, error)) *accountLoader {
+	return dataloader.NewBatchedLoader(
+		func(ctx context.Context, accountIDs []string) []*dataloader.Result[*storage.AccountRecord] {
+			results := make([]*dataloader.Result[*storage.AccountRecord], len(accountIDs))
+
+			// fetch the accounts from the read model within the tenant of the request
+			tenantID, err := tenantID(ctx)
+			var records []*storage.AccountRecord
+			if err == nil {
+				if records, err = dataStore.GetAccounts(ctx, tenantID, accountIDs); err != nil {
+					err = status.Error(codes.Internal, err.Error())
+				}
+			}
+			if err != nil {
+				for index := range results {
+					results[index] = &dataloader.Result[*storage.AccountRecord]{Error: err}
+				}
+				return results
+			}
+
+			// the records are in the order of the account ids and nil when not found
+			for index := range results {
+				results[index] = &dataloader.Result[*storage.AccountRecord]{Data: records[index]}
+			}
+			return results
+		},
+		dataloader.WithBatchCapacity[string, *storage.AccountRecord](service.MaxBatchSize),
+	)
+}
+
+// withAccountLoader returns a copy of the context holding the given accountLoader
+func withAccountLoader(ctx context.Context, loader *accountLoader) context.Context {
+	return context.WithValue(ctx, accountLoaderKey{}, loader)
+}
+
+// accountLoaderFrom returns the accountLoader of the request
+func accountLoaderFrom(ctx context.Context) (*accountLoader, error) {
+	loader, ok := ctx.Value(accountLoaderKey{}).(*accountLoader)
+	if !ok {
+		return nil, status.Error(codes.Internal, "the accounts loader is not set")
+	}
+	return loader, nil
+}
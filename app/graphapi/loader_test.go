@@ -0,0 +1,99 @@
+package graphapi
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/tochemey/cos-go-sample/app/storage"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	mocks "github.com/tochemey/cos-go-sample/mocks/app/storage"
+)
+
+func TestAccountLoader(t *testing.T) {
+	t.Run("With concurrent loads batched", func(t *testing.T) {
+		ctx := context.TODO()
+		record := &storage.AccountRecord{Account: &pb.BankAccount{AccountId: "account-1"}}
+
+		dataStore := new(mocks.Storage)
+		dataStore.
+			On("GetAccounts", mock.Anything, "acme", mock.MatchedBy(func(accountIDs []string) bool {
+				return assert.ElementsMatch(t, []string{"account-1", "account-2"}, accountIDs)
+			})).
+			Return(func(_ context.Context, _ string, accountIDs []string) []*storage.AccountRecord {
+				records := make([]*storage.AccountRecord, len(accountIDs))
+				for index, accountID := range accountIDs {
+					if accountID == "account-1" {
+						records[index] = record
+					}
+				}
+				return records
+			}, nil).
+			Once()
+
+		loader := newAccountLoader(dataStore, tenantOf("acme", nil))
+
+		// load the accounts concurrently as the resolvers of a list do
+		accountIDs := []string{"account-1", "account-2", "account-1"}
+		actual := make([]*storage.AccountRecord, len(accountIDs))
+		wg := sync.WaitGroup{}
+		for index, accountID := range accountIDs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				var err error
+				actual[index], err = loader.Load(ctx, accountID)()
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, []*storage.AccountRecord{record, nil, record}, actual)
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With storage failure", func(t *testing.T) {
+		ctx := context.TODO()
+
+		dataStore := new(mocks.Storage)
+		dataStore.
+			On("GetAccounts", mock.Anything, "", []string{"account-1"}).
+			Return(nil, errors.New("connection refused"))
+
+		loader := newAccountLoader(dataStore, tenantOf("", nil))
+		actual, err := loader.Load(ctx, "account-1")()
+		require.Error(t, err)
+		assert.Nil(t, actual)
+		assert.Equal(t, codes.Internal, status.Code(err))
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With tenant not resolved", func(t *testing.T) {
+		ctx := context.TODO()
+
+		dataStore := new(mocks.Storage)
+		loader := newAccountLoader(dataStore, tenantOf("", status.Error(codes.InvalidArgument, "the tenant is not set")))
+		actual, err := loader.Load(ctx, "account-1")()
+		require.Error(t, err)
+		assert.Nil(t, actual)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		dataStore.AssertNotCalled(t, "GetAccounts", mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("With loader not set", func(t *testing.T) {
+		actual, err := accountLoaderFrom(context.TODO())
+		require.Error(t, err)
+		assert.Nil(t, actual)
+	})
+}
+
+// tenantOf returns the function resolving the given tenant, or failing with the given error when set
+func tenantOf(tenantID string, err error) func(context.Context) (string, error) {
+	return func(context.Context) (string, error) {
+		return tenantID, err
+	}
+}
@@ -0,0 +1,410 @@
+package graphapi
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/tochemey/cos-go-sample/app/auth"
+	"github.com/tochemey/cos-go-sample/app/ratelimit"
+	"github.com/tochemey/cos-go-sample/app/service"
+	"github.com/tochemey/cos-go-sample/app/storage"
+	"github.com/tochemey/cos-go-sample/app/tenant"
+	"github.com/tochemey/cos-go-sample/app/validation"
+	"github.com/tochemey/cos-go-sample/app/writeside/accounttypes"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// Resolver is the root resolver of the GraphQL schema.
+// The queries are answered from the read model and the mutations are executed by the accounts api service.
+// The accounts the caller is not allowed to read are resolved as not found.
+// The accounts are resolved with their entity ids, namespaced by their tenant, and exposed with the ids known by their tenant
+type Resolver struct {
+	dataStore   storage.Storage
+	apisService *service.Service
+	authorizer  *auth.Authorizer
+	tenants     *tenant.Resolver
+	limiter     *ratelimit.Limiter
+	validator   *validation.Validator
+}
+
+// NewResolver creates an instance of Resolver. Every account can be read when the authorizer is not set
+// and the accounts of every tenant are read when the tenants resolver is not set.
+// The mutations are rate limited as the api calls they stand for by the given limiter, when set,
+// and their inputs are validated as the requests of those calls by the given validator, when set
+func NewResolver(dataStore storage.Storage, apisService *service.Service, authorizer *auth.Authorizer, tenants *tenant.Resolver, limiter *ratelimit.Limiter, validator *validation.Validator) *Resolver {
+	return &Resolver{
+		dataStore:   dataStore,
+		apisService: apisService,
+		authorizer:  authorizer,
+		tenants:     tenants,
+		limiter:     limiter,
+		validator:   validator,
+	}
+}
+
+// Account fetches an account. A nil account is returned when it is not found
+func (r *Resolver) Account(ctx context.Context, args struct{ ID graphql.ID }) (*accountResolver, error) {
+	loader, err := accountLoaderFrom(ctx)
+	if err != nil {
+		return nil, toQueryError(err)
+	}
+
+	tenantID, err := r.tenantID(ctx)
+	if err != nil {
+		return nil, toQueryError(err)
+	}
+
+	record, err := loader.Load(ctx, tenant.EntityID(tenantID, string(args.ID)))()
+	if err != nil {
+		return nil, toQueryError(err)
+	}
+	return r.newReadableAccountResolver(ctx, record)
+}
+
+// Accounts fetches the accounts in the order of the given ids. The accounts not found are nil
+func (r *Resolver) Accounts(ctx context.Context, args struct{ IDs []graphql.ID }) ([]*accountResolver, error) {
+	// the number of accounts fetched at once is bounded
+	if len(args.IDs) > service.MaxBatchSize {
+		return nil, toQueryError(status.Errorf(codes.InvalidArgument, "at most %d accounts can be fetched at once", service.MaxBatchSize))
+	}
+
+	tenantID, err := r.tenantID(ctx)
+	if err != nil {
+		return nil, toQueryError(err)
+	}
+
+	accountIDs := make([]string, len(args.IDs))
+	for index, id := range args.IDs {
+		accountIDs[index] = tenant.EntityID(tenantID, string(id))
+	}
+	return r.loadAccounts(ctx, accountIDs)
+}
+
+// Owner fetches an account owner
+func (r *Resolver) Owner(args struct{ Name string }) *ownerResolver {
+	return &ownerResolver{root: r, name: args.Name}
+}
+
+// openAccountInput is the input of the OpenAccount mutation
+type openAccountInput struct {
+	AccountOwner string
+	Balance      float64
+	AccountID    *graphql.ID
+	AccountType  *string
+}
+
+// OpenAccount opens an account
+func (r *Resolver) OpenAccount(ctx context.Context, args struct{ Input openAccountInput }) (*accountResolver, error) {
+	request := &pb.OpenAccountRequest{
+		AccountOwner: args.Input.AccountOwner,
+		Balance:      args.Input.Balance,
+	}
+	if args.Input.AccountID != nil {
+		accountID := string(*args.Input.AccountID)
+		request.AccountId = &accountID
+	}
+	if args.Input.AccountType != nil {
+		request.AccountType = pb.AccountType(pb.AccountType_value[*args.Input.AccountType])
+	}
+
+	if err := r.validate(request); err != nil {
+		return nil, toQueryError(err)
+	}
+	if err := r.allow(ctx, pb.BankAccountService_OpenAccount_FullMethodName, request); err != nil {
+		return nil, toQueryError(err)
+	}
+
+	response, err := r.apisService.OpenAccount(ctx, request)
+	if err != nil {
+		return nil, toQueryError(err)
+	}
+	return r.newAccountResolver(toAccountRecord(response.GetAccount(), response.GetRevisionNumber(), response.GetRevisionDate())), nil
+}
+
+// postAccountInput is the input of the CreditAccount and DebitAccount mutations
+type postAccountInput struct {
+	AccountID        graphql.ID
+	Amount           float64
+	ExpectedRevision *int32
+}
+
+// CreditAccount credits an account
+func (r *Resolver) CreditAccount(ctx context.Context, args struct{ Input postAccountInput }) (*accountResolver, error) {
+	request := &pb.CreditAccountRequest{
+		AccountId:        string(args.Input.AccountID),
+		Amount:           args.Input.Amount,
+		ExpectedRevision: args.Input.ExpectedRevision,
+	}
+	if err := r.validate(request); err != nil {
+		return nil, toQueryError(err)
+	}
+	if err := r.allow(ctx, pb.BankAccountService_CreditAccount_FullMethodName, request); err != nil {
+		return nil, toQueryError(err)
+	}
+
+	response, err := r.apisService.CreditAccount(ctx, request)
+	if err != nil {
+		return nil, toQueryError(err)
+	}
+	return r.newAccountResolver(toAccountRecord(response.GetAccount(), response.GetRevisionNumber(), response.GetRevisionDate())), nil
+}
+
+// DebitAccount debits an account
+func (r *Resolver) DebitAccount(ctx context.Context, args struct{ Input postAccountInput }) (*accountResolver, error) {
+	request := &pb.DebitAccountRequest{
+		AccountId:        string(args.Input.AccountID),
+		Amount:           args.Input.Amount,
+		ExpectedRevision: args.Input.ExpectedRevision,
+	}
+	if err := r.validate(request); err != nil {
+		return nil, toQueryError(err)
+	}
+	if err := r.allow(ctx, pb.BankAccountService_DebitAccount_FullMethodName, request); err != nil {
+		return nil, toQueryError(err)
+	}
+
+	response, err := r.apisService.DebitAccount(ctx, request)
+	if err != nil {
+		return nil, toQueryError(err)
+	}
+	return r.newAccountResolver(toAccountRecord(response.GetAccount(), response.GetRevisionNumber(), response.GetRevisionDate())), nil
+}
+
+// validate validates the request of the api call a mutation stands for, since the mutations do not go through the api interceptors.
+// Every mutation is valid when the validation is not enabled
+func (r *Resolver) validate(request proto.Message) error {
+	if r.validator == nil {
+		return nil
+	}
+	return r.validator.Validate(request)
+}
+
+// allow rate limits a mutation as the call of a given api method with a given request, since the mutations do not go through
+// the api interceptors. Every mutation is allowed when the rate limiting is not enabled
+func (r *Resolver) allow(ctx context.Context, method string, request any) error {
+	if r.limiter == nil {
+		return nil
+	}
+	return r.limiter.Allow(ctx, method, request)
+}
+
+// tenantID resolves the tenant of the request served with the given context. There is no tenant when the multi-tenancy is not enabled
+func (r *Resolver) tenantID(ctx context.Context) (string, error) {
+	if r.tenants == nil {
+		return "", nil
+	}
+	return r.tenants.Resolve(ctx)
+}
+
+// loadAccounts fetches the accounts in the order of the given ids through the accounts loader of the request
+func (r *Resolver) loadAccounts(ctx context.Context, accountIDs []string) ([]*accountResolver, error) {
+	loader, err := accountLoaderFrom(ctx)
+	if err != nil {
+		return nil, toQueryError(err)
+	}
+
+	records, errs := loader.LoadMany(ctx, accountIDs)()
+	for _, err := range errs {
+		if err != nil {
+			return nil, toQueryError(err)
+		}
+	}
+
+	accounts := make([]*accountResolver, len(records))
+	for index, record := range records {
+		if accounts[index], err = r.newReadableAccountResolver(ctx, record); err != nil {
+			return nil, err
+		}
+	}
+	return accounts, nil
+}
+
+// newReadableAccountResolver creates an accountResolver when the caller is allowed to read the account of the given record.
+// A nil resolver is returned otherwise
+func (r *Resolver) newReadableAccountResolver(ctx context.Context, record *storage.AccountRecord) (*accountResolver, error) {
+	if record.GetAccount() == nil || r.authorizer == nil {
+		return r.newAccountResolver(record), nil
+	}
+
+	readable, err := r.authorizer.Allowed(ctx, auth.PermissionRead, auth.OwnedBy(record.GetAccount().GetAccountOwner()))
+	if err != nil {
+		return nil, toQueryError(err)
+	}
+	if !readable {
+		return nil, nil
+	}
+	return r.newAccountResolver(record), nil
+}
+
+// newAccountResolver creates an accountResolver. A nil resolver is returned when the record is not set
+func (r *Resolver) newAccountResolver(record *storage.AccountRecord) *accountResolver {
+	if record.GetAccount() == nil {
+		return nil
+	}
+	return &accountResolver{root: r, record: record}
+}
+
+// toAccountRecord creates the account record of an account returned by the accounts api service.
+// The account is recorded with its entity id, as in the read model
+func toAccountRecord(account *pb.BankAccount, revisionNumber int32, revisionDate *timestamppb.Timestamp) *storage.AccountRecord {
+	if account.GetTenantId() != "" {
+		account = proto.Clone(account).(*pb.BankAccount)
+		account.AccountId = tenant.EntityID(account.GetTenantId(), account.GetAccountId())
+	}
+
+	record := &storage.AccountRecord{
+		Account:  account,
+		Revision: storage.Revision{Number: revisionNumber},
+	}
+	if revisionDate != nil {
+		record.Revision.Date = revisionDate.AsTime()
+	}
+	return record
+}
+
+// accountResolver resolves the Account type
+type accountResolver struct {
+	root   *Resolver
+	record *storage.AccountRecord
+}
+
+// ID returns the id of the account as known by its tenant
+func (r *accountResolver) ID() graphql.ID {
+	account := r.record.GetAccount()
+	return graphql.ID(tenant.LocalID(account.GetTenantId(), account.GetAccountId()))
+}
+
+func (r *accountResolver) Balance() float64 {
+	return r.record.GetAccount().GetAccountBalance()
+}
+
+func (r *accountResolver) Owner() *ownerResolver {
+	return &ownerResolver{root: r.root, name: r.record.GetAccount().GetAccountOwner()}
+}
+
+// Type returns the effective type of the account. Accounts opened without a type are checking accounts
+func (r *accountResolver) Type() string {
+	return accounttypes.Resolve(r.record.GetAccount().GetAccountType()).String()
+}
+
+func (r *accountResolver) IsClosed() bool {
+	return r.record.GetAccount().GetIsClosed()
+}
+
+func (r *accountResolver) IsDormant() bool {
+	return r.record.GetAccount().GetIsDormant()
+}
+
+func (r *accountResolver) RevisionNumber() int32 {
+	return r.record.Revision.Number
+}
+
+// RevisionDate returns the revision date or nil for the accounts persisted before the revisions were recorded
+func (r *accountResolver) RevisionDate() *graphql.Time {
+	if r.record.Revision.Date.IsZero() {
+		return nil
+	}
+	return &graphql.Time{Time: r.record.Revision.Date}
+}
+
+// Transactions fetches the transactions of the account booked from the start time inclusive to the end time exclusive
+func (r *accountResolver) Transactions(ctx context.Context, args struct{ From, To graphql.Time }) ([]*transactionResolver, error) {
+	transactions, err := r.root.dataStore.GetTransactions(ctx, r.record.GetAccount().GetTenantId(), r.record.GetAccount().GetAccountId(), args.From.Time, args.To.Time)
+	if err != nil {
+		return nil, toQueryError(status.Error(codes.Internal, err.Error()))
+	}
+
+	resolvers := make([]*transactionResolver, len(transactions))
+	for index, transaction := range transactions {
+		resolvers[index] = &transactionResolver{root: r.root, transaction: transaction}
+	}
+	return resolvers, nil
+}
+
+// ownerResolver resolves the Owner type
+type ownerResolver struct {
+	root *Resolver
+	name string
+}
+
+func (r *ownerResolver) Name() string {
+	return r.name
+}
+
+// Accounts fetches the accounts held by the owner within the tenant of the request ordered by account id
+func (r *ownerResolver) Accounts(ctx context.Context) ([]*accountResolver, error) {
+	tenantID, err := r.root.tenantID(ctx)
+	if err != nil {
+		return nil, toQueryError(err)
+	}
+
+	accountIDs, err := r.root.dataStore.GetOwnerAccountIDs(ctx, tenantID, r.name)
+	if err != nil {
+		return nil, toQueryError(status.Error(codes.Internal, err.Error()))
+	}
+
+	accounts, err := r.root.loadAccounts(ctx, accountIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// skip the accounts missing from the read model
+	owned := make([]*accountResolver, 0, len(accounts))
+	for _, account := range accounts {
+		if account != nil {
+			owned = append(owned, account)
+		}
+	}
+	return owned, nil
+}
+
+// transactionResolver resolves the Transaction type
+type transactionResolver struct {
+	root        *Resolver
+	transaction *pb.Transaction
+}
+
+// Account fetches the account the transaction is booked on
+func (r *transactionResolver) Account(ctx context.Context) (*accountResolver, error) {
+	accounts, err := r.root.loadAccounts(ctx, []string{r.transaction.GetAccountId()})
+	if err != nil {
+		return nil, err
+	}
+	if accounts[0] == nil {
+		return nil, toQueryError(status.Errorf(codes.NotFound, "the account:(%s) is not found", r.transaction.GetAccountId()))
+	}
+	return accounts[0], nil
+}
+
+func (r *transactionResolver) RevisionNumber() int32 {
+	return r.transaction.GetRevisionNumber()
+}
+
+func (r *transactionResolver) Sequence() int32 {
+	return r.transaction.GetSequence()
+}
+
+func (r *transactionResolver) Type() string {
+	return r.transaction.GetType().String()
+}
+
+func (r *transactionResolver) Amount() float64 {
+	return r.transaction.GetAmount()
+}
+
+func (r *transactionResolver) BalanceAfter() float64 {
+	return r.transaction.GetBalanceAfter()
+}
+
+func (r *transactionResolver) Description() string {
+	return r.transaction.GetDescription()
+}
+
+func (r *transactionResolver) BookedAt() graphql.Time {
+	return graphql.Time{Time: r.transaction.GetBookedAt().AsTime()}
+}
@@ -0,0 +1,413 @@
+package graphapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/tochemey/cos-go-sample/app/auth"
+	"github.com/tochemey/cos-go-sample/app/ratelimit"
+	"github.com/tochemey/cos-go-sample/app/service"
+	"github.com/tochemey/cos-go-sample/app/storage"
+	"github.com/tochemey/cos-go-sample/app/tenant"
+	"github.com/tochemey/cos-go-sample/app/validation"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
+	cosmocks "github.com/tochemey/cos-go-sample/mocks/app/cos"
+	mocks "github.com/tochemey/cos-go-sample/mocks/app/storage"
+)
+
+// graphQLResponse is the response of a GraphQL request
+type graphQLResponse struct {
+	Data   map[string]any `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+// execute sends the given GraphQL query to a handler backed by the given data store and CoS client
+func execute(t *testing.T, dataStore *mocks.Storage, cosClient *cosmocks.Client, query string) *graphQLResponse {
+	return executeAs(t, nil, dataStore, cosClient, query)
+}
+
+// executeAs sends the given GraphQL query on behalf of the given principal. The requests are only authorized when the principal is set
+func executeAs(t *testing.T, principal *auth.Principal, dataStore *mocks.Storage, cosClient *cosmocks.Client, query string) *graphQLResponse {
+	var authorizer *auth.Authorizer
+	if principal != nil {
+		authorizer = auth.NewAuthorizer()
+	}
+
+	handler, err := NewHandler(dataStore, NewResolver(dataStore, service.NewService(cosClient, nil, nil, nil, authorizer, nil, nil, nil, nil), authorizer, nil, nil, nil), 8)
+	require.NoError(t, err)
+
+	body, err := json.Marshal(map[string]any{"query": query})
+	require.NoError(t, err)
+	request := httptest.NewRequest(http.MethodPost, Path, strings.NewReader(string(body)))
+	if principal != nil {
+		request = request.WithContext(auth.NewContext(request.Context(), principal))
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	response := new(graphQLResponse)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response))
+	return response
+}
+
+func TestResolver(t *testing.T) {
+	revisionDate := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	account1 := &storage.AccountRecord{
+		Account:  &pb.BankAccount{AccountId: "account-1", AccountBalance: 200, AccountOwner: "John Doe", AccountType: pb.AccountType_SAVINGS},
+		Revision: storage.Revision{Number: 3, Date: revisionDate},
+	}
+	account2 := &storage.AccountRecord{
+		Account: &pb.BankAccount{AccountId: "account-2", AccountBalance: 50, AccountOwner: "John Doe", IsDormant: true},
+	}
+
+	t.Run("With owner accounts fetched in a single batch", func(t *testing.T) {
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetOwnerAccountIDs", mock.Anything, "", "John Doe").Return([]string{"account-1", "account-2"}, nil)
+		dataStore.
+			On("GetAccounts", mock.Anything, "", []string{"account-1", "account-2"}).
+			Return([]*storage.AccountRecord{account1, account2}, nil).
+			Once()
+
+		response := execute(t, dataStore, nil, `{
+			owner(name: "John Doe") {
+				name
+				accounts { id balance type isDormant revisionNumber revisionDate }
+			}
+		}`)
+		require.Empty(t, response.Errors)
+
+		owner := response.Data["owner"].(map[string]any)
+		assert.Equal(t, "John Doe", owner["name"])
+		assert.Equal(t, []any{
+			map[string]any{"id": "account-1", "balance": 200.0, "type": "SAVINGS", "isDormant": false, "revisionNumber": 3.0, "revisionDate": "2024-01-02T10:00:00Z"},
+			map[string]any{"id": "account-2", "balance": 50.0, "type": "CHECKING", "isDormant": true, "revisionNumber": 0.0, "revisionDate": nil},
+		}, owner["accounts"])
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With accounts not found", func(t *testing.T) {
+		dataStore := new(mocks.Storage)
+		dataStore.
+			On("GetAccounts", mock.Anything, "", []string{"account-1", "account-3"}).
+			Return([]*storage.AccountRecord{account1, nil}, nil).
+			Once()
+
+		response := execute(t, dataStore, nil, `{ accounts(ids: ["account-1", "account-3"]) { id } }`)
+		require.Empty(t, response.Errors)
+		assert.Equal(t, []any{map[string]any{"id": "account-1"}, nil}, response.Data["accounts"])
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With transactions accounts loaded once", func(t *testing.T) {
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+		transactions := []*pb.Transaction{
+			{AccountId: "account-1", RevisionNumber: 1, Type: pb.TransactionType_OPENING, Amount: 100, BalanceAfter: 100, BookedAt: timestamppb.New(from)},
+			{AccountId: "account-1", RevisionNumber: 2, Type: pb.TransactionType_CREDIT, Amount: 100, BalanceAfter: 200, BookedAt: timestamppb.New(revisionDate)},
+		}
+
+		dataStore := new(mocks.Storage)
+		dataStore.
+			On("GetAccounts", mock.Anything, "", []string{"account-1"}).
+			Return([]*storage.AccountRecord{account1}, nil).
+			Once()
+		dataStore.On("GetTransactions", mock.Anything, "", "account-1", from, to).Return(transactions, nil)
+
+		response := execute(t, dataStore, nil, `{
+			account(id: "account-1") {
+				transactions(from: "2024-01-01T00:00:00Z", to: "2024-02-01T00:00:00Z") {
+					type amount balanceAfter bookedAt
+					account { id }
+				}
+			}
+		}`)
+		require.Empty(t, response.Errors)
+		assert.Equal(t, []any{
+			map[string]any{"type": "OPENING", "amount": 100.0, "balanceAfter": 100.0, "bookedAt": "2024-01-01T00:00:00Z", "account": map[string]any{"id": "account-1"}},
+			map[string]any{"type": "CREDIT", "amount": 100.0, "balanceAfter": 200.0, "bookedAt": "2024-01-02T10:00:00Z", "account": map[string]any{"id": "account-1"}},
+		}, response.Data["account"].(map[string]any)["transactions"])
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With account not found", func(t *testing.T) {
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "", []string{"account-3"}).Return([]*storage.AccountRecord{nil}, nil)
+
+		response := execute(t, dataStore, nil, `{ account(id: "account-3") { id } }`)
+		require.Empty(t, response.Errors)
+		assert.Nil(t, response.Data["account"])
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With accounts of other owners read by customer", func(t *testing.T) {
+		account3 := &storage.AccountRecord{Account: &pb.BankAccount{AccountId: "account-3", AccountOwner: "Jane Doe"}}
+		dataStore := new(mocks.Storage)
+		dataStore.
+			On("GetAccounts", mock.Anything, "", []string{"account-1", "account-3"}).
+			Return([]*storage.AccountRecord{account1, account3}, nil).
+			Once()
+		customer := &auth.Principal{Subject: "John Doe", Roles: []auth.Role{auth.RoleCustomer}}
+
+		// the accounts of other owners are not found
+		response := executeAs(t, customer, dataStore, nil, `{ accounts(ids: ["account-1", "account-3"]) { id } }`)
+		require.Empty(t, response.Errors)
+		assert.Equal(t, []any{map[string]any{"id": "account-1"}, nil}, response.Data["accounts"])
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With credit account mutation by customer", func(t *testing.T) {
+		customer := &auth.Principal{Subject: "John Doe", Roles: []auth.Role{auth.RoleCustomer}}
+
+		response := executeAs(t, customer, new(mocks.Storage), new(cosmocks.Client), `mutation {
+			creditAccount(input: {accountId: "account-1", amount: 50}) { id }
+		}`)
+		require.Len(t, response.Errors, 1)
+		assert.Equal(t, "PermissionDenied", response.Errors[0].Extensions["code"])
+	})
+	t.Run("With storage failure", func(t *testing.T) {
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetOwnerAccountIDs", mock.Anything, "", "John Doe").Return(nil, errors.New("connection refused"))
+
+		response := execute(t, dataStore, nil, `{ owner(name: "John Doe") { accounts { id } } }`)
+		require.Len(t, response.Errors, 1)
+		assert.Equal(t, "Internal", response.Errors[0].Extensions["code"])
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With too many accounts", func(t *testing.T) {
+		ids := make([]string, service.MaxBatchSize+1)
+		for index := range ids {
+			ids[index] = `"account"`
+		}
+
+		response := execute(t, new(mocks.Storage), nil, `{ accounts(ids: [`+strings.Join(ids, ",")+`]) { id } }`)
+		require.Len(t, response.Errors, 1)
+		assert.Equal(t, "InvalidArgument", response.Errors[0].Extensions["code"])
+	})
+	t.Run("With query too deep", func(t *testing.T) {
+		response := execute(t, new(mocks.Storage), nil, `{
+			account(id: "account-1") { owner { accounts { owner { accounts { owner { accounts { owner { accounts { id } } } } } } } } }
+		}`)
+		require.NotEmpty(t, response.Errors)
+		assert.Nil(t, response.Data)
+	})
+	t.Run("With credit account mutation", func(t *testing.T) {
+		account := &pb.BankAccount{AccountId: "account-1", AccountBalance: 250, AccountOwner: "John Doe"}
+		command := &pb.CreditAccount{AccountId: "account-1", Amount: 50, ExpectedRevision: proto.Int32(3)}
+
+		cosClient := new(cosmocks.Client)
+		cosClient.
+			On("ProcessCommand", mock.Anything, "account-1", mock.MatchedBy(func(actual *pb.CreditAccount) bool {
+				return proto.Equal(command, actual)
+			})).
+			Return(account, &cospb.MetaData{RevisionNumber: 4, RevisionDate: timestamppb.New(revisionDate)}, nil)
+
+		response := execute(t, new(mocks.Storage), cosClient, `mutation {
+			creditAccount(input: {accountId: "account-1", amount: 50, expectedRevision: 3}) { id balance revisionNumber revisionDate }
+		}`)
+		require.Empty(t, response.Errors)
+		assert.Equal(t, map[string]any{"id": "account-1", "balance": 250.0, "revisionNumber": 4.0, "revisionDate": "2024-01-02T10:00:00Z"},
+			response.Data["creditAccount"])
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With open account mutation", func(t *testing.T) {
+		account := &pb.BankAccount{AccountId: "account-1", AccountBalance: 500, AccountOwner: "John Doe", AccountType: pb.AccountType_ESCROW}
+		command := &pb.OpenAccount{AccountId: "account-1", AccountOwner: "John Doe", OpeningBalance: 500, AccountType: pb.AccountType_ESCROW}
+
+		cosClient := new(cosmocks.Client)
+		cosClient.
+			On("ProcessCommand", mock.Anything, "account-1", mock.MatchedBy(func(actual *pb.OpenAccount) bool {
+				return proto.Equal(command, actual)
+			})).
+			Return(account, &cospb.MetaData{RevisionNumber: 1}, nil)
+
+		response := execute(t, new(mocks.Storage), cosClient, `mutation {
+			openAccount(input: {accountId: "account-1", accountOwner: "John Doe", balance: 500, accountType: ESCROW}) { id type owner { name } }
+		}`)
+		require.Empty(t, response.Errors)
+		assert.Equal(t, map[string]any{"id": "account-1", "type": "ESCROW", "owner": map[string]any{"name": "John Doe"}},
+			response.Data["openAccount"])
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With debit account mutation rejected", func(t *testing.T) {
+		st, err := status.New(codes.FailedPrecondition, "insufficient balance").
+			WithDetails(&errdetails.ErrorInfo{Reason: "INSUFFICIENT_BALANCE", Domain: "accounts.v1"})
+		require.NoError(t, err)
+
+		cosClient := new(cosmocks.Client)
+		cosClient.
+			On("ProcessCommand", mock.Anything, "account-1", mock.AnythingOfType("*accountsv1.DebitAccount")).
+			Return(nil, nil, st.Err())
+
+		response := execute(t, new(mocks.Storage), cosClient, `mutation {
+			debitAccount(input: {accountId: "account-1", amount: 5000}) { id }
+		}`)
+		require.Len(t, response.Errors, 1)
+		assert.Equal(t, "insufficient balance", response.Errors[0].Message)
+		assert.Equal(t, map[string]any{"code": "FailedPrecondition", "reason": "INSUFFICIENT_BALANCE"}, response.Errors[0].Extensions)
+		cosClient.AssertExpectations(t)
+	})
+}
+
+func TestResolverTenancy(t *testing.T) {
+	account := &storage.AccountRecord{
+		Account: &pb.BankAccount{AccountId: "acme/account-1", AccountBalance: 200, AccountOwner: "John Doe", TenantId: "acme"},
+	}
+
+	// send sends the given GraphQL query on behalf of the given tenant
+	send := func(t *testing.T, dataStore *mocks.Storage, tenantID, query string) *graphQLResponse {
+		tenants := tenant.NewResolver("acme", "globex")
+		handler, err := NewHandler(dataStore, NewResolver(dataStore, service.NewService(nil, nil, nil, nil, nil, nil, tenants, nil, nil), nil, tenants, nil, nil), 8)
+		require.NoError(t, err)
+
+		body, err := json.Marshal(map[string]any{"query": query})
+		require.NoError(t, err)
+		request := httptest.NewRequest(http.MethodPost, Path, strings.NewReader(string(body)))
+		request.Header.Set("X-Tenant-Id", tenantID)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		response := new(graphQLResponse)
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response))
+		return response
+	}
+
+	t.Run("With owner accounts of the tenant", func(t *testing.T) {
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetOwnerAccountIDs", mock.Anything, "acme", "John Doe").Return([]string{"acme/account-1"}, nil)
+		dataStore.On("GetAccounts", mock.Anything, "acme", []string{"acme/account-1"}).Return([]*storage.AccountRecord{account}, nil)
+
+		response := send(t, dataStore, "acme", `{ owner(name: "John Doe") { accounts { id } } }`)
+		require.Empty(t, response.Errors)
+
+		// the accounts are exposed with the ids known by the tenant
+		owner := response.Data["owner"].(map[string]any)
+		assert.Equal(t, []any{map[string]any{"id": "account-1"}}, owner["accounts"])
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With transactions of the tenant", func(t *testing.T) {
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+		transactions := []*pb.Transaction{
+			{AccountId: "acme/account-1", RevisionNumber: 1, Type: pb.TransactionType_OPENING, Amount: 200, BalanceAfter: 200, BookedAt: timestamppb.New(from)},
+		}
+
+		// the transactions are read within the tenant of the account
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "acme", []string{"acme/account-1"}).Return([]*storage.AccountRecord{account}, nil)
+		dataStore.On("GetTransactions", mock.Anything, "acme", "acme/account-1", from, to).Return(transactions, nil)
+
+		response := send(t, dataStore, "acme", `{
+			account(id: "account-1") { transactions(from: "2024-01-01T00:00:00Z", to: "2024-02-01T00:00:00Z") { amount } }
+		}`)
+		require.Empty(t, response.Errors)
+		assert.Equal(t, []any{map[string]any{"amount": 200.0}}, response.Data["account"].(map[string]any)["transactions"])
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With account of another tenant", func(t *testing.T) {
+		// the account ids are namespaced by the tenant of the request
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "globex", []string{"globex/account-1"}).Return([]*storage.AccountRecord{nil}, nil)
+
+		response := send(t, dataStore, "globex", `{ account(id: "account-1") { id } }`)
+		require.Empty(t, response.Errors)
+		assert.Nil(t, response.Data["account"])
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With unknown tenant", func(t *testing.T) {
+		response := send(t, new(mocks.Storage), "initech", `{ account(id: "account-1") { id } }`)
+		require.Len(t, response.Errors, 1)
+		assert.Equal(t, "InvalidArgument", response.Errors[0].Extensions["code"])
+	})
+}
+
+func TestResolverRateLimit(t *testing.T) {
+	t.Run("With credit account mutation throttled", func(t *testing.T) {
+		cosClient := new(cosmocks.Client)
+		cosClient.
+			On("ProcessCommand", mock.Anything, "account-1", mock.AnythingOfType("*accountsv1.CreditAccount")).
+			Return(&pb.BankAccount{AccountId: "account-1", AccountBalance: 250}, &cospb.MetaData{RevisionNumber: 4}, nil)
+
+		dataStore := new(mocks.Storage)
+		limiter := ratelimit.NewLimiter(&ratelimit.Config{AccountRate: 0.001, AccountBurst: 1}, ratelimit.NewMemoryBackend())
+		handler, err := NewHandler(dataStore, NewResolver(dataStore, service.NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil), nil, nil, limiter, nil), 8)
+		require.NoError(t, err)
+
+		mutate := func() *graphQLResponse {
+			body, err := json.Marshal(map[string]any{"query": `mutation { creditAccount(input: {accountId: "account-1", amount: 50}) { id } }`})
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, Path, strings.NewReader(string(body))))
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			response := new(graphQLResponse)
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response))
+			return response
+		}
+
+		require.Empty(t, mutate().Errors)
+		response := mutate()
+		require.Len(t, response.Errors, 1)
+		assert.Equal(t, map[string]any{"code": "ResourceExhausted"}, response.Errors[0].Extensions)
+		cosClient.AssertNumberOfCalls(t, "ProcessCommand", 1)
+	})
+}
+
+func TestResolverValidation(t *testing.T) {
+	validator, err := validation.NewValidator()
+	require.NoError(t, err)
+
+	mutate := func(t *testing.T, cosClient *cosmocks.Client, query string) *graphQLResponse {
+		dataStore := new(mocks.Storage)
+		handler, err := NewHandler(dataStore, NewResolver(dataStore, service.NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil), nil, nil, nil, validator), 8)
+		require.NoError(t, err)
+
+		body, err := json.Marshal(map[string]any{"query": query})
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, Path, strings.NewReader(string(body))))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		response := new(graphQLResponse)
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), response))
+		return response
+	}
+
+	t.Run("With credit account mutation with invalid amount", func(t *testing.T) {
+		cosClient := new(cosmocks.Client)
+		response := mutate(t, cosClient, `mutation { creditAccount(input: {accountId: "account-1", amount: -50}) { id } }`)
+		require.Len(t, response.Errors, 1)
+		assert.Equal(t, map[string]any{"code": "InvalidArgument"}, response.Errors[0].Extensions)
+		assert.Contains(t, response.Errors[0].Message, "amount")
+		cosClient.AssertNotCalled(t, "ProcessCommand", mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("With debit account mutation with invalid account id", func(t *testing.T) {
+		cosClient := new(cosmocks.Client)
+		response := mutate(t, cosClient, `mutation { debitAccount(input: {accountId: "account 1", amount: 50}) { id } }`)
+		require.Len(t, response.Errors, 1)
+		assert.Equal(t, map[string]any{"code": "InvalidArgument"}, response.Errors[0].Extensions)
+		assert.Contains(t, response.Errors[0].Message, "account_id")
+		cosClient.AssertNotCalled(t, "ProcessCommand", mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("With open account mutation with invalid account id", func(t *testing.T) {
+		cosClient := new(cosmocks.Client)
+		response := mutate(t, cosClient, `mutation { openAccount(input: {accountId: "-account", accountOwner: "owner-1", balance: 100}) { id } }`)
+		require.Len(t, response.Errors, 1)
+		assert.Equal(t, map[string]any{"code": "InvalidArgument"}, response.Errors[0].Extensions)
+		cosClient.AssertNotCalled(t, "ProcessCommand", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
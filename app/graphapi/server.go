@@ -0,0 +1,107 @@
+package graphapi
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/pkg/errors"
+
+	"github.com/tochemey/cos-go-sample/app/auth"
+	"github.com/tochemey/cos-go-sample/app/caller"
+	"github.com/tochemey/cos-go-sample/app/log"
+	"github.com/tochemey/cos-go-sample/app/storage"
+	"github.com/tochemey/cos-go-sample/app/tenant"
+)
+
+// Path is the path serving the GraphQL requests
+const Path = "/graphql"
+
+// schemaDefinition is the GraphQL schema of the accounts
+//
+//go:embed schema.graphql
+var schemaDefinition string
+
+// Server serves the accounts GraphQL api
+type Server struct {
+	server *http.Server
+}
+
+// NewServer creates an instance of Server. When the authenticator is set, the requests must carry a valid bearer token.
+// The client IP of the requests, recorded in the audit trail of the mutations, is resolved by the given client IP resolver when set
+func NewServer(config *Config, dataStore storage.Storage, resolver *Resolver, authenticator *auth.Authenticator, clientIPResolver *caller.ClientIPResolver) (*Server, error) {
+	// create the http handler
+	handler, err := NewHandler(dataStore, resolver, config.MaxDepth)
+	// handle the error
+	if err != nil {
+		return nil, err
+	}
+
+	// authenticate the requests
+	if authenticator != nil {
+		handler = authenticator.Middleware(handler)
+	}
+
+	// resolve the client IP of the requests, as the interceptors of the api calls do
+	if clientIPResolver != nil {
+		handler = clientIPResolver.Middleware(handler)
+	}
+
+	return &Server{
+		server: &http.Server{
+			Addr:    fmt.Sprintf(":%d", config.Port),
+			Handler: handler,
+		},
+	}, nil
+}
+
+// NewHandler creates the http handler serving the GraphQL requests on Path.
+// Every request gets its own accounts loader so that the accounts are batched and cached per request only.
+func NewHandler(dataStore storage.Storage, resolver *Resolver, maxDepth int) (http.Handler, error) {
+	// parse the schema and bind the resolvers
+	schema, err := graphql.ParseSchema(schemaDefinition, resolver, graphql.MaxDepth(maxDepth))
+	// handle the error
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the GraphQL schema")
+	}
+
+	relayHandler := &relay.Handler{Schema: schema}
+	mux := http.NewServeMux()
+	// the tenant of the requests is read from their header
+	mux.Handle(Path, tenant.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := withAccountLoader(r.Context(), newAccountLoader(dataStore, resolver.tenantID))
+		relayHandler.ServeHTTP(w, r.WithContext(ctx))
+	})))
+
+	return mux, nil
+}
+
+// Start starts the server. The requests are served in the background
+func (s *Server) Start() error {
+	// listen on the server address
+	listener, err := net.Listen("tcp", s.server.Addr)
+	// handle the error
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen on (%s)", s.server.Addr)
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error(errors.Wrap(err, "the GraphQL server stopped unexpectedly"))
+		}
+	}()
+
+	return nil
+}
+
+// Stop gracefully stops the server
+func (s *Server) Stop(ctx context.Context) error {
+	if err := s.server.Shutdown(ctx); err != nil {
+		return errors.Wrap(err, "failed to stop the GraphQL server")
+	}
+	return nil
+}
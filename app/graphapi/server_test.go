@@ -0,0 +1,90 @@
+package graphapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tochemey/cos-go-sample/app/caller"
+	"github.com/tochemey/cos-go-sample/app/service"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
+	cosmocks "github.com/tochemey/cos-go-sample/mocks/app/cos"
+	mocks "github.com/tochemey/cos-go-sample/mocks/app/storage"
+)
+
+// freePort returns a free tcp port
+func freePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestServer(t *testing.T) {
+	t.Run("With start and stop", func(t *testing.T) {
+		ctx := context.TODO()
+		dataStore := new(mocks.Storage)
+		config := &Config{Port: freePort(t), MaxDepth: 8}
+
+		server, err := NewServer(config, dataStore, NewResolver(dataStore, service.NewService(nil, nil, nil, nil, nil, nil, nil, nil, nil), nil, nil, nil, nil), nil, nil)
+		require.NoError(t, err)
+		require.NoError(t, server.Start())
+
+		// the GraphQL requests are served on the GraphQL path
+		url := fmt.Sprintf("http://localhost:%d%s", config.Port, Path)
+		response, err := http.Post(url, "application/json", strings.NewReader(`{"query": "{ owner(name: \"John Doe\") { name } }"}`))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.NoError(t, response.Body.Close())
+
+		assert.NoError(t, server.Stop(ctx))
+	})
+	t.Run("With port already in use", func(t *testing.T) {
+		listener, err := net.Listen("tcp", ":0")
+		require.NoError(t, err)
+		defer listener.Close()
+
+		dataStore := new(mocks.Storage)
+		config := &Config{Port: listener.Addr().(*net.TCPAddr).Port, MaxDepth: 8}
+		server, err := NewServer(config, dataStore, NewResolver(dataStore, nil, nil, nil, nil, nil), nil, nil)
+		require.NoError(t, err)
+		assert.Error(t, server.Start())
+	})
+	t.Run("With client IP of the mutations resolved", func(t *testing.T) {
+		clientIPResolver, err := caller.NewClientIPResolver(&caller.Config{})
+		require.NoError(t, err)
+
+		cosClient := new(cosmocks.Client)
+		cosClient.
+			On("ProcessCommand", mock.MatchedBy(func(ctx context.Context) bool {
+				return caller.FromIncomingContext(ctx).GetClientIp() == "198.51.100.1"
+			}), "account-1", mock.AnythingOfType("*accountsv1.CreditAccount")).
+			Return(&pb.BankAccount{AccountId: "account-1"}, &cospb.MetaData{RevisionNumber: 2}, nil)
+
+		dataStore := new(mocks.Storage)
+		config := &Config{Port: freePort(t), MaxDepth: 8}
+		resolver := NewResolver(dataStore, service.NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil), nil, nil, nil, nil)
+		server, err := NewServer(config, dataStore, resolver, nil, clientIPResolver)
+		require.NoError(t, err)
+
+		// the request is forwarded by the local gateway
+		request := httptest.NewRequest(http.MethodPost, Path,
+			strings.NewReader(`{"query": "mutation { creditAccount(input: {accountId: \"account-1\", amount: 50}) { id } }"}`))
+		request.RemoteAddr = "127.0.0.1:52000"
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("X-Forwarded-For", "198.51.100.1")
+		recorder := httptest.NewRecorder()
+		server.server.Handler.ServeHTTP(recorder, request)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		cosClient.AssertExpectations(t)
+	})
+}
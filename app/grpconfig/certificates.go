@@ -0,0 +1,126 @@
+package grpconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/tochemey/cos-go-sample/app/log"
+)
+
+// reloadCheckInterval is the minimum interval between two checks of the certificate files
+const reloadCheckInterval = time.Second
+
+// certificateStore holds a certificate and a CA pool read from disk. They are reloaded when their files change
+// so that the rotated certificates are picked up by the next handshakes without restarting the process.
+// The certificate and the CA files are optional.
+type certificateStore struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	mu            sync.Mutex
+	certificate   *tls.Certificate
+	pool          *x509.CertPool
+	modTimes      map[string]time.Time
+	lastCheck     time.Time
+	checkInterval time.Duration
+}
+
+// newCertificateStore creates a certificateStore and loads its certificate and CA pool
+func newCertificateStore(certFile, keyFile, caFile string) (*certificateStore, error) {
+	store := &certificateStore{
+		certFile:      certFile,
+		keyFile:       keyFile,
+		caFile:        caFile,
+		checkInterval: reloadCheckInterval,
+	}
+
+	modTimes, err := store.modTimesOf()
+	if err != nil {
+		return nil, err
+	}
+	if err := store.load(modTimes); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// current returns the certificate and the CA pool, reloaded first when their files have changed.
+// A failed reload keeps the previous ones so that a file being rewritten does not break the handshakes
+func (s *certificateStore) current() (*tls.Certificate, *x509.CertPool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if time.Since(s.lastCheck) >= s.checkInterval {
+		s.lastCheck = time.Now()
+		if modTimes, err := s.modTimesOf(); err != nil {
+			log.Error(errors.Wrap(err, "failed to check the TLS certificates"))
+		} else if s.changed(modTimes) {
+			if err := s.load(modTimes); err != nil {
+				log.Error(errors.Wrap(err, "failed to reload the TLS certificates"))
+			} else {
+				log.Info("the TLS certificates have been reloaded")
+			}
+		}
+	}
+
+	return s.certificate, s.pool
+}
+
+// load reads the certificate and the CA pool from disk
+func (s *certificateStore) load(modTimes map[string]time.Time) error {
+	var certificate *tls.Certificate
+	if s.certFile != "" {
+		keyPair, err := tls.LoadX509KeyPair(s.certFile, s.keyFile)
+		if err != nil {
+			return errors.Wrapf(err, "failed to load the certificate (%s)", s.certFile)
+		}
+		certificate = &keyPair
+	}
+
+	var pool *x509.CertPool
+	if s.caFile != "" {
+		raw, err := os.ReadFile(s.caFile)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read the CA file (%s)", s.caFile)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(raw) {
+			return errors.Errorf("the CA file (%s) holds no certificate", s.caFile)
+		}
+	}
+
+	s.certificate, s.pool, s.modTimes = certificate, pool, modTimes
+	return nil
+}
+
+// modTimesOf returns the modification time of every file of the store
+func (s *certificateStore) modTimesOf() (map[string]time.Time, error) {
+	modTimes := make(map[string]time.Time, 3)
+	for _, file := range []string{s.certFile, s.keyFile, s.caFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read the file (%s)", file)
+		}
+		modTimes[file] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// changed checks whether one of the files has changed since it was loaded
+func (s *certificateStore) changed(modTimes map[string]time.Time) bool {
+	for file, modTime := range modTimes {
+		if !modTime.Equal(s.modTimes[file]) {
+			return true
+		}
+	}
+	return false
+}
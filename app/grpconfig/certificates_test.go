@@ -0,0 +1,93 @@
+package grpconfig
+
+import (
+	"crypto/x509"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertificateStore(t *testing.T) {
+	ca := newTestAuthority(t, "ca")
+
+	t.Run("With the certificate and the CA pool", func(t *testing.T) {
+		certFile, keyFile := ca.issue(t, "accounts", x509.ExtKeyUsageServerAuth)
+		store, err := newCertificateStore(certFile, keyFile, ca.caFile)
+		require.NoError(t, err)
+
+		certificate, pool := store.current()
+		assert.NotNil(t, certificate)
+		assert.NotNil(t, pool)
+	})
+	t.Run("With no file", func(t *testing.T) {
+		store, err := newCertificateStore("", "", "")
+		require.NoError(t, err)
+
+		certificate, pool := store.current()
+		assert.Nil(t, certificate)
+		assert.Nil(t, pool)
+	})
+	t.Run("With an invalid CA file", func(t *testing.T) {
+		caFile := t.TempDir() + "/ca.pem"
+		require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0o600))
+
+		store, err := newCertificateStore("", "", caFile)
+		assert.Error(t, err)
+		assert.Nil(t, store)
+	})
+	t.Run("With rotated certificate files", func(t *testing.T) {
+		certFile, keyFile := ca.issue(t, "accounts", x509.ExtKeyUsageServerAuth)
+		store, err := newCertificateStore(certFile, keyFile, ca.caFile)
+		require.NoError(t, err)
+		store.checkInterval = 0
+		before, _ := store.current()
+
+		// rotate the certificate
+		rotatedCertFile, rotatedKeyFile := ca.issue(t, "accounts", x509.ExtKeyUsageServerAuth)
+		replaceFile(t, rotatedCertFile, certFile)
+		replaceFile(t, rotatedKeyFile, keyFile)
+
+		after, _ := store.current()
+		assert.NotEqual(t, before.Certificate[0], after.Certificate[0])
+	})
+	t.Run("With a failed reload", func(t *testing.T) {
+		certFile, keyFile := ca.issue(t, "accounts", x509.ExtKeyUsageServerAuth)
+		store, err := newCertificateStore(certFile, keyFile, ca.caFile)
+		require.NoError(t, err)
+		store.checkInterval = 0
+		before, _ := store.current()
+
+		// only rotate the certificate so that it no longer matches the key
+		rotatedCertFile, _ := ca.issue(t, "accounts", x509.ExtKeyUsageServerAuth)
+		replaceFile(t, rotatedCertFile, certFile)
+
+		after, _ := store.current()
+		assert.Equal(t, before, after)
+	})
+	t.Run("With the check interval not elapsed", func(t *testing.T) {
+		certFile, keyFile := ca.issue(t, "accounts", x509.ExtKeyUsageServerAuth)
+		store, err := newCertificateStore(certFile, keyFile, ca.caFile)
+		require.NoError(t, err)
+		store.checkInterval = time.Hour
+		before, _ := store.current()
+
+		rotatedCertFile, rotatedKeyFile := ca.issue(t, "accounts", x509.ExtKeyUsageServerAuth)
+		replaceFile(t, rotatedCertFile, certFile)
+		replaceFile(t, rotatedKeyFile, keyFile)
+
+		after, _ := store.current()
+		assert.Equal(t, before, after)
+	})
+}
+
+// replaceFile replaces the content of a file with the content of another one and moves its modification time forward
+func replaceFile(t *testing.T, source, target string) {
+	content, err := os.ReadFile(source)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(target, content, 0o600))
+	modTime := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(target, modTime, modTime))
+}
@@ -8,13 +8,14 @@ import (
 
 // Config represents the gRPC service configuration
 type Config struct {
-	ServiceName      string `env:"SERVICE_NAME"`                                 // ServiceName is the name given that will show in the traces
-	GrpcPort         int    `env:"GRPC_PORT" envDefault:"50051"`                 // GrpcPort is the gRPC port used to received and handle gRPC requests
-	MetricsEnabled   bool   `env:"METRICS_ENABLED" envDefault:"false"`           // MetricsEnabled checks whether metrics should be enabled or not
-	MetricsPort      int    `env:"METRICS_PORT" envDefault:"9102"`               // MetricsPort is used to send gRPC server metrics to the prometheus server
-	TraceEnabled     bool   `env:"TRACE_ENABLED" envDefault:"false"`             // TraceEnabled checks whether tracing should be enabled or not
-	TraceURL         string `env:"TRACE_URL" envDefault:""`                      // TraceURL is the OTLP collector url.
-	EnableReflection bool   `env:"SERVER_REFLECTION_ENABLED" envDefault:"false"` // EnableReflection this is useful or local dev testing
+	ServiceName      string    `env:"SERVICE_NAME"`                                 // ServiceName is the name given that will show in the traces
+	GrpcPort         int       `env:"GRPC_PORT" envDefault:"50051"`                 // GrpcPort is the gRPC port used to received and handle gRPC requests
+	MetricsEnabled   bool      `env:"METRICS_ENABLED" envDefault:"false"`           // MetricsEnabled checks whether metrics should be enabled or not
+	MetricsPort      int       `env:"METRICS_PORT" envDefault:"9102"`               // MetricsPort is used to send gRPC server metrics to the prometheus server
+	TraceEnabled     bool      `env:"TRACE_ENABLED" envDefault:"false"`             // TraceEnabled checks whether tracing should be enabled or not
+	TraceURL         string    `env:"TRACE_URL" envDefault:""`                      // TraceURL is the OTLP collector url.
+	EnableReflection bool      `env:"SERVER_REFLECTION_ENABLED" envDefault:"false"` // EnableReflection this is useful or local dev testing
+	TLS              TLSConfig // TLS is used to secure the gRPC server connections
 }
 
 // GetGrpcConfig returns a grpc config from the config object
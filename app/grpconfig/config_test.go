@@ -23,6 +23,9 @@ func TestGetGrpcConfig(t *testing.T) {
 			EnableReflection: false,
 			MetricsEnabled:   false,
 			MetricsPort:      9102,
+			TLS: TLSConfig{
+				ClientAuth: ClientAuthRequire,
+			},
 		}
 
 		// fetch the actual config
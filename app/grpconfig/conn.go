@@ -0,0 +1,86 @@
+package grpconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"time"
+
+	"github.com/pkg/errors"
+	gopack "github.com/tochemey/gopack/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+)
+
+// Connectivity reports the connectivity state of a gRPC client connection, e.g. *grpc.ClientConn
+type Connectivity interface {
+	GetState() connectivity.State
+	Connect()
+}
+
+// NewConn creates a gRPC client connection to a given address. The connection uses the given TLS configuration
+// and is in plaintext when it is not set
+func NewConn(addr string, tlsConfig *tls.Config) (*grpc.ClientConn, error) {
+	if tlsConfig == nil {
+		return gopack.DefaultConn(addr)
+	}
+
+	// the same options as the plaintext connections
+	return gopack.NewConnectionBuilder().
+		WithDefaultUnaryInterceptors().
+		WithDefaultStreamInterceptors().
+		WithKeepAliveParams(keepalive.ClientParameters{
+			Time:                1200 * time.Second,
+			PermitWithoutStream: true,
+		}).
+		WithTLS(tlsConfig).
+		TLSConn(addr)
+}
+
+// NewServerBuilder creates the gRPC server builder of a given config. The server only accepts TLS connections when TLS is enabled
+func NewServerBuilder(config *Config) (*gopack.ServerBuilder, error) {
+	builder := gopack.NewServerBuilderFromConfig(config.GetGrpcConfig())
+
+	serverCredentials, err := NewServerCredentials(config.TLS)
+	if err != nil {
+		return nil, err
+	}
+	if serverCredentials != nil {
+		builder.WithOption(grpc.Creds(credentials.NewTLS(serverCredentials.TLSConfig())))
+	}
+	return builder, nil
+}
+
+// CheckConn checks the connectivity state of a given client connection. An error is returned when the connection is failing or closed.
+// The idle connections are asked to connect so that their next check reflects the availability of the server
+func CheckConn(conn Connectivity) error {
+	switch state := conn.GetState(); state {
+	case connectivity.Ready, connectivity.Connecting:
+		return nil
+	case connectivity.Idle:
+		conn.Connect()
+		return nil
+	default:
+		return errors.Errorf("the connection is %s", state)
+	}
+}
+
+// GracefulStop stops a given server from accepting new RPCs and waits for the in-flight RPCs to finish.
+// The server is forcibly stopped, cancelling the remaining RPCs, when the given context is done before they finish
+func GracefulStop(ctx context.Context, server *grpc.Server) error {
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		server.Stop()
+		<-done
+		return errors.Wrap(ctx.Err(), "the in-flight RPCs were not drained")
+	}
+}
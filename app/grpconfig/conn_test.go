@@ -0,0 +1,143 @@
+package grpconfig
+
+import (
+	"context"
+	"crypto/x509"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestNewConn(t *testing.T) {
+	t.Run("Without TLS", func(t *testing.T) {
+		conn, err := NewConn("localhost:50051", nil)
+		require.NoError(t, err)
+		assert.NotNil(t, conn)
+		assert.NoError(t, conn.Close())
+	})
+	t.Run("With mTLS", func(t *testing.T) {
+		ctx := context.TODO()
+		ca := newTestAuthority(t, "ca")
+		serverCertFile, serverKeyFile := ca.issue(t, "writeside", x509.ExtKeyUsageServerAuth)
+		clientCertFile, clientKeyFile := ca.issue(t, "cos", x509.ExtKeyUsageClientAuth)
+
+		serverCredentials, err := NewServerCredentials(TLSConfig{
+			Enabled:      true,
+			CertFile:     serverCertFile,
+			KeyFile:      serverKeyFile,
+			CAFile:       ca.caFile,
+			ClientAuth:   ClientAuthRequire,
+			AllowedPeers: []string{"cos"},
+		})
+		require.NoError(t, err)
+
+		// start a grpc server only accepting the allowed peers
+		listener, err := net.Listen("tcp", "localhost:0")
+		require.NoError(t, err)
+		server := grpc.NewServer(grpc.Creds(credentials.NewTLS(serverCredentials.TLSConfig())))
+		healthpb.RegisterHealthServer(server, health.NewServer())
+		go func() { _ = server.Serve(listener) }()
+		t.Cleanup(server.Stop)
+
+		tlsConfig, err := NewClientTLSConfig(ClientTLSConfig{
+			Enabled:    true,
+			CertFile:   clientCertFile,
+			KeyFile:    clientKeyFile,
+			CAFile:     ca.caFile,
+			ServerName: "writeside",
+		})
+		require.NoError(t, err)
+
+		conn, err := NewConn(listener.Addr().String(), tlsConfig)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		response, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, response.GetStatus())
+	})
+}
+
+func TestCheckConn(t *testing.T) {
+	t.Run("With available server", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "localhost:0")
+		require.NoError(t, err)
+		server := grpc.NewServer()
+		healthpb.RegisterHealthServer(server, health.NewServer())
+		go func() { _ = server.Serve(listener) }()
+		t.Cleanup(server.Stop)
+
+		conn, err := NewConn(listener.Addr().String(), nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		// the idle connection is asked to connect
+		assert.NoError(t, CheckConn(conn))
+		assert.Eventually(t, func() bool { return conn.GetState() == connectivity.Ready }, 5*time.Second, 10*time.Millisecond)
+		assert.NoError(t, CheckConn(conn))
+	})
+	t.Run("With closed connection", func(t *testing.T) {
+		conn, err := NewConn("localhost:50051", nil)
+		require.NoError(t, err)
+		require.NoError(t, conn.Close())
+
+		assert.EqualError(t, CheckConn(conn), "the connection is SHUTDOWN")
+	})
+}
+
+func TestGracefulStop(t *testing.T) {
+	t.Run("Without in-flight RPC", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "localhost:0")
+		require.NoError(t, err)
+		server := grpc.NewServer()
+		healthpb.RegisterHealthServer(server, health.NewServer())
+		go func() { _ = server.Serve(listener) }()
+
+		assert.NoError(t, GracefulStop(context.TODO(), server))
+	})
+	t.Run("With in-flight RPC not finishing", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "localhost:0")
+		require.NoError(t, err)
+		server := grpc.NewServer()
+		healthpb.RegisterHealthServer(server, health.NewServer())
+		go func() { _ = server.Serve(listener) }()
+
+		conn, err := NewConn(listener.Addr().String(), nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		// the watch stream is only ended by the server stop
+		stream, err := healthpb.NewHealthClient(conn).Watch(context.TODO(), &healthpb.HealthCheckRequest{})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+		defer cancel()
+		err = GracefulStop(ctx, server)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		// the stream is cancelled
+		_, err = stream.Recv()
+		assert.Error(t, err)
+	})
+}
+
+func TestNewServerBuilder(t *testing.T) {
+	t.Run("Without TLS", func(t *testing.T) {
+		builder, err := NewServerBuilder(&Config{ServiceName: "writeside", GrpcPort: 0})
+		require.NoError(t, err)
+		assert.NotNil(t, builder)
+	})
+	t.Run("With an invalid TLS config", func(t *testing.T) {
+		builder, err := NewServerBuilder(&Config{ServiceName: "writeside", TLS: TLSConfig{Enabled: true}})
+		assert.Error(t, err)
+		assert.Nil(t, builder)
+	})
+}
@@ -0,0 +1,218 @@
+package grpconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+// ClientAuthMode states whether the servers request and verify the certificates of their clients
+type ClientAuthMode string
+
+const (
+	// ClientAuthNone does not request the client certificates
+	ClientAuthNone ClientAuthMode = "none"
+	// ClientAuthRequest requests the client certificates and verifies them when they are sent
+	ClientAuthRequest ClientAuthMode = "request"
+	// ClientAuthRequire requires and verifies the client certificates (mTLS)
+	ClientAuthRequire ClientAuthMode = "require"
+)
+
+// TLSConfig defines the TLS configuration of a gRPC server
+type TLSConfig struct {
+	Enabled      bool           `env:"TLS_ENABLED" envDefault:"false"`                   // Enabled states whether the server only accepts TLS connections
+	CertFile     string         `env:"TLS_CERT_FILE" envDefault:""`                      // CertFile is the PEM file holding the server certificate chain
+	KeyFile      string         `env:"TLS_KEY_FILE" envDefault:""`                       // KeyFile is the PEM file holding the server private key
+	CAFile       string         `env:"TLS_CA_FILE" envDefault:""`                        // CAFile is the PEM file holding the CA certificates verifying the client certificates
+	ClientAuth   ClientAuthMode `env:"TLS_CLIENT_AUTH" envDefault:"require"`             // ClientAuth is the client certificates mode: none, request or require
+	AllowedPeers []string       `env:"TLS_ALLOWED_PEERS" envDefault:"" envSeparator:","` // AllowedPeers are the identities allowed to connect. Any verified client is allowed when not set
+}
+
+// ClientTLSConfig defines the TLS configuration of the connections to Chief of State
+type ClientTLSConfig struct {
+	Enabled    bool   `env:"COS_TLS_ENABLED" envDefault:"false"` // Enabled states whether the connections to CoS use TLS
+	CertFile   string `env:"COS_TLS_CERT_FILE" envDefault:""`    // CertFile is the PEM file holding the client certificate chain presented to CoS
+	KeyFile    string `env:"COS_TLS_KEY_FILE" envDefault:""`     // KeyFile is the PEM file holding the client private key
+	CAFile     string `env:"COS_TLS_CA_FILE" envDefault:""`      // CAFile is the PEM file holding the CA certificates verifying CoS. The system CAs are used when not set
+	ServerName string `env:"COS_TLS_SERVER_NAME" envDefault:""`  // ServerName overrides the name expected in the CoS certificate
+}
+
+// ServerCredentials builds the TLS configurations of a server from certificates reloaded from disk
+type ServerCredentials struct {
+	config TLSConfig
+	store  *certificateStore
+}
+
+// NewServerCredentials creates an instance of ServerCredentials. A nil instance is returned when TLS is not enabled
+func NewServerCredentials(config TLSConfig) (*ServerCredentials, error) {
+	if !config.Enabled {
+		return nil, nil
+	}
+
+	// validate the config
+	switch {
+	case config.CertFile == "" || config.KeyFile == "":
+		return nil, errors.New("the TLS certificate and key files are not set")
+	case !slices.Contains([]ClientAuthMode{ClientAuthNone, ClientAuthRequest, ClientAuthRequire}, config.ClientAuth):
+		return nil, errors.Errorf("the TLS client auth mode (%s) is invalid", config.ClientAuth)
+	case config.ClientAuth != ClientAuthNone && config.CAFile == "":
+		return nil, errors.New("the TLS CA file verifying the client certificates is not set")
+	case config.ClientAuth == ClientAuthNone && len(config.AllowedPeers) > 0:
+		return nil, errors.New("the TLS allowed peers require the client certificates")
+	}
+
+	store, err := newCertificateStore(config.CertFile, config.KeyFile, config.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	return &ServerCredentials{config: config, store: store}, nil
+}
+
+// TLSConfig returns the TLS configuration of the server. The client certificates are verified against the current CA pool
+// and their identity, the common name or one of the DNS and URI names, must be one of the allowed peers when those are set.
+// The server certificate itself is always accepted as a client certificate so that the server can call itself
+func (c *ServerCredentials) TLSConfig() *tls.Config {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			certificate, _ := c.store.current()
+			return certificate, nil
+		},
+	}
+
+	switch c.config.ClientAuth {
+	case ClientAuthNone:
+		return config
+	case ClientAuthRequest:
+		config.ClientAuth = tls.RequestClientCert
+	default:
+		config.ClientAuth = tls.RequireAnyClientCert
+	}
+
+	// the client certificates are verified here rather than by the TLS stack so that the CA pool can be reloaded
+	config.VerifyConnection = func(state tls.ConnectionState) error {
+		if len(state.PeerCertificates) == 0 {
+			if len(c.config.AllowedPeers) > 0 {
+				return errors.New("the client certificate is required")
+			}
+			return nil
+		}
+
+		certificate, pool := c.store.current()
+		if isCertificate(state.PeerCertificates[0], certificate) {
+			return nil
+		}
+		if err := verifyChain(state.PeerCertificates, pool, "", x509.ExtKeyUsageClientAuth); err != nil {
+			return err
+		}
+		return verifyPeer(state.PeerCertificates[0], c.config.AllowedPeers)
+	}
+	return config
+}
+
+// LoopbackTLSConfig returns the TLS configuration of the connections of the server to itself.
+// The server certificate is presented to the server and only the server certificate is trusted
+func (c *ServerCredentials) LoopbackTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			certificate, _ := c.store.current()
+			return certificate, nil
+		},
+		// the server certificate is verified by VerifyConnection since the loopback host is not one of its names
+		InsecureSkipVerify: true, //nolint:gosec
+		VerifyConnection: func(state tls.ConnectionState) error {
+			certificate, _ := c.store.current()
+			if len(state.PeerCertificates) == 0 || !isCertificate(state.PeerCertificates[0], certificate) {
+				return errors.New("the loopback peer is not the server")
+			}
+			return nil
+		},
+	}
+}
+
+// NewClientTLSConfig creates the TLS configuration of the connections to CoS. The client certificate and the CA pool are
+// reloaded from disk when they change. A nil configuration is returned when TLS is not enabled
+func NewClientTLSConfig(config ClientTLSConfig) (*tls.Config, error) {
+	if !config.Enabled {
+		return nil, nil
+	}
+
+	// the client certificate is optional but comes with its key
+	if (config.CertFile == "") != (config.KeyFile == "") {
+		return nil, errors.New("the CoS TLS certificate and key files must be set together")
+	}
+
+	store, err := newCertificateStore(config.CertFile, config.KeyFile, config.CAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: config.ServerName,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			certificate, _ := store.current()
+			if certificate == nil {
+				// no certificate is sent
+				return new(tls.Certificate), nil
+			}
+			return certificate, nil
+		},
+		// the server certificate is verified by VerifyConnection so that the CA pool can be reloaded
+		InsecureSkipVerify: true, //nolint:gosec
+		VerifyConnection: func(state tls.ConnectionState) error {
+			_, pool := store.current()
+			return verifyChain(state.PeerCertificates, pool, state.ServerName, x509.ExtKeyUsageServerAuth)
+		},
+	}, nil
+}
+
+// verifyChain verifies a peer certificate chain against a given CA pool, or the system CAs when the pool is not set.
+// The leaf certificate must be valid for the given name when the name is set
+func verifyChain(chain []*x509.Certificate, pool *x509.CertPool, name string, usage x509.ExtKeyUsage) error {
+	if len(chain) == 0 {
+		return errors.New("the peer certificate is not set")
+	}
+
+	options := x509.VerifyOptions{
+		Roots:         pool,
+		Intermediates: x509.NewCertPool(),
+		DNSName:       name,
+		KeyUsages:     []x509.ExtKeyUsage{usage},
+	}
+	for _, intermediate := range chain[1:] {
+		options.Intermediates.AddCert(intermediate)
+	}
+
+	if _, err := chain[0].Verify(options); err != nil {
+		return errors.Wrap(err, "the peer certificate is invalid")
+	}
+	return nil
+}
+
+// verifyPeer checks that the identity of a peer certificate is one of the allowed peers. Every peer is allowed when none is set
+func verifyPeer(certificate *x509.Certificate, allowedPeers []string) error {
+	if len(allowedPeers) == 0 {
+		return nil
+	}
+
+	identities := append([]string{certificate.Subject.CommonName}, certificate.DNSNames...)
+	for _, uri := range certificate.URIs {
+		identities = append(identities, uri.String())
+	}
+
+	for _, identity := range identities {
+		if identity != "" && slices.Contains(allowedPeers, identity) {
+			return nil
+		}
+	}
+	return errors.Errorf("the peer (%s) is not allowed", certificate.Subject.CommonName)
+}
+
+// isCertificate checks whether a peer certificate is the leaf of a given certificate
+func isCertificate(peer *x509.Certificate, certificate *tls.Certificate) bool {
+	return certificate != nil && len(certificate.Certificate) > 0 && slices.Equal(peer.Raw, certificate.Certificate[0])
+}
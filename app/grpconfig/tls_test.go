@@ -0,0 +1,299 @@
+package grpconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAuthority is a certificate authority issuing the test certificates
+type testAuthority struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	caFile      string
+}
+
+// newTestAuthority creates a testAuthority and writes its certificate in the test directory
+func newTestAuthority(t *testing.T, name string) *testAuthority {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(raw)
+	require.NoError(t, err)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writePEM(t, caFile, "CERTIFICATE", raw)
+	return &testAuthority{certificate: certificate, key: key, caFile: caFile}
+}
+
+// issue issues a certificate of a given name valid for the given usage and writes it with its key in the test directory
+func (a *testAuthority) issue(t *testing.T, name string, usage x509.ExtKeyUsage) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, a.certificate, &key.PublicKey, a.key)
+	require.NoError(t, err)
+	rawKey, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writePEM(t, certFile, "CERTIFICATE", raw)
+	writePEM(t, keyFile, "EC PRIVATE KEY", rawKey)
+	return certFile, keyFile
+}
+
+// writePEM writes a PEM block to a given file
+func writePEM(t *testing.T, file, blockType string, raw []byte) {
+	require.NoError(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: raw}), 0o600))
+}
+
+// handshake runs a TLS handshake between the given server and client configurations over a loopback connection
+func handshake(t *testing.T, serverConfig, clientConfig *tls.Config) (serverErr, clientErr error) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			done <- err
+			return
+		}
+		defer conn.Close()
+		done <- tls.Server(conn, serverConfig).Handshake()
+	}()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	clientErr = tls.Client(conn, clientConfig).Handshake()
+	// unblock the server in case of failure
+	_ = conn.Close()
+	return <-done, clientErr
+}
+
+func TestNewServerCredentials(t *testing.T) {
+	ca := newTestAuthority(t, "ca")
+	certFile, keyFile := ca.issue(t, "accounts", x509.ExtKeyUsageServerAuth)
+
+	t.Run("With TLS disabled", func(t *testing.T) {
+		credentials, err := NewServerCredentials(TLSConfig{Enabled: false})
+		require.NoError(t, err)
+		assert.Nil(t, credentials)
+	})
+	t.Run("With a valid config", func(t *testing.T) {
+		credentials, err := NewServerCredentials(TLSConfig{
+			Enabled:      true,
+			CertFile:     certFile,
+			KeyFile:      keyFile,
+			CAFile:       ca.caFile,
+			ClientAuth:   ClientAuthRequire,
+			AllowedPeers: []string{"cos"},
+		})
+		require.NoError(t, err)
+		assert.NotNil(t, credentials)
+	})
+	t.Run("With invalid configs", func(t *testing.T) {
+		configs := map[string]TLSConfig{
+			"missing key":              {Enabled: true, CertFile: certFile, ClientAuth: ClientAuthNone},
+			"invalid client auth mode": {Enabled: true, CertFile: certFile, KeyFile: keyFile, ClientAuth: "optional"},
+			"missing CA file":          {Enabled: true, CertFile: certFile, KeyFile: keyFile, ClientAuth: ClientAuthRequire},
+			"peers without client auth": {
+				Enabled: true, CertFile: certFile, KeyFile: keyFile, ClientAuth: ClientAuthNone, AllowedPeers: []string{"cos"},
+			},
+			"unknown certificate file": {Enabled: true, CertFile: "unknown.pem", KeyFile: keyFile, ClientAuth: ClientAuthNone},
+		}
+		for name, config := range configs {
+			credentials, err := NewServerCredentials(config)
+			assert.Error(t, err, name)
+			assert.Nil(t, credentials, name)
+		}
+	})
+}
+
+func TestServerCredentialsTLSConfig(t *testing.T) {
+	ca := newTestAuthority(t, "ca")
+	serverCertFile, serverKeyFile := ca.issue(t, "accounts", x509.ExtKeyUsageServerAuth)
+	cosCertFile, cosKeyFile := ca.issue(t, "cos", x509.ExtKeyUsageClientAuth)
+	otherCertFile, otherKeyFile := ca.issue(t, "other", x509.ExtKeyUsageClientAuth)
+	// a client certificate issued by another authority
+	rogueCertFile, rogueKeyFile := newTestAuthority(t, "rogue").issue(t, "cos", x509.ExtKeyUsageClientAuth)
+
+	// newCredentials creates the server credentials of a given client auth mode and allowed peers
+	newCredentials := func(t *testing.T, clientAuth ClientAuthMode, allowedPeers ...string) *ServerCredentials {
+		credentials, err := NewServerCredentials(TLSConfig{
+			Enabled:      true,
+			CertFile:     serverCertFile,
+			KeyFile:      serverKeyFile,
+			CAFile:       ca.caFile,
+			ClientAuth:   clientAuth,
+			AllowedPeers: allowedPeers,
+		})
+		require.NoError(t, err)
+		return credentials
+	}
+	// newClientConfig creates the client configuration presenting a given certificate when it is set
+	newClientConfig := func(t *testing.T, certFile, keyFile string) *tls.Config {
+		config, err := NewClientTLSConfig(ClientTLSConfig{
+			Enabled:    true,
+			CertFile:   certFile,
+			KeyFile:    keyFile,
+			CAFile:     ca.caFile,
+			ServerName: "accounts",
+		})
+		require.NoError(t, err)
+		return config
+	}
+
+	t.Run("With an allowed peer", func(t *testing.T) {
+		serverErr, clientErr := handshake(t, newCredentials(t, ClientAuthRequire, "cos").TLSConfig(), newClientConfig(t, cosCertFile, cosKeyFile))
+		assert.NoError(t, serverErr)
+		assert.NoError(t, clientErr)
+	})
+	t.Run("With a peer not allowed", func(t *testing.T) {
+		serverErr, _ := handshake(t, newCredentials(t, ClientAuthRequire, "cos").TLSConfig(), newClientConfig(t, otherCertFile, otherKeyFile))
+		assert.ErrorContains(t, serverErr, "the peer (other) is not allowed")
+	})
+	t.Run("With any verified peer allowed", func(t *testing.T) {
+		serverErr, clientErr := handshake(t, newCredentials(t, ClientAuthRequire).TLSConfig(), newClientConfig(t, otherCertFile, otherKeyFile))
+		assert.NoError(t, serverErr)
+		assert.NoError(t, clientErr)
+	})
+	t.Run("With a peer certificate issued by another authority", func(t *testing.T) {
+		serverErr, _ := handshake(t, newCredentials(t, ClientAuthRequire, "cos").TLSConfig(), newClientConfig(t, rogueCertFile, rogueKeyFile))
+		assert.ErrorContains(t, serverErr, "the peer certificate is invalid")
+	})
+	t.Run("With a missing client certificate", func(t *testing.T) {
+		serverErr, _ := handshake(t, newCredentials(t, ClientAuthRequire).TLSConfig(), newClientConfig(t, "", ""))
+		assert.Error(t, serverErr)
+	})
+	t.Run("With a requested client certificate", func(t *testing.T) {
+		credentials := newCredentials(t, ClientAuthRequest)
+		serverErr, clientErr := handshake(t, credentials.TLSConfig(), newClientConfig(t, "", ""))
+		assert.NoError(t, serverErr)
+		assert.NoError(t, clientErr)
+
+		serverErr, _ = handshake(t, credentials.TLSConfig(), newClientConfig(t, rogueCertFile, rogueKeyFile))
+		assert.ErrorContains(t, serverErr, "the peer certificate is invalid")
+	})
+	t.Run("With no client authentication", func(t *testing.T) {
+		serverErr, clientErr := handshake(t, newCredentials(t, ClientAuthNone).TLSConfig(), newClientConfig(t, rogueCertFile, rogueKeyFile))
+		assert.NoError(t, serverErr)
+		assert.NoError(t, clientErr)
+	})
+	t.Run("With the loopback connection", func(t *testing.T) {
+		credentials := newCredentials(t, ClientAuthRequire, "cos")
+		serverErr, clientErr := handshake(t, credentials.TLSConfig(), credentials.LoopbackTLSConfig())
+		assert.NoError(t, serverErr)
+		assert.NoError(t, clientErr)
+	})
+	t.Run("With the loopback connection to another server", func(t *testing.T) {
+		credentials := newCredentials(t, ClientAuthNone)
+		otherCredentials, err := NewServerCredentials(TLSConfig{Enabled: true, CertFile: otherCertFile, KeyFile: otherKeyFile, ClientAuth: ClientAuthNone})
+		require.NoError(t, err)
+
+		_, clientErr := handshake(t, otherCredentials.TLSConfig(), credentials.LoopbackTLSConfig())
+		assert.ErrorContains(t, clientErr, "the loopback peer is not the server")
+	})
+}
+
+func TestNewClientTLSConfig(t *testing.T) {
+	ca := newTestAuthority(t, "ca")
+	serverCertFile, serverKeyFile := ca.issue(t, "cos", x509.ExtKeyUsageServerAuth)
+	clientCertFile, clientKeyFile := ca.issue(t, "accounts", x509.ExtKeyUsageClientAuth)
+	server, err := NewServerCredentials(TLSConfig{
+		Enabled:    true,
+		CertFile:   serverCertFile,
+		KeyFile:    serverKeyFile,
+		CAFile:     ca.caFile,
+		ClientAuth: ClientAuthRequire,
+	})
+	require.NoError(t, err)
+
+	t.Run("With TLS disabled", func(t *testing.T) {
+		config, err := NewClientTLSConfig(ClientTLSConfig{Enabled: false})
+		require.NoError(t, err)
+		assert.Nil(t, config)
+	})
+	t.Run("With a certificate without its key", func(t *testing.T) {
+		config, err := NewClientTLSConfig(ClientTLSConfig{Enabled: true, CertFile: clientCertFile})
+		assert.Error(t, err)
+		assert.Nil(t, config)
+	})
+	t.Run("With the expected server name", func(t *testing.T) {
+		config, err := NewClientTLSConfig(ClientTLSConfig{
+			Enabled:    true,
+			CertFile:   clientCertFile,
+			KeyFile:    clientKeyFile,
+			CAFile:     ca.caFile,
+			ServerName: "cos",
+		})
+		require.NoError(t, err)
+
+		serverErr, clientErr := handshake(t, server.TLSConfig(), config)
+		assert.NoError(t, serverErr)
+		assert.NoError(t, clientErr)
+	})
+	t.Run("With an unexpected server name", func(t *testing.T) {
+		config, err := NewClientTLSConfig(ClientTLSConfig{
+			Enabled:    true,
+			CertFile:   clientCertFile,
+			KeyFile:    clientKeyFile,
+			CAFile:     ca.caFile,
+			ServerName: "writeside",
+		})
+		require.NoError(t, err)
+
+		_, clientErr := handshake(t, server.TLSConfig(), config)
+		assert.ErrorContains(t, clientErr, "the peer certificate is invalid")
+	})
+	t.Run("With a server issued by another authority", func(t *testing.T) {
+		config, err := NewClientTLSConfig(ClientTLSConfig{
+			Enabled:    true,
+			CertFile:   clientCertFile,
+			KeyFile:    clientKeyFile,
+			CAFile:     newTestAuthority(t, "other").caFile,
+			ServerName: "cos",
+		})
+		require.NoError(t, err)
+
+		_, clientErr := handshake(t, server.TLSConfig(), config)
+		assert.ErrorContains(t, clientErr, "the peer certificate is invalid")
+	})
+}
@@ -0,0 +1,242 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/tochemey/cos-go-sample/app/log"
+)
+
+const (
+	// Liveness is the service name of the liveness status. The process is live as long as it runs, whatever the state of its dependencies
+	Liveness = "liveness"
+	// Readiness is the service name of the readiness status. The process is ready when all its dependencies are available.
+	// It is also the status of the server as a whole, i.e. of the empty service name
+	Readiness = "readiness"
+)
+
+// Check probes a dependency. An error is returned when the dependency is not available.
+// The checks must return once the given context is done
+type Check func(ctx context.Context) error
+
+// Checker probes the dependencies of a process and reports the health of its services through the gRPC health checking protocol.
+// A service is serving when all its dependencies are available
+type Checker struct {
+	config   *Config
+	server   *health.Server
+	checks   map[string]Check
+	services map[string][]string
+
+	// failures holds the outcome of the last probe of every dependency, nil when available
+	failures map[string]error
+	mu       sync.RWMutex
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// DependencyReport is the health of a dependency
+type DependencyReport struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is the health of a process: its readiness, the status of its services and of its dependencies
+type Report struct {
+	Status       string                      `json:"status"`
+	Services     map[string]string           `json:"services"`
+	Dependencies map[string]DependencyReport `json:"dependencies"`
+}
+
+// NewChecker creates an instance of Checker. The process is live but not ready until its dependencies are probed
+func NewChecker(config *Config) *Checker {
+	server := health.NewServer()
+	server.SetServingStatus(Liveness, healthpb.HealthCheckResponse_SERVING)
+	server.SetServingStatus(Readiness, healthpb.HealthCheckResponse_NOT_SERVING)
+	server.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	return &Checker{
+		config:   config,
+		server:   server,
+		checks:   make(map[string]Check),
+		services: make(map[string][]string),
+		failures: make(map[string]error),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// AddDependency adds a dependency probed by a given check. The dependencies are added before the checker is started
+func (c *Checker) AddDependency(name string, check Check) {
+	c.checks[name] = check
+}
+
+// AddService adds a service serving when the given dependencies are available. The services are added before the checker is started
+func (c *Checker) AddService(service string, dependencies ...string) {
+	c.services[service] = dependencies
+	c.server.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// Start probes the dependencies and then keeps probing them at the configured interval in the background until the checker is stopped
+func (c *Checker) Start(ctx context.Context) {
+	c.probe(ctx)
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stopCh:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.probe(ctx)
+			}
+		}
+	}()
+}
+
+// Stop stops probing the dependencies and reports every service, the readiness included, as not serving.
+// The liveness stays serving since the process is still running while it drains its requests
+func (c *Checker) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopCh)
+	})
+	c.wg.Wait()
+
+	for service := range c.services {
+		c.server.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	c.server.SetServingStatus(Readiness, healthpb.HealthCheckResponse_NOT_SERVING)
+	c.server.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// Live checks whether the process is live. The process is live as long as it runs, the checker being stopped included
+func (c *Checker) Live() bool {
+	return true
+}
+
+// stopped checks whether the checker is stopped
+func (c *Checker) stopped() bool {
+	select {
+	case <-c.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// Report returns the health of the process as of the last probe of its dependencies
+func (c *Checker) Report() *Report {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	report := &Report{
+		Status:       status(!c.stopped() && c.available(c.dependencies()...)).String(),
+		Services:     make(map[string]string, len(c.services)),
+		Dependencies: make(map[string]DependencyReport, len(c.checks)),
+	}
+	for service, dependencies := range c.services {
+		report.Services[service] = status(!c.stopped() && c.available(dependencies...)).String()
+	}
+	for name := range c.checks {
+		failure, probed := c.failures[name]
+		dependency := DependencyReport{Status: status(probed && failure == nil).String()}
+		if failure != nil {
+			dependency.Error = failure.Error()
+		}
+		report.Dependencies[name] = dependency
+	}
+	return report
+}
+
+// RegisterService registers the gRPC health service
+func (c *Checker) RegisterService(sv *grpc.Server) {
+	healthpb.RegisterHealthServer(sv, c.server)
+}
+
+// probe probes the dependencies concurrently and updates the status of the services
+func (c *Checker) probe(ctx context.Context) {
+	failures := make(map[string]error, len(c.checks))
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for name, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+			defer cancel()
+			err := check(checkCtx)
+			mu.Lock()
+			failures[name] = err
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// log the dependencies changing state only
+	for _, name := range c.dependencies() {
+		failure := failures[name]
+		previous, probed := c.failures[name]
+		switch {
+		case failure != nil && (!probed || previous == nil):
+			log.Warnf("the dependency (%s) is not available: %v", name, failure)
+		case failure == nil && probed && previous != nil:
+			log.Infof("the dependency (%s) is available again", name)
+		}
+	}
+	c.failures = failures
+
+	// the statuses are left as is once the checker is stopped
+	if c.stopped() {
+		return
+	}
+	for service, dependencies := range c.services {
+		c.server.SetServingStatus(service, status(c.available(dependencies...)))
+	}
+	ready := status(c.available(c.dependencies()...))
+	c.server.SetServingStatus(Readiness, ready)
+	c.server.SetServingStatus("", ready)
+}
+
+// available checks whether the given dependencies were available at their last probe. It must be called under the lock
+func (c *Checker) available(dependencies ...string) bool {
+	for _, name := range dependencies {
+		if failure, probed := c.failures[name]; !probed || failure != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// dependencies returns the names of the dependencies ordered by name
+func (c *Checker) dependencies() []string {
+	names := make([]string, 0, len(c.checks))
+	for name := range c.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// status returns the serving status of a given availability
+func status(available bool) healthpb.HealthCheckResponse_ServingStatus {
+	if available {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
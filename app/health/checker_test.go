@@ -0,0 +1,148 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// testConfig returns a configuration probing the dependencies often
+func testConfig() *Config {
+	return &Config{Interval: 10 * time.Millisecond, Timeout: 50 * time.Millisecond}
+}
+
+// servingStatus returns the serving status of a given service
+func servingStatus(t *testing.T, checker *Checker, service string) healthpb.HealthCheckResponse_ServingStatus {
+	response, err := checker.server.Check(context.TODO(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+	return response.GetStatus()
+}
+
+func TestChecker(t *testing.T) {
+	t.Run("With dependencies not probed yet", func(t *testing.T) {
+		checker := NewChecker(testConfig())
+		checker.AddDependency("postgres", func(context.Context) error { return nil })
+		checker.AddService("bank", "postgres")
+
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, checker, Liveness))
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, checker, Readiness))
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, checker, ""))
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, checker, "bank"))
+	})
+	t.Run("With all dependencies available", func(t *testing.T) {
+		checker := NewChecker(testConfig())
+		checker.AddDependency("postgres", func(context.Context) error { return nil })
+		checker.AddDependency("cos", func(context.Context) error { return nil })
+		checker.AddService("bank", "postgres", "cos")
+		checker.Start(context.TODO())
+		defer checker.Stop()
+
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, checker, Liveness))
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, checker, Readiness))
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, checker, ""))
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, checker, "bank"))
+
+		report := checker.Report()
+		assert.Equal(t, "SERVING", report.Status)
+		assert.Equal(t, map[string]string{"bank": "SERVING"}, report.Services)
+		assert.Equal(t, DependencyReport{Status: "SERVING"}, report.Dependencies["postgres"])
+	})
+	t.Run("With a dependency not available", func(t *testing.T) {
+		checker := NewChecker(testConfig())
+		checker.AddDependency("postgres", func(context.Context) error { return nil })
+		checker.AddDependency("cos", func(context.Context) error { return errors.New("connection refused") })
+		checker.AddService("bank", "postgres", "cos")
+		checker.AddService("read-side", "postgres")
+		checker.Start(context.TODO())
+		defer checker.Stop()
+
+		// the process is still live
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, checker, Liveness))
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, checker, Readiness))
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, checker, "bank"))
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, checker, "read-side"))
+
+		report := checker.Report()
+		assert.Equal(t, "NOT_SERVING", report.Status)
+		assert.Equal(t, DependencyReport{Status: "NOT_SERVING", Error: "connection refused"}, report.Dependencies["cos"])
+	})
+	t.Run("With a dependency timing out", func(t *testing.T) {
+		checker := NewChecker(testConfig())
+		checker.AddDependency("postgres", func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		checker.AddService("bank", "postgres")
+		checker.Start(context.TODO())
+		defer checker.Stop()
+
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, checker, "bank"))
+		assert.Equal(t, context.DeadlineExceeded.Error(), checker.Report().Dependencies["postgres"].Error)
+	})
+	t.Run("With a dependency recovering", func(t *testing.T) {
+		var available atomic.Bool
+		checker := NewChecker(testConfig())
+		checker.AddDependency("postgres", func(context.Context) error {
+			if available.Load() {
+				return nil
+			}
+			return errors.New("connection refused")
+		})
+		checker.AddService("bank", "postgres")
+		checker.Start(context.TODO())
+		defer checker.Stop()
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, checker, "bank"))
+
+		available.Store(true)
+		assert.Eventually(t, func() bool {
+			return servingStatus(t, checker, "bank") == healthpb.HealthCheckResponse_SERVING
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, checker, Readiness))
+	})
+	t.Run("With checker stopped", func(t *testing.T) {
+		checker := NewChecker(testConfig())
+		checker.AddDependency("postgres", func(context.Context) error { return nil })
+		checker.AddService("bank", "postgres")
+		checker.Start(context.TODO())
+		checker.Stop()
+		// stopping twice is harmless
+		checker.Stop()
+
+		assert.True(t, checker.Live())
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, checker, Liveness))
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, checker, Readiness))
+		assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, checker, "bank"))
+		assert.Equal(t, "NOT_SERVING", checker.Report().Status)
+	})
+	t.Run("With gRPC health service registered", func(t *testing.T) {
+		checker := NewChecker(testConfig())
+		checker.AddDependency("postgres", func(context.Context) error { return nil })
+		checker.AddService("bank", "postgres")
+		checker.Start(context.TODO())
+		defer checker.Stop()
+
+		listener, err := net.Listen("tcp", "localhost:0")
+		require.NoError(t, err)
+		server := grpc.NewServer()
+		checker.RegisterService(server)
+		go func() { _ = server.Serve(listener) }()
+		defer server.Stop()
+
+		conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		response, err := healthpb.NewHealthClient(conn).Check(context.TODO(), &healthpb.HealthCheckRequest{Service: "bank"})
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, response.GetStatus())
+	})
+}
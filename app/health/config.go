@@ -0,0 +1,27 @@
+package health
+
+import (
+	"time"
+
+	"github.com/caarlos0/env/v9"
+	"github.com/pkg/errors"
+)
+
+// Config defines the health checking config
+type Config struct {
+	Port     int           `env:"HEALTH_PORT" envDefault:"8086"`         // Port is the port of the HTTP liveness and readiness probes
+	Interval time.Duration `env:"HEALTH_CHECK_INTERVAL" envDefault:"5s"` // Interval is the interval between two probes of the dependencies
+	Timeout  time.Duration `env:"HEALTH_CHECK_TIMEOUT" envDefault:"2s"`  // Timeout is how long a probe of a dependency may take
+}
+
+// LoadConfig fetches the Config from env vars
+func LoadConfig() *Config {
+	config := &Config{}
+	// all env vars are required
+	opts := env.Options{RequiredIfNoDef: true}
+	if err := env.ParseWithOptions(config, opts); err != nil {
+		panic(errors.Wrap(err, "unable to load environment variables"))
+	}
+
+	return config
+}
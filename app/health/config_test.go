@@ -0,0 +1,27 @@
+package health
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("With default values", func(t *testing.T) {
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.Equal(t, 8086, actual.Port)
+		assert.Equal(t, 5*time.Second, actual.Interval)
+		assert.Equal(t, 2*time.Second, actual.Timeout)
+	})
+	t.Run("With check interval set", func(t *testing.T) {
+		assert.NoError(t, os.Setenv("HEALTH_CHECK_INTERVAL", "10s"))
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.Equal(t, 10*time.Second, actual.Interval)
+		assert.NoError(t, os.Unsetenv("HEALTH_CHECK_INTERVAL"))
+	})
+}
@@ -0,0 +1,85 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/tochemey/cos-go-sample/app/log"
+)
+
+const (
+	// LivenessPath is the path of the HTTP liveness probe
+	LivenessPath = "/livez"
+	// ReadinessPath is the path of the HTTP readiness probe
+	ReadinessPath = "/readyz"
+)
+
+// Server serves the liveness and readiness probes over HTTP, for the orchestrators not speaking the gRPC health checking protocol
+type Server struct {
+	server *http.Server
+}
+
+// NewServer creates an instance of Server serving the health reported by the given checker
+func NewServer(config *Config, checker *Checker) *Server {
+	return &Server{
+		server: &http.Server{
+			Addr:    fmt.Sprintf(":%d", config.Port),
+			Handler: NewHandler(checker),
+		},
+	}
+}
+
+// NewHandler creates the http handler serving the probes. The probes respond with 200 when the process is live, respectively ready,
+// and with 503 otherwise. The readiness probe responds with the health report of the process
+func NewHandler(checker *Checker) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(LivenessPath, func(w http.ResponseWriter, _ *http.Request) {
+		live := checker.Live()
+		writeJSON(w, live, map[string]string{"status": status(live).String()})
+	})
+	mux.HandleFunc(ReadinessPath, func(w http.ResponseWriter, _ *http.Request) {
+		report := checker.Report()
+		writeJSON(w, report.Status == status(true).String(), report)
+	})
+	return mux
+}
+
+// writeJSON writes a given probe response with the status code of a given outcome
+func writeJSON(w http.ResponseWriter, ok bool, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// Start starts the server. The requests are served in the background
+func (s *Server) Start() error {
+	// listen on the server address
+	listener, err := net.Listen("tcp", s.server.Addr)
+	// handle the error
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen on (%s)", s.server.Addr)
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error(errors.Wrap(err, "the health server stopped unexpectedly"))
+		}
+	}()
+
+	return nil
+}
+
+// Stop gracefully stops the server
+func (s *Server) Stop(ctx context.Context) error {
+	if err := s.server.Shutdown(ctx); err != nil {
+		return errors.Wrap(err, "failed to stop the health server")
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// freePort returns a free tcp port
+func freePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("With all dependencies available", func(t *testing.T) {
+		checker := NewChecker(testConfig())
+		checker.AddDependency("postgres", func(context.Context) error { return nil })
+		checker.AddService("bank", "postgres")
+		checker.Start(context.TODO())
+		defer checker.Stop()
+		handler := NewHandler(checker)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, LivenessPath, nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, ReadinessPath, nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		report := new(Report)
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), report))
+		assert.Equal(t, "SERVING", report.Status)
+		assert.Equal(t, "SERVING", report.Services["bank"])
+	})
+	t.Run("With a dependency not available", func(t *testing.T) {
+		checker := NewChecker(testConfig())
+		checker.AddDependency("postgres", func(context.Context) error { return errors.New("connection refused") })
+		checker.Start(context.TODO())
+		defer checker.Stop()
+		handler := NewHandler(checker)
+
+		// the process is still live
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, LivenessPath, nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, ReadinessPath, nil))
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		report := new(Report)
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), report))
+		assert.Equal(t, "connection refused", report.Dependencies["postgres"].Error)
+	})
+	t.Run("With checker stopped", func(t *testing.T) {
+		checker := NewChecker(testConfig())
+		checker.Start(context.TODO())
+		checker.Stop()
+		handler := NewHandler(checker)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, LivenessPath, nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, ReadinessPath, nil))
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	})
+}
+
+func TestServer(t *testing.T) {
+	t.Run("With start and stop", func(t *testing.T) {
+		config := &Config{Port: freePort(t)}
+		server := NewServer(config, NewChecker(testConfig()))
+		require.NoError(t, server.Start())
+
+		response, err := http.Get(fmt.Sprintf("http://localhost:%d%s", config.Port, LivenessPath))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.NoError(t, response.Body.Close())
+
+		assert.NoError(t, server.Stop(context.TODO()))
+	})
+	t.Run("With port already in use", func(t *testing.T) {
+		listener, err := net.Listen("tcp", ":0")
+		require.NoError(t, err)
+		defer listener.Close()
+
+		server := NewServer(&Config{Port: listener.Addr().(*net.TCPAddr).Port}, NewChecker(testConfig()))
+		assert.Error(t, server.Start())
+	})
+}
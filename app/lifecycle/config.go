@@ -0,0 +1,26 @@
+package lifecycle
+
+import (
+	"time"
+
+	"github.com/caarlos0/env/v9"
+	"github.com/pkg/errors"
+)
+
+// Config defines the shutdown config
+type Config struct {
+	DrainDelay time.Duration `env:"SHUTDOWN_DRAIN_DELAY" envDefault:"5s"` // DrainDelay is how long the process reports not serving before draining the requests
+	Timeout    time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"30s"`    // Timeout is how long the components are given to stop
+}
+
+// LoadConfig fetches the Config from env vars
+func LoadConfig() *Config {
+	config := &Config{}
+	// all env vars are required
+	opts := env.Options{RequiredIfNoDef: true}
+	if err := env.ParseWithOptions(config, opts); err != nil {
+		panic(errors.Wrap(err, "unable to load environment variables"))
+	}
+
+	return config
+}
@@ -0,0 +1,26 @@
+package lifecycle
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("With default values", func(t *testing.T) {
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.Equal(t, 5*time.Second, actual.DrainDelay)
+		assert.Equal(t, 30*time.Second, actual.Timeout)
+	})
+	t.Run("With shutdown timeout set", func(t *testing.T) {
+		assert.NoError(t, os.Setenv("SHUTDOWN_TIMEOUT", "1m"))
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.Equal(t, time.Minute, actual.Timeout)
+		assert.NoError(t, os.Unsetenv("SHUTDOWN_TIMEOUT"))
+	})
+}
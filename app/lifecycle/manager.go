@@ -0,0 +1,139 @@
+package lifecycle
+
+import (
+	"context"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/tochemey/cos-go-sample/app/log"
+)
+
+// Component is a part of a process started and stopped by the Manager, e.g. a server or a client connection
+type Component struct {
+	// Name names the component in the logs and the errors
+	Name string
+	// Start starts the component. It must return once the component is started, the long-running work being done in the background.
+	// The components without Start are only stopped
+	Start func(ctx context.Context) error
+	// Stop stops the component. It must return once the given context is done.
+	// The components without Stop are only started
+	Stop func(ctx context.Context) error
+}
+
+// Manager starts the components of a process in the order of their dependencies and stops them in the reverse order.
+// A component is added after the components it depends on, e.g. a server after the database it reads from,
+// so that it is started after them and stopped before them
+type Manager struct {
+	config     *Config
+	components []Component
+
+	// started holds the components started, in their start order
+	started []Component
+	mu      sync.Mutex
+}
+
+// NewManager creates an instance of Manager
+func NewManager(config *Config) *Manager {
+	return &Manager{config: config}
+}
+
+// Add adds the given components, started in the given order after the components already added
+func (m *Manager) Add(components ...Component) *Manager {
+	m.components = append(m.components, components...)
+	return m
+}
+
+// Run starts the components and awaits for the termination signals (SIGINT, SIGTERM), or the given context to be done, to stop them
+func (m *Manager) Run(ctx context.Context) error {
+	// the termination signals received while starting stop the components once started
+	signalCtx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if err := m.Start(ctx); err != nil {
+		return err
+	}
+	<-signalCtx.Done()
+
+	log.Info("shutting down")
+	return m.Stop(context.WithoutCancel(ctx))
+}
+
+// Start starts the components in the order they are added. When a component fails to start,
+// the components already started are stopped and the start error is returned
+func (m *Manager) Start(ctx context.Context) error {
+	for _, component := range m.components {
+		if component.Start != nil {
+			if err := component.Start(ctx); err != nil {
+				err = errors.Wrapf(err, "failed to start (%s)", component.Name)
+				return multierr.Append(err, m.Stop(context.WithoutCancel(ctx)))
+			}
+		}
+
+		m.mu.Lock()
+		m.started = append(m.started, component)
+		m.mu.Unlock()
+	}
+	return nil
+}
+
+// Stop stops the started components in the reverse order of their start, all within the shutdown timeout.
+// A component failing to stop does not prevent the next ones from stopping, while a component still stopping at the timeout
+// blocks the shutdown: the next ones are not stopped and the error returned names the blocking component
+func (m *Manager) Stop(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, m.config.Timeout)
+	defer cancel()
+
+	m.mu.Lock()
+	started := m.started
+	m.started = nil
+	m.mu.Unlock()
+
+	var err error
+	for i := len(started) - 1; i >= 0; i-- {
+		component := started[i]
+		if component.Stop == nil {
+			continue
+		}
+
+		done := make(chan error, 1)
+		go func() { done <- component.Stop(ctx) }()
+
+		var stopErr error
+		select {
+		case stopErr = <-done:
+		case <-ctx.Done():
+		}
+
+		// the component returning at the timeout, e.g. forcibly stopped, blocked the shutdown as well
+		if ctx.Err() != nil {
+			log.Errorf("the shutdown is blocked by (%s)", component.Name)
+			return multierr.Append(err, errors.Wrapf(ctx.Err(), "the shutdown is blocked by (%s)", component.Name))
+		}
+		if stopErr != nil {
+			log.Errorf("failed to stop (%s): %v", component.Name, stopErr)
+			err = multierr.Append(err, errors.Wrapf(stopErr, "failed to stop (%s)", component.Name))
+			continue
+		}
+		log.Infof("(%s) stopped", component.Name)
+	}
+	return err
+}
+
+// Drain waits for the drain delay, e.g. after reporting the process as not serving so that the clients and the load balancers
+// notice it before the in-flight requests are drained. It returns early when the given context is done
+func (m *Manager) Drain(ctx context.Context) error {
+	timer := time.NewTimer(m.config.DrainDelay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
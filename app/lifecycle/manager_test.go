@@ -0,0 +1,167 @@
+package lifecycle
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder records the starts and the stops of the components
+type recorder struct {
+	calls []string
+}
+
+// component returns a component recording its start and its stop
+func (r *recorder) component(name string) Component {
+	return Component{
+		Name: name,
+		Start: func(context.Context) error {
+			r.calls = append(r.calls, "start "+name)
+			return nil
+		},
+		Stop: func(context.Context) error {
+			r.calls = append(r.calls, "stop "+name)
+			return nil
+		},
+	}
+}
+
+func TestManager(t *testing.T) {
+	config := &Config{DrainDelay: 10 * time.Millisecond, Timeout: 100 * time.Millisecond}
+
+	t.Run("With components started and stopped in order", func(t *testing.T) {
+		rec := new(recorder)
+		manager := NewManager(config).Add(rec.component("database"), rec.component("cos"), rec.component("server"))
+
+		require.NoError(t, manager.Start(context.TODO()))
+		require.NoError(t, manager.Stop(context.TODO()))
+		assert.Equal(t, []string{"start database", "start cos", "start server", "stop server", "stop cos", "stop database"}, rec.calls)
+
+		// the components are stopped once
+		require.NoError(t, manager.Stop(context.TODO()))
+		assert.Len(t, rec.calls, 6)
+	})
+	t.Run("With components without start or stop", func(t *testing.T) {
+		rec := new(recorder)
+		manager := NewManager(config).Add(
+			Component{Name: "database", Stop: rec.component("database").Stop},
+			Component{Name: "server", Start: rec.component("server").Start},
+		)
+
+		require.NoError(t, manager.Start(context.TODO()))
+		require.NoError(t, manager.Stop(context.TODO()))
+		assert.Equal(t, []string{"start server", "stop database"}, rec.calls)
+	})
+	t.Run("With component failing to start", func(t *testing.T) {
+		rec := new(recorder)
+		manager := NewManager(config).Add(
+			rec.component("database"),
+			Component{Name: "server", Start: func(context.Context) error { return errors.New("address already in use") }},
+			rec.component("gateway"),
+		)
+
+		err := manager.Start(context.TODO())
+		assert.EqualError(t, err, "failed to start (server): address already in use")
+		// the components started are stopped and the next ones are not started
+		assert.Equal(t, []string{"start database", "stop database"}, rec.calls)
+	})
+	t.Run("With component failing to stop", func(t *testing.T) {
+		rec := new(recorder)
+		manager := NewManager(config).Add(
+			rec.component("database"),
+			Component{Name: "cos", Stop: func(context.Context) error { return errors.New("connection already closed") }},
+			rec.component("server"),
+		)
+
+		require.NoError(t, manager.Start(context.TODO()))
+		err := manager.Stop(context.TODO())
+		assert.EqualError(t, err, "failed to stop (cos): connection already closed")
+		// the next components are still stopped
+		assert.Equal(t, []string{"start database", "start server", "stop server", "stop database"}, rec.calls)
+	})
+	t.Run("With component blocking the shutdown", func(t *testing.T) {
+		rec := new(recorder)
+		blocked := make(chan struct{})
+		defer close(blocked)
+		manager := NewManager(config).Add(
+			rec.component("database"),
+			Component{Name: "subscriptions", Stop: func(context.Context) error {
+				<-blocked
+				return nil
+			}},
+			rec.component("server"),
+		)
+
+		require.NoError(t, manager.Start(context.TODO()))
+		err := manager.Stop(context.TODO())
+		assert.EqualError(t, err, "the shutdown is blocked by (subscriptions): context deadline exceeded")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		// the components after the blocking one are not stopped
+		assert.Equal(t, []string{"start database", "start server", "stop server"}, rec.calls)
+	})
+	t.Run("With component forcibly stopped at the timeout", func(t *testing.T) {
+		rec := new(recorder)
+		manager := NewManager(config).Add(
+			rec.component("database"),
+			Component{Name: "server", Stop: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}},
+		)
+
+		require.NoError(t, manager.Start(context.TODO()))
+		assert.EqualError(t, manager.Stop(context.TODO()), "the shutdown is blocked by (server): context deadline exceeded")
+		assert.Equal(t, []string{"start database"}, rec.calls)
+	})
+	t.Run("With termination signal", func(t *testing.T) {
+		rec := new(recorder)
+		manager := NewManager(config).Add(rec.component("database"), rec.component("server"))
+
+		done := make(chan error, 1)
+		go func() { done <- manager.Run(context.TODO()) }()
+		// wait for the components to be started before signaling the termination
+		assert.Eventually(t, func() bool {
+			manager.mu.Lock()
+			defer manager.mu.Unlock()
+			return len(manager.started) == 2
+		}, time.Second, time.Millisecond)
+		require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGTERM))
+
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			require.Fail(t, "the manager did not stop on termination")
+		}
+		assert.Equal(t, []string{"start database", "start server", "stop server", "stop database"}, rec.calls)
+	})
+	t.Run("With context done", func(t *testing.T) {
+		rec := new(recorder)
+		manager := NewManager(config).Add(rec.component("database"))
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+		require.NoError(t, manager.Run(ctx))
+		assert.Equal(t, []string{"start database", "stop database"}, rec.calls)
+	})
+}
+
+func TestDrain(t *testing.T) {
+	t.Run("With drain delay elapsed", func(t *testing.T) {
+		manager := NewManager(&Config{DrainDelay: 10 * time.Millisecond})
+		start := time.Now()
+		assert.NoError(t, manager.Drain(context.TODO()))
+		assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+	})
+	t.Run("With context done", func(t *testing.T) {
+		manager := NewManager(&Config{DrainDelay: time.Minute})
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+		assert.ErrorIs(t, manager.Drain(ctx), context.Canceled)
+	})
+}
@@ -7,7 +7,8 @@ import (
 
 // Config represents the gRPC service configuration
 type Config struct {
-	LogLevel string `env:"LOG_LEVEL" envDefault:"DEBUG"` // LogLevel define the application log level
+	LogLevel       string   `env:"LOG_LEVEL" envDefault:"DEBUG"`                       // LogLevel define the application log level
+	RedactedFields []string `env:"LOG_REDACTED_FIELDS" envDefault:"" envSeparator:","` // RedactedFields are the full names of the proto fields masked in the logs along the sensitive ones
 }
 
 // loadConfig return the Config from env vars or panic in case of error
@@ -0,0 +1,75 @@
+package log
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// Field is a typed key-value pair added to a structured log entry
+type Field struct {
+	field zap.Field
+}
+
+// String returns a string field
+func String(key, value string) Field {
+	return Field{field: zap.String(key, value)}
+}
+
+// Int returns an int field
+func Int(key string, value int) Field {
+	return Field{field: zap.Int(key, value)}
+}
+
+// Int32 returns an int32 field
+func Int32(key string, value int32) Field {
+	return Field{field: zap.Int32(key, value)}
+}
+
+// Int64 returns an int64 field
+func Int64(key string, value int64) Field {
+	return Field{field: zap.Int64(key, value)}
+}
+
+// Float64 returns a float64 field
+func Float64(key string, value float64) Field {
+	return Field{field: zap.Float64(key, value)}
+}
+
+// Bool returns a bool field
+func Bool(key string, value bool) Field {
+	return Field{field: zap.Bool(key, value)}
+}
+
+// Time returns a time field
+func Time(key string, value time.Time) Field {
+	return Field{field: zap.Time(key, value)}
+}
+
+// Duration returns a duration field
+func Duration(key string, value time.Duration) Field {
+	return Field{field: zap.Duration(key, value)}
+}
+
+// Err returns the field of an error under the error key
+func Err(err error) Field {
+	return Field{field: zap.Error(err)}
+}
+
+// Proto returns the field of a proto message. The redacted fields of the message are masked
+func Proto(key string, message proto.Message) Field {
+	if message == nil || !message.ProtoReflect().IsValid() {
+		return Field{field: zap.Skip()}
+	}
+	return Field{field: zap.Object(key, redaction.Marshaler(message))}
+}
+
+// zapFields returns the zap fields of the given fields
+func zapFields(fields []Field) []zap.Field {
+	zapped := make([]zap.Field, len(fields))
+	for index, field := range fields {
+		zapped[index] = field.field
+	}
+	return zapped
+}
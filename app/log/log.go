@@ -8,6 +8,7 @@ import (
 
 	"github.com/tochemey/gopack/log"
 	"github.com/tochemey/gopack/log/zapl"
+	"go.uber.org/zap"
 )
 
 // defines the log levels map
@@ -24,6 +25,11 @@ var (
 	globalLevel log.Level
 	once        sync.Once
 	logger      *zapl.Log
+	// wrapped is the logger used behind the functions and the context loggers of the package.
+	// It skips one more caller so that the entries are reported at the call sites
+	wrapped *zapl.Log
+	// redaction is the policy masking the redacted fields of the logged messages
+	redaction *RedactionPolicy
 )
 
 // init sets the global log level and the logger
@@ -39,62 +45,73 @@ func init() {
 		}
 		// logger sets the logger to use in the application
 		logger = zapl.New(globalLevel, os.Stderr)
+		wrapped = &zapl.Log{Logger: logger.Logger.WithOptions(zap.AddCallerSkip(1))}
+		// set the redaction policy of the sensitive and the configured fields
+		redaction = NewRedactionPolicy(config.RedactedFields...)
 	})
 }
 
 // Info logs to INFO level.
 func Info(v ...any) {
-	logger.Info(v...)
+	wrapped.Info(redaction.redactedArgs(v)...)
 }
 
 // Infof logs to INFO level
 func Infof(format string, v ...any) {
-	logger.Infof(format, v...)
+	wrapped.Infof(format, redaction.redactedArgs(v)...)
 }
 
 // Warn logs to the WARNING level.
 func Warn(v ...any) {
-	logger.Warn(v...)
+	wrapped.Warn(redaction.redactedArgs(v)...)
 }
 
 // Warnf logs to the WARNING level.
 func Warnf(format string, v ...any) {
-	logger.Warnf(format, v...)
+	wrapped.Warnf(format, redaction.redactedArgs(v)...)
 }
 
 // Error logs to the ERROR level.
 func Error(v ...any) {
-	logger.Error(v...)
+	wrapped.Error(redaction.redactedArgs(v)...)
 }
 
 // Errorf logs to the ERROR level.
 func Errorf(format string, v ...any) {
-	logger.Errorf(format, v...)
+	wrapped.Errorf(format, redaction.redactedArgs(v)...)
 }
 
 // Fatal logs to the FATAL level followed by a call to os.Exit(1).
 func Fatal(v ...any) {
-	logger.Fatal(v...)
+	wrapped.Fatal(redaction.redactedArgs(v)...)
 }
 
 // Fatalf logs to the FATAL level followed by a call to os.Exit(1).
 func Fatalf(format string, v ...any) {
-	logger.Fatalf(format, v...)
+	wrapped.Fatalf(format, redaction.redactedArgs(v)...)
 }
 
 // Panic logs to the PANIC level followed by a call to panic().
 func Panic(v ...any) {
-	logger.Panic(v...)
+	wrapped.Panic(redaction.redactedArgs(v)...)
 }
 
 // Panicf logs to the PANIC level followed by a call to panic().
 func Panicf(format string, v ...any) {
-	logger.Panicf(format, v...)
+	wrapped.Panicf(format, redaction.redactedArgs(v)...)
 }
 
 // WithContext returns the Logger associated with the ctx.
 // This will set the traceid, requestid and spanid in case there are
-// in the context
+// in the context. The logged messages have their redacted fields masked
 func WithContext(ctx context.Context) log.Logger {
-	return logger.WithContext(ctx)
+	return &redactingLogger{Logger: wrapped.WithContext(ctx)}
+}
+
+// contextZapLogger returns the zap logger associated with the ctx
+func contextZapLogger(ctx context.Context) *zap.Logger {
+	if contextLogger, ok := logger.WithContext(ctx).(*zapl.Log); ok {
+		return contextLogger.Logger
+	}
+	return logger.Logger
 }
@@ -0,0 +1,122 @@
+package log
+
+import (
+	"context"
+
+	"github.com/tochemey/gopack/log"
+	"go.uber.org/zap"
+)
+
+// Logger is a structured logger. Its entries carry a message along typed fields
+type Logger struct {
+	zap *zap.Logger
+}
+
+// With returns the structured logger adding the given fields to its entries
+func With(fields ...Field) *Logger {
+	return &Logger{zap: logger.Logger.With(zapFields(fields)...)}
+}
+
+// FromContext returns the structured logger associated with the ctx.
+// Its entries carry the traceid, requestid and spanid in case there are in the context
+func FromContext(ctx context.Context) *Logger {
+	return &Logger{zap: contextZapLogger(ctx)}
+}
+
+// With returns a logger adding the given fields to the entries along the fields of the logger
+func (l *Logger) With(fields ...Field) *Logger {
+	return &Logger{zap: l.zap.With(zapFields(fields)...)}
+}
+
+// Debug logs a message with the given fields to the DEBUG level
+func (l *Logger) Debug(msg string, fields ...Field) {
+	l.zap.Debug(msg, zapFields(fields)...)
+}
+
+// Info logs a message with the given fields to the INFO level
+func (l *Logger) Info(msg string, fields ...Field) {
+	l.zap.Info(msg, zapFields(fields)...)
+}
+
+// Warn logs a message with the given fields to the WARNING level
+func (l *Logger) Warn(msg string, fields ...Field) {
+	l.zap.Warn(msg, zapFields(fields)...)
+}
+
+// Error logs a message with the given fields to the ERROR level
+func (l *Logger) Error(msg string, fields ...Field) {
+	l.zap.Error(msg, zapFields(fields)...)
+}
+
+// redactingLogger is a context logger masking the redacted fields of the messages it logs
+type redactingLogger struct {
+	log.Logger
+}
+
+// enforce compilation error
+var _ log.Logger = (*redactingLogger)(nil)
+
+// Info logs to INFO level
+func (l *redactingLogger) Info(v ...any) {
+	l.Logger.Info(redaction.redactedArgs(v)...)
+}
+
+// Infof logs to INFO level
+func (l *redactingLogger) Infof(format string, v ...any) {
+	l.Logger.Infof(format, redaction.redactedArgs(v)...)
+}
+
+// Warn logs to the WARNING level
+func (l *redactingLogger) Warn(v ...any) {
+	l.Logger.Warn(redaction.redactedArgs(v)...)
+}
+
+// Warnf logs to the WARNING level
+func (l *redactingLogger) Warnf(format string, v ...any) {
+	l.Logger.Warnf(format, redaction.redactedArgs(v)...)
+}
+
+// Error logs to the ERROR level
+func (l *redactingLogger) Error(v ...any) {
+	l.Logger.Error(redaction.redactedArgs(v)...)
+}
+
+// Errorf logs to the ERROR level
+func (l *redactingLogger) Errorf(format string, v ...any) {
+	l.Logger.Errorf(format, redaction.redactedArgs(v)...)
+}
+
+// Fatal logs to the FATAL level followed by a call to os.Exit(1)
+func (l *redactingLogger) Fatal(v ...any) {
+	l.Logger.Fatal(redaction.redactedArgs(v)...)
+}
+
+// Fatalf logs to the FATAL level followed by a call to os.Exit(1)
+func (l *redactingLogger) Fatalf(format string, v ...any) {
+	l.Logger.Fatalf(format, redaction.redactedArgs(v)...)
+}
+
+// Panic logs to the PANIC level followed by a call to panic()
+func (l *redactingLogger) Panic(v ...any) {
+	l.Logger.Panic(redaction.redactedArgs(v)...)
+}
+
+// Panicf logs to the PANIC level followed by a call to panic()
+func (l *redactingLogger) Panicf(format string, v ...any) {
+	l.Logger.Panicf(format, redaction.redactedArgs(v)...)
+}
+
+// Debug logs to the DEBUG level
+func (l *redactingLogger) Debug(v ...any) {
+	l.Logger.Debug(redaction.redactedArgs(v)...)
+}
+
+// Debugf logs to the DEBUG level
+func (l *redactingLogger) Debugf(format string, v ...any) {
+	l.Logger.Debugf(format, redaction.redactedArgs(v)...)
+}
+
+// WithContext returns the redacting logger associated with the ctx
+func (l *redactingLogger) WithContext(ctx context.Context) log.Logger {
+	return &redactingLogger{Logger: l.Logger.WithContext(ctx)}
+}
@@ -0,0 +1,81 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tochemey/gopack/log/zapl"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestLogger(t *testing.T) {
+	t.Run("With typed fields", func(t *testing.T) {
+		core, logs := observer.New(zapcore.DebugLevel)
+		logger := (&Logger{zap: zap.New(core)}).With(String("component", "subscription"))
+
+		logger.Info("event received",
+			Int32("revision", 2),
+			Bool("replayed", false),
+			Err(context.Canceled),
+			Proto("event", &pb.AccountDebited{AccountId: "account-1", Amount: 50}))
+
+		require.Equal(t, 1, logs.Len())
+		entry := logs.All()[0]
+		assert.Equal(t, "event received", entry.Message)
+		assert.Equal(t, zapcore.InfoLevel, entry.Level)
+		assert.Equal(t, map[string]any{
+			"component": "subscription",
+			"revision":  int32(2),
+			"replayed":  false,
+			"error":     "context canceled",
+			"event":     map[string]any{"account_id": "account-1", "amount": "[REDACTED]"},
+		}, entry.ContextMap())
+	})
+	t.Run("With levels", func(t *testing.T) {
+		core, logs := observer.New(zapcore.InfoLevel)
+		logger := &Logger{zap: zap.New(core)}
+
+		logger.Debug("debug")
+		logger.Info("info")
+		logger.Warn("warn")
+		logger.Error("error")
+
+		var messages []string
+		for _, entry := range logs.All() {
+			messages = append(messages, entry.Message)
+		}
+		assert.Equal(t, []string{"info", "warn", "error"}, messages)
+	})
+	t.Run("Without proto message", func(t *testing.T) {
+		core, logs := observer.New(zapcore.DebugLevel)
+		(&Logger{zap: zap.New(core)}).Info("event received", Proto("event", nil))
+
+		require.Equal(t, 1, logs.Len())
+		assert.Empty(t, logs.All()[0].ContextMap())
+	})
+	t.Run("With context", func(t *testing.T) {
+		assert.NotNil(t, FromContext(context.TODO()))
+		assert.NotNil(t, With(String("component", "subscription")))
+	})
+}
+
+func TestRedactingLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := &redactingLogger{Logger: &zapl.Log{Logger: zap.New(core)}}
+
+	account := &pb.BankAccount{AccountId: "account-1", AccountOwner: "John Doe", AccountBalance: 100}
+	logger.Infof("account persisted: %v", account)
+	logger.WithContext(context.TODO()).Warn("account persisted: ", account)
+
+	require.Equal(t, 2, logs.Len())
+	for _, entry := range logs.All() {
+		assert.NotContains(t, entry.Message, "John Doe")
+		assert.Contains(t, entry.Message, `"account_owner":"[REDACTED]"`)
+	}
+}
@@ -0,0 +1,229 @@
+package log
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// redactedValue replaces the values of the redacted fields
+const redactedValue = "[REDACTED]"
+
+// RedactionPolicy designates the proto fields whose values are masked whenever the messages are logged:
+// the fields annotated as sensitive and the ones configured by full name, e.g. accounts.v1.BankAccount.account_id
+type RedactionPolicy struct {
+	fields map[protoreflect.FullName]struct{}
+}
+
+// NewRedactionPolicy creates an instance of RedactionPolicy masking the sensitive fields and the given ones
+func NewRedactionPolicy(fieldNames ...string) *RedactionPolicy {
+	fields := make(map[protoreflect.FullName]struct{}, len(fieldNames))
+	for _, fieldName := range fieldNames {
+		if fieldName != "" {
+			fields[protoreflect.FullName(fieldName)] = struct{}{}
+		}
+	}
+	return &RedactionPolicy{fields: fields}
+}
+
+// Redacts checks whether the value of a given field is masked
+func (p *RedactionPolicy) Redacts(field protoreflect.FieldDescriptor) bool {
+	if _, ok := p.fields[field.FullName()]; ok {
+		return true
+	}
+	sensitive, _ := proto.GetExtension(field.Options(), pb.E_Sensitive).(bool)
+	return sensitive
+}
+
+// Marshaler returns the zap marshaler logging the given message with its redacted fields masked
+func (p *RedactionPolicy) Marshaler(message proto.Message) zapcore.ObjectMarshaler {
+	return redactedMessage{policy: p, message: message.ProtoReflect()}
+}
+
+// Redact returns the JSON text of the given message with its redacted fields masked
+func (p *RedactionPolicy) Redact(message proto.Message) string {
+	if message == nil || !message.ProtoReflect().IsValid() {
+		return "{}"
+	}
+
+	encoder := zapcore.NewMapObjectEncoder()
+	if err := p.Marshaler(message).MarshalLogObject(encoder); err != nil {
+		return redactedValue
+	}
+	text, err := json.Marshal(encoder.Fields)
+	if err != nil {
+		return redactedValue
+	}
+	return string(text)
+}
+
+// redactedMessage logs a message with its redacted fields masked. The messages packed into an Any are unpacked
+// when their type is known so that their redacted fields are masked too, and the timestamps and durations are logged as text
+type redactedMessage struct {
+	policy  *RedactionPolicy
+	message protoreflect.Message
+}
+
+// MarshalLogObject logs the fields of the message
+func (m redactedMessage) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	if packed, ok := m.message.Interface().(*anypb.Any); ok {
+		encoder.AddString("@type", packed.GetTypeUrl())
+		unpacked, err := packed.UnmarshalNew()
+		if err != nil {
+			// the content of an unknown type cannot be redacted, hence it is not logged
+			return nil
+		}
+		m = redactedMessage{policy: m.policy, message: unpacked.ProtoReflect()}
+	}
+
+	var err error
+	m.message.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		name := string(field.Name())
+		switch {
+		case m.policy.Redacts(field):
+			encoder.AddString(name, redactedValue)
+		case field.IsList():
+			err = encoder.AddArray(name, redactedList{policy: m.policy, field: field, list: value.List()})
+		case field.IsMap():
+			err = encoder.AddObject(name, redactedMap{policy: m.policy, field: field, entries: value.Map()})
+		default:
+			err = addValue(encoder, name, m.policy.logValue(field, value))
+		}
+		return err == nil
+	})
+	return err
+}
+
+// redactedList logs the values of a repeated field
+type redactedList struct {
+	policy *RedactionPolicy
+	field  protoreflect.FieldDescriptor
+	list   protoreflect.List
+}
+
+// MarshalLogArray logs the values of the list
+func (l redactedList) MarshalLogArray(encoder zapcore.ArrayEncoder) error {
+	for index := range l.list.Len() {
+		if err := appendValue(encoder, l.policy.logValue(l.field, l.list.Get(index))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redactedMap logs the entries of a map field
+type redactedMap struct {
+	policy  *RedactionPolicy
+	field   protoreflect.FieldDescriptor
+	entries protoreflect.Map
+}
+
+// MarshalLogObject logs the entries of the map
+func (m redactedMap) MarshalLogObject(encoder zapcore.ObjectEncoder) error {
+	var err error
+	m.entries.Range(func(key protoreflect.MapKey, value protoreflect.Value) bool {
+		err = addValue(encoder, key.String(), m.policy.logValue(m.field.MapValue(), value))
+		return err == nil
+	})
+	return err
+}
+
+// logValue returns the value of a given field as logged: a marshaler for the messages, the name of the enums
+// and the Go value of the scalars
+func (p *RedactionPolicy) logValue(field protoreflect.FieldDescriptor, value protoreflect.Value) any {
+	switch field.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		switch message := value.Message().Interface().(type) {
+		case *timestamppb.Timestamp:
+			return message.AsTime().String()
+		case *durationpb.Duration:
+			return message.AsDuration().String()
+		}
+		return redactedMessage{policy: p, message: value.Message()}
+	case protoreflect.EnumKind:
+		if enumValue := field.Enum().Values().ByNumber(value.Enum()); enumValue != nil {
+			return string(enumValue.Name())
+		}
+		return int32(value.Enum())
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(value.Bytes())
+	default:
+		return value.Interface()
+	}
+}
+
+// addValue adds a logged value to an object
+func addValue(encoder zapcore.ObjectEncoder, key string, value any) error {
+	switch v := value.(type) {
+	case zapcore.ObjectMarshaler:
+		return encoder.AddObject(key, v)
+	case string:
+		encoder.AddString(key, v)
+	case bool:
+		encoder.AddBool(key, v)
+	case int32:
+		encoder.AddInt32(key, v)
+	case int64:
+		encoder.AddInt64(key, v)
+	case uint32:
+		encoder.AddUint32(key, v)
+	case uint64:
+		encoder.AddUint64(key, v)
+	case float32:
+		encoder.AddFloat32(key, v)
+	case float64:
+		encoder.AddFloat64(key, v)
+	default:
+		encoder.AddString(key, fmt.Sprint(v))
+	}
+	return nil
+}
+
+// appendValue appends a logged value to an array
+func appendValue(encoder zapcore.ArrayEncoder, value any) error {
+	switch v := value.(type) {
+	case zapcore.ObjectMarshaler:
+		return encoder.AppendObject(v)
+	case string:
+		encoder.AppendString(v)
+	case bool:
+		encoder.AppendBool(v)
+	case int32:
+		encoder.AppendInt32(v)
+	case int64:
+		encoder.AppendInt64(v)
+	case uint32:
+		encoder.AppendUint32(v)
+	case uint64:
+		encoder.AppendUint64(v)
+	case float32:
+		encoder.AppendFloat32(v)
+	case float64:
+		encoder.AppendFloat64(v)
+	default:
+		encoder.AppendString(fmt.Sprint(v))
+	}
+	return nil
+}
+
+// redactedArgs returns the given printf arguments with the messages replaced by their redacted text
+func (p *RedactionPolicy) redactedArgs(args []any) []any {
+	redacted := make([]any, len(args))
+	for index, arg := range args {
+		if message, ok := arg.(proto.Message); ok {
+			redacted[index] = p.Redact(message)
+			continue
+		}
+		redacted[index] = arg
+	}
+	return redacted
+}
@@ -0,0 +1,80 @@
+package log
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestRedactionPolicy(t *testing.T) {
+	t.Run("With sensitive fields", func(t *testing.T) {
+		policy := NewRedactionPolicy()
+		event := &pb.AccountOpened{
+			AccountId:    "account-1",
+			Balance:      100,
+			AccountOwner: "John Doe",
+			AccountType:  pb.AccountType_SAVINGS,
+			Caller:       &pb.Caller{Subject: "customer-1", ClientIp: "10.0.0.1", RequestId: "request-1"},
+		}
+
+		expected := `{"account_id":"account-1","account_owner":"[REDACTED]","account_type":"SAVINGS","balance":"[REDACTED]",` +
+			`"caller":{"client_ip":"[REDACTED]","request_id":"request-1","subject":"[REDACTED]"}}`
+		assert.Equal(t, expected, policy.Redact(event))
+	})
+	t.Run("With configured fields", func(t *testing.T) {
+		policy := NewRedactionPolicy("accounts.v1.BankAccount.account_id", "")
+		account := &pb.BankAccount{
+			AccountId:                "account-1",
+			AccountOwner:             "John Doe",
+			ProcessedIdempotencyKeys: []string{"key-1", "key-2"},
+		}
+
+		expected := `{"account_id":"[REDACTED]","account_owner":"[REDACTED]","processed_idempotency_keys":["key-1","key-2"]}`
+		assert.Equal(t, expected, policy.Redact(account))
+		assert.True(t, policy.Redacts(account.ProtoReflect().Descriptor().Fields().ByName("account_id")))
+		assert.False(t, policy.Redacts(account.ProtoReflect().Descriptor().Fields().ByName(protoreflect.Name("is_closed"))))
+	})
+	t.Run("With packed message", func(t *testing.T) {
+		policy := NewRedactionPolicy()
+		debited := &pb.AccountDebited{
+			AccountId: "account-1",
+			Amount:    50,
+			DebitedAt: timestamppb.New(time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)),
+			Fee:       &pb.FeeCharged{AccountId: "account-1", FeeId: "fee-1", Amount: 1},
+		}
+		packed, err := anypb.New(debited)
+		require.NoError(t, err)
+
+		expected := `{"@type":"type.googleapis.com/accounts.v1.AccountDebited","account_id":"account-1","amount":"[REDACTED]",` +
+			`"debited_at":"2024-01-01 10:00:00 +0000 UTC","fee":{"account_id":"account-1","amount":"[REDACTED]","fee_id":"fee-1"}}`
+		assert.Equal(t, expected, policy.Redact(packed))
+	})
+	t.Run("With packed message of unknown type", func(t *testing.T) {
+		policy := NewRedactionPolicy()
+		packed := &anypb.Any{TypeUrl: "type.googleapis.com/unknown.v1.Secret", Value: []byte("secret")}
+
+		assert.Equal(t, `{"@type":"type.googleapis.com/unknown.v1.Secret"}`, policy.Redact(packed))
+	})
+	t.Run("With printf arguments", func(t *testing.T) {
+		policy := NewRedactionPolicy()
+		args := []any{"account-1", &pb.AccountCredited{AccountId: "account-1", Amount: 20}, 3}
+
+		actual := policy.redactedArgs(args)
+		assert.Equal(t, []any{"account-1", `{"account_id":"account-1","amount":"[REDACTED]"}`, 3}, actual)
+		// the arguments are left unchanged
+		assert.IsType(t, &pb.AccountCredited{}, args[1])
+	})
+	t.Run("Without message", func(t *testing.T) {
+		policy := NewRedactionPolicy()
+		var account *pb.BankAccount
+		assert.Equal(t, "{}", policy.Redact(account))
+		assert.Equal(t, "{}", policy.Redact(nil))
+	})
+}
@@ -0,0 +1,133 @@
+package pii
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// envelopePrefix prefixes the encrypted values, followed by the id of their data key and their base64 encoded nonce and ciphertext
+const envelopePrefix = "pii:v1:"
+
+// ErrErased is returned when decrypting a value whose data key has been destroyed
+var ErrErased = errors.New("the personal data has been erased")
+
+// Cipher encrypts the personal data of the customers with their data key so that it can be erased by destroying the key,
+// the encrypted values being kept in the immutable events. The values are encrypted with AES-256-GCM
+type Cipher struct {
+	keyStore KeyStore
+}
+
+// NewCipher creates an instance of Cipher encrypting with the data keys of the given key store
+func NewCipher(keyStore KeyStore) *Cipher {
+	return &Cipher{keyStore: keyStore}
+}
+
+// Encrypt encrypts a given value with the data key of a given customer
+func (c *Cipher) Encrypt(ctx context.Context, customer, value string) (string, error) {
+	keyID, key, err := c.keyStore.DataKey(ctx, customer)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get the customer data key")
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", errors.Wrap(err, "failed to generate the nonce")
+	}
+	// the key id is authenticated so that a value cannot be moved to another key
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(keyID))
+	return envelopePrefix + keyID + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a given value. The values which are not encrypted, e.g. recorded before the encryption has been enabled,
+// are returned as is. ErrErased is returned when the data key of the value has been destroyed
+func (c *Cipher) Decrypt(ctx context.Context, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	keyID, encoded, ok := strings.Cut(strings.TrimPrefix(value, envelopePrefix), ":")
+	if !ok {
+		return "", errors.New("the encrypted value is malformed")
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", errors.Wrap(err, "the encrypted value is malformed")
+	}
+
+	key, err := c.keyStore.Key(ctx, keyID)
+	switch {
+	case errors.Is(err, ErrKeyNotFound):
+		return "", ErrErased
+	case err != nil:
+		return "", errors.Wrap(err, "failed to get the data key")
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("the encrypted value is malformed")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(keyID))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decrypt the value")
+	}
+	return string(plaintext), nil
+}
+
+// Erase destroys the data key of a given customer so that its personal data cannot be decrypted anymore
+func (c *Cipher) Erase(ctx context.Context, customer string) error {
+	if err := c.keyStore.Destroy(ctx, customer); err != nil {
+		return errors.Wrap(err, "failed to destroy the customer data key")
+	}
+	return nil
+}
+
+// DecryptAccount returns a copy of a given account with its personal data decrypted.
+// The personal data of the erased customers is left empty
+func (c *Cipher) DecryptAccount(ctx context.Context, account *pb.BankAccount) (*pb.BankAccount, error) {
+	if !IsEncrypted(account.GetAccountOwner()) {
+		return account, nil
+	}
+
+	owner, err := c.Decrypt(ctx, account.GetAccountOwner())
+	if err != nil && !errors.Is(err, ErrErased) {
+		return nil, err
+	}
+
+	decrypted := proto.Clone(account).(*pb.BankAccount)
+	decrypted.AccountOwner = owner
+	return decrypted, nil
+}
+
+// IsEncrypted checks whether a given value is encrypted
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, envelopePrefix)
+}
+
+// newAEAD creates the AES-GCM cipher of a given data key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the cipher")
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the cipher")
+	}
+	return aead, nil
+}
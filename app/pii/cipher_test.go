@@ -0,0 +1,113 @@
+package pii
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// newTestCipher creates a cipher holding its keys in a temporary directory
+func newTestCipher(t *testing.T) *Cipher {
+	store, err := NewLocalKeyStore(t.TempDir())
+	require.NoError(t, err)
+	return NewCipher(store)
+}
+
+func TestCipher(t *testing.T) {
+	ctx := context.TODO()
+	t.Run("With encrypted value", func(t *testing.T) {
+		cipher := newTestCipher(t)
+
+		encrypted, err := cipher.Encrypt(ctx, "John Doe", "John Doe")
+		require.NoError(t, err)
+		assert.True(t, IsEncrypted(encrypted))
+		assert.NotContains(t, encrypted, "John")
+
+		// the values are encrypted with a random nonce
+		other, err := cipher.Encrypt(ctx, "John Doe", "John Doe")
+		require.NoError(t, err)
+		assert.NotEqual(t, encrypted, other)
+
+		actual, err := cipher.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, "John Doe", actual)
+	})
+	t.Run("With value not encrypted", func(t *testing.T) {
+		actual, err := newTestCipher(t).Decrypt(ctx, "John Doe")
+		require.NoError(t, err)
+		assert.Equal(t, "John Doe", actual)
+	})
+	t.Run("With erased customer", func(t *testing.T) {
+		cipher := newTestCipher(t)
+		encrypted, err := cipher.Encrypt(ctx, "John Doe", "John Doe")
+		require.NoError(t, err)
+		janeEncrypted, err := cipher.Encrypt(ctx, "Jane Doe", "Jane Doe")
+		require.NoError(t, err)
+
+		require.NoError(t, cipher.Erase(ctx, "John Doe"))
+
+		_, err = cipher.Decrypt(ctx, encrypted)
+		assert.ErrorIs(t, err, ErrErased)
+
+		// the other customers are left readable
+		actual, err := cipher.Decrypt(ctx, janeEncrypted)
+		require.NoError(t, err)
+		assert.Equal(t, "Jane Doe", actual)
+	})
+	t.Run("With tampered value", func(t *testing.T) {
+		cipher := newTestCipher(t)
+		encrypted, err := cipher.Encrypt(ctx, "John Doe", "John Doe")
+		require.NoError(t, err)
+
+		// the value of a customer cannot be decrypted with the key of another one
+		janeEncrypted, err := cipher.Encrypt(ctx, "Jane Doe", "Jane Doe")
+		require.NoError(t, err)
+		janeKeyID := strings.Split(janeEncrypted, ":")[2]
+		parts := strings.Split(encrypted, ":")
+		parts[2] = janeKeyID
+
+		_, err = cipher.Decrypt(ctx, strings.Join(parts, ":"))
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrErased)
+	})
+	t.Run("With malformed value", func(t *testing.T) {
+		_, err := newTestCipher(t).Decrypt(ctx, "pii:v1:no-ciphertext")
+		assert.EqualError(t, err, "the encrypted value is malformed")
+	})
+}
+
+func TestDecryptAccount(t *testing.T) {
+	ctx := context.TODO()
+	cipher := newTestCipher(t)
+	owner, err := cipher.Encrypt(ctx, "John Doe", "John Doe")
+	require.NoError(t, err)
+	account := &pb.BankAccount{AccountId: "account-1", AccountBalance: 200, AccountOwner: owner}
+
+	t.Run("With encrypted owner", func(t *testing.T) {
+		actual, err := cipher.DecryptAccount(ctx, account)
+		require.NoError(t, err)
+		assert.Equal(t, "John Doe", actual.GetAccountOwner())
+		assert.EqualValues(t, 200, actual.GetAccountBalance())
+		// the given account is left untouched
+		assert.Equal(t, owner, account.GetAccountOwner())
+	})
+	t.Run("With owner not encrypted", func(t *testing.T) {
+		plain := &pb.BankAccount{AccountId: "account-2", AccountOwner: "Jane Doe"}
+		actual, err := cipher.DecryptAccount(ctx, plain)
+		require.NoError(t, err)
+		assert.Equal(t, plain, actual)
+	})
+	t.Run("With erased owner", func(t *testing.T) {
+		require.NoError(t, cipher.Erase(ctx, "John Doe"))
+
+		actual, err := cipher.DecryptAccount(ctx, account)
+		require.NoError(t, err)
+		assert.Empty(t, actual.GetAccountOwner())
+		assert.Equal(t, "account-1", actual.GetAccountId())
+	})
+}
@@ -0,0 +1,24 @@
+package pii
+
+import (
+	"github.com/caarlos0/env/v9"
+	"github.com/pkg/errors"
+)
+
+// Config defines the personal data encryption config
+type Config struct {
+	Enabled     bool   `env:"PII_ENCRYPTION_ENABLED" envDefault:"false"`             // Enabled states whether the personal data is encrypted
+	KeyStoreDir string `env:"PII_KEY_STORE_DIR" envDefault:"/var/lib/accounts/keys"` // KeyStoreDir is the directory of the local key store holding the customers data keys
+}
+
+// LoadConfig fetches the Config from env vars
+func LoadConfig() *Config {
+	config := &Config{}
+	// all env vars are required
+	opts := env.Options{RequiredIfNoDef: true}
+	if err := env.ParseWithOptions(config, opts); err != nil {
+		panic(errors.Wrap(err, "unable to load environment variables"))
+	}
+
+	return config
+}
@@ -0,0 +1,40 @@
+package pii
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("With default values", func(t *testing.T) {
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.False(t, actual.Enabled)
+		assert.Equal(t, "/var/lib/accounts/keys", actual.KeyStoreDir)
+	})
+	t.Run("With environment variables set", func(t *testing.T) {
+		assert.NoError(t, os.Setenv("PII_ENCRYPTION_ENABLED", "true"))
+		assert.NoError(t, os.Setenv("PII_KEY_STORE_DIR", "/tmp/keys"))
+
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.True(t, actual.Enabled)
+		assert.Equal(t, "/tmp/keys", actual.KeyStoreDir)
+
+		// free resources
+		assert.NoError(t, os.Unsetenv("PII_ENCRYPTION_ENABLED"))
+		assert.NoError(t, os.Unsetenv("PII_KEY_STORE_DIR"))
+	})
+	t.Run("With invalid environment variables", func(t *testing.T) {
+		assert.NoError(t, os.Setenv("PII_ENCRYPTION_ENABLED", "not-a-bool"))
+		assert.Panics(t, func() {
+			LoadConfig()
+		})
+
+		// free resources
+		assert.NoError(t, os.Unsetenv("PII_ENCRYPTION_ENABLED"))
+	})
+}
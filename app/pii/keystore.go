@@ -0,0 +1,163 @@
+package pii
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// keySize is the size of the data keys: AES-256 keys
+const keySize = 32
+
+// ErrKeyNotFound is returned when the data key looked up is not in the key store, e.g. it has been destroyed
+var ErrKeyNotFound = errors.New("the data key is not found")
+
+// KeyStore holds the data keys encrypting the personal data of the customers, one key per customer
+type KeyStore interface {
+	// DataKey returns the id and the data key of a given customer. The key is created when the customer has none
+	DataKey(ctx context.Context, customer string) (keyID string, key []byte, err error)
+	// Key returns the data key of a given id. ErrKeyNotFound is returned when the key does not exist
+	Key(ctx context.Context, keyID string) ([]byte, error)
+	// Destroy destroys the data key of a given customer so that its personal data cannot be decrypted anymore
+	Destroy(ctx context.Context, customer string) error
+}
+
+// LocalKeyStore is a KeyStore holding the data keys in files of a local directory, which must be shared by the instances.
+// The customers are only recorded by digest so that the key store does not hold their personal data
+type LocalKeyStore struct {
+	customersDir string
+	keysDir      string
+}
+
+// enforce compilation error
+var _ KeyStore = (*LocalKeyStore)(nil)
+
+// NewLocalKeyStore creates an instance of LocalKeyStore holding its keys under the given directory
+func NewLocalKeyStore(dir string) (*LocalKeyStore, error) {
+	store := &LocalKeyStore{
+		customersDir: filepath.Join(dir, "customers"),
+		keysDir:      filepath.Join(dir, "keys"),
+	}
+	for _, path := range []string{store.customersDir, store.keysDir} {
+		if err := os.MkdirAll(path, 0o700); err != nil {
+			return nil, errors.Wrapf(err, "failed to create the key store directory:(%s)", path)
+		}
+	}
+	return store, nil
+}
+
+// DataKey returns the id and the data key of a given customer. The key is created when the customer has none
+func (s *LocalKeyStore) DataKey(ctx context.Context, customer string) (string, []byte, error) {
+	keyID, err := s.customerKeyID(customer)
+	switch {
+	case err == nil:
+		key, err := s.Key(ctx, keyID)
+		return keyID, key, err
+	case !os.IsNotExist(err):
+		return "", nil, errors.Wrap(err, "failed to read the customer data key id")
+	}
+
+	// create the key before recording it as the customer key so that a recorded key always exists
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", nil, errors.Wrap(err, "failed to generate the data key")
+	}
+	keyID = uuid.NewString()
+	if err := os.WriteFile(s.keyPath(keyID), key, 0o600); err != nil {
+		return "", nil, errors.Wrap(err, "failed to write the data key")
+	}
+
+	// the customer key may be created concurrently, in which case the first one recorded wins
+	if err := s.recordCustomerKeyID(customer, keyID); err != nil {
+		_ = os.Remove(s.keyPath(keyID))
+		if os.IsExist(err) {
+			return s.DataKey(ctx, customer)
+		}
+		return "", nil, errors.Wrap(err, "failed to record the customer data key id")
+	}
+	return keyID, key, nil
+}
+
+// Key returns the data key of a given id. ErrKeyNotFound is returned when the key does not exist
+func (s *LocalKeyStore) Key(_ context.Context, keyID string) ([]byte, error) {
+	// the key ids are read from the encrypted values, hence they are checked before being used as file names
+	if _, err := uuid.Parse(keyID); err != nil {
+		return nil, ErrKeyNotFound
+	}
+
+	key, err := os.ReadFile(s.keyPath(keyID))
+	switch {
+	case os.IsNotExist(err):
+		return nil, ErrKeyNotFound
+	case err != nil:
+		return nil, errors.Wrap(err, "failed to read the data key")
+	case len(key) != keySize:
+		return nil, errors.Errorf("the data key:(%s) is corrupted", keyID)
+	}
+	return key, nil
+}
+
+// Destroy destroys the data key of a given customer. Destroying the key of a customer without key is a no-op
+func (s *LocalKeyStore) Destroy(_ context.Context, customer string) error {
+	keyID, err := s.customerKeyID(customer)
+	switch {
+	case os.IsNotExist(err):
+		return nil
+	case err != nil:
+		return errors.Wrap(err, "failed to read the customer data key id")
+	}
+
+	if err := os.Remove(s.keyPath(keyID)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to destroy the data key")
+	}
+	if err := os.Remove(s.customerPath(customer)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to remove the customer data key id")
+	}
+	return nil
+}
+
+// recordCustomerKeyID records a given data key id as the key of a given customer. The id is written to a temporary file
+// linked in place once complete, hence the customer file is never read partially written. The link fails with an
+// already exists error when the customer key has been recorded in the meantime
+func (s *LocalKeyStore) recordCustomerKeyID(customer, keyID string) error {
+	file, err := os.CreateTemp(s.customersDir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(keyID); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Link(file.Name(), s.customerPath(customer))
+}
+
+// customerKeyID reads the id of the data key of a given customer
+func (s *LocalKeyStore) customerKeyID(customer string) (string, error) {
+	keyID, err := os.ReadFile(s.customerPath(customer))
+	if err != nil {
+		return "", err
+	}
+	return string(keyID), nil
+}
+
+// customerPath returns the path of the file recording the data key id of a given customer
+func (s *LocalKeyStore) customerPath(customer string) string {
+	digest := sha256.Sum256([]byte(customer))
+	return filepath.Join(s.customersDir, hex.EncodeToString(digest[:]))
+}
+
+// keyPath returns the path of the file holding the data key of a given id
+func (s *LocalKeyStore) keyPath(keyID string) string {
+	return filepath.Join(s.keysDir, keyID)
+}
@@ -0,0 +1,105 @@
+package pii
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalKeyStore(t *testing.T) {
+	ctx := context.TODO()
+	t.Run("With data key", func(t *testing.T) {
+		store, err := NewLocalKeyStore(t.TempDir())
+		require.NoError(t, err)
+
+		keyID, key, err := store.DataKey(ctx, "John Doe")
+		require.NoError(t, err)
+		assert.NotEmpty(t, keyID)
+		assert.Len(t, key, keySize)
+
+		// the customer keeps its key
+		sameID, sameKey, err := store.DataKey(ctx, "John Doe")
+		require.NoError(t, err)
+		assert.Equal(t, keyID, sameID)
+		assert.Equal(t, key, sameKey)
+
+		// every customer has its own key
+		otherID, _, err := store.DataKey(ctx, "Jane Doe")
+		require.NoError(t, err)
+		assert.NotEqual(t, keyID, otherID)
+
+		actual, err := store.Key(ctx, keyID)
+		require.NoError(t, err)
+		assert.Equal(t, key, actual)
+	})
+	t.Run("With concurrent data keys", func(t *testing.T) {
+		store, err := NewLocalKeyStore(t.TempDir())
+		require.NoError(t, err)
+
+		keyIDs := make([]string, 32)
+		errs := make([]error, len(keyIDs))
+		var wg sync.WaitGroup
+		for index := range keyIDs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				keyIDs[index], _, errs[index] = store.DataKey(ctx, "John Doe")
+			}()
+		}
+		wg.Wait()
+
+		// the customer key id is never read partially recorded
+		for index, keyID := range keyIDs {
+			require.NoError(t, errs[index])
+			assert.Equal(t, keyIDs[0], keyID)
+		}
+		keys, err := os.ReadDir(store.keysDir)
+		require.NoError(t, err)
+		assert.Len(t, keys, 1)
+		customers, err := os.ReadDir(store.customersDir)
+		require.NoError(t, err)
+		assert.Len(t, customers, 1)
+	})
+	t.Run("With customers recorded by digest", func(t *testing.T) {
+		dir := t.TempDir()
+		store, err := NewLocalKeyStore(dir)
+		require.NoError(t, err)
+		_, _, err = store.DataKey(ctx, "John Doe")
+		require.NoError(t, err)
+
+		customers, err := os.ReadDir(filepath.Join(dir, "customers"))
+		require.NoError(t, err)
+		require.Len(t, customers, 1)
+		assert.NotContains(t, customers[0].Name(), "John")
+	})
+	t.Run("With destroyed key", func(t *testing.T) {
+		store, err := NewLocalKeyStore(t.TempDir())
+		require.NoError(t, err)
+		keyID, _, err := store.DataKey(ctx, "John Doe")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Destroy(ctx, "John Doe"))
+		_, err = store.Key(ctx, keyID)
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+
+		// destroying the key again is a no-op
+		assert.NoError(t, store.Destroy(ctx, "John Doe"))
+
+		// a new key is created afterwards
+		newID, _, err := store.DataKey(ctx, "John Doe")
+		require.NoError(t, err)
+		assert.NotEqual(t, keyID, newID)
+	})
+	t.Run("With invalid key id", func(t *testing.T) {
+		store, err := NewLocalKeyStore(t.TempDir())
+		require.NoError(t, err)
+
+		_, err = store.Key(ctx, "../customers/key")
+		assert.ErrorIs(t, err, ErrKeyNotFound)
+	})
+}
@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"github.com/caarlos0/env/v9"
+	"github.com/pkg/errors"
+)
+
+// BackendType is the backend holding the token buckets of the rate limits
+type BackendType string
+
+const (
+	// BackendMemory holds the token buckets in memory, hence every instance enforces its own limits
+	BackendMemory BackendType = "memory"
+	// BackendPostgres holds the token buckets in the database, hence the limits are shared by the instances
+	BackendPostgres BackendType = "postgres"
+)
+
+// Config defines the rate limiting config. A rate is a number of calls per second and a limit whose rate is zero is not enforced.
+// The burst is the number of calls allowed at once, the rate rounded up when not set
+type Config struct {
+	Enabled        bool        `env:"RATE_LIMIT_ENABLED" envDefault:"false"`     // Enabled states whether the api calls are rate limited
+	Backend        BackendType `env:"RATE_LIMIT_BACKEND" envDefault:"memory"`    // Backend holds the token buckets: memory or postgres
+	GlobalRate     float64     `env:"RATE_LIMIT_GLOBAL_RATE" envDefault:"0"`     // GlobalRate is the rate of all the api calls
+	GlobalBurst    int         `env:"RATE_LIMIT_GLOBAL_BURST" envDefault:"0"`    // GlobalBurst is the burst of all the api calls
+	PrincipalRate  float64     `env:"RATE_LIMIT_PRINCIPAL_RATE" envDefault:"0"`  // PrincipalRate is the rate of the calls of every authenticated principal
+	PrincipalBurst int         `env:"RATE_LIMIT_PRINCIPAL_BURST" envDefault:"0"` // PrincipalBurst is the burst of the calls of every authenticated principal
+	AccountRate    float64     `env:"RATE_LIMIT_ACCOUNT_RATE" envDefault:"0"`    // AccountRate is the rate of the calls targeting every account
+	AccountBurst   int         `env:"RATE_LIMIT_ACCOUNT_BURST" envDefault:"0"`   // AccountBurst is the burst of the calls targeting every account
+}
+
+// LoadConfig fetches the Config from env vars
+func LoadConfig() *Config {
+	config := &Config{}
+	// all env vars are required
+	opts := env.Options{RequiredIfNoDef: true}
+	if err := env.ParseWithOptions(config, opts); err != nil {
+		panic(errors.Wrap(err, "unable to load environment variables"))
+	}
+
+	return config
+}
+
+// Validate checks that the backend is known and that the rates and the bursts are not negative
+func (c *Config) Validate() error {
+	switch {
+	case c.Backend != BackendMemory && c.Backend != BackendPostgres:
+		return errors.Errorf("the rate limit backend (%s) is invalid", c.Backend)
+	case c.GlobalRate < 0 || c.PrincipalRate < 0 || c.AccountRate < 0:
+		return errors.New("the rate limits cannot be negative")
+	case c.GlobalBurst < 0 || c.PrincipalBurst < 0 || c.AccountBurst < 0:
+		return errors.New("the rate limit bursts cannot be negative")
+	}
+	return nil
+}
+
+// Limits returns the enforced limits of the config
+func (c *Config) Limits() []Limit {
+	candidates := []Limit{
+		{Scope: ScopeGlobal, Rate: c.GlobalRate, Burst: c.GlobalBurst},
+		{Scope: ScopePrincipal, Rate: c.PrincipalRate, Burst: c.PrincipalBurst},
+		{Scope: ScopeAccount, Rate: c.AccountRate, Burst: c.AccountBurst},
+	}
+
+	limits := make([]Limit, 0, len(candidates))
+	for _, limit := range candidates {
+		if limit.Rate > 0 {
+			limits = append(limits, limit.withDefaultBurst())
+		}
+	}
+	return limits
+}
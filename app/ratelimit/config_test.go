@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("With default values", func(t *testing.T) {
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.False(t, actual.Enabled)
+		assert.Equal(t, BackendMemory, actual.Backend)
+		assert.Empty(t, actual.Limits())
+	})
+	t.Run("With environment variables set", func(t *testing.T) {
+		assert.NoError(t, os.Setenv("RATE_LIMIT_ENABLED", "true"))
+		assert.NoError(t, os.Setenv("RATE_LIMIT_BACKEND", "postgres"))
+		assert.NoError(t, os.Setenv("RATE_LIMIT_GLOBAL_RATE", "500"))
+		assert.NoError(t, os.Setenv("RATE_LIMIT_GLOBAL_BURST", "1000"))
+		assert.NoError(t, os.Setenv("RATE_LIMIT_PRINCIPAL_RATE", "20"))
+		assert.NoError(t, os.Setenv("RATE_LIMIT_PRINCIPAL_BURST", "40"))
+		assert.NoError(t, os.Setenv("RATE_LIMIT_ACCOUNT_RATE", "0.5"))
+
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.True(t, actual.Enabled)
+		assert.Equal(t, BackendPostgres, actual.Backend)
+		expected := []Limit{
+			{Scope: ScopeGlobal, Rate: 500, Burst: 1000},
+			{Scope: ScopePrincipal, Rate: 20, Burst: 40},
+			{Scope: ScopeAccount, Rate: 0.5, Burst: 1},
+		}
+		assert.Equal(t, expected, actual.Limits())
+
+		// free resources
+		assert.NoError(t, os.Unsetenv("RATE_LIMIT_ENABLED"))
+		assert.NoError(t, os.Unsetenv("RATE_LIMIT_BACKEND"))
+		assert.NoError(t, os.Unsetenv("RATE_LIMIT_GLOBAL_RATE"))
+		assert.NoError(t, os.Unsetenv("RATE_LIMIT_GLOBAL_BURST"))
+		assert.NoError(t, os.Unsetenv("RATE_LIMIT_PRINCIPAL_RATE"))
+		assert.NoError(t, os.Unsetenv("RATE_LIMIT_PRINCIPAL_BURST"))
+		assert.NoError(t, os.Unsetenv("RATE_LIMIT_ACCOUNT_RATE"))
+	})
+	t.Run("With invalid environment variables", func(t *testing.T) {
+		assert.NoError(t, os.Setenv("RATE_LIMIT_ACCOUNT_RATE", "not-a-rate"))
+
+		assert.Panics(t, func() {
+			LoadConfig()
+		})
+
+		// free resources
+		assert.NoError(t, os.Unsetenv("RATE_LIMIT_ACCOUNT_RATE"))
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Run("With valid config", func(t *testing.T) {
+		config := &Config{Backend: BackendMemory, AccountRate: 10}
+		assert.NoError(t, config.Validate())
+	})
+	t.Run("With invalid backend", func(t *testing.T) {
+		config := &Config{Backend: "redis"}
+		assert.EqualError(t, config.Validate(), "the rate limit backend (redis) is invalid")
+	})
+	t.Run("With negative rate", func(t *testing.T) {
+		config := &Config{Backend: BackendMemory, PrincipalRate: -1}
+		assert.EqualError(t, config.Validate(), "the rate limits cannot be negative")
+	})
+	t.Run("With negative burst", func(t *testing.T) {
+		config := &Config{Backend: BackendPostgres, GlobalBurst: -1}
+		assert.EqualError(t, config.Validate(), "the rate limit bursts cannot be negative")
+	})
+}
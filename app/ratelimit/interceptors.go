@@ -0,0 +1,156 @@
+package ratelimit
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"connectrpc.com/connect"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// retryAfterHeader is the header carrying the number of seconds to wait before retrying a throttled call
+const retryAfterHeader = "Retry-After"
+
+// UnaryServerInterceptor returns the gRPC interceptor rate limiting the unary calls to the given services.
+// The throttled calls fail with a resource exhausted status carrying the retry delay
+func (l *Limiter) UnaryServerInterceptor(serviceNames ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !limits(info.FullMethod, serviceNames) {
+			return handler(ctx, req)
+		}
+
+		if err := l.allowGRPC(ctx, info.FullMethod, req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns the gRPC interceptor rate limiting the streaming calls to the given services.
+// A stream is limited when its first request is received
+func (l *Limiter) StreamServerInterceptor(serviceNames ...string) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !limits(info.FullMethod, serviceNames) {
+			return handler(srv, stream)
+		}
+
+		return handler(srv, &limitedStream{ServerStream: stream, limiter: l, method: info.FullMethod})
+	}
+}
+
+// ConnectInterceptor returns the Connect interceptor rate limiting the calls of a Connect handler.
+// The throttled calls fail with a resource exhausted error carrying the retry delay
+func (l *Limiter) ConnectInterceptor() connect.Interceptor {
+	return &connectInterceptor{limiter: l}
+}
+
+// allowGRPC rate limits a gRPC call and sets the retry after header of the throttled ones
+func (l *Limiter) allowGRPC(ctx context.Context, method string, request any) error {
+	err := l.Allow(ctx, method, request)
+	var throttled *ThrottledError
+	if errors.As(err, &throttled) {
+		// the header is best effort, setting it only fails outside of a gRPC call
+		_ = grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(retryAfterHeader), throttled.retryAfterSeconds()))
+	}
+	return err
+}
+
+// allowConnect rate limits a Connect call and converts the throttled ones into Connect errors
+func (l *Limiter) allowConnect(ctx context.Context, procedure string, request any) error {
+	err := l.Allow(ctx, procedure, request)
+	var throttled *ThrottledError
+	if !errors.As(err, &throttled) {
+		return err
+	}
+
+	connectErr := connect.NewError(connect.CodeResourceExhausted, throttled)
+	if detail, detailErr := connect.NewErrorDetail(throttled.retryInfo()); detailErr == nil {
+		connectErr.AddDetail(detail)
+	}
+	connectErr.Meta().Set(retryAfterHeader, throttled.retryAfterSeconds())
+	return connectErr
+}
+
+// limits checks whether a given gRPC method belongs to one of the given services
+func limits(fullMethod string, serviceNames []string) bool {
+	for _, serviceName := range serviceNames {
+		if strings.HasPrefix(fullMethod, "/"+serviceName+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// limitedStream is a gRPC server stream rate limited when its first request is received
+type limitedStream struct {
+	grpc.ServerStream
+	limiter *Limiter
+	method  string
+	once    sync.Once
+}
+
+// RecvMsg receives a request of the stream. The first one is rate limited
+func (s *limitedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	var err error
+	s.once.Do(func() {
+		err = s.limiter.allowGRPC(s.Context(), s.method, m)
+	})
+	return err
+}
+
+// connectInterceptor rate limits the calls of a Connect handler
+type connectInterceptor struct {
+	limiter *Limiter
+}
+
+var _ connect.Interceptor = (*connectInterceptor)(nil)
+
+// WrapUnary rate limits the unary calls
+func (i *connectInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, request connect.AnyRequest) (connect.AnyResponse, error) {
+		if err := i.limiter.allowConnect(ctx, request.Spec().Procedure, request.Any()); err != nil {
+			return nil, err
+		}
+		return next(ctx, request)
+	}
+}
+
+// WrapStreamingClient leaves the client streams unchanged
+func (i *connectInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler rate limits the streaming calls when their first request is received
+func (i *connectInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		return next(ctx, &limitedConn{StreamingHandlerConn: conn, limiter: i.limiter, ctx: ctx})
+	}
+}
+
+// limitedConn is a Connect streaming handler connection rate limited when its first request is received
+type limitedConn struct {
+	connect.StreamingHandlerConn
+	limiter *Limiter
+	ctx     context.Context
+	once    sync.Once
+}
+
+// Receive receives a request of the stream. The first one is rate limited
+func (c *limitedConn) Receive(m any) error {
+	if err := c.StreamingHandlerConn.Receive(m); err != nil {
+		return err
+	}
+
+	var err error
+	c.once.Do(func() {
+		err = c.limiter.allowConnect(c.ctx, c.Spec().Procedure, m)
+	})
+	return err
+}
@@ -0,0 +1,139 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+const (
+	testService   = "accounts.v1.BankAccountService"
+	testProcedure = "/" + testService + "/WatchAccount"
+)
+
+// testServerStream is a gRPC server stream receiving a given request
+type testServerStream struct {
+	grpc.ServerStream
+	request proto.Message
+}
+
+// Context returns the context of the stream
+func (s *testServerStream) Context() context.Context {
+	return context.TODO()
+}
+
+// RecvMsg receives the request of the stream
+func (s *testServerStream) RecvMsg(m any) error {
+	proto.Merge(m.(proto.Message), s.request)
+	return nil
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	limiter, _ := newTestLimiter(t, &Config{AccountRate: 1})
+	interceptor := limiter.UnaryServerInterceptor(testService)
+	info := &grpc.UnaryServerInfo{FullMethod: testMethod}
+
+	handler := func(context.Context, any) (any, error) {
+		return new(pb.DebitAccountResponse), nil
+	}
+
+	t.Run("With call within the limit", func(t *testing.T) {
+		actual, err := interceptor(context.TODO(), &pb.DebitAccountRequest{AccountId: "account-1"}, info, handler)
+		require.NoError(t, err)
+		assert.NotNil(t, actual)
+	})
+	t.Run("With call exceeding the limit", func(t *testing.T) {
+		actual, err := interceptor(context.TODO(), &pb.DebitAccountRequest{AccountId: "account-1"}, info, handler)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Nil(t, actual)
+	})
+	t.Run("With call to other service", func(t *testing.T) {
+		healthInfo := &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}
+		for range 2 {
+			_, err := interceptor(context.TODO(), &pb.DebitAccountRequest{AccountId: "account-1"}, healthInfo, handler)
+			require.NoError(t, err)
+		}
+	})
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	limiter, _ := newTestLimiter(t, &Config{AccountRate: 1})
+	interceptor := limiter.StreamServerInterceptor(testService)
+	info := &grpc.StreamServerInfo{FullMethod: testProcedure, IsServerStream: true}
+
+	// handler receives the request of the stream
+	handler := func(_ any, stream grpc.ServerStream) error {
+		return stream.RecvMsg(new(pb.WatchAccountRequest))
+	}
+
+	t.Run("With call within the limit", func(t *testing.T) {
+		stream := &testServerStream{request: &pb.WatchAccountRequest{AccountId: "account-1"}}
+		require.NoError(t, interceptor(nil, stream, info, handler))
+	})
+	t.Run("With call exceeding the limit", func(t *testing.T) {
+		stream := &testServerStream{request: &pb.WatchAccountRequest{AccountId: "account-1"}}
+		err := interceptor(nil, stream, info, handler)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+}
+
+func TestConnectInterceptor(t *testing.T) {
+	limiter, _ := newTestLimiter(t, &Config{AccountRate: 1})
+	interceptor := connect.WithInterceptors(limiter.ConnectInterceptor())
+
+	mux := http.NewServeMux()
+	mux.Handle(testMethod, connect.NewUnaryHandler(testMethod,
+		func(context.Context, *connect.Request[pb.DebitAccountRequest]) (*connect.Response[pb.DebitAccountResponse], error) {
+			return connect.NewResponse(new(pb.DebitAccountResponse)), nil
+		}, interceptor))
+	mux.Handle(testProcedure, connect.NewServerStreamHandler(testProcedure,
+		func(_ context.Context, _ *connect.Request[pb.WatchAccountRequest], stream *connect.ServerStream[pb.WatchAccountResponse]) error {
+			return stream.Send(new(pb.WatchAccountResponse))
+		}, interceptor))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	unaryClient := connect.NewClient[pb.DebitAccountRequest, pb.DebitAccountResponse](server.Client(), server.URL+testMethod)
+	streamClient := connect.NewClient[pb.WatchAccountRequest, pb.WatchAccountResponse](server.Client(), server.URL+testProcedure)
+
+	t.Run("With unary call", func(t *testing.T) {
+		_, err := unaryClient.CallUnary(context.TODO(), connect.NewRequest(&pb.DebitAccountRequest{AccountId: "account-1"}))
+		require.NoError(t, err)
+
+		_, err = unaryClient.CallUnary(context.TODO(), connect.NewRequest(&pb.DebitAccountRequest{AccountId: "account-1"}))
+		require.Equal(t, connect.CodeResourceExhausted, connect.CodeOf(err))
+
+		// the error carries the retry delay
+		var connectErr *connect.Error
+		require.ErrorAs(t, err, &connectErr)
+		assert.Equal(t, "1", connectErr.Meta().Get("Retry-After"))
+		require.Len(t, connectErr.Details(), 1)
+		detail, err := connectErr.Details()[0].Value()
+		require.NoError(t, err)
+		assert.IsType(t, &errdetails.RetryInfo{}, detail)
+	})
+	t.Run("With streaming call", func(t *testing.T) {
+		stream, err := streamClient.CallServerStream(context.TODO(), connect.NewRequest(&pb.WatchAccountRequest{AccountId: "account-2"}))
+		require.NoError(t, err)
+		require.True(t, stream.Receive())
+		require.NoError(t, stream.Close())
+
+		stream, err = streamClient.CallServerStream(context.TODO(), connect.NewRequest(&pb.WatchAccountRequest{AccountId: "account-2"}))
+		require.NoError(t, err)
+		defer stream.Close()
+		assert.False(t, stream.Receive())
+		assert.Equal(t, connect.CodeResourceExhausted, connect.CodeOf(stream.Err()))
+	})
+}
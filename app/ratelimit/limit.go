@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Scope is the scope of a rate limit
+type Scope string
+
+const (
+	// ScopeGlobal limits all the api calls
+	ScopeGlobal Scope = "global"
+	// ScopePrincipal limits the calls of every authenticated principal
+	ScopePrincipal Scope = "principal"
+	// ScopeAccount limits the calls targeting every account
+	ScopeAccount Scope = "account"
+)
+
+// Limit is a token bucket limit refilled at Rate tokens per second up to Burst tokens. Every call takes a token
+type Limit struct {
+	Scope Scope
+	Rate  float64
+	Burst int
+}
+
+// Backend holds the token buckets of the rate limits
+type Backend interface {
+	// Take takes a token from the bucket of the given key and returns the tokens available before the take.
+	// No token is taken when less than one is available
+	Take(ctx context.Context, key string, limit Limit) (available float64, err error)
+}
+
+// withDefaultBurst returns the limit with its burst set to its rate rounded up when it is not set
+func (l Limit) withDefaultBurst() Limit {
+	if l.Burst == 0 {
+		l.Burst = int(math.Ceil(l.Rate))
+	}
+	return l
+}
+
+// retryAfter returns the time to wait for a token given the tokens available
+func (l Limit) retryAfter(available float64) time.Duration {
+	return time.Duration((1 - available) / l.Rate * float64(time.Second))
+}
+
+// refill returns the tokens of a bucket holding the given tokens once refilled for the given elapsed time
+func (l Limit) refill(tokens float64, elapsed time.Duration) float64 {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(float64(l.Burst), tokens+elapsed.Seconds()*l.Rate)
+}
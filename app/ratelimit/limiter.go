@@ -0,0 +1,155 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/tochemey/cos-go-sample/app/auth"
+	"github.com/tochemey/cos-go-sample/app/log"
+)
+
+const (
+	// meterName is the name of the meter of the rate limiting metrics
+	meterName = "github.com/tochemey/cos-go-sample/app/ratelimit"
+	// throttledMetric is the name of the counter of the throttled calls
+	throttledMetric = "accounts.rate_limit.throttled"
+)
+
+// accountRequest is a request targeting an account
+type accountRequest interface {
+	GetAccountId() string
+}
+
+// ThrottledError is returned for the calls exceeding a rate limit
+type ThrottledError struct {
+	Scope      Scope
+	RetryAfter time.Duration
+}
+
+// Error returns the error message
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("the %s rate limit is exceeded, retry after %s", e.Scope, e.RetryAfter.Round(time.Millisecond))
+}
+
+// GRPCStatus returns the resource exhausted status of the error carrying the retry delay
+func (e *ThrottledError) GRPCStatus() *status.Status {
+	st := status.New(codes.ResourceExhausted, e.Error())
+	if detailed, err := st.WithDetails(e.retryInfo()); err == nil {
+		return detailed
+	}
+	return st
+}
+
+// retryInfo returns the retry delay of the error as a gRPC error detail
+func (e *ThrottledError) retryInfo() *errdetails.RetryInfo {
+	return &errdetails.RetryInfo{RetryDelay: durationpb.New(e.RetryAfter)}
+}
+
+// retryAfterSeconds returns the retry delay of the error rounded up to the second as expected by the Retry-After header
+func (e *ThrottledError) retryAfterSeconds() string {
+	return strconv.Itoa(int(math.Max(1, math.Ceil(e.RetryAfter.Seconds()))))
+}
+
+// Limiter enforces the global, the per-principal and the per-account rate limits of the api calls
+type Limiter struct {
+	backend   Backend
+	limits    []Limit
+	throttled metric.Int64Counter
+}
+
+// NewLimiter creates an instance of Limiter enforcing the limits of the given config with the token buckets of the given backend
+func NewLimiter(config *Config, backend Backend) *Limiter {
+	throttled, err := otel.Meter(meterName).Int64Counter(throttledMetric,
+		metric.WithDescription("The number of api calls rejected by a rate limit"),
+		metric.WithUnit("{call}"))
+	if err != nil {
+		log.Error(errors.Wrap(err, "failed to create the throttled calls counter"))
+		throttled = noop.Int64Counter{}
+	}
+
+	return &Limiter{
+		backend:   backend,
+		limits:    config.Limits(),
+		throttled: throttled,
+	}
+}
+
+// Allow takes a token from every bucket the call of the given method with the given request falls into and returns a ThrottledError
+// when one of them is empty. The principal limit only applies to the authenticated calls and the account limit to the requests targeting an account.
+// The calls are allowed when the backend fails so that the api remains available
+func (l *Limiter) Allow(ctx context.Context, method string, request any) error {
+	for _, limit := range l.limits {
+		key, ok := bucketKey(ctx, limit.Scope, request)
+		if !ok {
+			continue
+		}
+
+		if err := l.take(ctx, method, key, limit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AllowAccount takes a token from the bucket of a given account and returns a ThrottledError when it is empty.
+// It limits the accounts targeted by the entries of a batch call, the call itself being limited by Allow
+func (l *Limiter) AllowAccount(ctx context.Context, method string, accountID string) error {
+	for _, limit := range l.limits {
+		if limit.Scope != ScopeAccount || accountID == "" {
+			continue
+		}
+
+		if err := l.take(ctx, method, string(limit.Scope)+":"+accountID, limit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// take takes a token from the bucket of a given key and returns a ThrottledError when it is empty.
+// The token is granted when the backend fails
+func (l *Limiter) take(ctx context.Context, method string, key string, limit Limit) error {
+	available, err := l.backend.Take(ctx, key, limit)
+	if err != nil {
+		log.WithContext(ctx).Error(errors.Wrapf(err, "failed to check the %s rate limit", limit.Scope))
+		return nil
+	}
+
+	if available < 1 {
+		l.throttled.Add(ctx, 1, metric.WithAttributes(
+			attribute.String("scope", string(limit.Scope)),
+			attribute.String("rpc.method", method)))
+		return &ThrottledError{Scope: limit.Scope, RetryAfter: limit.retryAfter(available)}
+	}
+	return nil
+}
+
+// bucketKey returns the key of the bucket of the given scope a call falls into, if any
+func bucketKey(ctx context.Context, scope Scope, request any) (string, bool) {
+	switch scope {
+	case ScopePrincipal:
+		if principal, ok := auth.FromContext(ctx); ok && principal.Subject != "" {
+			return string(scope) + ":" + principal.Subject, true
+		}
+	case ScopeAccount:
+		if req, ok := request.(accountRequest); ok && req.GetAccountId() != "" {
+			return string(scope) + ":" + req.GetAccountId(), true
+		}
+	default:
+		return string(scope), true
+	}
+	return "", false
+}
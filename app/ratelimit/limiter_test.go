@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/tochemey/cos-go-sample/app/auth"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+const testMethod = "/accounts.v1.BankAccountService/DebitAccount"
+
+// failingBackend is a backend failing to take the tokens
+type failingBackend struct{}
+
+// Take fails
+func (failingBackend) Take(context.Context, string, Limit) (float64, error) {
+	return 0, errors.New("connection refused")
+}
+
+// newTestLimiter creates a limiter with a memory backend whose clock is fixed and the reader of its metrics
+func newTestLimiter(t *testing.T, config *Config) (*Limiter, *sdkmetric.ManualReader) {
+	backend := NewMemoryBackend()
+	now := time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)
+	backend.now = func() time.Time { return now }
+
+	reader := sdkmetric.NewManualReader()
+	throttled, err := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter(meterName).Int64Counter(throttledMetric)
+	require.NoError(t, err)
+
+	limiter := NewLimiter(config, backend)
+	limiter.throttled = throttled
+	return limiter, reader
+}
+
+// throttledCalls returns the throttled calls recorded by the given reader by scope
+func throttledCalls(t *testing.T, reader *sdkmetric.ManualReader) map[string]int64 {
+	var data metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.TODO(), &data))
+
+	calls := make(map[string]int64)
+	for _, scopeMetrics := range data.ScopeMetrics {
+		for _, m := range scopeMetrics.Metrics {
+			if m.Name != throttledMetric {
+				continue
+			}
+			for _, point := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				scope, _ := point.Attributes.Value(attribute.Key("scope"))
+				calls[scope.AsString()] += point.Value
+			}
+		}
+	}
+	return calls
+}
+
+func TestLimiter(t *testing.T) {
+	t.Run("With global limit", func(t *testing.T) {
+		ctx := context.TODO()
+		limiter, reader := newTestLimiter(t, &Config{GlobalRate: 2, GlobalBurst: 2})
+
+		require.NoError(t, limiter.Allow(ctx, testMethod, &pb.DebitAccountRequest{AccountId: "account-1"}))
+		require.NoError(t, limiter.Allow(ctx, testMethod, &pb.DebitAccountRequest{AccountId: "account-2"}))
+
+		err := limiter.Allow(ctx, testMethod, &pb.DebitAccountRequest{AccountId: "account-3"})
+		var throttled *ThrottledError
+		require.True(t, errors.As(err, &throttled))
+		assert.Equal(t, &ThrottledError{Scope: ScopeGlobal, RetryAfter: 500 * time.Millisecond}, throttled)
+		assert.EqualError(t, err, "the global rate limit is exceeded, retry after 500ms")
+		assert.Equal(t, map[string]int64{"global": 1}, throttledCalls(t, reader))
+	})
+	t.Run("With principal limit", func(t *testing.T) {
+		limiter, reader := newTestLimiter(t, &Config{PrincipalRate: 1})
+		customer := auth.NewContext(context.TODO(), &auth.Principal{Subject: "customer-1"})
+		operator := auth.NewContext(context.TODO(), &auth.Principal{Subject: "operator-1"})
+
+		require.NoError(t, limiter.Allow(customer, testMethod, &pb.DebitAccountRequest{AccountId: "account-1"}))
+		assert.Error(t, limiter.Allow(customer, testMethod, &pb.DebitAccountRequest{AccountId: "account-2"}))
+
+		// the other principals and the unauthenticated calls are not limited by the customer calls
+		assert.NoError(t, limiter.Allow(operator, testMethod, &pb.DebitAccountRequest{AccountId: "account-1"}))
+		for range 3 {
+			assert.NoError(t, limiter.Allow(context.TODO(), testMethod, &pb.DebitAccountRequest{AccountId: "account-1"}))
+		}
+		assert.Equal(t, map[string]int64{"principal": 1}, throttledCalls(t, reader))
+	})
+	t.Run("With account limit", func(t *testing.T) {
+		ctx := context.TODO()
+		limiter, reader := newTestLimiter(t, &Config{AccountRate: 0.5, AccountBurst: 1})
+
+		require.NoError(t, limiter.Allow(ctx, testMethod, &pb.DebitAccountRequest{AccountId: "account-1"}))
+
+		err := limiter.Allow(ctx, testMethod, &pb.CreditAccountRequest{AccountId: "account-1"})
+		assert.Equal(t, &ThrottledError{Scope: ScopeAccount, RetryAfter: 2 * time.Second}, err)
+
+		// the other accounts and the requests without an account are not limited by the account calls
+		assert.NoError(t, limiter.Allow(ctx, testMethod, &pb.DebitAccountRequest{AccountId: "account-2"}))
+		assert.NoError(t, limiter.Allow(ctx, testMethod, &pb.BatchGetAccountsRequest{}))
+		assert.Equal(t, map[string]int64{"account": 1}, throttledCalls(t, reader))
+	})
+	t.Run("With account limit of a batch entry", func(t *testing.T) {
+		ctx := context.TODO()
+		limiter, reader := newTestLimiter(t, &Config{GlobalRate: 1, GlobalBurst: 1, AccountRate: 0.5, AccountBurst: 1})
+
+		// the entries only take a token from the bucket of their account
+		require.NoError(t, limiter.AllowAccount(ctx, testMethod, "account-1"))
+		require.NoError(t, limiter.AllowAccount(ctx, testMethod, "account-2"))
+		assert.Equal(t, &ThrottledError{Scope: ScopeAccount, RetryAfter: 2 * time.Second}, limiter.AllowAccount(ctx, testMethod, "account-1"))
+
+		// the account bucket is shared with the calls targeting the account
+		assert.Equal(t, &ThrottledError{Scope: ScopeAccount, RetryAfter: 2 * time.Second}, limiter.Allow(ctx, testMethod, &pb.DebitAccountRequest{AccountId: "account-2"}))
+		assert.Equal(t, map[string]int64{"account": 2}, throttledCalls(t, reader))
+	})
+	t.Run("With backend failure", func(t *testing.T) {
+		limiter := NewLimiter(&Config{GlobalRate: 1, AccountRate: 1}, failingBackend{})
+		for range 3 {
+			assert.NoError(t, limiter.Allow(context.TODO(), testMethod, &pb.DebitAccountRequest{AccountId: "account-1"}))
+		}
+	})
+}
+
+func TestThrottledError(t *testing.T) {
+	err := &ThrottledError{Scope: ScopeAccount, RetryAfter: 1500 * time.Millisecond}
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+	assert.Equal(t, "the account rate limit is exceeded, retry after 1.5s", st.Message())
+	require.Len(t, st.Details(), 1)
+	retryInfo, ok := st.Details()[0].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	assert.Equal(t, 1500*time.Millisecond, retryInfo.GetRetryDelay().AsDuration())
+	assert.Equal(t, "2", err.retryAfterSeconds())
+}
@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is the interval between the removals of the full buckets
+const sweepInterval = time.Minute
+
+// bucket is a token bucket
+type bucket struct {
+	limit     Limit
+	tokens    float64
+	updatedAt time.Time
+}
+
+// MemoryBackend holds the token buckets in memory. The limits are only enforced by the instance
+type MemoryBackend struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// enforce compilation error
+var _ Backend = (*MemoryBackend)(nil)
+
+// NewMemoryBackend creates an instance of MemoryBackend
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+		now:       time.Now,
+	}
+}
+
+// Take takes a token from the bucket of the given key. A missing bucket is created full
+func (b *MemoryBackend) Take(_ context.Context, key string, limit Limit) (float64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.sweep(now)
+
+	current, ok := b.buckets[key]
+	if !ok {
+		current = &bucket{tokens: float64(limit.Burst), updatedAt: now}
+		b.buckets[key] = current
+	}
+
+	available := limit.refill(current.tokens, now.Sub(current.updatedAt))
+	current.limit, current.tokens, current.updatedAt = limit, available, now
+	if available >= 1 {
+		current.tokens--
+	}
+	return available, nil
+}
+
+// sweep removes the buckets refilled to their burst since they are the same as missing ones.
+// It keeps the memory bounded while the keys, such as the accounts, are not
+func (b *MemoryBackend) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < sweepInterval {
+		return
+	}
+	for key, current := range b.buckets {
+		if current.limit.refill(current.tokens, now.Sub(current.updatedAt)) >= float64(current.limit.Burst) {
+			delete(b.buckets, key)
+		}
+	}
+	b.lastSweep = now
+}
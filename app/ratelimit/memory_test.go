@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBackend(t *testing.T) {
+	ctx := context.TODO()
+	limit := Limit{Scope: ScopeAccount, Rate: 1, Burst: 2}
+
+	// newBackend creates a backend with a clock moved forward by the returned function
+	newBackend := func() (*MemoryBackend, func(time.Duration)) {
+		now := time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)
+		backend := NewMemoryBackend()
+		backend.now = func() time.Time { return now }
+		backend.lastSweep = now
+		return backend, func(elapsed time.Duration) { now = now.Add(elapsed) }
+	}
+
+	t.Run("With burst consumed", func(t *testing.T) {
+		backend, _ := newBackend()
+		for _, expected := range []float64{2, 1, 0, 0} {
+			available, err := backend.Take(ctx, "account:account-1", limit)
+			require.NoError(t, err)
+			assert.InDelta(t, expected, available, 1e-9)
+		}
+
+		// the other keys have their own bucket
+		available, err := backend.Take(ctx, "account:account-2", limit)
+		require.NoError(t, err)
+		assert.InDelta(t, 2, available, 1e-9)
+	})
+	t.Run("With bucket refilled", func(t *testing.T) {
+		backend, advance := newBackend()
+		for range 2 {
+			_, err := backend.Take(ctx, "account:account-1", limit)
+			require.NoError(t, err)
+		}
+
+		// half a token is refilled
+		advance(500 * time.Millisecond)
+		available, err := backend.Take(ctx, "account:account-1", limit)
+		require.NoError(t, err)
+		assert.InDelta(t, 0.5, available, 1e-9)
+
+		// the refill is capped by the burst
+		advance(time.Hour)
+		available, err = backend.Take(ctx, "account:account-1", limit)
+		require.NoError(t, err)
+		assert.InDelta(t, 2, available, 1e-9)
+	})
+	t.Run("With full buckets swept", func(t *testing.T) {
+		backend, advance := newBackend()
+		_, err := backend.Take(ctx, "account:account-1", limit)
+		require.NoError(t, err)
+		_, err = backend.Take(ctx, "account:account-2", Limit{Scope: ScopeAccount, Rate: 0.001, Burst: 2})
+		require.NoError(t, err)
+
+		// only the bucket refilled since is removed
+		advance(sweepInterval)
+		_, err = backend.Take(ctx, "global", Limit{Scope: ScopeGlobal, Rate: 1, Burst: 1})
+		require.NoError(t, err)
+		assert.NotContains(t, backend.buckets, "account:account-1")
+		assert.Contains(t, backend.buckets, "account:account-2")
+	})
+}
@@ -0,0 +1,28 @@
+package ratelimit
+
+import (
+	"context"
+)
+
+// TokenStore takes the tokens of the token buckets kept in the database
+type TokenStore interface {
+	TakeRateLimitToken(ctx context.Context, key string, rate float64, burst int) (available float64, err error)
+}
+
+// PostgresBackend holds the token buckets in the database. The limits are shared by the instances using the same database
+type PostgresBackend struct {
+	store TokenStore
+}
+
+// enforce compilation error
+var _ Backend = (*PostgresBackend)(nil)
+
+// NewPostgresBackend creates an instance of PostgresBackend
+func NewPostgresBackend(store TokenStore) *PostgresBackend {
+	return &PostgresBackend{store: store}
+}
+
+// Take takes a token from the bucket of the given key
+func (b *PostgresBackend) Take(ctx context.Context, key string, limit Limit) (float64, error) {
+	return b.store.TakeRateLimitToken(ctx, key, limit.Rate, limit.Burst)
+}
@@ -0,0 +1,45 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// testTokenStore is a token store recording the token taken and returning a given result.
+// The generated mocks of the package cannot be used since they import it
+type testTokenStore struct {
+	key       string
+	rate      float64
+	burst     int
+	available float64
+	err       error
+}
+
+// TakeRateLimitToken records the token taken
+func (s *testTokenStore) TakeRateLimitToken(_ context.Context, key string, rate float64, burst int) (float64, error) {
+	s.key, s.rate, s.burst = key, rate, burst
+	return s.available, s.err
+}
+
+func TestPostgresBackend(t *testing.T) {
+	ctx := context.TODO()
+	limit := Limit{Scope: ScopeAccount, Rate: 5, Burst: 10}
+
+	t.Run("With token taken", func(t *testing.T) {
+		store := &testTokenStore{available: 3.5}
+
+		available, err := NewPostgresBackend(store).Take(ctx, "account:account-1", limit)
+		assert.NoError(t, err)
+		assert.Equal(t, 3.5, available)
+		assert.Equal(t, &testTokenStore{key: "account:account-1", rate: 5, burst: 10, available: 3.5}, store)
+	})
+	t.Run("With store failure", func(t *testing.T) {
+		store := &testTokenStore{err: errors.New("connection refused")}
+
+		_, err := NewPostgresBackend(store).Take(ctx, "account:account-1", limit)
+		assert.EqualError(t, err, "connection refused")
+	})
+}
@@ -0,0 +1,26 @@
+package readmodel
+
+import (
+	"time"
+
+	"github.com/caarlos0/env/v9"
+	"github.com/pkg/errors"
+)
+
+// Config defines the read model reader config
+type Config struct {
+	WaitTimeout  time.Duration `env:"READ_MODEL_WAIT_TIMEOUT" envDefault:"500ms"` // WaitTimeout is how long a read waits for the read model to catch up with a given revision
+	PollInterval time.Duration `env:"READ_MODEL_POLL_INTERVAL" envDefault:"50ms"` // PollInterval is the interval between two lookups of the read model while waiting
+}
+
+// LoadConfig fetches the Config from env vars
+func LoadConfig() *Config {
+	config := &Config{}
+	// all env vars are required
+	opts := env.Options{RequiredIfNoDef: true}
+	if err := env.ParseWithOptions(config, opts); err != nil {
+		panic(errors.Wrap(err, "unable to load environment variables"))
+	}
+
+	return config
+}
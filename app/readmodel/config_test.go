@@ -0,0 +1,26 @@
+package readmodel
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("With default values", func(t *testing.T) {
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.Equal(t, 500*time.Millisecond, actual.WaitTimeout)
+		assert.Equal(t, 50*time.Millisecond, actual.PollInterval)
+	})
+	t.Run("With wait timeout set", func(t *testing.T) {
+		assert.NoError(t, os.Setenv("READ_MODEL_WAIT_TIMEOUT", "2s"))
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.Equal(t, 2*time.Second, actual.WaitTimeout)
+		assert.NoError(t, os.Unsetenv("READ_MODEL_WAIT_TIMEOUT"))
+	})
+}
@@ -0,0 +1,199 @@
+package readmodel
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tochemey/gopack/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/tochemey/cos-go-sample/app/storage"
+	"github.com/tochemey/cos-go-sample/app/writeside/events"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
+)
+
+// Reader reads the accounts from the read model
+type Reader struct {
+	dataStore  storage.Storage
+	config     *Config
+	dispatcher events.Dispatcher
+}
+
+// NewReader creates an instance of Reader
+func NewReader(dataStore storage.Storage, config *Config) *Reader {
+	return &Reader{
+		dataStore:  dataStore,
+		config:     config,
+		dispatcher: events.NewDispatcher(),
+	}
+}
+
+// GetAccount fetches a given account of a given tenant from the read model. Nil is returned when the account is not found
+func (r *Reader) GetAccount(ctx context.Context, tenantID, accountID string) (*storage.AccountRecord, error) {
+	// set the observability span
+	ctx, span := trace.SpanContext(ctx, "ReadAccount")
+	defer span.End()
+
+	return r.getAccount(ctx, tenantID, accountID)
+}
+
+// GetAccounts fetches the given accounts of a given tenant from the read model in the order of the account ids.
+// Nil is returned in place of the accounts not found
+func (r *Reader) GetAccounts(ctx context.Context, tenantID string, accountIDs []string) ([]*storage.AccountRecord, error) {
+	// set the observability span
+	ctx, span := trace.SpanContext(ctx, "ReadAccounts")
+	defer span.End()
+
+	accounts, err := r.dataStore.GetAccounts(ctx, tenantID, accountIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch the accounts from the read model")
+	}
+	return accounts, nil
+}
+
+// WaitForAccount fetches a given account of a given tenant from the read model once it has caught up with the given revision.
+// Nil is returned when the read model is still behind the revision after the wait timeout
+func (r *Reader) WaitForAccount(ctx context.Context, tenantID, accountID string, minRevision int32) (*storage.AccountRecord, error) {
+	// set the observability span
+	ctx, span := trace.SpanContext(ctx, "WaitForAccount")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, r.config.WaitTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		record, err := r.getAccount(ctx, tenantID, accountID)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			return nil, err
+		case record != nil && record.Revision.Number >= minRevision:
+			return record, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			// the read model did not catch up in time
+			return nil, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// GetAccountAt rebuilds a given account of a given tenant at the given revision or, when the revision is not set, as of the given time.
+// The account is rebuilt by folding the events of the ledger with the events dispatcher of the write side, hence the
+// historical view matches the write side semantics. The errors returned are gRPC status errors
+func (r *Reader) GetAccountAt(ctx context.Context, tenantID, accountID string, revision int32, asOf time.Time) (*pb.BankAccount, *cospb.MetaData, error) {
+	// set the observability span
+	ctx, span := trace.SpanContext(ctx, "GetAccountAt")
+	defer span.End()
+
+	// the revision takes precedence over the time
+	if revision > 0 {
+		asOf = time.Time{}
+	}
+
+	ledger, err := r.dataStore.GetAccountEvents(ctx, tenantID, accountID, revision, asOf)
+	if err != nil {
+		return nil, nil, status.Error(codes.Internal, errors.Wrap(err, "failed to fetch the account events").Error())
+	}
+
+	// the account did not exist at that point in time
+	if len(ledger) == 0 {
+		return nil, nil, status.Errorf(codes.NotFound, "the account:(%s) is not found at the requested point in time", accountID)
+	}
+
+	// the ledger has not reached the requested revision yet
+	if revision > 0 && ledger[len(ledger)-1].RevisionNumber < revision {
+		return nil, nil, status.Errorf(codes.OutOfRange, "the account:(%s) has no revision %d", accountID, revision)
+	}
+
+	var (
+		state = new(pb.BankAccount)
+		meta  *cospb.MetaData
+	)
+	for index, accountEvent := range ledger {
+		// the events persisted before the ledger was kept are missing
+		if accountEvent.RevisionNumber != int32(index+1) { // #nosec G115
+			return nil, nil, status.Errorf(codes.FailedPrecondition, "the history of the account:(%s) is incomplete", accountID)
+		}
+
+		event, err := accountEvent.Event.UnmarshalNew()
+		if err != nil {
+			return nil, nil, status.Error(codes.Internal, errors.Wrapf(err, "failed to unpack event:(%s)", accountEvent.Event.GetTypeUrl()).Error())
+		}
+
+		meta = &cospb.MetaData{
+			EntityId:       accountID,
+			RevisionNumber: accountEvent.RevisionNumber,
+			RevisionDate:   timestamppb.New(accountEvent.RevisionDate),
+		}
+		if state, err = r.dispatcher.Dispatch(ctx, event, state, meta); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return state, meta, nil
+}
+
+// GetAuditEntries fetches at most limit entries of the audit trail of a given account or standing order of a given tenant
+// with a revision greater than the given revision. The entries are ordered by revision
+func (r *Reader) GetAuditEntries(ctx context.Context, tenantID, entityID string, afterRevision int32, limit int) ([]*pb.AuditEntry, error) {
+	// set the observability span
+	ctx, span := trace.SpanContext(ctx, "ReadAuditEntries")
+	defer span.End()
+
+	records, err := r.dataStore.GetAuditEntries(ctx, tenantID, entityID, afterRevision, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch the audit entries from the read model")
+	}
+
+	entries := make([]*pb.AuditEntry, 0, len(records))
+	for _, record := range records {
+		entry := &pb.AuditEntry{
+			EntityId:       record.EntityID,
+			RevisionNumber: record.RevisionNumber,
+			RevisionDate:   timestamppb.New(record.RevisionDate),
+			EventType:      record.EventType,
+		}
+		// the events recorded without caller have no caller
+		if record.CallerSubject != "" || record.ClientIP != "" || record.RequestID != "" {
+			entry.Caller = &pb.Caller{Subject: record.CallerSubject, ClientIp: record.ClientIP, RequestId: record.RequestID}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// EraseAccountOwner scrubs a given owner from its accounts of a given tenant in the read model and returns the ids of the scrubbed accounts
+func (r *Reader) EraseAccountOwner(ctx context.Context, tenantID, accountOwner string) ([]string, error) {
+	// set the observability span
+	ctx, span := trace.SpanContext(ctx, "EraseAccountOwner")
+	defer span.End()
+
+	accountIDs, err := r.dataStore.EraseAccountOwner(ctx, tenantID, accountOwner)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to erase the account owner from the read model")
+	}
+	return accountIDs, nil
+}
+
+// getAccount fetches a given account of a given tenant from the read model
+func (r *Reader) getAccount(ctx context.Context, tenantID, accountID string) (*storage.AccountRecord, error) {
+	accounts, err := r.dataStore.GetAccounts(ctx, tenantID, []string{accountID})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch the account from the read model")
+	}
+
+	// the account is not found
+	if len(accounts) == 0 {
+		return nil, nil
+	}
+	return accounts[0], nil
+}
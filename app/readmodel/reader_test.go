@@ -0,0 +1,273 @@
+package readmodel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/tochemey/cos-go-sample/app/storage"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	mocks "github.com/tochemey/cos-go-sample/mocks/app/storage"
+)
+
+func TestGetAccount(t *testing.T) {
+	config := &Config{WaitTimeout: 100 * time.Millisecond, PollInterval: 10 * time.Millisecond}
+	t.Run("With happy path", func(t *testing.T) {
+		record := &storage.AccountRecord{Account: &pb.BankAccount{AccountId: "account-1"}, Revision: storage.Revision{Number: 2}}
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "acme", []string{"account-1"}).Return([]*storage.AccountRecord{record}, nil)
+
+		actual, err := NewReader(dataStore, config).GetAccount(context.TODO(), "acme", "account-1")
+		require.NoError(t, err)
+		assert.Equal(t, record, actual)
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With account not found", func(t *testing.T) {
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "acme", []string{"account-1"}).Return([]*storage.AccountRecord{nil}, nil)
+
+		actual, err := NewReader(dataStore, config).GetAccount(context.TODO(), "acme", "account-1")
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
+	t.Run("With data store failure", func(t *testing.T) {
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "acme", []string{"account-1"}).Return(nil, errors.New("failed"))
+
+		actual, err := NewReader(dataStore, config).GetAccount(context.TODO(), "acme", "account-1")
+		assert.EqualError(t, err, "failed to fetch the account from the read model: failed")
+		assert.Nil(t, actual)
+	})
+}
+
+func TestGetAccounts(t *testing.T) {
+	config := &Config{WaitTimeout: 100 * time.Millisecond, PollInterval: 10 * time.Millisecond}
+	t.Run("With happy path", func(t *testing.T) {
+		records := []*storage.AccountRecord{
+			{Account: &pb.BankAccount{AccountId: "account-1"}, Revision: storage.Revision{Number: 2}},
+			nil,
+		}
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "acme", []string{"account-1", "account-2"}).Return(records, nil)
+
+		actual, err := NewReader(dataStore, config).GetAccounts(context.TODO(), "acme", []string{"account-1", "account-2"})
+		require.NoError(t, err)
+		assert.Equal(t, records, actual)
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With data store failure", func(t *testing.T) {
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "acme", []string{"account-1"}).Return(nil, errors.New("failed"))
+
+		actual, err := NewReader(dataStore, config).GetAccounts(context.TODO(), "acme", []string{"account-1"})
+		assert.EqualError(t, err, "failed to fetch the accounts from the read model: failed")
+		assert.Nil(t, actual)
+	})
+}
+
+func TestWaitForAccount(t *testing.T) {
+	config := &Config{WaitTimeout: 100 * time.Millisecond, PollInterval: 10 * time.Millisecond}
+	t.Run("With read model caught up", func(t *testing.T) {
+		record := &storage.AccountRecord{Account: &pb.BankAccount{AccountId: "account-1"}, Revision: storage.Revision{Number: 3}}
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "acme", []string{"account-1"}).Return([]*storage.AccountRecord{record}, nil).Once()
+
+		actual, err := NewReader(dataStore, config).WaitForAccount(context.TODO(), "acme", "account-1", 2)
+		require.NoError(t, err)
+		assert.Equal(t, record, actual)
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With read model catching up", func(t *testing.T) {
+		behind := &storage.AccountRecord{Account: &pb.BankAccount{AccountId: "account-1"}, Revision: storage.Revision{Number: 1}}
+		caughtUp := &storage.AccountRecord{Account: &pb.BankAccount{AccountId: "account-1"}, Revision: storage.Revision{Number: 2}}
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "acme", []string{"account-1"}).Return([]*storage.AccountRecord{nil}, nil).Once()
+		dataStore.On("GetAccounts", mock.Anything, "acme", []string{"account-1"}).Return([]*storage.AccountRecord{behind}, nil).Once()
+		dataStore.On("GetAccounts", mock.Anything, "acme", []string{"account-1"}).Return([]*storage.AccountRecord{caughtUp}, nil).Once()
+
+		actual, err := NewReader(dataStore, config).WaitForAccount(context.TODO(), "acme", "account-1", 2)
+		require.NoError(t, err)
+		assert.Equal(t, caughtUp, actual)
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With read model behind", func(t *testing.T) {
+		behind := &storage.AccountRecord{Account: &pb.BankAccount{AccountId: "account-1"}, Revision: storage.Revision{Number: 1}}
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "acme", []string{"account-1"}).Return([]*storage.AccountRecord{behind}, nil)
+
+		actual, err := NewReader(dataStore, config).WaitForAccount(context.TODO(), "acme", "account-1", 2)
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
+	t.Run("With data store failure", func(t *testing.T) {
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "acme", []string{"account-1"}).Return(nil, errors.New("failed"))
+
+		actual, err := NewReader(dataStore, config).WaitForAccount(context.TODO(), "acme", "account-1", 2)
+		assert.EqualError(t, err, "failed to fetch the account from the read model: failed")
+		assert.Nil(t, actual)
+	})
+}
+
+func TestGetAccountAt(t *testing.T) {
+	config := &Config{WaitTimeout: 100 * time.Millisecond, PollInterval: 10 * time.Millisecond}
+	startTime := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	// newLedger builds the ledger of the given events starting at the given revision, one event per day
+	newLedger := func(t *testing.T, revision int32, events ...proto.Message) []*storage.AccountEvent {
+		ledger := make([]*storage.AccountEvent, 0, len(events))
+		for index, event := range events {
+			anyEvent, err := anypb.New(event)
+			require.NoError(t, err)
+			ledger = append(ledger, &storage.AccountEvent{
+				AccountID:      "account-1",
+				RevisionNumber: revision + int32(index), // #nosec G115
+				RevisionDate:   startTime.AddDate(0, 0, index),
+				Event:          anyEvent,
+			})
+		}
+		return ledger
+	}
+
+	t.Run("With revision", func(t *testing.T) {
+		ledger := newLedger(t, 1,
+			&pb.AccountOpened{AccountId: "account-1", AccountOwner: "John Doe", Balance: 100, AccountType: pb.AccountType_CHECKING},
+			&pb.AccountCredited{AccountId: "account-1", Amount: 50},
+		)
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccountEvents", mock.Anything, "acme", "account-1", int32(2), time.Time{}).Return(ledger, nil)
+
+		// the time is ignored when the revision is set
+		account, meta, err := NewReader(dataStore, config).GetAccountAt(context.TODO(), "acme", "account-1", 2, startTime)
+		require.NoError(t, err)
+		assert.Equal(t, "account-1", account.GetAccountId())
+		assert.EqualValues(t, 150, account.GetAccountBalance())
+		assert.EqualValues(t, 2, meta.GetRevisionNumber())
+		assert.True(t, startTime.AddDate(0, 0, 1).Equal(meta.GetRevisionDate().AsTime()))
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With time", func(t *testing.T) {
+		ledger := newLedger(t, 1, &pb.AccountOpened{AccountId: "account-1", AccountOwner: "John Doe", Balance: 100})
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccountEvents", mock.Anything, "acme", "account-1", int32(0), startTime).Return(ledger, nil)
+
+		account, meta, err := NewReader(dataStore, config).GetAccountAt(context.TODO(), "acme", "account-1", 0, startTime)
+		require.NoError(t, err)
+		assert.EqualValues(t, 100, account.GetAccountBalance())
+		assert.EqualValues(t, 1, meta.GetRevisionNumber())
+	})
+	t.Run("With account not found", func(t *testing.T) {
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccountEvents", mock.Anything, "acme", "account-1", int32(0), startTime).Return([]*storage.AccountEvent{}, nil)
+
+		account, meta, err := NewReader(dataStore, config).GetAccountAt(context.TODO(), "acme", "account-1", 0, startTime)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Nil(t, account)
+		assert.Nil(t, meta)
+	})
+	t.Run("With revision not reached", func(t *testing.T) {
+		ledger := newLedger(t, 1, &pb.AccountOpened{AccountId: "account-1", Balance: 100})
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccountEvents", mock.Anything, "acme", "account-1", int32(3), time.Time{}).Return(ledger, nil)
+
+		account, _, err := NewReader(dataStore, config).GetAccountAt(context.TODO(), "acme", "account-1", 3, time.Time{})
+		assert.EqualError(t, err, "rpc error: code = OutOfRange desc = the account:(account-1) has no revision 3")
+		assert.Nil(t, account)
+	})
+	t.Run("With incomplete history", func(t *testing.T) {
+		// the events preceding the ledger are missing
+		ledger := newLedger(t, 4, &pb.AccountCredited{AccountId: "account-1", Amount: 50})
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccountEvents", mock.Anything, "acme", "account-1", int32(4), time.Time{}).Return(ledger, nil)
+
+		account, _, err := NewReader(dataStore, config).GetAccountAt(context.TODO(), "acme", "account-1", 4, time.Time{})
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = the history of the account:(account-1) is incomplete")
+		assert.Nil(t, account)
+	})
+	t.Run("With data store failure", func(t *testing.T) {
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccountEvents", mock.Anything, "acme", "account-1", int32(1), time.Time{}).Return(nil, errors.New("failed"))
+
+		account, _, err := NewReader(dataStore, config).GetAccountAt(context.TODO(), "acme", "account-1", 1, time.Time{})
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.Nil(t, account)
+	})
+}
+
+func TestGetAuditEntries(t *testing.T) {
+	config := &Config{WaitTimeout: 100 * time.Millisecond, PollInterval: 10 * time.Millisecond}
+	revisionDate := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	t.Run("With happy path", func(t *testing.T) {
+		records := []*storage.AuditEntry{
+			{
+				EntityID:       "account-1",
+				RevisionNumber: 1,
+				RevisionDate:   revisionDate,
+				EventType:      "accounts.v1.AccountOpened",
+				CallerSubject:  "customer-1",
+				ClientIP:       "10.0.0.1",
+				RequestID:      "request-1",
+			},
+			{EntityID: "account-1", RevisionNumber: 2, RevisionDate: revisionDate, EventType: "accounts.v1.AccountCredited"},
+		}
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAuditEntries", mock.Anything, "acme", "account-1", int32(0), 10).Return(records, nil)
+
+		actual, err := NewReader(dataStore, config).GetAuditEntries(context.TODO(), "acme", "account-1", 0, 10)
+		require.NoError(t, err)
+		expected := []*pb.AuditEntry{
+			{
+				EntityId:       "account-1",
+				RevisionNumber: 1,
+				RevisionDate:   timestamppb.New(revisionDate),
+				EventType:      "accounts.v1.AccountOpened",
+				Caller:         &pb.Caller{Subject: "customer-1", ClientIp: "10.0.0.1", RequestId: "request-1"},
+			},
+			{EntityId: "account-1", RevisionNumber: 2, RevisionDate: timestamppb.New(revisionDate), EventType: "accounts.v1.AccountCredited"},
+		}
+		require.Len(t, actual, len(expected))
+		for index := range expected {
+			assert.True(t, proto.Equal(expected[index], actual[index]))
+		}
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With data store failure", func(t *testing.T) {
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAuditEntries", mock.Anything, "acme", "account-1", int32(0), 10).Return(nil, errors.New("failed"))
+
+		actual, err := NewReader(dataStore, config).GetAuditEntries(context.TODO(), "acme", "account-1", 0, 10)
+		assert.EqualError(t, err, "failed to fetch the audit entries from the read model: failed")
+		assert.Nil(t, actual)
+	})
+}
+
+func TestEraseAccountOwner(t *testing.T) {
+	config := &Config{WaitTimeout: 100 * time.Millisecond, PollInterval: 10 * time.Millisecond}
+	t.Run("With happy path", func(t *testing.T) {
+		dataStore := new(mocks.Storage)
+		dataStore.On("EraseAccountOwner", mock.Anything, "acme", "John Doe").Return([]string{"account-1", "account-3"}, nil)
+
+		actual, err := NewReader(dataStore, config).EraseAccountOwner(context.TODO(), "acme", "John Doe")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"account-1", "account-3"}, actual)
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With data store failure", func(t *testing.T) {
+		dataStore := new(mocks.Storage)
+		dataStore.On("EraseAccountOwner", mock.Anything, "acme", "John Doe").Return(nil, errors.New("failed"))
+
+		actual, err := NewReader(dataStore, config).EraseAccountOwner(context.TODO(), "acme", "John Doe")
+		assert.EqualError(t, err, "failed to erase the account owner from the read model: failed")
+		assert.Nil(t, actual)
+	})
+}
@@ -0,0 +1,33 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/caarlos0/env/v9"
+	"github.com/pkg/errors"
+
+	"github.com/tochemey/cos-go-sample/app/grpconfig"
+)
+
+// Config defines the scheduler config
+type Config struct {
+	CosHost      string                    `env:"COS_HOST"`                                // CosHost is used to connect to ChiefOfState
+	CosPort      int                       `env:"COS_PORT"`                                // CosPort is used to connect to ChiefOfState
+	PollInterval time.Duration             `env:"SCHEDULER_POLL_INTERVAL" envDefault:"1m"` // PollInterval is the interval between two lookups of the due standing orders
+	BatchSize    int                       `env:"SCHEDULER_BATCH_SIZE" envDefault:"100"`   // BatchSize is the maximum number of standing orders executed per lookup
+	MaxAttempts  int                       `env:"SCHEDULER_MAX_ATTEMPTS" envDefault:"3"`   // MaxAttempts is the number of attempts of an execution before it is skipped
+	RetryBackoff time.Duration             `env:"SCHEDULER_RETRY_BACKOFF" envDefault:"1h"` // RetryBackoff is the delay before the first retry. It doubles on every failed attempt
+	CosTLS       grpconfig.ClientTLSConfig // CosTLS is used to secure the connections to ChiefOfState
+}
+
+// LoadConfig fetches the Config from env vars
+func LoadConfig() *Config {
+	config := &Config{}
+	// all env vars are required
+	opts := env.Options{RequiredIfNoDef: true}
+	if err := env.ParseWithOptions(config, opts); err != nil {
+		panic(errors.Wrap(err, "unable to load environment variables"))
+	}
+
+	return config
+}
@@ -0,0 +1,191 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tochemey/gopack/otel/trace"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/tochemey/cos-go-sample/app/cos"
+	"github.com/tochemey/cos-go-sample/app/log"
+	"github.com/tochemey/cos-go-sample/app/storage"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// Scheduler executes the due standing orders.
+// Every execution debits the account and credits the beneficiary account with idempotency keys
+// derived from the execution date, hence an execution retried after a partial failure is never applied twice.
+// The debit of a failed execution is recorded with the standing order, so that it is not repeated by the retries
+// and is paid back when the execution is skipped
+type Scheduler struct {
+	dataStore storage.Storage
+	cosClient cos.Client
+	config    *Config
+	now       func() time.Time
+}
+
+// New creates a new instance of Scheduler
+func New(dataStore storage.Storage, cosClient cos.Client, config *Config) (*Scheduler, error) {
+	// check whether the data store is defined or not
+	if dataStore == nil {
+		return nil, errors.New("the dataStore is not defined")
+	}
+
+	// check whether the cos client is defined or not
+	if cosClient == nil {
+		return nil, errors.New("the cosClient is not defined")
+	}
+
+	// check whether the config is defined or not
+	if config == nil {
+		return nil, errors.New("the config is not defined")
+	}
+
+	// return the new instance of Scheduler
+	return &Scheduler{
+		dataStore: dataStore,
+		cosClient: cosClient,
+		config:    config,
+		now:       time.Now,
+	}, nil
+}
+
+// Run executes the due standing orders at every poll interval until the given context is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	// get the context logger
+	logger := log.WithContext(ctx)
+
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// execute the due standing orders
+		if err := s.RunOnce(ctx); err != nil {
+			logger.Error(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce executes the standing orders due at the time of the call
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+	// set the observability span
+	ctx, span := trace.SpanContext(ctx, "RunOnce")
+	defer span.End()
+
+	// get the context logger
+	logger := log.WithContext(ctx)
+
+	// fetch the due standing orders
+	standingOrders, err := s.dataStore.GetDueStandingOrders(ctx, s.now(), s.config.BatchSize)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch the due standing orders")
+	}
+
+	// execute the standing orders one after the other
+	for _, standingOrder := range standingOrders {
+		// stop when the scheduler is shutting down
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err := s.execute(ctx, standingOrder); err != nil {
+			logger.Error(errors.Wrapf(err, "failed to record the standing order:(%s) execution", standingOrder.GetOrderId()))
+		}
+	}
+
+	return nil
+}
+
+// execute transfers the standing order amount and records the outcome of the execution
+func (s *Scheduler) execute(ctx context.Context, standingOrder *pb.StandingOrder) error {
+	// get the context logger
+	logger := log.WithContext(ctx)
+
+	executionDate := standingOrder.GetNextExecutionDate()
+	command := &pb.RecordStandingOrderExecution{
+		OrderId:       standingOrder.GetOrderId(),
+		ExecutionDate: executionDate,
+	}
+
+	// transfer the amount and record the failure
+	if debited, err := s.transfer(ctx, standingOrder); err != nil {
+		logger.Warnf("the standing order:(%s) execution of (%s) failed: %v", standingOrder.GetOrderId(), executionDate.AsTime(), err)
+		command.FailureReason = err.Error()
+
+		// retry the execution with an exponential backoff
+		if attempt := int(standingOrder.GetFailedAttempts()) + 1; attempt < s.config.MaxAttempts {
+			backoff := s.config.RetryBackoff * time.Duration(1<<(attempt-1))
+			command.NextAttemptAt = timestamppb.New(s.now().Add(backoff))
+			command.Debited = debited
+		} else if debited {
+			// the execution is skipped, hence the debited amount is paid back
+			if err := s.reverse(ctx, standingOrder); err != nil {
+				return errors.Wrap(err, "failed to reverse the debit")
+			}
+		}
+	}
+
+	// record the execution
+	_, _, err := s.cosClient.ProcessStandingOrderCommand(ctx, standingOrder.GetOrderId(), command)
+	return err
+}
+
+// transfer debits the account, unless it has been debited by a previous attempt, and credits the beneficiary account, if any.
+// It returns whether the account has been debited for the execution, also when the credit failed.
+// The transfers are initiated by the system, hence they do not reactivate dormant accounts
+func (s *Scheduler) transfer(ctx context.Context, standingOrder *pb.StandingOrder) (debited bool, err error) {
+	// debit the account
+	if !standingOrder.GetDebited() {
+		debit := &pb.DebitAccount{
+			AccountId:       standingOrder.GetAccountId(),
+			Amount:          standingOrder.GetAmount(),
+			IdempotencyKey:  idempotencyKey(standingOrder, "debit"),
+			SystemInitiated: true,
+		}
+		if _, _, err := s.cosClient.ProcessCommand(ctx, debit.GetAccountId(), debit); err != nil {
+			return false, err
+		}
+	}
+
+	// a direct debit has no beneficiary account to credit
+	if standingOrder.GetBeneficiaryAccountId() == "" {
+		return true, nil
+	}
+
+	// credit the beneficiary account
+	credit := &pb.CreditAccount{
+		AccountId:       standingOrder.GetBeneficiaryAccountId(),
+		Amount:          standingOrder.GetAmount(),
+		IdempotencyKey:  idempotencyKey(standingOrder, "credit"),
+		SystemInitiated: true,
+	}
+	_, _, err = s.cosClient.ProcessCommand(ctx, credit.GetAccountId(), credit)
+	return true, err
+}
+
+// reverse credits the account back for the execution it has been debited for
+func (s *Scheduler) reverse(ctx context.Context, standingOrder *pb.StandingOrder) error {
+	credit := &pb.CreditAccount{
+		AccountId:       standingOrder.GetAccountId(),
+		Amount:          standingOrder.GetAmount(),
+		IdempotencyKey:  idempotencyKey(standingOrder, "reversal"),
+		SystemInitiated: true,
+	}
+	_, _, err := s.cosClient.ProcessCommand(ctx, credit.GetAccountId(), credit)
+	return err
+}
+
+// idempotencyKey returns the idempotency key of the given standing order execution step
+func idempotencyKey(standingOrder *pb.StandingOrder, step string) string {
+	executionDate := standingOrder.GetNextExecutionDate().AsTime().UTC().Format(time.DateOnly)
+	return fmt.Sprintf("%s/%s/%s", standingOrder.GetOrderId(), executionDate, step)
+}
@@ -0,0 +1,248 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	cosmocks "github.com/tochemey/cos-go-sample/mocks/app/cos"
+	mocks "github.com/tochemey/cos-go-sample/mocks/app/storage"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("With happy path", func(t *testing.T) {
+		scheduler, err := New(new(mocks.Storage), new(cosmocks.Client), &Config{})
+		assert.NoError(t, err)
+		assert.NotNil(t, scheduler)
+	})
+	t.Run("With data store not set", func(t *testing.T) {
+		scheduler, err := New(nil, new(cosmocks.Client), &Config{})
+		assert.EqualError(t, err, "the dataStore is not defined")
+		assert.Nil(t, scheduler)
+	})
+	t.Run("With cos client not set", func(t *testing.T) {
+		scheduler, err := New(new(mocks.Storage), nil, &Config{})
+		assert.EqualError(t, err, "the cosClient is not defined")
+		assert.Nil(t, scheduler)
+	})
+	t.Run("With config not set", func(t *testing.T) {
+		scheduler, err := New(new(mocks.Storage), new(cosmocks.Client), nil)
+		assert.EqualError(t, err, "the config is not defined")
+		assert.Nil(t, scheduler)
+	})
+}
+
+func TestRunOnce(t *testing.T) {
+	now := time.Date(2024, time.January, 10, 8, 0, 0, 0, time.UTC)
+	executionDate := timestamppb.New(time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC))
+	config := &Config{BatchSize: 10, MaxAttempts: 3, RetryBackoff: time.Hour}
+
+	// newStandingOrder creates a due standing order with the given failed attempts
+	newStandingOrder := func(failedAttempts int32) *pb.StandingOrder {
+		return &pb.StandingOrder{
+			OrderId:              "order-1",
+			AccountId:            "account-1",
+			BeneficiaryAccountId: "account-2",
+			Amount:               100,
+			Frequency:            pb.Frequency_MONTHLY,
+			Status:               pb.StandingOrderStatus_ACTIVE,
+			NextExecutionDate:    executionDate,
+			FailedAttempts:       failedAttempts,
+		}
+	}
+
+	// newScheduler creates a scheduler with a fixed clock
+	newScheduler := func(t *testing.T, dataStore *mocks.Storage, cosClient *cosmocks.Client) *Scheduler {
+		scheduler, err := New(dataStore, cosClient, config)
+		require.NoError(t, err)
+		scheduler.now = func() time.Time { return now }
+		return scheduler
+	}
+
+	debit := &pb.DebitAccount{AccountId: "account-1", Amount: 100, IdempotencyKey: "order-1/2024-01-10/debit", SystemInitiated: true}
+	credit := &pb.CreditAccount{AccountId: "account-2", Amount: 100, IdempotencyKey: "order-1/2024-01-10/credit", SystemInitiated: true}
+	reversal := &pb.CreditAccount{AccountId: "account-1", Amount: 100, IdempotencyKey: "order-1/2024-01-10/reversal", SystemInitiated: true}
+
+	// matches returns a matcher of the given proto message
+	matches := func(expected proto.Message) any {
+		return mock.MatchedBy(func(actual proto.Message) bool {
+			return proto.Equal(expected, actual)
+		})
+	}
+
+	t.Run("With successful execution", func(t *testing.T) {
+		ctx := context.TODO()
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetDueStandingOrders", mock.Anything, now, 10).Return([]*pb.StandingOrder{newStandingOrder(0)}, nil)
+		cosClient := new(cosmocks.Client)
+		cosClient.On("ProcessCommand", mock.Anything, "account-1", matches(debit)).Return(new(pb.BankAccount), nil, nil)
+		cosClient.On("ProcessCommand", mock.Anything, "account-2", matches(credit)).Return(new(pb.BankAccount), nil, nil)
+		cosClient.On("ProcessStandingOrderCommand", mock.Anything, "order-1", matches(&pb.RecordStandingOrderExecution{
+			OrderId:       "order-1",
+			ExecutionDate: executionDate,
+		})).Return(new(pb.StandingOrder), nil, nil)
+
+		require.NoError(t, newScheduler(t, dataStore, cosClient).RunOnce(ctx))
+		dataStore.AssertExpectations(t)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With successful direct debit execution", func(t *testing.T) {
+		ctx := context.TODO()
+		directDebit := newStandingOrder(0)
+		directDebit.BeneficiaryAccountId = ""
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetDueStandingOrders", mock.Anything, now, 10).Return([]*pb.StandingOrder{directDebit}, nil)
+		cosClient := new(cosmocks.Client)
+		cosClient.On("ProcessCommand", mock.Anything, "account-1", matches(debit)).Return(new(pb.BankAccount), nil, nil)
+		cosClient.On("ProcessStandingOrderCommand", mock.Anything, "order-1", matches(&pb.RecordStandingOrderExecution{
+			OrderId:       "order-1",
+			ExecutionDate: executionDate,
+		})).Return(new(pb.StandingOrder), nil, nil)
+
+		require.NoError(t, newScheduler(t, dataStore, cosClient).RunOnce(ctx))
+		dataStore.AssertExpectations(t)
+		cosClient.AssertExpectations(t)
+		// only the account is debited
+		cosClient.AssertNumberOfCalls(t, "ProcessCommand", 1)
+	})
+	t.Run("With failed execution retried", func(t *testing.T) {
+		ctx := context.TODO()
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetDueStandingOrders", mock.Anything, now, 10).Return([]*pb.StandingOrder{newStandingOrder(1)}, nil)
+		cosClient := new(cosmocks.Client)
+		cosClient.On("ProcessCommand", mock.Anything, "account-1", matches(debit)).Return(nil, nil, errors.New("insufficient balance"))
+		cosClient.On("ProcessStandingOrderCommand", mock.Anything, "order-1", matches(&pb.RecordStandingOrderExecution{
+			OrderId:       "order-1",
+			ExecutionDate: executionDate,
+			FailureReason: "insufficient balance",
+			NextAttemptAt: timestamppb.New(now.Add(2 * time.Hour)),
+		})).Return(new(pb.StandingOrder), nil, nil)
+
+		require.NoError(t, newScheduler(t, dataStore, cosClient).RunOnce(ctx))
+		dataStore.AssertExpectations(t)
+		cosClient.AssertExpectations(t)
+		cosClient.AssertNotCalled(t, "GetState", mock.Anything, mock.Anything)
+	})
+	t.Run("With failed execution skipped after the debit", func(t *testing.T) {
+		ctx := context.TODO()
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetDueStandingOrders", mock.Anything, now, 10).Return([]*pb.StandingOrder{newStandingOrder(2)}, nil)
+		cosClient := new(cosmocks.Client)
+		cosClient.On("ProcessCommand", mock.Anything, "account-1", matches(debit)).Return(new(pb.BankAccount), nil, nil)
+		cosClient.On("ProcessCommand", mock.Anything, "account-2", matches(credit)).Return(nil, nil, errors.New("the account is closed"))
+		cosClient.On("ProcessCommand", mock.Anything, "account-1", matches(reversal)).Return(new(pb.BankAccount), nil, nil)
+		cosClient.On("ProcessStandingOrderCommand", mock.Anything, "order-1", matches(&pb.RecordStandingOrderExecution{
+			OrderId:       "order-1",
+			ExecutionDate: executionDate,
+			FailureReason: "the account is closed",
+		})).Return(new(pb.StandingOrder), nil, nil)
+
+		require.NoError(t, newScheduler(t, dataStore, cosClient).RunOnce(ctx))
+		dataStore.AssertExpectations(t)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With failed execution skipped before the debit", func(t *testing.T) {
+		ctx := context.TODO()
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetDueStandingOrders", mock.Anything, now, 10).Return([]*pb.StandingOrder{newStandingOrder(2)}, nil)
+		cosClient := new(cosmocks.Client)
+		cosClient.On("ProcessCommand", mock.Anything, "account-1", matches(debit)).Return(nil, nil, errors.New("insufficient balance"))
+		cosClient.On("ProcessStandingOrderCommand", mock.Anything, "order-1", matches(&pb.RecordStandingOrderExecution{
+			OrderId:       "order-1",
+			ExecutionDate: executionDate,
+			FailureReason: "insufficient balance",
+		})).Return(new(pb.StandingOrder), nil, nil)
+
+		require.NoError(t, newScheduler(t, dataStore, cosClient).RunOnce(ctx))
+		dataStore.AssertExpectations(t)
+		cosClient.AssertExpectations(t)
+		cosClient.AssertNotCalled(t, "ProcessCommand", mock.Anything, "account-1", matches(reversal))
+	})
+	t.Run("With failed execution retried after the debit", func(t *testing.T) {
+		ctx := context.TODO()
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetDueStandingOrders", mock.Anything, now, 10).Return([]*pb.StandingOrder{newStandingOrder(0)}, nil)
+		cosClient := new(cosmocks.Client)
+		cosClient.On("ProcessCommand", mock.Anything, "account-1", matches(debit)).Return(new(pb.BankAccount), nil, nil)
+		cosClient.On("ProcessCommand", mock.Anything, "account-2", matches(credit)).Return(nil, nil, errors.New("unavailable"))
+		// the debit is recorded with the failure
+		cosClient.On("ProcessStandingOrderCommand", mock.Anything, "order-1", matches(&pb.RecordStandingOrderExecution{
+			OrderId:       "order-1",
+			ExecutionDate: executionDate,
+			FailureReason: "unavailable",
+			NextAttemptAt: timestamppb.New(now.Add(time.Hour)),
+			Debited:       true,
+		})).Return(new(pb.StandingOrder), nil, nil)
+
+		require.NoError(t, newScheduler(t, dataStore, cosClient).RunOnce(ctx))
+		dataStore.AssertExpectations(t)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With debited execution retried", func(t *testing.T) {
+		ctx := context.TODO()
+		standingOrder := newStandingOrder(1)
+		standingOrder.Debited = true
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetDueStandingOrders", mock.Anything, now, 10).Return([]*pb.StandingOrder{standingOrder}, nil)
+		cosClient := new(cosmocks.Client)
+		cosClient.On("ProcessCommand", mock.Anything, "account-2", matches(credit)).Return(new(pb.BankAccount), nil, nil)
+		cosClient.On("ProcessStandingOrderCommand", mock.Anything, "order-1", matches(&pb.RecordStandingOrderExecution{
+			OrderId:       "order-1",
+			ExecutionDate: executionDate,
+		})).Return(new(pb.StandingOrder), nil, nil)
+
+		require.NoError(t, newScheduler(t, dataStore, cosClient).RunOnce(ctx))
+		dataStore.AssertExpectations(t)
+		cosClient.AssertExpectations(t)
+		// the account is not debited again
+		cosClient.AssertNumberOfCalls(t, "ProcessCommand", 1)
+	})
+	t.Run("With debited execution skipped", func(t *testing.T) {
+		ctx := context.TODO()
+		standingOrder := newStandingOrder(2)
+		standingOrder.Debited = true
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetDueStandingOrders", mock.Anything, now, 10).Return([]*pb.StandingOrder{standingOrder}, nil)
+		cosClient := new(cosmocks.Client)
+		cosClient.On("ProcessCommand", mock.Anything, "account-2", matches(credit)).Return(nil, nil, errors.New("the account is closed"))
+		// the debit of a previous attempt is paid back, whatever the idempotency keys still kept by the account
+		cosClient.On("ProcessCommand", mock.Anything, "account-1", matches(reversal)).Return(new(pb.BankAccount), nil, nil)
+		cosClient.On("ProcessStandingOrderCommand", mock.Anything, "order-1", matches(&pb.RecordStandingOrderExecution{
+			OrderId:       "order-1",
+			ExecutionDate: executionDate,
+			FailureReason: "the account is closed",
+		})).Return(new(pb.StandingOrder), nil, nil)
+
+		require.NoError(t, newScheduler(t, dataStore, cosClient).RunOnce(ctx))
+		dataStore.AssertExpectations(t)
+		cosClient.AssertExpectations(t)
+		cosClient.AssertNotCalled(t, "ProcessCommand", mock.Anything, "account-1", matches(debit))
+		cosClient.AssertNotCalled(t, "GetState", mock.Anything, mock.Anything)
+	})
+	t.Run("With data store failure", func(t *testing.T) {
+		ctx := context.TODO()
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetDueStandingOrders", mock.Anything, now, 10).Return(nil, errors.New("failed"))
+		cosClient := new(cosmocks.Client)
+
+		err := newScheduler(t, dataStore, cosClient).RunOnce(ctx)
+		assert.EqualError(t, err, "failed to fetch the due standing orders: failed")
+		dataStore.AssertExpectations(t)
+	})
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	standingOrder := &pb.StandingOrder{
+		OrderId:           "order-1",
+		NextExecutionDate: timestamppb.New(time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)),
+	}
+	assert.Equal(t, "order-1/2024-02-29/debit", idempotencyKey(standingOrder, "debit"))
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"bytes"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// statementChunkSize is the maximum size of a statement chunk streamed back to the caller
+const statementChunkSize = 32 * 1024
+
+// chunkWriter streams the written bytes back to the caller in chunks of statementChunkSize.
+// Flush must be called to send the remaining bytes
+type chunkWriter struct {
+	stream pb.BankAccountService_GenerateStatementServer
+	buffer []byte
+}
+
+// newChunkWriter creates an instance of chunkWriter
+func newChunkWriter(stream pb.BankAccountService_GenerateStatementServer) *chunkWriter {
+	return &chunkWriter{stream: stream}
+}
+
+// Write buffers the given bytes and sends every complete chunk
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	w.buffer = append(w.buffer, p...)
+	for len(w.buffer) >= statementChunkSize {
+		if err := w.send(w.buffer[:statementChunkSize]); err != nil {
+			return 0, err
+		}
+		w.buffer = w.buffer[statementChunkSize:]
+	}
+	return len(p), nil
+}
+
+// Flush sends the buffered bytes
+func (w *chunkWriter) Flush() error {
+	if len(w.buffer) == 0 {
+		return nil
+	}
+
+	err := w.send(w.buffer)
+	w.buffer = nil
+	return err
+}
+
+// send streams the given chunk back to the caller
+func (w *chunkWriter) send(chunk []byte) error {
+	return w.stream.Send(&pb.GenerateStatementResponse{Chunk: bytes.Clone(chunk)})
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	pbmocks "github.com/tochemey/cos-go-sample/mocks/gen/accounts/v1"
+)
+
+func TestChunkWriter(t *testing.T) {
+	t.Run("With payload larger than a chunk", func(t *testing.T) {
+		// create a mock stream collecting the chunks
+		var chunks [][]byte
+		stream := new(pbmocks.BankAccountService_GenerateStatementServer[pb.GenerateStatementResponse])
+		stream.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+			chunks = append(chunks, args.Get(0).(*pb.GenerateStatementResponse).GetChunk())
+		}).Return(nil)
+
+		payload := bytes.Repeat([]byte("a"), 2*statementChunkSize+10)
+		writer := newChunkWriter(stream)
+		written, err := writer.Write(payload)
+		require.NoError(t, err)
+		assert.Equal(t, len(payload), written)
+		require.Len(t, chunks, 2)
+
+		// flush the remaining bytes
+		require.NoError(t, writer.Flush())
+		require.Len(t, chunks, 3)
+		assert.Len(t, chunks[2], 10)
+		assert.Equal(t, payload, bytes.Join(chunks, nil))
+
+		// nothing is left to flush
+		require.NoError(t, writer.Flush())
+		assert.Len(t, chunks, 3)
+	})
+	t.Run("With stream failure", func(t *testing.T) {
+		stream := new(pbmocks.BankAccountService_GenerateStatementServer[pb.GenerateStatementResponse])
+		stream.On("Send", mock.Anything).Return(errors.New("failed"))
+
+		writer := newChunkWriter(stream)
+		_, err := writer.Write(bytes.Repeat([]byte("a"), statementChunkSize))
+		assert.EqualError(t, err, "failed")
+	})
+}
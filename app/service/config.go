@@ -9,9 +9,11 @@ import (
 
 // Config defines the application config
 type Config struct {
-	CosHost    string           `env:"COS_HOST"` // CosHost is used to connect to ChiefOfState
-	CosPort    int              `env:"COS_PORT"` // CosPort is used to connect to ChiefOfState
-	GRPCConfig grpconfig.Config // GRPCConfig is used to spawn gRPC service
+	CosHost         string                    `env:"COS_HOST"`                          // CosHost is used to connect to ChiefOfState
+	CosPort         int                       `env:"COS_PORT"`                          // CosPort is used to connect to ChiefOfState
+	WatchBufferSize int                       `env:"WATCH_BUFFER_SIZE" envDefault:"64"` // WatchBufferSize is the number of changes buffered per account watcher before it is evicted
+	GRPCConfig      grpconfig.Config          // GRPCConfig is used to spawn gRPC service
+	CosTLS          grpconfig.ClientTLSConfig // CosTLS is used to secure the connections to ChiefOfState
 }
 
 // LoadConfig fetches the Config from env vars
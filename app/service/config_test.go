@@ -20,8 +20,9 @@ func TestGetConfig(t *testing.T) {
 
 		// let us defined the expected value
 		expected := &Config{
-			CosHost: "localhost",
-			CosPort: 9000,
+			CosHost:         "localhost",
+			CosPort:         9000,
+			WatchBufferSize: 64,
 			GRPCConfig: grpconfig.Config{
 				ServiceName:      "accounts",
 				GrpcPort:         50051,
@@ -30,6 +31,9 @@ func TestGetConfig(t *testing.T) {
 				EnableReflection: false,
 				MetricsEnabled:   false,
 				MetricsPort:      9102,
+				TLS: grpconfig.TLSConfig{
+					ClientAuth: grpconfig.ClientAuthRequire,
+				},
 			},
 		}
 
@@ -2,29 +2,80 @@ package service
 
 import (
 	"context"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/tochemey/gopack/log/zapl"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/tochemey/cos-go-sample/app/auth"
 	"github.com/tochemey/cos-go-sample/app/cos"
 	"github.com/tochemey/cos-go-sample/app/log"
+	"github.com/tochemey/cos-go-sample/app/pii"
+	"github.com/tochemey/cos-go-sample/app/ratelimit"
+	"github.com/tochemey/cos-go-sample/app/readmodel"
+	"github.com/tochemey/cos-go-sample/app/statement"
+	"github.com/tochemey/cos-go-sample/app/storage"
+	"github.com/tochemey/cos-go-sample/app/subscription"
+	"github.com/tochemey/cos-go-sample/app/tenant"
+	"github.com/tochemey/cos-go-sample/app/validation"
 	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
 )
 
+const (
+	// MaxBatchSize is the maximum number of accounts fetched or commands posted by a single batch request
+	MaxBatchSize = 500
+	// BulkPostParallelism is the maximum number of commands of a bulk post request executed concurrently
+	BulkPostParallelism = 16
+	// DefaultAuditPageSize is the number of audit entries returned per page when the page size is not set
+	DefaultAuditPageSize = 50
+	// MaxAuditPageSize is the maximum number of audit entries returned per page
+	MaxAuditPageSize = 500
+)
+
 // Service implements the application service interface
 type Service struct {
-	cosClient cos.Client
+	cosClient          cos.Client
+	statementGenerator *statement.Generator
+	accountReader      *readmodel.Reader
+	subscriber         subscription.Subscriber
+	authorizer         *auth.Authorizer
+	cipher             *pii.Cipher
+	tenants            *tenant.Resolver
+	limiter            *ratelimit.Limiter
+	validator          *validation.Validator
 }
 
 // enforce compilation error when Service does not implement fully the
 // BankAccountServiceServer interface
 var _ pb.BankAccountServiceServer = &Service{}
 
-// NewService creates an instance of api
-func NewService(cosClient cos.Client) *Service {
+// NewService creates an instance of api. The eventually consistent reads go through the given account reader
+// and the accounts changes are watched through the given subscriber. The requests are authorized by the given authorizer
+// against the principal of their context. Every request is allowed when the authorizer is not set.
+// The personal data of the customers is encrypted by the given cipher and left in clear when the cipher is not set.
+// The requests are isolated by the tenant resolved by the given resolver and the service is single tenant when the resolver is not set.
+// The calls themselves are rate limited by the interceptors while the accounts targeted by the entries of the bulk posts
+// are rate limited by the given limiter, when set. Likewise the calls are validated by the interceptors while the entries
+// of the bulk posts are validated by the given validator, and are not validated when it is nil
+func NewService(cosClient cos.Client, statementGenerator *statement.Generator, accountReader *readmodel.Reader, subscriber subscription.Subscriber, authorizer *auth.Authorizer, cipher *pii.Cipher, tenants *tenant.Resolver, limiter *ratelimit.Limiter, validator *validation.Validator) *Service {
 	return &Service{
 		cosClient,
+		statementGenerator,
+		accountReader,
+		subscriber,
+		authorizer,
+		cipher,
+		tenants,
+		limiter,
+		validator,
 	}
 }
 
@@ -34,28 +85,55 @@ func (s *Service) OpenAccount(ctx context.Context, request *pb.OpenAccountReques
 	// get context log
 	log := log.WithContext(ctx)
 
+	// resolve the tenant of the request
+	tenantID, err := s.tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// check the caller is allowed to open an account for the owner
+	if err := s.authorize(ctx, auth.PermissionOpen, auth.OwnedBy(request.GetAccountOwner())); err != nil {
+		return nil, err
+	}
+
 	// let us generate the account id or use it
 	accountID := request.GetAccountId()
 	if accountID == "" {
 		accountID = uuid.NewString()
 	}
+	accountID = tenant.EntityID(tenantID, accountID)
+
+	// the owner is encrypted with its own data key so that it can be erased from the events
+	accountOwner, err := s.encryptOwner(ctx, tenantID, request.GetAccountOwner())
+	if err != nil {
+		log.Error(err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
 
 	// let us create the command to send to CoS
 	command := &pb.OpenAccount{
 		AccountId:      accountID,
-		AccountOwner:   request.GetAccountOwner(),
+		AccountOwner:   accountOwner,
 		OpeningBalance: request.GetBalance(),
+		AccountType:    request.GetAccountType(),
+		TenantId:       tenantID,
 	}
 
 	// send the command to CoS
-	state, _, err := s.cosClient.ProcessCommand(ctx, accountID, command)
+	state, meta, err := s.cosClient.ProcessCommand(ctx, accountID, command)
 	// handle the error
 	if err != nil {
 		log.Error(err)
 		return nil, err
 	}
 
-	return &pb.OpenAccountResponse{Account: state}, nil
+	// decrypt the personal data of the account
+	account, err := s.decryptAccount(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.OpenAccountResponse{Account: localAccount(account), RevisionNumber: meta.GetRevisionNumber(), RevisionDate: meta.GetRevisionDate()}, nil
 }
 
 // DebitAccount sends a debit account request to the service. When the request is successful the debited account with the new balance is returned in the response.
@@ -64,21 +142,40 @@ func (s *Service) DebitAccount(ctx context.Context, request *pb.DebitAccountRequ
 	// get context log
 	log := log.WithContext(ctx)
 
+	// resolve the tenant of the request
+	tenantID, err := s.tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accountID := tenant.EntityID(tenantID, request.GetAccountId())
+
+	// check the caller is allowed to debit the account
+	if err := s.authorize(ctx, auth.PermissionDebit, s.accountOwner(ctx, accountID)); err != nil {
+		return nil, err
+	}
+
 	// create the debit command
 	command := &pb.DebitAccount{
-		AccountId: request.GetAccountId(),
-		Amount:    request.GetAmount(),
+		AccountId:        accountID,
+		Amount:           request.GetAmount(),
+		ExpectedRevision: request.ExpectedRevision,
 	}
 
 	// send the request to CoS
-	state, _, err := s.cosClient.ProcessCommand(ctx, request.GetAccountId(), command)
+	state, meta, err := s.cosClient.ProcessCommand(ctx, accountID, command)
 	// handle the error
 	if err != nil {
 		log.Error(err)
 		return nil, err
 	}
 
-	return &pb.DebitAccountResponse{Account: state}, nil
+	// decrypt the personal data of the account
+	account, err := s.decryptAccount(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DebitAccountResponse{Account: localAccount(account), RevisionNumber: meta.GetRevisionNumber(), RevisionDate: meta.GetRevisionDate()}, nil
 }
 
 // CreditAccount sends a credit account request to the service. When the request is successful the newly credited account with the new balance is returned in the response.
@@ -87,37 +184,950 @@ func (s *Service) CreditAccount(ctx context.Context, request *pb.CreditAccountRe
 	// get context log
 	log := log.WithContext(ctx)
 
+	// resolve the tenant of the request
+	tenantID, err := s.tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accountID := tenant.EntityID(tenantID, request.GetAccountId())
+
+	// check the caller is allowed to credit the account
+	if err := s.authorize(ctx, auth.PermissionCredit, s.accountOwner(ctx, accountID)); err != nil {
+		return nil, err
+	}
+
 	// create the command to send to CoS
 	command := &pb.CreditAccount{
-		AccountId: request.GetAccountId(),
-		Amount:    request.GetAmount(),
+		AccountId:        accountID,
+		Amount:           request.GetAmount(),
+		ExpectedRevision: request.ExpectedRevision,
 	}
 
 	// send the command to CoS
-	state, _, err := s.cosClient.ProcessCommand(ctx, request.GetAccountId(), command)
+	state, meta, err := s.cosClient.ProcessCommand(ctx, accountID, command)
 	// handle the error
 	if err != nil {
 		log.Error(err)
 		return nil, err
 	}
 
-	return &pb.CreditAccountResponse{Account: state}, nil
+	// decrypt the personal data of the account
+	account, err := s.decryptAccount(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CreditAccountResponse{Account: localAccount(account), RevisionNumber: meta.GetRevisionNumber(), RevisionDate: meta.GetRevisionDate()}, nil
 }
 
 // GetAccount returns a given account information. When the request is successful the account info is returned in the response.
+// The account is read from CoS or from the read model according to the consistency of the request.
 // In case of error a gRPC error is returned. For more information refer to https://www.grpc.io/docs/guides/error/
 func (s *Service) GetAccount(ctx context.Context, request *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
 	// get context log
 	log := zapl.WithContext(ctx)
+
+	// resolve the tenant of the request
+	tenantID, err := s.tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accountID := tenant.EntityID(tenantID, request.GetAccountId())
+
+	// the reads of the read model fall back to CoS when there is no read model
+	consistency := request.GetConsistency()
+	if s.accountReader == nil {
+		consistency = pb.Consistency_STRONG
+	}
+
+	switch consistency {
+	case pb.Consistency_EVENTUAL:
+		record, err := s.accountReader.GetAccount(ctx, tenantID, accountID)
+		// handle the error
+		if err != nil {
+			log.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		// the account is not found in the read model
+		if record == nil {
+			return nil, status.Errorf(codes.NotFound, "the account:(%s) is not found", request.GetAccountId())
+		}
+		return s.authorizeAccountResponse(ctx, toGetAccountResponse(record))
+	case pb.Consistency_AT_LEAST_REVISION:
+		// the minimum revision is required
+		if request.GetMinRevision() <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "the minimum revision is not set")
+		}
+
+		record, err := s.accountReader.WaitForAccount(ctx, tenantID, accountID, request.GetMinRevision())
+		// handle the error
+		if err != nil {
+			log.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		// the read model has caught up with the revision
+		if record != nil {
+			return s.authorizeAccountResponse(ctx, toGetAccountResponse(record))
+		}
+	}
+
 	// let us get the current state from CoS. At this stage it makes sense to fetch the current state
-	state, _, err := s.cosClient.GetState(ctx, request.GetAccountId())
+	state, meta, err := s.cosClient.GetState(ctx, accountID)
 	// handle the error
 	if err != nil {
 		log.Error(err)
 		return nil, err
 	}
 
-	return &pb.GetAccountResponse{Account: state}, nil
+	// decrypt the personal data of the account
+	account, err := s.decryptAccount(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.authorizeAccountResponse(ctx, &pb.GetAccountResponse{Account: localAccount(account), RevisionNumber: meta.GetRevisionNumber(), RevisionDate: meta.GetRevisionDate()})
+}
+
+// authorizeAccountResponse returns a given get account response once the caller is allowed to read its account
+func (s *Service) authorizeAccountResponse(ctx context.Context, response *pb.GetAccountResponse) (*pb.GetAccountResponse, error) {
+	if err := s.authorize(ctx, auth.PermissionRead, auth.OwnedBy(response.GetAccount().GetAccountOwner())); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GetAccountAt returns a given account as it was at a given time or revision. The account is rebuilt from the events ledger of the read side.
+// In case of error a gRPC error is returned. For more information refer to https://www.grpc.io/docs/guides/error/
+func (s *Service) GetAccountAt(ctx context.Context, request *pb.GetAccountAtRequest) (*pb.GetAccountAtResponse, error) {
+	// get context log
+	log := log.WithContext(ctx)
+
+	// validate the request
+	if request.GetAccountId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "the account id is not set")
+	}
+
+	var (
+		revision int32
+		asOf     time.Time
+	)
+	switch point := request.GetPoint().(type) {
+	case *pb.GetAccountAtRequest_Revision:
+		if point.Revision <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "the revision is invalid")
+		}
+		revision = point.Revision
+	case *pb.GetAccountAtRequest_AsOfTime:
+		if point.AsOfTime == nil {
+			return nil, status.Error(codes.InvalidArgument, "the point in time is not set")
+		}
+		asOf = point.AsOfTime.AsTime()
+	default:
+		return nil, status.Error(codes.InvalidArgument, "the point in time is not set")
+	}
+
+	if s.accountReader == nil {
+		return nil, status.Error(codes.Unimplemented, "reading the accounts history is not enabled")
+	}
+
+	// resolve the tenant of the request
+	tenantID, err := s.tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accountID := tenant.EntityID(tenantID, request.GetAccountId())
+
+	// check the caller is allowed to read the account
+	if err := s.authorize(ctx, auth.PermissionRead, s.accountOwner(ctx, accountID)); err != nil {
+		return nil, err
+	}
+
+	state, meta, err := s.accountReader.GetAccountAt(ctx, tenantID, accountID, revision, asOf)
+	// handle the error
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	// decrypt the personal data of the account
+	account, err := s.decryptAccount(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetAccountAtResponse{Account: localAccount(account), RevisionNumber: meta.GetRevisionNumber(), RevisionDate: meta.GetRevisionDate()}, nil
+}
+
+// toGetAccountResponse builds the get account response of a given record of the read model
+func toGetAccountResponse(record *storage.AccountRecord) *pb.GetAccountResponse {
+	response := &pb.GetAccountResponse{Account: localAccount(record.GetAccount()), RevisionNumber: record.Revision.Number}
+	if !record.Revision.Date.IsZero() {
+		response.RevisionDate = timestamppb.New(record.Revision.Date)
+	}
+	return response
+}
+
+// WaiveFee refunds previously charged fees to a given account. When the request is successful the account with the new balance is returned in the response.
+// In case of error a gRPC error is returned. For more information refer to https://www.grpc.io/docs/guides/error/
+func (s *Service) WaiveFee(ctx context.Context, request *pb.WaiveFeeRequest) (*pb.WaiveFeeResponse, error) {
+	// get context log
+	log := log.WithContext(ctx)
+
+	// resolve the tenant of the request
+	tenantID, err := s.tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accountID := tenant.EntityID(tenantID, request.GetAccountId())
+
+	// check the caller is allowed to waive the account fees
+	if err := s.authorize(ctx, auth.PermissionWaiveFee, s.accountOwner(ctx, accountID)); err != nil {
+		return nil, err
+	}
+
+	// create the command to send to CoS
+	command := &pb.WaiveFee{
+		AccountId: accountID,
+		Amount:    request.GetAmount(),
+		Reason:    request.GetReason(),
+	}
+
+	// send the command to CoS
+	state, meta, err := s.cosClient.ProcessCommand(ctx, accountID, command)
+	// handle the error
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	// decrypt the personal data of the account
+	account, err := s.decryptAccount(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.WaiveFeeResponse{Account: localAccount(account), RevisionNumber: meta.GetRevisionNumber(), RevisionDate: meta.GetRevisionDate()}, nil
+}
+
+// CreateStandingOrder schedules recurring transfers or direct debits from a given account. When the request is successful the newly created standing order is returned in the response.
+// In case of error a gRPC error is returned. For more information refer to https://www.grpc.io/docs/guides/error/
+func (s *Service) CreateStandingOrder(ctx context.Context, request *pb.CreateStandingOrderRequest) (*pb.CreateStandingOrderResponse, error) {
+	// get context log
+	log := log.WithContext(ctx)
+
+	// resolve the tenant of the request
+	tenantID, err := s.tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accountID := tenant.EntityID(tenantID, request.GetAccountId())
+
+	// check the caller is allowed to manage the standing orders of the account
+	if err := s.authorize(ctx, auth.PermissionManageStandingOrders, s.accountOwner(ctx, accountID)); err != nil {
+		return nil, err
+	}
+
+	// let us generate the standing order id or use it
+	orderID := request.GetOrderId()
+	if orderID == "" {
+		orderID = uuid.NewString()
+	}
+	orderID = tenant.EntityID(tenantID, orderID)
+
+	// let us create the command to send to CoS
+	command := &pb.CreateStandingOrder{
+		OrderId:              orderID,
+		AccountId:            accountID,
+		BeneficiaryAccountId: tenant.EntityID(tenantID, request.GetBeneficiaryAccountId()),
+		Amount:               request.GetAmount(),
+		Frequency:            request.GetFrequency(),
+		StartDate:            request.GetStartDate(),
+		EndDate:              request.GetEndDate(),
+		Reference:            request.GetReference(),
+		TenantId:             tenantID,
+	}
+
+	// send the command to CoS
+	standingOrder, meta, err := s.cosClient.ProcessStandingOrderCommand(ctx, orderID, command)
+	// handle the error
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	return &pb.CreateStandingOrderResponse{StandingOrder: localStandingOrder(standingOrder), RevisionNumber: meta.GetRevisionNumber(), RevisionDate: meta.GetRevisionDate()}, nil
+}
+
+// PauseStandingOrder suspends the executions of a given standing order. When the request is successful the paused standing order is returned in the response.
+// In case of error a gRPC error is returned. For more information refer to https://www.grpc.io/docs/guides/error/
+func (s *Service) PauseStandingOrder(ctx context.Context, request *pb.PauseStandingOrderRequest) (*pb.PauseStandingOrderResponse, error) {
+	// get context log
+	log := log.WithContext(ctx)
+
+	// resolve the tenant of the request
+	tenantID, err := s.tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	orderID := tenant.EntityID(tenantID, request.GetOrderId())
+
+	// check the caller is allowed to manage the standing order
+	if err := s.authorize(ctx, auth.PermissionManageStandingOrders, s.standingOrderOwner(ctx, orderID)); err != nil {
+		return nil, err
+	}
+
+	// send the command to CoS
+	command := &pb.PauseStandingOrder{OrderId: orderID}
+	standingOrder, meta, err := s.cosClient.ProcessStandingOrderCommand(ctx, orderID, command)
+	// handle the error
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	return &pb.PauseStandingOrderResponse{StandingOrder: localStandingOrder(standingOrder), RevisionNumber: meta.GetRevisionNumber(), RevisionDate: meta.GetRevisionDate()}, nil
+}
+
+// ResumeStandingOrder resumes the executions of a given paused standing order. When the request is successful the resumed standing order is returned in the response.
+// In case of error a gRPC error is returned. For more information refer to https://www.grpc.io/docs/guides/error/
+func (s *Service) ResumeStandingOrder(ctx context.Context, request *pb.ResumeStandingOrderRequest) (*pb.ResumeStandingOrderResponse, error) {
+	// get context log
+	log := log.WithContext(ctx)
+
+	// resolve the tenant of the request
+	tenantID, err := s.tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	orderID := tenant.EntityID(tenantID, request.GetOrderId())
+
+	// check the caller is allowed to manage the standing order
+	if err := s.authorize(ctx, auth.PermissionManageStandingOrders, s.standingOrderOwner(ctx, orderID)); err != nil {
+		return nil, err
+	}
+
+	// send the command to CoS
+	command := &pb.ResumeStandingOrder{OrderId: orderID}
+	standingOrder, meta, err := s.cosClient.ProcessStandingOrderCommand(ctx, orderID, command)
+	// handle the error
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	return &pb.ResumeStandingOrderResponse{StandingOrder: localStandingOrder(standingOrder), RevisionNumber: meta.GetRevisionNumber(), RevisionDate: meta.GetRevisionDate()}, nil
+}
+
+// CancelStandingOrder definitely stops the executions of a given standing order. When the request is successful the cancelled standing order is returned in the response.
+// In case of error a gRPC error is returned. For more information refer to https://www.grpc.io/docs/guides/error/
+func (s *Service) CancelStandingOrder(ctx context.Context, request *pb.CancelStandingOrderRequest) (*pb.CancelStandingOrderResponse, error) {
+	// get context log
+	log := log.WithContext(ctx)
+
+	// resolve the tenant of the request
+	tenantID, err := s.tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	orderID := tenant.EntityID(tenantID, request.GetOrderId())
+
+	// check the caller is allowed to manage the standing order
+	if err := s.authorize(ctx, auth.PermissionManageStandingOrders, s.standingOrderOwner(ctx, orderID)); err != nil {
+		return nil, err
+	}
+
+	// send the command to CoS
+	command := &pb.CancelStandingOrder{OrderId: orderID}
+	standingOrder, meta, err := s.cosClient.ProcessStandingOrderCommand(ctx, orderID, command)
+	// handle the error
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	return &pb.CancelStandingOrderResponse{StandingOrder: localStandingOrder(standingOrder), RevisionNumber: meta.GetRevisionNumber(), RevisionDate: meta.GetRevisionDate()}, nil
+}
+
+// GetStandingOrder returns a given standing order with its schedule. When the request is successful the standing order is returned in the response.
+// In case of error a gRPC error is returned. For more information refer to https://www.grpc.io/docs/guides/error/
+func (s *Service) GetStandingOrder(ctx context.Context, request *pb.GetStandingOrderRequest) (*pb.GetStandingOrderResponse, error) {
+	// get context log
+	log := log.WithContext(ctx)
+
+	// resolve the tenant of the request
+	tenantID, err := s.tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// let us get the current state from CoS
+	standingOrder, meta, err := s.cosClient.GetStandingOrder(ctx, tenant.EntityID(tenantID, request.GetOrderId()))
+	// handle the error
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	// the standing order is not found
+	if standingOrder == nil {
+		return nil, status.Errorf(codes.NotFound, "the standing order:(%s) is not found", request.GetOrderId())
+	}
+
+	// check the caller is allowed to read the account of the standing order
+	if err := s.authorize(ctx, auth.PermissionRead, s.accountOwner(ctx, standingOrder.GetAccountId())); err != nil {
+		return nil, err
+	}
+
+	return &pb.GetStandingOrderResponse{StandingOrder: localStandingOrder(standingOrder), RevisionNumber: meta.GetRevisionNumber(), RevisionDate: meta.GetRevisionDate()}, nil
+}
+
+// GenerateStatement builds the statement of a given account for a given period and streams it back rendered in the requested format.
+// In case of error a gRPC error is returned. For more information refer to https://www.grpc.io/docs/guides/error/
+func (s *Service) GenerateStatement(request *pb.GenerateStatementRequest, stream pb.BankAccountService_GenerateStatementServer) error {
+	// get the stream context
+	ctx := stream.Context()
+	// get context log
+	log := log.WithContext(ctx)
+
+	// validate the request before hitting the read model
+	switch {
+	case request.GetStartTime() == nil || request.GetEndTime() == nil:
+		return status.Error(codes.InvalidArgument, "the statement period is not set")
+	case request.GetFormat() == pb.StatementFormat_STATEMENT_FORMAT_NONE:
+		return status.Error(codes.InvalidArgument, "the statement format is not set")
+	}
+
+	// resolve the tenant of the request
+	tenantID, err := s.tenantID(ctx)
+	if err != nil {
+		return err
+	}
+	accountID := tenant.EntityID(tenantID, request.GetAccountId())
+
+	// check the caller is allowed to read the account
+	if err := s.authorize(ctx, auth.PermissionRead, s.accountOwner(ctx, accountID)); err != nil {
+		return err
+	}
+
+	// build the statement from the read model
+	accountStatement, err := s.statementGenerator.Generate(ctx, tenantID, accountID, request.GetStartTime().AsTime(), request.GetEndTime().AsTime())
+	// handle the error
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	// the statement is rendered with the ids known by the tenant
+	accountStatement.AccountId = request.GetAccountId()
+	for _, transaction := range accountStatement.GetTransactions() {
+		transaction.AccountId = tenant.LocalID(tenantID, transaction.GetAccountId())
+	}
+
+	// render the statement and stream it back
+	writer := newChunkWriter(stream)
+	if err := statement.Render(writer, accountStatement, request.GetFormat()); err != nil {
+		log.Error(err)
+		return err
+	}
+
+	return writer.Flush()
+}
+
+// WatchAccount streams the current state of a given account and then every subsequent change of that account.
+// When resuming from a revision, the current state is only streamed when it is newer than that revision.
+// In case of error a gRPC error is returned. For more information refer to https://www.grpc.io/docs/guides/error/
+func (s *Service) WatchAccount(request *pb.WatchAccountRequest, stream pb.BankAccountService_WatchAccountServer) error {
+	// get the stream context
+	ctx := stream.Context()
+	// get context log
+	log := log.WithContext(ctx)
+
+	// validate the request
+	if request.GetAccountId() == "" {
+		return status.Error(codes.InvalidArgument, "the account id is not set")
+	}
+
+	if s.subscriber == nil {
+		return status.Error(codes.Unimplemented, "watching accounts is not enabled")
+	}
+
+	// resolve the tenant of the request
+	tenantID, err := s.tenantID(ctx)
+	if err != nil {
+		return err
+	}
+	accountID := tenant.EntityID(tenantID, request.GetAccountId())
+
+	// check the caller is allowed to read the account
+	if err := s.authorize(ctx, auth.PermissionRead, s.accountOwner(ctx, accountID)); err != nil {
+		return err
+	}
+
+	// watch the account before fetching its current state so that no change is missed in between
+	watcher, err := s.subscriber.Subscribe(ctx, accountID)
+	// handle the error
+	if err != nil {
+		log.Error(err)
+		return status.Error(codes.Unavailable, "the account changes cannot be watched")
+	}
+	// free resources
+	defer func() {
+		if err := s.subscriber.Unsubscribe(ctx, watcher); err != nil {
+			log.Error(err)
+		}
+	}()
+
+	// fetch the current state of the account
+	state, meta, err := s.cosClient.GetState(ctx, accountID)
+	// handle the error
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	// stream the current state unless the client already has it
+	lastRevision := request.GetFromRevision()
+	if meta.GetRevisionNumber() > lastRevision {
+		account, err := s.decryptAccount(ctx, state)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&pb.WatchAccountResponse{
+			Account:        localAccount(account),
+			RevisionNumber: meta.GetRevisionNumber(),
+			RevisionDate:   meta.GetRevisionDate(),
+		}); err != nil {
+			return err
+		}
+		lastRevision = meta.GetRevisionNumber()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events():
+			if !ok {
+				if watcher.Lagging() {
+					return status.Errorf(codes.ResourceExhausted, "the watch fell behind the account changes, resume from revision %d", lastRevision)
+				}
+				return nil
+			}
+
+			// skip the changes already streamed
+			if event.Meta.GetRevisionNumber() <= lastRevision {
+				continue
+			}
+
+			state, ok := event.ResultingState.(*pb.BankAccount)
+			if !ok {
+				continue
+			}
+			// the resulting state is shared by the watchers of the account, hence it is decrypted into a copy
+			account, err := s.decryptAccount(ctx, state)
+			if err != nil {
+				return err
+			}
+
+			response := &pb.WatchAccountResponse{
+				Account:        localAccount(account),
+				RevisionNumber: event.Meta.GetRevisionNumber(),
+				RevisionDate:   event.Meta.GetRevisionDate(),
+			}
+			// attach the event that led to the account state
+			if anyEvent, err := anypb.New(event.Event); err == nil {
+				response.Event = anyEvent
+			}
+
+			if err := stream.Send(response); err != nil {
+				return err
+			}
+			lastRevision = event.Meta.GetRevisionNumber()
+		}
+	}
+}
+
+// BatchGetAccounts returns the given accounts from the read model. The accounts are returned in the order of the account ids
+// and the accounts not found are left unset. In case of error a gRPC error is returned. For more information refer to https://www.grpc.io/docs/guides/error/
+func (s *Service) BatchGetAccounts(ctx context.Context, request *pb.BatchGetAccountsRequest) (*pb.BatchGetAccountsResponse, error) {
+	// get context log
+	log := log.WithContext(ctx)
+
+	// validate the request
+	accountIDs := request.GetAccountIds()
+	switch {
+	case len(accountIDs) == 0:
+		return nil, status.Error(codes.InvalidArgument, "the account ids are not set")
+	case len(accountIDs) > MaxBatchSize:
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d accounts can be fetched at once", MaxBatchSize)
+	}
+
+	if s.accountReader == nil {
+		return nil, status.Error(codes.Unimplemented, "reading the accounts from the read model is not enabled")
+	}
+
+	// resolve the tenant of the request
+	tenantID, err := s.tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entityIDs := make([]string, len(accountIDs))
+	for index, accountID := range accountIDs {
+		entityIDs[index] = tenant.EntityID(tenantID, accountID)
+	}
+
+	records, err := s.accountReader.GetAccounts(ctx, tenantID, entityIDs)
+	// handle the error
+	if err != nil {
+		log.Error(err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	results := make([]*pb.BatchGetAccountsResult, len(accountIDs))
+	for index, accountID := range accountIDs {
+		result := &pb.BatchGetAccountsResult{AccountId: accountID}
+		// the account is found in the read model
+		if index < len(records) && records[index] != nil {
+			// the accounts the caller is not allowed to read are left unset
+			readable, err := s.readable(ctx, records[index].GetAccount())
+			if err != nil {
+				return nil, err
+			}
+
+			if readable {
+				account := toGetAccountResponse(records[index])
+				result.Account = account.GetAccount()
+				result.RevisionNumber = account.GetRevisionNumber()
+				result.RevisionDate = account.GetRevisionDate()
+			}
+		}
+		results[index] = result
+	}
+
+	return &pb.BatchGetAccountsResponse{Results: results}, nil
+}
+
+// BulkPost executes the given credits and debits concurrently, at most BulkPostParallelism at a time. Every command succeeds or fails
+// on its own and its outcome is returned in the order of the commands. In case of error a gRPC error is returned.
+// For more information refer to https://www.grpc.io/docs/guides/error/
+func (s *Service) BulkPost(ctx context.Context, request *pb.BulkPostRequest) (*pb.BulkPostResponse, error) {
+	// validate the request
+	entries := request.GetEntries()
+	switch {
+	case len(entries) == 0:
+		return nil, status.Error(codes.InvalidArgument, "the entries are not set")
+	case len(entries) > MaxBatchSize:
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d entries can be posted at once", MaxBatchSize)
+	}
+
+	results := make([]*pb.BulkPostResult, len(entries))
+	group := new(errgroup.Group)
+	group.SetLimit(BulkPostParallelism)
+	for index, entry := range entries {
+		group.Go(func() error {
+			results[index] = s.post(ctx, entry)
+			return nil
+		})
+	}
+	// the commands failures are reported per entry
+	_ = group.Wait()
+
+	return &pb.BulkPostResponse{Results: results}, nil
+}
+
+// post executes the command of a given bulk post entry and returns its outcome
+func (s *Service) post(ctx context.Context, entry *pb.BulkPostEntry) *pb.BulkPostResult {
+	var (
+		account        *pb.BankAccount
+		revisionNumber int32
+		revisionDate   *timestamppb.Timestamp
+		err            error
+	)
+
+	switch command := entry.GetCommand().(type) {
+	case *pb.BulkPostEntry_Credit:
+		// the entries do not go through the interceptors, hence they are validated and their account is rate limited here
+		if err = s.validate(command.Credit); err != nil {
+			break
+		}
+		if err = s.allowAccount(ctx, command.Credit.GetAccountId()); err != nil {
+			break
+		}
+		var response *pb.CreditAccountResponse
+		if response, err = s.CreditAccount(ctx, command.Credit); err == nil {
+			account, revisionNumber, revisionDate = response.GetAccount(), response.GetRevisionNumber(), response.GetRevisionDate()
+		}
+	case *pb.BulkPostEntry_Debit:
+		if err = s.validate(command.Debit); err != nil {
+			break
+		}
+		if err = s.allowAccount(ctx, command.Debit.GetAccountId()); err != nil {
+			break
+		}
+		var response *pb.DebitAccountResponse
+		if response, err = s.DebitAccount(ctx, command.Debit); err == nil {
+			account, revisionNumber, revisionDate = response.GetAccount(), response.GetRevisionNumber(), response.GetRevisionDate()
+		}
+	default:
+		err = status.Error(codes.InvalidArgument, "the command is not set")
+	}
+
+	if err != nil {
+		return &pb.BulkPostResult{Error: status.Convert(err).Proto()}
+	}
+	return &pb.BulkPostResult{Account: account, RevisionNumber: revisionNumber, RevisionDate: revisionDate}
+}
+
+// ListAuditEntries returns the audit trail of a given account or standing order: its events with the caller of the commands that led to them.
+// The entries are ordered by revision and paginated. The page token is the revision of the last entry of the previous page.
+// In case of error a gRPC error is returned. For more information refer to https://www.grpc.io/docs/guides/error/
+func (s *Service) ListAuditEntries(ctx context.Context, request *pb.ListAuditEntriesRequest) (*pb.ListAuditEntriesResponse, error) {
+	// get context log
+	log := log.WithContext(ctx)
+
+	// validate the request
+	var (
+		entityID string
+		ownerOf  func(context.Context, string) auth.OwnerFunc
+	)
+	switch entity := request.GetEntity().(type) {
+	case *pb.ListAuditEntriesRequest_AccountId:
+		entityID, ownerOf = entity.AccountId, s.accountOwner
+	case *pb.ListAuditEntriesRequest_OrderId:
+		entityID, ownerOf = entity.OrderId, s.standingOrderOwner
+	}
+	if entityID == "" {
+		return nil, status.Error(codes.InvalidArgument, "the account id or the standing order id is not set")
+	}
+
+	pageSize := int(request.GetPageSize())
+	switch {
+	case pageSize < 0 || pageSize > MaxAuditPageSize:
+		return nil, status.Errorf(codes.InvalidArgument, "the page size must be between 0 and %d", MaxAuditPageSize)
+	case pageSize == 0:
+		pageSize = DefaultAuditPageSize
+	}
+
+	var afterRevision int32
+	if request.GetPageToken() != "" {
+		revision, err := strconv.ParseInt(request.GetPageToken(), 10, 32)
+		if err != nil || revision < 0 {
+			return nil, status.Error(codes.InvalidArgument, "the page token is invalid")
+		}
+		afterRevision = int32(revision)
+	}
+
+	if s.accountReader == nil {
+		return nil, status.Error(codes.Unimplemented, "reading the audit trail is not enabled")
+	}
+
+	// resolve the tenant of the request
+	tenantID, err := s.tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entityID = tenant.EntityID(tenantID, entityID)
+
+	// check the caller is allowed to read the account
+	if err := s.authorize(ctx, auth.PermissionRead, ownerOf(ctx, entityID)); err != nil {
+		return nil, err
+	}
+
+	// fetch one more entry to know whether there is a next page
+	entries, err := s.accountReader.GetAuditEntries(ctx, tenantID, entityID, afterRevision, pageSize+1)
+	// handle the error
+	if err != nil {
+		log.Error(err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// the entries are returned with the ids known by the tenant
+	for _, entry := range entries {
+		entry.EntityId = tenant.LocalID(tenantID, entry.GetEntityId())
+	}
+
+	response := &pb.ListAuditEntriesResponse{Entries: entries}
+	if len(entries) > pageSize {
+		response.Entries = entries[:pageSize]
+		response.NextPageToken = strconv.FormatInt(int64(entries[pageSize-1].GetRevisionNumber()), 10)
+	}
+	return response, nil
+}
+
+// EraseCustomer erases the personal data of a given customer by destroying its data key: its events are left intact but unreadable
+// and its accounts are scrubbed from the read model. In case of error a gRPC error is returned. For more information refer to https://www.grpc.io/docs/guides/error/
+func (s *Service) EraseCustomer(ctx context.Context, request *pb.EraseCustomerRequest) (*pb.EraseCustomerResponse, error) {
+	// get context log
+	log := log.WithContext(ctx)
+
+	// validate the request
+	if request.GetAccountOwner() == "" {
+		return nil, status.Error(codes.InvalidArgument, "the account owner is not set")
+	}
+
+	if s.cipher == nil {
+		return nil, status.Error(codes.Unimplemented, "the encryption of the personal data is not enabled")
+	}
+
+	// resolve the tenant of the request
+	tenantID, err := s.tenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// check the caller is allowed to erase the customers
+	if err := s.authorize(ctx, auth.PermissionEraseCustomers, auth.OwnedBy(request.GetAccountOwner())); err != nil {
+		return nil, err
+	}
+
+	// destroy the data key first so that the read side cannot write the owner back
+	if err := s.cipher.Erase(ctx, tenant.EntityID(tenantID, request.GetAccountOwner())); err != nil {
+		log.Error(err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// scrub the owner from the read model
+	response := new(pb.EraseCustomerResponse)
+	if s.accountReader != nil {
+		accountIDs, err := s.accountReader.EraseAccountOwner(ctx, tenantID, request.GetAccountOwner())
+		// handle the error
+		if err != nil {
+			log.Error(err)
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		for _, accountID := range accountIDs {
+			response.ErasedAccountIds = append(response.ErasedAccountIds, tenant.LocalID(tenantID, accountID))
+		}
+	}
+	return response, nil
+}
+
+// encryptOwner encrypts a given account owner of a given tenant with its data key, the customers of the tenants having their own keys.
+// The owner is left in clear when the encryption is not enabled
+func (s *Service) encryptOwner(ctx context.Context, tenantID, accountOwner string) (string, error) {
+	if s.cipher == nil {
+		return accountOwner, nil
+	}
+	return s.cipher.Encrypt(ctx, tenant.EntityID(tenantID, accountOwner), accountOwner)
+}
+
+// decryptAccount returns a given account with its personal data decrypted. The owner of the erased customers is left empty
+// and the account is returned as is when the encryption is not enabled
+func (s *Service) decryptAccount(ctx context.Context, account *pb.BankAccount) (*pb.BankAccount, error) {
+	if s.cipher == nil {
+		return account, nil
+	}
+
+	decrypted, err := s.cipher.DecryptAccount(ctx, account)
+	// handle the error
+	if err != nil {
+		log.WithContext(ctx).Error(err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return decrypted, nil
+}
+
+// tenantID resolves the tenant of the request served with the given context. There is no tenant when the multi-tenancy is not enabled
+func (s *Service) tenantID(ctx context.Context) (string, error) {
+	if s.tenants == nil {
+		return "", nil
+	}
+	return s.tenants.Resolve(ctx)
+}
+
+// localAccount returns a given account with its id as known by its tenant
+func localAccount(account *pb.BankAccount) *pb.BankAccount {
+	if account.GetTenantId() == "" {
+		return account
+	}
+	// the account may be shared, e.g. by the watchers of the account, hence its id is set on a copy
+	local := proto.Clone(account).(*pb.BankAccount)
+	local.AccountId = tenant.LocalID(account.GetTenantId(), account.GetAccountId())
+	return local
+}
+
+// localStandingOrder returns a given standing order with its ids as known by its tenant
+func localStandingOrder(standingOrder *pb.StandingOrder) *pb.StandingOrder {
+	tenantID := standingOrder.GetTenantId()
+	if tenantID == "" {
+		return standingOrder
+	}
+	local := proto.Clone(standingOrder).(*pb.StandingOrder)
+	local.OrderId = tenant.LocalID(tenantID, standingOrder.GetOrderId())
+	local.AccountId = tenant.LocalID(tenantID, standingOrder.GetAccountId())
+	local.BeneficiaryAccountId = tenant.LocalID(tenantID, standingOrder.GetBeneficiaryAccountId())
+	return local
+}
+
+// validate validates a given command of a bulk post entry. Every command is valid when the validation is not enabled
+func (s *Service) validate(command proto.Message) error {
+	if s.validator == nil {
+		return nil
+	}
+	return s.validator.Validate(command)
+}
+
+// allowAccount takes a rate limit token for a given account targeted by a bulk post entry. Every entry is allowed when the rate limiting is not enabled
+func (s *Service) allowAccount(ctx context.Context, accountID string) error {
+	if s.limiter == nil {
+		return nil
+	}
+	return s.limiter.AllowAccount(ctx, pb.BankAccountService_BulkPost_FullMethodName, accountID)
+}
+
+// authorize checks that the caller is granted a given permission on the accounts of the owner returned by the given function.
+// Every request is allowed when the authorization is not enabled
+func (s *Service) authorize(ctx context.Context, permission auth.Permission, owner auth.OwnerFunc) error {
+	if s.authorizer == nil {
+		return nil
+	}
+	return s.authorizer.Authorize(ctx, permission, owner)
+}
+
+// readable checks whether the caller is allowed to read a given account. The accounts the caller is not allowed to read
+// are reported as not found by the batch reads
+func (s *Service) readable(ctx context.Context, account *pb.BankAccount) (bool, error) {
+	if s.authorizer == nil {
+		return true, nil
+	}
+	return s.authorizer.Allowed(ctx, auth.PermissionRead, auth.OwnedBy(account.GetAccountOwner()))
+}
+
+// accountOwner returns the OwnerFunc looking up the owner of a given account in CoS
+func (s *Service) accountOwner(ctx context.Context, accountID string) auth.OwnerFunc {
+	return func() (string, error) {
+		state, _, err := s.cosClient.GetState(ctx, accountID)
+		// handle the error
+		if err != nil {
+			log.WithContext(ctx).Error(err)
+			return "", err
+		}
+		account, err := s.decryptAccount(ctx, state)
+		if err != nil {
+			return "", err
+		}
+		return account.GetAccountOwner(), nil
+	}
+}
+
+// standingOrderOwner returns the OwnerFunc looking up the owner of the account of a given standing order in CoS
+func (s *Service) standingOrderOwner(ctx context.Context, orderID string) auth.OwnerFunc {
+	return func() (string, error) {
+		standingOrder, _, err := s.cosClient.GetStandingOrder(ctx, orderID)
+		// handle the error
+		if err != nil {
+			log.WithContext(ctx).Error(err)
+			return "", err
+		}
+
+		// the standing order is not found
+		if standingOrder == nil {
+			return "", status.Error(codes.NotFound, "the standing order is not found")
+		}
+		return s.accountOwner(ctx, standingOrder.GetAccountId())()
+	}
 }
 
 // RegisterService registers the gRPC api
@@ -3,25 +3,41 @@ package service
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	gopack "github.com/tochemey/gopack/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/tochemey/cos-go-sample/app/auth"
+	"github.com/tochemey/cos-go-sample/app/pii"
+	"github.com/tochemey/cos-go-sample/app/ratelimit"
+	"github.com/tochemey/cos-go-sample/app/readmodel"
+	"github.com/tochemey/cos-go-sample/app/statement"
+	"github.com/tochemey/cos-go-sample/app/storage"
+	"github.com/tochemey/cos-go-sample/app/subscription"
+	"github.com/tochemey/cos-go-sample/app/tenant"
+	"github.com/tochemey/cos-go-sample/app/validation"
 	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
 	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
 	mocks "github.com/tochemey/cos-go-sample/mocks/app/cos"
+	storagemocks "github.com/tochemey/cos-go-sample/mocks/app/storage"
+	subscriptionmocks "github.com/tochemey/cos-go-sample/mocks/app/subscription"
+	pbmocks "github.com/tochemey/cos-go-sample/mocks/gen/accounts/v1"
 )
 
 func TestService(t *testing.T) {
 	t.Run("With new instance", func(t *testing.T) {
 		cosClient := new(mocks.Client)
-		svc := NewService(cosClient)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil)
 		assert.NotNil(t, svc)
 		// assert the type of svc
 		assert.IsType(t, &Service{}, svc)
@@ -52,6 +68,7 @@ func TestService(t *testing.T) {
 			AccountOwner: accountOwner,
 			Balance:      openingBalance,
 			AccountId:    &accountID,
+			AccountType:  pb.AccountType_SAVINGS,
 		}
 
 		// create the command sent to the cos mock service
@@ -59,6 +76,7 @@ func TestService(t *testing.T) {
 			AccountId:      accountID,
 			AccountOwner:   accountOwner,
 			OpeningBalance: openingBalance,
+			AccountType:    pb.AccountType_SAVINGS,
 		}
 
 		// create the resulting state when cos finishes processing the command
@@ -67,18 +85,19 @@ func TestService(t *testing.T) {
 			AccountBalance: openingBalance,
 			AccountOwner:   accountOwner,
 			IsClosed:       false,
+			AccountType:    pb.AccountType_SAVINGS,
 		}
 
 		// create the cos meta
 		cosMeta := &cospb.MetaData{EntityId: accountID, RevisionNumber: 1, RevisionDate: timestamppb.Now()}
 
 		// create the expected response
-		expected := &pb.OpenAccountResponse{Account: state}
+		expected := &pb.OpenAccountResponse{Account: state, RevisionNumber: cosMeta.GetRevisionNumber(), RevisionDate: cosMeta.GetRevisionDate()}
 
 		// create a mock cos client
 		cosClient := new(mocks.Client)
 		cosClient.On("ProcessCommand", ctx, accountID, command).Return(state, cosMeta, nil)
-		svc := NewService(cosClient)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil)
 		require.NotNil(t, svc)
 
 		// process the request
@@ -117,7 +136,7 @@ func TestService(t *testing.T) {
 		// create a mock cos client
 		cosClient := new(mocks.Client)
 		cosClient.On("ProcessCommand", ctx, accountID, command).Return(new(pb.BankAccount), cosMeta, err)
-		svc := NewService(cosClient)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil)
 		require.NotNil(t, svc)
 
 		// process the request
@@ -156,12 +175,12 @@ func TestService(t *testing.T) {
 		cosMeta := &cospb.MetaData{EntityId: accountID, RevisionNumber: 1, RevisionDate: timestamppb.Now()}
 
 		// create the expected response
-		expected := &pb.DebitAccountResponse{Account: state}
+		expected := &pb.DebitAccountResponse{Account: state, RevisionNumber: cosMeta.GetRevisionNumber(), RevisionDate: cosMeta.GetRevisionDate()}
 
 		// create a mock cos client
 		cosClient := new(mocks.Client)
 		cosClient.On("ProcessCommand", ctx, accountID, command).Return(state, cosMeta, nil)
-		svc := NewService(cosClient)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil)
 		require.NotNil(t, svc)
 
 		// process the request
@@ -197,7 +216,7 @@ func TestService(t *testing.T) {
 		// create a mock cos client
 		cosClient := new(mocks.Client)
 		cosClient.On("ProcessCommand", ctx, accountID, command).Return(new(pb.BankAccount), cosMeta, expectedErr)
-		svc := NewService(cosClient)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil)
 		require.NotNil(t, svc)
 
 		// process the request
@@ -237,12 +256,12 @@ func TestService(t *testing.T) {
 		cosMeta := &cospb.MetaData{EntityId: accountID, RevisionNumber: 1, RevisionDate: timestamppb.Now()}
 
 		// create the expected response
-		expected := &pb.CreditAccountResponse{Account: state}
+		expected := &pb.CreditAccountResponse{Account: state, RevisionNumber: cosMeta.GetRevisionNumber(), RevisionDate: cosMeta.GetRevisionDate()}
 
 		// create a mock cos client
 		cosClient := new(mocks.Client)
 		cosClient.On("ProcessCommand", ctx, accountID, command).Return(state, cosMeta, nil)
-		svc := NewService(cosClient)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil)
 		require.NotNil(t, svc)
 
 		// process the request
@@ -278,7 +297,7 @@ func TestService(t *testing.T) {
 		// create a mock cos client
 		cosClient := new(mocks.Client)
 		cosClient.On("ProcessCommand", ctx, accountID, command).Return(new(pb.BankAccount), cosMeta, expectedErr)
-		svc := NewService(cosClient)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil)
 		require.NotNil(t, svc)
 
 		// process the request
@@ -311,12 +330,12 @@ func TestService(t *testing.T) {
 		cosMeta := &cospb.MetaData{EntityId: accountID, RevisionNumber: 1, RevisionDate: timestamppb.Now()}
 
 		// create the expected response
-		expected := &pb.GetAccountResponse{Account: state}
+		expected := &pb.GetAccountResponse{Account: state, RevisionNumber: cosMeta.GetRevisionNumber(), RevisionDate: cosMeta.GetRevisionDate()}
 
 		// create a mock cos client
 		cosClient := new(mocks.Client)
 		cosClient.On("GetState", ctx, accountID).Return(state, cosMeta, nil)
-		svc := NewService(cosClient)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil)
 		require.NotNil(t, svc)
 
 		// process the request
@@ -344,7 +363,7 @@ func TestService(t *testing.T) {
 		// create a mock cos client
 		cosClient := new(mocks.Client)
 		cosClient.On("GetState", ctx, accountID).Return(new(pb.BankAccount), cosMeta, err)
-		svc := NewService(cosClient)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil)
 		require.NotNil(t, svc)
 
 		// process the request
@@ -353,4 +372,1380 @@ func TestService(t *testing.T) {
 		require.Nil(t, actual)
 		cosClient.AssertExpectations(t)
 	})
+	t.Run("With GetAccount request with eventual consistency", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := uuid.NewString()
+		revisionDate := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+		// create the rpc request
+		rpcReq := &pb.GetAccountRequest{
+			AccountId:   accountID,
+			Consistency: pb.Consistency_EVENTUAL,
+		}
+
+		// create the account record of the read model
+		account := &pb.BankAccount{AccountId: accountID, AccountBalance: 50, AccountOwner: "Mr Account"}
+		record := &storage.AccountRecord{Account: account, Revision: storage.Revision{Number: 2, Date: revisionDate}}
+
+		// create the expected response
+		expected := &pb.GetAccountResponse{Account: account, RevisionNumber: 2, RevisionDate: timestamppb.New(revisionDate)}
+
+		// create a mock data store and cos client
+		dataStore := new(storagemocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "", []string{accountID}).Return([]*storage.AccountRecord{record}, nil)
+		cosClient := new(mocks.Client)
+		svc := NewService(cosClient, nil, readmodel.NewReader(dataStore, &readmodel.Config{WaitTimeout: time.Second, PollInterval: time.Millisecond}), nil, nil, nil, nil, nil, nil)
+
+		// process the request
+		actual, err := svc.GetAccount(ctx, rpcReq)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(expected, actual))
+		cosClient.AssertNotCalled(t, "GetState", mock.Anything, mock.Anything)
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With GetAccount request with eventual consistency and account not found", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := uuid.NewString()
+
+		// create the rpc request
+		rpcReq := &pb.GetAccountRequest{
+			AccountId:   accountID,
+			Consistency: pb.Consistency_EVENTUAL,
+		}
+
+		// create a mock data store
+		dataStore := new(storagemocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "", []string{accountID}).Return([]*storage.AccountRecord{nil}, nil)
+		svc := NewService(new(mocks.Client), nil, readmodel.NewReader(dataStore, &readmodel.Config{WaitTimeout: time.Second, PollInterval: time.Millisecond}), nil, nil, nil, nil, nil, nil)
+
+		// process the request
+		actual, err := svc.GetAccount(ctx, rpcReq)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Nil(t, actual)
+	})
+	t.Run("With GetAccount request with eventual consistency and no read model", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := uuid.NewString()
+
+		// create the rpc request
+		rpcReq := &pb.GetAccountRequest{
+			AccountId:   accountID,
+			Consistency: pb.Consistency_EVENTUAL,
+		}
+
+		// create the resulting state and meta
+		state := &pb.BankAccount{AccountId: accountID, AccountBalance: 50}
+		cosMeta := &cospb.MetaData{EntityId: accountID, RevisionNumber: 1, RevisionDate: timestamppb.Now()}
+
+		// the account is read from CoS
+		cosClient := new(mocks.Client)
+		cosClient.On("GetState", ctx, accountID).Return(state, cosMeta, nil)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil)
+
+		// process the request
+		actual, err := svc.GetAccount(ctx, rpcReq)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(state, actual.GetAccount()))
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With GetAccount request with at least revision consistency", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := uuid.NewString()
+
+		// create the rpc request
+		rpcReq := &pb.GetAccountRequest{
+			AccountId:   accountID,
+			Consistency: pb.Consistency_AT_LEAST_REVISION,
+			MinRevision: 2,
+		}
+
+		// the read model catches up with the revision
+		account := &pb.BankAccount{AccountId: accountID, AccountBalance: 50}
+		dataStore := new(storagemocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "", []string{accountID}).
+			Return([]*storage.AccountRecord{{Account: account, Revision: storage.Revision{Number: 1}}}, nil).Once()
+		dataStore.On("GetAccounts", mock.Anything, "", []string{accountID}).
+			Return([]*storage.AccountRecord{{Account: account, Revision: storage.Revision{Number: 2}}}, nil).Once()
+		cosClient := new(mocks.Client)
+		svc := NewService(cosClient, nil, readmodel.NewReader(dataStore, &readmodel.Config{WaitTimeout: time.Second, PollInterval: time.Millisecond}), nil, nil, nil, nil, nil, nil)
+
+		// process the request
+		actual, err := svc.GetAccount(ctx, rpcReq)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(account, actual.GetAccount()))
+		assert.EqualValues(t, 2, actual.GetRevisionNumber())
+		cosClient.AssertNotCalled(t, "GetState", mock.Anything, mock.Anything)
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With GetAccount request with at least revision consistency and read model behind", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := uuid.NewString()
+
+		// create the rpc request
+		rpcReq := &pb.GetAccountRequest{
+			AccountId:   accountID,
+			Consistency: pb.Consistency_AT_LEAST_REVISION,
+			MinRevision: 3,
+		}
+
+		// the read model does not catch up with the revision
+		dataStore := new(storagemocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "", []string{accountID}).
+			Return([]*storage.AccountRecord{{Account: &pb.BankAccount{AccountId: accountID}, Revision: storage.Revision{Number: 1}}}, nil)
+
+		// the account is read from CoS
+		state := &pb.BankAccount{AccountId: accountID, AccountBalance: 50}
+		cosMeta := &cospb.MetaData{EntityId: accountID, RevisionNumber: 3, RevisionDate: timestamppb.Now()}
+		cosClient := new(mocks.Client)
+		cosClient.On("GetState", ctx, accountID).Return(state, cosMeta, nil)
+		svc := NewService(cosClient, nil, readmodel.NewReader(dataStore, &readmodel.Config{WaitTimeout: 20 * time.Millisecond, PollInterval: 5 * time.Millisecond}), nil, nil, nil, nil, nil, nil)
+
+		// process the request
+		actual, err := svc.GetAccount(ctx, rpcReq)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(state, actual.GetAccount()))
+		assert.EqualValues(t, 3, actual.GetRevisionNumber())
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With GetAccount request with at least revision consistency and minimum revision not set", func(t *testing.T) {
+		ctx := context.TODO()
+
+		// create the rpc request
+		rpcReq := &pb.GetAccountRequest{
+			AccountId:   uuid.NewString(),
+			Consistency: pb.Consistency_AT_LEAST_REVISION,
+		}
+
+		svc := NewService(new(mocks.Client), nil, readmodel.NewReader(new(storagemocks.Storage), readmodel.LoadConfig()), nil, nil, nil, nil, nil, nil)
+
+		// process the request
+		actual, err := svc.GetAccount(ctx, rpcReq)
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = the minimum revision is not set")
+		assert.Nil(t, actual)
+	})
+	t.Run("With GetAccountAt request", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := uuid.NewString()
+		revisionDate := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+
+		// create the events ledger of the account
+		anyEvent, err := anypb.New(&pb.AccountOpened{AccountId: accountID, AccountOwner: "Mr Account", Balance: 50})
+		require.NoError(t, err)
+		ledger := []*storage.AccountEvent{{AccountID: accountID, RevisionNumber: 1, RevisionDate: revisionDate, Event: anyEvent}}
+
+		// create a mock data store
+		dataStore := new(storagemocks.Storage)
+		dataStore.On("GetAccountEvents", mock.Anything, "", accountID, int32(0), revisionDate).Return(ledger, nil)
+		svc := NewService(new(mocks.Client), nil, readmodel.NewReader(dataStore, readmodel.LoadConfig()), nil, nil, nil, nil, nil, nil)
+
+		// process the request
+		rpcReq := &pb.GetAccountAtRequest{AccountId: accountID, Point: &pb.GetAccountAtRequest_AsOfTime{AsOfTime: timestamppb.New(revisionDate)}}
+		actual, err := svc.GetAccountAt(ctx, rpcReq)
+		require.NoError(t, err)
+		assert.EqualValues(t, 50, actual.GetAccount().GetAccountBalance())
+		assert.EqualValues(t, 1, actual.GetRevisionNumber())
+		assert.True(t, proto.Equal(timestamppb.New(revisionDate), actual.GetRevisionDate()))
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With GetAccountAt request without point in time", func(t *testing.T) {
+		svc := NewService(new(mocks.Client), nil, readmodel.NewReader(new(storagemocks.Storage), readmodel.LoadConfig()), nil, nil, nil, nil, nil, nil)
+		actual, err := svc.GetAccountAt(context.TODO(), &pb.GetAccountAtRequest{AccountId: "account-1"})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = the point in time is not set")
+		assert.Nil(t, actual)
+	})
+	t.Run("With GetAccountAt request with invalid revision", func(t *testing.T) {
+		svc := NewService(new(mocks.Client), nil, readmodel.NewReader(new(storagemocks.Storage), readmodel.LoadConfig()), nil, nil, nil, nil, nil, nil)
+		actual, err := svc.GetAccountAt(context.TODO(), &pb.GetAccountAtRequest{AccountId: "account-1", Point: &pb.GetAccountAtRequest_Revision{}})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = the revision is invalid")
+		assert.Nil(t, actual)
+	})
+	t.Run("With GetAccountAt request and no read model", func(t *testing.T) {
+		svc := NewService(new(mocks.Client), nil, nil, nil, nil, nil, nil, nil, nil)
+		actual, err := svc.GetAccountAt(context.TODO(), &pb.GetAccountAtRequest{AccountId: "account-1", Point: &pb.GetAccountAtRequest_Revision{Revision: 1}})
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+		assert.Nil(t, actual)
+	})
+	t.Run("With GetAccountAt request with unknown account", func(t *testing.T) {
+		dataStore := new(storagemocks.Storage)
+		dataStore.On("GetAccountEvents", mock.Anything, "", "account-1", int32(2), time.Time{}).Return(nil, nil)
+		svc := NewService(new(mocks.Client), nil, readmodel.NewReader(dataStore, readmodel.LoadConfig()), nil, nil, nil, nil, nil, nil)
+
+		actual, err := svc.GetAccountAt(context.TODO(), &pb.GetAccountAtRequest{AccountId: "account-1", Point: &pb.GetAccountAtRequest_Revision{Revision: 2}})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Nil(t, actual)
+	})
+	t.Run("With WaiveFee request", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := uuid.NewString()
+		accountOwner := "Mr Account"
+		amount := 2.0
+
+		// create the rpc request
+		rpcReq := &pb.WaiveFeeRequest{
+			AccountId: accountID,
+			Amount:    amount,
+			Reason:    "goodwill",
+		}
+
+		// create the command sent to the cos mock service
+		command := &pb.WaiveFee{
+			AccountId: accountID,
+			Amount:    amount,
+			Reason:    "goodwill",
+		}
+
+		// create the resulting state when cos finishes processing the command
+		state := &pb.BankAccount{
+			AccountId:        accountID,
+			AccountBalance:   amount + 10.0,
+			AccountOwner:     accountOwner,
+			TotalFeesCharged: amount,
+			TotalFeesWaived:  amount,
+		}
+
+		// create the cos meta
+		cosMeta := &cospb.MetaData{EntityId: accountID, RevisionNumber: 1, RevisionDate: timestamppb.Now()}
+
+		// create the expected response
+		expected := &pb.WaiveFeeResponse{Account: state, RevisionNumber: cosMeta.GetRevisionNumber(), RevisionDate: cosMeta.GetRevisionDate()}
+
+		// create a mock cos client
+		cosClient := new(mocks.Client)
+		cosClient.On("ProcessCommand", ctx, accountID, command).Return(state, cosMeta, nil)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil)
+		require.NotNil(t, svc)
+
+		// process the request
+		actual, err := svc.WaiveFee(ctx, rpcReq)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.True(t, proto.Equal(expected, actual))
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With CreateStandingOrder request", func(t *testing.T) {
+		ctx := context.TODO()
+		orderID := uuid.NewString()
+		startDate := timestamppb.Now()
+
+		// create the rpc request
+		rpcReq := &pb.CreateStandingOrderRequest{
+			AccountId:            "account-1",
+			BeneficiaryAccountId: "account-2",
+			Amount:               50,
+			Frequency:            pb.Frequency_MONTHLY,
+			StartDate:            startDate,
+			Reference:            "rent",
+			OrderId:              &orderID,
+		}
+
+		// create the command sent to the cos mock service
+		command := &pb.CreateStandingOrder{
+			OrderId:              orderID,
+			AccountId:            "account-1",
+			BeneficiaryAccountId: "account-2",
+			Amount:               50,
+			Frequency:            pb.Frequency_MONTHLY,
+			StartDate:            startDate,
+			Reference:            "rent",
+		}
+
+		// create the resulting state when cos finishes processing the command
+		state := &pb.StandingOrder{
+			OrderId:              orderID,
+			AccountId:            "account-1",
+			BeneficiaryAccountId: "account-2",
+			Amount:               50,
+			Frequency:            pb.Frequency_MONTHLY,
+			Status:               pb.StandingOrderStatus_ACTIVE,
+			NextExecutionDate:    startDate,
+			Reference:            "rent",
+		}
+
+		// create the cos meta
+		cosMeta := &cospb.MetaData{EntityId: orderID, RevisionNumber: 1, RevisionDate: timestamppb.Now()}
+
+		// create the expected response
+		expected := &pb.CreateStandingOrderResponse{StandingOrder: state, RevisionNumber: cosMeta.GetRevisionNumber(), RevisionDate: cosMeta.GetRevisionDate()}
+
+		// create a mock cos client
+		cosClient := new(mocks.Client)
+		cosClient.On("ProcessStandingOrderCommand", ctx, orderID, command).Return(state, cosMeta, nil)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil)
+		require.NotNil(t, svc)
+
+		// process the request
+		actual, err := svc.CreateStandingOrder(ctx, rpcReq)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.True(t, proto.Equal(expected, actual))
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With CreateStandingOrder request without order id", func(t *testing.T) {
+		ctx := context.TODO()
+
+		// create the rpc request
+		rpcReq := &pb.CreateStandingOrderRequest{
+			AccountId:            "account-1",
+			BeneficiaryAccountId: "account-2",
+			Amount:               50,
+			Frequency:            pb.Frequency_DAILY,
+			StartDate:            timestamppb.Now(),
+		}
+
+		// create a mock cos client
+		cosClient := new(mocks.Client)
+		cosClient.
+			On("ProcessStandingOrderCommand", ctx, mock.AnythingOfType("string"), mock.MatchedBy(func(command *pb.CreateStandingOrder) bool {
+				return command.GetOrderId() != ""
+			})).
+			Return(new(pb.StandingOrder), nil, nil)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil)
+		require.NotNil(t, svc)
+
+		// process the request
+		actual, err := svc.CreateStandingOrder(ctx, rpcReq)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With PauseStandingOrder request", func(t *testing.T) {
+		ctx := context.TODO()
+		orderID := uuid.NewString()
+
+		// create the resulting state when cos finishes processing the command
+		state := &pb.StandingOrder{OrderId: orderID, Status: pb.StandingOrderStatus_PAUSED}
+
+		// create a mock cos client
+		cosClient := new(mocks.Client)
+		cosClient.On("ProcessStandingOrderCommand", ctx, orderID, &pb.PauseStandingOrder{OrderId: orderID}).Return(state, nil, nil)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil)
+		require.NotNil(t, svc)
+
+		// process the request
+		actual, err := svc.PauseStandingOrder(ctx, &pb.PauseStandingOrderRequest{OrderId: orderID})
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(&pb.PauseStandingOrderResponse{StandingOrder: state}, actual))
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With ResumeStandingOrder request", func(t *testing.T) {
+		ctx := context.TODO()
+		orderID := uuid.NewString()
+
+		// create the resulting state when cos finishes processing the command
+		state := &pb.StandingOrder{OrderId: orderID, Status: pb.StandingOrderStatus_ACTIVE}
+
+		// create a mock cos client
+		cosClient := new(mocks.Client)
+		cosClient.On("ProcessStandingOrderCommand", ctx, orderID, &pb.ResumeStandingOrder{OrderId: orderID}).Return(state, nil, nil)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil)
+		require.NotNil(t, svc)
+
+		// process the request
+		actual, err := svc.ResumeStandingOrder(ctx, &pb.ResumeStandingOrderRequest{OrderId: orderID})
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(&pb.ResumeStandingOrderResponse{StandingOrder: state}, actual))
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With CancelStandingOrder request with cos client failure", func(t *testing.T) {
+		ctx := context.TODO()
+		orderID := uuid.NewString()
+
+		// create the expected error
+		err := status.Error(codes.FailedPrecondition, "the standing order is COMPLETED")
+
+		// create a mock cos client
+		cosClient := new(mocks.Client)
+		cosClient.On("ProcessStandingOrderCommand", ctx, orderID, &pb.CancelStandingOrder{OrderId: orderID}).Return(nil, nil, err)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil)
+		require.NotNil(t, svc)
+
+		// process the request
+		actual, err := svc.CancelStandingOrder(ctx, &pb.CancelStandingOrderRequest{OrderId: orderID})
+		require.Error(t, err)
+		require.Nil(t, actual)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With GetStandingOrder request", func(t *testing.T) {
+		ctx := context.TODO()
+		orderID := uuid.NewString()
+
+		// create the current state
+		state := &pb.StandingOrder{OrderId: orderID, Status: pb.StandingOrderStatus_ACTIVE}
+
+		// create a mock cos client
+		cosClient := new(mocks.Client)
+		cosClient.On("GetStandingOrder", ctx, orderID).Return(state, nil, nil)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil)
+		require.NotNil(t, svc)
+
+		// process the request
+		actual, err := svc.GetStandingOrder(ctx, &pb.GetStandingOrderRequest{OrderId: orderID})
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(&pb.GetStandingOrderResponse{StandingOrder: state}, actual))
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With GetStandingOrder request for unknown order", func(t *testing.T) {
+		ctx := auth.NewContext(context.TODO(), &auth.Principal{Subject: "customer-1", Roles: []auth.Role{auth.RoleCustomer}})
+
+		// create a mock cos client without state for the order
+		cosClient := new(mocks.Client)
+		cosClient.On("GetStandingOrder", ctx, "order-1").Return(nil, nil, nil)
+		svc := NewService(cosClient, nil, nil, nil, auth.NewAuthorizer(), nil, nil, nil, nil)
+
+		// the order is not found rather than the caller not allowed
+		actual, err := svc.GetStandingOrder(ctx, &pb.GetStandingOrderRequest{OrderId: "order-1"})
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = the standing order:(order-1) is not found")
+		assert.Nil(t, actual)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With PauseStandingOrder request for unknown order", func(t *testing.T) {
+		ctx := auth.NewContext(context.TODO(), &auth.Principal{Subject: "customer-1", Roles: []auth.Role{auth.RoleCustomer}})
+
+		// create a mock cos client without state for the order
+		cosClient := new(mocks.Client)
+		cosClient.On("GetStandingOrder", ctx, "order-1").Return(nil, nil, nil)
+		svc := NewService(cosClient, nil, nil, nil, auth.NewAuthorizer(), nil, nil, nil, nil)
+
+		actual, err := svc.PauseStandingOrder(ctx, &pb.PauseStandingOrderRequest{OrderId: "order-1"})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Nil(t, actual)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With GenerateStatement request", func(t *testing.T) {
+		ctx := context.TODO()
+		startTime := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+		endTime := startTime.AddDate(0, 1, 0)
+
+		// create the rpc request
+		rpcReq := &pb.GenerateStatementRequest{
+			AccountId: "account-1",
+			StartTime: timestamppb.New(startTime),
+			EndTime:   timestamppb.New(endTime),
+			Format:    pb.StatementFormat_CSV,
+		}
+
+		// create a mock data store
+		dataStore := new(storagemocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "", []string{"account-1"}).Return([]*storage.AccountRecord{{Account: &pb.BankAccount{AccountId: "account-1"}}}, nil)
+		dataStore.On("GetLastTransaction", mock.Anything, "", "account-1", startTime).Return(&pb.Transaction{BalanceAfter: 10}, nil)
+		dataStore.On("GetTransactions", mock.Anything, "", "account-1", startTime, endTime).Return([]*pb.Transaction{
+			{AccountId: "account-1", RevisionNumber: 2, Type: pb.TransactionType_CREDIT, Amount: 5, BalanceAfter: 15, BookedAt: timestamppb.New(startTime)},
+		}, nil)
+
+		// create a mock stream collecting the chunks
+		var chunks []byte
+		stream := new(pbmocks.BankAccountService_GenerateStatementServer[pb.GenerateStatementResponse])
+		stream.On("Context").Return(ctx)
+		stream.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+			chunks = append(chunks, args.Get(0).(*pb.GenerateStatementResponse).GetChunk()...)
+		}).Return(nil)
+
+		svc := NewService(new(mocks.Client), statement.NewGenerator(dataStore, "USD"), nil, nil, nil, nil, nil, nil, nil)
+		require.NotNil(t, svc)
+
+		// process the request
+		require.NoError(t, svc.GenerateStatement(rpcReq, stream))
+		assert.Contains(t, string(chunks), "2024-01-01T00:00:00Z,2-0,CREDIT,,5.00,15.00")
+		assert.Contains(t, string(chunks), "CLOSING_BALANCE,,,15.00")
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With GenerateStatement request without format", func(t *testing.T) {
+		ctx := context.TODO()
+
+		// create the rpc request
+		rpcReq := &pb.GenerateStatementRequest{
+			AccountId: "account-1",
+			StartTime: timestamppb.Now(),
+			EndTime:   timestamppb.Now(),
+		}
+
+		// create a mock stream
+		stream := new(pbmocks.BankAccountService_GenerateStatementServer[pb.GenerateStatementResponse])
+		stream.On("Context").Return(ctx)
+
+		svc := NewService(new(mocks.Client), statement.NewGenerator(new(storagemocks.Storage), "USD"), nil, nil, nil, nil, nil, nil, nil)
+		require.NotNil(t, svc)
+
+		// process the request
+		err := svc.GenerateStatement(rpcReq, stream)
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = the statement format is not set")
+		stream.AssertNotCalled(t, "Send", mock.Anything)
+	})
+	t.Run("With GenerateStatement request with data store failure", func(t *testing.T) {
+		ctx := context.TODO()
+
+		// create the rpc request
+		rpcReq := &pb.GenerateStatementRequest{
+			AccountId: "account-1",
+			StartTime: timestamppb.New(time.Unix(0, 0)),
+			EndTime:   timestamppb.Now(),
+			Format:    pb.StatementFormat_JSON,
+		}
+
+		// create a mock data store
+		dataStore := new(storagemocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "", []string{"account-1"}).Return(nil, errors.New("failed"))
+
+		// create a mock stream
+		stream := new(pbmocks.BankAccountService_GenerateStatementServer[pb.GenerateStatementResponse])
+		stream.On("Context").Return(ctx)
+
+		svc := NewService(new(mocks.Client), statement.NewGenerator(dataStore, "USD"), nil, nil, nil, nil, nil, nil, nil)
+		require.NotNil(t, svc)
+
+		// process the request
+		err := svc.GenerateStatement(rpcReq, stream)
+		assert.Equal(t, codes.Internal, status.Code(err))
+		stream.AssertNotCalled(t, "Send", mock.Anything)
+	})
+	t.Run("With WatchAccount request", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+		accountID := "account-1"
+		hub := subscription.NewHub(10)
+
+		// create the mock CoS client returning the current state
+		cosClient := new(mocks.Client)
+		cosClient.On("GetState", ctx, accountID).
+			Return(&pb.BankAccount{AccountId: accountID, AccountBalance: 100}, &cospb.MetaData{EntityId: accountID, RevisionNumber: 2}, nil)
+
+		// create a mock stream collecting the changes
+		responses := make(chan *pb.WatchAccountResponse, 10)
+		stream := new(pbmocks.BankAccountService_WatchAccountServer[pb.WatchAccountResponse])
+		stream.On("Context").Return(ctx)
+		stream.On("Send", mock.Anything).Run(func(args mock.Arguments) {
+			responses <- args.Get(0).(*pb.WatchAccountResponse)
+		}).Return(nil)
+
+		svc := NewService(cosClient, nil, nil, hub, nil, nil, nil, nil, nil)
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- svc.WatchAccount(&pb.WatchAccountRequest{AccountId: accountID}, stream)
+		}()
+
+		// the current state is streamed first
+		current := <-responses
+		assert.EqualValues(t, 2, current.GetRevisionNumber())
+		assert.EqualValues(t, 100, current.GetAccount().GetAccountBalance())
+		assert.Nil(t, current.GetEvent())
+
+		// publish a change already streamed with the current state and a new change
+		hub.Publish(&subscription.Event{
+			Event:          &pb.AccountCredited{AccountId: accountID, Amount: 100},
+			ResultingState: &pb.BankAccount{AccountId: accountID, AccountBalance: 100},
+			Meta:           &cospb.MetaData{EntityId: accountID, RevisionNumber: 2},
+		})
+		hub.Publish(&subscription.Event{
+			Event:          &pb.AccountDebited{AccountId: accountID, Amount: 40},
+			ResultingState: &pb.BankAccount{AccountId: accountID, AccountBalance: 60},
+			Meta:           &cospb.MetaData{EntityId: accountID, RevisionNumber: 3},
+		})
+
+		change := <-responses
+		assert.EqualValues(t, 3, change.GetRevisionNumber())
+		assert.EqualValues(t, 60, change.GetAccount().GetAccountBalance())
+		require.NotNil(t, change.GetEvent())
+		assert.True(t, change.GetEvent().MessageIs(new(pb.AccountDebited)))
+
+		// stop watching
+		cancel()
+		require.NoError(t, <-errCh)
+		assert.Empty(t, responses)
+		assert.Zero(t, hub.Watchers(accountID))
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With WatchAccount request resuming from the current revision", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.TODO())
+		accountID := "account-1"
+		hub := subscription.NewHub(10)
+
+		cosClient := new(mocks.Client)
+		cosClient.On("GetState", ctx, accountID).
+			Return(&pb.BankAccount{AccountId: accountID}, &cospb.MetaData{EntityId: accountID, RevisionNumber: 2}, nil)
+
+		stream := new(pbmocks.BankAccountService_WatchAccountServer[pb.WatchAccountResponse])
+		stream.On("Context").Return(ctx)
+
+		// the client is already up-to-date
+		cancel()
+		svc := NewService(cosClient, nil, nil, hub, nil, nil, nil, nil, nil)
+		err := svc.WatchAccount(&pb.WatchAccountRequest{AccountId: accountID, FromRevision: 2}, stream)
+		require.NoError(t, err)
+		stream.AssertNotCalled(t, "Send", mock.Anything)
+	})
+	t.Run("With WatchAccount request falling behind", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+		hub := subscription.NewHub(1)
+
+		cosClient := new(mocks.Client)
+		cosClient.On("GetState", ctx, accountID).
+			Return(&pb.BankAccount{AccountId: accountID}, &cospb.MetaData{EntityId: accountID, RevisionNumber: 1}, nil)
+
+		// the stream blocks on the first change until the watcher is evicted
+		release := make(chan struct{})
+		stream := new(pbmocks.BankAccountService_WatchAccountServer[pb.WatchAccountResponse])
+		stream.On("Context").Return(ctx)
+		stream.On("Send", mock.Anything).Return(nil).Once()
+		stream.On("Send", mock.Anything).Run(func(mock.Arguments) { <-release }).Return(nil)
+
+		svc := NewService(cosClient, nil, nil, hub, nil, nil, nil, nil, nil)
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- svc.WatchAccount(&pb.WatchAccountRequest{AccountId: accountID}, stream)
+		}()
+		require.Eventually(t, func() bool { return hub.Watchers(accountID) == 1 }, time.Second, 10*time.Millisecond)
+
+		for revision := int32(2); hub.Watchers(accountID) > 0; revision++ {
+			hub.Publish(&subscription.Event{
+				Event:          &pb.AccountCredited{AccountId: accountID, Amount: 1},
+				ResultingState: &pb.BankAccount{AccountId: accountID},
+				Meta:           &cospb.MetaData{EntityId: accountID, RevisionNumber: revision},
+			})
+		}
+		close(release)
+
+		err := <-errCh
+		require.Error(t, err)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+	t.Run("With WatchAccount request without account id", func(t *testing.T) {
+		stream := new(pbmocks.BankAccountService_WatchAccountServer[pb.WatchAccountResponse])
+		stream.On("Context").Return(context.TODO())
+
+		svc := NewService(new(mocks.Client), nil, nil, subscription.NewHub(10), nil, nil, nil, nil, nil)
+		err := svc.WatchAccount(&pb.WatchAccountRequest{}, stream)
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = the account id is not set")
+	})
+	t.Run("With WatchAccount request and CoS failure", func(t *testing.T) {
+		ctx := context.TODO()
+		hub := subscription.NewHub(10)
+
+		cosClient := new(mocks.Client)
+		cosClient.On("GetState", ctx, "account-1").Return(nil, nil, status.Error(codes.NotFound, "account not found"))
+
+		stream := new(pbmocks.BankAccountService_WatchAccountServer[pb.WatchAccountResponse])
+		stream.On("Context").Return(ctx)
+
+		svc := NewService(cosClient, nil, nil, hub, nil, nil, nil, nil, nil)
+		err := svc.WatchAccount(&pb.WatchAccountRequest{AccountId: "account-1"}, stream)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		// the watcher is released
+		assert.Zero(t, hub.Watchers("account-1"))
+	})
+	t.Run("With WatchAccount request and subscription failure", func(t *testing.T) {
+		ctx := context.TODO()
+
+		subscriber := new(subscriptionmocks.Subscriber)
+		subscriber.On("Subscribe", ctx, "account-1").Return(nil, errors.New("connection refused"))
+
+		stream := new(pbmocks.BankAccountService_WatchAccountServer[pb.WatchAccountResponse])
+		stream.On("Context").Return(ctx)
+
+		svc := NewService(new(mocks.Client), nil, nil, subscriber, nil, nil, nil, nil, nil)
+		err := svc.WatchAccount(&pb.WatchAccountRequest{AccountId: "account-1"}, stream)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		subscriber.AssertExpectations(t)
+	})
+	t.Run("With DebitAccount request with expected revision mismatch", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := uuid.NewString()
+		expectedRevision := int32(2)
+
+		// create the rpc request
+		rpcReq := &pb.DebitAccountRequest{
+			AccountId:        accountID,
+			Amount:           10,
+			ExpectedRevision: &expectedRevision,
+		}
+
+		// the expected revision is forwarded to the write side
+		command := &pb.DebitAccount{
+			AccountId:        accountID,
+			Amount:           10,
+			ExpectedRevision: &expectedRevision,
+		}
+
+		err := status.Error(codes.Aborted, "the account is at revision 3 instead of the expected revision 2")
+		// create a mock cos client
+		cosClient := new(mocks.Client)
+		cosClient.On("ProcessCommand", ctx, accountID, command).Return(nil, nil, err)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil)
+
+		// process the request
+		actual, actualErr := svc.DebitAccount(ctx, rpcReq)
+		assert.Nil(t, actual)
+		assert.Equal(t, codes.Aborted, status.Code(actualErr))
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With BatchGetAccounts request", func(t *testing.T) {
+		ctx := context.TODO()
+		revisionDate := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+		account := &pb.BankAccount{AccountId: "account-1", AccountBalance: 50}
+
+		// create a mock data store
+		dataStore := new(storagemocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "", []string{"account-1", "account-2"}).Return([]*storage.AccountRecord{
+			{Account: account, Revision: storage.Revision{Number: 2, Date: revisionDate}},
+			nil,
+		}, nil)
+		svc := NewService(new(mocks.Client), nil, readmodel.NewReader(dataStore, readmodel.LoadConfig()), nil, nil, nil, nil, nil, nil)
+
+		// create the expected response
+		expected := &pb.BatchGetAccountsResponse{Results: []*pb.BatchGetAccountsResult{
+			{AccountId: "account-1", Account: account, RevisionNumber: 2, RevisionDate: timestamppb.New(revisionDate)},
+			{AccountId: "account-2"},
+		}}
+
+		// process the request
+		actual, err := svc.BatchGetAccounts(ctx, &pb.BatchGetAccountsRequest{AccountIds: []string{"account-1", "account-2"}})
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(expected, actual))
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With BatchGetAccounts request without account ids", func(t *testing.T) {
+		svc := NewService(new(mocks.Client), nil, readmodel.NewReader(new(storagemocks.Storage), readmodel.LoadConfig()), nil, nil, nil, nil, nil, nil)
+		actual, err := svc.BatchGetAccounts(context.TODO(), &pb.BatchGetAccountsRequest{})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = the account ids are not set")
+		assert.Nil(t, actual)
+	})
+	t.Run("With BatchGetAccounts request with too many account ids", func(t *testing.T) {
+		svc := NewService(new(mocks.Client), nil, readmodel.NewReader(new(storagemocks.Storage), readmodel.LoadConfig()), nil, nil, nil, nil, nil, nil)
+		actual, err := svc.BatchGetAccounts(context.TODO(), &pb.BatchGetAccountsRequest{AccountIds: make([]string, MaxBatchSize+1)})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Nil(t, actual)
+	})
+	t.Run("With BatchGetAccounts request and no read model", func(t *testing.T) {
+		svc := NewService(new(mocks.Client), nil, nil, nil, nil, nil, nil, nil, nil)
+		actual, err := svc.BatchGetAccounts(context.TODO(), &pb.BatchGetAccountsRequest{AccountIds: []string{"account-1"}})
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+		assert.Nil(t, actual)
+	})
+	t.Run("With BatchGetAccounts request with data store failure", func(t *testing.T) {
+		dataStore := new(storagemocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "", []string{"account-1"}).Return(nil, errors.New("failed"))
+		svc := NewService(new(mocks.Client), nil, readmodel.NewReader(dataStore, readmodel.LoadConfig()), nil, nil, nil, nil, nil, nil)
+
+		actual, err := svc.BatchGetAccounts(context.TODO(), &pb.BatchGetAccountsRequest{AccountIds: []string{"account-1"}})
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.Nil(t, actual)
+	})
+	t.Run("With BulkPost request", func(t *testing.T) {
+		ctx := context.TODO()
+		cosMeta := &cospb.MetaData{RevisionNumber: 2, RevisionDate: timestamppb.Now()}
+		credited := &pb.BankAccount{AccountId: "account-1", AccountBalance: 110}
+
+		// create a mock cos client succeeding the credit and failing the debit
+		cosClient := new(mocks.Client)
+		cosClient.On("ProcessCommand", ctx, "account-1", mock.AnythingOfType("*accountsv1.CreditAccount")).Return(credited, cosMeta, nil)
+		cosClient.On("ProcessCommand", ctx, "account-2", mock.AnythingOfType("*accountsv1.DebitAccount")).
+			Return(nil, nil, status.Error(codes.FailedPrecondition, "insufficient funds"))
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, nil)
+
+		// create the rpc request
+		rpcReq := &pb.BulkPostRequest{Entries: []*pb.BulkPostEntry{
+			{Command: &pb.BulkPostEntry_Credit{Credit: &pb.CreditAccountRequest{AccountId: "account-1", Amount: 10}}},
+			{Command: &pb.BulkPostEntry_Debit{Debit: &pb.DebitAccountRequest{AccountId: "account-2", Amount: 1000}}},
+			{},
+		}}
+
+		// process the request
+		actual, err := svc.BulkPost(ctx, rpcReq)
+		require.NoError(t, err)
+		require.Len(t, actual.GetResults(), 3)
+
+		// the results are in the order of the entries
+		assert.True(t, proto.Equal(credited, actual.GetResults()[0].GetAccount()))
+		assert.EqualValues(t, 2, actual.GetResults()[0].GetRevisionNumber())
+		assert.Nil(t, actual.GetResults()[0].GetError())
+		assert.Nil(t, actual.GetResults()[1].GetAccount())
+		assert.EqualValues(t, codes.FailedPrecondition, actual.GetResults()[1].GetError().GetCode())
+		assert.Equal(t, "insufficient funds", actual.GetResults()[1].GetError().GetMessage())
+		assert.EqualValues(t, codes.InvalidArgument, actual.GetResults()[2].GetError().GetCode())
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With BulkPost request throttled by account", func(t *testing.T) {
+		ctx := context.TODO()
+		cosMeta := &cospb.MetaData{RevisionNumber: 2, RevisionDate: timestamppb.Now()}
+
+		// create a mock cos client succeeding the credits
+		cosClient := new(mocks.Client)
+		cosClient.On("ProcessCommand", ctx, "account-1", mock.AnythingOfType("*accountsv1.CreditAccount")).Return(new(pb.BankAccount), cosMeta, nil)
+		limiter := ratelimit.NewLimiter(&ratelimit.Config{AccountRate: 0.001, AccountBurst: 2}, ratelimit.NewMemoryBackend())
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, nil, limiter, nil)
+
+		// the entries target the same account beyond its limit
+		entry := &pb.BulkPostEntry{Command: &pb.BulkPostEntry_Credit{Credit: &pb.CreditAccountRequest{AccountId: "account-1", Amount: 10}}}
+		actual, err := svc.BulkPost(ctx, &pb.BulkPostRequest{Entries: []*pb.BulkPostEntry{entry, entry, entry}})
+		require.NoError(t, err)
+		require.Len(t, actual.GetResults(), 3)
+
+		codesByEntry := make(map[codes.Code]int)
+		for _, result := range actual.GetResults() {
+			codesByEntry[codes.Code(result.GetError().GetCode())]++
+		}
+		assert.Equal(t, map[codes.Code]int{codes.OK: 2, codes.ResourceExhausted: 1}, codesByEntry)
+		cosClient.AssertNumberOfCalls(t, "ProcessCommand", 2)
+	})
+	t.Run("With BulkPost request with invalid entries", func(t *testing.T) {
+		ctx := context.TODO()
+		cosMeta := &cospb.MetaData{RevisionNumber: 2, RevisionDate: timestamppb.Now()}
+
+		// create a mock cos client succeeding the credit
+		cosClient := new(mocks.Client)
+		cosClient.On("ProcessCommand", ctx, "account-1", mock.AnythingOfType("*accountsv1.CreditAccount")).Return(new(pb.BankAccount), cosMeta, nil)
+		validator, err := validation.NewValidator()
+		require.NoError(t, err)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, nil, nil, validator)
+
+		// the entries are validated one by one, the invalid ones not reaching CoS
+		rpcReq := &pb.BulkPostRequest{Entries: []*pb.BulkPostEntry{
+			{Command: &pb.BulkPostEntry_Credit{Credit: &pb.CreditAccountRequest{AccountId: "account-1", Amount: 10}}},
+			{Command: &pb.BulkPostEntry_Credit{Credit: &pb.CreditAccountRequest{AccountId: "account-1", Amount: -10}}},
+			{Command: &pb.BulkPostEntry_Debit{Debit: &pb.DebitAccountRequest{AccountId: "account 2", Amount: 10}}},
+		}}
+		actual, err := svc.BulkPost(ctx, rpcReq)
+		require.NoError(t, err)
+		require.Len(t, actual.GetResults(), 3)
+
+		assert.Nil(t, actual.GetResults()[0].GetError())
+		assert.EqualValues(t, codes.InvalidArgument, actual.GetResults()[1].GetError().GetCode())
+		assert.Contains(t, actual.GetResults()[1].GetError().GetMessage(), "amount")
+		assert.EqualValues(t, codes.InvalidArgument, actual.GetResults()[2].GetError().GetCode())
+		assert.Contains(t, actual.GetResults()[2].GetError().GetMessage(), "account_id")
+		cosClient.AssertNumberOfCalls(t, "ProcessCommand", 1)
+	})
+	t.Run("With BulkPost request with too many entries", func(t *testing.T) {
+		svc := NewService(new(mocks.Client), nil, nil, nil, nil, nil, nil, nil, nil)
+		actual, err := svc.BulkPost(context.TODO(), &pb.BulkPostRequest{Entries: make([]*pb.BulkPostEntry, MaxBatchSize+1)})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Nil(t, actual)
+	})
+	t.Run("With BulkPost request without entries", func(t *testing.T) {
+		svc := NewService(new(mocks.Client), nil, nil, nil, nil, nil, nil, nil, nil)
+		actual, err := svc.BulkPost(context.TODO(), &pb.BulkPostRequest{})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = the entries are not set")
+		assert.Nil(t, actual)
+	})
+	t.Run("With ListAuditEntries request", func(t *testing.T) {
+		ctx := context.TODO()
+		revisionDate := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+		records := []*storage.AuditEntry{
+			{EntityID: "account-1", RevisionNumber: 3, RevisionDate: revisionDate, EventType: "accounts.v1.AccountDebited", CallerSubject: "customer-1"},
+			{EntityID: "account-1", RevisionNumber: 4, RevisionDate: revisionDate, EventType: "accounts.v1.AccountCredited"},
+			{EntityID: "account-1", RevisionNumber: 5, RevisionDate: revisionDate, EventType: "accounts.v1.AccountCredited"},
+		}
+
+		// create a mock data store returning one more entry than the page size
+		dataStore := new(storagemocks.Storage)
+		dataStore.On("GetAuditEntries", mock.Anything, "", "account-1", int32(2), 3).Return(records, nil)
+		svc := NewService(new(mocks.Client), nil, readmodel.NewReader(dataStore, readmodel.LoadConfig()), nil, nil, nil, nil, nil, nil)
+
+		// process the request
+		rpcReq := &pb.ListAuditEntriesRequest{Entity: &pb.ListAuditEntriesRequest_AccountId{AccountId: "account-1"}, PageSize: 2, PageToken: "2"}
+		actual, err := svc.ListAuditEntries(ctx, rpcReq)
+		require.NoError(t, err)
+		require.Len(t, actual.GetEntries(), 2)
+		assert.EqualValues(t, 3, actual.GetEntries()[0].GetRevisionNumber())
+		assert.Equal(t, "customer-1", actual.GetEntries()[0].GetCaller().GetSubject())
+		assert.Nil(t, actual.GetEntries()[1].GetCaller())
+		assert.Equal(t, "4", actual.GetNextPageToken())
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With ListAuditEntries request on the last page", func(t *testing.T) {
+		records := []*storage.AuditEntry{{EntityID: "order-1", RevisionNumber: 1, EventType: "accounts.v1.StandingOrderCreated"}}
+		dataStore := new(storagemocks.Storage)
+		dataStore.On("GetAuditEntries", mock.Anything, "", "order-1", int32(0), DefaultAuditPageSize+1).Return(records, nil)
+		svc := NewService(new(mocks.Client), nil, readmodel.NewReader(dataStore, readmodel.LoadConfig()), nil, nil, nil, nil, nil, nil)
+
+		actual, err := svc.ListAuditEntries(context.TODO(), &pb.ListAuditEntriesRequest{Entity: &pb.ListAuditEntriesRequest_OrderId{OrderId: "order-1"}})
+		require.NoError(t, err)
+		require.Len(t, actual.GetEntries(), 1)
+		assert.Empty(t, actual.GetNextPageToken())
+	})
+	t.Run("With ListAuditEntries request without entity", func(t *testing.T) {
+		svc := NewService(new(mocks.Client), nil, readmodel.NewReader(new(storagemocks.Storage), readmodel.LoadConfig()), nil, nil, nil, nil, nil, nil)
+		actual, err := svc.ListAuditEntries(context.TODO(), &pb.ListAuditEntriesRequest{})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = the account id or the standing order id is not set")
+		assert.Nil(t, actual)
+	})
+	t.Run("With ListAuditEntries request with invalid page", func(t *testing.T) {
+		svc := NewService(new(mocks.Client), nil, readmodel.NewReader(new(storagemocks.Storage), readmodel.LoadConfig()), nil, nil, nil, nil, nil, nil)
+		entity := &pb.ListAuditEntriesRequest_AccountId{AccountId: "account-1"}
+
+		actual, err := svc.ListAuditEntries(context.TODO(), &pb.ListAuditEntriesRequest{Entity: entity, PageSize: MaxAuditPageSize + 1})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Nil(t, actual)
+
+		actual, err = svc.ListAuditEntries(context.TODO(), &pb.ListAuditEntriesRequest{Entity: entity, PageToken: "next"})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = the page token is invalid")
+		assert.Nil(t, actual)
+	})
+	t.Run("With ListAuditEntries request and no read model", func(t *testing.T) {
+		svc := NewService(new(mocks.Client), nil, nil, nil, nil, nil, nil, nil, nil)
+		actual, err := svc.ListAuditEntries(context.TODO(), &pb.ListAuditEntriesRequest{Entity: &pb.ListAuditEntriesRequest_AccountId{AccountId: "account-1"}})
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+		assert.Nil(t, actual)
+	})
+	t.Run("With ListAuditEntries request with data store failure", func(t *testing.T) {
+		dataStore := new(storagemocks.Storage)
+		dataStore.On("GetAuditEntries", mock.Anything, "", "account-1", int32(0), DefaultAuditPageSize+1).Return(nil, errors.New("failed"))
+		svc := NewService(new(mocks.Client), nil, readmodel.NewReader(dataStore, readmodel.LoadConfig()), nil, nil, nil, nil, nil, nil)
+
+		actual, err := svc.ListAuditEntries(context.TODO(), &pb.ListAuditEntriesRequest{Entity: &pb.ListAuditEntriesRequest_AccountId{AccountId: "account-1"}})
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.Nil(t, actual)
+	})
+}
+
+func TestServiceAuthorization(t *testing.T) {
+	customer := &auth.Principal{Subject: "John Doe", Roles: []auth.Role{auth.RoleCustomer}}
+	operator := &auth.Principal{Subject: "operator-1", Roles: []auth.Role{auth.RoleOperator}}
+	auditor := &auth.Principal{Subject: "auditor-1", Roles: []auth.Role{auth.RoleAuditor}}
+	ownAccount := &pb.BankAccount{AccountId: "account-1", AccountBalance: 100, AccountOwner: "John Doe"}
+	otherAccount := &pb.BankAccount{AccountId: "account-2", AccountBalance: 100, AccountOwner: "Jane Doe"}
+	cosMeta := &cospb.MetaData{RevisionNumber: 2}
+
+	t.Run("With request without principal", func(t *testing.T) {
+		cosClient := new(mocks.Client)
+		svc := NewService(cosClient, nil, nil, nil, auth.NewAuthorizer(), nil, nil, nil, nil)
+
+		actual, err := svc.OpenAccount(context.TODO(), &pb.OpenAccountRequest{AccountOwner: "John Doe", Balance: 500})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.Nil(t, actual)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With OpenAccount request by customer", func(t *testing.T) {
+		ctx := auth.NewContext(context.TODO(), customer)
+		cosClient := new(mocks.Client)
+		cosClient.On("ProcessCommand", ctx, "account-1", mock.AnythingOfType("*accountsv1.OpenAccount")).Return(ownAccount, cosMeta, nil)
+		svc := NewService(cosClient, nil, nil, nil, auth.NewAuthorizer(), nil, nil, nil, nil)
+
+		// customers open their own accounts
+		accountID := "account-1"
+		actual, err := svc.OpenAccount(ctx, &pb.OpenAccountRequest{AccountId: &accountID, AccountOwner: "John Doe", Balance: 500})
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(ownAccount, actual.GetAccount()))
+
+		// customers cannot open the accounts of other owners
+		actual, err = svc.OpenAccount(ctx, &pb.OpenAccountRequest{AccountOwner: "Jane Doe", Balance: 500})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Nil(t, actual)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With DebitAccount request by customer", func(t *testing.T) {
+		ctx := auth.NewContext(context.TODO(), customer)
+		cosClient := new(mocks.Client)
+		cosClient.On("GetState", ctx, "account-1").Return(ownAccount, cosMeta, nil)
+		cosClient.On("GetState", ctx, "account-2").Return(otherAccount, cosMeta, nil)
+		cosClient.On("ProcessCommand", ctx, "account-1", mock.AnythingOfType("*accountsv1.DebitAccount")).Return(ownAccount, cosMeta, nil).Once()
+		svc := NewService(cosClient, nil, nil, nil, auth.NewAuthorizer(), nil, nil, nil, nil)
+
+		// customers debit their own accounts
+		_, err := svc.DebitAccount(ctx, &pb.DebitAccountRequest{AccountId: "account-1", Amount: 10})
+		require.NoError(t, err)
+
+		// customers cannot debit the accounts of other owners
+		actual, err := svc.DebitAccount(ctx, &pb.DebitAccountRequest{AccountId: "account-2", Amount: 10})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Nil(t, actual)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With DebitAccount request by customer for unknown account", func(t *testing.T) {
+		ctx := auth.NewContext(context.TODO(), customer)
+		cosClient := new(mocks.Client)
+		cosClient.On("GetState", ctx, "account-3").Return(nil, nil, status.Error(codes.NotFound, "not found"))
+		svc := NewService(cosClient, nil, nil, nil, auth.NewAuthorizer(), nil, nil, nil, nil)
+
+		actual, err := svc.DebitAccount(ctx, &pb.DebitAccountRequest{AccountId: "account-3", Amount: 10})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Nil(t, actual)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With CreditAccount request by customer", func(t *testing.T) {
+		// the account owner is not looked up since customers cannot credit any account
+		cosClient := new(mocks.Client)
+		svc := NewService(cosClient, nil, nil, nil, auth.NewAuthorizer(), nil, nil, nil, nil)
+
+		actual, err := svc.CreditAccount(auth.NewContext(context.TODO(), customer), &pb.CreditAccountRequest{AccountId: "account-1", Amount: 10})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Nil(t, actual)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With CreditAccount request by operator", func(t *testing.T) {
+		// the account owner is not looked up since operators can credit every account
+		ctx := auth.NewContext(context.TODO(), operator)
+		cosClient := new(mocks.Client)
+		cosClient.On("ProcessCommand", ctx, "account-2", mock.AnythingOfType("*accountsv1.CreditAccount")).Return(otherAccount, cosMeta, nil)
+		svc := NewService(cosClient, nil, nil, nil, auth.NewAuthorizer(), nil, nil, nil, nil)
+
+		_, err := svc.CreditAccount(ctx, &pb.CreditAccountRequest{AccountId: "account-2", Amount: 10})
+		require.NoError(t, err)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With GetAccount request", func(t *testing.T) {
+		cosClient := new(mocks.Client)
+		cosClient.On("GetState", mock.Anything, "account-2").Return(otherAccount, cosMeta, nil)
+		svc := NewService(cosClient, nil, nil, nil, auth.NewAuthorizer(), nil, nil, nil, nil)
+
+		// auditors read every account
+		actual, err := svc.GetAccount(auth.NewContext(context.TODO(), auditor), &pb.GetAccountRequest{AccountId: "account-2"})
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(otherAccount, actual.GetAccount()))
+
+		// customers cannot read the accounts of other owners
+		actual, err = svc.GetAccount(auth.NewContext(context.TODO(), customer), &pb.GetAccountRequest{AccountId: "account-2"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Nil(t, actual)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With DebitAccount request by auditor", func(t *testing.T) {
+		cosClient := new(mocks.Client)
+		svc := NewService(cosClient, nil, nil, nil, auth.NewAuthorizer(), nil, nil, nil, nil)
+
+		actual, err := svc.DebitAccount(auth.NewContext(context.TODO(), auditor), &pb.DebitAccountRequest{AccountId: "account-1", Amount: 10})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Nil(t, actual)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With BatchGetAccounts request by customer", func(t *testing.T) {
+		dataStore := new(storagemocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "", []string{"account-1", "account-2"}).Return([]*storage.AccountRecord{
+			{Account: ownAccount},
+			{Account: otherAccount},
+		}, nil)
+		svc := NewService(new(mocks.Client), nil, readmodel.NewReader(dataStore, readmodel.LoadConfig()), nil, auth.NewAuthorizer(), nil, nil, nil, nil)
+
+		// the accounts of other owners are left unset
+		expected := &pb.BatchGetAccountsResponse{Results: []*pb.BatchGetAccountsResult{
+			{AccountId: "account-1", Account: ownAccount},
+			{AccountId: "account-2"},
+		}}
+
+		actual, err := svc.BatchGetAccounts(auth.NewContext(context.TODO(), customer), &pb.BatchGetAccountsRequest{AccountIds: []string{"account-1", "account-2"}})
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(expected, actual))
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With PauseStandingOrder request by customer", func(t *testing.T) {
+		ctx := auth.NewContext(context.TODO(), customer)
+		standingOrder := &pb.StandingOrder{OrderId: "order-1", AccountId: "account-2"}
+
+		cosClient := new(mocks.Client)
+		cosClient.On("GetStandingOrder", ctx, "order-1").Return(standingOrder, cosMeta, nil)
+		cosClient.On("GetState", ctx, "account-2").Return(otherAccount, cosMeta, nil)
+		svc := NewService(cosClient, nil, nil, nil, auth.NewAuthorizer(), nil, nil, nil, nil)
+
+		// customers cannot manage the standing orders of other owners
+		actual, err := svc.PauseStandingOrder(ctx, &pb.PauseStandingOrderRequest{OrderId: "order-1"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Nil(t, actual)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With WatchAccount request by customer", func(t *testing.T) {
+		ctx := auth.NewContext(context.TODO(), customer)
+		cosClient := new(mocks.Client)
+		cosClient.On("GetState", ctx, "account-2").Return(otherAccount, cosMeta, nil)
+
+		stream := new(pbmocks.BankAccountService_WatchAccountServer[pb.WatchAccountResponse])
+		stream.On("Context").Return(ctx)
+
+		// the account is not watched
+		subscriber := new(subscriptionmocks.Subscriber)
+		svc := NewService(cosClient, nil, nil, subscriber, auth.NewAuthorizer(), nil, nil, nil, nil)
+
+		err := svc.WatchAccount(&pb.WatchAccountRequest{AccountId: "account-2"}, stream)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		subscriber.AssertExpectations(t)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With ListAuditEntries request by customer", func(t *testing.T) {
+		ctx := auth.NewContext(context.TODO(), customer)
+		cosClient := new(mocks.Client)
+		cosClient.On("GetState", ctx, "account-2").Return(otherAccount, cosMeta, nil)
+		dataStore := new(storagemocks.Storage)
+		svc := NewService(cosClient, nil, readmodel.NewReader(dataStore, readmodel.LoadConfig()), nil, auth.NewAuthorizer(), nil, nil, nil, nil)
+
+		// customers cannot read the audit trail of the accounts of other owners
+		actual, err := svc.ListAuditEntries(ctx, &pb.ListAuditEntriesRequest{Entity: &pb.ListAuditEntriesRequest_AccountId{AccountId: "account-2"}})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Nil(t, actual)
+		dataStore.AssertNotCalled(t, "GetAuditEntries", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With BulkPost request by auditor", func(t *testing.T) {
+		svc := NewService(new(mocks.Client), nil, nil, nil, auth.NewAuthorizer(), nil, nil, nil, nil)
+
+		// every entry is denied on its own
+		actual, err := svc.BulkPost(auth.NewContext(context.TODO(), auditor), &pb.BulkPostRequest{Entries: []*pb.BulkPostEntry{
+			{Command: &pb.BulkPostEntry_Credit{Credit: &pb.CreditAccountRequest{AccountId: "account-1", Amount: 10}}},
+			{Command: &pb.BulkPostEntry_Debit{Debit: &pb.DebitAccountRequest{AccountId: "account-2", Amount: 10}}},
+		}})
+		require.NoError(t, err)
+		require.Len(t, actual.GetResults(), 2)
+		for _, result := range actual.GetResults() {
+			assert.EqualValues(t, codes.PermissionDenied, result.GetError().GetCode())
+		}
+	})
+}
+
+// newTestCipher creates a cipher holding its keys in a temporary directory
+func newTestCipher(t *testing.T) *pii.Cipher {
+	keyStore, err := pii.NewLocalKeyStore(t.TempDir())
+	require.NoError(t, err)
+	return pii.NewCipher(keyStore)
+}
+
+func TestServiceEncryption(t *testing.T) {
+	operator := &auth.Principal{Subject: "operator-1", Roles: []auth.Role{auth.RoleOperator}}
+	customer := &auth.Principal{Subject: "John Doe", Roles: []auth.Role{auth.RoleCustomer}}
+	cosMeta := &cospb.MetaData{RevisionNumber: 2}
+
+	t.Run("With OpenAccount request", func(t *testing.T) {
+		ctx := context.TODO()
+		cipher := newTestCipher(t)
+		accountID := "account-1"
+
+		// the command carries the encrypted owner and so does the resulting state
+		var encryptedOwner string
+		isEncrypted := mock.MatchedBy(func(command *pb.OpenAccount) bool {
+			encryptedOwner = command.GetAccountOwner()
+			return pii.IsEncrypted(encryptedOwner)
+		})
+		cosClient := new(mocks.Client)
+		cosClient.On("ProcessCommand", ctx, accountID, isEncrypted).
+			Return(func(context.Context, string, proto.Message) *pb.BankAccount {
+				return &pb.BankAccount{AccountId: accountID, AccountBalance: 500, AccountOwner: encryptedOwner}
+			}, cosMeta, nil)
+		svc := NewService(cosClient, nil, nil, nil, nil, cipher, nil, nil, nil)
+
+		actual, err := svc.OpenAccount(ctx, &pb.OpenAccountRequest{AccountId: &accountID, AccountOwner: "John Doe", Balance: 500})
+		require.NoError(t, err)
+		assert.Equal(t, "John Doe", actual.GetAccount().GetAccountOwner())
+		assert.NotContains(t, encryptedOwner, "John")
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With GetAccount request", func(t *testing.T) {
+		ctx := auth.NewContext(context.TODO(), customer)
+		cipher := newTestCipher(t)
+		encryptedOwner, err := cipher.Encrypt(ctx, "John Doe", "John Doe")
+		require.NoError(t, err)
+		state := &pb.BankAccount{AccountId: "account-1", AccountBalance: 500, AccountOwner: encryptedOwner}
+
+		cosClient := new(mocks.Client)
+		cosClient.On("GetState", ctx, "account-1").Return(state, cosMeta, nil)
+		svc := NewService(cosClient, nil, nil, nil, auth.NewAuthorizer(), cipher, nil, nil, nil)
+
+		// the customers are authorized against their decrypted name
+		actual, err := svc.GetAccount(ctx, &pb.GetAccountRequest{AccountId: "account-1", Consistency: pb.Consistency_STRONG})
+		require.NoError(t, err)
+		assert.Equal(t, "John Doe", actual.GetAccount().GetAccountOwner())
+
+		// the erased owners are left empty
+		require.NoError(t, cipher.Erase(ctx, "John Doe"))
+		operatorCtx := auth.NewContext(context.TODO(), operator)
+		cosClient.On("GetState", operatorCtx, "account-1").Return(state, cosMeta, nil)
+		actual, err = svc.GetAccount(operatorCtx, &pb.GetAccountRequest{AccountId: "account-1", Consistency: pb.Consistency_STRONG})
+		require.NoError(t, err)
+		assert.Empty(t, actual.GetAccount().GetAccountOwner())
+		assert.EqualValues(t, 500, actual.GetAccount().GetAccountBalance())
+		// the state is left untouched
+		assert.Equal(t, encryptedOwner, state.GetAccountOwner())
+	})
+	t.Run("With EraseCustomer request", func(t *testing.T) {
+		ctx := auth.NewContext(context.TODO(), operator)
+		cipher := newTestCipher(t)
+		encryptedOwner, err := cipher.Encrypt(ctx, "John Doe", "John Doe")
+		require.NoError(t, err)
+
+		dataStore := new(storagemocks.Storage)
+		dataStore.On("EraseAccountOwner", mock.Anything, "", "John Doe").Return([]string{"account-1", "account-3"}, nil)
+		svc := NewService(new(mocks.Client), nil, readmodel.NewReader(dataStore, readmodel.LoadConfig()), nil, auth.NewAuthorizer(), cipher, nil, nil, nil)
+
+		actual, err := svc.EraseCustomer(ctx, &pb.EraseCustomerRequest{AccountOwner: "John Doe"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"account-1", "account-3"}, actual.GetErasedAccountIds())
+
+		// the owner cannot be decrypted anymore
+		_, err = cipher.Decrypt(ctx, encryptedOwner)
+		assert.ErrorIs(t, err, pii.ErrErased)
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With EraseCustomer request by customer", func(t *testing.T) {
+		cipher := newTestCipher(t)
+		ctx := auth.NewContext(context.TODO(), customer)
+		encryptedOwner, err := cipher.Encrypt(ctx, "John Doe", "John Doe")
+		require.NoError(t, err)
+		svc := NewService(new(mocks.Client), nil, nil, nil, auth.NewAuthorizer(), cipher, nil, nil, nil)
+
+		// customers cannot erase themselves
+		actual, err := svc.EraseCustomer(ctx, &pb.EraseCustomerRequest{AccountOwner: "John Doe"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Nil(t, actual)
+
+		owner, err := cipher.Decrypt(ctx, encryptedOwner)
+		require.NoError(t, err)
+		assert.Equal(t, "John Doe", owner)
+	})
+	t.Run("With EraseCustomer request without owner", func(t *testing.T) {
+		svc := NewService(new(mocks.Client), nil, nil, nil, nil, newTestCipher(t), nil, nil, nil)
+
+		actual, err := svc.EraseCustomer(context.TODO(), new(pb.EraseCustomerRequest))
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Nil(t, actual)
+	})
+	t.Run("With EraseCustomer request and encryption not enabled", func(t *testing.T) {
+		svc := NewService(new(mocks.Client), nil, nil, nil, nil, nil, nil, nil, nil)
+
+		actual, err := svc.EraseCustomer(context.TODO(), &pb.EraseCustomerRequest{AccountOwner: "John Doe"})
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+		assert.Nil(t, actual)
+	})
+	t.Run("With EraseCustomer request and data store failure", func(t *testing.T) {
+		dataStore := new(storagemocks.Storage)
+		dataStore.On("EraseAccountOwner", mock.Anything, "", "John Doe").Return(nil, errors.New("connection refused"))
+		svc := NewService(new(mocks.Client), nil, readmodel.NewReader(dataStore, readmodel.LoadConfig()), nil, nil, newTestCipher(t), nil, nil, nil)
+
+		actual, err := svc.EraseCustomer(context.TODO(), &pb.EraseCustomerRequest{AccountOwner: "John Doe"})
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.Nil(t, actual)
+	})
+}
+
+func TestServiceTenancy(t *testing.T) {
+	tenants := tenant.NewResolver("acme", "globex")
+	cosMeta := &cospb.MetaData{RevisionNumber: 2}
+
+	t.Run("With request without tenant", func(t *testing.T) {
+		cosClient := new(mocks.Client)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, tenants, nil, nil)
+
+		actual, err := svc.GetAccount(context.TODO(), &pb.GetAccountRequest{AccountId: "account-1", Consistency: pb.Consistency_STRONG})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Nil(t, actual)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With OpenAccount request", func(t *testing.T) {
+		ctx := tenant.NewContext(context.TODO(), "acme")
+		accountID := "account-1"
+		state := &pb.BankAccount{AccountId: "acme/account-1", AccountBalance: 500, AccountOwner: "John Doe", TenantId: "acme"}
+
+		// the account is namespaced by the tenant in CoS
+		command := &pb.OpenAccount{AccountId: "acme/account-1", AccountOwner: "John Doe", OpeningBalance: 500, TenantId: "acme"}
+		cosClient := new(mocks.Client)
+		cosClient.On("ProcessCommand", ctx, "acme/account-1", command).Return(state, cosMeta, nil)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, tenants, nil, nil)
+
+		// the account is returned with the id known by the tenant
+		actual, err := svc.OpenAccount(ctx, &pb.OpenAccountRequest{AccountId: &accountID, AccountOwner: "John Doe", Balance: 500})
+		require.NoError(t, err)
+		assert.Equal(t, "account-1", actual.GetAccount().GetAccountId())
+		assert.Equal(t, "acme/account-1", state.GetAccountId())
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With CreateStandingOrder request", func(t *testing.T) {
+		ctx := tenant.NewContext(context.TODO(), "acme")
+		orderID := "order-1"
+		state := &pb.StandingOrder{
+			OrderId:              "acme/order-1",
+			AccountId:            "acme/account-1",
+			BeneficiaryAccountId: "acme/account-2",
+			Amount:               50,
+			TenantId:             "acme",
+		}
+
+		cosClient := new(mocks.Client)
+		cosClient.
+			On("ProcessStandingOrderCommand", ctx, "acme/order-1", mock.MatchedBy(func(command *pb.CreateStandingOrder) bool {
+				return command.GetAccountId() == "acme/account-1" && command.GetBeneficiaryAccountId() == "acme/account-2" && command.GetTenantId() == "acme"
+			})).
+			Return(state, cosMeta, nil)
+		svc := NewService(cosClient, nil, nil, nil, nil, nil, tenants, nil, nil)
+
+		actual, err := svc.CreateStandingOrder(ctx, &pb.CreateStandingOrderRequest{
+			OrderId:              &orderID,
+			AccountId:            "account-1",
+			BeneficiaryAccountId: "account-2",
+			Amount:               50,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "order-1", actual.GetStandingOrder().GetOrderId())
+		assert.Equal(t, "account-1", actual.GetStandingOrder().GetAccountId())
+		assert.Equal(t, "account-2", actual.GetStandingOrder().GetBeneficiaryAccountId())
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With caller of another tenant", func(t *testing.T) {
+		principal := &auth.Principal{Subject: "John Doe", Roles: []auth.Role{auth.RoleCustomer}, Tenant: "globex"}
+		ctx := tenant.NewContext(auth.NewContext(context.TODO(), principal), "acme")
+		cosClient := new(mocks.Client)
+		svc := NewService(cosClient, nil, nil, nil, auth.NewAuthorizer(), nil, tenants, nil, nil)
+
+		actual, err := svc.GetAccount(ctx, &pb.GetAccountRequest{AccountId: "account-1", Consistency: pb.Consistency_STRONG})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Nil(t, actual)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With BatchGetAccounts request", func(t *testing.T) {
+		ctx := tenant.NewContext(context.TODO(), "globex")
+		record := &storage.AccountRecord{
+			Account:  &pb.BankAccount{AccountId: "globex/account-1", AccountBalance: 100, TenantId: "globex"},
+			Revision: storage.Revision{Number: 3},
+		}
+
+		// the accounts are read within the tenant only
+		dataStore := new(storagemocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "globex", []string{"globex/account-1", "globex/account-2"}).Return([]*storage.AccountRecord{record, nil}, nil)
+		svc := NewService(new(mocks.Client), nil, readmodel.NewReader(dataStore, readmodel.LoadConfig()), nil, nil, nil, tenants, nil, nil)
+
+		actual, err := svc.BatchGetAccounts(ctx, &pb.BatchGetAccountsRequest{AccountIds: []string{"account-1", "account-2"}})
+		require.NoError(t, err)
+		require.Len(t, actual.GetResults(), 2)
+		assert.Equal(t, "account-1", actual.GetResults()[0].GetAccountId())
+		assert.Equal(t, "account-1", actual.GetResults()[0].GetAccount().GetAccountId())
+		assert.Equal(t, "account-2", actual.GetResults()[1].GetAccountId())
+		assert.Nil(t, actual.GetResults()[1].GetAccount())
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With ListAuditEntries request", func(t *testing.T) {
+		ctx := tenant.NewContext(context.TODO(), "acme")
+		records := []*storage.AuditEntry{{EntityID: "acme/account-1", TenantID: "acme", RevisionNumber: 1, EventType: "accounts.v1.AccountOpened"}}
+
+		// the audit trail is read within the tenant only
+		dataStore := new(storagemocks.Storage)
+		dataStore.On("GetAuditEntries", mock.Anything, "acme", "acme/account-1", int32(0), DefaultAuditPageSize+1).Return(records, nil)
+		svc := NewService(new(mocks.Client), nil, readmodel.NewReader(dataStore, readmodel.LoadConfig()), nil, nil, nil, tenants, nil, nil)
+
+		actual, err := svc.ListAuditEntries(ctx, &pb.ListAuditEntriesRequest{Entity: &pb.ListAuditEntriesRequest_AccountId{AccountId: "account-1"}})
+		require.NoError(t, err)
+		require.Len(t, actual.GetEntries(), 1)
+		assert.EqualValues(t, 1, actual.GetEntries()[0].GetRevisionNumber())
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With EraseCustomer request", func(t *testing.T) {
+		cipher := newTestCipher(t)
+		acmeCtx := tenant.NewContext(context.TODO(), "acme")
+		globexCtx := tenant.NewContext(context.TODO(), "globex")
+
+		// the customers of the tenants have their own data keys
+		acmeOwner, err := cipher.Encrypt(acmeCtx, tenant.EntityID("acme", "John Doe"), "John Doe")
+		require.NoError(t, err)
+		globexOwner, err := cipher.Encrypt(globexCtx, tenant.EntityID("globex", "John Doe"), "John Doe")
+		require.NoError(t, err)
+
+		dataStore := new(storagemocks.Storage)
+		dataStore.On("EraseAccountOwner", mock.Anything, "acme", "John Doe").Return([]string{"acme/account-1"}, nil)
+		svc := NewService(new(mocks.Client), nil, readmodel.NewReader(dataStore, readmodel.LoadConfig()), nil, nil, cipher, tenants, nil, nil)
+
+		actual, err := svc.EraseCustomer(acmeCtx, &pb.EraseCustomerRequest{AccountOwner: "John Doe"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"account-1"}, actual.GetErasedAccountIds())
+
+		// the customer is only erased from the tenant
+		_, err = cipher.Decrypt(acmeCtx, acmeOwner)
+		assert.ErrorIs(t, err, pii.ErrErased)
+		owner, err := cipher.Decrypt(globexCtx, globexOwner)
+		require.NoError(t, err)
+		assert.Equal(t, "John Doe", owner)
+		dataStore.AssertExpectations(t)
+	})
 }
@@ -0,0 +1,161 @@
+package statement
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"time"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// camt053Namespace is the namespace of the ISO 20022 Bank To Customer Statement version rendered
+const camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.08"
+
+// the ISO 20022 codes used in the statement
+const (
+	openingBalanceCode = "OPBD"
+	closingBalanceCode = "CLBD"
+	creditCode         = "CRDT"
+	debitCode          = "DBIT"
+	bookedCode         = "BOOK"
+	dateLayout         = "2006-01-02"
+)
+
+type camt053Document struct {
+	XMLName   xml.Name         `xml:"Document"`
+	Namespace string           `xml:"xmlns,attr"`
+	Statement camt053Statement `xml:"BkToCstmrStmt"`
+}
+
+type camt053Statement struct {
+	GroupHeader camt053GroupHeader `xml:"GrpHdr"`
+	Stmt        camt053Stmt        `xml:"Stmt"`
+}
+
+type camt053GroupHeader struct {
+	MessageID        string `xml:"MsgId"`
+	CreationDateTime string `xml:"CreDtTm"`
+}
+
+type camt053Stmt struct {
+	ID               string           `xml:"Id"`
+	CreationDateTime string           `xml:"CreDtTm"`
+	Period           camt053Period    `xml:"FrToDt"`
+	Account          camt053Account   `xml:"Acct"`
+	Balances         []camt053Balance `xml:"Bal"`
+	Entries          []camt053Entry   `xml:"Ntry"`
+}
+
+type camt053Period struct {
+	From string `xml:"FrDtTm"`
+	To   string `xml:"ToDtTm"`
+}
+
+type camt053Account struct {
+	ID    string `xml:"Id>Othr>Id"`
+	Owner string `xml:"Ownr>Nm,omitempty"`
+}
+
+type camt053Amount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camt053Balance struct {
+	Code                 string        `xml:"Tp>CdOrPrtry>Cd"`
+	Amount               camt053Amount `xml:"Amt"`
+	CreditDebitIndicator string        `xml:"CdtDbtInd"`
+	Date                 string        `xml:"Dt>Dt"`
+}
+
+type camt053Entry struct {
+	Reference            string        `xml:"NtryRef"`
+	Amount               camt053Amount `xml:"Amt"`
+	CreditDebitIndicator string        `xml:"CdtDbtInd"`
+	Status               string        `xml:"Sts>Cd"`
+	BookingDate          string        `xml:"BookgDt>DtTm"`
+	ValueDate            string        `xml:"ValDt>DtTm"`
+	BankTransactionCode  string        `xml:"BkTxCd>Prtry>Cd"`
+	AdditionalInfo       string        `xml:"AddtlNtryInf,omitempty"`
+}
+
+// renderCamt053 writes the statement as an ISO 20022 camt.053 XML document
+func renderCamt053(w io.Writer, statement *pb.Statement) error {
+	generatedAt := formatTime(statement.GetGeneratedAt().AsTime())
+	openingDate := statement.GetStartTime().AsTime().UTC().Format(dateLayout)
+	// the period end is exclusive, hence the closing balance is dated the day before
+	closingDate := statement.GetEndTime().AsTime().Add(-time.Nanosecond).UTC().Format(dateLayout)
+	statementID := statement.GetAccountId() + "-" + openingDate
+
+	// build the statement entries
+	entries := make([]camt053Entry, 0, len(statement.GetTransactions()))
+	for _, transaction := range statement.GetTransactions() {
+		indicator := debitCode
+		if isCredit(transaction) {
+			indicator = creditCode
+		}
+
+		bookedAt := formatTime(transaction.GetBookedAt().AsTime())
+		entries = append(entries, camt053Entry{
+			Reference:            reference(transaction),
+			Amount:               camt053Amount{Currency: statement.GetCurrency(), Value: formatAmount(transaction.GetAmount())},
+			CreditDebitIndicator: indicator,
+			Status:               bookedCode,
+			BookingDate:          bookedAt,
+			ValueDate:            bookedAt,
+			BankTransactionCode:  transaction.GetType().String(),
+			AdditionalInfo:       transaction.GetDescription(),
+		})
+	}
+
+	document := camt053Document{
+		Namespace: camt053Namespace,
+		Statement: camt053Statement{
+			GroupHeader: camt053GroupHeader{
+				MessageID:        statementID,
+				CreationDateTime: generatedAt,
+			},
+			Stmt: camt053Stmt{
+				ID:               statementID,
+				CreationDateTime: generatedAt,
+				Period: camt053Period{
+					From: formatTime(statement.GetStartTime().AsTime()),
+					To:   formatTime(statement.GetEndTime().AsTime()),
+				},
+				Account: camt053Account{
+					ID:    statement.GetAccountId(),
+					Owner: statement.GetAccountOwner(),
+				},
+				Balances: []camt053Balance{
+					balance(openingBalanceCode, statement.GetOpeningBalance(), statement.GetCurrency(), openingDate),
+					balance(closingBalanceCode, statement.GetClosingBalance(), statement.GetCurrency(), closingDate),
+				},
+				Entries: entries,
+			},
+		},
+	}
+
+	// write the XML declaration and the document
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(document)
+}
+
+// balance builds a camt.053 balance. ISO 20022 amounts are unsigned, the sign is set by the credit debit indicator
+func balance(code string, amount float64, currency, date string) camt053Balance {
+	indicator := creditCode
+	if amount < 0 {
+		indicator = debitCode
+	}
+
+	return camt053Balance{
+		Code:                 code,
+		Amount:               camt053Amount{Currency: currency, Value: formatAmount(math.Abs(amount))},
+		CreditDebitIndicator: indicator,
+		Date:                 date,
+	}
+}
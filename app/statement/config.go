@@ -0,0 +1,23 @@
+package statement
+
+import (
+	"github.com/caarlos0/env/v9"
+	"github.com/pkg/errors"
+)
+
+// Config defines the statements config
+type Config struct {
+	Currency string `env:"STATEMENT_CURRENCY" envDefault:"USD"` // Currency is the ISO 4217 currency code of the statements amounts
+}
+
+// LoadConfig fetches the Config from env vars
+func LoadConfig() *Config {
+	config := &Config{}
+	// all env vars are required
+	opts := env.Options{RequiredIfNoDef: true}
+	if err := env.ParseWithOptions(config, opts); err != nil {
+		panic(errors.Wrap(err, "unable to load environment variables"))
+	}
+
+	return config
+}
@@ -0,0 +1,24 @@
+package statement
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("With default values", func(t *testing.T) {
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.Equal(t, "USD", actual.Currency)
+	})
+	t.Run("With currency set", func(t *testing.T) {
+		assert.NoError(t, os.Setenv("STATEMENT_CURRENCY", "EUR"))
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.Equal(t, "EUR", actual.Currency)
+		assert.NoError(t, os.Unsetenv("STATEMENT_CURRENCY"))
+	})
+}
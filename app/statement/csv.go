@@ -0,0 +1,59 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// renderCSV writes the statement as CSV. The first and last rows hold the opening and closing balances,
+// the rows in between hold the transactions with signed amounts
+func renderCSV(w io.Writer, statement *pb.Statement) error {
+	writer := csv.NewWriter(w)
+
+	// write the header and the opening balance
+	rows := [][]string{
+		{"booked_at", "reference", "type", "description", "amount", "balance"},
+		{formatTime(statement.GetStartTime().AsTime()), "", "OPENING_BALANCE", "", "", formatAmount(statement.GetOpeningBalance())},
+	}
+
+	// write the transactions
+	for _, transaction := range statement.GetTransactions() {
+		amount := transaction.GetAmount()
+		if !isCredit(transaction) {
+			amount = -amount
+		}
+
+		rows = append(rows, []string{
+			formatTime(transaction.GetBookedAt().AsTime()),
+			reference(transaction),
+			transaction.GetType().String(),
+			transaction.GetDescription(),
+			formatAmount(amount),
+			formatAmount(transaction.GetBalanceAfter()),
+		})
+	}
+
+	// write the closing balance
+	rows = append(rows, []string{formatTime(statement.GetEndTime().AsTime()), "", "CLOSING_BALANCE", "", "", formatAmount(statement.GetClosingBalance())})
+
+	return writer.WriteAll(rows)
+}
+
+// formatAmount formats the given amount with two decimals
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// formatTime formats the given time in UTC
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// reference returns the unique reference of the given transaction within its account
+func reference(transaction *pb.Transaction) string {
+	return strconv.Itoa(int(transaction.GetRevisionNumber())) + "-" + strconv.Itoa(int(transaction.GetSequence()))
+}
@@ -0,0 +1,89 @@
+package statement
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tochemey/gopack/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/tochemey/cos-go-sample/app/storage"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// Generator builds account statements from the read model
+type Generator struct {
+	dataStore storage.Storage
+	currency  string
+	now       func() time.Time
+}
+
+// NewGenerator creates an instance of Generator
+func NewGenerator(dataStore storage.Storage, currency string) *Generator {
+	return &Generator{
+		dataStore: dataStore,
+		currency:  currency,
+		now:       time.Now,
+	}
+}
+
+// Generate builds the statement of the given account of the given tenant for the period starting at the start time inclusive
+// and ending at the end time exclusive
+func (g *Generator) Generate(ctx context.Context, tenantID, accountID string, startTime, endTime time.Time) (*pb.Statement, error) {
+	// set the observability span
+	ctx, span := trace.SpanContext(ctx, "GenerateStatement")
+	defer span.End()
+
+	// validate the request
+	switch {
+	case accountID == "":
+		return nil, status.Error(codes.InvalidArgument, "the account id is not set")
+	case !startTime.Before(endTime):
+		return nil, status.Error(codes.InvalidArgument, "the statement period is invalid")
+	}
+
+	// fetch the account
+	accounts, err := g.dataStore.GetAccounts(ctx, tenantID, []string{accountID})
+	if err != nil {
+		return nil, status.Error(codes.Internal, errors.Wrap(err, "failed to fetch the account").Error())
+	}
+
+	// the account is not found
+	if len(accounts) == 0 || accounts[0] == nil {
+		return nil, status.Errorf(codes.NotFound, "the account:(%s) is not found", accountID)
+	}
+
+	// the opening balance is the balance after the last transaction booked before the period
+	lastTransaction, err := g.dataStore.GetLastTransaction(ctx, tenantID, accountID, startTime)
+	if err != nil {
+		return nil, status.Error(codes.Internal, errors.Wrap(err, "failed to fetch the opening balance").Error())
+	}
+
+	// fetch the transactions booked during the period
+	transactions, err := g.dataStore.GetTransactions(ctx, tenantID, accountID, startTime, endTime)
+	if err != nil {
+		return nil, status.Error(codes.Internal, errors.Wrap(err, "failed to fetch the transactions").Error())
+	}
+
+	// the closing balance is the balance after the last transaction booked during the period
+	openingBalance := lastTransaction.GetBalanceAfter()
+	closingBalance := openingBalance
+	if len(transactions) > 0 {
+		closingBalance = transactions[len(transactions)-1].GetBalanceAfter()
+	}
+
+	return &pb.Statement{
+		AccountId:      accountID,
+		AccountOwner:   accounts[0].GetAccount().GetAccountOwner(),
+		Currency:       g.currency,
+		StartTime:      timestamppb.New(startTime),
+		EndTime:        timestamppb.New(endTime),
+		OpeningBalance: openingBalance,
+		ClosingBalance: closingBalance,
+		Transactions:   transactions,
+		GeneratedAt:    timestamppb.New(g.now()),
+	}, nil
+}
@@ -0,0 +1,90 @@
+package statement
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/tochemey/cos-go-sample/app/storage"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	mocks "github.com/tochemey/cos-go-sample/mocks/app/storage"
+)
+
+func TestGenerate(t *testing.T) {
+	expected := newTestStatement()
+	startTime := expected.GetStartTime().AsTime()
+	endTime := expected.GetEndTime().AsTime()
+	account := &pb.BankAccount{AccountId: "account-1", AccountOwner: "John Doe", AccountBalance: 149}
+
+	// newGenerator creates a generator with a fixed clock
+	newGenerator := func(dataStore *mocks.Storage) *Generator {
+		generator := NewGenerator(dataStore, "EUR")
+		generator.now = func() time.Time { return expected.GetGeneratedAt().AsTime() }
+		return generator
+	}
+
+	t.Run("With happy path", func(t *testing.T) {
+		ctx := context.TODO()
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "acme", []string{"account-1"}).Return([]*storage.AccountRecord{{Account: account}}, nil)
+		dataStore.On("GetLastTransaction", mock.Anything, "acme", "account-1", startTime).Return(&pb.Transaction{BalanceAfter: 100}, nil)
+		dataStore.On("GetTransactions", mock.Anything, "acme", "account-1", startTime, endTime).Return(expected.GetTransactions(), nil)
+
+		actual, err := newGenerator(dataStore).Generate(ctx, "acme", "account-1", startTime, endTime)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(expected, actual))
+		dataStore.AssertExpectations(t)
+	})
+	t.Run("With no transaction", func(t *testing.T) {
+		ctx := context.TODO()
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "acme", []string{"account-1"}).Return([]*storage.AccountRecord{{Account: account}}, nil)
+		dataStore.On("GetLastTransaction", mock.Anything, "acme", "account-1", startTime).Return(nil, nil)
+		dataStore.On("GetTransactions", mock.Anything, "acme", "account-1", startTime, endTime).Return(nil, nil)
+
+		actual, err := newGenerator(dataStore).Generate(ctx, "acme", "account-1", startTime, endTime)
+		require.NoError(t, err)
+		assert.Zero(t, actual.GetOpeningBalance())
+		assert.Zero(t, actual.GetClosingBalance())
+		assert.Empty(t, actual.GetTransactions())
+		assert.True(t, proto.Equal(timestamppb.New(startTime), actual.GetStartTime()))
+	})
+	t.Run("With account not found", func(t *testing.T) {
+		ctx := context.TODO()
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "acme", []string{"account-1"}).Return([]*storage.AccountRecord{nil}, nil)
+
+		actual, err := newGenerator(dataStore).Generate(ctx, "acme", "account-1", startTime, endTime)
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Nil(t, actual)
+	})
+	t.Run("With invalid period", func(t *testing.T) {
+		actual, err := newGenerator(new(mocks.Storage)).Generate(context.TODO(), "acme", "account-1", endTime, startTime)
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = the statement period is invalid")
+		assert.Nil(t, actual)
+	})
+	t.Run("With account id not set", func(t *testing.T) {
+		actual, err := newGenerator(new(mocks.Storage)).Generate(context.TODO(), "acme", "", startTime, endTime)
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = the account id is not set")
+		assert.Nil(t, actual)
+	})
+	t.Run("With data store failure", func(t *testing.T) {
+		ctx := context.TODO()
+		dataStore := new(mocks.Storage)
+		dataStore.On("GetAccounts", mock.Anything, "acme", []string{"account-1"}).Return([]*storage.AccountRecord{{Account: account}}, nil)
+		dataStore.On("GetLastTransaction", mock.Anything, "acme", "account-1", startTime).Return(nil, errors.New("failed"))
+
+		actual, err := newGenerator(dataStore).Generate(ctx, "acme", "account-1", startTime, endTime)
+		assert.Equal(t, codes.Internal, status.Code(err))
+		assert.Nil(t, actual)
+	})
+}
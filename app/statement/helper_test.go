@@ -0,0 +1,54 @@
+package statement
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// newTestStatement creates the January statement used in the unit tests
+func newTestStatement() *pb.Statement {
+	startTime := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return &pb.Statement{
+		AccountId:      "account-1",
+		AccountOwner:   "John Doe",
+		Currency:       "EUR",
+		StartTime:      timestamppb.New(startTime),
+		EndTime:        timestamppb.New(startTime.AddDate(0, 1, 0)),
+		OpeningBalance: 100,
+		ClosingBalance: 149,
+		Transactions: []*pb.Transaction{
+			{
+				AccountId:      "account-1",
+				RevisionNumber: 4,
+				Type:           pb.TransactionType_CREDIT,
+				Amount:         100,
+				BalanceAfter:   200,
+				Description:    "credit",
+				BookedAt:       timestamppb.New(startTime.Add(10 * time.Hour)),
+			},
+			{
+				AccountId:      "account-1",
+				RevisionNumber: 5,
+				Type:           pb.TransactionType_DEBIT,
+				Amount:         50,
+				BalanceAfter:   150,
+				Description:    "debit",
+				BookedAt:       timestamppb.New(startTime.AddDate(0, 0, 1)),
+			},
+			{
+				AccountId:      "account-1",
+				RevisionNumber: 5,
+				Sequence:       1,
+				Type:           pb.TransactionType_FEE,
+				Amount:         1,
+				BalanceAfter:   149,
+				Description:    "fee (debit-fee)",
+				BookedAt:       timestamppb.New(startTime.AddDate(0, 0, 1)),
+			},
+		},
+		GeneratedAt: timestamppb.New(startTime.AddDate(0, 1, 1)),
+	}
+}
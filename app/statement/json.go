@@ -0,0 +1,20 @@
+package statement
+
+import (
+	"io"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// renderJSON writes the statement as JSON
+func renderJSON(w io.Writer, statement *pb.Statement) error {
+	bytea, err := protojson.MarshalOptions{Multiline: true, EmitUnpopulated: true}.Marshal(statement)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(bytea)
+	return err
+}
@@ -0,0 +1,34 @@
+package statement
+
+import (
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// Render writes the given statement into the writer in the given format
+func Render(w io.Writer, statement *pb.Statement, format pb.StatementFormat) error {
+	switch format {
+	case pb.StatementFormat_CSV:
+		return renderCSV(w, statement)
+	case pb.StatementFormat_JSON:
+		return renderJSON(w, statement)
+	case pb.StatementFormat_CAMT053:
+		return renderCamt053(w, statement)
+	default:
+		return status.Errorf(codes.InvalidArgument, "the statement format %s is not supported", format.String())
+	}
+}
+
+// isCredit checks whether the given transaction increases the account balance
+func isCredit(transaction *pb.Transaction) bool {
+	switch transaction.GetType() {
+	case pb.TransactionType_DEBIT, pb.TransactionType_FEE:
+		return false
+	default:
+		return true
+	}
+}
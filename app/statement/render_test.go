@@ -0,0 +1,69 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestRender(t *testing.T) {
+	t.Run("With CSV format", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		require.NoError(t, Render(buffer, newTestStatement(), pb.StatementFormat_CSV))
+
+		expected := "booked_at,reference,type,description,amount,balance\n" +
+			"2024-01-01T00:00:00Z,,OPENING_BALANCE,,,100.00\n" +
+			"2024-01-01T10:00:00Z,4-0,CREDIT,credit,100.00,200.00\n" +
+			"2024-01-02T00:00:00Z,5-0,DEBIT,debit,-50.00,150.00\n" +
+			"2024-01-02T00:00:00Z,5-1,FEE,fee (debit-fee),-1.00,149.00\n" +
+			"2024-02-01T00:00:00Z,,CLOSING_BALANCE,,,149.00\n"
+		assert.Equal(t, expected, buffer.String())
+	})
+	t.Run("With JSON format", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		require.NoError(t, Render(buffer, newTestStatement(), pb.StatementFormat_JSON))
+
+		actual := new(pb.Statement)
+		require.NoError(t, protojson.Unmarshal(buffer.Bytes(), actual))
+		assert.True(t, proto.Equal(newTestStatement(), actual))
+	})
+	t.Run("With camt.053 format", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		require.NoError(t, Render(buffer, newTestStatement(), pb.StatementFormat_CAMT053))
+
+		actual := new(camt053Document)
+		require.NoError(t, xml.Unmarshal(buffer.Bytes(), actual))
+		assert.Equal(t, "account-1-2024-01-01", actual.Statement.Stmt.ID)
+		assert.Equal(t, "account-1", actual.Statement.Stmt.Account.ID)
+
+		// check the balances
+		require.Len(t, actual.Statement.Stmt.Balances, 2)
+		assert.Equal(t, camt053Balance{
+			Code:                 openingBalanceCode,
+			Amount:               camt053Amount{Currency: "EUR", Value: "100.00"},
+			CreditDebitIndicator: creditCode,
+			Date:                 "2024-01-01",
+		}, actual.Statement.Stmt.Balances[0])
+		assert.Equal(t, "2024-01-31", actual.Statement.Stmt.Balances[1].Date)
+		assert.Equal(t, "149.00", actual.Statement.Stmt.Balances[1].Amount.Value)
+
+		// check the entries
+		require.Len(t, actual.Statement.Stmt.Entries, 3)
+		assert.Equal(t, creditCode, actual.Statement.Stmt.Entries[0].CreditDebitIndicator)
+		assert.Equal(t, debitCode, actual.Statement.Stmt.Entries[1].CreditDebitIndicator)
+		assert.Equal(t, "50.00", actual.Statement.Stmt.Entries[1].Amount.Value)
+		assert.Equal(t, "5-1", actual.Statement.Stmt.Entries[2].Reference)
+		assert.Contains(t, buffer.String(), camt053Namespace)
+	})
+	t.Run("With unsupported format", func(t *testing.T) {
+		err := Render(new(bytes.Buffer), newTestStatement(), pb.StatementFormat_STATEMENT_FORMAT_NONE)
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = the statement format STATEMENT_FORMAT_NONE is not supported")
+	})
+}
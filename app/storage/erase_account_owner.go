@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"sort"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+	"github.com/tochemey/gopack/otel/trace"
+)
+
+// EraseAccountOwner scrubs the given owner from the accounts records of the given tenant and returns the ids of the scrubbed accounts ordered by account id
+func (s *storage) EraseAccountOwner(ctx context.Context, tenantID, accountOwner string) (accountIDs []string, err error) {
+	// set the observability span
+	spanCtx, span := trace.SpanContext(ctx, "EraseAccountOwner")
+	defer span.End()
+
+	// create the update statement
+	statement := s.sb.
+		Update("accounts").
+		Set("account_owner", "").
+		Where(sq.Eq{"tenant_id": tenantID, "account_owner": accountOwner}).
+		Suffix("RETURNING account_id")
+
+	// get the sql statement and the arguments
+	query, args, err := statement.ToSql()
+	// handle the error
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to build sql statement")
+	}
+
+	// define the data type to hold the records scrubbed
+	type row struct {
+		AccountID string
+	}
+
+	// create the variable to hold the scrubbed account records
+	var rows []*row
+	// scrub the data and handle the eventual update error
+	if err = s.selectAll(spanCtx, tenantID, &rows, query, args...); err != nil {
+		return nil, errors.Wrap(err, "failed to erase the account owner")
+	}
+
+	// build the output data
+	accountIDs = make([]string, 0, len(rows))
+	for _, row := range rows {
+		accountIDs = append(accountIDs, row.AccountID)
+	}
+	sort.Strings(accountIDs)
+
+	return
+}
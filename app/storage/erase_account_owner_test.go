@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEraseAccountOwner(t *testing.T) {
+	ctx := context.TODO()
+	db, err := dbHandle(ctx)
+	assert.NoError(t, err)
+	// create the schema utils
+	schemaUtils := NewSchemaUtils(db)
+	// create the accounts table
+	require.NoError(t, schemaUtils.CreateAccountsTable(ctx))
+
+	// let insert some accounts record into the database
+	insertStatement := `
+	INSERT INTO accounts(account_id, account_balance, account_owner, is_closed, account_type, tenant_id)
+	VALUES 
+	    ('acme/account-3', 500.21, 'John Doe', TRUE, 'SAVINGS', 'acme'),
+	    ('acme/account-2', 200.00, 'Mr Smith', FALSE, 'CHECKING', 'acme'),
+	    ('acme/account-1', 1000.00, 'John Doe', FALSE, 'CHECKING', 'acme'),
+	    ('globex/account-1', 300.00, 'John Doe', FALSE, 'CHECKING', 'globex');
+	`
+
+	_, err = db.Exec(ctx, insertStatement)
+	require.NoError(t, err)
+
+	// create the storage for test
+	storage := NewTestStorage(db)
+
+	// the scrubbed accounts are ordered by account id
+	accountIDs, err := storage.EraseAccountOwner(ctx, "acme", "John Doe")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"acme/account-1", "acme/account-3"}, accountIDs)
+
+	// the owner is scrubbed from its accounts of the tenant only
+	accountIDs, err = storage.GetOwnerAccountIDs(ctx, "acme", "John Doe")
+	require.NoError(t, err)
+	assert.Empty(t, accountIDs)
+	accountIDs, err = storage.GetOwnerAccountIDs(ctx, "globex", "John Doe")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"globex/account-1"}, accountIDs)
+	accounts, err := storage.GetAccounts(ctx, "acme", []string{"acme/account-1", "acme/account-2"})
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	assert.Empty(t, accounts[0].GetAccount().GetAccountOwner())
+	assert.EqualValues(t, 1000, accounts[0].GetAccount().GetAccountBalance())
+	assert.Equal(t, "Mr Smith", accounts[1].GetAccount().GetAccountOwner())
+
+	// erasing an unknown owner scrubs no account
+	accountIDs, err = storage.EraseAccountOwner(ctx, "acme", "Lady G.")
+	require.NoError(t, err)
+	assert.Empty(t, accountIDs)
+
+	// free resources
+	assert.NoError(t, schemaUtils.DropAccountsTable(ctx))
+	assert.NoError(t, db.Disconnect(ctx))
+}
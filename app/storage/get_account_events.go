@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+	"github.com/tochemey/gopack/otel/trace"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// GetAccountEvents fetches the ledger events of the given account of the given tenant up to the given revision inclusive
+// and up to the given time inclusive. A zero revision or a zero time does not bound the events.
+// The events are ordered by revision
+func (s *storage) GetAccountEvents(ctx context.Context, tenantID, accountID string, untilRevision int32, untilTime time.Time) (events []*AccountEvent, err error) {
+	// set the observability span
+	spanCtx, span := trace.SpanContext(ctx, "GetAccountEvents")
+	defer span.End()
+
+	// create the select statement
+	statement := s.sb.
+		Select(
+			"account_id",
+			"revision_number",
+			"revision_date",
+			"event_type",
+			"event_data").
+		From("account_events").
+		Where(sq.Eq{"tenant_id": tenantID, "account_id": accountID}).
+		OrderBy("revision_number")
+
+	// bound the events
+	if untilRevision > 0 {
+		statement = statement.Where(sq.LtOrEq{"revision_number": untilRevision})
+	}
+	if !untilTime.IsZero() {
+		statement = statement.Where(sq.LtOrEq{"revision_date": untilTime})
+	}
+
+	// get the sql statement and the arguments
+	query, args, err := statement.ToSql()
+	// handle the error
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to build sql statement")
+	}
+
+	// define the data type to hold the records fetched from the database
+	type row struct {
+		AccountID      string
+		RevisionNumber int32
+		RevisionDate   time.Time
+		EventType      string
+		EventData      []byte
+	}
+
+	// create the variable to hold the scanned event records
+	var rows []*row
+	// fetch the data and handle the eventual select error
+	if err = s.selectAll(spanCtx, tenantID, &rows, query, args...); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch account event records")
+	}
+
+	// build the output data
+	events = make([]*AccountEvent, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, &AccountEvent{
+			AccountID:      row.AccountID,
+			RevisionNumber: row.RevisionNumber,
+			RevisionDate:   row.RevisionDate,
+			Event:          &anypb.Any{TypeUrl: row.EventType, Value: row.EventData},
+		})
+	}
+
+	return events, nil
+}
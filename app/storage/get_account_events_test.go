@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestGetAccountEvents(t *testing.T) {
+	ctx := context.TODO()
+	db, err := dbHandle(ctx)
+	assert.NoError(t, err)
+	// create the schema utils
+	schemaUtils := NewSchemaUtils(db)
+	// create the account events table
+	require.NoError(t, schemaUtils.CreateAccountEventsTable(ctx))
+
+	// create the storage for test
+	storage := NewTestStorage(db)
+
+	// record an event per day
+	startTime := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for day := 0; day < 5; day++ {
+		anyEvent, err := anypb.New(&pb.AccountCredited{AccountId: "account-1", Amount: 10})
+		require.NoError(t, err)
+		require.NoError(t, storage.PersistAccountEvent(ctx, &AccountEvent{
+			AccountID:      "account-1",
+			RevisionNumber: int32(day + 1), // #nosec G115
+			RevisionDate:   startTime.AddDate(0, 0, day),
+			Event:          anyEvent,
+		}))
+	}
+	// record an event of an account of another tenant
+	anyEvent, err := anypb.New(&pb.AccountCredited{AccountId: "acme/account-1", Amount: 10})
+	require.NoError(t, err)
+	require.NoError(t, storage.PersistAccountEvent(ctx, &AccountEvent{
+		AccountID:      "acme/account-1",
+		TenantID:       "acme",
+		RevisionNumber: 1,
+		RevisionDate:   startTime,
+		Event:          anyEvent,
+	}))
+
+	t.Run("With all the events", func(t *testing.T) {
+		actual, err := storage.GetAccountEvents(ctx, "", "account-1", 0, time.Time{})
+		require.NoError(t, err)
+		require.Len(t, actual, 5)
+		for index, event := range actual {
+			assert.EqualValues(t, index+1, event.RevisionNumber)
+		}
+	})
+	t.Run("With events up to a revision", func(t *testing.T) {
+		actual, err := storage.GetAccountEvents(ctx, "", "account-1", 3, time.Time{})
+		require.NoError(t, err)
+		require.Len(t, actual, 3)
+		assert.EqualValues(t, 3, actual[2].RevisionNumber)
+	})
+	t.Run("With events up to a time", func(t *testing.T) {
+		actual, err := storage.GetAccountEvents(ctx, "", "account-1", 0, startTime.AddDate(0, 0, 1))
+		require.NoError(t, err)
+		require.Len(t, actual, 2)
+		assert.EqualValues(t, 2, actual[1].RevisionNumber)
+	})
+	t.Run("With unknown account", func(t *testing.T) {
+		actual, err := storage.GetAccountEvents(ctx, "", "account-2", 0, time.Time{})
+		require.NoError(t, err)
+		assert.Empty(t, actual)
+	})
+	t.Run("With account of another tenant", func(t *testing.T) {
+		actual, err := storage.GetAccountEvents(ctx, "acme", "acme/account-1", 0, time.Time{})
+		require.NoError(t, err)
+		assert.Len(t, actual, 1)
+
+		actual, err = storage.GetAccountEvents(ctx, "globex", "acme/account-1", 0, time.Time{})
+		require.NoError(t, err)
+		assert.Empty(t, actual)
+	})
+
+	// free resources
+	assert.NoError(t, schemaUtils.DropAccountEventsTable(ctx))
+	assert.NoError(t, db.Disconnect(ctx))
+}
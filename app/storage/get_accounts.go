@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/pkg/errors"
@@ -10,10 +11,24 @@ import (
 	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
 )
 
-// GetAccounts fetches the ordered list of accounts.
+// AccountRecord holds an account of the read model along with the revision it is built from
+type AccountRecord struct {
+	Account  *pb.BankAccount
+	Revision Revision
+}
+
+// GetAccount returns the account of the record or nil when the record is not set
+func (r *AccountRecord) GetAccount() *pb.BankAccount {
+	if r == nil {
+		return nil
+	}
+	return r.Account
+}
+
+// GetAccounts fetches the ordered list of accounts of the given tenant.
 // The order in the list is the same as the order of the account's ids sent.
 // When a record is not found nil is return instead
-func (s *storage) GetAccounts(ctx context.Context, accountIDs []string) (accounts []*pb.BankAccount, err error) {
+func (s *storage) GetAccounts(ctx context.Context, tenantID string, accountIDs []string) (accounts []*AccountRecord, err error) {
 	// set the observability span
 	spanCtx, span := trace.SpanContext(ctx, "GetAccounts")
 	defer span.End()
@@ -24,9 +39,18 @@ func (s *storage) GetAccounts(ctx context.Context, accountIDs []string) (account
 			"account_id",
 			"account_balance",
 			"account_owner",
-			"is_closed").
+			"is_closed",
+			"account_type",
+			"is_dormant",
+			"revision_number",
+			"revision_date",
+			"tenant_id",
+			"total_fees_charged",
+			"total_fees_waived",
+			"monthly_withdrawals",
+			"withdrawals_month").
 		From("accounts").
-		Where(sq.Eq{"account_id": accountIDs})
+		Where(sq.Eq{"tenant_id": tenantID, "account_id": accountIDs})
 
 	// get the sql statement and the arguments
 	query, args, err := statement.ToSql()
@@ -37,39 +61,63 @@ func (s *storage) GetAccounts(ctx context.Context, accountIDs []string) (account
 
 	// define the data type to hold the records fetched from the database
 	type row struct {
-		AccountID      string
-		AccountBalance float64
-		AccountOwner   string
-		IsClosed       bool
+		AccountID          string
+		AccountBalance     float64
+		AccountOwner       string
+		IsClosed           bool
+		AccountType        string
+		IsDormant          bool
+		RevisionNumber     int32
+		RevisionDate       *time.Time
+		TenantID           string
+		TotalFeesCharged   float64
+		TotalFeesWaived    float64
+		MonthlyWithdrawals int32
+		WithdrawalsMonth   string
 	}
 
 	// create the variable to hold the scanned account records
 	var rows []*row
 	// fetch the data and handle the eventual select error
-	if err = s.db.SelectAll(spanCtx, &rows, query, args...); err != nil {
+	if err = s.selectAll(spanCtx, tenantID, &rows, query, args...); err != nil {
 		return nil, errors.Wrap(err, "failed to fetch account records")
 	}
 
 	// create a map holding account id and record scanned from the database
-	recordsMap := make(map[string]*pb.BankAccount)
+	recordsMap := make(map[string]*AccountRecord)
 	// iterate the rows scanned and build the map
 	for _, row := range rows {
-		recordsMap[row.AccountID] = &pb.BankAccount{
-			AccountId:      row.AccountID,
-			AccountBalance: row.AccountBalance,
-			AccountOwner:   row.AccountOwner,
-			IsClosed:       row.IsClosed,
+		record := &AccountRecord{
+			Account: &pb.BankAccount{
+				AccountId:          row.AccountID,
+				AccountBalance:     row.AccountBalance,
+				AccountOwner:       row.AccountOwner,
+				IsClosed:           row.IsClosed,
+				AccountType:        pb.AccountType(pb.AccountType_value[row.AccountType]),
+				IsDormant:          row.IsDormant,
+				TenantId:           row.TenantID,
+				TotalFeesCharged:   row.TotalFeesCharged,
+				TotalFeesWaived:    row.TotalFeesWaived,
+				MonthlyWithdrawals: row.MonthlyWithdrawals,
+				WithdrawalsMonth:   row.WithdrawalsMonth,
+			},
+			Revision: Revision{Number: row.RevisionNumber},
+		}
+		// the accounts persisted before the revisions were recorded have no revision date
+		if row.RevisionDate != nil {
+			record.Revision.Date = *row.RevisionDate
 		}
+		recordsMap[row.AccountID] = record
 	}
 
 	// initialize the output data
-	accounts = make([]*pb.BankAccount, len(accountIDs))
+	accounts = make([]*AccountRecord, len(accountIDs))
 	// set the output data with the records fetched
 	for orderNr, accountID := range accountIDs {
 		// look up for the account id in the record map and sets
 		// the corresponding record fetched.
-		if account, found := recordsMap[accountID]; found {
-			accounts[orderNr] = account
+		if record, found := recordsMap[accountID]; found {
+			accounts[orderNr] = record
 		}
 	}
 
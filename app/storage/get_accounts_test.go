@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -22,12 +23,13 @@ func TestGetAccounts(t *testing.T) {
 
 	// let insert some accounts record into the database
 	insertStatement := `
-	INSERT INTO accounts(account_id, account_balance, account_owner, is_closed)
+	INSERT INTO accounts(account_id, account_balance, account_owner, is_closed, account_type, revision_number, revision_date,
+	    total_fees_charged, total_fees_waived, monthly_withdrawals, withdrawals_month)
 	VALUES 
-	    ('account-1', 500.21, 'John Doe', TRUE),
-	    ('account-2', 200.00, 'Mr Smith', FALSE),
-	    ('account-3', 1000.00, 'Lady G.', FALSE),
-	    ('account-4', 250.00, 'Mrs Peng', FALSE);
+	    ('account-1', 500.21, 'John Doe', TRUE, 'SAVINGS', 4, '2024-01-02 10:00:00+00', 3.50, 1.25, 2, '2024-01'),
+	    ('account-2', 200.00, 'Mr Smith', FALSE, 'CHECKING', 1, '2024-01-01 10:00:00+00', 0, 0, 0, ''),
+	    ('account-3', 1000.00, 'Lady G.', FALSE, 'CHECKING', 0, NULL, 0, 0, 0, ''),
+	    ('account-4', 250.00, 'Mrs Peng', FALSE, 'ESCROW', 2, '2024-01-01 10:00:00+00', 0, 0, 0, '');
 	`
 
 	_, err = db.Exec(ctx, insertStatement)
@@ -38,7 +40,7 @@ func TestGetAccounts(t *testing.T) {
 
 	// define the accounts we want to fetch
 	accountIDs := []string{"account-1", "account-5", "account-3"}
-	accounts, err := storage.GetAccounts(ctx, accountIDs)
+	accounts, err := storage.GetAccounts(ctx, "", accountIDs)
 	require.NoError(t, err)
 	require.NotEmpty(t, accounts)
 	require.Len(t, accounts, 3)
@@ -49,12 +51,19 @@ func TestGetAccounts(t *testing.T) {
 		AccountBalance: 500.21,
 		AccountOwner:   "John Doe",
 		IsClosed:       true,
+		AccountType:    pb.AccountType_SAVINGS,
+		// the fees and the withdrawals of the month are fetched as well
+		TotalFeesCharged:   3.50,
+		TotalFeesWaived:    1.25,
+		MonthlyWithdrawals: 2,
+		WithdrawalsMonth:   "2024-01",
 	}
 	account3 := &pb.BankAccount{
 		AccountId:      "account-3",
 		AccountBalance: 1000.00,
 		AccountOwner:   "Lady G.",
 		IsClosed:       false,
+		AccountType:    pb.AccountType_CHECKING,
 	}
 
 	expecteds := []*pb.BankAccount{
@@ -64,9 +73,18 @@ func TestGetAccounts(t *testing.T) {
 	}
 
 	for index, account := range accounts {
-		require.True(t, proto.Equal(expecteds[index], account))
+		require.True(t, proto.Equal(expecteds[index], account.GetAccount()))
 	}
 
+	// the revisions are fetched along with the accounts
+	assert.Equal(t, Revision{Number: 4, Date: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)}, Revision{Number: accounts[0].Revision.Number, Date: accounts[0].Revision.Date.UTC()})
+	assert.Equal(t, Revision{}, accounts[2].Revision)
+
+	// the accounts are not found within another tenant
+	accounts, err = storage.GetAccounts(ctx, "acme", accountIDs)
+	require.NoError(t, err)
+	assert.Equal(t, []*AccountRecord{nil, nil, nil}, accounts)
+
 	// free resources
 	assert.NoError(t, schemaUtils.DropAccountsTable(ctx))
 	assert.NoError(t, db.Disconnect(ctx))
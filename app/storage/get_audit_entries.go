@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+	"github.com/tochemey/gopack/otel/trace"
+)
+
+// GetAuditEntries fetches at most limit entries of the audit trail of the given entity of the given tenant with a revision greater than
+// the given revision. The entries are ordered by revision
+func (s *storage) GetAuditEntries(ctx context.Context, tenantID, entityID string, afterRevision int32, limit int) (entries []*AuditEntry, err error) {
+	// set the observability span
+	spanCtx, span := trace.SpanContext(ctx, "GetAuditEntries")
+	defer span.End()
+
+	// create the select statement
+	statement := s.sb.
+		Select(
+			"entity_id",
+			"revision_number",
+			"revision_date",
+			"event_type",
+			"caller_subject",
+			"client_ip",
+			"request_id").
+		From("audit_log").
+		Where(sq.Eq{"tenant_id": tenantID, "entity_id": entityID}).
+		Where(sq.Gt{"revision_number": afterRevision}).
+		OrderBy("revision_number").
+		Limit(uint64(limit)) // #nosec G115
+
+	// get the sql statement and the arguments
+	query, args, err := statement.ToSql()
+	// handle the error
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to build sql statement")
+	}
+
+	// define the data type to hold the records fetched from the database
+	type row struct {
+		EntityID       string
+		RevisionNumber int32
+		RevisionDate   time.Time
+		EventType      string
+		CallerSubject  string
+		ClientIP       string
+		RequestID      string
+	}
+
+	// create the variable to hold the scanned audit records
+	var rows []*row
+	// fetch the data and handle the eventual select error
+	if err = s.selectAll(spanCtx, tenantID, &rows, query, args...); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch audit entry records")
+	}
+
+	// build the output data
+	entries = make([]*AuditEntry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, &AuditEntry{
+			EntityID:       row.EntityID,
+			RevisionNumber: row.RevisionNumber,
+			RevisionDate:   row.RevisionDate,
+			EventType:      row.EventType,
+			CallerSubject:  row.CallerSubject,
+			ClientIP:       row.ClientIP,
+			RequestID:      row.RequestID,
+		})
+	}
+
+	return entries, nil
+}
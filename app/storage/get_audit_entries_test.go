@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAuditEntries(t *testing.T) {
+	ctx := context.TODO()
+	db, err := dbHandle(ctx)
+	assert.NoError(t, err)
+	// create the schema utils
+	schemaUtils := NewSchemaUtils(db)
+	// create the audit log table
+	require.NoError(t, schemaUtils.CreateAuditLogTable(ctx))
+
+	// create the storage for test
+	storage := NewTestStorage(db)
+
+	// record an entry per day
+	startTime := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for day := 0; day < 5; day++ {
+		require.NoError(t, storage.PersistAuditEntry(ctx, &AuditEntry{
+			EntityID:       "account-1",
+			RevisionNumber: int32(day + 1), // #nosec G115
+			RevisionDate:   startTime.AddDate(0, 0, day),
+			EventType:      "accounts.v1.AccountCredited",
+			CallerSubject:  "operator-1",
+		}))
+	}
+	// record an entry of an account of another tenant
+	require.NoError(t, storage.PersistAuditEntry(ctx, &AuditEntry{
+		EntityID:       "acme/account-1",
+		TenantID:       "acme",
+		RevisionNumber: 1,
+		RevisionDate:   startTime,
+		EventType:      "accounts.v1.AccountCredited",
+	}))
+
+	t.Run("With all the entries", func(t *testing.T) {
+		actual, err := storage.GetAuditEntries(ctx, "", "account-1", 0, 10)
+		require.NoError(t, err)
+		require.Len(t, actual, 5)
+		for index, entry := range actual {
+			assert.EqualValues(t, index+1, entry.RevisionNumber)
+			assert.Equal(t, "operator-1", entry.CallerSubject)
+		}
+	})
+	t.Run("With a page of entries", func(t *testing.T) {
+		actual, err := storage.GetAuditEntries(ctx, "", "account-1", 2, 2)
+		require.NoError(t, err)
+		require.Len(t, actual, 2)
+		assert.EqualValues(t, 3, actual[0].RevisionNumber)
+		assert.EqualValues(t, 4, actual[1].RevisionNumber)
+	})
+	t.Run("With unknown entity", func(t *testing.T) {
+		actual, err := storage.GetAuditEntries(ctx, "", "account-2", 0, 10)
+		require.NoError(t, err)
+		assert.Empty(t, actual)
+	})
+	t.Run("With entity of another tenant", func(t *testing.T) {
+		actual, err := storage.GetAuditEntries(ctx, "acme", "acme/account-1", 0, 10)
+		require.NoError(t, err)
+		assert.Len(t, actual, 1)
+
+		actual, err = storage.GetAuditEntries(ctx, "globex", "acme/account-1", 0, 10)
+		require.NoError(t, err)
+		assert.Empty(t, actual)
+	})
+
+	// free resources
+	assert.NoError(t, schemaUtils.DropAuditLogTable(ctx))
+	assert.NoError(t, db.Disconnect(ctx))
+}
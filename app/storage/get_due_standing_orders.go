@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+	"github.com/tochemey/gopack/otel/trace"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// GetDueStandingOrders fetches at most limit active standing orders which execution or retry is due at the given time.
+// The standing orders are ordered by due time
+func (s *storage) GetDueStandingOrders(ctx context.Context, asOf time.Time, limit int) (standingOrders []*pb.StandingOrder, err error) {
+	// set the observability span
+	spanCtx, span := trace.SpanContext(ctx, "GetDueStandingOrders")
+	defer span.End()
+
+	// a retry takes precedence over the next execution date
+	const dueAt = "COALESCE(next_attempt_at, next_execution_date)"
+
+	// create the select statement
+	statement := s.sb.
+		Select(
+			"order_id",
+			"account_id",
+			"beneficiary_account_id",
+			"amount",
+			"frequency",
+			"status",
+			"next_execution_date",
+			"end_date",
+			"reference",
+			"failed_attempts",
+			"last_failure_reason",
+			"next_attempt_at",
+			"last_execution_date",
+			"tenant_id",
+			"debited").
+		From("standing_orders").
+		Where(sq.Eq{"status": pb.StandingOrderStatus_ACTIVE.String()}).
+		Where(sq.LtOrEq{dueAt: asOf}).
+		OrderBy(dueAt).
+		Limit(uint64(limit)) // #nosec G115
+
+	// get the sql statement and the arguments
+	query, args, err := statement.ToSql()
+	// handle the error
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to build sql statement")
+	}
+
+	// define the data type to hold the records fetched from the database
+	type row struct {
+		OrderID              string
+		AccountID            string
+		BeneficiaryAccountID string
+		Amount               float64
+		Frequency            string
+		Status               string
+		NextExecutionDate    *time.Time
+		EndDate              *time.Time
+		Reference            string
+		FailedAttempts       int32
+		LastFailureReason    string
+		NextAttemptAt        *time.Time
+		LastExecutionDate    *time.Time
+		TenantID             string
+		Debited              bool
+	}
+
+	// create the variable to hold the scanned standing order records
+	var rows []*row
+	// fetch the data and handle the eventual select error
+	if err = s.selectAll(spanCtx, allTenants, &rows, query, args...); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch standing order records")
+	}
+
+	// build the output data
+	standingOrders = make([]*pb.StandingOrder, 0, len(rows))
+	for _, row := range rows {
+		standingOrders = append(standingOrders, &pb.StandingOrder{
+			OrderId:              row.OrderID,
+			AccountId:            row.AccountID,
+			BeneficiaryAccountId: row.BeneficiaryAccountID,
+			Amount:               row.Amount,
+			Frequency:            pb.Frequency(pb.Frequency_value[row.Frequency]),
+			Status:               pb.StandingOrderStatus(pb.StandingOrderStatus_value[row.Status]),
+			NextExecutionDate:    fromNullTime(row.NextExecutionDate),
+			EndDate:              fromNullTime(row.EndDate),
+			Reference:            row.Reference,
+			FailedAttempts:       row.FailedAttempts,
+			LastFailureReason:    row.LastFailureReason,
+			NextAttemptAt:        fromNullTime(row.NextAttemptAt),
+			LastExecutionDate:    fromNullTime(row.LastExecutionDate),
+			TenantId:             row.TenantID,
+			Debited:              row.Debited,
+		})
+	}
+
+	return
+}
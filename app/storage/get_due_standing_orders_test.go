@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestGetDueStandingOrders(t *testing.T) {
+	ctx := context.TODO()
+	db, err := dbHandle(ctx)
+	assert.NoError(t, err)
+	// create the schema utils
+	schemaUtils := NewSchemaUtils(db)
+	// create the standing orders table
+	require.NoError(t, schemaUtils.CreateStandingOrdersTable(ctx))
+
+	// create the storage for test
+	storage := NewTestStorage(db)
+
+	asOf := time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC)
+	standingOrders := []*pb.StandingOrder{
+		{
+			// due
+			OrderId:           "order-1",
+			Status:            pb.StandingOrderStatus_ACTIVE,
+			Frequency:         pb.Frequency_DAILY,
+			NextExecutionDate: timestamppb.New(asOf.AddDate(0, 0, -1)),
+		},
+		{
+			// not yet due
+			OrderId:           "order-2",
+			Status:            pb.StandingOrderStatus_ACTIVE,
+			Frequency:         pb.Frequency_DAILY,
+			NextExecutionDate: timestamppb.New(asOf.AddDate(0, 0, 1)),
+		},
+		{
+			// paused
+			OrderId:           "order-3",
+			Status:            pb.StandingOrderStatus_PAUSED,
+			Frequency:         pb.Frequency_DAILY,
+			NextExecutionDate: timestamppb.New(asOf.AddDate(0, 0, -1)),
+		},
+		{
+			// retry not yet due
+			OrderId:           "order-4",
+			Status:            pb.StandingOrderStatus_ACTIVE,
+			Frequency:         pb.Frequency_DAILY,
+			NextExecutionDate: timestamppb.New(asOf.AddDate(0, 0, -2)),
+			NextAttemptAt:     timestamppb.New(asOf.Add(time.Hour)),
+			FailedAttempts:    1,
+		},
+		{
+			// retry due
+			OrderId:           "order-5",
+			Status:            pb.StandingOrderStatus_ACTIVE,
+			Frequency:         pb.Frequency_DAILY,
+			NextExecutionDate: timestamppb.New(asOf.AddDate(0, 0, -2)),
+			NextAttemptAt:     timestamppb.New(asOf.Add(-time.Hour)),
+			FailedAttempts:    1,
+		},
+	}
+
+	for _, standingOrder := range standingOrders {
+		require.NoError(t, storage.PersistStandingOrder(ctx, standingOrder))
+	}
+
+	// fetch the due standing orders
+	actual, err := storage.GetDueStandingOrders(ctx, asOf, 10)
+	require.NoError(t, err)
+	require.Len(t, actual, 2)
+	assert.Equal(t, "order-1", actual[0].GetOrderId())
+	assert.Equal(t, "order-5", actual[1].GetOrderId())
+
+	// fetch with a limit
+	actual, err = storage.GetDueStandingOrders(ctx, asOf, 1)
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+
+	// free resources
+	assert.NoError(t, schemaUtils.DropStandingOrdersTable(ctx))
+	assert.NoError(t, db.Disconnect(ctx))
+}
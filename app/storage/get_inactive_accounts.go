@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+	"github.com/tochemey/gopack/otel/trace"
+)
+
+// AccountActivity holds the last customer activity of an account
+type AccountActivity struct {
+	AccountID      string
+	AccountOwner   string
+	LastActivityAt time.Time
+}
+
+// GetInactiveAccounts fetches at most limit open and non-dormant accounts without customer activity since the given time.
+// The accounts are ordered by last activity then account id. The accounts fetched are the ones following the given
+// cursor when it is set, hence the accounts can be paged through
+func (s *storage) GetInactiveAccounts(ctx context.Context, inactiveSince time.Time, after *AccountActivity, limit int) (accounts []*AccountActivity, err error) {
+	// set the observability span
+	spanCtx, span := trace.SpanContext(ctx, "GetInactiveAccounts")
+	defer span.End()
+
+	// create the select statement
+	statement := s.sb.
+		Select(
+			"account_id",
+			"account_owner",
+			"last_activity_at").
+		From("accounts").
+		Where(sq.Eq{"is_closed": false, "is_dormant": false}).
+		Where(sq.Lt{"last_activity_at": inactiveSince}).
+		OrderBy("last_activity_at", "account_id").
+		Limit(uint64(limit)) // #nosec G115
+
+	// resume after the cursor
+	if after != nil {
+		statement = statement.Where(sq.Expr("(last_activity_at, account_id) > (?, ?)", after.LastActivityAt, after.AccountID))
+	}
+
+	// get the sql statement and the arguments
+	query, args, err := statement.ToSql()
+	// handle the error
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to build sql statement")
+	}
+
+	// fetch the data and handle the eventual select error
+	if err = s.selectAll(spanCtx, allTenants, &accounts, query, args...); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch account records")
+	}
+
+	return accounts, nil
+}
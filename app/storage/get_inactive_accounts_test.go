@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestGetInactiveAccounts(t *testing.T) {
+	ctx := context.TODO()
+	db, err := dbHandle(ctx)
+	assert.NoError(t, err)
+	// create the schema utils
+	schemaUtils := NewSchemaUtils(db)
+	// create the accounts table
+	require.NoError(t, schemaUtils.CreateAccountsTable(ctx))
+
+	// create the storage for test
+	storage := NewTestStorage(db)
+
+	inactiveSince := time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC)
+	accounts := []struct {
+		account    *pb.BankAccount
+		activityAt time.Time
+	}{
+		// inactive
+		{&pb.BankAccount{AccountId: "account-1", AccountOwner: "John Doe"}, inactiveSince.AddDate(0, -2, 0)},
+		// active
+		{&pb.BankAccount{AccountId: "account-2", AccountOwner: "Mr Smith"}, inactiveSince.AddDate(0, 0, 1)},
+		// already dormant
+		{&pb.BankAccount{AccountId: "account-3", AccountOwner: "Lady G.", IsDormant: true}, inactiveSince.AddDate(0, -2, 0)},
+		// closed
+		{&pb.BankAccount{AccountId: "account-4", AccountOwner: "Mrs Peng", IsClosed: true}, inactiveSince.AddDate(0, -2, 0)},
+		// inactive
+		{&pb.BankAccount{AccountId: "account-5", AccountOwner: "Jane Doe"}, inactiveSince.AddDate(0, -1, 0)},
+	}
+
+	for _, record := range accounts {
+		require.NoError(t, storage.PersistAccount(ctx, record.account, Revision{}))
+		require.NoError(t, storage.RecordAccountActivity(ctx, record.account.GetAccountId(), record.activityAt))
+	}
+
+	// fetch the first page
+	actual, err := storage.GetInactiveAccounts(ctx, inactiveSince, nil, 1)
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	assert.Equal(t, "account-1", actual[0].AccountID)
+	assert.Equal(t, "John Doe", actual[0].AccountOwner)
+
+	// fetch the next page
+	actual, err = storage.GetInactiveAccounts(ctx, inactiveSince, actual[0], 10)
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	assert.Equal(t, "account-5", actual[0].AccountID)
+
+	// free resources
+	assert.NoError(t, schemaUtils.DropAccountsTable(ctx))
+	assert.NoError(t, db.Disconnect(ctx))
+}
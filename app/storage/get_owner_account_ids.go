@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+	"github.com/tochemey/gopack/otel/trace"
+)
+
+// GetOwnerAccountIDs fetches the ids of the accounts held by the given owner within the given tenant ordered by account id
+func (s *storage) GetOwnerAccountIDs(ctx context.Context, tenantID, accountOwner string) (accountIDs []string, err error) {
+	// set the observability span
+	spanCtx, span := trace.SpanContext(ctx, "GetOwnerAccountIDs")
+	defer span.End()
+
+	// create the select statement
+	statement := s.sb.
+		Select("account_id").
+		From("accounts").
+		Where(sq.Eq{"tenant_id": tenantID, "account_owner": accountOwner}).
+		OrderBy("account_id")
+
+	// get the sql statement and the arguments
+	query, args, err := statement.ToSql()
+	// handle the error
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to build sql statement")
+	}
+
+	// define the data type to hold the records fetched from the database
+	type row struct {
+		AccountID string
+	}
+
+	// create the variable to hold the scanned account records
+	var rows []*row
+	// fetch the data and handle the eventual select error
+	if err = s.selectAll(spanCtx, tenantID, &rows, query, args...); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch account records")
+	}
+
+	// build the output data
+	accountIDs = make([]string, 0, len(rows))
+	for _, row := range rows {
+		accountIDs = append(accountIDs, row.AccountID)
+	}
+
+	return
+}
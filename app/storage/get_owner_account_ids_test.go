@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOwnerAccountIDs(t *testing.T) {
+	ctx := context.TODO()
+	db, err := dbHandle(ctx)
+	assert.NoError(t, err)
+	// create the schema utils
+	schemaUtils := NewSchemaUtils(db)
+	// create the accounts table
+	require.NoError(t, schemaUtils.CreateAccountsTable(ctx))
+
+	// let insert some accounts record into the database
+	insertStatement := `
+	INSERT INTO accounts(account_id, account_balance, account_owner, is_closed, account_type, tenant_id)
+	VALUES 
+	    ('acme/account-3', 500.21, 'John Doe', TRUE, 'SAVINGS', 'acme'),
+	    ('acme/account-2', 200.00, 'Mr Smith', FALSE, 'CHECKING', 'acme'),
+	    ('acme/account-1', 1000.00, 'John Doe', FALSE, 'CHECKING', 'acme'),
+	    ('globex/account-1', 300.00, 'John Doe', FALSE, 'CHECKING', 'globex');
+	`
+
+	_, err = db.Exec(ctx, insertStatement)
+	require.NoError(t, err)
+
+	// create the storage for test
+	storage := NewTestStorage(db)
+
+	// the accounts of the owner within the tenant are ordered by account id
+	accountIDs, err := storage.GetOwnerAccountIDs(ctx, "acme", "John Doe")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"acme/account-1", "acme/account-3"}, accountIDs)
+
+	// the accounts of the owner in another tenant are not fetched
+	accountIDs, err = storage.GetOwnerAccountIDs(ctx, "globex", "John Doe")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"globex/account-1"}, accountIDs)
+
+	// an unknown owner holds no account
+	accountIDs, err = storage.GetOwnerAccountIDs(ctx, "acme", "Lady G.")
+	require.NoError(t, err)
+	assert.Empty(t, accountIDs)
+
+	// free resources
+	assert.NoError(t, schemaUtils.DropAccountsTable(ctx))
+	assert.NoError(t, db.Disconnect(ctx))
+}
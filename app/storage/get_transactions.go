@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+	"github.com/tochemey/gopack/otel/trace"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// transactionRow defines the data type to hold the transaction records fetched from the database
+type transactionRow struct {
+	AccountID       string
+	RevisionNumber  int32
+	Sequence        int32
+	TransactionType string
+	Amount          float64
+	BalanceAfter    float64
+	Description     string
+	BookedAt        time.Time
+}
+
+// toTransaction converts the record into a transaction
+func (r *transactionRow) toTransaction() *pb.Transaction {
+	return &pb.Transaction{
+		AccountId:      r.AccountID,
+		RevisionNumber: r.RevisionNumber,
+		Sequence:       r.Sequence,
+		Type:           pb.TransactionType(pb.TransactionType_value[r.TransactionType]),
+		Amount:         r.Amount,
+		BalanceAfter:   r.BalanceAfter,
+		Description:    r.Description,
+		BookedAt:       timestamppb.New(r.BookedAt),
+	}
+}
+
+// selectTransactions returns the select statement of the transaction records of the given account within the given tenant
+func (s *storage) selectTransactions(tenantID, accountID string) sq.SelectBuilder {
+	return s.sb.
+		Select(
+			"account_id",
+			"revision_number",
+			"sequence",
+			"transaction_type",
+			"amount",
+			"balance_after",
+			"description",
+			"booked_at").
+		From("account_transactions").
+		Where(sq.Eq{"tenant_id": tenantID, "account_id": accountID})
+}
+
+// GetTransactions fetches the transactions of the given account of the given tenant booked from the start time inclusive
+// to the end time exclusive. The transactions are ordered by booking order
+func (s *storage) GetTransactions(ctx context.Context, tenantID, accountID string, startTime, endTime time.Time) (transactions []*pb.Transaction, err error) {
+	// set the observability span
+	spanCtx, span := trace.SpanContext(ctx, "GetTransactions")
+	defer span.End()
+
+	// create the select statement
+	statement := s.selectTransactions(tenantID, accountID).
+		Where(sq.GtOrEq{"booked_at": startTime}).
+		Where(sq.Lt{"booked_at": endTime}).
+		OrderBy("revision_number", "sequence")
+
+	// get the sql statement and the arguments
+	query, args, err := statement.ToSql()
+	// handle the error
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to build sql statement")
+	}
+
+	// create the variable to hold the scanned transaction records
+	var rows []*transactionRow
+	// fetch the data and handle the eventual select error
+	if err = s.selectAll(spanCtx, tenantID, &rows, query, args...); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch transaction records")
+	}
+
+	// build the output data
+	transactions = make([]*pb.Transaction, 0, len(rows))
+	for _, row := range rows {
+		transactions = append(transactions, row.toTransaction())
+	}
+
+	return
+}
+
+// GetLastTransaction fetches the last transaction of the given account of the given tenant booked before the given time.
+// When there is no such transaction nil is returned
+func (s *storage) GetLastTransaction(ctx context.Context, tenantID, accountID string, before time.Time) (*pb.Transaction, error) {
+	// set the observability span
+	spanCtx, span := trace.SpanContext(ctx, "GetLastTransaction")
+	defer span.End()
+
+	// create the select statement
+	statement := s.selectTransactions(tenantID, accountID).
+		Where(sq.Lt{"booked_at": before}).
+		OrderBy("revision_number DESC", "sequence DESC").
+		Limit(1)
+
+	// get the sql statement and the arguments
+	query, args, err := statement.ToSql()
+	// handle the error
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to build sql statement")
+	}
+
+	// create the variable to hold the scanned transaction records
+	var rows []*transactionRow
+	// fetch the data and handle the eventual select error
+	if err = s.selectAll(spanCtx, tenantID, &rows, query, args...); err != nil {
+		return nil, errors.Wrap(err, "failed to fetch transaction records")
+	}
+
+	// no transaction has been booked yet
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	return rows[0].toTransaction(), nil
+}
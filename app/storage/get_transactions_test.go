@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestGetTransactions(t *testing.T) {
+	ctx := context.TODO()
+	db, err := dbHandle(ctx)
+	assert.NoError(t, err)
+	// create the schema utils
+	schemaUtils := NewSchemaUtils(db)
+	// create the transactions table
+	require.NoError(t, schemaUtils.CreateTransactionsTable(ctx))
+
+	// create the storage for test
+	storage := NewTestStorage(db)
+
+	// book a transaction per day
+	startTime := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	var transactions []*pb.Transaction
+	for day := 0; day < 5; day++ {
+		transactions = append(transactions, &pb.Transaction{
+			AccountId:      "account-1",
+			RevisionNumber: int32(day + 1), // #nosec G115
+			Type:           pb.TransactionType_CREDIT,
+			Amount:         10,
+			BalanceAfter:   float64(10 * (day + 1)),
+			BookedAt:       timestamppb.New(startTime.AddDate(0, 0, day)),
+		})
+	}
+	require.NoError(t, storage.PersistTransactions(ctx, "", transactions))
+
+	t.Run("With transactions in the period", func(t *testing.T) {
+		actual, err := storage.GetTransactions(ctx, "", "account-1", startTime.AddDate(0, 0, 1), startTime.AddDate(0, 0, 3))
+		require.NoError(t, err)
+		require.Len(t, actual, 2)
+		assert.EqualValues(t, 2, actual[0].GetRevisionNumber())
+		assert.EqualValues(t, 3, actual[1].GetRevisionNumber())
+	})
+	t.Run("With unknown account", func(t *testing.T) {
+		actual, err := storage.GetTransactions(ctx, "", "account-2", startTime, startTime.AddDate(0, 0, 5))
+		require.NoError(t, err)
+		assert.Empty(t, actual)
+	})
+	t.Run("With account of another tenant", func(t *testing.T) {
+		actual, err := storage.GetTransactions(ctx, "acme", "account-1", startTime, startTime.AddDate(0, 0, 5))
+		require.NoError(t, err)
+		assert.Empty(t, actual)
+
+		last, err := storage.GetLastTransaction(ctx, "acme", "account-1", startTime.AddDate(0, 0, 3))
+		require.NoError(t, err)
+		assert.Nil(t, last)
+	})
+	t.Run("With last transaction", func(t *testing.T) {
+		actual, err := storage.GetLastTransaction(ctx, "", "account-1", startTime.AddDate(0, 0, 3))
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.EqualValues(t, 3, actual.GetRevisionNumber())
+		assert.EqualValues(t, 30, actual.GetBalanceAfter())
+	})
+	t.Run("With no last transaction", func(t *testing.T) {
+		actual, err := storage.GetLastTransaction(ctx, "", "account-1", startTime)
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
+
+	// free resources
+	assert.NoError(t, schemaUtils.DropTransactionsTable(ctx))
+	assert.NoError(t, db.Disconnect(ctx))
+}
@@ -58,9 +58,24 @@ func (s SchemaUtils) CreateAccountsTable(ctx context.Context) error {
 		account_balance NUMERIC(19, 2) NOT NULL,
 		account_owner VARCHAR(255) NOT NULL,
 		is_closed BOOLEAN NOT NULL,
+		account_type VARCHAR(50) NOT NULL DEFAULT 'CHECKING',
+		is_dormant BOOLEAN NOT NULL DEFAULT FALSE,
+		last_activity_at TIMESTAMP WITH TIME ZONE,
+		revision_number INTEGER NOT NULL DEFAULT 0,
+		revision_date TIMESTAMP WITH TIME ZONE,
+		tenant_id VARCHAR(63) NOT NULL DEFAULT '',
+		total_fees_charged NUMERIC(19, 2) NOT NULL DEFAULT 0,
+		total_fees_waived NUMERIC(19, 2) NOT NULL DEFAULT 0,
+		monthly_withdrawals INTEGER NOT NULL DEFAULT 0,
+		withdrawals_month VARCHAR(7) NOT NULL DEFAULT '',
 	
 		PRIMARY KEY (account_id)
 	);
+
+	-- the records are only accessible within the tenant the transaction is scoped to
+	ALTER TABLE accounts ENABLE ROW LEVEL SECURITY;
+	ALTER TABLE accounts FORCE ROW LEVEL SECURITY;
+	CREATE POLICY accounts_tenant_isolation ON accounts USING (current_setting('app.tenant_scoped', true) = 'on' AND current_setting('app.tenant_id', true) IN (tenant_id, '*'));
 	`
 	_, err := s.db.Exec(ctx, schemaDDL)
 	return err
@@ -71,3 +86,167 @@ func (s SchemaUtils) CreateAccountsTable(ctx context.Context) error {
 func (s SchemaUtils) DropAccountsTable(ctx context.Context) error {
 	return s.db.DropTable(ctx, "accounts")
 }
+
+// CreateStandingOrdersTable creates the standing orders table used for unit and integration tests
+func (s SchemaUtils) CreateStandingOrdersTable(ctx context.Context) error {
+	schemaDDL := `
+	DROP TABLE IF EXISTS standing_orders;
+
+	-- standing orders relation
+	CREATE TABLE standing_orders(
+		order_id VARCHAR(255) NOT NULL,
+		account_id VARCHAR(255) NOT NULL,
+		beneficiary_account_id VARCHAR(255) NOT NULL,
+		amount NUMERIC(19, 2) NOT NULL,
+		frequency VARCHAR(50) NOT NULL,
+		status VARCHAR(50) NOT NULL,
+		next_execution_date TIMESTAMP WITH TIME ZONE NULL,
+		end_date TIMESTAMP WITH TIME ZONE NULL,
+		reference VARCHAR(255) NOT NULL,
+		failed_attempts INTEGER NOT NULL,
+		last_failure_reason TEXT NOT NULL,
+		next_attempt_at TIMESTAMP WITH TIME ZONE NULL,
+		last_execution_date TIMESTAMP WITH TIME ZONE NULL,
+		tenant_id VARCHAR(63) NOT NULL DEFAULT '',
+		debited BOOLEAN NOT NULL DEFAULT FALSE,
+
+		PRIMARY KEY (order_id)
+	);
+
+	-- the records are only accessible within the tenant the transaction is scoped to
+	ALTER TABLE standing_orders ENABLE ROW LEVEL SECURITY;
+	ALTER TABLE standing_orders FORCE ROW LEVEL SECURITY;
+	CREATE POLICY standing_orders_tenant_isolation ON standing_orders USING (current_setting('app.tenant_scoped', true) = 'on' AND current_setting('app.tenant_id', true) IN (tenant_id, '*'));
+	`
+	_, err := s.db.Exec(ctx, schemaDDL)
+	return err
+}
+
+// DropStandingOrdersTable drops the standing orders table used in unit test
+// This is useful for resource cleanup after a unit test
+func (s SchemaUtils) DropStandingOrdersTable(ctx context.Context) error {
+	return s.db.DropTable(ctx, "standing_orders")
+}
+
+// CreateTransactionsTable creates the account transactions table used for unit and integration tests
+func (s SchemaUtils) CreateTransactionsTable(ctx context.Context) error {
+	schemaDDL := `
+	DROP TABLE IF EXISTS account_transactions;
+
+	-- account transactions relation
+	CREATE TABLE account_transactions(
+		account_id VARCHAR(255) NOT NULL,
+		revision_number INTEGER NOT NULL,
+		sequence INTEGER NOT NULL,
+		transaction_type VARCHAR(50) NOT NULL,
+		amount NUMERIC(19, 2) NOT NULL,
+		balance_after NUMERIC(19, 2) NOT NULL,
+		description TEXT NOT NULL,
+		booked_at TIMESTAMP WITH TIME ZONE NOT NULL,
+		tenant_id VARCHAR(63) NOT NULL DEFAULT '',
+
+		PRIMARY KEY (account_id, revision_number, sequence)
+	);
+
+	-- the records are only accessible within the tenant the transaction is scoped to
+	ALTER TABLE account_transactions ENABLE ROW LEVEL SECURITY;
+	ALTER TABLE account_transactions FORCE ROW LEVEL SECURITY;
+	CREATE POLICY account_transactions_tenant_isolation ON account_transactions USING (current_setting('app.tenant_scoped', true) = 'on' AND current_setting('app.tenant_id', true) IN (tenant_id, '*'));
+	`
+	_, err := s.db.Exec(ctx, schemaDDL)
+	return err
+}
+
+// DropTransactionsTable drops the account transactions table used in unit test
+// This is useful for resource cleanup after a unit test
+func (s SchemaUtils) DropTransactionsTable(ctx context.Context) error {
+	return s.db.DropTable(ctx, "account_transactions")
+}
+
+// CreateAccountEventsTable creates the account events table used for unit and integration tests
+func (s SchemaUtils) CreateAccountEventsTable(ctx context.Context) error {
+	schemaDDL := `
+	DROP TABLE IF EXISTS account_events;
+
+	-- account events ledger relation
+	CREATE TABLE account_events(
+		account_id VARCHAR(255) NOT NULL,
+		revision_number INTEGER NOT NULL,
+		revision_date TIMESTAMP WITH TIME ZONE NOT NULL,
+		event_type VARCHAR(255) NOT NULL,
+		event_data BYTEA NOT NULL,
+		tenant_id VARCHAR(63) NOT NULL DEFAULT '',
+
+		PRIMARY KEY (account_id, revision_number)
+	);
+
+	-- the records are only accessible within the tenant the transaction is scoped to
+	ALTER TABLE account_events ENABLE ROW LEVEL SECURITY;
+	ALTER TABLE account_events FORCE ROW LEVEL SECURITY;
+	CREATE POLICY account_events_tenant_isolation ON account_events USING (current_setting('app.tenant_scoped', true) = 'on' AND current_setting('app.tenant_id', true) IN (tenant_id, '*'));
+	`
+	_, err := s.db.Exec(ctx, schemaDDL)
+	return err
+}
+
+// DropAccountEventsTable drops the account events table used in unit test
+// This is useful for resource cleanup after a unit test
+func (s SchemaUtils) DropAccountEventsTable(ctx context.Context) error {
+	return s.db.DropTable(ctx, "account_events")
+}
+
+// CreateAuditLogTable creates the audit log table used for unit and integration tests
+func (s SchemaUtils) CreateAuditLogTable(ctx context.Context) error {
+	schemaDDL := `
+	DROP TABLE IF EXISTS audit_log;
+
+	-- audit trail relation
+	CREATE TABLE audit_log(
+		entity_id VARCHAR(255) NOT NULL,
+		revision_number INTEGER NOT NULL,
+		revision_date TIMESTAMP WITH TIME ZONE NOT NULL,
+		event_type VARCHAR(255) NOT NULL,
+		caller_subject VARCHAR(255) NOT NULL DEFAULT '',
+		client_ip VARCHAR(64) NOT NULL DEFAULT '',
+		request_id VARCHAR(255) NOT NULL DEFAULT '',
+		tenant_id VARCHAR(63) NOT NULL DEFAULT '',
+
+		PRIMARY KEY (entity_id, revision_number)
+	);
+
+	-- the records are only accessible within the tenant the transaction is scoped to
+	ALTER TABLE audit_log ENABLE ROW LEVEL SECURITY;
+	ALTER TABLE audit_log FORCE ROW LEVEL SECURITY;
+	CREATE POLICY audit_log_tenant_isolation ON audit_log USING (current_setting('app.tenant_scoped', true) = 'on' AND current_setting('app.tenant_id', true) IN (tenant_id, '*'));
+	`
+	_, err := s.db.Exec(ctx, schemaDDL)
+	return err
+}
+
+// DropAuditLogTable drops the audit log table used in unit test
+// This is useful for resource cleanup after a unit test
+func (s SchemaUtils) DropAuditLogTable(ctx context.Context) error {
+	return s.db.DropTable(ctx, "audit_log")
+}
+
+// CreateRateLimitBucketsTable creates the rate limit buckets table used for unit and integration tests
+func (s SchemaUtils) CreateRateLimitBucketsTable(ctx context.Context) error {
+	schemaDDL := `
+	DROP TABLE IF EXISTS rate_limit_buckets;
+
+	-- rate limit token buckets relation
+	CREATE TABLE rate_limit_buckets(
+		bucket_key VARCHAR(255) NOT NULL PRIMARY KEY,
+		tokens DOUBLE PRECISION NOT NULL,
+		updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+	);
+	`
+	_, err := s.db.Exec(ctx, schemaDDL)
+	return err
+}
+
+// DropRateLimitBucketsTable drops the rate limit buckets table used in unit test
+// This is useful for resource cleanup after a unit test
+func (s SchemaUtils) DropRateLimitBucketsTable(ctx context.Context) error {
+	return s.db.DropTable(ctx, "rate_limit_buckets")
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
 )
@@ -9,6 +10,21 @@ import (
 // Storage represents the storage API
 type Storage interface {
 	Shutdown(ctx context.Context) error
-	PersistAccount(ctx context.Context, account *pb.BankAccount) error
-	GetAccounts(ctx context.Context, accountIDs []string) (accounts []*pb.BankAccount, err error)
+	Ping(ctx context.Context) error
+	PersistAccount(ctx context.Context, account *pb.BankAccount, revision Revision) error
+	GetAccounts(ctx context.Context, tenantID string, accountIDs []string) (accounts []*AccountRecord, err error)
+	GetOwnerAccountIDs(ctx context.Context, tenantID, accountOwner string) (accountIDs []string, err error)
+	EraseAccountOwner(ctx context.Context, tenantID, accountOwner string) (accountIDs []string, err error)
+	RecordAccountActivity(ctx context.Context, accountID string, activityAt time.Time) error
+	GetInactiveAccounts(ctx context.Context, inactiveSince time.Time, after *AccountActivity, limit int) (accounts []*AccountActivity, err error)
+	PersistStandingOrder(ctx context.Context, standingOrder *pb.StandingOrder) error
+	GetDueStandingOrders(ctx context.Context, asOf time.Time, limit int) (standingOrders []*pb.StandingOrder, err error)
+	PersistTransactions(ctx context.Context, tenantID string, transactions []*pb.Transaction) error
+	GetTransactions(ctx context.Context, tenantID, accountID string, startTime, endTime time.Time) (transactions []*pb.Transaction, err error)
+	GetLastTransaction(ctx context.Context, tenantID, accountID string, before time.Time) (*pb.Transaction, error)
+	PersistAccountEvent(ctx context.Context, event *AccountEvent) error
+	GetAccountEvents(ctx context.Context, tenantID, accountID string, untilRevision int32, untilTime time.Time) (events []*AccountEvent, err error)
+	PersistAuditEntry(ctx context.Context, entry *AuditEntry) error
+	GetAuditEntries(ctx context.Context, tenantID, entityID string, afterRevision int32, limit int) (entries []*AuditEntry, err error)
+	TakeRateLimitToken(ctx context.Context, key string, rate float64, burst int) (available float64, err error)
 }
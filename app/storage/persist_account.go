@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/pkg/errors"
@@ -13,8 +14,15 @@ import (
 	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
 )
 
-// PersistAccount persist an account record into the database
-func (s *storage) PersistAccount(ctx context.Context, account *pb.BankAccount) error {
+// Revision holds the CoS revision a record of the read model is built from
+type Revision struct {
+	Number int32
+	Date   time.Time
+}
+
+// PersistAccount persist an account record built from a given revision into the database.
+// A record is never overwritten by an older revision
+func (s *storage) PersistAccount(ctx context.Context, account *pb.BankAccount, revision Revision) error {
 	// set the observability span
 	spanCtx, span := trace.SpanContext(ctx, "PersistAccount")
 	defer span.End()
@@ -39,8 +47,8 @@ func (s *storage) PersistAccount(ctx context.Context, account *pb.BankAccount) e
 
 	// build the transaction runner
 	runner := txRunner.
-		AddSQLBuilder(&deleteStmt{account}).
-		AddSQLBuilder(&insertionStateStmt{account})
+		AddSQLBuilder(&tenantScopeStmt{account.GetTenantId()}).
+		AddSQLBuilder(&insertionStateStmt{account, revision})
 
 	// handle the error
 	if err = runner.Run(); err != nil {
@@ -51,25 +59,9 @@ func (s *storage) PersistAccount(ctx context.Context, account *pb.BankAccount) e
 	return nil
 }
 
-type deleteStmt struct {
-	account *pb.BankAccount
-}
-
-var _ postgres.SQLBuilder = (*deleteStmt)(nil)
-
-func (s deleteStmt) ToSQL() (sqlStatement string, args []any, err error) {
-	// build the actual SQL statement and params
-	sqlStatement, args, err = sq.
-		StatementBuilder.
-		PlaceholderFormat(sq.Dollar).
-		Delete("accounts").
-		Where(sq.Eq{"account_id": s.account.GetAccountId()}).
-		ToSql()
-	return
-}
-
 type insertionStateStmt struct {
-	account *pb.BankAccount
+	account  *pb.BankAccount
+	revision Revision
 }
 
 // BuildQuery build the SQL statement and arguments to run against the database
@@ -83,13 +75,53 @@ func (s insertionStateStmt) ToSQL() (sqlStatement string, args []any, err error)
 			"account_id",
 			"account_balance",
 			"account_owner",
-			"is_closed").
+			"is_closed",
+			"account_type",
+			"is_dormant",
+			"revision_number",
+			"revision_date",
+			"tenant_id",
+			"total_fees_charged",
+			"total_fees_waived",
+			"monthly_withdrawals",
+			"withdrawals_month").
 		Values(
 			s.account.GetAccountId(),
 			s.account.GetAccountBalance(),
 			s.account.GetAccountOwner(),
 			s.account.GetIsClosed(),
+			s.account.GetAccountType().String(),
+			s.account.GetIsDormant(),
+			s.revision.Number,
+			revisionDate(s.revision),
+			s.account.GetTenantId(),
+			s.account.GetTotalFeesCharged(),
+			s.account.GetTotalFeesWaived(),
+			s.account.GetMonthlyWithdrawals(),
+			s.account.GetWithdrawalsMonth(),
 		).
+		// the account activity is recorded separately, hence it is kept on update
+		Suffix(`ON CONFLICT (account_id) DO UPDATE SET
+			account_balance = EXCLUDED.account_balance,
+			account_owner = EXCLUDED.account_owner,
+			is_closed = EXCLUDED.is_closed,
+			account_type = EXCLUDED.account_type,
+			is_dormant = EXCLUDED.is_dormant,
+			revision_number = EXCLUDED.revision_number,
+			revision_date = EXCLUDED.revision_date,
+			total_fees_charged = EXCLUDED.total_fees_charged,
+			total_fees_waived = EXCLUDED.total_fees_waived,
+			monthly_withdrawals = EXCLUDED.monthly_withdrawals,
+			withdrawals_month = EXCLUDED.withdrawals_month
+		WHERE accounts.revision_number <= EXCLUDED.revision_number`).
 		ToSql()
 	return
 }
+
+// revisionDate returns the date of a given revision or nil when it is not set
+func revisionDate(revision Revision) *time.Time {
+	if revision.Date.IsZero() {
+		return nil
+	}
+	return &revision.Date
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tochemey/gopack/otel/trace"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/tochemey/cos-go-sample/app/log"
+)
+
+// AccountEvent holds an event of the account events ledger
+type AccountEvent struct {
+	AccountID      string
+	TenantID       string
+	RevisionNumber int32
+	RevisionDate   time.Time
+	Event          *anypb.Any
+}
+
+// PersistAccountEvent records the given event into the account events ledger.
+// Events already recorded are ignored, hence replaying the read side is safe
+func (s *storage) PersistAccountEvent(ctx context.Context, event *AccountEvent) error {
+	// set the observability span
+	spanCtx, span := trace.SpanContext(ctx, "PersistAccountEvent")
+	defer span.End()
+
+	// get the context logger
+	logger := log.WithContext(ctx)
+
+	// check whether the event is set or not
+	if event == nil || event.AccountID == "" || event.Event == nil {
+		err := errors.New("the account event record is not set")
+		logger.Error(err)
+		return err
+	}
+
+	// create the insert statement
+	statement := s.sb.
+		Insert("account_events").
+		Columns(
+			"account_id",
+			"tenant_id",
+			"revision_number",
+			"revision_date",
+			"event_type",
+			"event_data").
+		Values(
+			event.AccountID,
+			event.TenantID,
+			event.RevisionNumber,
+			event.RevisionDate,
+			event.Event.GetTypeUrl(),
+			event.Event.GetValue()).
+		Suffix("ON CONFLICT DO NOTHING")
+
+	// get the sql statement and the arguments
+	query, args, err := statement.ToSql()
+	// handle the error
+	if err != nil {
+		return errors.Wrap(err, "unable to build sql statement")
+	}
+
+	// execute the statement and handle the error
+	if err = s.exec(spanCtx, event.TenantID, query, args...); err != nil {
+		err = errors.Wrap(err, "failed to persist account event record")
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
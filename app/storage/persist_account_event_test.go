@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestPersistAccountEvent(t *testing.T) {
+	t.Run("With valid account event", func(t *testing.T) {
+		ctx := context.TODO()
+		db, err := dbHandle(ctx)
+		assert.NoError(t, err)
+		// create the schema utils
+		schemaUtils := NewSchemaUtils(db)
+		// create the account events table
+		require.NoError(t, schemaUtils.CreateAccountEventsTable(ctx))
+
+		// create the storage for test
+		storage := NewTestStorage(db)
+
+		// create the account event to persist
+		credited := &pb.AccountCredited{AccountId: "account-1", Amount: 50}
+		anyEvent, err := anypb.New(credited)
+		require.NoError(t, err)
+		event := &AccountEvent{
+			AccountID:      "account-1",
+			RevisionNumber: 2,
+			RevisionDate:   time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+			Event:          anyEvent,
+		}
+
+		// persist the event twice as a replayed read side would do
+		require.NoError(t, storage.PersistAccountEvent(ctx, event))
+		require.NoError(t, storage.PersistAccountEvent(ctx, event))
+
+		// fetch the events
+		events, err := storage.GetAccountEvents(ctx, "", "account-1", 0, time.Time{})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.EqualValues(t, 2, events[0].RevisionNumber)
+		assert.True(t, event.RevisionDate.Equal(events[0].RevisionDate))
+		actual, err := events[0].Event.UnmarshalNew()
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(credited, actual))
+
+		// free resources
+		assert.NoError(t, schemaUtils.DropAccountEventsTable(ctx))
+		assert.NoError(t, db.Disconnect(ctx))
+	})
+	t.Run("With invalid account event", func(t *testing.T) {
+		ctx := context.TODO()
+		db, err := dbHandle(ctx)
+		assert.NoError(t, err)
+
+		// create the storage for test
+		storage := NewTestStorage(db)
+		require.Error(t, storage.PersistAccountEvent(ctx, &AccountEvent{AccountID: "account-1"}))
+
+		// free resources
+		assert.NoError(t, db.Disconnect(ctx))
+	})
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -25,26 +26,67 @@ func TestPersistAccount(t *testing.T) {
 		storage := NewTestStorage(db)
 
 		// create the account record to persist
-		accountID := "account-1"
+		accountID := "acme/account-1"
 		accountBal := 150.55
 		accountOwner := "John Doe"
 		account := &pb.BankAccount{
-			AccountId:      accountID,
-			AccountBalance: accountBal,
-			AccountOwner:   accountOwner,
-			IsClosed:       false,
+			AccountId:          accountID,
+			AccountBalance:     accountBal,
+			AccountOwner:       accountOwner,
+			IsClosed:           false,
+			AccountType:        pb.AccountType_SAVINGS,
+			TenantId:           "acme",
+			TotalFeesCharged:   2.5,
+			TotalFeesWaived:    1,
+			MonthlyWithdrawals: 3,
+			WithdrawalsMonth:   "2024-01",
 		}
 
 		// persist the account
-		require.NoError(t, storage.PersistAccount(ctx, account))
+		revision := Revision{Number: 1, Date: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)}
+		require.NoError(t, storage.PersistAccount(ctx, account, revision))
 
-		// fetch the record
-		accounts, err := storage.GetAccounts(ctx, []string{accountID})
+		// fetch the record within its tenant
+		accounts, err := storage.GetAccounts(ctx, "acme", []string{accountID})
 		require.NoError(t, err)
 		require.NotEmpty(t, accounts)
 		require.Len(t, accounts, 1)
 
-		assert.True(t, proto.Equal(account, accounts[0]))
+		assert.True(t, proto.Equal(account, accounts[0].GetAccount()))
+		assert.EqualValues(t, 1, accounts[0].Revision.Number)
+		assert.True(t, revision.Date.Equal(accounts[0].Revision.Date))
+
+		// free resources
+		assert.NoError(t, schemaUtils.DropAccountsTable(ctx))
+		assert.NoError(t, db.Disconnect(ctx))
+	})
+	t.Run("With older revision", func(t *testing.T) {
+		ctx := context.TODO()
+		db, err := dbHandle(ctx)
+		assert.NoError(t, err)
+		// create the schema utils
+		schemaUtils := NewSchemaUtils(db)
+		// create the accounts table
+		require.NoError(t, schemaUtils.CreateAccountsTable(ctx))
+
+		// create the storage for test
+		storage := NewTestStorage(db)
+
+		// persist the account at the second revision
+		accountID := "account-1"
+		account := &pb.BankAccount{AccountId: accountID, AccountBalance: 200, AccountOwner: "John Doe"}
+		require.NoError(t, storage.PersistAccount(ctx, account, Revision{Number: 2}))
+
+		// persist the account at the first revision
+		olderAccount := &pb.BankAccount{AccountId: accountID, AccountBalance: 100, AccountOwner: "John Doe"}
+		require.NoError(t, storage.PersistAccount(ctx, olderAccount, Revision{Number: 1}))
+
+		// the record is not overwritten by the older revision
+		accounts, err := storage.GetAccounts(ctx, "", []string{accountID})
+		require.NoError(t, err)
+		require.Len(t, accounts, 1)
+		assert.True(t, proto.Equal(account, accounts[0].GetAccount()))
+		assert.EqualValues(t, 2, accounts[0].Revision.Number)
 
 		// free resources
 		assert.NoError(t, schemaUtils.DropAccountsTable(ctx))
@@ -65,7 +107,7 @@ func TestPersistAccount(t *testing.T) {
 		// create the account record to persist
 		account := new(pb.BankAccount)
 		// persist the account
-		require.Error(t, storage.PersistAccount(ctx, account))
+		require.Error(t, storage.PersistAccount(ctx, account, Revision{}))
 
 		// free resources
 		assert.NoError(t, schemaUtils.DropAccountsTable(ctx))
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/tochemey/gopack/otel/trace"
+
+	"github.com/tochemey/cos-go-sample/app/log"
+)
+
+// AuditEntry holds an entry of the audit trail: an event of an entity together with the caller of the command that led to it
+type AuditEntry struct {
+	EntityID       string
+	TenantID       string
+	RevisionNumber int32
+	RevisionDate   time.Time
+	EventType      string
+	CallerSubject  string
+	ClientIP       string
+	RequestID      string
+}
+
+// PersistAuditEntry records the given entry into the audit trail.
+// Entries already recorded are ignored, hence replaying the read side is safe
+func (s *storage) PersistAuditEntry(ctx context.Context, entry *AuditEntry) error {
+	// set the observability span
+	spanCtx, span := trace.SpanContext(ctx, "PersistAuditEntry")
+	defer span.End()
+
+	// get the context logger
+	logger := log.WithContext(ctx)
+
+	// check whether the entry is set or not
+	if entry == nil || entry.EntityID == "" || entry.EventType == "" {
+		err := errors.New("the audit entry record is not set")
+		logger.Error(err)
+		return err
+	}
+
+	// create the insert statement
+	statement := s.sb.
+		Insert("audit_log").
+		Columns(
+			"entity_id",
+			"tenant_id",
+			"revision_number",
+			"revision_date",
+			"event_type",
+			"caller_subject",
+			"client_ip",
+			"request_id").
+		Values(
+			entry.EntityID,
+			entry.TenantID,
+			entry.RevisionNumber,
+			entry.RevisionDate,
+			entry.EventType,
+			entry.CallerSubject,
+			entry.ClientIP,
+			entry.RequestID).
+		Suffix("ON CONFLICT DO NOTHING")
+
+	// get the sql statement and the arguments
+	query, args, err := statement.ToSql()
+	// handle the error
+	if err != nil {
+		return errors.Wrap(err, "unable to build sql statement")
+	}
+
+	// execute the statement and handle the error
+	if err = s.exec(spanCtx, entry.TenantID, query, args...); err != nil {
+		err = errors.Wrap(err, "failed to persist audit entry record")
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPersistAuditEntry(t *testing.T) {
+	t.Run("With valid audit entry", func(t *testing.T) {
+		ctx := context.TODO()
+		db, err := dbHandle(ctx)
+		assert.NoError(t, err)
+		// create the schema utils
+		schemaUtils := NewSchemaUtils(db)
+		// create the audit log table
+		require.NoError(t, schemaUtils.CreateAuditLogTable(ctx))
+
+		// create the storage for test
+		storage := NewTestStorage(db)
+
+		// create the audit entry to persist
+		entry := &AuditEntry{
+			EntityID:       "account-1",
+			RevisionNumber: 2,
+			RevisionDate:   time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+			EventType:      "accounts.v1.AccountDebited",
+			CallerSubject:  "customer-1",
+			ClientIP:       "10.0.0.1",
+			RequestID:      "request-1",
+		}
+
+		// persist the entry twice as a replayed read side would do
+		require.NoError(t, storage.PersistAuditEntry(ctx, entry))
+		require.NoError(t, storage.PersistAuditEntry(ctx, entry))
+
+		// fetch the entries
+		entries, err := storage.GetAuditEntries(ctx, "", "account-1", 0, 10)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.True(t, entry.RevisionDate.Equal(entries[0].RevisionDate))
+		entries[0].RevisionDate = entry.RevisionDate
+		assert.Equal(t, entry, entries[0])
+
+		// free resources
+		assert.NoError(t, schemaUtils.DropAuditLogTable(ctx))
+		assert.NoError(t, db.Disconnect(ctx))
+	})
+	t.Run("With invalid audit entry", func(t *testing.T) {
+		ctx := context.TODO()
+		db, err := dbHandle(ctx)
+		assert.NoError(t, err)
+
+		// create the storage for test
+		storage := NewTestStorage(db)
+		require.Error(t, storage.PersistAuditEntry(ctx, &AuditEntry{EntityID: "account-1"}))
+
+		// free resources
+		assert.NoError(t, db.Disconnect(ctx))
+	})
+}
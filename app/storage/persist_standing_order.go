@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+	"github.com/tochemey/gopack/otel/trace"
+	"github.com/tochemey/gopack/postgres"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/tochemey/cos-go-sample/app/log"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// PersistStandingOrder persist a standing order record into the database
+func (s *storage) PersistStandingOrder(ctx context.Context, standingOrder *pb.StandingOrder) error {
+	// set the observability span
+	spanCtx, span := trace.SpanContext(ctx, "PersistStandingOrder")
+	defer span.End()
+
+	// get the context logger
+	logger := log.WithContext(ctx)
+
+	// check whether the standing order record is set or not
+	if standingOrder == nil || proto.Equal(standingOrder, new(pb.StandingOrder)) {
+		err := errors.New("the standing order data record is not set")
+		logger.Error(err)
+		return err
+	}
+
+	// start a transaction runner
+	txRunner, err := postgres.NewTxRunner(spanCtx, s.db)
+	// handle the error
+	if err != nil {
+		logger.Error(errors.Wrap(err, "failed to setup database transaction"))
+		return err
+	}
+
+	// build the transaction runner
+	runner := txRunner.
+		AddSQLBuilder(&tenantScopeStmt{standingOrder.GetTenantId()}).
+		AddSQLBuilder(&deleteStandingOrderStmt{standingOrder}).
+		AddSQLBuilder(&insertStandingOrderStmt{standingOrder})
+
+	// handle the error
+	if err = runner.Run(); err != nil {
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+type deleteStandingOrderStmt struct {
+	standingOrder *pb.StandingOrder
+}
+
+var _ postgres.SQLBuilder = (*deleteStandingOrderStmt)(nil)
+
+func (s deleteStandingOrderStmt) ToSQL() (sqlStatement string, args []any, err error) {
+	// build the actual SQL statement and params
+	sqlStatement, args, err = sq.
+		StatementBuilder.
+		PlaceholderFormat(sq.Dollar).
+		Delete("standing_orders").
+		Where(sq.Eq{"order_id": s.standingOrder.GetOrderId()}).
+		ToSql()
+	return
+}
+
+type insertStandingOrderStmt struct {
+	standingOrder *pb.StandingOrder
+}
+
+var _ postgres.SQLBuilder = (*insertStandingOrderStmt)(nil)
+
+// ToSQL build the SQL statement and arguments to run against the database
+func (s insertStandingOrderStmt) ToSQL() (sqlStatement string, args []any, err error) {
+	// build the actual SQL statement and params
+	sqlStatement, args, err = sq.
+		StatementBuilder.
+		PlaceholderFormat(sq.Dollar).
+		Insert("standing_orders").
+		Columns(
+			"order_id",
+			"account_id",
+			"beneficiary_account_id",
+			"amount",
+			"frequency",
+			"status",
+			"next_execution_date",
+			"end_date",
+			"reference",
+			"failed_attempts",
+			"last_failure_reason",
+			"next_attempt_at",
+			"last_execution_date",
+			"tenant_id",
+			"debited").
+		Values(
+			s.standingOrder.GetOrderId(),
+			s.standingOrder.GetAccountId(),
+			s.standingOrder.GetBeneficiaryAccountId(),
+			s.standingOrder.GetAmount(),
+			s.standingOrder.GetFrequency().String(),
+			s.standingOrder.GetStatus().String(),
+			toNullTime(s.standingOrder.GetNextExecutionDate()),
+			toNullTime(s.standingOrder.GetEndDate()),
+			s.standingOrder.GetReference(),
+			s.standingOrder.GetFailedAttempts(),
+			s.standingOrder.GetLastFailureReason(),
+			toNullTime(s.standingOrder.GetNextAttemptAt()),
+			toNullTime(s.standingOrder.GetLastExecutionDate()),
+			s.standingOrder.GetTenantId(),
+			s.standingOrder.GetDebited(),
+		).
+		ToSql()
+	return
+}
+
+// toNullTime converts the given timestamp into a nullable time column value
+func toNullTime(timestamp *timestamppb.Timestamp) *time.Time {
+	if timestamp == nil {
+		return nil
+	}
+	t := timestamp.AsTime()
+	return &t
+}
+
+// fromNullTime converts the given nullable time column value into a timestamp
+func fromNullTime(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestPersistStandingOrder(t *testing.T) {
+	t.Run("With valid standing order record", func(t *testing.T) {
+		ctx := context.TODO()
+		db, err := dbHandle(ctx)
+		assert.NoError(t, err)
+		// create the schema utils
+		schemaUtils := NewSchemaUtils(db)
+		// create the standing orders table
+		require.NoError(t, schemaUtils.CreateStandingOrdersTable(ctx))
+
+		// create the storage for test
+		storage := NewTestStorage(db)
+
+		// create the standing order record to persist
+		dueDate := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+		standingOrder := &pb.StandingOrder{
+			OrderId:              "acme/order-1",
+			AccountId:            "acme/account-1",
+			BeneficiaryAccountId: "acme/account-2",
+			Amount:               50.25,
+			Frequency:            pb.Frequency_MONTHLY,
+			Status:               pb.StandingOrderStatus_ACTIVE,
+			NextExecutionDate:    timestamppb.New(dueDate),
+			Reference:            "rent",
+			TenantId:             "acme",
+			FailedAttempts:       1,
+			Debited:              true,
+		}
+
+		// persist the standing order twice to make sure it is replaced
+		require.NoError(t, storage.PersistStandingOrder(ctx, standingOrder))
+		require.NoError(t, storage.PersistStandingOrder(ctx, standingOrder))
+
+		// fetch the record
+		standingOrders, err := storage.GetDueStandingOrders(ctx, dueDate, 10)
+		require.NoError(t, err)
+		require.Len(t, standingOrders, 1)
+
+		assert.True(t, proto.Equal(standingOrder, standingOrders[0]))
+
+		// free resources
+		assert.NoError(t, schemaUtils.DropStandingOrdersTable(ctx))
+		assert.NoError(t, db.Disconnect(ctx))
+	})
+	t.Run("With invalid standing order record", func(t *testing.T) {
+		ctx := context.TODO()
+		db, err := dbHandle(ctx)
+		assert.NoError(t, err)
+		// create the schema utils
+		schemaUtils := NewSchemaUtils(db)
+		// create the standing orders table
+		require.NoError(t, schemaUtils.CreateStandingOrdersTable(ctx))
+
+		// create the storage for test
+		storage := NewTestStorage(db)
+
+		// persist the standing order
+		require.Error(t, storage.PersistStandingOrder(ctx, new(pb.StandingOrder)))
+
+		// free resources
+		assert.NoError(t, schemaUtils.DropStandingOrdersTable(ctx))
+		assert.NoError(t, db.Disconnect(ctx))
+	})
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tochemey/gopack/otel/trace"
+
+	"github.com/tochemey/cos-go-sample/app/log"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// PersistTransactions persist the given account transactions of the given tenant into the database.
+// Transactions already recorded are ignored, hence replaying the read side is safe
+func (s *storage) PersistTransactions(ctx context.Context, tenantID string, transactions []*pb.Transaction) error {
+	// set the observability span
+	spanCtx, span := trace.SpanContext(ctx, "PersistTransactions")
+	defer span.End()
+
+	// get the context logger
+	logger := log.WithContext(ctx)
+
+	// there is nothing to persist
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	// create the insert statement
+	statement := s.sb.
+		Insert("account_transactions").
+		Columns(
+			"account_id",
+			"tenant_id",
+			"revision_number",
+			"sequence",
+			"transaction_type",
+			"amount",
+			"balance_after",
+			"description",
+			"booked_at").
+		Suffix("ON CONFLICT DO NOTHING")
+
+	// add the transaction records
+	for _, transaction := range transactions {
+		statement = statement.Values(
+			transaction.GetAccountId(),
+			tenantID,
+			transaction.GetRevisionNumber(),
+			transaction.GetSequence(),
+			transaction.GetType().String(),
+			transaction.GetAmount(),
+			transaction.GetBalanceAfter(),
+			transaction.GetDescription(),
+			transaction.GetBookedAt().AsTime(),
+		)
+	}
+
+	// get the sql statement and the arguments
+	query, args, err := statement.ToSql()
+	// handle the error
+	if err != nil {
+		return errors.Wrap(err, "unable to build sql statement")
+	}
+
+	// execute the statement and handle the error
+	if err = s.exec(spanCtx, tenantID, query, args...); err != nil {
+		err = errors.Wrap(err, "failed to persist transaction records")
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestPersistTransactions(t *testing.T) {
+	t.Run("With valid transaction records", func(t *testing.T) {
+		ctx := context.TODO()
+		db, err := dbHandle(ctx)
+		assert.NoError(t, err)
+		// create the schema utils
+		schemaUtils := NewSchemaUtils(db)
+		// create the transactions table
+		require.NoError(t, schemaUtils.CreateTransactionsTable(ctx))
+
+		// create the storage for test
+		storage := NewTestStorage(db)
+
+		// create the transaction records to persist
+		bookedAt := time.Date(2024, time.January, 1, 10, 0, 0, 0, time.UTC)
+		transactions := []*pb.Transaction{
+			{
+				AccountId:      "account-1",
+				RevisionNumber: 2,
+				Sequence:       0,
+				Type:           pb.TransactionType_DEBIT,
+				Amount:         50,
+				BalanceAfter:   51,
+				Description:    "debit",
+				BookedAt:       timestamppb.New(bookedAt),
+			},
+			{
+				AccountId:      "account-1",
+				RevisionNumber: 2,
+				Sequence:       1,
+				Type:           pb.TransactionType_FEE,
+				Amount:         1,
+				BalanceAfter:   50,
+				Description:    "fee",
+				BookedAt:       timestamppb.New(bookedAt),
+			},
+		}
+
+		// persist the transactions twice to make sure the replay is ignored
+		require.NoError(t, storage.PersistTransactions(ctx, "", transactions))
+		require.NoError(t, storage.PersistTransactions(ctx, "", transactions))
+
+		// fetch the records
+		actual, err := storage.GetTransactions(ctx, "", "account-1", bookedAt, bookedAt.Add(time.Second))
+		require.NoError(t, err)
+		require.Len(t, actual, 2)
+		assert.True(t, proto.Equal(transactions[0], actual[0]))
+		assert.True(t, proto.Equal(transactions[1], actual[1]))
+
+		// free resources
+		assert.NoError(t, schemaUtils.DropTransactionsTable(ctx))
+		assert.NoError(t, db.Disconnect(ctx))
+	})
+	t.Run("With no transaction records", func(t *testing.T) {
+		ctx := context.TODO()
+		db, err := dbHandle(ctx)
+		assert.NoError(t, err)
+
+		// create the storage for test
+		storage := NewTestStorage(db)
+		require.NoError(t, storage.PersistTransactions(ctx, "", nil))
+
+		// free resources
+		assert.NoError(t, db.Disconnect(ctx))
+	})
+}
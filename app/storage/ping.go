@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tochemey/gopack/otel/trace"
+)
+
+// Ping checks that the database is reachable
+func (s *storage) Ping(ctx context.Context) error {
+	// set the observability span
+	spanCtx, span := trace.SpanContext(ctx, "Ping")
+	defer span.End()
+
+	if _, err := s.db.Exec(spanCtx, "SELECT 1"); err != nil {
+		return errors.Wrap(err, "the database is not reachable")
+	}
+	return nil
+}
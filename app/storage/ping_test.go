@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPing(t *testing.T) {
+	ctx := context.TODO()
+	db, err := dbHandle(ctx)
+	require.NoError(t, err)
+
+	// create the storage for test
+	storage := NewTestStorage(db)
+	assert.NoError(t, storage.Ping(ctx))
+
+	// the database is not reachable once disconnected
+	require.NoError(t, db.Disconnect(ctx))
+	assert.Error(t, storage.Ping(ctx))
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/pkg/errors"
+	"github.com/tochemey/gopack/otel/trace"
+
+	"github.com/tochemey/cos-go-sample/app/log"
+)
+
+// RecordAccountActivity records the customer activity of the given account at the given time.
+// An older activity never overrides a more recent one, hence replaying the read side is safe
+func (s *storage) RecordAccountActivity(ctx context.Context, accountID string, activityAt time.Time) error {
+	// set the observability span
+	spanCtx, span := trace.SpanContext(ctx, "RecordAccountActivity")
+	defer span.End()
+
+	// get the context logger
+	logger := log.WithContext(ctx)
+
+	// create the update statement
+	statement := s.sb.
+		Update("accounts").
+		Set("last_activity_at", sq.Expr("GREATEST(COALESCE(last_activity_at, ?), ?)", activityAt, activityAt)).
+		Where(sq.Eq{"account_id": accountID})
+
+	// get the sql statement and the arguments
+	query, args, err := statement.ToSql()
+	// handle the error
+	if err != nil {
+		return errors.Wrap(err, "unable to build sql statement")
+	}
+
+	// execute the statement and handle the error
+	if err = s.exec(spanCtx, allTenants, query, args...); err != nil {
+		err = errors.Wrap(err, "failed to record the account activity")
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestRecordAccountActivity(t *testing.T) {
+	ctx := context.TODO()
+	db, err := dbHandle(ctx)
+	assert.NoError(t, err)
+	// create the schema utils
+	schemaUtils := NewSchemaUtils(db)
+	// create the accounts table
+	require.NoError(t, schemaUtils.CreateAccountsTable(ctx))
+
+	// create the storage for test
+	storage := NewTestStorage(db)
+
+	// persist the account
+	require.NoError(t, storage.PersistAccount(ctx, &pb.BankAccount{AccountId: "account-1", AccountBalance: 100, AccountOwner: "John Doe"}, Revision{Number: 1}))
+
+	// record the activities. The older activity is ignored
+	activityAt := time.Date(2024, time.January, 10, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, storage.RecordAccountActivity(ctx, "account-1", activityAt))
+	require.NoError(t, storage.RecordAccountActivity(ctx, "account-1", activityAt.AddDate(0, 0, -1)))
+
+	// persisting the account state keeps the activity
+	require.NoError(t, storage.PersistAccount(ctx, &pb.BankAccount{AccountId: "account-1", AccountBalance: 50, AccountOwner: "John Doe"}, Revision{Number: 2}))
+
+	// fetch the inactive accounts
+	actual, err := storage.GetInactiveAccounts(ctx, activityAt.Add(time.Second), nil, 10)
+	require.NoError(t, err)
+	require.Len(t, actual, 1)
+	assert.Equal(t, "account-1", actual[0].AccountID)
+	assert.True(t, activityAt.Equal(actual[0].LastActivityAt))
+
+	// free resources
+	assert.NoError(t, schemaUtils.DropAccountsTable(ctx))
+	assert.NoError(t, db.Disconnect(ctx))
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/tochemey/gopack/otel/trace"
+)
+
+// takeRateLimitTokenQuery refills the token bucket of a given key at the given rate up to the given burst and takes a token
+// when at least one is available. The bucket is locked for the time of the statement so that the instances sharing it
+// are serialized. A missing bucket is created full
+const takeRateLimitTokenQuery = `
+WITH previous AS (
+	SELECT tokens, updated_at FROM rate_limit_buckets WHERE bucket_key = $1 FOR UPDATE
+), available AS (
+	SELECT COALESCE(
+		(SELECT LEAST($3::DOUBLE PRECISION, tokens + GREATEST(0, EXTRACT(EPOCH FROM clock_timestamp() - updated_at))::DOUBLE PRECISION * $2::DOUBLE PRECISION) FROM previous),
+		$3::DOUBLE PRECISION) AS tokens
+), taken AS (
+	INSERT INTO rate_limit_buckets (bucket_key, tokens, updated_at)
+	SELECT $1, CASE WHEN tokens >= 1 THEN tokens - 1 ELSE tokens END, clock_timestamp() FROM available
+	ON CONFLICT (bucket_key) DO UPDATE SET tokens = EXCLUDED.tokens, updated_at = EXCLUDED.updated_at
+	RETURNING bucket_key
+)
+SELECT available.tokens FROM available, taken`
+
+// TakeRateLimitToken takes a token from the rate limit bucket of the given key, refilled at rate tokens per second up to burst tokens.
+// It returns the tokens available before the take; no token is taken when less than one is available
+func (s *storage) TakeRateLimitToken(ctx context.Context, key string, rate float64, burst int) (available float64, err error) {
+	// set the observability span
+	spanCtx, span := trace.SpanContext(ctx, "TakeRateLimitToken")
+	defer span.End()
+
+	// define the data type to hold the record fetched from the database
+	type row struct {
+		Tokens float64
+	}
+
+	// take the token and handle the error
+	var bucket row
+	if err = s.db.Select(spanCtx, &bucket, takeRateLimitTokenQuery, key, rate, float64(burst)); err != nil {
+		return 0, errors.Wrap(err, "failed to take a rate limit token")
+	}
+
+	return bucket.Tokens, nil
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTakeRateLimitToken(t *testing.T) {
+	ctx := context.TODO()
+	db, err := dbHandle(ctx)
+	assert.NoError(t, err)
+	// create the schema utils
+	schemaUtils := NewSchemaUtils(db)
+	// create the rate limit buckets table
+	require.NoError(t, schemaUtils.CreateRateLimitBucketsTable(ctx))
+
+	// create the storage for test
+	storage := NewTestStorage(db)
+
+	t.Run("With tokens available", func(t *testing.T) {
+		// the bucket is created full
+		available, err := storage.TakeRateLimitToken(ctx, "account:account-1", 0.001, 2)
+		require.NoError(t, err)
+		assert.InDelta(t, 2, available, 0.01)
+
+		available, err = storage.TakeRateLimitToken(ctx, "account:account-1", 0.001, 2)
+		require.NoError(t, err)
+		assert.InDelta(t, 1, available, 0.01)
+	})
+	t.Run("With empty bucket", func(t *testing.T) {
+		available, err := storage.TakeRateLimitToken(ctx, "account:account-2", 0.001, 1)
+		require.NoError(t, err)
+		assert.InDelta(t, 1, available, 0.01)
+
+		// no token is taken from the empty bucket
+		for range 2 {
+			available, err = storage.TakeRateLimitToken(ctx, "account:account-2", 0.001, 1)
+			require.NoError(t, err)
+			assert.Less(t, available, 1.0)
+		}
+	})
+
+	// free resources
+	assert.NoError(t, schemaUtils.DropRateLimitBucketsTable(ctx))
+	assert.NoError(t, db.Disconnect(ctx))
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+	"github.com/tochemey/gopack/postgres"
+)
+
+// allTenants is the tenant scope of the statements spanning the tenants, e.g. the sweeps of the scheduler.
+// It is not a valid tenant id, hence no record belongs to it
+const allTenants = "*"
+
+// tenantScopeStmt scopes the statements of the current transaction to a given tenant.
+// The row level security policies of the read model only grant access to the records of that tenant,
+// or to every record for allTenants. The statements of a transaction which is not scoped access no record
+type tenantScopeStmt struct {
+	tenantID string
+}
+
+var _ postgres.SQLBuilder = (*tenantScopeStmt)(nil)
+
+// ToSQL build the SQL statement and arguments to run against the database
+func (s tenantScopeStmt) ToSQL() (sqlStatement string, args []any, err error) {
+	// the scoped flag tells the empty id of the default tenant apart from the settings of a transaction which is not scoped,
+	// which are unset or reset to empty
+	return "SELECT set_config('app.tenant_id', $1, true), set_config('app.tenant_scoped', 'on', true)", []any{s.tenantID}, nil
+}
+
+// withinTenant runs the given function within a database transaction scoped to the given tenant.
+// The transaction is committed when the function succeeds and rolled back otherwise
+func (s *storage) withinTenant(ctx context.Context, tenantID string, fn func(tx pgx.Tx) error) error {
+	// start the transaction
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{})
+	// handle the error
+	if err != nil {
+		return errors.Wrap(err, "failed to setup database transaction")
+	}
+	// the rollback is a no-op once the transaction is committed
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// scope the transaction to the tenant
+	query, args, _ := tenantScopeStmt{tenantID}.ToSQL()
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		return errors.Wrap(err, "failed to scope the database transaction to the tenant")
+	}
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// selectAll fetches the rows of the given query within the given tenant and scans them into dst.
+// It returns nil when there are no rows to fetch
+func (s *storage) selectAll(ctx context.Context, tenantID string, dst any, query string, args ...any) error {
+	return s.withinTenant(ctx, tenantID, func(tx pgx.Tx) error {
+		if err := pgxscan.Select(ctx, tx, dst, query, args...); err != nil && !pgxscan.NotFound(err) {
+			return err
+		}
+		return nil
+	})
+}
+
+// exec executes the given statement within the given tenant
+func (s *storage) exec(ctx context.Context, tenantID string, query string, args ...any) error {
+	return s.withinTenant(ctx, tenantID, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, query, args...)
+		return err
+	})
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantScope(t *testing.T) {
+	ctx := context.TODO()
+	db, err := dbHandle(ctx)
+	require.NoError(t, err)
+	// create the schema utils
+	schemaUtils := NewSchemaUtils(db)
+	// create the accounts table
+	require.NoError(t, schemaUtils.CreateAccountsTable(ctx))
+
+	// let insert some accounts record into the database
+	insertStatement := `
+	INSERT INTO accounts(account_id, account_balance, account_owner, is_closed, tenant_id)
+	VALUES
+	    ('account-1', 500.00, 'John Doe', FALSE, ''),
+	    ('acme/account-1', 1000.00, 'John Doe', FALSE, 'acme'),
+	    ('globex/account-1', 300.00, 'John Doe', FALSE, 'globex');
+	`
+	_, err = db.Exec(ctx, insertStatement)
+	require.NoError(t, err)
+
+	// create the storage for test
+	storage := NewTestStorage(db).(*storage)
+
+	t.Run("With statements scoped to the tenant", func(t *testing.T) {
+		var tenants []string
+		require.NoError(t, storage.selectAll(ctx, "acme", &tenants, "SELECT current_setting('app.tenant_id')"))
+		assert.Equal(t, []string{"acme"}, tenants)
+	})
+	t.Run("With records of other tenants not accessible", func(t *testing.T) {
+		// the test database user is a superuser, hence the policies are checked on behalf of a plain role.
+		// The role only lives within the transaction which is rolled back
+		tx, err := db.BeginTx(ctx, pgx.TxOptions{})
+		require.NoError(t, err)
+		defer func() { _ = tx.Rollback(ctx) }()
+		for _, statement := range []string{
+			"CREATE ROLE tenant_scope_test",
+			"GRANT SELECT ON accounts TO tenant_scope_test",
+			"SET LOCAL ROLE tenant_scope_test",
+		} {
+			_, err = tx.Exec(ctx, statement)
+			require.NoError(t, err)
+		}
+
+		selectAccountIDs := func() []string {
+			var accountIDs []string
+			require.NoError(t, pgxscan.Select(ctx, tx, &accountIDs, "SELECT account_id FROM accounts ORDER BY account_id"))
+			return accountIDs
+		}
+		scope := func(tenantID string) {
+			query, args, err := tenantScopeStmt{tenantID}.ToSQL()
+			require.NoError(t, err)
+			_, err = tx.Exec(ctx, query, args...)
+			require.NoError(t, err)
+		}
+
+		// the transactions which are not scoped access no record, not even the ones of the default tenant
+		assert.Empty(t, selectAccountIDs())
+		_, err = tx.Exec(ctx, "SELECT set_config('app.tenant_id', '', true)")
+		require.NoError(t, err)
+		assert.Empty(t, selectAccountIDs())
+
+		scope("")
+		assert.Equal(t, []string{"account-1"}, selectAccountIDs())
+		scope("acme")
+		assert.Equal(t, []string{"acme/account-1"}, selectAccountIDs())
+		scope("globex")
+		assert.Equal(t, []string{"globex/account-1"}, selectAccountIDs())
+		scope(allTenants)
+		assert.Equal(t, []string{"account-1", "acme/account-1", "globex/account-1"}, selectAccountIDs())
+	})
+
+	// free resources
+	assert.NoError(t, schemaUtils.DropAccountsTable(ctx))
+	assert.NoError(t, db.Disconnect(ctx))
+}
@@ -7,50 +7,43 @@ import (
 
 	"github.com/tochemey/cos-go-sample/app/log"
 	"github.com/tochemey/cos-go-sample/app/storage"
-	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
 )
 
 type Handler struct {
 	dataStore storage.Storage
+	hub       *Hub
 }
 
-func NewSubscriptionHandler(dataStore storage.Storage) *Handler {
+// NewSubscriptionHandler creates a handler of the subscription events.
+// The events are published to the given hub watchers when the hub is set.
+func NewSubscriptionHandler(dataStore storage.Storage, hub *Hub) *Handler {
 	return &Handler{
 		dataStore: dataStore,
+		hub:       hub,
 	}
 }
 
 func (s *Handler) HandleEvents(ctx context.Context, events []any) error {
-	logger := log.WithContext(ctx)
+	logger := log.FromContext(ctx)
 	for _, e := range events {
 		evt, ok := e.(*Event)
 		if !ok {
 			if ue, ok := e.(*UnknownEvent); ok {
-				logger.Infof("received unknown event: type=%s", ue.TypeURL)
+				logger.Info("received unknown event", log.String("type", ue.TypeURL))
 			}
 			continue
 		}
 
-		entityID := ""
-		if evt.Meta != nil {
-			entityID = evt.Meta.EntityId
-		}
+		// the event is logged with its account owner and amounts masked
+		logger.Info("event received",
+			log.String("entity_id", evt.Meta.GetEntityId()),
+			log.Int32("revision", evt.Meta.GetRevisionNumber()),
+			log.String("type", string(proto.MessageName(evt.Event))),
+			log.Proto("event", evt.Event))
 
-		logger.Infof("event received: entity_id=%s revision=%d type=%s",
-			entityID, evt.Meta.GetRevisionNumber(), proto.MessageName(evt.Event))
-
-		switch v := evt.Event.(type) {
-		case *pb.AccountOpened:
-			logger.Infof("  AccountOpened: account_id=%s owner=%s balance=%.2f",
-				v.AccountId, v.AccountOwner, v.Balance)
-		case *pb.AccountCredited:
-			logger.Infof("  AccountCredited: account_id=%s amount=%.2f",
-				v.AccountId, v.Amount)
-		case *pb.AccountDebited:
-			logger.Infof("  AccountDebited: account_id=%s amount=%.2f",
-				v.AccountId, v.Amount)
-		default:
-			logger.Infof("  event: %+v", evt.Event)
+		// fan out the event to the entity watchers
+		if s.hub != nil {
+			s.hub.Publish(evt)
 		}
 	}
 	return nil
@@ -0,0 +1,43 @@
+package subscription
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
+)
+
+func TestHandleEvents(t *testing.T) {
+	t.Run("With events published to the hub", func(t *testing.T) {
+		ctx := context.TODO()
+		hub := NewHub(10)
+		watcher := hub.Watch("account-1")
+		defer hub.Unwatch(watcher)
+
+		event := &Event{
+			Event:          &pb.AccountCredited{AccountId: "account-1", Amount: 10},
+			ResultingState: &pb.BankAccount{AccountId: "account-1", AccountBalance: 110},
+			Meta:           &cospb.MetaData{EntityId: "account-1", RevisionNumber: 2},
+		}
+
+		handler := NewSubscriptionHandler(nil, hub)
+		require.NoError(t, handler.HandleEvents(ctx, []any{event, &UnknownEvent{TypeURL: "unknown"}}))
+
+		require.Len(t, watcher.Events(), 1)
+		assert.Same(t, event, <-watcher.Events())
+	})
+	t.Run("Without hub", func(t *testing.T) {
+		ctx := context.TODO()
+		event := &Event{
+			Event: &pb.AccountCredited{AccountId: "account-1", Amount: 10},
+			Meta:  &cospb.MetaData{EntityId: "account-1", RevisionNumber: 2},
+		}
+
+		handler := NewSubscriptionHandler(nil, nil)
+		assert.NoError(t, handler.HandleEvents(ctx, []any{event}))
+	})
+}
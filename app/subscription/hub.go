@@ -0,0 +1,191 @@
+package subscription
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// DefaultWatchBufferSize is the default number of events buffered per watcher
+const DefaultWatchBufferSize = 64
+
+// Hub fans out the events received from the CoS subscription to the watchers of the entities.
+// Publishing never blocks: every watcher buffers a bounded number of events, and a watcher
+// that falls behind the buffer is evicted and flagged as lagging so that it can resume later.
+type Hub struct {
+	mu         sync.RWMutex
+	watchers   map[string]map[*Watcher]struct{}
+	bufferSize int
+	closed     bool
+}
+
+// enforce compilation error when the Hub does not fully implement the Subscriber interface
+var _ Subscriber = (*Hub)(nil)
+
+// NewHub creates an instance of Hub buffering bufferSize events per watcher
+func NewHub(bufferSize int) *Hub {
+	if bufferSize <= 0 {
+		bufferSize = DefaultWatchBufferSize
+	}
+	return &Hub{
+		watchers:   make(map[string]map[*Watcher]struct{}),
+		bufferSize: bufferSize,
+	}
+}
+
+// Watch registers a watcher of the events of a given entity. When event types are given, only the events
+// of those types are sent to the watcher. The watcher must be released with Unwatch once done.
+// The watchers of a closed hub have their events channel closed right away.
+func (h *Hub) Watch(entityID string, eventTypes ...protoreflect.FullName) *Watcher {
+	watcher := &Watcher{
+		entityID: entityID,
+		events:   make(chan *Event, h.bufferSize),
+	}
+	if len(eventTypes) > 0 {
+		watcher.eventTypes = make(map[protoreflect.FullName]struct{}, len(eventTypes))
+		for _, eventType := range eventTypes {
+			watcher.eventTypes[eventType] = struct{}{}
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// the watchers of a closed hub are released right away
+	if h.closed {
+		close(watcher.events)
+		return watcher
+	}
+	if _, ok := h.watchers[entityID]; !ok {
+		h.watchers[entityID] = make(map[*Watcher]struct{})
+	}
+	h.watchers[entityID][watcher] = struct{}{}
+	return watcher
+}
+
+// Unwatch releases a given watcher. It is safe to call it on an evicted watcher
+func (h *Hub) Unwatch(watcher *Watcher) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(watcher)
+}
+
+// Subscribe registers a watcher of the events published to the hub. It implements the Subscriber interface
+func (h *Hub) Subscribe(_ context.Context, entityID string, eventTypes ...protoreflect.FullName) (*Watcher, error) {
+	return h.Watch(entityID, eventTypes...), nil
+}
+
+// Unsubscribe releases a given watcher. It implements the Subscriber interface
+func (h *Hub) Unsubscribe(_ context.Context, watcher *Watcher) error {
+	h.Unwatch(watcher)
+	return nil
+}
+
+// Publish sends a given event to the watchers of its entity accepting its type.
+// The watchers whose buffer is full are evicted.
+func (h *Hub) Publish(event *Event) {
+	entityID := event.Meta.GetEntityId()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for watcher := range h.watchers[entityID] {
+		if !watcher.accepts(event) {
+			continue
+		}
+
+		select {
+		case watcher.events <- event:
+		default:
+			// the watcher is too slow to keep up with the entity changes
+			watcher.lagging = true
+			h.remove(watcher)
+		}
+	}
+}
+
+// Watchers returns the number of watchers of a given entity
+func (h *Hub) Watchers(entityID string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.watchers[entityID])
+}
+
+// Evict releases all the watchers of a given entity and flags them as lagging so that they can resume later.
+// It is used when the events of the entity can no longer be delivered
+func (h *Hub) Evict(entityID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for watcher := range h.watchers[entityID] {
+		watcher.lagging = true
+		h.remove(watcher)
+	}
+}
+
+// Close releases all the watchers, e.g. on shutdown so that the streams of the watchers end before the servers are drained.
+// The watchers registered afterwards are released right away
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for _, watchers := range h.watchers {
+		for watcher := range watchers {
+			h.remove(watcher)
+		}
+	}
+}
+
+// remove unregisters a watcher and closes its events channel. It must be called with the lock held
+func (h *Hub) remove(watcher *Watcher) {
+	watchers, ok := h.watchers[watcher.entityID]
+	if !ok {
+		return
+	}
+	if _, ok := watchers[watcher]; !ok {
+		return
+	}
+	delete(watchers, watcher)
+	if len(watchers) == 0 {
+		delete(h.watchers, watcher.entityID)
+	}
+	close(watcher.events)
+}
+
+// Watcher receives the events of a given entity
+type Watcher struct {
+	entityID string
+	events   chan *Event
+	// eventTypes holds the types of the events sent to the watcher. Every event is sent when it is empty
+	eventTypes map[protoreflect.FullName]struct{}
+	// lagging is set before the events channel is closed
+	lagging bool
+}
+
+// Events returns the channel of the entity events.
+// The channel is closed when the watcher is released or evicted.
+func (w *Watcher) Events() <-chan *Event {
+	return w.events
+}
+
+// Lagging checks whether the watcher has been evicted because it did not keep up with the entity changes.
+// It must be called once the events channel is closed.
+func (w *Watcher) Lagging() bool {
+	return w.lagging
+}
+
+// EntityID returns the id of the watched entity
+func (w *Watcher) EntityID() string {
+	return w.entityID
+}
+
+// accepts checks whether a given event is of a type sent to the watcher
+func (w *Watcher) accepts(event *Event) bool {
+	if len(w.eventTypes) == 0 {
+		return true
+	}
+	if event.Event == nil {
+		return false
+	}
+	_, ok := w.eventTypes[proto.MessageName(event.Event)]
+	return ok
+}
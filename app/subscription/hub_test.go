@@ -0,0 +1,149 @@
+package subscription
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
+)
+
+func TestHub(t *testing.T) {
+	t.Run("With events fanned out to the entity watchers", func(t *testing.T) {
+		hub := NewHub(10)
+		watcher1 := hub.Watch("account-1")
+		watcher2 := hub.Watch("account-1")
+		other := hub.Watch("account-2")
+		assert.Equal(t, 2, hub.Watchers("account-1"))
+
+		event := &Event{
+			Event: &pb.AccountCredited{AccountId: "account-1", Amount: 10},
+			Meta:  &cospb.MetaData{EntityId: "account-1", RevisionNumber: 2},
+		}
+		hub.Publish(event)
+
+		require.Len(t, watcher1.Events(), 1)
+		assert.Same(t, event, <-watcher1.Events())
+		require.Len(t, watcher2.Events(), 1)
+		assert.Same(t, event, <-watcher2.Events())
+		assert.Empty(t, other.Events())
+
+		// free resources
+		hub.Unwatch(watcher1)
+		hub.Unwatch(watcher2)
+		hub.Unwatch(other)
+		assert.Zero(t, hub.Watchers("account-1"))
+		assert.Zero(t, hub.Watchers("account-2"))
+	})
+	t.Run("With watcher released", func(t *testing.T) {
+		hub := NewHub(10)
+		watcher := hub.Watch("account-1")
+		hub.Unwatch(watcher)
+
+		// the events channel is closed
+		_, ok := <-watcher.Events()
+		assert.False(t, ok)
+		assert.False(t, watcher.Lagging())
+
+		// releasing twice is a no-op
+		assert.NotPanics(t, func() { hub.Unwatch(watcher) })
+	})
+	t.Run("With slow watcher evicted", func(t *testing.T) {
+		hub := NewHub(2)
+		watcher := hub.Watch("account-1")
+
+		for i := 1; i <= 3; i++ {
+			hub.Publish(&Event{Meta: &cospb.MetaData{EntityId: "account-1", RevisionNumber: int32(i)}})
+		}
+
+		// the buffered events are still delivered before the channel is closed
+		var revisions []int32
+		for event := range watcher.Events() {
+			revisions = append(revisions, event.Meta.GetRevisionNumber())
+		}
+		assert.Equal(t, []int32{1, 2}, revisions)
+		assert.True(t, watcher.Lagging())
+		assert.Zero(t, hub.Watchers("account-1"))
+
+		// releasing an evicted watcher is a no-op
+		assert.NotPanics(t, func() { hub.Unwatch(watcher) })
+	})
+	t.Run("With events filtered by type", func(t *testing.T) {
+		hub := NewHub(10)
+		watcher := hub.Watch("account-1", proto.MessageName(&pb.AccountDebited{}), proto.MessageName(&pb.FeeCharged{}))
+		all := hub.Watch("account-1")
+
+		credited := &Event{Event: &pb.AccountCredited{AccountId: "account-1"}, Meta: &cospb.MetaData{EntityId: "account-1", RevisionNumber: 2}}
+		debited := &Event{Event: &pb.AccountDebited{AccountId: "account-1"}, Meta: &cospb.MetaData{EntityId: "account-1", RevisionNumber: 3}}
+		hub.Publish(credited)
+		hub.Publish(debited)
+
+		// only the events of the given types are sent to the filtered watcher
+		require.Len(t, watcher.Events(), 1)
+		assert.Same(t, debited, <-watcher.Events())
+		assert.Len(t, all.Events(), 2)
+
+		// free resources
+		hub.Unwatch(watcher)
+		hub.Unwatch(all)
+	})
+	t.Run("With entity watchers evicted", func(t *testing.T) {
+		hub := NewHub(10)
+		watcher := hub.Watch("account-1")
+		other := hub.Watch("account-2")
+
+		hub.Evict("account-1")
+
+		_, ok := <-watcher.Events()
+		assert.False(t, ok)
+		assert.True(t, watcher.Lagging())
+		assert.Zero(t, hub.Watchers("account-1"))
+		assert.Equal(t, 1, hub.Watchers("account-2"))
+
+		// free resources
+		hub.Unwatch(other)
+	})
+	t.Run("With hub closed", func(t *testing.T) {
+		hub := NewHub(10)
+		watcher := hub.Watch("account-1")
+		other := hub.Watch("account-2")
+
+		hub.Close()
+
+		_, ok := <-watcher.Events()
+		assert.False(t, ok)
+		assert.False(t, watcher.Lagging())
+		_, ok = <-other.Events()
+		assert.False(t, ok)
+		assert.Zero(t, hub.Watchers("account-1"))
+		assert.Zero(t, hub.Watchers("account-2"))
+
+		// the watchers registered afterwards are released right away
+		late := hub.Watch("account-1")
+		_, ok = <-late.Events()
+		assert.False(t, ok)
+		assert.Zero(t, hub.Watchers("account-1"))
+		// releasing them is harmless
+		hub.Unwatch(late)
+	})
+	t.Run("With subscriber", func(t *testing.T) {
+		ctx := context.TODO()
+		hub := NewHub(10)
+
+		watcher, err := hub.Subscribe(ctx, "account-1")
+		require.NoError(t, err)
+		assert.Equal(t, "account-1", watcher.EntityID())
+		assert.Equal(t, 1, hub.Watchers("account-1"))
+
+		assert.NoError(t, hub.Unsubscribe(ctx, watcher))
+		assert.Zero(t, hub.Watchers("account-1"))
+	})
+	t.Run("With default buffer size", func(t *testing.T) {
+		hub := NewHub(0)
+		assert.Equal(t, DefaultWatchBufferSize, hub.bufferSize)
+	})
+}
@@ -2,34 +2,86 @@ package subscription
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
-	gopack "github.com/tochemey/gopack/grpc"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 
+	"github.com/tochemey/cos-go-sample/app/grpconfig"
 	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
 )
 
-// Manager manages Chief of State event subscriptions.
-// It subscribes to all events on Start and unsubscribes on Stop.
+// unsubscribeTimeout is how long the unsubscribe requests sent to CoS may take
+const unsubscribeTimeout = 5 * time.Second
+
+// Manager manages the Chief of State events subscriptions of the watchers registered with Subscribe: the watchers of an entity
+// share a single CoS subscription, opened with the first watcher and closed once the last one is released or the manager stopped.
 type Manager struct {
 	cosHost   string
 	cosPort   int
 	handler   *Handler
 	conn      interface{ Close() error }
 	cosClient cospb.ChiefOfStateServiceClient
-	subID     string
-	subMux    sync.RWMutex
 	stopCh    chan struct{}
 	stopOnce  sync.Once
+
+	// streams holds the per-entity subscriptions by entity id
+	streams map[string]*entityStream
+	// watchers holds the per-entity subscription of every registered watcher
+	watchers  map[*Watcher]*entityStream
+	streamsMu sync.Mutex
+}
+
+// enforce compilation error when the Manager does not fully implement the Subscriber interface
+var _ Subscriber = (*Manager)(nil)
+
+// entityStream is a CoS subscription to the events of an entity shared by the watchers of that entity
+type entityStream struct {
+	entityID       string
+	subscriptionID string
+	ctx            context.Context
+	cancel         context.CancelFunc
+	// watchers counts the watchers of the entity, including the ones awaiting the subscription to be opened
+	watchers int
+
+	// opened is closed once the subscription is opened with CoS, or failed to be opened with openErr
+	opened  chan struct{}
+	openErr error
+}
+
+// newEntityStream creates the subscription to the events of a given entity, opened with subscribe.
+// The subscription outlives the request of its first watcher, hence its own context
+func newEntityStream(entityID string) *entityStream {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &entityStream{
+		entityID:       entityID,
+		subscriptionID: uuid.New().String(),
+		ctx:            ctx,
+		cancel:         cancel,
+		opened:         make(chan struct{}),
+	}
+}
+
+// isOpen checks whether the subscription has been opened with CoS
+func (s *entityStream) isOpen() bool {
+	select {
+	case <-s.opened:
+		return s.openErr == nil
+	default:
+		return false
+	}
 }
 
 // NewManager creates a new subscription manager.
-func NewManager(cosHost string, cosPort int, handler *Handler) (*Manager, error) {
-	conn, err := gopack.DefaultConn(fmt.Sprintf("%s:%d", cosHost, cosPort))
+// The connection to CoS uses the given TLS configuration when it is set.
+func NewManager(cosHost string, cosPort int, tlsConfig *tls.Config, handler *Handler) (*Manager, error) {
+	conn, err := grpconfig.NewConn(fmt.Sprintf("%s:%d", cosHost, cosPort), tlsConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -40,32 +92,187 @@ func NewManager(cosHost string, cosPort int, handler *Handler) (*Manager, error)
 		conn:      conn,
 		cosClient: cospb.NewChiefOfStateServiceClient(conn),
 		stopCh:    make(chan struct{}),
+		streams:   make(map[string]*entityStream),
+		watchers:  make(map[*Watcher]*entityStream),
 	}, nil
 }
 
-// Start begins subscribing to all CoS events and forwards them to the handler.
-// It starts a background goroutine and returns immediately.
-// Call Stop to unsubscribe and clean up.
-func (m *Manager) Start(ctx context.Context) error {
-	subID := uuid.New().String()
-	req := &cospb.SubscribeAllRequest{SubscriptionId: subID}
+// Check checks the state of the subscriptions to CoS. An error is returned when the manager is stopped
+// or when the connection to CoS is failing
+func (m *Manager) Check(context.Context) error {
+	if m.stopped() {
+		return errors.New("the subscription manager is stopped")
+	}
+
+	if conn, ok := m.conn.(grpconfig.Connectivity); ok {
+		if err := grpconfig.CheckConn(conn); err != nil {
+			return errors.Wrap(err, "the subscriptions connection")
+		}
+	}
+	return nil
+}
+
+// Subscribe registers a watcher of the events of a given entity published to the handler hub.
+// When event types are given, only the events of those types are sent to the watcher.
+// The CoS subscription to the entity events is opened with its first watcher, the other watchers of the entity awaiting it.
+// The watcher must be released with Unsubscribe once done.
+func (m *Manager) Subscribe(ctx context.Context, entityID string, eventTypes ...protoreflect.FullName) (*Watcher, error) {
+	if m.handler == nil || m.handler.hub == nil {
+		return nil, errors.New("subscribe: the subscription handler has no hub")
+	}
+
+	m.streamsMu.Lock()
+	if m.stopped() {
+		m.streamsMu.Unlock()
+		return nil, errors.New("subscribe: the subscription manager is stopped")
+	}
+
+	// share the subscription of the entity with its other watchers
+	stream, ok := m.streams[entityID]
+	if !ok {
+		stream = newEntityStream(entityID)
+		m.streams[entityID] = stream
+	}
+	stream.watchers++
+	m.streamsMu.Unlock()
+
+	// open the subscription outside of the lock, so that a slow CoS call only holds the watchers of the same entity
+	if !ok {
+		m.subscribe(stream)
+	}
+
+	select {
+	case <-stream.opened:
+	case <-ctx.Done():
+		_ = m.release(stream)
+		return nil, errors.Wrap(ctx.Err(), "subscribe")
+	}
+	if stream.openErr != nil {
+		_ = m.release(stream)
+		return nil, stream.openErr
+	}
+
+	m.streamsMu.Lock()
+	// the manager may have been stopped, or the subscription closed by CoS, while the subscription was opened
+	if m.stopped() || m.streams[entityID] != stream {
+		m.streamsMu.Unlock()
+		_ = m.release(stream)
+		return nil, errors.New("subscribe: the subscription has been closed")
+	}
+	defer m.streamsMu.Unlock()
+
+	watcher := m.handler.hub.Watch(entityID, eventTypes...)
+	m.watchers[watcher] = stream
+	return watcher, nil
+}
+
+// Unsubscribe releases a given watcher. The CoS subscription to the entity events is closed with its last watcher.
+// It is safe to call it on an evicted watcher
+func (m *Manager) Unsubscribe(_ context.Context, watcher *Watcher) error {
+	m.streamsMu.Lock()
+	stream, ok := m.watchers[watcher]
+	if !ok {
+		m.streamsMu.Unlock()
+		return nil
+	}
+
+	delete(m.watchers, watcher)
+	m.handler.hub.Unwatch(watcher)
+	m.streamsMu.Unlock()
+
+	return m.release(stream)
+}
+
+// release releases a watcher of a given entity subscription. The subscription is closed with its last watcher
+func (m *Manager) release(stream *entityStream) error {
+	m.streamsMu.Lock()
+	stream.watchers--
+
+	// keep the subscription as long as the entity is watched
+	if stream.watchers > 0 {
+		m.streamsMu.Unlock()
+		return nil
+	}
+
+	// the subscription may have been closed by CoS in the meantime
+	current := m.streams[stream.entityID] == stream
+	if current {
+		delete(m.streams, stream.entityID)
+	}
+	m.streamsMu.Unlock()
+
+	stream.cancel()
+	if !current || !stream.isOpen() {
+		return nil
+	}
+	return m.unsubscribe(stream)
+}
+
+// Subscriptions returns the number of the per-entity subscriptions opened with CoS
+func (m *Manager) Subscriptions() int {
+	m.streamsMu.Lock()
+	defer m.streamsMu.Unlock()
+	return len(m.streams)
+}
 
-	stream, err := m.cosClient.SubscribeAll(ctx, req)
+// subscribe opens the CoS subscription to the events of the entity of a given stream and forwards them to the handler.
+// The stream is marked as opened once done and, when failing to be opened, is removed so that the next watchers retry
+func (m *Manager) subscribe(stream *entityStream) {
+	defer close(stream.opened)
+
+	upstream, err := m.cosClient.Subscribe(stream.ctx, &cospb.SubscribeRequest{SubscriptionId: stream.subscriptionID, EntityId: stream.entityID})
 	if err != nil {
-		return fmt.Errorf("subscribe all: %w", err)
+		stream.openErr = errors.Wrap(err, "subscribe")
+		m.streamsMu.Lock()
+		if m.streams[stream.entityID] == stream {
+			delete(m.streams, stream.entityID)
+		}
+		m.streamsMu.Unlock()
+		stream.cancel()
+		return
 	}
 
-	m.subMux.Lock()
-	m.subID = subID
-	m.subMux.Unlock()
+	go func() {
+		m.receiveLoop(stream.ctx, upstream.Recv)
+
+		// the subscription has been released or the manager stopped
+		if stream.ctx.Err() != nil || m.stopped() {
+			return
+		}
 
-	go m.receiveLoop(ctx, stream)
+		// the subscription has been closed by CoS. Evict the watchers so that they can resume with a new subscription
+		m.streamsMu.Lock()
+		if m.streams[stream.entityID] == stream {
+			delete(m.streams, stream.entityID)
+			m.handler.hub.Evict(stream.entityID)
+		}
+		m.streamsMu.Unlock()
+		stream.cancel()
+	}()
+}
+
+// unsubscribe closes the CoS subscription to the events of an entity
+func (m *Manager) unsubscribe(stream *entityStream) error {
+	unsubCtx, cancel := context.WithTimeout(context.Background(), unsubscribeTimeout)
+	defer cancel()
+	_, err := m.cosClient.Unsubscribe(unsubCtx, &cospb.UnsubscribeRequest{SubscriptionId: stream.subscriptionID, EntityId: stream.entityID})
+	if err != nil {
+		return errors.Wrap(err, "unsubscribe")
+	}
 	return nil
 }
 
-func (m *Manager) receiveLoop(ctx context.Context, stream interface {
-	Recv() (*cospb.SubscribeAllResponse, error)
-}) {
+// stopped checks whether the manager has been stopped
+func (m *Manager) stopped() bool {
+	select {
+	case <-m.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+func (m *Manager) receiveLoop(ctx context.Context, recv func() (*cospb.SubscribeResponse, error)) {
 	for {
 		select {
 		case <-m.stopCh:
@@ -73,23 +280,14 @@ func (m *Manager) receiveLoop(ctx context.Context, stream interface {
 		case <-ctx.Done():
 			return
 		default:
-			resp, err := stream.Recv()
+			resp, err := recv()
 			if err != nil {
-				select {
-				case <-m.stopCh:
-				default:
-					// Stream closed (e.g. context cancelled during shutdown)
-				}
+				// Stream closed (e.g. context cancelled during shutdown)
 				return
 			}
 			if resp == nil {
 				continue
 			}
-			m.subMux.Lock()
-			if resp.SubscriptionId != "" {
-				m.subID = resp.SubscriptionId
-			}
-			m.subMux.Unlock()
 			events := m.convertToEvents(resp)
 			if len(events) > 0 && m.handler != nil {
 				_ = m.handler.HandleEvents(ctx, events)
@@ -98,15 +296,15 @@ func (m *Manager) receiveLoop(ctx context.Context, stream interface {
 	}
 }
 
-// convertToEvents converts a SubscribeAllResponse to events for the handler.
-func (m *Manager) convertToEvents(resp *cospb.SubscribeAllResponse) []any {
+// convertToEvents converts a subscription response to events for the handler.
+func (m *Manager) convertToEvents(resp *cospb.SubscribeResponse) []any {
 	if resp.GetEvent() == nil {
 		return nil
 	}
 
-	event, err := resp.Event.UnmarshalNew()
+	event, err := resp.GetEvent().UnmarshalNew()
 	if err != nil {
-		return []any{&UnknownEvent{TypeURL: resp.Event.GetTypeUrl(), Raw: resp.Event}}
+		return []any{&UnknownEvent{TypeURL: resp.GetEvent().GetTypeUrl(), Raw: resp.GetEvent()}}
 	}
 
 	item := &Event{
@@ -116,7 +314,7 @@ func (m *Manager) convertToEvents(resp *cospb.SubscribeAllResponse) []any {
 	}
 
 	if resp.GetResultingState() != nil {
-		state, err := resp.ResultingState.UnmarshalNew()
+		state, err := resp.GetResultingState().UnmarshalNew()
 		if err == nil {
 			item.ResultingState = state
 		}
@@ -125,22 +323,30 @@ func (m *Manager) convertToEvents(resp *cospb.SubscribeAllResponse) []any {
 	return []any{item}
 }
 
-// Stop unsubscribes from all events and closes the connection.
+// Stop closes the per-entity subscriptions and closes the connection.
 func (m *Manager) Stop(ctx context.Context) error {
 	var err error
 	m.stopOnce.Do(func() {
 		close(m.stopCh)
-		m.subMux.RLock()
-		subID := m.subID
-		m.subMux.RUnlock()
-		if subID != "" {
-			unsubCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-			_, unsubErr := m.cosClient.UnsubscribeAll(unsubCtx, &cospb.UnsubscribeAllRequest{SubscriptionId: subID})
-			if unsubErr != nil {
-				err = fmt.Errorf("unsubscribe all: %w", unsubErr)
+
+		// close the per-entity subscriptions and release their watchers
+		m.streamsMu.Lock()
+		streams, watchers := m.streams, m.watchers
+		m.streams = make(map[string]*entityStream)
+		m.watchers = make(map[*Watcher]*entityStream)
+		m.streamsMu.Unlock()
+		for watcher := range watchers {
+			m.handler.hub.Unwatch(watcher)
+		}
+		for _, stream := range streams {
+			// the subscriptions still being opened are aborted
+			open := stream.isOpen()
+			stream.cancel()
+			if open {
+				err = multierr.Append(err, m.unsubscribe(stream))
 			}
 		}
+
 		if m.conn != nil {
 			_ = m.conn.Close()
 		}
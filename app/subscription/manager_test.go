@@ -0,0 +1,280 @@
+package subscription
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
+	mocks "github.com/tochemey/cos-go-sample/mocks/gen/chief_of_state/v1"
+)
+
+// testStream is a CoS subscription stream fed by the test
+type testStream struct {
+	grpc.ClientStream
+	ctx       context.Context
+	responses chan *cospb.SubscribeResponse
+}
+
+// Recv returns the next response sent by the test
+func (s *testStream) Recv() (*cospb.SubscribeResponse, error) {
+	select {
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	case response, ok := <-s.responses:
+		if !ok {
+			return nil, io.EOF
+		}
+		return response, nil
+	}
+}
+
+// newTestManager creates a Manager publishing the events to the given hub
+func newTestManager(cosClient cospb.ChiefOfStateServiceClient, hub *Hub) *Manager {
+	return &Manager{
+		handler:   NewSubscriptionHandler(nil, hub),
+		cosClient: cosClient,
+		stopCh:    make(chan struct{}),
+		streams:   make(map[string]*entityStream),
+		watchers:  make(map[*Watcher]*entityStream),
+	}
+}
+
+// expectSubscribe expects a single subscription to the given entity and returns the responses channel of its stream
+func expectSubscribe(cosClient *mocks.ChiefOfStateServiceClient, entityID string) chan *cospb.SubscribeResponse {
+	responses := make(chan *cospb.SubscribeResponse)
+	cosClient.
+		On("Subscribe", mock.Anything, mock.MatchedBy(func(request *cospb.SubscribeRequest) bool {
+			return request.GetEntityId() == entityID && request.GetSubscriptionId() != ""
+		})).
+		Return(func(ctx context.Context, _ *cospb.SubscribeRequest, _ ...grpc.CallOption) (grpc.ServerStreamingClient[cospb.SubscribeResponse], error) {
+			return &testStream{ctx: ctx, responses: responses}, nil
+		}).
+		Once()
+	return responses
+}
+
+// newResponse creates a subscription response of the given event
+func newResponse(t *testing.T, entityID string, revision int32, event proto.Message) *cospb.SubscribeResponse {
+	anyEvent, err := anypb.New(event)
+	require.NoError(t, err)
+	return &cospb.SubscribeResponse{Event: anyEvent, Meta: &cospb.MetaData{EntityId: entityID, RevisionNumber: revision}}
+}
+
+func TestManager(t *testing.T) {
+	t.Run("With watchers sharing the entity subscription", func(t *testing.T) {
+		ctx := context.TODO()
+		hub := NewHub(10)
+
+		cosClient := new(mocks.ChiefOfStateServiceClient)
+		responses := expectSubscribe(cosClient, "account-1")
+		manager := newTestManager(cosClient, hub)
+
+		watcher, err := manager.Subscribe(ctx, "account-1")
+		require.NoError(t, err)
+		debits, err := manager.Subscribe(ctx, "account-1", proto.MessageName(&pb.AccountDebited{}))
+		require.NoError(t, err)
+		assert.Equal(t, 1, manager.Subscriptions())
+
+		// the events of the entity are sent to its watchers accepting their type
+		responses <- newResponse(t, "account-1", 2, &pb.AccountCredited{AccountId: "account-1", Amount: 10})
+		responses <- newResponse(t, "account-1", 3, &pb.AccountDebited{AccountId: "account-1", Amount: 5})
+
+		event := <-watcher.Events()
+		assert.EqualValues(t, 2, event.Meta.GetRevisionNumber())
+		event = <-watcher.Events()
+		assert.EqualValues(t, 3, event.Meta.GetRevisionNumber())
+		event = <-debits.Events()
+		assert.EqualValues(t, 3, event.Meta.GetRevisionNumber())
+		assert.Empty(t, debits.Events())
+
+		// the subscription is kept as long as the entity is watched
+		require.NoError(t, manager.Unsubscribe(ctx, watcher))
+		assert.Equal(t, 1, manager.Subscriptions())
+
+		// the subscription is closed with the last watcher
+		cosClient.
+			On("Unsubscribe", mock.Anything, mock.MatchedBy(func(request *cospb.UnsubscribeRequest) bool {
+				return request.GetEntityId() == "account-1"
+			})).
+			Return(new(cospb.UnsubscribeResponse), nil).
+			Once()
+		require.NoError(t, manager.Unsubscribe(ctx, debits))
+		assert.Zero(t, manager.Subscriptions())
+		assert.Zero(t, hub.Watchers("account-1"))
+
+		// releasing twice is a no-op
+		require.NoError(t, manager.Unsubscribe(ctx, debits))
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With subscription closed by CoS", func(t *testing.T) {
+		ctx := context.TODO()
+		hub := NewHub(10)
+
+		cosClient := new(mocks.ChiefOfStateServiceClient)
+		responses := expectSubscribe(cosClient, "account-1")
+		manager := newTestManager(cosClient, hub)
+
+		watcher, err := manager.Subscribe(ctx, "account-1")
+		require.NoError(t, err)
+
+		close(responses)
+
+		// the watchers are evicted so that they can resume with a new subscription
+		_, ok := <-watcher.Events()
+		assert.False(t, ok)
+		assert.True(t, watcher.Lagging())
+		require.Eventually(t, func() bool { return manager.Subscriptions() == 0 }, time.Second, 10*time.Millisecond)
+
+		// the closed subscription is not unsubscribed
+		require.NoError(t, manager.Unsubscribe(ctx, watcher))
+
+		// a new subscription is opened with the next watcher
+		expectSubscribe(cosClient, "account-1")
+		_, err = manager.Subscribe(ctx, "account-1")
+		require.NoError(t, err)
+		assert.Equal(t, 1, manager.Subscriptions())
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With subscription failure", func(t *testing.T) {
+		ctx := context.TODO()
+		hub := NewHub(10)
+
+		cosClient := new(mocks.ChiefOfStateServiceClient)
+		cosClient.On("Subscribe", mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+		manager := newTestManager(cosClient, hub)
+
+		watcher, err := manager.Subscribe(ctx, "account-1")
+		require.Error(t, err)
+		assert.Nil(t, watcher)
+		assert.Zero(t, manager.Subscriptions())
+		assert.Zero(t, hub.Watchers("account-1"))
+	})
+	t.Run("With slow subscription", func(t *testing.T) {
+		ctx := context.TODO()
+		hub := NewHub(10)
+
+		// the subscription to account-1 is held by CoS until released
+		opened := make(chan struct{})
+		cosClient := new(mocks.ChiefOfStateServiceClient)
+		cosClient.
+			On("Subscribe", mock.Anything, mock.MatchedBy(func(request *cospb.SubscribeRequest) bool {
+				return request.GetEntityId() == "account-1"
+			})).
+			Return(func(ctx context.Context, _ *cospb.SubscribeRequest, _ ...grpc.CallOption) (grpc.ServerStreamingClient[cospb.SubscribeResponse], error) {
+				<-opened
+				return &testStream{ctx: ctx, responses: make(chan *cospb.SubscribeResponse)}, nil
+			}).
+			Once()
+		expectSubscribe(cosClient, "account-2")
+		manager := newTestManager(cosClient, hub)
+
+		watchers := make(chan *Watcher, 2)
+		for range 2 {
+			go func() {
+				watcher, err := manager.Subscribe(ctx, "account-1")
+				assert.NoError(t, err)
+				watchers <- watcher
+			}()
+		}
+
+		// the other entities are not held by the slow subscription
+		watcher, err := manager.Subscribe(ctx, "account-2")
+		require.NoError(t, err)
+		assert.NotNil(t, watcher)
+		assert.Empty(t, watchers)
+
+		// the watchers of the entity await its single subscription
+		close(opened)
+		for range 2 {
+			select {
+			case watcher := <-watchers:
+				assert.NotNil(t, watcher)
+			case <-time.After(time.Second):
+				require.Fail(t, "the subscription was not shared")
+			}
+		}
+		assert.Equal(t, 2, manager.Subscriptions())
+		assert.Equal(t, 2, hub.Watchers("account-1"))
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With context done while awaiting the subscription", func(t *testing.T) {
+		hub := NewHub(10)
+
+		opened := make(chan struct{})
+		defer close(opened)
+		cosClient := new(mocks.ChiefOfStateServiceClient)
+		cosClient.
+			On("Subscribe", mock.Anything, mock.Anything).
+			Return(func(ctx context.Context, _ *cospb.SubscribeRequest, _ ...grpc.CallOption) (grpc.ServerStreamingClient[cospb.SubscribeResponse], error) {
+				<-opened
+				return nil, errors.New("connection refused")
+			}).
+			Once()
+		manager := newTestManager(cosClient, hub)
+		go func() { _, _ = manager.Subscribe(context.TODO(), "account-1") }()
+		require.Eventually(t, func() bool { return manager.Subscriptions() == 1 }, time.Second, time.Millisecond)
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+		watcher, err := manager.Subscribe(ctx, "account-1")
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, watcher)
+	})
+	t.Run("With handler without hub", func(t *testing.T) {
+		manager := newTestManager(new(mocks.ChiefOfStateServiceClient), nil)
+
+		watcher, err := manager.Subscribe(context.TODO(), "account-1")
+		require.Error(t, err)
+		assert.Nil(t, watcher)
+	})
+	t.Run("With manager stopped", func(t *testing.T) {
+		ctx := context.TODO()
+		hub := NewHub(10)
+
+		cosClient := new(mocks.ChiefOfStateServiceClient)
+		expectSubscribe(cosClient, "account-1")
+		cosClient.
+			On("Unsubscribe", mock.Anything, mock.MatchedBy(func(request *cospb.UnsubscribeRequest) bool {
+				return request.GetEntityId() == "account-1"
+			})).
+			Return(new(cospb.UnsubscribeResponse), nil).
+			Once()
+		manager := newTestManager(cosClient, hub)
+
+		watcher, err := manager.Subscribe(ctx, "account-1")
+		require.NoError(t, err)
+
+		require.NoError(t, manager.Stop(ctx))
+
+		// the watchers are released
+		_, ok := <-watcher.Events()
+		assert.False(t, ok)
+		assert.False(t, watcher.Lagging())
+		assert.Zero(t, manager.Subscriptions())
+		require.NoError(t, manager.Unsubscribe(ctx, watcher))
+
+		// no subscription is opened once stopped
+		_, err = manager.Subscribe(ctx, "account-1")
+		require.Error(t, err)
+		cosClient.AssertExpectations(t)
+	})
+	t.Run("With manager stopped checked", func(t *testing.T) {
+		ctx := context.TODO()
+		manager := newTestManager(new(mocks.ChiefOfStateServiceClient), NewHub(10))
+		assert.NoError(t, manager.Check(ctx))
+
+		require.NoError(t, manager.Stop(ctx))
+		assert.EqualError(t, manager.Check(ctx), "the subscription manager is stopped")
+	})
+}
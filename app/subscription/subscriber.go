@@ -0,0 +1,16 @@
+package subscription
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Subscriber registers the watchers of the events of the entities
+type Subscriber interface {
+	// Subscribe registers a watcher of the events of a given entity. When event types are given, only the events
+	// of those types are sent to the watcher. The watcher must be released with Unsubscribe once done.
+	Subscribe(ctx context.Context, entityID string, eventTypes ...protoreflect.FullName) (*Watcher, error)
+	// Unsubscribe releases a given watcher
+	Unsubscribe(ctx context.Context, watcher *Watcher) error
+}
@@ -0,0 +1,37 @@
+package tenant
+
+import (
+	"github.com/caarlos0/env/v9"
+	"github.com/pkg/errors"
+)
+
+// Config defines the multi-tenancy config
+type Config struct {
+	Enabled bool     `env:"TENANCY_ENABLED" envDefault:"false"`             // Enabled states whether the requests must carry the tenant they belong to
+	Tenants []string `env:"TENANCY_TENANTS" envDefault:"" envSeparator:","` // Tenants are the ids of the tenants hosted by the deployment
+}
+
+// LoadConfig fetches the Config from env vars
+func LoadConfig() *Config {
+	config := &Config{}
+	// all env vars are required
+	opts := env.Options{RequiredIfNoDef: true}
+	if err := env.ParseWithOptions(config, opts); err != nil {
+		panic(errors.Wrap(err, "unable to load environment variables"))
+	}
+
+	return config
+}
+
+// Validate checks that at least one tenant is hosted and that the tenants ids are valid
+func (c *Config) Validate() error {
+	if len(c.Tenants) == 0 {
+		return errors.New("the tenants are not set")
+	}
+	for _, tenantID := range c.Tenants {
+		if !IsValidID(tenantID) {
+			return errors.Errorf("the tenant id (%s) is invalid", tenantID)
+		}
+	}
+	return nil
+}
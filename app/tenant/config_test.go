@@ -0,0 +1,55 @@
+package tenant
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("With default values", func(t *testing.T) {
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.False(t, actual.Enabled)
+		assert.Empty(t, actual.Tenants)
+	})
+	t.Run("With environment variables set", func(t *testing.T) {
+		assert.NoError(t, os.Setenv("TENANCY_ENABLED", "true"))
+		assert.NoError(t, os.Setenv("TENANCY_TENANTS", "acme,globex"))
+
+		actual := LoadConfig()
+		require.NotNil(t, actual)
+		assert.True(t, actual.Enabled)
+		assert.Equal(t, []string{"acme", "globex"}, actual.Tenants)
+
+		// free resources
+		assert.NoError(t, os.Unsetenv("TENANCY_ENABLED"))
+		assert.NoError(t, os.Unsetenv("TENANCY_TENANTS"))
+	})
+	t.Run("With invalid environment variables", func(t *testing.T) {
+		assert.NoError(t, os.Setenv("TENANCY_ENABLED", "not-a-bool"))
+		assert.Panics(t, func() {
+			LoadConfig()
+		})
+
+		// free resources
+		assert.NoError(t, os.Unsetenv("TENANCY_ENABLED"))
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Run("With valid config", func(t *testing.T) {
+		config := &Config{Enabled: true, Tenants: []string{"acme", "globex-2"}}
+		assert.NoError(t, config.Validate())
+	})
+	t.Run("With tenants not set", func(t *testing.T) {
+		config := &Config{Enabled: true}
+		assert.EqualError(t, config.Validate(), "the tenants are not set")
+	})
+	t.Run("With invalid tenant id", func(t *testing.T) {
+		config := &Config{Enabled: true, Tenants: []string{"acme", "Globex/1"}}
+		assert.EqualError(t, config.Validate(), "the tenant id (Globex/1) is invalid")
+	})
+}
@@ -0,0 +1,65 @@
+package tenant
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/tochemey/cos-go-sample/app/auth"
+)
+
+// Header is the header carrying the tenant a request belongs to, as gRPC metadata or HTTP header
+const Header = "x-tenant-id"
+
+// Resolver resolves the tenant the requests belong to from their metadata.
+// Only the hosted tenants are resolved, and the authenticated callers are only allowed on their own tenant
+type Resolver struct {
+	tenants map[string]struct{}
+}
+
+// NewResolver creates an instance of Resolver resolving the given tenants
+func NewResolver(tenantIDs ...string) *Resolver {
+	tenants := make(map[string]struct{}, len(tenantIDs))
+	for _, tenantID := range tenantIDs {
+		tenants[tenantID] = struct{}{}
+	}
+	return &Resolver{tenants: tenants}
+}
+
+// Resolve returns the tenant of the request served with the given context. The tenant is read from the context,
+// where it is set by Middleware, or from the incoming gRPC metadata. An invalid argument error is returned when
+// the tenant is not set or not hosted and a permission denied error is returned when the caller belongs to another tenant
+func (r *Resolver) Resolve(ctx context.Context) (string, error) {
+	tenantID, ok := FromContext(ctx)
+	if !ok {
+		if values := metadata.ValueFromIncomingContext(ctx, Header); len(values) > 0 {
+			tenantID = values[0]
+		}
+	}
+
+	if tenantID == "" {
+		return "", status.Error(codes.InvalidArgument, "the tenant is not set")
+	}
+	if _, ok := r.tenants[tenantID]; !ok {
+		return "", status.Errorf(codes.InvalidArgument, "the tenant:(%s) is unknown", tenantID)
+	}
+
+	// the authenticated callers only access the tenant of their token
+	if principal, ok := auth.FromContext(ctx); ok && principal.Tenant != tenantID {
+		return "", status.Errorf(codes.PermissionDenied, "the caller:(%s) does not belong to the tenant:(%s)", principal.Subject, tenantID)
+	}
+	return tenantID, nil
+}
+
+// Middleware sets the tenant of the HTTP requests, read from their header, into their context
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if tenantID := r.Header.Get(Header); tenantID != "" {
+			r = r.WithContext(NewContext(r.Context(), tenantID))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,72 @@
+package tenant
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/tochemey/cos-go-sample/app/auth"
+)
+
+func TestResolver(t *testing.T) {
+	resolver := NewResolver("acme", "globex")
+
+	t.Run("With tenant metadata", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.TODO(), metadata.Pairs(Header, "acme"))
+		tenantID, err := resolver.Resolve(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "acme", tenantID)
+	})
+	t.Run("With tenant context", func(t *testing.T) {
+		tenantID, err := resolver.Resolve(NewContext(context.TODO(), "globex"))
+		require.NoError(t, err)
+		assert.Equal(t, "globex", tenantID)
+	})
+	t.Run("With tenant not set", func(t *testing.T) {
+		_, err := resolver.Resolve(context.TODO())
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+	t.Run("With unknown tenant", func(t *testing.T) {
+		_, err := resolver.Resolve(NewContext(context.TODO(), "initech"))
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, "the tenant:(initech) is unknown", status.Convert(err).Message())
+	})
+	t.Run("With caller of the tenant", func(t *testing.T) {
+		ctx := auth.NewContext(NewContext(context.TODO(), "acme"), &auth.Principal{Subject: "John Doe", Tenant: "acme"})
+		tenantID, err := resolver.Resolve(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "acme", tenantID)
+	})
+	t.Run("With caller of another tenant", func(t *testing.T) {
+		ctx := auth.NewContext(NewContext(context.TODO(), "globex"), &auth.Principal{Subject: "John Doe", Tenant: "acme"})
+		_, err := resolver.Resolve(ctx)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+	t.Run("With caller without tenant", func(t *testing.T) {
+		ctx := auth.NewContext(NewContext(context.TODO(), "acme"), &auth.Principal{Subject: "John Doe"})
+		_, err := resolver.Resolve(ctx)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	var actual string
+	handler := Middleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		actual, _ = FromContext(r.Context())
+	}))
+
+	request := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+	request.Header.Set("X-Tenant-Id", "acme")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	assert.Equal(t, "acme", actual)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/graphql", nil))
+	assert.Empty(t, actual)
+}
@@ -0,0 +1,54 @@
+package tenant
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// separator separates the tenant id from the id of the entity in the namespaced entity ids
+const separator = "/"
+
+// idPattern is the pattern of the tenants ids: lower case letters, digits and dashes
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// IsValidID checks whether a given tenant id is valid
+func IsValidID(tenantID string) bool {
+	return idPattern.MatchString(tenantID)
+}
+
+// EntityID returns the CoS entity id of a given id namespaced by a given tenant. The id is returned as is when the tenant or the id is not set
+func EntityID(tenantID, id string) string {
+	if tenantID == "" || id == "" {
+		return id
+	}
+	return tenantID + separator + id
+}
+
+// LocalID returns the id of a given entity id namespaced by a given tenant, as known by the tenant.
+// The entity id is returned as is when the tenant is not set or does not own it
+func LocalID(tenantID, entityID string) string {
+	if tenantID == "" {
+		return entityID
+	}
+	return strings.TrimPrefix(entityID, tenantID+separator)
+}
+
+// Owns checks whether a given entity id is namespaced by a given tenant
+func Owns(tenantID, entityID string) bool {
+	return strings.HasPrefix(entityID, tenantID+separator) && len(entityID) > len(tenantID)+len(separator)
+}
+
+// tenantKey is the context key of the tenant id
+type tenantKey struct{}
+
+// NewContext returns a copy of the given context carrying the given tenant id
+func NewContext(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// FromContext returns the tenant id carried by the given context
+func FromContext(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(tenantKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
@@ -0,0 +1,54 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntityID(t *testing.T) {
+	t.Run("With tenant", func(t *testing.T) {
+		entityID := EntityID("acme", "account-1")
+		assert.Equal(t, "acme/account-1", entityID)
+		assert.Equal(t, "account-1", LocalID("acme", entityID))
+		assert.True(t, Owns("acme", entityID))
+		assert.False(t, Owns("globex", entityID))
+	})
+	t.Run("With tenant not set", func(t *testing.T) {
+		assert.Equal(t, "account-1", EntityID("", "account-1"))
+		assert.Equal(t, "account-1", LocalID("", "account-1"))
+	})
+	t.Run("With id not set", func(t *testing.T) {
+		assert.Empty(t, EntityID("acme", ""))
+	})
+	t.Run("With id holding the separator", func(t *testing.T) {
+		entityID := EntityID("acme", "globex/account-1")
+		assert.Equal(t, "globex/account-1", LocalID("acme", entityID))
+		assert.True(t, Owns("acme", entityID))
+		assert.False(t, Owns("globex", entityID))
+	})
+	t.Run("With entity of another tenant", func(t *testing.T) {
+		assert.Equal(t, "globex/account-1", LocalID("acme", "globex/account-1"))
+		assert.False(t, Owns("acme", "acme/"))
+	})
+}
+
+func TestIsValidID(t *testing.T) {
+	for _, tenantID := range []string{"acme", "globex-2", "1"} {
+		assert.True(t, IsValidID(tenantID), tenantID)
+	}
+	for _, tenantID := range []string{"", "Acme", "acme/1", "-acme", "acme corp"} {
+		assert.False(t, IsValidID(tenantID), tenantID)
+	}
+}
+
+func TestContext(t *testing.T) {
+	tenantID, ok := FromContext(context.TODO())
+	assert.False(t, ok)
+	assert.Empty(t, tenantID)
+
+	tenantID, ok = FromContext(NewContext(context.TODO(), "acme"))
+	assert.True(t, ok)
+	assert.Equal(t, "acme", tenantID)
+}
@@ -0,0 +1,138 @@
+package validation
+
+import (
+	"context"
+	"strings"
+
+	"connectrpc.com/connect"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// UnaryServerInterceptor returns the gRPC interceptor validating the requests of the unary calls to the given services.
+// The invalid requests fail with an invalid argument status carrying the field violations
+func (v *Validator) UnaryServerInterceptor(serviceNames ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !validates(info.FullMethod, serviceNames) {
+			return handler(ctx, req)
+		}
+
+		if err := v.validateRequest(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns the gRPC interceptor validating the requests of the streaming calls to the given services.
+// The requests are validated as they are received
+func (v *Validator) StreamServerInterceptor(serviceNames ...string) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !validates(info.FullMethod, serviceNames) {
+			return handler(srv, stream)
+		}
+
+		return handler(srv, &validatedStream{ServerStream: stream, validator: v})
+	}
+}
+
+// ConnectInterceptor returns the Connect interceptor validating the requests of the calls of a Connect handler.
+// The invalid requests fail with an invalid argument error carrying the field violations
+func (v *Validator) ConnectInterceptor() connect.Interceptor {
+	return &connectInterceptor{validator: v}
+}
+
+// validateRequest validates a given request. The requests which are not proto messages are left as is
+func (v *Validator) validateRequest(request any) error {
+	message, ok := request.(proto.Message)
+	if !ok {
+		return nil
+	}
+	return v.Validate(message)
+}
+
+// validateConnect validates a Connect request and converts the violations into a Connect error
+func (v *Validator) validateConnect(request any) error {
+	err := v.validateRequest(request)
+	if err == nil {
+		return nil
+	}
+
+	st := status.Convert(err)
+	connectErr := connect.NewError(connect.Code(st.Code()), errors.New(st.Message()))
+	for _, detail := range st.Proto().GetDetails() {
+		if errorDetail, detailErr := connect.NewErrorDetail(detail); detailErr == nil {
+			connectErr.AddDetail(errorDetail)
+		}
+	}
+	return connectErr
+}
+
+// validates checks whether a given gRPC method belongs to one of the given services
+func validates(fullMethod string, serviceNames []string) bool {
+	for _, serviceName := range serviceNames {
+		if strings.HasPrefix(fullMethod, "/"+serviceName+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// validatedStream is a gRPC server stream validating the requests it receives
+type validatedStream struct {
+	grpc.ServerStream
+	validator *Validator
+}
+
+// RecvMsg receives and validates a request of the stream
+func (s *validatedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return s.validator.validateRequest(m)
+}
+
+// connectInterceptor validates the requests of the calls of a Connect handler
+type connectInterceptor struct {
+	validator *Validator
+}
+
+var _ connect.Interceptor = (*connectInterceptor)(nil)
+
+// WrapUnary validates the requests of the unary calls
+func (i *connectInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, request connect.AnyRequest) (connect.AnyResponse, error) {
+		if err := i.validator.validateConnect(request.Any()); err != nil {
+			return nil, err
+		}
+		return next(ctx, request)
+	}
+}
+
+// WrapStreamingClient leaves the client streams unchanged
+func (i *connectInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler validates the requests of the streaming calls as they are received
+func (i *connectInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		return next(ctx, &validatedConn{StreamingHandlerConn: conn, validator: i.validator})
+	}
+}
+
+// validatedConn is a Connect streaming handler connection validating the requests it receives
+type validatedConn struct {
+	connect.StreamingHandlerConn
+	validator *Validator
+}
+
+// Receive receives and validates a request of the stream
+func (c *validatedConn) Receive(m any) error {
+	if err := c.StreamingHandlerConn.Receive(m); err != nil {
+		return err
+	}
+	return c.validator.validateConnect(m)
+}
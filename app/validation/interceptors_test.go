@@ -0,0 +1,138 @@
+package validation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+const (
+	testService   = "accounts.v1.BankAccountService"
+	testMethod    = "/" + testService + "/DebitAccount"
+	testProcedure = "/" + testService + "/WatchAccount"
+)
+
+// testServerStream is a gRPC server stream receiving a given request
+type testServerStream struct {
+	grpc.ServerStream
+	request proto.Message
+}
+
+// RecvMsg receives the request of the stream
+func (s *testServerStream) RecvMsg(m any) error {
+	proto.Merge(m.(proto.Message), s.request)
+	return nil
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	validator, err := NewValidator()
+	require.NoError(t, err)
+	interceptor := validator.UnaryServerInterceptor(testService)
+	info := &grpc.UnaryServerInfo{FullMethod: testMethod}
+
+	handler := func(context.Context, any) (any, error) {
+		return new(pb.DebitAccountResponse), nil
+	}
+
+	t.Run("With valid request", func(t *testing.T) {
+		actual, err := interceptor(context.TODO(), &pb.DebitAccountRequest{AccountId: "account-1", Amount: 10}, info, handler)
+		require.NoError(t, err)
+		assert.NotNil(t, actual)
+	})
+	t.Run("With invalid request", func(t *testing.T) {
+		actual, err := interceptor(context.TODO(), &pb.DebitAccountRequest{AccountId: "account-1", Amount: -10}, info, handler)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, map[string]string{"amount": "double.gt"}, fieldViolations(t, err))
+		assert.Nil(t, actual)
+	})
+	t.Run("With call to other service", func(t *testing.T) {
+		healthInfo := &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}
+		_, err := interceptor(context.TODO(), &pb.DebitAccountRequest{AccountId: "account-1", Amount: -10}, healthInfo, handler)
+		require.NoError(t, err)
+	})
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	validator, err := NewValidator()
+	require.NoError(t, err)
+	interceptor := validator.StreamServerInterceptor(testService)
+	info := &grpc.StreamServerInfo{FullMethod: testProcedure, IsServerStream: true}
+
+	// handler receives the request of the stream
+	handler := func(_ any, stream grpc.ServerStream) error {
+		return stream.RecvMsg(new(pb.WatchAccountRequest))
+	}
+
+	t.Run("With valid request", func(t *testing.T) {
+		stream := &testServerStream{request: &pb.WatchAccountRequest{AccountId: "account-1"}}
+		require.NoError(t, interceptor(nil, stream, info, handler))
+	})
+	t.Run("With invalid request", func(t *testing.T) {
+		stream := &testServerStream{request: &pb.WatchAccountRequest{AccountId: "account-1", FromRevision: -1}}
+		err := interceptor(nil, stream, info, handler)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, map[string]string{"from_revision": "int32.gte"}, fieldViolations(t, err))
+	})
+}
+
+func TestConnectInterceptor(t *testing.T) {
+	validator, err := NewValidator()
+	require.NoError(t, err)
+	interceptor := connect.WithInterceptors(validator.ConnectInterceptor())
+
+	mux := http.NewServeMux()
+	mux.Handle(testMethod, connect.NewUnaryHandler(testMethod,
+		func(context.Context, *connect.Request[pb.DebitAccountRequest]) (*connect.Response[pb.DebitAccountResponse], error) {
+			return connect.NewResponse(new(pb.DebitAccountResponse)), nil
+		}, interceptor))
+	mux.Handle(testProcedure, connect.NewServerStreamHandler(testProcedure,
+		func(_ context.Context, _ *connect.Request[pb.WatchAccountRequest], stream *connect.ServerStream[pb.WatchAccountResponse]) error {
+			return stream.Send(new(pb.WatchAccountResponse))
+		}, interceptor))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	unaryClient := connect.NewClient[pb.DebitAccountRequest, pb.DebitAccountResponse](server.Client(), server.URL+testMethod)
+	streamClient := connect.NewClient[pb.WatchAccountRequest, pb.WatchAccountResponse](server.Client(), server.URL+testProcedure)
+
+	t.Run("With unary call", func(t *testing.T) {
+		_, err := unaryClient.CallUnary(context.TODO(), connect.NewRequest(&pb.DebitAccountRequest{AccountId: "account-1", Amount: 10}))
+		require.NoError(t, err)
+
+		_, err = unaryClient.CallUnary(context.TODO(), connect.NewRequest(&pb.DebitAccountRequest{AccountId: "account 1", Amount: 10}))
+		require.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(err))
+
+		// the error carries the field violations
+		var connectErr *connect.Error
+		require.ErrorAs(t, err, &connectErr)
+		require.Len(t, connectErr.Details(), 1)
+		detail, err := connectErr.Details()[0].Value()
+		require.NoError(t, err)
+		require.IsType(t, &errdetails.BadRequest{}, detail)
+		assert.Equal(t, "account_id", detail.(*errdetails.BadRequest).GetFieldViolations()[0].GetField())
+	})
+	t.Run("With streaming call", func(t *testing.T) {
+		stream, err := streamClient.CallServerStream(context.TODO(), connect.NewRequest(&pb.WatchAccountRequest{AccountId: "account-1"}))
+		require.NoError(t, err)
+		require.True(t, stream.Receive())
+		require.NoError(t, stream.Close())
+
+		stream, err = streamClient.CallServerStream(context.TODO(), connect.NewRequest(&pb.WatchAccountRequest{AccountId: ""}))
+		require.NoError(t, err)
+		defer stream.Close()
+		assert.False(t, stream.Receive())
+		assert.Equal(t, connect.CodeInvalidArgument, connect.CodeOf(stream.Err()))
+	})
+}
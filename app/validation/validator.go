@@ -0,0 +1,60 @@
+package validation
+
+import (
+	"strings"
+
+	"buf.build/go/protovalidate"
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Validator evaluates the protovalidate constraints annotating the fields of the proto messages,
+// e.g. the positive amounts of the credits and debits or the format of the account ids
+type Validator struct {
+	validator protovalidate.Validator
+}
+
+// NewValidator creates an instance of Validator
+func NewValidator() (*Validator, error) {
+	validator, err := protovalidate.New()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the validator")
+	}
+	return &Validator{validator: validator}, nil
+}
+
+// Validate validates a given message. The violations of its constraints are returned as an invalid argument status
+// carrying the field violations in its bad request details
+func (v *Validator) Validate(message proto.Message) error {
+	err := v.validator.Validate(message)
+	if err == nil {
+		return nil
+	}
+
+	var validationErr *protovalidate.ValidationError
+	if !errors.As(err, &validationErr) {
+		// the constraints cannot be evaluated, e.g. an invalid CEL expression
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	badRequest := new(errdetails.BadRequest)
+	descriptions := make([]string, 0, len(validationErr.Violations))
+	for _, violation := range validationErr.Violations {
+		field := protovalidate.FieldPathString(violation.Proto.GetField())
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: violation.Proto.GetMessage(),
+			Reason:      violation.Proto.GetRuleId(),
+		})
+		descriptions = append(descriptions, field+": "+violation.Proto.GetMessage())
+	}
+
+	st := status.Newf(codes.InvalidArgument, "invalid %s: %s", message.ProtoReflect().Descriptor().Name(), strings.Join(descriptions, "; "))
+	if detailed, detailErr := st.WithDetails(badRequest); detailErr == nil {
+		st = detailed
+	}
+	return st.Err()
+}
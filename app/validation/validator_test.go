@@ -0,0 +1,97 @@
+package validation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// fieldViolations returns the fields violations carried by the details of a given status error
+func fieldViolations(t *testing.T, err error) map[string]string {
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Len(t, st.Details(), 1)
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+
+	violations := make(map[string]string, len(badRequest.GetFieldViolations()))
+	for _, violation := range badRequest.GetFieldViolations() {
+		violations[violation.GetField()] = violation.GetReason()
+	}
+	return violations
+}
+
+func TestValidator(t *testing.T) {
+	validator, err := NewValidator()
+	require.NoError(t, err)
+
+	t.Run("With valid requests", func(t *testing.T) {
+		requests := []proto.Message{
+			&pb.OpenAccountRequest{AccountOwner: "John Doe", Balance: 0},
+			&pb.OpenAccountRequest{AccountOwner: "John Doe", Balance: 100, AccountId: proto.String("4f9c9d4e-8a4b-4b8e-9c1d-2f1e0a6b7c8d")},
+			&pb.CreditAccountRequest{AccountId: "account-1", Amount: 10.5},
+			&pb.DebitAccountRequest{AccountId: "account-1", Amount: 10.5, ExpectedRevision: proto.Int32(2)},
+			&pb.GetAccountAtRequest{AccountId: "account-1", Point: &pb.GetAccountAtRequest_Revision{Revision: 1}},
+			&pb.CreateStandingOrderRequest{AccountId: "account-1", Amount: 10, Frequency: pb.Frequency_MONTHLY, StartDate: timestamppb.Now()},
+			&pb.BatchGetAccountsRequest{AccountIds: []string{"account-1", "account-2"}},
+			// the commands of the bulk posts are validated one by one
+			&pb.BulkPostRequest{Entries: []*pb.BulkPostEntry{{Command: &pb.BulkPostEntry_Credit{Credit: &pb.CreditAccountRequest{Amount: -1}}}}},
+			&pb.OpenAccount{AccountId: "acme/account-1", AccountOwner: "John Doe", OpeningBalance: 100},
+		}
+		for _, request := range requests {
+			assert.NoError(t, validator.Validate(request), "%T", request)
+		}
+	})
+	t.Run("With invalid amount", func(t *testing.T) {
+		err := validator.Validate(&pb.CreditAccountRequest{AccountId: "account-1", Amount: -10})
+		require.Error(t, err)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, "invalid CreditAccountRequest: amount: value must be greater than 0", status.Convert(err).Message())
+		assert.Equal(t, map[string]string{"amount": "double.gt"}, fieldViolations(t, err))
+	})
+	t.Run("With infinite amount", func(t *testing.T) {
+		err := validator.Validate(&pb.DebitAccountRequest{AccountId: "account-1", Amount: math.Inf(1)})
+		assert.Equal(t, map[string]string{"amount": "double.finite"}, fieldViolations(t, err))
+	})
+	t.Run("With negative opening balance", func(t *testing.T) {
+		err := validator.Validate(&pb.OpenAccountRequest{AccountOwner: "John Doe", Balance: -1})
+		assert.Equal(t, map[string]string{"balance": "double.gte"}, fieldViolations(t, err))
+	})
+	t.Run("With invalid account id", func(t *testing.T) {
+		for _, accountID := range []string{"", "account 1", "acme/account-1", "-account"} {
+			err := validator.Validate(&pb.GetAccountRequest{AccountId: accountID})
+			assert.Equal(t, map[string]string{"account_id": "string.pattern"}, fieldViolations(t, err), accountID)
+		}
+	})
+	t.Run("With several violations", func(t *testing.T) {
+		err := validator.Validate(&pb.OpenAccountRequest{Balance: -1, AccountId: proto.String(""), AccountType: pb.AccountType(42)})
+		assert.Equal(t, map[string]string{
+			"account_owner": "string.min_len",
+			"balance":       "double.gte",
+			"account_id":    "string.pattern",
+			"account_type":  "enum.defined_only",
+		}, fieldViolations(t, err))
+	})
+	t.Run("With point in time not set", func(t *testing.T) {
+		err := validator.Validate(&pb.GetAccountAtRequest{AccountId: "account-1"})
+		assert.Equal(t, map[string]string{"point": "required"}, fieldViolations(t, err))
+	})
+	t.Run("With invalid batch account id", func(t *testing.T) {
+		err := validator.Validate(&pb.BatchGetAccountsRequest{AccountIds: []string{"account-1", "account 2"}})
+		assert.Equal(t, map[string]string{"account_ids[1]": "string.pattern"}, fieldViolations(t, err))
+	})
+	t.Run("With invalid command", func(t *testing.T) {
+		err := validator.Validate(&pb.DebitAccount{AccountId: "acme/account-1", Amount: 0})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, map[string]string{"amount": "double.gt"}, fieldViolations(t, err))
+	})
+}
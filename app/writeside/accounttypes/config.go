@@ -0,0 +1,37 @@
+package accounttypes
+
+import (
+	"github.com/caarlos0/env/v9"
+	"github.com/pkg/errors"
+)
+
+// Config holds the account rules configuration
+type Config struct {
+	AccountRulesPath string `env:"ACCOUNT_RULES_PATH" envDefault:""` // AccountRulesPath is the path to the JSON account rules. The default rules apply when not set
+}
+
+// LoadRegistry reads the account rules from the file set in the environment variables.
+// We panic here because this call is usually and must be done during application start
+func LoadRegistry() *Registry {
+	config := &Config{}
+	// all env vars are required
+	opts := env.Options{RequiredIfNoDef: true}
+
+	// parse the environment variables and panic in case of error
+	if err := env.ParseWithOptions(config, opts); err != nil {
+		panic(errors.Wrap(err, "unable to load environment variables"))
+	}
+
+	// no account rules are configured
+	if config.AccountRulesPath == "" {
+		return NewRegistry()
+	}
+
+	// read the account rules file
+	registry, err := ReadRegistry(config.AccountRulesPath)
+	if err != nil {
+		panic(errors.Wrap(err, "unable to load the account rules"))
+	}
+
+	return registry
+}
@@ -0,0 +1,38 @@
+package accounttypes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRegistry(t *testing.T) {
+	t.Run("With no account rules path set", func(t *testing.T) {
+		registry := LoadRegistry()
+		require.NotNil(t, registry)
+		assert.Empty(t, registry.Rules)
+	})
+	t.Run("With account rules path set", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "account_rules.json")
+		content := `{"rules":[{"account_type":"SAVINGS","min_opening_balance":50,"max_monthly_withdrawals":3}]}`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		assert.NoError(t, os.Setenv("ACCOUNT_RULES_PATH", path))
+
+		registry := LoadRegistry()
+		require.NotNil(t, registry)
+		assert.Len(t, registry.Rules, 1)
+		// free resources
+		assert.NoError(t, os.Unsetenv("ACCOUNT_RULES_PATH"))
+	})
+	t.Run("With invalid account rules file", func(t *testing.T) {
+		assert.NoError(t, os.Setenv("ACCOUNT_RULES_PATH", filepath.Join(t.TempDir(), "account_rules.json")))
+		assert.Panics(t, func() {
+			LoadRegistry()
+		})
+		// free resources
+		assert.NoError(t, os.Unsetenv("ACCOUNT_RULES_PATH"))
+	})
+}
@@ -0,0 +1,122 @@
+package accounttypes
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// Rules defines the business rules an account type is governed by
+type Rules struct {
+	AccountType           string  `json:"account_type"`            // AccountType is the account type the rules apply to. e.g. SAVINGS
+	MinOpeningBalance     float64 `json:"min_opening_balance"`     // MinOpeningBalance is the lowest balance the account can be opened with
+	OverdraftLimit        float64 `json:"overdraft_limit"`         // OverdraftLimit is how far below zero the balance can go, down to the limit included. Zero means the balance must remain positive
+	MaxMonthlyWithdrawals int32   `json:"max_monthly_withdrawals"` // MaxMonthlyWithdrawals caps the debits per calendar month. Zero means no limit
+	CreditsDisallowed     bool    `json:"credits_disallowed"`      // CreditsDisallowed rejects the credits on the account. e.g. escrow accounts
+}
+
+// validate checks whether the rules are well-defined
+func (r *Rules) validate() error {
+	switch {
+	case r.AccountType == "":
+		return errors.New("the account type is not set")
+	case pb.AccountType(pb.AccountType_value[r.AccountType]) == pb.AccountType_ACCOUNT_TYPE_NONE:
+		return errors.Errorf("the account type (%s) is not supported", r.AccountType)
+	case r.MinOpeningBalance < 0 || r.OverdraftLimit < 0 || r.MaxMonthlyWithdrawals < 0:
+		return errors.Errorf("the account type (%s) rules cannot be negative", r.AccountType)
+	default:
+		return nil
+	}
+}
+
+// AllowsCredits returns true when the account can be credited
+func (r *Rules) AllowsCredits() bool {
+	return !r.CreditsDisallowed
+}
+
+// defaultRules are the rules applied when the registry does not override them
+var defaultRules = map[pb.AccountType]*Rules{
+	pb.AccountType_CHECKING: {
+		AccountType: pb.AccountType_CHECKING.String(),
+	},
+	pb.AccountType_SAVINGS: {
+		AccountType:           pb.AccountType_SAVINGS.String(),
+		MinOpeningBalance:     100,
+		MaxMonthlyWithdrawals: 6,
+	},
+	pb.AccountType_ESCROW: {
+		AccountType:       pb.AccountType_ESCROW.String(),
+		CreditsDisallowed: true,
+	},
+}
+
+// Registry holds the business rules of every account type
+type Registry struct {
+	Rules []*Rules `json:"rules"`
+}
+
+// NewRegistry creates an instance of Registry with the given rules.
+// The account types without rules fall back to the default rules
+func NewRegistry(rules ...*Rules) *Registry {
+	return &Registry{Rules: rules}
+}
+
+// ReadRegistry reads and validates a JSON account rules file
+func ReadRegistry(path string) (*Registry, error) {
+	// read the file content
+	bytea, err := os.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the account rules file (%s)", path)
+	}
+
+	// parse the registry
+	registry := NewRegistry()
+	if err := json.Unmarshal(bytea, registry); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the account rules file (%s)", path)
+	}
+
+	// validate every rule
+	for _, rules := range registry.Rules {
+		if err := rules.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
+
+// Resolve returns the effective type of an account. Accounts opened without a type are checking accounts
+func Resolve(accountType pb.AccountType) pb.AccountType {
+	if accountType == pb.AccountType_ACCOUNT_TYPE_NONE {
+		return pb.AccountType_CHECKING
+	}
+	return accountType
+}
+
+// WithdrawalsMonth returns the calendar month the withdrawals performed at the given time are counted against. e.g. 2024-01
+func WithdrawalsMonth(at time.Time) string {
+	return at.UTC().Format("2006-01")
+}
+
+// Lookup returns the rules governing the given account type.
+// The default rules are returned when the registry does not define them
+func (r *Registry) Lookup(accountType pb.AccountType) *Rules {
+	accountType = Resolve(accountType)
+	if r != nil {
+		for _, rules := range r.Rules {
+			if rules.AccountType == accountType.String() {
+				return rules
+			}
+		}
+	}
+
+	// fall back to the default rules
+	if rules, ok := defaultRules[accountType]; ok {
+		return rules
+	}
+	return defaultRules[pb.AccountType_CHECKING]
+}
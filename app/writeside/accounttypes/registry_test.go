@@ -0,0 +1,111 @@
+package accounttypes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestLookup(t *testing.T) {
+	t.Run("With default rules", func(t *testing.T) {
+		registry := NewRegistry()
+
+		checking := registry.Lookup(pb.AccountType_CHECKING)
+		require.NotNil(t, checking)
+		assert.Zero(t, checking.MinOpeningBalance)
+		assert.Zero(t, checking.MaxMonthlyWithdrawals)
+		assert.True(t, checking.AllowsCredits())
+
+		savings := registry.Lookup(pb.AccountType_SAVINGS)
+		require.NotNil(t, savings)
+		assert.Equal(t, 100.00, savings.MinOpeningBalance)
+		assert.EqualValues(t, 6, savings.MaxMonthlyWithdrawals)
+		assert.True(t, savings.AllowsCredits())
+
+		escrow := registry.Lookup(pb.AccountType_ESCROW)
+		require.NotNil(t, escrow)
+		assert.False(t, escrow.AllowsCredits())
+	})
+	t.Run("With account type not set", func(t *testing.T) {
+		registry := NewRegistry()
+		assert.Equal(t, registry.Lookup(pb.AccountType_CHECKING), registry.Lookup(pb.AccountType_ACCOUNT_TYPE_NONE))
+	})
+	t.Run("With overridden rules", func(t *testing.T) {
+		registry := NewRegistry(&Rules{
+			AccountType:    "CHECKING",
+			OverdraftLimit: 500,
+		})
+
+		checking := registry.Lookup(pb.AccountType_CHECKING)
+		require.NotNil(t, checking)
+		assert.Equal(t, 500.00, checking.OverdraftLimit)
+
+		// the other account types keep their default rules
+		assert.EqualValues(t, 6, registry.Lookup(pb.AccountType_SAVINGS).MaxMonthlyWithdrawals)
+	})
+	t.Run("With nil registry", func(t *testing.T) {
+		var registry *Registry
+		savings := registry.Lookup(pb.AccountType_SAVINGS)
+		require.NotNil(t, savings)
+		assert.Equal(t, 100.00, savings.MinOpeningBalance)
+	})
+}
+
+func TestResolve(t *testing.T) {
+	assert.Equal(t, pb.AccountType_CHECKING, Resolve(pb.AccountType_ACCOUNT_TYPE_NONE))
+	assert.Equal(t, pb.AccountType_SAVINGS, Resolve(pb.AccountType_SAVINGS))
+}
+
+func TestWithdrawalsMonth(t *testing.T) {
+	at := time.Date(2024, time.January, 31, 23, 30, 0, 0, time.FixedZone("WAT", -3600))
+	assert.Equal(t, "2024-02", WithdrawalsMonth(at))
+}
+
+func TestReadRegistry(t *testing.T) {
+	t.Run("With valid account rules file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "account_rules.json")
+		content := `{"rules":[{"account_type":"ESCROW","min_opening_balance":1000,"credits_disallowed":true}]}`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		registry, err := ReadRegistry(path)
+		require.NoError(t, err)
+		require.NotNil(t, registry)
+		require.Len(t, registry.Rules, 1)
+		assert.Equal(t, &Rules{
+			AccountType:       "ESCROW",
+			MinOpeningBalance: 1000,
+			CreditsDisallowed: true,
+		}, registry.Rules[0])
+	})
+	t.Run("With unsupported account type", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "account_rules.json")
+		content := `{"rules":[{"account_type":"LOAN"}]}`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		registry, err := ReadRegistry(path)
+		require.Error(t, err)
+		assert.EqualError(t, err, "the account type (LOAN) is not supported")
+		assert.Nil(t, registry)
+	})
+	t.Run("With negative rules", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "account_rules.json")
+		content := `{"rules":[{"account_type":"CHECKING","overdraft_limit":-10}]}`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		registry, err := ReadRegistry(path)
+		require.Error(t, err)
+		assert.EqualError(t, err, "the account type (CHECKING) rules cannot be negative")
+		assert.Nil(t, registry)
+	})
+	t.Run("With missing file", func(t *testing.T) {
+		registry, err := ReadRegistry(filepath.Join(t.TempDir(), "account_rules.json"))
+		assert.Error(t, err)
+		assert.Nil(t, registry)
+	})
+}
@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/tochemey/gopack/otel/trace"
+
+	"github.com/tochemey/cos-go-sample/app/log"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// cancelStandingOrder handles the Cancel Standing Order command. When the command is valid the standing order cancelled event
+// is returned to be persisted. On the contrary a validation error is returned
+func cancelStandingOrder(ctx context.Context, command *pb.CancelStandingOrder, priorState *pb.StandingOrder) (*pb.StandingOrderCancelled, error) {
+	// add a span context to trace the event handler
+	ctx, span := trace.SpanContext(ctx, "HandleCancelStandingOrder")
+	defer span.End()
+
+	// get the context logger
+	logger := log.WithContext(ctx)
+
+	// check the prior state
+	if err := checkStandingOrder(ctx, command.GetOrderId(), priorState); err != nil {
+		return nil, err
+	}
+
+	// check the standing order status
+	if priorState.GetStatus() != pb.StandingOrderStatus_ACTIVE && priorState.GetStatus() != pb.StandingOrderStatus_PAUSED {
+		logger.Warnf("the standing order:(%s) cannot be cancelled", command.GetOrderId())
+		return nil, errInvalidStandingOrderStatus(priorState.GetStatus())
+	}
+
+	// create the standing order cancelled event to persist into the data store
+	return &pb.StandingOrderCancelled{OrderId: command.GetOrderId()}, nil
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestCancelStandingOrder(t *testing.T) {
+	t.Run("With active standing order", func(t *testing.T) {
+		ctx := context.TODO()
+		priorState := &pb.StandingOrder{OrderId: "order-1", Status: pb.StandingOrderStatus_ACTIVE}
+
+		// perform the cancel standing order command handling
+		actual, err := cancelStandingOrder(ctx, &pb.CancelStandingOrder{OrderId: "order-1"}, priorState)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(&pb.StandingOrderCancelled{OrderId: "order-1"}, actual))
+	})
+	t.Run("With paused standing order", func(t *testing.T) {
+		ctx := context.TODO()
+		priorState := &pb.StandingOrder{OrderId: "order-1", Status: pb.StandingOrderStatus_PAUSED}
+
+		// perform the cancel standing order command handling
+		actual, err := cancelStandingOrder(ctx, &pb.CancelStandingOrder{OrderId: "order-1"}, priorState)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(&pb.StandingOrderCancelled{OrderId: "order-1"}, actual))
+	})
+	t.Run("With prior state not defined", func(t *testing.T) {
+		ctx := context.TODO()
+
+		// perform the cancel standing order command handling
+		actual, err := cancelStandingOrder(ctx, &pb.CancelStandingOrder{OrderId: "order-1"}, nil)
+		assert.EqualError(t, err, errMissingPriorState.Error())
+		assert.Nil(t, actual)
+	})
+	t.Run("With completed standing order", func(t *testing.T) {
+		ctx := context.TODO()
+		priorState := &pb.StandingOrder{OrderId: "order-1", Status: pb.StandingOrderStatus_COMPLETED}
+
+		// perform the cancel standing order command handling
+		actual, err := cancelStandingOrder(ctx, &pb.CancelStandingOrder{OrderId: "order-1"}, priorState)
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = the standing order is COMPLETED")
+		assert.Nil(t, actual)
+	})
+}
@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/tochemey/cos-go-sample/app/writeside/accounttypes"
+	"github.com/tochemey/cos-go-sample/app/writeside/fees"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// chargeFee evaluates the fee schedule for the given command sent to the given account and returns the fee charged event.
+// It returns nil when the command is free of charge
+func chargeFee(feeSchedule *fees.Schedule, command proto.Message, account *pb.BankAccount, amount float64) *pb.FeeCharged {
+	accountType := accounttypes.Resolve(account.GetAccountType())
+	rule, fee := feeSchedule.Evaluate(accountType.String(), command, amount)
+	if rule == nil {
+		return nil
+	}
+
+	return &pb.FeeCharged{
+		AccountId:   account.GetAccountId(),
+		FeeId:       uuid.NewString(),
+		RuleId:      rule.ID,
+		CommandType: rule.CommandType,
+		Amount:      fee,
+	}
+}
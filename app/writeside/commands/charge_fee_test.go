@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tochemey/cos-go-sample/app/writeside/fees"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestChargeFee(t *testing.T) {
+	t.Run("With matching rule", func(t *testing.T) {
+		// create the fee schedule
+		feeSchedule := fees.NewSchedule(&fees.Rule{
+			ID:          "credit-fee",
+			CommandType: "accounts.v1.CreditAccount",
+			Kind:        fees.FlatKind,
+			Value:       0.50,
+		})
+
+		command := &pb.CreditAccount{AccountId: "account-1", Amount: 100}
+		actual := chargeFee(feeSchedule, command, &pb.BankAccount{AccountId: command.GetAccountId()}, command.GetAmount())
+		require.NotNil(t, actual)
+		assert.Equal(t, "account-1", actual.GetAccountId())
+		assert.Equal(t, "credit-fee", actual.GetRuleId())
+		assert.Equal(t, "accounts.v1.CreditAccount", actual.GetCommandType())
+		assert.Equal(t, 0.50, actual.GetAmount())
+		assert.NotEmpty(t, actual.GetFeeId())
+	})
+	t.Run("With no matching rule", func(t *testing.T) {
+		// create the fee schedule
+		feeSchedule := fees.NewSchedule(&fees.Rule{
+			ID:          "credit-fee",
+			CommandType: "accounts.v1.CreditAccount",
+			Kind:        fees.FlatKind,
+			Value:       0.50,
+		})
+
+		command := &pb.DebitAccount{AccountId: "account-1", Amount: 100}
+		assert.Nil(t, chargeFee(feeSchedule, command, &pb.BankAccount{AccountId: command.GetAccountId()}, command.GetAmount()))
+	})
+	t.Run("With account type specific rule", func(t *testing.T) {
+		// create the fee schedule
+		feeSchedule := fees.NewSchedule(
+			&fees.Rule{
+				ID:          "debit-fee",
+				CommandType: "accounts.v1.DebitAccount",
+				Kind:        fees.FlatKind,
+				Value:       0.50,
+			},
+			&fees.Rule{
+				ID:          "savings-debit-fee",
+				AccountType: "SAVINGS",
+				CommandType: "accounts.v1.DebitAccount",
+				Kind:        fees.FlatKind,
+				Value:       2,
+			},
+		)
+
+		command := &pb.DebitAccount{AccountId: "account-1", Amount: 100}
+		savings := &pb.BankAccount{AccountId: "account-1", AccountType: pb.AccountType_SAVINGS}
+		actual := chargeFee(feeSchedule, command, savings, command.GetAmount())
+		require.NotNil(t, actual)
+		assert.Equal(t, "savings-debit-fee", actual.GetRuleId())
+		assert.Equal(t, 2.00, actual.GetAmount())
+
+		// accounts without a type are charged as checking accounts
+		actual = chargeFee(feeSchedule, command, &pb.BankAccount{AccountId: "account-1"}, command.GetAmount())
+		require.NotNil(t, actual)
+		assert.Equal(t, "debit-fee", actual.GetRuleId())
+	})
+	t.Run("With no fee schedule", func(t *testing.T) {
+		command := &pb.DebitAccount{AccountId: "account-1", Amount: 100}
+		assert.Nil(t, chargeFee(nil, command, &pb.BankAccount{AccountId: command.GetAccountId()}, command.GetAmount()))
+	})
+}
@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	"github.com/tochemey/gopack/otel/trace"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/tochemey/cos-go-sample/app/log"
+	"github.com/tochemey/cos-go-sample/app/tenant"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// createStandingOrder handles the Create Standing Order command. When the command is valid the standing order created event
+// is returned to be persisted. On the contrary a validation error is returned
+func createStandingOrder(ctx context.Context, command *pb.CreateStandingOrder, priorState *pb.StandingOrder) (*pb.StandingOrderCreated, error) {
+	// add a span context to trace the event handler
+	ctx, span := trace.SpanContext(ctx, "HandleCreateStandingOrder")
+	defer span.End()
+
+	// get the context logger
+	logger := log.WithContext(ctx)
+
+	// let us make a copy of the command
+	commandCopy := proto.Clone(command).(*pb.CreateStandingOrder)
+
+	// a standing order cannot be created twice
+	if priorState != nil && !proto.Equal(priorState, new(pb.StandingOrder)) {
+		logger.Errorf("the standing order:(%s) already exists", command.GetOrderId())
+		return nil, errStandingOrderAlreadyExists
+	}
+
+	// validate the standing order definition
+	var reason string
+	switch {
+	case commandCopy.GetOrderId() == "":
+		reason = "the standing order id is not set"
+	case commandCopy.GetAccountId() == "":
+		reason = "the account id is not set"
+	case commandCopy.GetBeneficiaryAccountId() == commandCopy.GetAccountId():
+		reason = "the beneficiary account cannot be the debited account"
+	case commandCopy.GetAmount() <= 0:
+		reason = "invalid standing order amount"
+	case commandCopy.GetFrequency() == pb.Frequency_FREQUENCY_NONE:
+		reason = "the standing order frequency is not set"
+	case commandCopy.GetStartDate() == nil:
+		reason = "the standing order start date is not set"
+	case commandCopy.GetEndDate() != nil && commandCopy.GetEndDate().AsTime().Before(commandCopy.GetStartDate().AsTime()):
+		reason = "the standing order end date is before its start date"
+	}
+
+	// return a validation error
+	if reason != "" {
+		logger.Warn(reason)
+		return nil, errInvalidStandingOrder(reason)
+	}
+
+	// the standing order and its accounts must be namespaced by the tenant of the standing order
+	if tenantID := commandCopy.GetTenantId(); tenantID != "" {
+		beneficiaryAccountID := commandCopy.GetBeneficiaryAccountId()
+		if !tenant.Owns(tenantID, commandCopy.GetOrderId()) || !tenant.Owns(tenantID, commandCopy.GetAccountId()) ||
+			(beneficiaryAccountID != "" && !tenant.Owns(tenantID, beneficiaryAccountID)) {
+			logger.Warn("the standing order is not namespaced by its tenant")
+			return nil, errCrossTenant(tenantID)
+		}
+	}
+
+	// create the standing order created event to persist into the data store
+	return &pb.StandingOrderCreated{
+		OrderId:              commandCopy.GetOrderId(),
+		AccountId:            commandCopy.GetAccountId(),
+		BeneficiaryAccountId: commandCopy.GetBeneficiaryAccountId(),
+		Amount:               commandCopy.GetAmount(),
+		Frequency:            commandCopy.GetFrequency(),
+		StartDate:            executionDay(commandCopy.GetStartDate()),
+		EndDate:              commandCopy.GetEndDate(),
+		Reference:            commandCopy.GetReference(),
+		TenantId:             commandCopy.GetTenantId(),
+	}, nil
+}
+
+// executionDay truncates the given timestamp to the start of its UTC day.
+// Standing orders are executed at most once a day
+func executionDay(timestamp *timestamppb.Timestamp) *timestamppb.Timestamp {
+	return timestamppb.New(timestamp.AsTime().UTC().Truncate(24 * time.Hour))
+}
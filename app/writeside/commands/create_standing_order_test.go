@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestCreateStandingOrder(t *testing.T) {
+	startDate := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	// newCommand creates a valid create standing order command
+	newCommand := func() *pb.CreateStandingOrder {
+		return &pb.CreateStandingOrder{
+			OrderId:              "order-1",
+			AccountId:            "account-1",
+			BeneficiaryAccountId: "account-2",
+			Amount:               50,
+			Frequency:            pb.Frequency_MONTHLY,
+			StartDate:            timestamppb.New(startDate.Add(13 * time.Hour)),
+			Reference:            "rent",
+		}
+	}
+
+	t.Run("With happy path", func(t *testing.T) {
+		ctx := context.TODO()
+
+		// create the expected outcome
+		expected := &pb.StandingOrderCreated{
+			OrderId:              "order-1",
+			AccountId:            "account-1",
+			BeneficiaryAccountId: "account-2",
+			Amount:               50,
+			Frequency:            pb.Frequency_MONTHLY,
+			StartDate:            timestamppb.New(startDate),
+			Reference:            "rent",
+		}
+
+		// perform the create standing order command handling
+		actual, err := createStandingOrder(ctx, newCommand(), nil)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.True(t, proto.Equal(expected, actual))
+	})
+	t.Run("With standing order already created", func(t *testing.T) {
+		ctx := context.TODO()
+		priorState := &pb.StandingOrder{OrderId: "order-1", Status: pb.StandingOrderStatus_ACTIVE}
+
+		// perform the create standing order command handling
+		actual, err := createStandingOrder(ctx, newCommand(), priorState)
+		require.Error(t, err)
+		assert.Nil(t, actual)
+		assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	})
+	t.Run("With invalid command", func(t *testing.T) {
+		testCases := map[string]func(command *pb.CreateStandingOrder){
+			"the account id is not set":                             func(command *pb.CreateStandingOrder) { command.AccountId = "" },
+			"the beneficiary account cannot be the debited account": func(command *pb.CreateStandingOrder) { command.BeneficiaryAccountId = "account-1" },
+			"invalid standing order amount":                         func(command *pb.CreateStandingOrder) { command.Amount = 0 },
+			"the standing order frequency is not set":               func(command *pb.CreateStandingOrder) { command.Frequency = pb.Frequency_FREQUENCY_NONE },
+			"the standing order start date is not set":              func(command *pb.CreateStandingOrder) { command.StartDate = nil },
+			"the standing order end date is before its start date": func(command *pb.CreateStandingOrder) {
+				command.EndDate = timestamppb.New(startDate.AddDate(0, 0, -1))
+			},
+		}
+
+		for reason, mutate := range testCases {
+			command := newCommand()
+			mutate(command)
+
+			// perform the create standing order command handling
+			actual, err := createStandingOrder(context.TODO(), command, nil)
+			require.Error(t, err)
+			assert.Nil(t, actual)
+			assert.EqualError(t, err, status.Error(codes.InvalidArgument, reason).Error())
+		}
+	})
+	t.Run("With tenant", func(t *testing.T) {
+		ctx := context.TODO()
+
+		// the tenant is carried into the event
+		command := newCommand()
+		command.OrderId, command.AccountId, command.BeneficiaryAccountId, command.TenantId = "acme/order-1", "acme/account-1", "acme/account-2", "acme"
+		actual, err := createStandingOrder(ctx, command, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "acme", actual.GetTenantId())
+
+		// the standing order cannot transfer to the accounts of another tenant
+		command.BeneficiaryAccountId = "globex/account-2"
+		actual, err = createStandingOrder(ctx, command, nil)
+		assert.Nil(t, actual)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Equal(t, ReasonCrossTenant, ReasonOf(err))
+	})
+}
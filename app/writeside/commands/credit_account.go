@@ -2,17 +2,22 @@ package commands
 
 import (
 	"context"
+	"slices"
 
 	"github.com/tochemey/gopack/otel/trace"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/tochemey/cos-go-sample/app/log"
+	"github.com/tochemey/cos-go-sample/app/writeside/accounttypes"
+	"github.com/tochemey/cos-go-sample/app/writeside/fees"
 	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
 )
 
 // creditAccount handles the Credit Account command. When the command is valid the account credited event is returned
-// to be persisted. On the contrary a validation error is returned
-func creditAccount(ctx context.Context, command *pb.CreditAccount, priorState *pb.BankAccount) (*pb.AccountCredited, error) {
+// to be persisted with the fee charged according to the fee schedule. Credits are rejected when the account type does not
+// allow them or when the balance does not cover the fee, and aborted when the account is not at the revision expected by the caller. On the contrary a validation error is returned
+func creditAccount(ctx context.Context, command *pb.CreditAccount, priorState *pb.BankAccount, priorMeta *cospb.MetaData, feeSchedule *fees.Schedule, accountRules *accounttypes.Registry) (*pb.AccountCredited, error) {
 	// add a span context to trace the event handler
 	ctx, span := trace.SpanContext(ctx, "HandleCreditAccount")
 	defer span.End()
@@ -39,9 +44,43 @@ func creditAccount(ctx context.Context, command *pb.CreditAccount, priorState *p
 		return nil, errCommandSentToWrongEntity
 	}
 
+	// the credit has already been processed, hence it is a no-op
+	if key := commandCopy.GetIdempotencyKey(); key != "" && slices.Contains(priorStateCopy.GetProcessedIdempotencyKeys(), key) {
+		logger.Infof("the credit with idempotency key:(%s) is already processed", key)
+		return nil, nil
+	}
+
+	// the account has been changed since the revision expected by the caller
+	if commandCopy.ExpectedRevision != nil && commandCopy.GetExpectedRevision() != priorMeta.GetRevisionNumber() {
+		logger.Warnf("the account:(%s) is not at the expected revision:(%d)", command.GetAccountId(), commandCopy.GetExpectedRevision())
+		return nil, errRevisionMismatch(commandCopy.GetExpectedRevision(), priorMeta.GetRevisionNumber())
+	}
+
+	// check whether the account type allows credits
+	accountType := accounttypes.Resolve(priorStateCopy.GetAccountType())
+	rules := accountRules.Lookup(accountType)
+	if !rules.AllowsCredits() {
+		logger.Warnf("the account:(%s) does not allow credits", command.GetAccountId())
+		return nil, errCreditsNotAllowed(accountType)
+	}
+
+	// evaluate the fee charged on the credit
+	fee := chargeFee(feeSchedule, commandCopy, priorStateCopy, commandCopy.GetAmount())
+
+	// return a validation error when the fee charged takes the balance after beyond what the account covers, as a debit would
+	balanceAfter := priorStateCopy.GetAccountBalance() + commandCopy.GetAmount() - fee.GetAmount()
+	if fee.GetAmount() > 0 && !covered(balanceAfter, rules.OverdraftLimit) {
+		logger.Warn("insufficient balance to pay the fee")
+		return nil, errInsufficientBalance(priorStateCopy.GetAccountBalance(), commandCopy.GetAmount())
+	}
+
 	// create the account credited event to persist into the data store
 	return &pb.AccountCredited{
-		AccountId: commandCopy.GetAccountId(),
-		Amount:    commandCopy.GetAmount(),
+		AccountId:       commandCopy.GetAccountId(),
+		Amount:          commandCopy.GetAmount(),
+		IdempotencyKey:  commandCopy.GetIdempotencyKey(),
+		SystemInitiated: commandCopy.GetSystemInitiated(),
+		Reactivated:     reactivates(commandCopy, priorStateCopy),
+		Fee:             fee,
 	}, nil
 }
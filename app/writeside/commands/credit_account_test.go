@@ -6,9 +6,14 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/tochemey/cos-go-sample/app/writeside/accounttypes"
+	"github.com/tochemey/cos-go-sample/app/writeside/fees"
 	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
 )
 
 func TestCreditAccount(t *testing.T) {
@@ -40,7 +45,7 @@ func TestCreditAccount(t *testing.T) {
 		}
 
 		// perform the credit account command handling
-		actual, err := creditAccount(ctx, command, priorState)
+		actual, err := creditAccount(ctx, command, priorState, nil, nil, nil)
 		require.NoError(t, err)
 		require.NotNil(t, actual)
 		require.IsType(t, new(pb.AccountCredited), actual)
@@ -61,7 +66,7 @@ func TestCreditAccount(t *testing.T) {
 		}
 
 		// perform the credit account command handling
-		actual, err := creditAccount(ctx, command, priorState)
+		actual, err := creditAccount(ctx, command, priorState, nil, nil, nil)
 		require.Error(t, err)
 		assert.EqualError(t, err, errMissingPriorState.Error())
 		require.Nil(t, actual)
@@ -84,9 +89,269 @@ func TestCreditAccount(t *testing.T) {
 		}
 
 		// perform the credit account command handling
-		actual, err := creditAccount(ctx, command, priorState)
+		actual, err := creditAccount(ctx, command, priorState, nil, nil, nil)
 		require.Error(t, err)
 		assert.EqualError(t, err, errCommandSentToWrongEntity.Error())
 		require.Nil(t, actual)
 	})
+	t.Run("With idempotency key", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 150.55,
+			AccountOwner:   "John Doe",
+		}
+
+		// create the command
+		command := &pb.CreditAccount{
+			AccountId:      accountID,
+			Amount:         50.00,
+			IdempotencyKey: "order-1/2024-01-01/credit",
+		}
+
+		// perform the credit account command handling
+		actual, err := creditAccount(ctx, command, priorState, nil, nil, nil)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.Equal(t, "order-1/2024-01-01/credit", actual.GetIdempotencyKey())
+	})
+	t.Run("With idempotency key already processed", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:                accountID,
+			AccountBalance:           150.55,
+			AccountOwner:             "John Doe",
+			ProcessedIdempotencyKeys: []string{"order-1/2024-01-01/credit"},
+		}
+
+		// create the command
+		command := &pb.CreditAccount{
+			AccountId:      accountID,
+			Amount:         50.00,
+			IdempotencyKey: "order-1/2024-01-01/credit",
+		}
+
+		// perform the credit account command handling. This is a no-op
+		actual, err := creditAccount(ctx, command, priorState, nil, nil, nil)
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
+	t.Run("With insufficient balance to pay the fee", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 1.00,
+		}
+
+		// create the command
+		command := &pb.CreditAccount{
+			AccountId: accountID,
+			Amount:    2.00,
+		}
+
+		// create the fee schedule charging more than the credit
+		feeSchedule := fees.NewSchedule(&fees.Rule{
+			ID:          "credit-fee",
+			CommandType: "accounts.v1.CreditAccount",
+			Kind:        fees.FlatKind,
+			Value:       5,
+		})
+
+		// perform the credit account command handling
+		actual, err := creditAccount(ctx, command, priorState, nil, feeSchedule, nil)
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = insufficient balance")
+		assert.Equal(t, ReasonInsufficientBalance, ReasonOf(err))
+		assert.Nil(t, actual)
+	})
+	t.Run("With fee covered by the overdraft", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 1.00,
+			AccountType:    pb.AccountType_CHECKING,
+		}
+
+		// create the command
+		command := &pb.CreditAccount{
+			AccountId: accountID,
+			Amount:    2.00,
+		}
+
+		// create the fee schedule charging more than the credit
+		feeSchedule := fees.NewSchedule(&fees.Rule{
+			ID:          "credit-fee",
+			CommandType: "accounts.v1.CreditAccount",
+			Kind:        fees.FlatKind,
+			Value:       5,
+		})
+
+		// create the account rules allowing an overdraft
+		accountRules := accounttypes.NewRegistry(&accounttypes.Rules{
+			AccountType:    "CHECKING",
+			OverdraftLimit: 100,
+		})
+
+		// perform the credit account command handling
+		actual, err := creditAccount(ctx, command, priorState, nil, feeSchedule, accountRules)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.Equal(t, 5.00, actual.GetFee().GetAmount())
+	})
+	t.Run("With credits not allowed", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 1000.00,
+			AccountType:    pb.AccountType_ESCROW,
+		}
+
+		// create the command
+		command := &pb.CreditAccount{
+			AccountId: accountID,
+			Amount:    50.00,
+		}
+
+		expectedErr := status.Error(codes.FailedPrecondition, "credits are not allowed on ESCROW accounts")
+		// perform the credit account command handling
+		actual, err := creditAccount(ctx, command, priorState, nil, nil, accounttypes.NewRegistry())
+		require.Error(t, err)
+		require.Nil(t, actual)
+		assert.EqualError(t, err, expectedErr.Error())
+	})
+	t.Run("With dormant account reactivated", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 150.55,
+			IsDormant:      true,
+		}
+
+		// create the command
+		command := &pb.CreditAccount{
+			AccountId: accountID,
+			Amount:    50.00,
+		}
+
+		// perform the credit account command handling
+		actual, err := creditAccount(ctx, command, priorState, nil, nil, nil)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.True(t, actual.GetReactivated())
+	})
+	t.Run("With dormant account not reactivated by the system", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 150.55,
+			IsDormant:      true,
+		}
+
+		// create the command
+		command := &pb.CreditAccount{
+			AccountId:       accountID,
+			Amount:          50.00,
+			SystemInitiated: true,
+		}
+
+		// perform the credit account command handling
+		actual, err := creditAccount(ctx, command, priorState, nil, nil, nil)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.True(t, actual.GetSystemInitiated())
+		assert.False(t, actual.GetReactivated())
+	})
+	t.Run("With expected revision", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+		expectedRevision := int32(3)
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 150.55,
+		}
+
+		// create the command
+		command := &pb.CreditAccount{
+			AccountId:        accountID,
+			Amount:           50.00,
+			ExpectedRevision: &expectedRevision,
+		}
+
+		// perform the credit account command handling
+		actual, err := creditAccount(ctx, command, priorState, &cospb.MetaData{EntityId: accountID, RevisionNumber: 3}, nil, nil)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+	})
+	t.Run("With expected revision mismatch", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+		expectedRevision := int32(2)
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 150.55,
+		}
+
+		// create the command
+		command := &pb.CreditAccount{
+			AccountId:        accountID,
+			Amount:           50.00,
+			ExpectedRevision: &expectedRevision,
+		}
+
+		expectedErr := status.Error(codes.Aborted, "the account is at revision 3 instead of the expected revision 2")
+		// perform the credit account command handling
+		actual, err := creditAccount(ctx, command, priorState, &cospb.MetaData{EntityId: accountID, RevisionNumber: 3}, nil, nil)
+		require.Error(t, err)
+		require.Nil(t, actual)
+		assert.EqualError(t, err, expectedErr.Error())
+	})
+	t.Run("With expected revision mismatch and idempotency key already processed", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+		expectedRevision := int32(2)
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:                accountID,
+			AccountBalance:           150.55,
+			ProcessedIdempotencyKeys: []string{"credit-1"},
+		}
+
+		// create the command retried after being applied
+		command := &pb.CreditAccount{
+			AccountId:        accountID,
+			Amount:           50.00,
+			IdempotencyKey:   "credit-1",
+			ExpectedRevision: &expectedRevision,
+		}
+
+		// perform the credit account command handling. This is a no-op
+		actual, err := creditAccount(ctx, command, priorState, &cospb.MetaData{EntityId: accountID, RevisionNumber: 3}, nil, nil)
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
 }
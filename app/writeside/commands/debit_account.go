@@ -2,19 +2,24 @@ package commands
 
 import (
 	"context"
+	"slices"
 
 	"github.com/tochemey/gopack/otel/trace"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/tochemey/cos-go-sample/app/log"
+	"github.com/tochemey/cos-go-sample/app/writeside/accounttypes"
+	"github.com/tochemey/cos-go-sample/app/writeside/fees"
 	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
 )
 
 // debitAccount handles the Debit Account command. When the command is valid the account debited event is returned
-// to be persisted. On the contrary a validation error is returned
-func debitAccount(ctx context.Context, command *pb.DebitAccount, priorState *pb.BankAccount) (*pb.AccountDebited, error) {
+// to be persisted with the fee charged according to the fee schedule. The overdraft and the monthly withdrawals allowed by
+// the account type are enforced, and debits are aborted when the account is not at the revision expected by the caller.
+// On the contrary a validation error is returned
+func debitAccount(ctx context.Context, command *pb.DebitAccount, priorState *pb.BankAccount, priorMeta *cospb.MetaData, feeSchedule *fees.Schedule, accountRules *accounttypes.Registry) (*pb.AccountDebited, error) {
 	// add a span context to trace the event handler
 	ctx, span := trace.SpanContext(ctx, "HandleDebitAccount")
 	defer span.End()
@@ -41,17 +46,59 @@ func debitAccount(ctx context.Context, command *pb.DebitAccount, priorState *pb.
 		return nil, errCommandSentToWrongEntity
 	}
 
+	// the debit has already been processed, hence it is a no-op
+	if key := commandCopy.GetIdempotencyKey(); key != "" && slices.Contains(priorStateCopy.GetProcessedIdempotencyKeys(), key) {
+		logger.Infof("the debit with idempotency key:(%s) is already processed", key)
+		return nil, nil
+	}
+
+	// the account has been changed since the revision expected by the caller
+	if commandCopy.ExpectedRevision != nil && commandCopy.GetExpectedRevision() != priorMeta.GetRevisionNumber() {
+		logger.Warnf("the account:(%s) is not at the expected revision:(%d)", command.GetAccountId(), commandCopy.GetExpectedRevision())
+		return nil, errRevisionMismatch(commandCopy.GetExpectedRevision(), priorMeta.GetRevisionNumber())
+	}
+
+	// get the rules governing the account type
+	accountType := accounttypes.Resolve(priorStateCopy.GetAccountType())
+	rules := accountRules.Lookup(accountType)
+
+	// check the monthly withdrawals limit
+	debitedAt := timestamppb.Now()
+	if rules.MaxMonthlyWithdrawals > 0 &&
+		priorStateCopy.GetWithdrawalsMonth() == accounttypes.WithdrawalsMonth(debitedAt.AsTime()) &&
+		priorStateCopy.GetMonthlyWithdrawals() >= rules.MaxMonthlyWithdrawals {
+		logger.Warnf("the account:(%s) has reached its monthly withdrawals limit", command.GetAccountId())
+		return nil, errMonthlyWithdrawalsExceeded(accountType, rules.MaxMonthlyWithdrawals)
+	}
+
+	// evaluate the fee charged on the debit
+	fee := chargeFee(feeSchedule, commandCopy, priorStateCopy, commandCopy.GetAmount())
+
 	// perform some validation
-	balanceAfter := priorStateCopy.GetAccountBalance() - commandCopy.GetAmount()
-	// return a validation error when the balance after is negative or zero
-	if balanceAfter <= 0 {
+	balanceAfter := priorStateCopy.GetAccountBalance() - commandCopy.GetAmount() - fee.GetAmount()
+	// return a validation error when the balance after is not covered
+	if !covered(balanceAfter, rules.OverdraftLimit) {
 		logger.Warn("insufficient balance")
-		return nil, status.Error(codes.InvalidArgument, "insufficient balance")
+		return nil, errInsufficientBalance(priorStateCopy.GetAccountBalance(), commandCopy.GetAmount())
 	}
 
 	// create the account debited event to persist into the data store
 	return &pb.AccountDebited{
-		AccountId: commandCopy.GetAccountId(),
-		Amount:    commandCopy.GetAmount(),
+		AccountId:       commandCopy.GetAccountId(),
+		Amount:          commandCopy.GetAmount(),
+		IdempotencyKey:  commandCopy.GetIdempotencyKey(),
+		SystemInitiated: commandCopy.GetSystemInitiated(),
+		Reactivated:     reactivates(commandCopy, priorStateCopy),
+		Fee:             fee,
+		DebitedAt:       debitedAt,
 	}, nil
 }
+
+// covered checks whether a given balance resulting from a command is covered by the account. Without overdraft the balance
+// must remain positive, while with an overdraft it can go down to the overdraft limit, landing exactly on it
+func covered(balance, overdraftLimit float64) bool {
+	if overdraftLimit == 0 {
+		return balance > 0
+	}
+	return balance >= -overdraftLimit
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -10,7 +11,10 @@ import (
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/tochemey/cos-go-sample/app/writeside/accounttypes"
+	"github.com/tochemey/cos-go-sample/app/writeside/fees"
 	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
 )
 
 func TestDebitAccount(t *testing.T) {
@@ -42,10 +46,13 @@ func TestDebitAccount(t *testing.T) {
 		}
 
 		// perform the credit account command handling
-		actual, err := debitAccount(ctx, command, priorState)
+		actual, err := debitAccount(ctx, command, priorState, nil, nil, nil)
 		require.NoError(t, err)
 		require.NotNil(t, actual)
 		require.IsType(t, new(pb.AccountDebited), actual)
+		// the debit time is set by the command handler
+		require.NotNil(t, actual.GetDebitedAt())
+		expected.DebitedAt = actual.GetDebitedAt()
 		assert.True(t, proto.Equal(expected, actual))
 	})
 	t.Run("With prior state not defined", func(t *testing.T) {
@@ -63,7 +70,7 @@ func TestDebitAccount(t *testing.T) {
 		}
 
 		// perform the credit account command handling
-		actual, err := debitAccount(ctx, command, priorState)
+		actual, err := debitAccount(ctx, command, priorState, nil, nil, nil)
 		require.Error(t, err)
 		assert.EqualError(t, err, errMissingPriorState.Error())
 		require.Nil(t, actual)
@@ -86,7 +93,7 @@ func TestDebitAccount(t *testing.T) {
 		}
 
 		// perform the credit account command handling
-		actual, err := debitAccount(ctx, command, priorState)
+		actual, err := debitAccount(ctx, command, priorState, nil, nil, nil)
 		require.Error(t, err)
 		assert.EqualError(t, err, errCommandSentToWrongEntity.Error())
 		require.Nil(t, actual)
@@ -112,11 +119,440 @@ func TestDebitAccount(t *testing.T) {
 			Amount:    amount,
 		}
 
-		expectedErr := status.Error(codes.InvalidArgument, "insufficient balance")
+		expectedErr := status.Error(codes.FailedPrecondition, "insufficient balance")
 		// perform the credit account command handling
-		actual, err := debitAccount(ctx, command, priorState)
+		actual, err := debitAccount(ctx, command, priorState, nil, nil, nil)
 		require.Error(t, err)
 		require.Nil(t, actual)
 		assert.EqualError(t, err, expectedErr.Error())
+		assert.Equal(t, ReasonInsufficientBalance, ReasonOf(err))
+	})
+	t.Run("With fee charged", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+		accountBal := 150.55
+		accountOwner := "John Doe"
+		amount := 50.00
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: accountBal,
+			AccountOwner:   accountOwner,
+			IsClosed:       false,
+		}
+
+		// create the command
+		command := &pb.DebitAccount{
+			AccountId: accountID,
+			Amount:    amount,
+		}
+
+		// create the fee schedule
+		feeSchedule := fees.NewSchedule(&fees.Rule{
+			ID:          "debit-fee",
+			CommandType: "accounts.v1.DebitAccount",
+			Kind:        fees.PercentageKind,
+			Value:       1,
+			MinFee:      1,
+		})
+
+		// perform the debit account command handling
+		actual, err := debitAccount(ctx, command, priorState, nil, feeSchedule, nil)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.Equal(t, amount, actual.GetAmount())
+		require.NotNil(t, actual.GetFee())
+		assert.Equal(t, accountID, actual.GetFee().GetAccountId())
+		assert.Equal(t, "debit-fee", actual.GetFee().GetRuleId())
+		assert.Equal(t, "accounts.v1.DebitAccount", actual.GetFee().GetCommandType())
+		assert.Equal(t, 1.00, actual.GetFee().GetAmount())
+		assert.NotEmpty(t, actual.GetFee().GetFeeId())
+	})
+	t.Run("With insufficient balance to pay the fee", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+		accountBal := 50.50
+		amount := 50.00
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: accountBal,
+		}
+
+		// create the command
+		command := &pb.DebitAccount{
+			AccountId: accountID,
+			Amount:    amount,
+		}
+
+		// create the fee schedule
+		feeSchedule := fees.NewSchedule(&fees.Rule{
+			ID:          "debit-fee",
+			CommandType: "accounts.v1.DebitAccount",
+			Kind:        fees.FlatKind,
+			Value:       1,
+		})
+
+		expectedErr := status.Error(codes.FailedPrecondition, "insufficient balance")
+		// perform the debit account command handling
+		actual, err := debitAccount(ctx, command, priorState, nil, feeSchedule, nil)
+		require.Error(t, err)
+		require.Nil(t, actual)
+		assert.EqualError(t, err, expectedErr.Error())
+		assert.Equal(t, ReasonInsufficientBalance, ReasonOf(err))
+	})
+	t.Run("With idempotency key", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 150.55,
+			AccountOwner:   "John Doe",
+		}
+
+		// create the command
+		command := &pb.DebitAccount{
+			AccountId:      accountID,
+			Amount:         50.00,
+			IdempotencyKey: "order-1/2024-01-01/debit",
+		}
+
+		// perform the debit account command handling
+		actual, err := debitAccount(ctx, command, priorState, nil, nil, nil)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.Equal(t, "order-1/2024-01-01/debit", actual.GetIdempotencyKey())
+	})
+	t.Run("With idempotency key already processed", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:                accountID,
+			AccountBalance:           150.55,
+			AccountOwner:             "John Doe",
+			ProcessedIdempotencyKeys: []string{"order-1/2024-01-01/debit"},
+		}
+
+		// create the command
+		command := &pb.DebitAccount{
+			AccountId:      accountID,
+			Amount:         50.00,
+			IdempotencyKey: "order-1/2024-01-01/debit",
+		}
+
+		// perform the debit account command handling. This is a no-op
+		actual, err := debitAccount(ctx, command, priorState, nil, nil, nil)
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
+	t.Run("With overdraft allowed", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 20.00,
+			AccountType:    pb.AccountType_CHECKING,
+		}
+
+		// create the command
+		command := &pb.DebitAccount{
+			AccountId: accountID,
+			Amount:    50.00,
+		}
+
+		// create the account rules
+		accountRules := accounttypes.NewRegistry(&accounttypes.Rules{
+			AccountType:    "CHECKING",
+			OverdraftLimit: 100,
+		})
+
+		// perform the debit account command handling
+		actual, err := debitAccount(ctx, command, priorState, nil, nil, accountRules)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.Equal(t, 50.00, actual.GetAmount())
+	})
+	t.Run("With balance debited down to zero", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 50.00,
+		}
+
+		// create the command
+		command := &pb.DebitAccount{
+			AccountId: accountID,
+			Amount:    50.00,
+		}
+
+		// the balance must remain positive without overdraft
+		actual, err := debitAccount(ctx, command, priorState, nil, nil, nil)
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = insufficient balance")
+		assert.Nil(t, actual)
+	})
+	t.Run("With balance debited down to a cent", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 50.00,
+		}
+
+		// create the command
+		command := &pb.DebitAccount{
+			AccountId: accountID,
+			Amount:    49.99,
+		}
+
+		// perform the debit account command handling
+		actual, err := debitAccount(ctx, command, priorState, nil, nil, nil)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.Equal(t, 49.99, actual.GetAmount())
+	})
+	t.Run("With balance debited down to the overdraft limit", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 20.00,
+			AccountType:    pb.AccountType_CHECKING,
+		}
+
+		// create the command
+		command := &pb.DebitAccount{
+			AccountId: accountID,
+			Amount:    120.00,
+		}
+
+		// create the account rules
+		accountRules := accounttypes.NewRegistry(&accounttypes.Rules{
+			AccountType:    "CHECKING",
+			OverdraftLimit: 100,
+		})
+
+		// perform the debit account command handling
+		actual, err := debitAccount(ctx, command, priorState, nil, nil, accountRules)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.Equal(t, 120.00, actual.GetAmount())
+	})
+	t.Run("With overdraft limit exceeded", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 20.00,
+			AccountType:    pb.AccountType_CHECKING,
+		}
+
+		// create the command
+		command := &pb.DebitAccount{
+			AccountId: accountID,
+			Amount:    150.00,
+		}
+
+		// create the account rules
+		accountRules := accounttypes.NewRegistry(&accounttypes.Rules{
+			AccountType:    "CHECKING",
+			OverdraftLimit: 100,
+		})
+
+		expectedErr := status.Error(codes.FailedPrecondition, "insufficient balance")
+		// perform the debit account command handling
+		actual, err := debitAccount(ctx, command, priorState, nil, nil, accountRules)
+		require.Error(t, err)
+		require.Nil(t, actual)
+		assert.EqualError(t, err, expectedErr.Error())
+		assert.Equal(t, ReasonInsufficientBalance, ReasonOf(err))
+	})
+	t.Run("With monthly withdrawals limit reached", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:          accountID,
+			AccountBalance:     500.00,
+			AccountType:        pb.AccountType_SAVINGS,
+			MonthlyWithdrawals: 6,
+			WithdrawalsMonth:   accounttypes.WithdrawalsMonth(time.Now()),
+		}
+
+		// create the command
+		command := &pb.DebitAccount{
+			AccountId: accountID,
+			Amount:    50.00,
+		}
+
+		expectedErr := status.Error(codes.FailedPrecondition, "SAVINGS accounts are limited to 6 withdrawals per month")
+		// perform the debit account command handling
+		actual, err := debitAccount(ctx, command, priorState, nil, nil, accounttypes.NewRegistry())
+		require.Error(t, err)
+		require.Nil(t, actual)
+		assert.EqualError(t, err, expectedErr.Error())
+	})
+	t.Run("With monthly withdrawals limit reached in a previous month", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:          accountID,
+			AccountBalance:     500.00,
+			AccountType:        pb.AccountType_SAVINGS,
+			MonthlyWithdrawals: 6,
+			WithdrawalsMonth:   "2024-01",
+		}
+
+		// create the command
+		command := &pb.DebitAccount{
+			AccountId: accountID,
+			Amount:    50.00,
+		}
+
+		// perform the debit account command handling
+		actual, err := debitAccount(ctx, command, priorState, nil, nil, accounttypes.NewRegistry())
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.Equal(t, 50.00, actual.GetAmount())
+	})
+	t.Run("With dormant account reactivated", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 150.55,
+			IsDormant:      true,
+		}
+
+		// create the command
+		command := &pb.DebitAccount{
+			AccountId: accountID,
+			Amount:    50.00,
+		}
+
+		// perform the debit account command handling
+		actual, err := debitAccount(ctx, command, priorState, nil, nil, nil)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.True(t, actual.GetReactivated())
+	})
+	t.Run("With dormant account not reactivated by the system", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 150.55,
+			IsDormant:      true,
+		}
+
+		// create the command
+		command := &pb.DebitAccount{
+			AccountId:       accountID,
+			Amount:          50.00,
+			SystemInitiated: true,
+		}
+
+		// perform the debit account command handling
+		actual, err := debitAccount(ctx, command, priorState, nil, nil, nil)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.True(t, actual.GetSystemInitiated())
+		assert.False(t, actual.GetReactivated())
+	})
+	t.Run("With expected revision", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+		expectedRevision := int32(3)
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 150.55,
+		}
+
+		// create the command
+		command := &pb.DebitAccount{
+			AccountId:        accountID,
+			Amount:           50.00,
+			ExpectedRevision: &expectedRevision,
+		}
+
+		// perform the debit account command handling
+		actual, err := debitAccount(ctx, command, priorState, &cospb.MetaData{EntityId: accountID, RevisionNumber: 3}, nil, nil)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+	})
+	t.Run("With expected revision mismatch", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+		expectedRevision := int32(2)
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 150.55,
+		}
+
+		// create the command
+		command := &pb.DebitAccount{
+			AccountId:        accountID,
+			Amount:           50.00,
+			ExpectedRevision: &expectedRevision,
+		}
+
+		expectedErr := status.Error(codes.Aborted, "the account is at revision 3 instead of the expected revision 2")
+		// perform the debit account command handling
+		actual, err := debitAccount(ctx, command, priorState, &cospb.MetaData{EntityId: accountID, RevisionNumber: 3}, nil, nil)
+		require.Error(t, err)
+		require.Nil(t, actual)
+		assert.EqualError(t, err, expectedErr.Error())
+	})
+	t.Run("With expected revision mismatch and idempotency key already processed", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+		expectedRevision := int32(2)
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:                accountID,
+			AccountBalance:           150.55,
+			ProcessedIdempotencyKeys: []string{"debit-1"},
+		}
+
+		// create the command retried after being applied
+		command := &pb.DebitAccount{
+			AccountId:        accountID,
+			Amount:           50.00,
+			IdempotencyKey:   "debit-1",
+			ExpectedRevision: &expectedRevision,
+		}
+
+		// perform the debit account command handling. This is a no-op
+		actual, err := debitAccount(ctx, command, priorState, &cospb.MetaData{EntityId: accountID, RevisionNumber: 3}, nil, nil)
+		require.NoError(t, err)
+		assert.Nil(t, actual)
 	})
 }
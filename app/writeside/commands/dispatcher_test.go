@@ -9,12 +9,14 @@ import (
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
 
+	"github.com/tochemey/cos-go-sample/app/writeside/accounttypes"
+	"github.com/tochemey/cos-go-sample/app/writeside/fees"
 	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
 	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
 )
 
 func TestNewDispatcher(t *testing.T) {
-	dispatcher := NewDispatcher()
+	dispatcher := NewDispatcher(fees.NewSchedule(), accounttypes.NewRegistry())
 	assert.NotNil(t, dispatcher)
 	var p interface{} = dispatcher
 	_, ok := p.(Dispatcher)
@@ -80,7 +82,7 @@ func TestDispatch(t *testing.T) {
 		priorMeta := &cospb.MetaData{EntityId: accountID}
 
 		// create the instance of the dispatcher
-		dispatcher := NewDispatcher()
+		dispatcher := NewDispatcher(fees.NewSchedule(), accounttypes.NewRegistry())
 
 		// perform the credit account command handling
 		actual, err := dispatcher.Dispatch(ctx, command, priorState, priorMeta)
@@ -105,15 +107,16 @@ func TestDispatch(t *testing.T) {
 
 		// create the expected outcome
 		expected := &pb.AccountOpened{
-			AccountId: accountID,
-			Balance:   amount,
+			AccountId:   accountID,
+			Balance:     amount,
+			AccountType: pb.AccountType_CHECKING,
 		}
 
 		// create the cos prior meta
 		priorMeta := &cospb.MetaData{EntityId: accountID, RevisionNumber: 2}
 
 		// create the instance of the dispatcher
-		dispatcher := NewDispatcher()
+		dispatcher := NewDispatcher(fees.NewSchedule(), accounttypes.NewRegistry())
 
 		// perform the credit account command handling
 		actual, err := dispatcher.Dispatch(ctx, command, priorState, priorMeta)
@@ -152,13 +155,116 @@ func TestDispatch(t *testing.T) {
 		priorMeta := &cospb.MetaData{EntityId: accountID, RevisionNumber: 2}
 
 		// create the instance of the dispatcher
-		dispatcher := NewDispatcher()
+		dispatcher := NewDispatcher(fees.NewSchedule(), accounttypes.NewRegistry())
 
 		// perform the credit account command handling
 		actual, err := dispatcher.Dispatch(ctx, command, priorState, priorMeta)
 		require.NoError(t, err)
 		require.NotNil(t, actual)
 		require.IsType(t, new(pb.AccountDebited), actual)
+		// the debit time is set by the command handler
+		require.NotNil(t, actual.(*pb.AccountDebited).GetDebitedAt())
+		expected.DebitedAt = actual.(*pb.AccountDebited).GetDebitedAt()
+		assert.True(t, proto.Equal(expected, actual))
+	})
+	t.Run("With WaiveFee command", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+		accountBal := 150.55
+		accountOwner := "John Doe"
+		amount := 2.50
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:        accountID,
+			AccountBalance:   accountBal,
+			AccountOwner:     accountOwner,
+			TotalFeesCharged: 5.00,
+		}
+
+		// create the command
+		command := &pb.WaiveFee{
+			AccountId: accountID,
+			Amount:    amount,
+			Reason:    "goodwill",
+		}
+
+		// create the expected outcome
+		expected := &pb.FeeWaived{
+			AccountId: accountID,
+			Amount:    amount,
+			Reason:    "goodwill",
+		}
+		// create the cos prior meta
+		priorMeta := &cospb.MetaData{EntityId: accountID, RevisionNumber: 2}
+
+		// create the instance of the dispatcher
+		dispatcher := NewDispatcher(fees.NewSchedule(), accounttypes.NewRegistry())
+
+		// perform the waive fee command handling
+		actual, err := dispatcher.Dispatch(ctx, command, priorState, priorMeta)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		require.IsType(t, new(pb.FeeWaived), actual)
+		assert.True(t, proto.Equal(expected, actual))
+	})
+	t.Run("With MarkAccountDormant command", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 150.55,
+			AccountOwner:   "John Doe",
+		}
+
+		// create the command
+		command := &pb.MarkAccountDormant{AccountId: accountID}
+
+		// create the expected outcome
+		expected := &pb.AccountMarkedDormant{AccountId: accountID}
+		// create the cos prior meta
+		priorMeta := &cospb.MetaData{EntityId: accountID, RevisionNumber: 2}
+
+		// create the instance of the dispatcher
+		dispatcher := NewDispatcher(fees.NewSchedule(), accounttypes.NewRegistry())
+
+		// perform the mark account dormant command handling
+		actual, err := dispatcher.Dispatch(ctx, command, priorState, priorMeta)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		require.IsType(t, new(pb.AccountMarkedDormant), actual)
 		assert.True(t, proto.Equal(expected, actual))
 	})
+	t.Run("With CreditAccount command and expected revision mismatch", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+		expectedRevision := int32(1)
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 150.55,
+		}
+
+		// create the command
+		command := &pb.CreditAccount{
+			AccountId:        accountID,
+			Amount:           50.00,
+			ExpectedRevision: &expectedRevision,
+		}
+
+		// create the cos prior meta
+		priorMeta := &cospb.MetaData{EntityId: accountID, RevisionNumber: 2}
+
+		// create the instance of the dispatcher
+		dispatcher := NewDispatcher(fees.NewSchedule(), accounttypes.NewRegistry())
+
+		// perform the credit account command handling
+		actual, err := dispatcher.Dispatch(ctx, command, priorState, priorMeta)
+		require.Error(t, err)
+		assert.Nil(t, actual)
+		assert.EqualError(t, err, errRevisionMismatch(expectedRevision, 2).Error())
+	})
 }
@@ -0,0 +1,208 @@
+package commands
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/protoadapt"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// ErrorDomain is the domain of the errors returned by the commands handlers
+const ErrorDomain = "accounts.v1"
+
+// Reason is the stable reason code of a domain error. Clients branch on it rather than on the error message
+type Reason string
+
+// The reasons of the domain errors
+const (
+	ReasonCommandNotDefined          Reason = "COMMAND_NOT_DEFINED"
+	ReasonUnhandledCommand           Reason = "UNHANDLED_COMMAND"
+	ReasonMissingPriorState          Reason = "MISSING_PRIOR_STATE"
+	ReasonWrongEntity                Reason = "WRONG_ENTITY"
+	ReasonRevisionMismatch           Reason = "REVISION_MISMATCH"
+	ReasonInvalidAmount              Reason = "INVALID_AMOUNT"
+	ReasonInsufficientBalance        Reason = "INSUFFICIENT_BALANCE"
+	ReasonCreditsNotAllowed          Reason = "CREDITS_NOT_ALLOWED"
+	ReasonMonthlyWithdrawalsExceeded Reason = "MONTHLY_WITHDRAWALS_EXCEEDED"
+	ReasonOpeningBalanceTooLow       Reason = "OPENING_BALANCE_TOO_LOW"
+	ReasonWaiverExceedsFees          Reason = "WAIVER_EXCEEDS_FEES"
+	ReasonAccountClosed              Reason = "ACCOUNT_CLOSED"
+	ReasonStandingOrderAlreadyExists Reason = "STANDING_ORDER_ALREADY_EXISTS"
+	ReasonInvalidStandingOrder       Reason = "INVALID_STANDING_ORDER"
+	ReasonInvalidStandingOrderStatus Reason = "INVALID_STANDING_ORDER_STATUS"
+	ReasonExecutionDateNotSet        Reason = "EXECUTION_DATE_NOT_SET"
+	ReasonExecutionNotDue            Reason = "EXECUTION_NOT_DUE"
+	ReasonCrossTenant                Reason = "CROSS_TENANT"
+	ReasonEventNotDefined            Reason = "EVENT_NOT_DEFINED"
+	ReasonUnhandledEvent             Reason = "UNHANDLED_EVENT"
+)
+
+// entityIDKey is the metadata key of the id of the entity targeted by the command
+const entityIDKey = "entity_id"
+
+// Error is a domain error returned by the commands handlers. It is converted into a gRPC status carrying
+// an ErrorInfo detail with its reason and metadata, and a PreconditionFailure detail when a precondition failed
+type Error struct {
+	code     codes.Code
+	reason   Reason
+	message  string
+	metadata map[string]string
+}
+
+var _ error = (*Error)(nil)
+
+// newError creates an instance of Error
+func NewError(code codes.Code, reason Reason, metadata map[string]string, format string, args ...any) *Error {
+	return &Error{
+		code:     code,
+		reason:   reason,
+		message:  fmt.Sprintf(format, args...),
+		metadata: metadata,
+	}
+}
+
+// Error returns the error message
+func (e *Error) Error() string {
+	return e.GRPCStatus().Err().Error()
+}
+
+// Code returns the gRPC code of the error
+func (e *Error) Code() codes.Code {
+	return e.code
+}
+
+// Reason returns the reason of the error
+func (e *Error) Reason() Reason {
+	return e.reason
+}
+
+// Metadata returns the metadata of the error
+func (e *Error) Metadata() map[string]string {
+	return maps.Clone(e.metadata)
+}
+
+// WithEntity returns a copy of the error targeting the given entity
+func (e *Error) WithEntity(entityID string) *Error {
+	if entityID == "" {
+		return e
+	}
+	metadata := maps.Clone(e.metadata)
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+	metadata[entityIDKey] = entityID
+	return &Error{code: e.code, reason: e.reason, message: e.message, metadata: metadata}
+}
+
+// GRPCStatus returns the gRPC status of the error with its details
+func (e *Error) GRPCStatus() *status.Status {
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{
+			Reason:   string(e.reason),
+			Domain:   ErrorDomain,
+			Metadata: e.metadata,
+		},
+	}
+
+	// the business rules violations are described as failed preconditions
+	if e.code == codes.FailedPrecondition {
+		details = append(details, &errdetails.PreconditionFailure{
+			Violations: []*errdetails.PreconditionFailure_Violation{
+				{
+					Type:        string(e.reason),
+					Subject:     e.metadata[entityIDKey],
+					Description: e.message,
+				},
+			},
+		})
+	}
+
+	st := status.New(e.code, e.message)
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st
+	}
+	return withDetails
+}
+
+// ReasonOf returns the reason of a given domain error, whether it is an Error or the gRPC status it has been converted into.
+// An empty reason is returned for the other errors
+func ReasonOf(err error) Reason {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.reason
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return ""
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetDomain() == ErrorDomain {
+			return Reason(info.GetReason())
+		}
+	}
+	return ""
+}
+
+var (
+	errCommandNotDefined        = NewError(codes.Internal, ReasonCommandNotDefined, nil, "the command is not defined")
+	errMissingPriorState        = NewError(codes.InvalidArgument, ReasonMissingPriorState, nil, "the priorState is not defined")
+	errCommandSentToWrongEntity = NewError(codes.InvalidArgument, ReasonWrongEntity, nil, "the command is sent to the wrong entity")
+	errUnhandledCommand         = func(command proto.Message) error {
+		commandType := string(command.ProtoReflect().Descriptor().FullName())
+		return NewError(codes.Internal, ReasonUnhandledCommand, map[string]string{"command_type": commandType},
+			"received unhandled command (%s)", commandType)
+	}
+	errCreditsNotAllowed = func(accountType pb.AccountType) error {
+		return NewError(codes.FailedPrecondition, ReasonCreditsNotAllowed, map[string]string{"account_type": accountType.String()},
+			"credits are not allowed on %s accounts", accountType.String())
+	}
+	errMonthlyWithdrawalsExceeded = func(accountType pb.AccountType, maxWithdrawals int32) error {
+		return NewError(codes.FailedPrecondition, ReasonMonthlyWithdrawalsExceeded,
+			map[string]string{"account_type": accountType.String(), "max_withdrawals": fmt.Sprint(maxWithdrawals)},
+			"%s accounts are limited to %d withdrawals per month", accountType.String(), maxWithdrawals)
+	}
+	errRevisionMismatch = func(expectedRevision, revision int32) error {
+		return NewError(codes.Aborted, ReasonRevisionMismatch,
+			map[string]string{"expected_revision": fmt.Sprint(expectedRevision), "revision": fmt.Sprint(revision)},
+			"the account is at revision %d instead of the expected revision %d", revision, expectedRevision)
+	}
+	errInsufficientBalance = func(balance, amount float64) error {
+		return NewError(codes.FailedPrecondition, ReasonInsufficientBalance,
+			map[string]string{"balance": fmt.Sprintf("%.2f", balance), "amount": fmt.Sprintf("%.2f", amount)},
+			"insufficient balance")
+	}
+	errOpeningBalanceTooLow = func(accountType pb.AccountType, minOpeningBalance float64) error {
+		return NewError(codes.InvalidArgument, ReasonOpeningBalanceTooLow,
+			map[string]string{"account_type": accountType.String(), "min_opening_balance": fmt.Sprintf("%.2f", minOpeningBalance)},
+			"the opening balance is below the minimum required for %s accounts (%.2f)", accountType.String(), minOpeningBalance)
+	}
+	errInvalidWaiverAmount = NewError(codes.InvalidArgument, ReasonInvalidAmount, nil, "invalid waiver amount")
+	errWaiverExceedsFees   = func(outstandingFees float64) error {
+		return NewError(codes.FailedPrecondition, ReasonWaiverExceedsFees, map[string]string{"outstanding_fees": fmt.Sprintf("%.2f", outstandingFees)},
+			"waiver amount exceeds the outstanding fees")
+	}
+	errAccountClosed              = NewError(codes.FailedPrecondition, ReasonAccountClosed, nil, "the account is closed")
+	errStandingOrderAlreadyExists = NewError(codes.AlreadyExists, ReasonStandingOrderAlreadyExists, nil, "the standing order already exists")
+	errInvalidStandingOrder       = func(reason string) error {
+		return NewError(codes.InvalidArgument, ReasonInvalidStandingOrder, nil, "%s", reason)
+	}
+	errInvalidStandingOrderStatus = func(orderStatus pb.StandingOrderStatus) error {
+		return NewError(codes.FailedPrecondition, ReasonInvalidStandingOrderStatus, map[string]string{"status": orderStatus.String()},
+			"the standing order is %s", orderStatus.String())
+	}
+	errExecutionDateNotSet = NewError(codes.InvalidArgument, ReasonExecutionDateNotSet, nil, "the execution date is not set")
+	errExecutionNotDue     = NewError(codes.FailedPrecondition, ReasonExecutionNotDue, nil, "the standing order execution is not due")
+	errCrossTenant         = func(tenantID string) error {
+		return NewError(codes.PermissionDenied, ReasonCrossTenant, map[string]string{"tenant_id": tenantID},
+			"the command targets entities outside of the tenant:(%s)", tenantID)
+	}
+)
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestError(t *testing.T) {
+	t.Run("With error info", func(t *testing.T) {
+		err := errRevisionMismatch(2, 3)
+
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		assert.Equal(t, codes.Aborted, st.Code())
+		assert.Equal(t, "the account is at revision 3 instead of the expected revision 2", st.Message())
+		assert.EqualError(t, err, status.Error(codes.Aborted, st.Message()).Error())
+
+		// only the error info is attached to a non precondition failure
+		require.Len(t, st.Details(), 1)
+		info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+		require.True(t, ok)
+		assert.Equal(t, string(ReasonRevisionMismatch), info.GetReason())
+		assert.Equal(t, ErrorDomain, info.GetDomain())
+		assert.Equal(t, map[string]string{"expected_revision": "2", "revision": "3"}, info.GetMetadata())
+	})
+	t.Run("With precondition failure", func(t *testing.T) {
+		domainErr := new(Error)
+		require.True(t, errors.As(errCreditsNotAllowed(pb.AccountType_ESCROW), &domainErr))
+		err := domainErr.WithEntity("account-1")
+
+		st := err.GRPCStatus()
+		assert.Equal(t, codes.FailedPrecondition, st.Code())
+		require.Len(t, st.Details(), 2)
+
+		info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+		require.True(t, ok)
+		assert.Equal(t, string(ReasonCreditsNotAllowed), info.GetReason())
+		assert.Equal(t, map[string]string{"account_type": "ESCROW", "entity_id": "account-1"}, info.GetMetadata())
+
+		failure, ok := st.Details()[1].(*errdetails.PreconditionFailure)
+		require.True(t, ok)
+		require.Len(t, failure.GetViolations(), 1)
+		assert.Equal(t, string(ReasonCreditsNotAllowed), failure.GetViolations()[0].GetType())
+		assert.Equal(t, "account-1", failure.GetViolations()[0].GetSubject())
+		assert.Equal(t, "credits are not allowed on ESCROW accounts", failure.GetViolations()[0].GetDescription())
+
+		// the original error is left untouched
+		assert.Equal(t, map[string]string{"account_type": "ESCROW"}, domainErr.Metadata())
+	})
+	t.Run("With reason of a converted error", func(t *testing.T) {
+		err := status.ErrorProto(errInsufficientBalance(10, 20).(*Error).GRPCStatus().Proto())
+		assert.Equal(t, ReasonInsufficientBalance, ReasonOf(err))
+		assert.Equal(t, ReasonAccountClosed, ReasonOf(errors.Wrap(errAccountClosed, "failed to handle command")))
+	})
+	t.Run("With reason of another error", func(t *testing.T) {
+		assert.Empty(t, ReasonOf(status.Error(codes.Internal, "boom")))
+		assert.Empty(t, ReasonOf(errors.New("boom")))
+	})
+}
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/tochemey/gopack/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/tochemey/cos-go-sample/app/log"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// markAccountDormant handles the Mark Account Dormant command. When the command is valid the account marked dormant event
+// is returned to be persisted. Marking an already dormant account is a no-op. On the contrary a validation error is returned
+func markAccountDormant(ctx context.Context, command *pb.MarkAccountDormant, priorState *pb.BankAccount) (*pb.AccountMarkedDormant, error) {
+	// add a span context to trace the event handler
+	ctx, span := trace.SpanContext(ctx, "HandleMarkAccountDormant")
+	defer span.End()
+
+	// get the context logger
+	logger := log.WithContext(ctx)
+
+	// let us make a copy of the command and the prior state
+	commandCopy := proto.Clone(command).(*pb.MarkAccountDormant)
+	priorStateCopy := proto.Clone(priorState).(*pb.BankAccount)
+
+	// check whether the prior state is defined or not
+	if priorStateCopy == nil || proto.Equal(priorStateCopy, new(pb.BankAccount)) {
+		// log a message for debugging purpose
+		logger.Error("the prior state is not defined")
+		// return an error of missing prior state
+		return nil, errMissingPriorState
+	}
+
+	// let us verify that the command is sent to right aggregate.
+	// this scenario with never occur but sanity check requires such verification
+	if command.GetAccountId() != priorState.GetAccountId() {
+		logger.Errorf("the account state:(%s) is not found", command.GetAccountId())
+		return nil, errCommandSentToWrongEntity
+	}
+
+	// a closed account cannot become dormant
+	if priorStateCopy.GetIsClosed() {
+		logger.Warnf("the account:(%s) is closed", command.GetAccountId())
+		return nil, errAccountClosed
+	}
+
+	// the account is already dormant, hence it is a no-op
+	if priorStateCopy.GetIsDormant() {
+		logger.Infof("the account:(%s) is already dormant", command.GetAccountId())
+		return nil, nil
+	}
+
+	// create the account marked dormant event to persist into the data store
+	return &pb.AccountMarkedDormant{
+		AccountId:      commandCopy.GetAccountId(),
+		LastActivityAt: commandCopy.GetLastActivityAt(),
+	}, nil
+}
+
+// systemInitiated is implemented by the credit and debit commands
+type systemInitiated interface {
+	GetSystemInitiated() bool
+}
+
+// reactivates returns true when the given credit or debit command reactivates the dormant account.
+// Only the commands initiated by the customer reactivate a dormant account
+func reactivates(command systemInitiated, account *pb.BankAccount) bool {
+	return account.GetIsDormant() && !command.GetSystemInitiated()
+}
@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestMarkAccountDormant(t *testing.T) {
+	t.Run("With happy path", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+		lastActivityAt := timestamppb.Now()
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 150.55,
+			AccountOwner:   "John Doe",
+		}
+
+		// create the command
+		command := &pb.MarkAccountDormant{
+			AccountId:      accountID,
+			LastActivityAt: lastActivityAt,
+		}
+
+		// create the expected outcome
+		expected := &pb.AccountMarkedDormant{
+			AccountId:      accountID,
+			LastActivityAt: lastActivityAt,
+		}
+
+		// perform the mark account dormant command handling
+		actual, err := markAccountDormant(ctx, command, priorState)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		require.IsType(t, new(pb.AccountMarkedDormant), actual)
+		assert.True(t, proto.Equal(expected, actual))
+	})
+	t.Run("With prior state not defined", func(t *testing.T) {
+		ctx := context.TODO()
+
+		// create the command
+		command := &pb.MarkAccountDormant{AccountId: "account-1"}
+
+		// perform the mark account dormant command handling
+		actual, err := markAccountDormant(ctx, command, new(pb.BankAccount))
+		require.Error(t, err)
+		assert.EqualError(t, err, errMissingPriorState.Error())
+		require.Nil(t, actual)
+	})
+	t.Run("With mismatch account id in command and prior state", func(t *testing.T) {
+		ctx := context.TODO()
+
+		// create the command
+		command := &pb.MarkAccountDormant{AccountId: "account-1"}
+
+		// perform the mark account dormant command handling
+		actual, err := markAccountDormant(ctx, command, &pb.BankAccount{AccountId: "mismatch-1"})
+		require.Error(t, err)
+		assert.EqualError(t, err, errCommandSentToWrongEntity.Error())
+		require.Nil(t, actual)
+	})
+	t.Run("With closed account", func(t *testing.T) {
+		ctx := context.TODO()
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId: "account-1",
+			IsClosed:  true,
+		}
+
+		// create the command
+		command := &pb.MarkAccountDormant{AccountId: "account-1"}
+
+		expectedErr := status.Error(codes.FailedPrecondition, "the account is closed")
+		// perform the mark account dormant command handling
+		actual, err := markAccountDormant(ctx, command, priorState)
+		require.Error(t, err)
+		require.Nil(t, actual)
+		assert.EqualError(t, err, expectedErr.Error())
+	})
+	t.Run("With account already dormant", func(t *testing.T) {
+		ctx := context.TODO()
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      "account-1",
+			AccountBalance: 150.55,
+			IsDormant:      true,
+		}
+
+		// create the command
+		command := &pb.MarkAccountDormant{AccountId: "account-1"}
+
+		// perform the mark account dormant command handling. This is a no-op
+		actual, err := markAccountDormant(ctx, command, priorState)
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
+}
@@ -6,22 +6,47 @@ import (
 	"github.com/tochemey/gopack/otel/trace"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/tochemey/cos-go-sample/app/log"
+	"github.com/tochemey/cos-go-sample/app/tenant"
+	"github.com/tochemey/cos-go-sample/app/writeside/accounttypes"
 	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
 )
 
 // openAccount handles the Open Account command. When the command is valid the account credited event is returned
 // to be persisted. On the contrary a validation error is returned
-func openAccount(ctx context.Context, command *pb.OpenAccount) (*pb.AccountOpened, error) {
+func openAccount(ctx context.Context, command *pb.OpenAccount, accountRules *accounttypes.Registry) (*pb.AccountOpened, error) {
 	// add a span context to trace the event handler
 	ctx, span := trace.SpanContext(ctx, "HandleOpenAccount")
 	defer span.End()
 
+	// get the context logger
+	logger := log.WithContext(ctx)
+
 	// let us make a copy of the command
 	commandCopy := proto.Clone(command).(*pb.OpenAccount)
 
+	// the account must be namespaced by its tenant
+	if tenantID := commandCopy.GetTenantId(); tenantID != "" && !tenant.Owns(tenantID, commandCopy.GetAccountId()) {
+		logger.Warn("the account is not namespaced by its tenant")
+		return nil, errCrossTenant(tenantID)
+	}
+
+	// accounts opened without a type are checking accounts
+	accountType := accounttypes.Resolve(commandCopy.GetAccountType())
+
+	// the opening balance must meet the minimum required by the account type
+	rules := accountRules.Lookup(accountType)
+	if commandCopy.GetOpeningBalance() < rules.MinOpeningBalance {
+		reason := "the opening balance is below the minimum required"
+		logger.Warn(reason)
+		return nil, errOpeningBalanceTooLow(accountType, rules.MinOpeningBalance)
+	}
+
 	return &pb.AccountOpened{
 		AccountId:    commandCopy.GetAccountId(),
 		Balance:      commandCopy.GetOpeningBalance(),
 		AccountOwner: commandCopy.GetAccountOwner(),
+		AccountType:  accountType,
+		TenantId:     commandCopy.GetTenantId(),
 	}, nil
 }
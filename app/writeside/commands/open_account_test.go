@@ -6,32 +6,87 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/tochemey/cos-go-sample/app/writeside/accounttypes"
 	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
 )
 
 func TestOpenAccount(t *testing.T) {
-	ctx := context.TODO()
-	accountID := "account-1"
-	amount := 50.00
-
-	// create the command
-	command := &pb.OpenAccount{
-		AccountId:      accountID,
-		OpeningBalance: amount,
-	}
-
-	// create the expected outcome
-	expected := &pb.AccountOpened{
-		AccountId: accountID,
-		Balance:   amount,
-	}
-
-	// perform the credit account command handling
-	actual, err := openAccount(ctx, command)
-	require.NoError(t, err)
-	require.NotNil(t, actual)
-	require.IsType(t, new(pb.AccountOpened), actual)
-	assert.True(t, proto.Equal(expected, actual))
+	t.Run("With happy path", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+		amount := 50.00
+
+		// create the command
+		command := &pb.OpenAccount{
+			AccountId:      accountID,
+			OpeningBalance: amount,
+		}
+
+		// create the expected outcome. Accounts opened without a type are checking accounts
+		expected := &pb.AccountOpened{
+			AccountId:   accountID,
+			Balance:     amount,
+			AccountType: pb.AccountType_CHECKING,
+		}
+
+		// perform the credit account command handling
+		actual, err := openAccount(ctx, command, nil)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		require.IsType(t, new(pb.AccountOpened), actual)
+		assert.True(t, proto.Equal(expected, actual))
+	})
+	t.Run("With account type", func(t *testing.T) {
+		ctx := context.TODO()
+
+		// create the command
+		command := &pb.OpenAccount{
+			AccountId:      "account-1",
+			OpeningBalance: 150,
+			AccountType:    pb.AccountType_SAVINGS,
+		}
+
+		// perform the open account command handling
+		actual, err := openAccount(ctx, command, accounttypes.NewRegistry())
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.Equal(t, pb.AccountType_SAVINGS, actual.GetAccountType())
+	})
+	t.Run("With opening balance below the minimum", func(t *testing.T) {
+		ctx := context.TODO()
+
+		// create the command
+		command := &pb.OpenAccount{
+			AccountId:      "account-1",
+			OpeningBalance: 50,
+			AccountType:    pb.AccountType_SAVINGS,
+		}
+
+		expectedErr := status.Error(codes.InvalidArgument, "the opening balance is below the minimum required for SAVINGS accounts (100.00)")
+		// perform the open account command handling
+		actual, err := openAccount(ctx, command, accounttypes.NewRegistry())
+		require.Error(t, err)
+		require.Nil(t, actual)
+		assert.EqualError(t, err, expectedErr.Error())
+	})
+	t.Run("With tenant", func(t *testing.T) {
+		ctx := context.TODO()
+
+		// the tenant is carried into the event
+		command := &pb.OpenAccount{AccountId: "acme/account-1", OpeningBalance: 50, TenantId: "acme"}
+		actual, err := openAccount(ctx, command, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "acme", actual.GetTenantId())
+
+		// the account must be namespaced by its tenant
+		command = &pb.OpenAccount{AccountId: "globex/account-1", OpeningBalance: 50, TenantId: "acme"}
+		actual, err = openAccount(ctx, command, nil)
+		assert.Nil(t, actual)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Equal(t, ReasonCrossTenant, ReasonOf(err))
+	})
 }
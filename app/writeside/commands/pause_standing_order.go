@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/tochemey/gopack/otel/trace"
+
+	"github.com/tochemey/cos-go-sample/app/log"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// pauseStandingOrder handles the Pause Standing Order command. When the command is valid the standing order paused event
+// is returned to be persisted. On the contrary a validation error is returned
+func pauseStandingOrder(ctx context.Context, command *pb.PauseStandingOrder, priorState *pb.StandingOrder) (*pb.StandingOrderPaused, error) {
+	// add a span context to trace the event handler
+	ctx, span := trace.SpanContext(ctx, "HandlePauseStandingOrder")
+	defer span.End()
+
+	// get the context logger
+	logger := log.WithContext(ctx)
+
+	// check the prior state
+	if err := checkStandingOrder(ctx, command.GetOrderId(), priorState); err != nil {
+		return nil, err
+	}
+
+	// check the standing order status
+	if priorState.GetStatus() != pb.StandingOrderStatus_ACTIVE {
+		logger.Warnf("the standing order:(%s) cannot be paused", command.GetOrderId())
+		return nil, errInvalidStandingOrderStatus(priorState.GetStatus())
+	}
+
+	// create the standing order paused event to persist into the data store
+	return &pb.StandingOrderPaused{OrderId: command.GetOrderId()}, nil
+}
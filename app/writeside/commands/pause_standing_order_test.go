@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestPauseStandingOrder(t *testing.T) {
+	t.Run("With happy path", func(t *testing.T) {
+		ctx := context.TODO()
+		priorState := &pb.StandingOrder{OrderId: "order-1", Status: pb.StandingOrderStatus_ACTIVE}
+
+		// perform the pause standing order command handling
+		actual, err := pauseStandingOrder(ctx, &pb.PauseStandingOrder{OrderId: "order-1"}, priorState)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(&pb.StandingOrderPaused{OrderId: "order-1"}, actual))
+	})
+	t.Run("With prior state not defined", func(t *testing.T) {
+		ctx := context.TODO()
+
+		// perform the pause standing order command handling
+		actual, err := pauseStandingOrder(ctx, &pb.PauseStandingOrder{OrderId: "order-1"}, nil)
+		assert.EqualError(t, err, errMissingPriorState.Error())
+		assert.Nil(t, actual)
+	})
+	t.Run("With command sent to the wrong standing order", func(t *testing.T) {
+		ctx := context.TODO()
+		priorState := &pb.StandingOrder{OrderId: "order-2", Status: pb.StandingOrderStatus_ACTIVE}
+
+		// perform the pause standing order command handling
+		actual, err := pauseStandingOrder(ctx, &pb.PauseStandingOrder{OrderId: "order-1"}, priorState)
+		assert.EqualError(t, err, errCommandSentToWrongEntity.Error())
+		assert.Nil(t, actual)
+	})
+	t.Run("With standing order already paused", func(t *testing.T) {
+		ctx := context.TODO()
+		priorState := &pb.StandingOrder{OrderId: "order-1", Status: pb.StandingOrderStatus_PAUSED}
+
+		// perform the pause standing order command handling
+		actual, err := pauseStandingOrder(ctx, &pb.PauseStandingOrder{OrderId: "order-1"}, priorState)
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = the standing order is PAUSED")
+		assert.Nil(t, actual)
+	})
+}
@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	"github.com/tochemey/gopack/otel/trace"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/tochemey/cos-go-sample/app/log"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// recordStandingOrderExecution handles the Record Standing Order Execution command. When the execution succeeded the standing order
+// executed event is returned to be persisted, otherwise the standing order execution failed event is returned.
+// Recording an execution date that is already recorded is a no-op. On the contrary a validation error is returned
+func recordStandingOrderExecution(ctx context.Context, command *pb.RecordStandingOrderExecution, priorState *pb.StandingOrder) (proto.Message, error) {
+	// add a span context to trace the event handler
+	ctx, span := trace.SpanContext(ctx, "HandleRecordStandingOrderExecution")
+	defer span.End()
+
+	// get the context logger
+	logger := log.WithContext(ctx)
+
+	// check the prior state
+	if err := checkStandingOrder(ctx, command.GetOrderId(), priorState); err != nil {
+		return nil, err
+	}
+
+	// let us make a copy of the command and the prior state
+	commandCopy := proto.Clone(command).(*pb.RecordStandingOrderExecution)
+	priorStateCopy := proto.Clone(priorState).(*pb.StandingOrder)
+
+	// executions are only recorded for running standing orders
+	orderStatus := priorStateCopy.GetStatus()
+	if orderStatus != pb.StandingOrderStatus_ACTIVE && orderStatus != pb.StandingOrderStatus_PAUSED {
+		logger.Warnf("the standing order:(%s) execution cannot be recorded", command.GetOrderId())
+		return nil, errInvalidStandingOrderStatus(orderStatus)
+	}
+
+	// the execution date is required
+	if commandCopy.GetExecutionDate() == nil {
+		logger.Warn("the execution date is not set")
+		return nil, errExecutionDateNotSet
+	}
+
+	executionDate := commandCopy.GetExecutionDate().AsTime()
+	nextExecutionDate := priorStateCopy.GetNextExecutionDate().AsTime()
+
+	// the execution has already been recorded, hence it is a no-op
+	if executionDate.Before(nextExecutionDate) {
+		logger.Infof("the standing order:(%s) execution of (%s) is already recorded", command.GetOrderId(), executionDate)
+		return nil, nil
+	}
+
+	// only the upcoming execution can be recorded
+	if executionDate.After(nextExecutionDate) {
+		logger.Warnf("the standing order:(%s) execution of (%s) is not due", command.GetOrderId(), executionDate)
+		return nil, errExecutionNotDue
+	}
+
+	// the execution succeeded
+	if commandCopy.GetFailureReason() == "" {
+		return &pb.StandingOrderExecuted{
+			OrderId:           commandCopy.GetOrderId(),
+			ExecutionDate:     commandCopy.GetExecutionDate(),
+			NextExecutionDate: nextExecution(priorStateCopy),
+		}, nil
+	}
+
+	// the failed execution is retried
+	if commandCopy.GetNextAttemptAt() != nil {
+		return &pb.StandingOrderExecutionFailed{
+			OrderId:       commandCopy.GetOrderId(),
+			ExecutionDate: commandCopy.GetExecutionDate(),
+			FailureReason: commandCopy.GetFailureReason(),
+			NextAttemptAt: commandCopy.GetNextAttemptAt(),
+			Debited:       commandCopy.GetDebited(),
+		}, nil
+	}
+
+	// the failed execution is skipped
+	return &pb.StandingOrderExecutionFailed{
+		OrderId:           commandCopy.GetOrderId(),
+		ExecutionDate:     commandCopy.GetExecutionDate(),
+		FailureReason:     commandCopy.GetFailureReason(),
+		NextExecutionDate: nextExecution(priorStateCopy),
+		Skipped:           true,
+	}, nil
+}
+
+// nextExecution returns the execution date following the upcoming execution of the given standing order.
+// It returns nil when the standing order ends before then
+func nextExecution(order *pb.StandingOrder) *timestamppb.Timestamp {
+	current := order.GetNextExecutionDate().AsTime()
+	var next time.Time
+	switch order.GetFrequency() {
+	case pb.Frequency_DAILY:
+		next = current.AddDate(0, 0, 1)
+	case pb.Frequency_WEEKLY:
+		next = current.AddDate(0, 0, 7)
+	case pb.Frequency_MONTHLY:
+		// the day of the month is taken from the start date rather than the previous execution, so that a short month
+		// does not shift the later executions. The orders created without start date fall on the day of their upcoming execution
+		day := current.Day()
+		if order.GetStartDate() != nil {
+			day = order.GetStartDate().AsTime().Day()
+		}
+		next = addMonth(current, day)
+	default:
+		return nil
+	}
+
+	// the standing order is completed
+	if order.GetEndDate() != nil && next.After(order.GetEndDate().AsTime()) {
+		return nil
+	}
+
+	return timestamppb.New(next)
+}
+
+// addMonth returns the date of the month following the given date on the given day of the month,
+// clamped to the last day of that month, e.g. February 29th for January 31st in a leap year
+func addMonth(date time.Time, day int) time.Time {
+	firstOfMonth := time.Date(date.Year(), date.Month()+1, 1, date.Hour(), date.Minute(), date.Second(), date.Nanosecond(), date.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	return firstOfMonth.AddDate(0, 0, min(day, lastDay)-1)
+}
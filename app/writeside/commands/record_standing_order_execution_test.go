@@ -0,0 +1,223 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestRecordStandingOrderExecution(t *testing.T) {
+	executionDate := time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)
+	// the next execution falls on the last day of February
+	nextExecutionDate := time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)
+
+	// newPriorState creates an active monthly standing order due at the execution date
+	newPriorState := func() *pb.StandingOrder {
+		return &pb.StandingOrder{
+			OrderId:           "order-1",
+			AccountId:         "account-1",
+			Amount:            50,
+			Frequency:         pb.Frequency_MONTHLY,
+			Status:            pb.StandingOrderStatus_ACTIVE,
+			NextExecutionDate: timestamppb.New(executionDate),
+		}
+	}
+
+	t.Run("With successful execution", func(t *testing.T) {
+		ctx := context.TODO()
+		command := &pb.RecordStandingOrderExecution{
+			OrderId:       "order-1",
+			ExecutionDate: timestamppb.New(executionDate),
+		}
+
+		// create the expected outcome
+		expected := &pb.StandingOrderExecuted{
+			OrderId:           "order-1",
+			ExecutionDate:     timestamppb.New(executionDate),
+			NextExecutionDate: timestamppb.New(nextExecutionDate),
+		}
+
+		// perform the record standing order execution command handling
+		actual, err := recordStandingOrderExecution(ctx, command, newPriorState())
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(expected, actual))
+	})
+	t.Run("With last execution", func(t *testing.T) {
+		ctx := context.TODO()
+		priorState := newPriorState()
+		priorState.EndDate = timestamppb.New(executionDate.AddDate(0, 0, 1))
+		command := &pb.RecordStandingOrderExecution{
+			OrderId:       "order-1",
+			ExecutionDate: timestamppb.New(executionDate),
+		}
+
+		// perform the record standing order execution command handling
+		actual, err := recordStandingOrderExecution(ctx, command, priorState)
+		require.NoError(t, err)
+		require.IsType(t, new(pb.StandingOrderExecuted), actual)
+		assert.Nil(t, actual.(*pb.StandingOrderExecuted).GetNextExecutionDate())
+	})
+	t.Run("With failed execution retried", func(t *testing.T) {
+		ctx := context.TODO()
+		nextAttemptAt := timestamppb.New(executionDate.Add(time.Hour))
+		command := &pb.RecordStandingOrderExecution{
+			OrderId:       "order-1",
+			ExecutionDate: timestamppb.New(executionDate),
+			FailureReason: "insufficient balance",
+			NextAttemptAt: nextAttemptAt,
+		}
+
+		// create the expected outcome
+		expected := &pb.StandingOrderExecutionFailed{
+			OrderId:       "order-1",
+			ExecutionDate: timestamppb.New(executionDate),
+			FailureReason: "insufficient balance",
+			NextAttemptAt: nextAttemptAt,
+		}
+
+		// perform the record standing order execution command handling
+		actual, err := recordStandingOrderExecution(ctx, command, newPriorState())
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(expected, actual))
+	})
+	t.Run("With failed execution retried after the debit", func(t *testing.T) {
+		ctx := context.TODO()
+		nextAttemptAt := timestamppb.New(executionDate.Add(time.Hour))
+		command := &pb.RecordStandingOrderExecution{
+			OrderId:       "order-1",
+			ExecutionDate: timestamppb.New(executionDate),
+			FailureReason: "the account is closed",
+			NextAttemptAt: nextAttemptAt,
+			Debited:       true,
+		}
+
+		// create the expected outcome
+		expected := &pb.StandingOrderExecutionFailed{
+			OrderId:       "order-1",
+			ExecutionDate: timestamppb.New(executionDate),
+			FailureReason: "the account is closed",
+			NextAttemptAt: nextAttemptAt,
+			Debited:       true,
+		}
+
+		// perform the record standing order execution command handling
+		actual, err := recordStandingOrderExecution(ctx, command, newPriorState())
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(expected, actual))
+	})
+	t.Run("With failed execution skipped", func(t *testing.T) {
+		ctx := context.TODO()
+		command := &pb.RecordStandingOrderExecution{
+			OrderId:       "order-1",
+			ExecutionDate: timestamppb.New(executionDate),
+			FailureReason: "insufficient balance",
+		}
+
+		// create the expected outcome
+		expected := &pb.StandingOrderExecutionFailed{
+			OrderId:           "order-1",
+			ExecutionDate:     timestamppb.New(executionDate),
+			FailureReason:     "insufficient balance",
+			NextExecutionDate: timestamppb.New(nextExecutionDate),
+			Skipped:           true,
+		}
+
+		// perform the record standing order execution command handling
+		actual, err := recordStandingOrderExecution(ctx, command, newPriorState())
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(expected, actual))
+	})
+	t.Run("With execution already recorded", func(t *testing.T) {
+		ctx := context.TODO()
+		command := &pb.RecordStandingOrderExecution{
+			OrderId:       "order-1",
+			ExecutionDate: timestamppb.New(executionDate.AddDate(0, -1, 0)),
+		}
+
+		// perform the record standing order execution command handling
+		actual, err := recordStandingOrderExecution(ctx, command, newPriorState())
+		require.NoError(t, err)
+		assert.Nil(t, actual)
+	})
+	t.Run("With execution not due", func(t *testing.T) {
+		ctx := context.TODO()
+		command := &pb.RecordStandingOrderExecution{
+			OrderId:       "order-1",
+			ExecutionDate: timestamppb.New(executionDate.AddDate(0, 1, 0)),
+		}
+
+		// perform the record standing order execution command handling
+		actual, err := recordStandingOrderExecution(ctx, command, newPriorState())
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = the standing order execution is not due")
+		assert.Nil(t, actual)
+	})
+	t.Run("With execution date not set", func(t *testing.T) {
+		ctx := context.TODO()
+		command := &pb.RecordStandingOrderExecution{OrderId: "order-1"}
+
+		// perform the record standing order execution command handling
+		actual, err := recordStandingOrderExecution(ctx, command, newPriorState())
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = the execution date is not set")
+		assert.Nil(t, actual)
+	})
+	t.Run("With cancelled standing order", func(t *testing.T) {
+		ctx := context.TODO()
+		priorState := newPriorState()
+		priorState.Status = pb.StandingOrderStatus_CANCELLED
+		command := &pb.RecordStandingOrderExecution{
+			OrderId:       "order-1",
+			ExecutionDate: timestamppb.New(executionDate),
+		}
+
+		// perform the record standing order execution command handling
+		actual, err := recordStandingOrderExecution(ctx, command, priorState)
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = the standing order is CANCELLED")
+		assert.Nil(t, actual)
+	})
+}
+
+func TestNextExecution(t *testing.T) {
+	current := time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)
+	testCases := map[pb.Frequency]*timestamppb.Timestamp{
+		pb.Frequency_DAILY:          timestamppb.New(current.AddDate(0, 0, 1)),
+		pb.Frequency_WEEKLY:         timestamppb.New(current.AddDate(0, 0, 7)),
+		pb.Frequency_MONTHLY:        timestamppb.New(time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)),
+		pb.Frequency_FREQUENCY_NONE: nil,
+	}
+
+	for frequency, expected := range testCases {
+		order := &pb.StandingOrder{Frequency: frequency, NextExecutionDate: timestamppb.New(current)}
+		assert.True(t, proto.Equal(expected, nextExecution(order)), frequency.String())
+	}
+
+	t.Run("With monthly order at the end of the month", func(t *testing.T) {
+		startDate := time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC)
+		order := &pb.StandingOrder{Frequency: pb.Frequency_MONTHLY, StartDate: timestamppb.New(startDate), NextExecutionDate: timestamppb.New(startDate)}
+
+		// the executions keep falling on the last day of the month after a short month
+		var actual []time.Time
+		for range 4 {
+			order.NextExecutionDate = nextExecution(order)
+			actual = append(actual, order.GetNextExecutionDate().AsTime())
+		}
+		expected := []time.Time{
+			time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC),
+			time.Date(2024, time.March, 31, 9, 0, 0, 0, time.UTC),
+			time.Date(2024, time.April, 30, 9, 0, 0, 0, time.UTC),
+			time.Date(2024, time.May, 31, 9, 0, 0, 0, time.UTC),
+		}
+		assert.Equal(t, expected, actual)
+	})
+	t.Run("With monthly order in the middle of the month", func(t *testing.T) {
+		startDate := time.Date(2024, time.January, 15, 0, 0, 0, 0, time.UTC)
+		order := &pb.StandingOrder{Frequency: pb.Frequency_MONTHLY, StartDate: timestamppb.New(startDate), NextExecutionDate: timestamppb.New(startDate)}
+		assert.Equal(t, time.Date(2024, time.February, 15, 0, 0, 0, 0, time.UTC), nextExecution(order).AsTime())
+	})
+}
@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestResumeStandingOrder(t *testing.T) {
+	t.Run("With happy path", func(t *testing.T) {
+		ctx := context.TODO()
+		priorState := &pb.StandingOrder{OrderId: "order-1", Status: pb.StandingOrderStatus_PAUSED}
+
+		// perform the resume standing order command handling
+		actual, err := resumeStandingOrder(ctx, &pb.ResumeStandingOrder{OrderId: "order-1"}, priorState)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(&pb.StandingOrderResumed{OrderId: "order-1"}, actual))
+	})
+	t.Run("With prior state not defined", func(t *testing.T) {
+		ctx := context.TODO()
+
+		// perform the resume standing order command handling
+		actual, err := resumeStandingOrder(ctx, &pb.ResumeStandingOrder{OrderId: "order-1"}, nil)
+		assert.EqualError(t, err, errMissingPriorState.Error())
+		assert.Nil(t, actual)
+	})
+	t.Run("With standing order not paused", func(t *testing.T) {
+		ctx := context.TODO()
+		priorState := &pb.StandingOrder{OrderId: "order-1", Status: pb.StandingOrderStatus_ACTIVE}
+
+		// perform the resume standing order command handling
+		actual, err := resumeStandingOrder(ctx, &pb.ResumeStandingOrder{OrderId: "order-1"}, priorState)
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = the standing order is ACTIVE")
+		assert.Nil(t, actual)
+	})
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/tochemey/cos-go-sample/app/log"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
+)
+
+// StandingOrderDispatcher dispatches the standing order commands
+type StandingOrderDispatcher interface {
+	// Dispatch dispatches the given command and return the appropriate event or an error
+	Dispatch(ctx context.Context, command proto.Message, priorState *pb.StandingOrder, priorMeta *cospb.MetaData) (event proto.Message, err error)
+}
+
+type standingOrderDispatcher struct{}
+
+var _ StandingOrderDispatcher = (*standingOrderDispatcher)(nil)
+
+// NewStandingOrderDispatcher create an instance of StandingOrderDispatcher
+func NewStandingOrderDispatcher() StandingOrderDispatcher {
+	return &standingOrderDispatcher{}
+}
+
+// Dispatch dispatches the given command and return the appropriate event or an error
+func (h standingOrderDispatcher) Dispatch(ctx context.Context, command proto.Message, priorState *pb.StandingOrder, priorMeta *cospb.MetaData) (event proto.Message, err error) { //nolint
+	switch typedCmd := command.(type) {
+	case *pb.CreateStandingOrder:
+		return createStandingOrder(ctx, typedCmd, priorState)
+	case *pb.PauseStandingOrder:
+		return pauseStandingOrder(ctx, typedCmd, priorState)
+	case *pb.ResumeStandingOrder:
+		return resumeStandingOrder(ctx, typedCmd, priorState)
+	case *pb.CancelStandingOrder:
+		return cancelStandingOrder(ctx, typedCmd, priorState)
+	case *pb.RecordStandingOrderExecution:
+		return recordStandingOrderExecution(ctx, typedCmd, priorState)
+	case nil:
+		return nil, errCommandNotDefined
+	default:
+		return nil, errUnhandledCommand(typedCmd)
+	}
+}
+
+// IsStandingOrderCommand checks whether the given command targets a standing order
+func IsStandingOrderCommand(command proto.Message) bool {
+	switch command.(type) {
+	case *pb.CreateStandingOrder,
+		*pb.PauseStandingOrder,
+		*pb.ResumeStandingOrder,
+		*pb.CancelStandingOrder,
+		*pb.RecordStandingOrderExecution:
+		return true
+	default:
+		return false
+	}
+}
+
+// checkStandingOrder verifies that the prior standing order is defined and targeted by the command
+func checkStandingOrder(ctx context.Context, orderID string, priorState *pb.StandingOrder) error {
+	// get the context logger
+	logger := log.WithContext(ctx)
+
+	// check whether the prior state is defined or not
+	if priorState == nil || proto.Equal(priorState, new(pb.StandingOrder)) {
+		// log a message for debugging purpose
+		logger.Error("the prior state is not defined")
+		// return an error of missing prior state
+		return errMissingPriorState
+	}
+
+	// let us verify that the command is sent to right aggregate.
+	// this scenario with never occur but sanity check requires such verification
+	if orderID != priorState.GetOrderId() {
+		logger.Errorf("the standing order state:(%s) is not found", orderID)
+		return errCommandSentToWrongEntity
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestStandingOrderDispatcher(t *testing.T) {
+	priorState := &pb.StandingOrder{
+		OrderId:           "order-1",
+		Status:            pb.StandingOrderStatus_ACTIVE,
+		Frequency:         pb.Frequency_DAILY,
+		NextExecutionDate: timestamppb.New(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)),
+	}
+
+	t.Run("with nil command", func(t *testing.T) {
+		dispatcher := NewStandingOrderDispatcher()
+		event, err := dispatcher.Dispatch(context.TODO(), nil, priorState, nil)
+		assert.EqualError(t, err, errCommandNotDefined.Error())
+		assert.Nil(t, event)
+	})
+	t.Run("with unknown command", func(t *testing.T) {
+		dispatcher := NewStandingOrderDispatcher()
+		event, err := dispatcher.Dispatch(context.TODO(), wrapperspb.String("unknown"), priorState, nil)
+		assert.Error(t, err)
+		assert.Nil(t, event)
+	})
+	t.Run("With CreateStandingOrder command", func(t *testing.T) {
+		dispatcher := NewStandingOrderDispatcher()
+		command := &pb.CreateStandingOrder{
+			OrderId:              "order-2",
+			AccountId:            "account-1",
+			BeneficiaryAccountId: "account-2",
+			Amount:               10,
+			Frequency:            pb.Frequency_DAILY,
+			StartDate:            priorState.GetNextExecutionDate(),
+		}
+		event, err := dispatcher.Dispatch(context.TODO(), command, nil, nil)
+		require.NoError(t, err)
+		assert.IsType(t, new(pb.StandingOrderCreated), event)
+	})
+	t.Run("With PauseStandingOrder command", func(t *testing.T) {
+		dispatcher := NewStandingOrderDispatcher()
+		event, err := dispatcher.Dispatch(context.TODO(), &pb.PauseStandingOrder{OrderId: "order-1"}, priorState, nil)
+		require.NoError(t, err)
+		assert.IsType(t, new(pb.StandingOrderPaused), event)
+	})
+	t.Run("With CancelStandingOrder command", func(t *testing.T) {
+		dispatcher := NewStandingOrderDispatcher()
+		event, err := dispatcher.Dispatch(context.TODO(), &pb.CancelStandingOrder{OrderId: "order-1"}, priorState, nil)
+		require.NoError(t, err)
+		assert.IsType(t, new(pb.StandingOrderCancelled), event)
+	})
+	t.Run("With RecordStandingOrderExecution command", func(t *testing.T) {
+		dispatcher := NewStandingOrderDispatcher()
+		command := &pb.RecordStandingOrderExecution{OrderId: "order-1", ExecutionDate: priorState.GetNextExecutionDate()}
+		event, err := dispatcher.Dispatch(context.TODO(), command, priorState, nil)
+		require.NoError(t, err)
+		assert.IsType(t, new(pb.StandingOrderExecuted), event)
+	})
+}
+
+func TestIsStandingOrderCommand(t *testing.T) {
+	assert.True(t, IsStandingOrderCommand(new(pb.CreateStandingOrder)))
+	assert.True(t, IsStandingOrderCommand(new(pb.RecordStandingOrderExecution)))
+	assert.False(t, IsStandingOrderCommand(new(pb.DebitAccount)))
+	assert.False(t, IsStandingOrderCommand(nil))
+}
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"context"
+	"math"
+
+	"github.com/tochemey/gopack/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/tochemey/cos-go-sample/app/log"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// waiveFee handles the Waive Fee command. When the command is valid the fee waived event is returned
+// to be persisted. On the contrary a validation error is returned
+func waiveFee(ctx context.Context, command *pb.WaiveFee, priorState *pb.BankAccount) (*pb.FeeWaived, error) {
+	// add a span context to trace the event handler
+	ctx, span := trace.SpanContext(ctx, "HandleWaiveFee")
+	defer span.End()
+
+	// get the context logger
+	logger := log.WithContext(ctx)
+
+	// let us make a copy of the command and the prior state
+	commandCopy := proto.Clone(command).(*pb.WaiveFee)
+	priorStateCopy := proto.Clone(priorState).(*pb.BankAccount)
+
+	// check whether the prior state is defined or not
+	if priorStateCopy == nil || proto.Equal(priorStateCopy, new(pb.BankAccount)) {
+		// log a message for debugging purpose
+		logger.Error("the prior state is not defined")
+		// return an error of missing prior state
+		return nil, errMissingPriorState
+	}
+
+	// let us verify that the command is sent to right aggregate.
+	// this scenario with never occur but sanity check requires such verification
+	if command.GetAccountId() != priorState.GetAccountId() {
+		logger.Errorf("the account state:(%s) is not found", command.GetAccountId())
+		return nil, errCommandSentToWrongEntity
+	}
+
+	// return a validation error when the amount to waive is not positive
+	if commandCopy.GetAmount() <= 0 {
+		logger.Warn("invalid waiver amount")
+		return nil, errInvalidWaiverAmount
+	}
+
+	// only the fees charged and not yet waived can be waived
+	outstandingFees := math.Round((priorStateCopy.GetTotalFeesCharged()-priorStateCopy.GetTotalFeesWaived())*100) / 100
+	if commandCopy.GetAmount() > outstandingFees {
+		logger.Warn("waiver amount exceeds the outstanding fees")
+		return nil, errWaiverExceedsFees(outstandingFees)
+	}
+
+	// create the fee waived event to persist into the data store
+	return &pb.FeeWaived{
+		AccountId: commandCopy.GetAccountId(),
+		Amount:    commandCopy.GetAmount(),
+		Reason:    commandCopy.GetReason(),
+	}, nil
+}
@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestWaiveFee(t *testing.T) {
+	t.Run("With happy path", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+		accountBal := 150.55
+		accountOwner := "John Doe"
+		amount := 2.00
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:        accountID,
+			AccountBalance:   accountBal,
+			AccountOwner:     accountOwner,
+			TotalFeesCharged: 3.00,
+			TotalFeesWaived:  1.00,
+		}
+
+		// create the command
+		command := &pb.WaiveFee{
+			AccountId: accountID,
+			Amount:    amount,
+			Reason:    "goodwill",
+		}
+
+		// create the expected outcome
+		expected := &pb.FeeWaived{
+			AccountId: accountID,
+			Amount:    amount,
+			Reason:    "goodwill",
+		}
+
+		// perform the waive fee command handling
+		actual, err := waiveFee(ctx, command, priorState)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		require.IsType(t, new(pb.FeeWaived), actual)
+		assert.True(t, proto.Equal(expected, actual))
+	})
+	t.Run("With prior state not defined", func(t *testing.T) {
+		ctx := context.TODO()
+
+		// create the command
+		command := &pb.WaiveFee{
+			AccountId: "account-1",
+			Amount:    2.00,
+		}
+
+		// perform the waive fee command handling
+		actual, err := waiveFee(ctx, command, &pb.BankAccount{})
+		require.Error(t, err)
+		assert.EqualError(t, err, errMissingPriorState.Error())
+		require.Nil(t, actual)
+	})
+	t.Run("With mismatch account id in command and prior state", func(t *testing.T) {
+		ctx := context.TODO()
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId: "mismatch-1",
+		}
+
+		// create the command
+		command := &pb.WaiveFee{
+			AccountId: "account-1",
+			Amount:    2.00,
+		}
+
+		// perform the waive fee command handling
+		actual, err := waiveFee(ctx, command, priorState)
+		require.Error(t, err)
+		assert.EqualError(t, err, errCommandSentToWrongEntity.Error())
+		require.Nil(t, actual)
+	})
+	t.Run("With invalid amount", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:        accountID,
+			AccountBalance:   150.55,
+			TotalFeesCharged: 3.00,
+		}
+
+		// create the command
+		command := &pb.WaiveFee{
+			AccountId: accountID,
+			Amount:    -2.00,
+		}
+
+		expectedErr := status.Error(codes.InvalidArgument, "invalid waiver amount")
+		// perform the waive fee command handling
+		actual, err := waiveFee(ctx, command, priorState)
+		require.Error(t, err)
+		require.Nil(t, actual)
+		assert.EqualError(t, err, expectedErr.Error())
+	})
+	t.Run("With amount exceeding the outstanding fees", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:        accountID,
+			AccountBalance:   150.55,
+			TotalFeesCharged: 3.00,
+			TotalFeesWaived:  2.00,
+		}
+
+		// create the command
+		command := &pb.WaiveFee{
+			AccountId: accountID,
+			Amount:    2.00,
+		}
+
+		expectedErr := status.Error(codes.FailedPrecondition, "waiver amount exceeds the outstanding fees")
+		// perform the waive fee command handling
+		actual, err := waiveFee(ctx, command, priorState)
+		require.Error(t, err)
+		require.Nil(t, actual)
+		assert.EqualError(t, err, expectedErr.Error())
+		assert.Equal(t, ReasonWaiverExceedsFees, ReasonOf(err))
+	})
+}
@@ -19,6 +19,17 @@ func accountCredited(ctx context.Context, event *pb.AccountCredited, priorState
 	stateCopy := proto.Clone(priorState).(*pb.BankAccount)
 
 	stateCopy.AccountBalance = stateCopy.GetAccountBalance() + eventCopy.GetAmount()
+	stateCopy.ProcessedIdempotencyKeys = rememberIdempotencyKey(stateCopy.GetProcessedIdempotencyKeys(), eventCopy.GetIdempotencyKey())
+
+	// the customer activity reactivates the dormant account
+	if eventCopy.GetReactivated() {
+		stateCopy.IsDormant = false
+	}
+
+	// apply the fee charged with the credit
+	if eventCopy.GetFee() != nil {
+		return feeCharged(ctx, eventCopy.GetFee(), stateCopy)
+	}
 
 	return stateCopy, nil
 }
@@ -12,36 +12,82 @@ import (
 )
 
 func TestAccountCredited(t *testing.T) {
-	ctx := context.TODO()
-	accountID := "account-1"
-	accountBal := 150.55
-	accountOwner := "John Doe"
-	amount := 50.00
-
-	// create the prior state
-	priorState := &pb.BankAccount{
-		AccountId:      accountID,
-		AccountBalance: accountBal,
-		AccountOwner:   accountOwner,
-		IsClosed:       false,
-	}
-
-	// create the event
-	event := &pb.AccountCredited{
-		AccountId: accountID,
-		Amount:    amount,
-	}
-
-	expected := &pb.BankAccount{
-		AccountId:      accountID,
-		AccountBalance: accountBal + amount,
-		AccountOwner:   accountOwner,
-		IsClosed:       false,
-	}
-
-	actual, err := accountCredited(ctx, event, priorState)
-	require.NoError(t, err)
-	require.NotNil(t, actual)
-	require.IsType(t, new(pb.BankAccount), actual)
-	assert.True(t, proto.Equal(expected, actual))
+	t.Run("With happy path", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+		accountBal := 150.55
+		accountOwner := "John Doe"
+		amount := 50.00
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: accountBal,
+			AccountOwner:   accountOwner,
+			IsClosed:       false,
+		}
+
+		// create the event
+		event := &pb.AccountCredited{
+			AccountId: accountID,
+			Amount:    amount,
+		}
+
+		expected := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: accountBal + amount,
+			AccountOwner:   accountOwner,
+			IsClosed:       false,
+		}
+
+		actual, err := accountCredited(ctx, event, priorState)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		require.IsType(t, new(pb.BankAccount), actual)
+		assert.True(t, proto.Equal(expected, actual))
+	})
+	t.Run("With dormant account reactivated", func(t *testing.T) {
+		ctx := context.TODO()
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      "account-1",
+			AccountBalance: 150.55,
+			IsDormant:      true,
+		}
+
+		// create the event
+		event := &pb.AccountCredited{
+			AccountId:   "account-1",
+			Amount:      50.00,
+			Reactivated: true,
+		}
+
+		actual, err := accountCredited(ctx, event, priorState)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.False(t, actual.GetIsDormant())
+	})
+	t.Run("With dormant account credited by the system", func(t *testing.T) {
+		ctx := context.TODO()
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      "account-1",
+			AccountBalance: 150.55,
+			IsDormant:      true,
+		}
+
+		// create the event
+		event := &pb.AccountCredited{
+			AccountId:       "account-1",
+			Amount:          50.00,
+			SystemInitiated: true,
+		}
+
+		actual, err := accountCredited(ctx, event, priorState)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.True(t, actual.GetIsDormant())
+	})
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/tochemey/gopack/otel/trace"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/tochemey/cos-go-sample/app/writeside/accounttypes"
 	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
 )
 
@@ -20,6 +21,27 @@ func accountDebited(ctx context.Context, event *pb.AccountDebited, priorState *p
 
 	bal := stateCopy.GetAccountBalance() - eventCopy.GetAmount()
 	stateCopy.AccountBalance = bal
+	stateCopy.ProcessedIdempotencyKeys = rememberIdempotencyKey(stateCopy.GetProcessedIdempotencyKeys(), eventCopy.GetIdempotencyKey())
+
+	// the customer activity reactivates the dormant account
+	if eventCopy.GetReactivated() {
+		stateCopy.IsDormant = false
+	}
+
+	// count the debit against the monthly withdrawals. The count restarts every month
+	if eventCopy.GetDebitedAt() != nil {
+		month := accounttypes.WithdrawalsMonth(eventCopy.GetDebitedAt().AsTime())
+		if stateCopy.GetWithdrawalsMonth() != month {
+			stateCopy.WithdrawalsMonth = month
+			stateCopy.MonthlyWithdrawals = 0
+		}
+		stateCopy.MonthlyWithdrawals++
+	}
+
+	// apply the fee charged with the debit
+	if eventCopy.GetFee() != nil {
+		return feeCharged(ctx, eventCopy.GetFee(), stateCopy)
+	}
 
 	return stateCopy, nil
 }
@@ -3,45 +3,121 @@ package events
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
 )
 
 func TestAccountDebited(t *testing.T) {
-	ctx := context.TODO()
-	accountID := "account-1"
-	accountBal := 150.55
-	accountOwner := "John Doe"
-	amount := 50.00
-
-	// create the prior state
-	priorState := &pb.BankAccount{
-		AccountId:      accountID,
-		AccountBalance: accountBal,
-		AccountOwner:   accountOwner,
-		IsClosed:       false,
-	}
-
-	// create the event
-	event := &pb.AccountDebited{
-		AccountId: accountID,
-		Amount:    amount,
-	}
-
-	expected := &pb.BankAccount{
-		AccountId:      accountID,
-		AccountBalance: accountBal - amount,
-		AccountOwner:   accountOwner,
-		IsClosed:       false,
-	}
-
-	actual, err := accountDebited(ctx, event, priorState)
-	require.NoError(t, err)
-	require.NotNil(t, actual)
-	require.IsType(t, new(pb.BankAccount), actual)
-	assert.True(t, proto.Equal(expected, actual))
+	t.Run("With happy path", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+		accountBal := 150.55
+		accountOwner := "John Doe"
+		amount := 50.00
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: accountBal,
+			AccountOwner:   accountOwner,
+			IsClosed:       false,
+		}
+
+		// create the event
+		event := &pb.AccountDebited{
+			AccountId: accountID,
+			Amount:    amount,
+		}
+
+		expected := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: accountBal - amount,
+			AccountOwner:   accountOwner,
+			IsClosed:       false,
+		}
+
+		actual, err := accountDebited(ctx, event, priorState)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		require.IsType(t, new(pb.BankAccount), actual)
+		assert.True(t, proto.Equal(expected, actual))
+	})
+	t.Run("With withdrawal in the same month", func(t *testing.T) {
+		ctx := context.TODO()
+		debitedAt := time.Date(2024, time.January, 15, 10, 0, 0, 0, time.UTC)
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:          "account-1",
+			AccountBalance:     150.55,
+			MonthlyWithdrawals: 2,
+			WithdrawalsMonth:   "2024-01",
+		}
+
+		// create the event
+		event := &pb.AccountDebited{
+			AccountId: "account-1",
+			Amount:    50.00,
+			DebitedAt: timestamppb.New(debitedAt),
+		}
+
+		actual, err := accountDebited(ctx, event, priorState)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.EqualValues(t, 3, actual.GetMonthlyWithdrawals())
+		assert.Equal(t, "2024-01", actual.GetWithdrawalsMonth())
+	})
+	t.Run("With withdrawal in a new month", func(t *testing.T) {
+		ctx := context.TODO()
+		debitedAt := time.Date(2024, time.February, 1, 10, 0, 0, 0, time.UTC)
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:          "account-1",
+			AccountBalance:     150.55,
+			MonthlyWithdrawals: 6,
+			WithdrawalsMonth:   "2024-01",
+		}
+
+		// create the event
+		event := &pb.AccountDebited{
+			AccountId: "account-1",
+			Amount:    50.00,
+			DebitedAt: timestamppb.New(debitedAt),
+		}
+
+		actual, err := accountDebited(ctx, event, priorState)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.EqualValues(t, 1, actual.GetMonthlyWithdrawals())
+		assert.Equal(t, "2024-02", actual.GetWithdrawalsMonth())
+	})
+	t.Run("With dormant account reactivated", func(t *testing.T) {
+		ctx := context.TODO()
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      "account-1",
+			AccountBalance: 150.55,
+			IsDormant:      true,
+		}
+
+		// create the event
+		event := &pb.AccountDebited{
+			AccountId:   "account-1",
+			Amount:      50.00,
+			Reactivated: true,
+		}
+
+		actual, err := accountDebited(ctx, event, priorState)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.False(t, actual.GetIsDormant())
+	})
 }
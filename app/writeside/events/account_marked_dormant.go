@@ -0,0 +1,22 @@
+package events
+
+import (
+	"context"
+
+	"github.com/tochemey/gopack/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// accountMarkedDormant handles the account marked dormant event and return the resulting state
+func accountMarkedDormant(ctx context.Context, _ *pb.AccountMarkedDormant, priorState *pb.BankAccount) (*pb.BankAccount, error) {
+	// add a span context to trace the event handler
+	ctx, span := trace.SpanContext(ctx, "HandleAccountMarkedDormant")
+	defer span.End()
+
+	stateCopy := proto.Clone(priorState).(*pb.BankAccount)
+	stateCopy.IsDormant = true
+
+	return stateCopy, nil
+}
@@ -0,0 +1,44 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestAccountMarkedDormant(t *testing.T) {
+	ctx := context.TODO()
+	accountID := "account-1"
+
+	// create the prior state
+	priorState := &pb.BankAccount{
+		AccountId:      accountID,
+		AccountBalance: 150.55,
+		AccountOwner:   "John Doe",
+	}
+
+	// create the event
+	event := &pb.AccountMarkedDormant{
+		AccountId:      accountID,
+		LastActivityAt: timestamppb.Now(),
+	}
+
+	expected := &pb.BankAccount{
+		AccountId:      accountID,
+		AccountBalance: 150.55,
+		AccountOwner:   "John Doe",
+		IsDormant:      true,
+	}
+
+	actual, err := accountMarkedDormant(ctx, event, priorState)
+	require.NoError(t, err)
+	require.NotNil(t, actual)
+	require.IsType(t, new(pb.BankAccount), actual)
+	assert.True(t, proto.Equal(expected, actual))
+}
@@ -24,5 +24,7 @@ func accountOpened(ctx context.Context, event *pb.AccountOpened) (*pb.BankAccoun
 		AccountBalance: eventCopy.GetBalance(),
 		AccountOwner:   eventCopy.GetAccountOwner(),
 		IsClosed:       false,
+		AccountType:    eventCopy.GetAccountType(),
+		TenantId:       eventCopy.GetTenantId(),
 	}, nil
 }
@@ -23,6 +23,8 @@ func TestAccountOpened(t *testing.T) {
 		AccountId:    accountID,
 		Balance:      amount,
 		AccountOwner: accountOwner,
+		AccountType:  pb.AccountType_SAVINGS,
+		TenantId:     "acme",
 	}
 
 	expected := &pb.BankAccount{
@@ -30,6 +32,8 @@ func TestAccountOpened(t *testing.T) {
 		AccountBalance: accountBal,
 		AccountOwner:   accountOwner,
 		IsClosed:       false,
+		AccountType:    pb.AccountType_SAVINGS,
+		TenantId:       "acme",
 	}
 
 	actual, err := accountOpened(ctx, event)
@@ -4,17 +4,19 @@ import (
 	"context"
 
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/tochemey/cos-go-sample/app/writeside/commands"
 	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
 	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
 )
 
 var (
-	errEventNotDefined = status.Error(codes.Internal, "the event is not defined")
+	errEventNotDefined = commands.NewError(codes.Internal, commands.ReasonEventNotDefined, nil, "the event is not defined")
 	errUnhandledEvent  = func(event proto.Message) error {
-		return status.Errorf(codes.Internal, "received unhandled command (%s)", event.ProtoReflect().Descriptor().FullName())
+		eventType := string(event.ProtoReflect().Descriptor().FullName())
+		return commands.NewError(codes.Internal, commands.ReasonUnhandledEvent, map[string]string{"event_type": eventType},
+			"received unhandled event (%s)", eventType)
 	}
 )
 
@@ -41,6 +43,12 @@ func (h dispatcher) Dispatch(ctx context.Context, event proto.Message, priorStat
 		return accountCredited(ctx, typedEvent, priorState)
 	case *pb.AccountDebited:
 		return accountDebited(ctx, typedEvent, priorState)
+	case *pb.FeeCharged:
+		return feeCharged(ctx, typedEvent, priorState)
+	case *pb.FeeWaived:
+		return feeWaived(ctx, typedEvent, priorState)
+	case *pb.AccountMarkedDormant:
+		return accountMarkedDormant(ctx, typedEvent, priorState)
 	case nil:
 		return nil, errEventNotDefined
 	default:
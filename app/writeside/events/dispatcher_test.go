@@ -9,6 +9,7 @@ import (
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
 
+	"github.com/tochemey/cos-go-sample/app/writeside/commands"
 	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
 	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
 )
@@ -33,6 +34,7 @@ func TestDispatch(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, actual)
 		assert.EqualError(t, err, errEventNotDefined.Error())
+		assert.Equal(t, commands.ReasonEventNotDefined, commands.ReasonOf(err))
 	})
 	t.Run("with unknown event", func(t *testing.T) {
 		// define a context
@@ -45,6 +47,8 @@ func TestDispatch(t *testing.T) {
 		actual, err := NewDispatcher().Dispatch(ctx, event, priorState, cosMeta)
 		assert.Error(t, err)
 		assert.EqualError(t, err, errUnhandledEvent(event).Error())
+		assert.Equal(t, commands.ReasonUnhandledEvent, commands.ReasonOf(err))
+		assert.Contains(t, err.Error(), "unhandled event (google.protobuf.Empty)")
 		assert.Nil(t, actual)
 	})
 	t.Run("With AccountOpened event", func(t *testing.T) {
@@ -166,4 +170,38 @@ func TestDispatch(t *testing.T) {
 		require.IsType(t, new(pb.BankAccount), actual)
 		assert.True(t, proto.Equal(expected, actual))
 	})
+	t.Run("With AccountMarkedDormant event", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 150.55,
+			AccountOwner:   "John Doe",
+		}
+
+		// create the event
+		event := &pb.AccountMarkedDormant{AccountId: accountID}
+
+		expected := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: 150.55,
+			AccountOwner:   "John Doe",
+			IsDormant:      true,
+		}
+
+		// create the cos prior meta
+		priorMeta := &cospb.MetaData{EntityId: accountID}
+
+		// create the instance of the dispatcher
+		dispatcher := NewDispatcher()
+
+		// perform the account marked dormant event handling
+		actual, err := dispatcher.Dispatch(ctx, event, priorState, priorMeta)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		require.IsType(t, new(pb.BankAccount), actual)
+		assert.True(t, proto.Equal(expected, actual))
+	})
 }
@@ -0,0 +1,25 @@
+package events
+
+import (
+	"context"
+
+	"github.com/tochemey/gopack/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// feeCharged handles the fee charged event and return the resulting state
+func feeCharged(ctx context.Context, event *pb.FeeCharged, priorState *pb.BankAccount) (*pb.BankAccount, error) {
+	// add a span context to trace the event handler
+	ctx, span := trace.SpanContext(ctx, "HandleFeeCharged")
+	defer span.End()
+
+	eventCopy := proto.Clone(event).(*pb.FeeCharged)
+	stateCopy := proto.Clone(priorState).(*pb.BankAccount)
+
+	stateCopy.AccountBalance = stateCopy.GetAccountBalance() - eventCopy.GetAmount()
+	stateCopy.TotalFeesCharged = stateCopy.GetTotalFeesCharged() + eventCopy.GetAmount()
+
+	return stateCopy, nil
+}
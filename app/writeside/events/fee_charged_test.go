@@ -0,0 +1,92 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestFeeCharged(t *testing.T) {
+	t.Run("With standalone event", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+		accountBal := 150.55
+		accountOwner := "John Doe"
+		fee := 1.50
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:        accountID,
+			AccountBalance:   accountBal,
+			AccountOwner:     accountOwner,
+			TotalFeesCharged: 2.00,
+		}
+
+		// create the event
+		event := &pb.FeeCharged{
+			AccountId:   accountID,
+			FeeId:       "fee-1",
+			RuleId:      "rule-1",
+			CommandType: "accounts.v1.DebitAccount",
+			Amount:      fee,
+		}
+
+		expected := &pb.BankAccount{
+			AccountId:        accountID,
+			AccountBalance:   accountBal - fee,
+			AccountOwner:     accountOwner,
+			TotalFeesCharged: 2.00 + fee,
+		}
+
+		actual, err := feeCharged(ctx, event, priorState)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		require.IsType(t, new(pb.BankAccount), actual)
+		assert.True(t, proto.Equal(expected, actual))
+	})
+	t.Run("With fee charged on a debit", func(t *testing.T) {
+		ctx := context.TODO()
+		accountID := "account-1"
+		accountBal := 150.55
+		accountOwner := "John Doe"
+		amount := 50.00
+		fee := 0.50
+
+		// create the prior state
+		priorState := &pb.BankAccount{
+			AccountId:      accountID,
+			AccountBalance: accountBal,
+			AccountOwner:   accountOwner,
+		}
+
+		// create the event
+		event := &pb.AccountDebited{
+			AccountId: accountID,
+			Amount:    amount,
+			Fee: &pb.FeeCharged{
+				AccountId:   accountID,
+				FeeId:       "fee-1",
+				RuleId:      "rule-1",
+				CommandType: "accounts.v1.DebitAccount",
+				Amount:      fee,
+			},
+		}
+
+		expected := &pb.BankAccount{
+			AccountId:        accountID,
+			AccountBalance:   accountBal - amount - fee,
+			AccountOwner:     accountOwner,
+			TotalFeesCharged: fee,
+		}
+
+		actual, err := accountDebited(ctx, event, priorState)
+		require.NoError(t, err)
+		require.NotNil(t, actual)
+		assert.True(t, proto.Equal(expected, actual))
+	})
+}
@@ -0,0 +1,25 @@
+package events
+
+import (
+	"context"
+
+	"github.com/tochemey/gopack/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// feeWaived handles the fee waived event and return the resulting state
+func feeWaived(ctx context.Context, event *pb.FeeWaived, priorState *pb.BankAccount) (*pb.BankAccount, error) {
+	// add a span context to trace the event handler
+	ctx, span := trace.SpanContext(ctx, "HandleFeeWaived")
+	defer span.End()
+
+	eventCopy := proto.Clone(event).(*pb.FeeWaived)
+	stateCopy := proto.Clone(priorState).(*pb.BankAccount)
+
+	stateCopy.AccountBalance = stateCopy.GetAccountBalance() + eventCopy.GetAmount()
+	stateCopy.TotalFeesWaived = stateCopy.GetTotalFeesWaived() + eventCopy.GetAmount()
+
+	return stateCopy, nil
+}
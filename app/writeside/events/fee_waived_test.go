@@ -0,0 +1,49 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestFeeWaived(t *testing.T) {
+	ctx := context.TODO()
+	accountID := "account-1"
+	accountBal := 150.55
+	accountOwner := "John Doe"
+	amount := 1.50
+
+	// create the prior state
+	priorState := &pb.BankAccount{
+		AccountId:        accountID,
+		AccountBalance:   accountBal,
+		AccountOwner:     accountOwner,
+		TotalFeesCharged: 3.00,
+	}
+
+	// create the event
+	event := &pb.FeeWaived{
+		AccountId: accountID,
+		Amount:    amount,
+		Reason:    "goodwill",
+	}
+
+	expected := &pb.BankAccount{
+		AccountId:        accountID,
+		AccountBalance:   accountBal + amount,
+		AccountOwner:     accountOwner,
+		TotalFeesCharged: 3.00,
+		TotalFeesWaived:  amount,
+	}
+
+	actual, err := feeWaived(ctx, event, priorState)
+	require.NoError(t, err)
+	require.NotNil(t, actual)
+	require.IsType(t, new(pb.BankAccount), actual)
+	assert.True(t, proto.Equal(expected, actual))
+}
@@ -0,0 +1,21 @@
+package events
+
+// maxProcessedIdempotencyKeys is the number of idempotency keys kept in the account state.
+// Older keys are dropped so that the state does not grow unbounded
+const maxProcessedIdempotencyKeys = 100
+
+// rememberIdempotencyKey appends the given idempotency key to the processed keys and
+// drops the oldest keys beyond maxProcessedIdempotencyKeys
+func rememberIdempotencyKey(processedKeys []string, key string) []string {
+	// nothing to remember
+	if key == "" {
+		return processedKeys
+	}
+
+	processedKeys = append(processedKeys, key)
+	if len(processedKeys) > maxProcessedIdempotencyKeys {
+		processedKeys = processedKeys[len(processedKeys)-maxProcessedIdempotencyKeys:]
+	}
+
+	return processedKeys
+}
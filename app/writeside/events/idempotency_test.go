@@ -0,0 +1,30 @@
+package events
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRememberIdempotencyKey(t *testing.T) {
+	t.Run("With empty key", func(t *testing.T) {
+		processedKeys := []string{"key-1"}
+		assert.Equal(t, processedKeys, rememberIdempotencyKey(processedKeys, ""))
+	})
+	t.Run("With new key", func(t *testing.T) {
+		processedKeys := []string{"key-1"}
+		assert.Equal(t, []string{"key-1", "key-2"}, rememberIdempotencyKey(processedKeys, "key-2"))
+	})
+	t.Run("With keys beyond the limit", func(t *testing.T) {
+		var processedKeys []string
+		for i := 0; i < maxProcessedIdempotencyKeys; i++ {
+			processedKeys = append(processedKeys, fmt.Sprintf("key-%d", i))
+		}
+
+		actual := rememberIdempotencyKey(processedKeys, "new-key")
+		assert.Len(t, actual, maxProcessedIdempotencyKeys)
+		assert.Equal(t, "key-1", actual[0])
+		assert.Equal(t, "new-key", actual[maxProcessedIdempotencyKeys-1])
+	})
+}
@@ -0,0 +1,35 @@
+package events
+
+import (
+	"context"
+
+	"github.com/tochemey/gopack/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// standingOrderCreated handles the standing order created event and returns the resulting state
+func standingOrderCreated(ctx context.Context, event *pb.StandingOrderCreated) (*pb.StandingOrder, error) {
+	// add a span context to trace the event handler
+	ctx, span := trace.SpanContext(ctx, "HandleStandingOrderCreated")
+	defer span.End()
+
+	// let us make a copy of the event
+	eventCopy := proto.Clone(event).(*pb.StandingOrderCreated)
+
+	// return the resulting state
+	return &pb.StandingOrder{
+		OrderId:              eventCopy.GetOrderId(),
+		AccountId:            eventCopy.GetAccountId(),
+		BeneficiaryAccountId: eventCopy.GetBeneficiaryAccountId(),
+		Amount:               eventCopy.GetAmount(),
+		Frequency:            eventCopy.GetFrequency(),
+		Status:               pb.StandingOrderStatus_ACTIVE,
+		NextExecutionDate:    eventCopy.GetStartDate(),
+		StartDate:            eventCopy.GetStartDate(),
+		EndDate:              eventCopy.GetEndDate(),
+		Reference:            eventCopy.GetReference(),
+		TenantId:             eventCopy.GetTenantId(),
+	}, nil
+}
@@ -0,0 +1,50 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestStandingOrderCreated(t *testing.T) {
+	ctx := context.TODO()
+	startDate := timestamppb.New(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+	// create the event
+	event := &pb.StandingOrderCreated{
+		OrderId:              "order-1",
+		AccountId:            "account-1",
+		BeneficiaryAccountId: "account-2",
+		Amount:               50,
+		Frequency:            pb.Frequency_WEEKLY,
+		StartDate:            startDate,
+		Reference:            "savings",
+		TenantId:             "acme",
+	}
+
+	expected := &pb.StandingOrder{
+		OrderId:              "order-1",
+		AccountId:            "account-1",
+		BeneficiaryAccountId: "account-2",
+		Amount:               50,
+		Frequency:            pb.Frequency_WEEKLY,
+		Status:               pb.StandingOrderStatus_ACTIVE,
+		NextExecutionDate:    startDate,
+		StartDate:            startDate,
+		Reference:            "savings",
+		TenantId:             "acme",
+	}
+
+	// handle the event
+	actual, err := standingOrderCreated(ctx, event)
+	require.NoError(t, err)
+	require.NotNil(t, actual)
+	assert.True(t, proto.Equal(expected, actual))
+}
@@ -0,0 +1,62 @@
+package events
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
+)
+
+// StandingOrderDispatcher dispatches the standing order events
+type StandingOrderDispatcher interface {
+	// Dispatch dispatches the given event and return the resulting standing order or an error
+	Dispatch(ctx context.Context, event proto.Message, priorState *pb.StandingOrder, eventMeta *cospb.MetaData) (newState *pb.StandingOrder, err error)
+}
+
+type standingOrderDispatcher struct{}
+
+var _ StandingOrderDispatcher = (*standingOrderDispatcher)(nil)
+
+// NewStandingOrderDispatcher create an instance of StandingOrderDispatcher
+func NewStandingOrderDispatcher() StandingOrderDispatcher {
+	return &standingOrderDispatcher{}
+}
+
+// Dispatch dispatches the given event and return the resulting standing order or an error
+func (h standingOrderDispatcher) Dispatch(ctx context.Context, event proto.Message, priorState *pb.StandingOrder, eventMeta *cospb.MetaData) (newState *pb.StandingOrder, err error) { //nolint
+	switch typedEvent := event.(type) {
+	case *pb.StandingOrderCreated:
+		return standingOrderCreated(ctx, typedEvent)
+	case *pb.StandingOrderPaused:
+		return standingOrderStatusChanged(ctx, priorState, pb.StandingOrderStatus_PAUSED)
+	case *pb.StandingOrderResumed:
+		return standingOrderStatusChanged(ctx, priorState, pb.StandingOrderStatus_ACTIVE)
+	case *pb.StandingOrderCancelled:
+		return standingOrderStatusChanged(ctx, priorState, pb.StandingOrderStatus_CANCELLED)
+	case *pb.StandingOrderExecuted:
+		return standingOrderExecuted(ctx, typedEvent, priorState)
+	case *pb.StandingOrderExecutionFailed:
+		return standingOrderExecutionFailed(ctx, typedEvent, priorState)
+	case nil:
+		return nil, errEventNotDefined
+	default:
+		return nil, errUnhandledEvent(typedEvent)
+	}
+}
+
+// IsStandingOrderEvent checks whether the given event belongs to a standing order
+func IsStandingOrderEvent(event proto.Message) bool {
+	switch event.(type) {
+	case *pb.StandingOrderCreated,
+		*pb.StandingOrderPaused,
+		*pb.StandingOrderResumed,
+		*pb.StandingOrderCancelled,
+		*pb.StandingOrderExecuted,
+		*pb.StandingOrderExecutionFailed:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,51 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestStandingOrderDispatcher(t *testing.T) {
+	priorState := &pb.StandingOrder{OrderId: "order-1", Status: pb.StandingOrderStatus_ACTIVE}
+
+	t.Run("With nil event", func(t *testing.T) {
+		dispatcher := NewStandingOrderDispatcher()
+		state, err := dispatcher.Dispatch(context.TODO(), nil, priorState, nil)
+		assert.EqualError(t, err, errEventNotDefined.Error())
+		assert.Nil(t, state)
+	})
+	t.Run("With unknown event", func(t *testing.T) {
+		dispatcher := NewStandingOrderDispatcher()
+		state, err := dispatcher.Dispatch(context.TODO(), wrapperspb.String("unknown"), priorState, nil)
+		assert.Error(t, err)
+		assert.Nil(t, state)
+	})
+	t.Run("With status change events", func(t *testing.T) {
+		dispatcher := NewStandingOrderDispatcher()
+
+		state, err := dispatcher.Dispatch(context.TODO(), &pb.StandingOrderPaused{OrderId: "order-1"}, priorState, nil)
+		require.NoError(t, err)
+		assert.Equal(t, pb.StandingOrderStatus_PAUSED, state.GetStatus())
+
+		state, err = dispatcher.Dispatch(context.TODO(), &pb.StandingOrderResumed{OrderId: "order-1"}, state, nil)
+		require.NoError(t, err)
+		assert.Equal(t, pb.StandingOrderStatus_ACTIVE, state.GetStatus())
+
+		state, err = dispatcher.Dispatch(context.TODO(), &pb.StandingOrderCancelled{OrderId: "order-1"}, state, nil)
+		require.NoError(t, err)
+		assert.Equal(t, pb.StandingOrderStatus_CANCELLED, state.GetStatus())
+	})
+}
+
+func TestIsStandingOrderEvent(t *testing.T) {
+	assert.True(t, IsStandingOrderEvent(new(pb.StandingOrderCreated)))
+	assert.True(t, IsStandingOrderEvent(new(pb.StandingOrderExecutionFailed)))
+	assert.False(t, IsStandingOrderEvent(new(pb.AccountDebited)))
+	assert.False(t, IsStandingOrderEvent(nil))
+}
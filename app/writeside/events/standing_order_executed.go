@@ -0,0 +1,34 @@
+package events
+
+import (
+	"context"
+
+	"github.com/tochemey/gopack/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// standingOrderExecuted handles the standing order executed event and returns the resulting state
+func standingOrderExecuted(ctx context.Context, event *pb.StandingOrderExecuted, priorState *pb.StandingOrder) (*pb.StandingOrder, error) {
+	// add a span context to trace the event handler
+	ctx, span := trace.SpanContext(ctx, "HandleStandingOrderExecuted")
+	defer span.End()
+
+	eventCopy := proto.Clone(event).(*pb.StandingOrderExecuted)
+	stateCopy := proto.Clone(priorState).(*pb.StandingOrder)
+
+	stateCopy.LastExecutionDate = eventCopy.GetExecutionDate()
+	stateCopy.FailedAttempts = 0
+	stateCopy.LastFailureReason = ""
+	stateCopy.NextAttemptAt = nil
+	stateCopy.Debited = false
+	stateCopy.NextExecutionDate = eventCopy.GetNextExecutionDate()
+
+	// there is no execution left
+	if stateCopy.GetNextExecutionDate() == nil {
+		stateCopy.Status = pb.StandingOrderStatus_COMPLETED
+	}
+
+	return stateCopy, nil
+}
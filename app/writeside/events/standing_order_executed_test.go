@@ -0,0 +1,65 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestStandingOrderExecuted(t *testing.T) {
+	executionDate := timestamppb.New(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+	nextExecutionDate := timestamppb.New(time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC))
+
+	// create the prior state
+	priorState := &pb.StandingOrder{
+		OrderId:           "order-1",
+		Frequency:         pb.Frequency_DAILY,
+		Status:            pb.StandingOrderStatus_ACTIVE,
+		NextExecutionDate: executionDate,
+		FailedAttempts:    1,
+		LastFailureReason: "insufficient balance",
+		NextAttemptAt:     timestamppb.New(executionDate.AsTime().Add(time.Hour)),
+	}
+
+	t.Run("With next execution", func(t *testing.T) {
+		ctx := context.TODO()
+		event := &pb.StandingOrderExecuted{
+			OrderId:           "order-1",
+			ExecutionDate:     executionDate,
+			NextExecutionDate: nextExecutionDate,
+		}
+
+		expected := &pb.StandingOrder{
+			OrderId:           "order-1",
+			Frequency:         pb.Frequency_DAILY,
+			Status:            pb.StandingOrderStatus_ACTIVE,
+			NextExecutionDate: nextExecutionDate,
+			LastExecutionDate: executionDate,
+		}
+
+		// handle the event
+		actual, err := standingOrderExecuted(ctx, event, priorState)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(expected, actual))
+	})
+	t.Run("With last execution", func(t *testing.T) {
+		ctx := context.TODO()
+		event := &pb.StandingOrderExecuted{
+			OrderId:       "order-1",
+			ExecutionDate: executionDate,
+		}
+
+		// handle the event
+		actual, err := standingOrderExecuted(ctx, event, priorState)
+		require.NoError(t, err)
+		assert.Equal(t, pb.StandingOrderStatus_COMPLETED, actual.GetStatus())
+		assert.Nil(t, actual.GetNextExecutionDate())
+	})
+}
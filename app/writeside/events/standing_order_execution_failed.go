@@ -0,0 +1,43 @@
+package events
+
+import (
+	"context"
+
+	"github.com/tochemey/gopack/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// standingOrderExecutionFailed handles the standing order execution failed event and returns the resulting state
+func standingOrderExecutionFailed(ctx context.Context, event *pb.StandingOrderExecutionFailed, priorState *pb.StandingOrder) (*pb.StandingOrder, error) {
+	// add a span context to trace the event handler
+	ctx, span := trace.SpanContext(ctx, "HandleStandingOrderExecutionFailed")
+	defer span.End()
+
+	eventCopy := proto.Clone(event).(*pb.StandingOrderExecutionFailed)
+	stateCopy := proto.Clone(priorState).(*pb.StandingOrder)
+
+	stateCopy.LastFailureReason = eventCopy.GetFailureReason()
+
+	// the execution is retried
+	if !eventCopy.GetSkipped() {
+		stateCopy.FailedAttempts = stateCopy.GetFailedAttempts() + 1
+		stateCopy.NextAttemptAt = eventCopy.GetNextAttemptAt()
+		stateCopy.Debited = eventCopy.GetDebited()
+		return stateCopy, nil
+	}
+
+	// the execution is skipped, hence the standing order moves to the next execution
+	stateCopy.FailedAttempts = 0
+	stateCopy.NextAttemptAt = nil
+	stateCopy.Debited = false
+	stateCopy.NextExecutionDate = eventCopy.GetNextExecutionDate()
+
+	// there is no execution left
+	if stateCopy.GetNextExecutionDate() == nil {
+		stateCopy.Status = pb.StandingOrderStatus_COMPLETED
+	}
+
+	return stateCopy, nil
+}
@@ -0,0 +1,124 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestStandingOrderExecutionFailed(t *testing.T) {
+	executionDate := timestamppb.New(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC))
+	nextAttemptAt := timestamppb.New(executionDate.AsTime().Add(time.Hour))
+	nextExecutionDate := timestamppb.New(time.Date(2024, time.January, 2, 0, 0, 0, 0, time.UTC))
+
+	// create the prior state
+	priorState := &pb.StandingOrder{
+		OrderId:           "order-1",
+		Frequency:         pb.Frequency_DAILY,
+		Status:            pb.StandingOrderStatus_ACTIVE,
+		NextExecutionDate: executionDate,
+		FailedAttempts:    1,
+	}
+
+	t.Run("With execution retried", func(t *testing.T) {
+		ctx := context.TODO()
+		event := &pb.StandingOrderExecutionFailed{
+			OrderId:       "order-1",
+			ExecutionDate: executionDate,
+			FailureReason: "insufficient balance",
+			NextAttemptAt: nextAttemptAt,
+		}
+
+		expected := &pb.StandingOrder{
+			OrderId:           "order-1",
+			Frequency:         pb.Frequency_DAILY,
+			Status:            pb.StandingOrderStatus_ACTIVE,
+			NextExecutionDate: executionDate,
+			FailedAttempts:    2,
+			LastFailureReason: "insufficient balance",
+			NextAttemptAt:     nextAttemptAt,
+		}
+
+		// handle the event
+		actual, err := standingOrderExecutionFailed(ctx, event, priorState)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(expected, actual))
+	})
+	t.Run("With execution retried after the debit", func(t *testing.T) {
+		ctx := context.TODO()
+		event := &pb.StandingOrderExecutionFailed{
+			OrderId:       "order-1",
+			ExecutionDate: executionDate,
+			FailureReason: "the account is closed",
+			NextAttemptAt: nextAttemptAt,
+			Debited:       true,
+		}
+
+		// handle the event
+		actual, err := standingOrderExecutionFailed(ctx, event, priorState)
+		require.NoError(t, err)
+		assert.True(t, actual.GetDebited())
+	})
+	t.Run("With debited execution skipped", func(t *testing.T) {
+		ctx := context.TODO()
+		debitedState := proto.Clone(priorState).(*pb.StandingOrder)
+		debitedState.Debited = true
+		event := &pb.StandingOrderExecutionFailed{
+			OrderId:           "order-1",
+			ExecutionDate:     executionDate,
+			FailureReason:     "the account is closed",
+			NextExecutionDate: nextExecutionDate,
+			Skipped:           true,
+		}
+
+		// the next execution starts without debit
+		actual, err := standingOrderExecutionFailed(ctx, event, debitedState)
+		require.NoError(t, err)
+		assert.False(t, actual.GetDebited())
+		assert.True(t, proto.Equal(nextExecutionDate, actual.GetNextExecutionDate()))
+	})
+	t.Run("With execution skipped", func(t *testing.T) {
+		ctx := context.TODO()
+		event := &pb.StandingOrderExecutionFailed{
+			OrderId:           "order-1",
+			ExecutionDate:     executionDate,
+			FailureReason:     "insufficient balance",
+			NextExecutionDate: nextExecutionDate,
+			Skipped:           true,
+		}
+
+		expected := &pb.StandingOrder{
+			OrderId:           "order-1",
+			Frequency:         pb.Frequency_DAILY,
+			Status:            pb.StandingOrderStatus_ACTIVE,
+			NextExecutionDate: nextExecutionDate,
+			LastFailureReason: "insufficient balance",
+		}
+
+		// handle the event
+		actual, err := standingOrderExecutionFailed(ctx, event, priorState)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(expected, actual))
+	})
+	t.Run("With last execution skipped", func(t *testing.T) {
+		ctx := context.TODO()
+		event := &pb.StandingOrderExecutionFailed{
+			OrderId:       "order-1",
+			ExecutionDate: executionDate,
+			FailureReason: "insufficient balance",
+			Skipped:       true,
+		}
+
+		// handle the event
+		actual, err := standingOrderExecutionFailed(ctx, event, priorState)
+		require.NoError(t, err)
+		assert.Equal(t, pb.StandingOrderStatus_COMPLETED, actual.GetStatus())
+	})
+}
@@ -0,0 +1,22 @@
+package events
+
+import (
+	"context"
+
+	"github.com/tochemey/gopack/otel/trace"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+// standingOrderStatusChanged handles the standing order paused, resumed and cancelled events and returns the resulting state
+func standingOrderStatusChanged(ctx context.Context, priorState *pb.StandingOrder, status pb.StandingOrderStatus) (*pb.StandingOrder, error) {
+	// add a span context to trace the event handler
+	ctx, span := trace.SpanContext(ctx, "HandleStandingOrderStatusChanged")
+	defer span.End()
+
+	stateCopy := proto.Clone(priorState).(*pb.StandingOrder)
+	stateCopy.Status = status
+
+	return stateCopy, nil
+}
@@ -0,0 +1,37 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestStandingOrderStatusChanged(t *testing.T) {
+	ctx := context.TODO()
+
+	// create the prior state
+	priorState := &pb.StandingOrder{
+		OrderId: "order-1",
+		Amount:  50,
+		Status:  pb.StandingOrderStatus_ACTIVE,
+	}
+
+	expected := &pb.StandingOrder{
+		OrderId: "order-1",
+		Amount:  50,
+		Status:  pb.StandingOrderStatus_PAUSED,
+	}
+
+	// handle the event
+	actual, err := standingOrderStatusChanged(ctx, priorState, pb.StandingOrderStatus_PAUSED)
+	require.NoError(t, err)
+	require.NotNil(t, actual)
+	assert.True(t, proto.Equal(expected, actual))
+	// the prior state is left untouched
+	assert.Equal(t, pb.StandingOrderStatus_ACTIVE, priorState.GetStatus())
+}
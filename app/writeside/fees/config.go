@@ -0,0 +1,37 @@
+package fees
+
+import (
+	"github.com/caarlos0/env/v9"
+	"github.com/pkg/errors"
+)
+
+// Config holds the fee schedule configuration
+type Config struct {
+	FeeSchedulePath string `env:"FEE_SCHEDULE_PATH" envDefault:""` // FeeSchedulePath is the path to the JSON fee schedule. No fee is charged when not set
+}
+
+// LoadSchedule reads the fee schedule from the file set in the environment variables.
+// We panic here because this call is usually and must be done during application start
+func LoadSchedule() *Schedule {
+	config := &Config{}
+	// all env vars are required
+	opts := env.Options{RequiredIfNoDef: true}
+
+	// parse the environment variables and panic in case of error
+	if err := env.ParseWithOptions(config, opts); err != nil {
+		panic(errors.Wrap(err, "unable to load environment variables"))
+	}
+
+	// no fee schedule is configured
+	if config.FeeSchedulePath == "" {
+		return NewSchedule()
+	}
+
+	// read the fee schedule file
+	schedule, err := ReadSchedule(config.FeeSchedulePath)
+	if err != nil {
+		panic(errors.Wrap(err, "unable to load the fee schedule"))
+	}
+
+	return schedule
+}
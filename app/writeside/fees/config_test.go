@@ -0,0 +1,38 @@
+package fees
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSchedule(t *testing.T) {
+	t.Run("With no fee schedule path set", func(t *testing.T) {
+		schedule := LoadSchedule()
+		require.NotNil(t, schedule)
+		assert.Empty(t, schedule.Rules)
+	})
+	t.Run("With fee schedule path set", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "fees.json")
+		content := `{"rules":[{"id":"debit-flat","command_type":"accounts.v1.DebitAccount","kind":"FLAT","value":1.5}]}`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		assert.NoError(t, os.Setenv("FEE_SCHEDULE_PATH", path))
+
+		schedule := LoadSchedule()
+		require.NotNil(t, schedule)
+		assert.Len(t, schedule.Rules, 1)
+		// free resources
+		assert.NoError(t, os.Unsetenv("FEE_SCHEDULE_PATH"))
+	})
+	t.Run("With invalid fee schedule file", func(t *testing.T) {
+		assert.NoError(t, os.Setenv("FEE_SCHEDULE_PATH", filepath.Join(t.TempDir(), "fees.json")))
+		assert.Panics(t, func() {
+			LoadSchedule()
+		})
+		// free resources
+		assert.NoError(t, os.Unsetenv("FEE_SCHEDULE_PATH"))
+	})
+}
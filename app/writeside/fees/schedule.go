@@ -0,0 +1,140 @@
+package fees
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+)
+
+// Kind defines how a fee amount is computed
+type Kind string
+
+const (
+	// FlatKind charges a fixed amount per command
+	FlatKind Kind = "FLAT"
+	// PercentageKind charges a percentage of the command amount
+	PercentageKind Kind = "PERCENTAGE"
+)
+
+// Rule defines a single entry of the fee schedule
+type Rule struct {
+	ID          string  `json:"id"`           // ID identifies the rule and is recorded on every fee it charges
+	AccountType string  `json:"account_type"` // AccountType is the account type the rule applies to. e.g. SAVINGS. An empty value applies to all account types
+	CommandType string  `json:"command_type"` // CommandType is the command full name the rule applies to. e.g. accounts.v1.DebitAccount
+	Kind        Kind    `json:"kind"`         // Kind defines whether the fee is flat or a percentage of the command amount
+	Value       float64 `json:"value"`        // Value is the flat amount or the percentage rate. e.g. 1.5 for 1.5%
+	MinFee      float64 `json:"min_fee"`      // MinFee is the lower cap of the computed fee
+	MaxFee      float64 `json:"max_fee"`      // MaxFee is the upper cap of the computed fee. Zero means no upper cap
+}
+
+// validate checks whether the rule is well-defined
+func (r *Rule) validate() error {
+	switch {
+	case r.ID == "":
+		return errors.New("the fee rule id is not set")
+	case r.CommandType == "":
+		return errors.Errorf("the fee rule (%s) command type is not set", r.ID)
+	case r.Kind != FlatKind && r.Kind != PercentageKind:
+		return errors.Errorf("the fee rule (%s) kind (%s) is not supported", r.ID, r.Kind)
+	case r.Value < 0 || r.MinFee < 0 || r.MaxFee < 0:
+		return errors.Errorf("the fee rule (%s) amounts cannot be negative", r.ID)
+	case r.MaxFee > 0 && r.MaxFee < r.MinFee:
+		return errors.Errorf("the fee rule (%s) max fee is lower than its min fee", r.ID)
+	default:
+		return nil
+	}
+}
+
+// compute returns the fee charged by the rule for the given amount
+func (r *Rule) compute(amount float64) float64 {
+	fee := r.Value
+	if r.Kind == PercentageKind {
+		fee = amount * r.Value / 100
+	}
+
+	// apply the caps
+	fee = math.Max(fee, r.MinFee)
+	if r.MaxFee > 0 {
+		fee = math.Min(fee, r.MaxFee)
+	}
+
+	// fees are charged in cents
+	return math.Round(fee*100) / 100
+}
+
+// Schedule holds the fee rules evaluated by the write side
+type Schedule struct {
+	Rules []*Rule `json:"rules"`
+}
+
+// NewSchedule creates an instance of Schedule with the given rules
+func NewSchedule(rules ...*Rule) *Schedule {
+	return &Schedule{Rules: rules}
+}
+
+// ReadSchedule reads and validates a JSON fee schedule file
+func ReadSchedule(path string) (*Schedule, error) {
+	// read the file content
+	bytea, err := os.ReadFile(path) // #nosec G304
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read the fee schedule file (%s)", path)
+	}
+
+	// parse the schedule
+	schedule := NewSchedule()
+	if err := json.Unmarshal(bytea, schedule); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the fee schedule file (%s)", path)
+	}
+
+	// validate every rule
+	for _, rule := range schedule.Rules {
+		if err := rule.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return schedule, nil
+}
+
+// Evaluate returns the rule matching the given account type and command with the fee it charges on the given amount.
+// A rule defined for the account type takes precedence over a rule defined for all account types.
+// A nil rule is returned when no fee applies.
+func (s *Schedule) Evaluate(accountType string, command proto.Message, amount float64) (*Rule, float64) {
+	// no fee is charged without a schedule
+	if s == nil || command == nil {
+		return nil, 0
+	}
+
+	commandType := string(proto.MessageName(command))
+	var matched *Rule
+	for _, rule := range s.Rules {
+		if rule.CommandType != commandType {
+			continue
+		}
+
+		// an account type specific rule wins
+		if rule.AccountType == accountType {
+			matched = rule
+			break
+		}
+
+		// keep the first rule applying to all account types
+		if rule.AccountType == "" && matched == nil {
+			matched = rule
+		}
+	}
+
+	// no rule applies or the fee is nil
+	if matched == nil {
+		return nil, 0
+	}
+
+	fee := matched.compute(amount)
+	if fee <= 0 {
+		return nil, 0
+	}
+	return matched, fee
+}
@@ -0,0 +1,134 @@
+package fees
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+)
+
+func TestEvaluate(t *testing.T) {
+	t.Run("With flat fee", func(t *testing.T) {
+		schedule := NewSchedule(&Rule{
+			ID:          "debit-flat",
+			CommandType: "accounts.v1.DebitAccount",
+			Kind:        FlatKind,
+			Value:       1.25,
+		})
+
+		rule, fee := schedule.Evaluate("", new(pb.DebitAccount), 500)
+		require.NotNil(t, rule)
+		assert.Equal(t, "debit-flat", rule.ID)
+		assert.Equal(t, 1.25, fee)
+	})
+	t.Run("With percentage fee and caps", func(t *testing.T) {
+		schedule := NewSchedule(&Rule{
+			ID:          "debit-percentage",
+			CommandType: "accounts.v1.DebitAccount",
+			Kind:        PercentageKind,
+			Value:       1.5,
+			MinFee:      0.50,
+			MaxFee:      10,
+		})
+
+		_, fee := schedule.Evaluate("", new(pb.DebitAccount), 100)
+		assert.Equal(t, 1.50, fee)
+		_, fee = schedule.Evaluate("", new(pb.DebitAccount), 10)
+		assert.Equal(t, 0.50, fee)
+		_, fee = schedule.Evaluate("", new(pb.DebitAccount), 10000)
+		assert.Equal(t, 10.00, fee)
+	})
+	t.Run("With account type specific rule", func(t *testing.T) {
+		schedule := NewSchedule(
+			&Rule{
+				ID:          "debit-all",
+				CommandType: "accounts.v1.DebitAccount",
+				Kind:        FlatKind,
+				Value:       1,
+			},
+			&Rule{
+				ID:          "debit-savings",
+				AccountType: "savings",
+				CommandType: "accounts.v1.DebitAccount",
+				Kind:        FlatKind,
+				Value:       2,
+			},
+		)
+
+		rule, fee := schedule.Evaluate("savings", new(pb.DebitAccount), 100)
+		require.NotNil(t, rule)
+		assert.Equal(t, "debit-savings", rule.ID)
+		assert.Equal(t, 2.00, fee)
+
+		rule, fee = schedule.Evaluate("checking", new(pb.DebitAccount), 100)
+		require.NotNil(t, rule)
+		assert.Equal(t, "debit-all", rule.ID)
+		assert.Equal(t, 1.00, fee)
+	})
+	t.Run("With no matching rule", func(t *testing.T) {
+		schedule := NewSchedule(&Rule{
+			ID:          "debit-flat",
+			CommandType: "accounts.v1.DebitAccount",
+			Kind:        FlatKind,
+			Value:       1.25,
+		})
+
+		rule, fee := schedule.Evaluate("", new(pb.CreditAccount), 100)
+		assert.Nil(t, rule)
+		assert.Zero(t, fee)
+	})
+	t.Run("With nil schedule", func(t *testing.T) {
+		var schedule *Schedule
+		rule, fee := schedule.Evaluate("", new(pb.DebitAccount), 100)
+		assert.Nil(t, rule)
+		assert.Zero(t, fee)
+	})
+}
+
+func TestReadSchedule(t *testing.T) {
+	t.Run("With valid schedule file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "fees.json")
+		content := `{"rules":[{"id":"debit-flat","command_type":"accounts.v1.DebitAccount","kind":"FLAT","value":1.5}]}`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		schedule, err := ReadSchedule(path)
+		require.NoError(t, err)
+		require.NotNil(t, schedule)
+		require.Len(t, schedule.Rules, 1)
+		assert.Equal(t, &Rule{
+			ID:          "debit-flat",
+			CommandType: "accounts.v1.DebitAccount",
+			Kind:        FlatKind,
+			Value:       1.5,
+		}, schedule.Rules[0])
+	})
+	t.Run("With invalid rule", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "fees.json")
+		content := `{"rules":[{"id":"debit-flat","command_type":"accounts.v1.DebitAccount","kind":"UNKNOWN","value":1.5}]}`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		schedule, err := ReadSchedule(path)
+		require.Error(t, err)
+		assert.EqualError(t, err, "the fee rule (debit-flat) kind (UNKNOWN) is not supported")
+		assert.Nil(t, schedule)
+	})
+	t.Run("With invalid caps", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "fees.json")
+		content := `{"rules":[{"id":"debit","command_type":"accounts.v1.DebitAccount","kind":"PERCENTAGE","value":1,"min_fee":5,"max_fee":2}]}`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		schedule, err := ReadSchedule(path)
+		require.Error(t, err)
+		assert.EqualError(t, err, "the fee rule (debit) max fee is lower than its min fee")
+		assert.Nil(t, schedule)
+	})
+	t.Run("With missing file", func(t *testing.T) {
+		schedule, err := ReadSchedule(filepath.Join(t.TempDir(), "fees.json"))
+		assert.Error(t, err)
+		assert.Nil(t, schedule)
+	})
+}
@@ -5,31 +5,49 @@ import (
 
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/known/anypb"
 
+	"github.com/tochemey/cos-go-sample/app/caller"
 	"github.com/tochemey/cos-go-sample/app/cos"
 	"github.com/tochemey/cos-go-sample/app/log"
+	"github.com/tochemey/cos-go-sample/app/validation"
 	"github.com/tochemey/cos-go-sample/app/writeside/commands"
 	"github.com/tochemey/cos-go-sample/app/writeside/events"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
 	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
 )
 
 // HandlerService is an implementation of the CoS WriteSide handler interface
 type HandlerService struct {
-	commandsDispatcher commands.Dispatcher
-	eventsDispatcher   events.Dispatcher
+	commandsDispatcher              commands.Dispatcher
+	eventsDispatcher                events.Dispatcher
+	standingOrderCommandsDispatcher commands.StandingOrderDispatcher
+	standingOrderEventsDispatcher   events.StandingOrderDispatcher
+	validator                       *validation.Validator
 }
 
 // enforce compilation error when the HandlerService does not fully implement the WriteSideHandlerServiceServer
 // interface
 var _ cospb.WriteSideHandlerServiceServer = (*HandlerService)(nil)
 
-// NewHandlerService creates a new instance of HandlerService
-func NewHandlerService(commandsDispatcher commands.Dispatcher, eventsDispatcher events.Dispatcher) *HandlerService {
+// NewHandlerService creates a new instance of HandlerService. The commands are validated against their constraints
+// by the given validator, and are not validated when it is nil
+func NewHandlerService(commandsDispatcher commands.Dispatcher,
+	eventsDispatcher events.Dispatcher,
+	standingOrderCommandsDispatcher commands.StandingOrderDispatcher,
+	standingOrderEventsDispatcher events.StandingOrderDispatcher,
+	validator *validation.Validator) *HandlerService {
 	// create the service object and set the commands and events handler
 	return &HandlerService{
-		commandsDispatcher: commandsDispatcher,
-		eventsDispatcher:   eventsDispatcher,
+		commandsDispatcher:              commandsDispatcher,
+		eventsDispatcher:                eventsDispatcher,
+		standingOrderCommandsDispatcher: standingOrderCommandsDispatcher,
+		standingOrderEventsDispatcher:   standingOrderEventsDispatcher,
+		validator:                       validator,
 	}
 }
 
@@ -46,19 +64,20 @@ func (s HandlerService) HandleCommand(ctx context.Context, request *cospb.Handle
 		return nil, err
 	}
 
-	// unpacking the state
-	priorState, err := cos.UnmarshalState(request.GetPriorState())
-	if err != nil {
-		err = errors.Wrapf(err, "failed to unpack state:(%s)", request.GetPriorState().GetTypeUrl())
-		logger.Error(err)
-		return nil, err
+	// reject the invalid commands before they reach their aggregate
+	if s.validator != nil {
+		if err := s.validator.Validate(cmd); err != nil {
+			logger.Warn(errors.Wrapf(err, "invalid command:(%s)", cmd.ProtoReflect().Descriptor().FullName()))
+			return nil, err
+		}
 	}
 
-	event, err := s.commandsDispatcher.Dispatch(ctx, cmd, priorState, request.GetPriorEventMeta())
+	// dispatch the command to the aggregate it targets
+	event, err := s.dispatchCommand(ctx, cmd, request)
 	if err != nil {
-		err = errors.Wrapf(err, "failed to handle command:(%s)", cmd.ProtoReflect().Descriptor().FullName())
-		logger.Error(err)
-		return nil, err
+		logger.Error(errors.Wrapf(err, "failed to handle command:(%s)", cmd.ProtoReflect().Descriptor().FullName()))
+		// return the error as a gRPC status so that its code and details reach the caller through CoS
+		return nil, toStatusError(err, request.GetPriorEventMeta().GetEntityId())
 	}
 
 	// prepare response object
@@ -66,6 +85,9 @@ func (s HandlerService) HandleCommand(ctx context.Context, request *cospb.Handle
 
 	// if there is an event, inject as Any, else leave nil to signal a no-op to COS
 	if event != nil {
+		// stamp the identity of the caller propagated by CoS into the event
+		stampCaller(event, caller.FromPropagatedContext(ctx))
+
 		eventAny, err := anypb.New(event)
 		if err != nil {
 			err = errors.Wrapf(err, "failed to pack event:(%s) as any proto message",
@@ -98,17 +120,8 @@ func (s HandlerService) HandleEvent(ctx context.Context, request *cospb.HandleEv
 		return nil, err
 	}
 
-	// unpack the prior state
-	state, err := cos.UnmarshalState(request.GetPriorState())
-	// handle the error
-	if err != nil {
-		err = errors.Wrapf(err, "failed to unpack state:(%s)", request.GetPriorState().GetTypeUrl())
-		logger.Error(err)
-		return nil, err
-	}
-
 	// handle the event
-	resultingState, err := s.eventsDispatcher.Dispatch(ctx, event, state, request.GetEventMeta())
+	resultingState, err := s.dispatchEvent(ctx, event, request)
 	// handle the error
 	if err != nil {
 		err = errors.Wrapf(err, "failed to handle event:(%s)", event.ProtoReflect().Descriptor().FullName())
@@ -133,6 +146,96 @@ func (s HandlerService) HandleEvent(ctx context.Context, request *cospb.HandleEv
 	return &cospb.HandleEventResponse{ResultingState: resultingStateAny}, nil
 }
 
+// dispatchCommand unpacks the prior state of the aggregate targeted by the command and dispatches the command
+func (s HandlerService) dispatchCommand(ctx context.Context, cmd proto.Message, request *cospb.HandleCommandRequest) (proto.Message, error) {
+	// set the logger with the context
+	logger := log.WithContext(ctx)
+
+	// the command targets a standing order
+	if commands.IsStandingOrderCommand(cmd) {
+		// unpacking the state
+		priorState, err := cos.UnmarshalStandingOrder(request.GetPriorState())
+		if err != nil {
+			err = errors.Wrapf(err, "failed to unpack state:(%s)", request.GetPriorState().GetTypeUrl())
+			logger.Error(err)
+			return nil, err
+		}
+
+		return s.standingOrderCommandsDispatcher.Dispatch(ctx, cmd, priorState, request.GetPriorEventMeta())
+	}
+
+	// unpacking the state
+	priorState, err := cos.UnmarshalState(request.GetPriorState())
+	if err != nil {
+		err = errors.Wrapf(err, "failed to unpack state:(%s)", request.GetPriorState().GetTypeUrl())
+		logger.Error(err)
+		return nil, err
+	}
+
+	return s.commandsDispatcher.Dispatch(ctx, cmd, priorState, request.GetPriorEventMeta())
+}
+
+// dispatchEvent unpacks the prior state of the aggregate the event belongs to and dispatches the event
+func (s HandlerService) dispatchEvent(ctx context.Context, event proto.Message, request *cospb.HandleEventRequest) (proto.Message, error) {
+	// set the logger with the context
+	logger := log.WithContext(ctx)
+
+	// the event belongs to a standing order
+	if events.IsStandingOrderEvent(event) {
+		// unpack the prior state
+		priorState, err := cos.UnmarshalStandingOrder(request.GetPriorState())
+		if err != nil {
+			err = errors.Wrapf(err, "failed to unpack state:(%s)", request.GetPriorState().GetTypeUrl())
+			logger.Error(err)
+			return nil, err
+		}
+
+		return s.standingOrderEventsDispatcher.Dispatch(ctx, event, priorState, request.GetEventMeta())
+	}
+
+	// unpack the prior state
+	priorState, err := cos.UnmarshalState(request.GetPriorState())
+	if err != nil {
+		err = errors.Wrapf(err, "failed to unpack state:(%s)", request.GetPriorState().GetTypeUrl())
+		logger.Error(err)
+		return nil, err
+	}
+
+	return s.eventsDispatcher.Dispatch(ctx, event, priorState, request.GetEventMeta())
+}
+
+// callerField is the name of the field of the events holding the caller of the command that led to them
+const callerField = "caller"
+
+// stampCaller sets the given caller into the caller field of the given event. The events without caller field are left unchanged
+func stampCaller(event proto.Message, identity *pb.Caller) {
+	if identity == nil {
+		return
+	}
+
+	message := event.ProtoReflect()
+	field := message.Descriptor().Fields().ByName(callerField)
+	if field == nil || field.Message() == nil || field.Message().FullName() != identity.ProtoReflect().Descriptor().FullName() {
+		return
+	}
+	message.Set(field, protoreflect.ValueOfMessage(identity.ProtoReflect()))
+}
+
+// toStatusError converts the error returned by a command handler into a gRPC status error.
+// The domain errors carry their reason and metadata as details and the other status errors are returned as is
+func toStatusError(err error, entityID string) error {
+	var domainErr *commands.Error
+	if errors.As(err, &domainErr) {
+		return domainErr.WithEntity(entityID).GRPCStatus().Err()
+	}
+
+	if st, ok := status.FromError(err); ok {
+		return st.Err()
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
+
 // RegisterService registers the gRPC api
 func (s HandlerService) RegisterService(sv *grpc.Server) {
 	cospb.RegisterWriteSideHandlerServiceServer(sv, s)
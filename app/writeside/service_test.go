@@ -0,0 +1,78 @@
+package writeside
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/tochemey/cos-go-sample/app/validation"
+	pb "github.com/tochemey/cos-go-sample/gen/accounts/v1"
+	cospb "github.com/tochemey/cos-go-sample/gen/chief_of_state/v1"
+	mocks "github.com/tochemey/cos-go-sample/mocks/app/writeside/commands"
+)
+
+// newHandleCommandRequest creates the request handling a given command of an account
+func newHandleCommandRequest(t *testing.T, command *pb.CreditAccount) *cospb.HandleCommandRequest {
+	commandAny, err := anypb.New(command)
+	require.NoError(t, err)
+	priorState, err := anypb.New(&pb.BankAccount{AccountId: command.GetAccountId(), AccountBalance: 100})
+	require.NoError(t, err)
+	return &cospb.HandleCommandRequest{
+		Command:        commandAny,
+		PriorState:     priorState,
+		PriorEventMeta: &cospb.MetaData{EntityId: command.GetAccountId(), RevisionNumber: 1},
+	}
+}
+
+func TestHandleCommand(t *testing.T) {
+	validator, err := validation.NewValidator()
+	require.NoError(t, err)
+
+	t.Run("With valid command", func(t *testing.T) {
+		command := &pb.CreditAccount{AccountId: "account-1", Amount: 10}
+		commandsDispatcher := new(mocks.Dispatcher)
+		commandsDispatcher.
+			On("Dispatch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(&pb.AccountCredited{AccountId: "account-1", Amount: 10}, nil)
+		service := NewHandlerService(commandsDispatcher, nil, nil, nil, validator)
+
+		actual, err := service.HandleCommand(context.TODO(), newHandleCommandRequest(t, command))
+		require.NoError(t, err)
+		require.NotNil(t, actual.GetEvent())
+		commandsDispatcher.AssertExpectations(t)
+	})
+	t.Run("With invalid command", func(t *testing.T) {
+		command := &pb.CreditAccount{AccountId: "account-1", Amount: -10}
+		commandsDispatcher := new(mocks.Dispatcher)
+		service := NewHandlerService(commandsDispatcher, nil, nil, nil, validator)
+
+		actual, err := service.HandleCommand(context.TODO(), newHandleCommandRequest(t, command))
+		require.Error(t, err)
+		assert.Nil(t, actual)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		details := status.Convert(err).Details()
+		require.Len(t, details, 1)
+		assert.Equal(t, "amount", details[0].(*errdetails.BadRequest).GetFieldViolations()[0].GetField())
+		// the command does not reach its aggregate
+		commandsDispatcher.AssertNotCalled(t, "Dispatch", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+	t.Run("Without validator", func(t *testing.T) {
+		command := &pb.CreditAccount{AccountId: "account-1", Amount: -10}
+		commandsDispatcher := new(mocks.Dispatcher)
+		commandsDispatcher.
+			On("Dispatch", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(&pb.AccountCredited{AccountId: "account-1", Amount: -10}, nil)
+		service := NewHandlerService(commandsDispatcher, nil, nil, nil, nil)
+
+		_, err := service.HandleCommand(context.TODO(), newHandleCommandRequest(t, command))
+		require.NoError(t, err)
+		commandsDispatcher.AssertExpectations(t)
+	})
+}
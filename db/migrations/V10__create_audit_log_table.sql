@@ -0,0 +1,12 @@
+-- audit trail relation recording the caller of the commands that led to every event
+CREATE TABLE sample.audit_log(
+    entity_id VARCHAR(255) NOT NULL,
+    revision_number INTEGER NOT NULL,
+    revision_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    event_type VARCHAR(255) NOT NULL,
+    caller_subject VARCHAR(255) NOT NULL DEFAULT '',
+    client_ip VARCHAR(64) NOT NULL DEFAULT '',
+    request_id VARCHAR(255) NOT NULL DEFAULT '',
+
+    PRIMARY KEY (entity_id, revision_number)
+);
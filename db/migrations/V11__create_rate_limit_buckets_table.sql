@@ -0,0 +1,6 @@
+-- token buckets of the rate limits shared by the api instances
+CREATE TABLE sample.rate_limit_buckets(
+    bucket_key VARCHAR(255) NOT NULL PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,6 @@
+-- accounts and standing orders opened before the multi-tenancy belong to the default tenant
+ALTER TABLE sample.accounts ADD COLUMN tenant_id VARCHAR(63) NOT NULL DEFAULT '';
+ALTER TABLE sample.standing_orders ADD COLUMN tenant_id VARCHAR(63) NOT NULL DEFAULT '';
+-- the accounts of an owner are looked up within its tenant
+DROP INDEX sample.accounts_account_owner_idx;
+CREATE INDEX accounts_account_owner_idx ON sample.accounts(tenant_id, account_owner, account_id);
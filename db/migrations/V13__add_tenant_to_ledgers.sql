@@ -0,0 +1,12 @@
+-- the events, transactions and audit entries are read within the tenant of their account or standing order
+ALTER TABLE sample.account_events ADD COLUMN tenant_id VARCHAR(63) NOT NULL DEFAULT '';
+ALTER TABLE sample.account_transactions ADD COLUMN tenant_id VARCHAR(63) NOT NULL DEFAULT '';
+ALTER TABLE sample.audit_log ADD COLUMN tenant_id VARCHAR(63) NOT NULL DEFAULT '';
+-- the rows recorded before take the tenant of their account or standing order
+UPDATE sample.account_events e SET tenant_id = a.tenant_id FROM sample.accounts a WHERE a.account_id = e.account_id;
+UPDATE sample.account_transactions t SET tenant_id = a.tenant_id FROM sample.accounts a WHERE a.account_id = t.account_id;
+UPDATE sample.audit_log l SET tenant_id = a.tenant_id FROM sample.accounts a WHERE a.account_id = l.entity_id;
+UPDATE sample.audit_log l SET tenant_id = o.tenant_id FROM sample.standing_orders o WHERE o.order_id = l.entity_id;
+-- the statements are generated within the tenant of the account
+DROP INDEX sample.account_transactions_booked_at_idx;
+CREATE INDEX account_transactions_booked_at_idx ON sample.account_transactions(tenant_id, account_id, booked_at);
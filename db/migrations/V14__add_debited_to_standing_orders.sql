@@ -0,0 +1,3 @@
+-- the standing orders being retried remember whether their account has been debited, so that the debit is neither repeated
+-- nor left unpaid when the execution is skipped
+ALTER TABLE sample.standing_orders ADD COLUMN debited BOOLEAN NOT NULL DEFAULT FALSE;
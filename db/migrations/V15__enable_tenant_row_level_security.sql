@@ -0,0 +1,29 @@
+-- the records of the read model are only accessible within the tenant the transaction is scoped to with
+-- set_config('app.tenant_id', <tenant>, true) and set_config('app.tenant_scoped', 'on', true), or within every tenant
+-- with '*' which is not a valid tenant id. The settings of a transaction which is not scoped are either unset or empty,
+-- hence its statements access no record, not even the ones of the default tenant.
+-- The policies are forced on the owner of the tables, the superusers and the BYPASSRLS roles are not subject to them
+ALTER TABLE sample.accounts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE sample.accounts FORCE ROW LEVEL SECURITY;
+CREATE POLICY accounts_tenant_isolation ON sample.accounts
+    USING (current_setting('app.tenant_scoped', true) = 'on' AND current_setting('app.tenant_id', true) IN (tenant_id, '*'));
+
+ALTER TABLE sample.standing_orders ENABLE ROW LEVEL SECURITY;
+ALTER TABLE sample.standing_orders FORCE ROW LEVEL SECURITY;
+CREATE POLICY standing_orders_tenant_isolation ON sample.standing_orders
+    USING (current_setting('app.tenant_scoped', true) = 'on' AND current_setting('app.tenant_id', true) IN (tenant_id, '*'));
+
+ALTER TABLE sample.account_events ENABLE ROW LEVEL SECURITY;
+ALTER TABLE sample.account_events FORCE ROW LEVEL SECURITY;
+CREATE POLICY account_events_tenant_isolation ON sample.account_events
+    USING (current_setting('app.tenant_scoped', true) = 'on' AND current_setting('app.tenant_id', true) IN (tenant_id, '*'));
+
+ALTER TABLE sample.account_transactions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE sample.account_transactions FORCE ROW LEVEL SECURITY;
+CREATE POLICY account_transactions_tenant_isolation ON sample.account_transactions
+    USING (current_setting('app.tenant_scoped', true) = 'on' AND current_setting('app.tenant_id', true) IN (tenant_id, '*'));
+
+ALTER TABLE sample.audit_log ENABLE ROW LEVEL SECURITY;
+ALTER TABLE sample.audit_log FORCE ROW LEVEL SECURITY;
+CREATE POLICY audit_log_tenant_isolation ON sample.audit_log
+    USING (current_setting('app.tenant_scoped', true) = 'on' AND current_setting('app.tenant_id', true) IN (tenant_id, '*'));
@@ -0,0 +1,6 @@
+-- the fees and the withdrawals of the month of the accounts, so that the eventual reads return the whole account.
+-- The accounts recorded before have zero values until their next event or the replay of the read side
+ALTER TABLE sample.accounts ADD COLUMN total_fees_charged NUMERIC(19, 2) NOT NULL DEFAULT 0;
+ALTER TABLE sample.accounts ADD COLUMN total_fees_waived NUMERIC(19, 2) NOT NULL DEFAULT 0;
+ALTER TABLE sample.accounts ADD COLUMN monthly_withdrawals INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sample.accounts ADD COLUMN withdrawals_month VARCHAR(7) NOT NULL DEFAULT '';
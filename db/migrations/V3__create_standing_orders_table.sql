@@ -0,0 +1,21 @@
+-- standing orders relation
+CREATE TABLE sample.standing_orders(
+    order_id VARCHAR(255) NOT NULL,
+    account_id VARCHAR(255) NOT NULL,
+    beneficiary_account_id VARCHAR(255) NOT NULL,
+    amount NUMERIC(19, 2) NOT NULL,
+    frequency VARCHAR(50) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    next_execution_date TIMESTAMP WITH TIME ZONE NULL,
+    end_date TIMESTAMP WITH TIME ZONE NULL,
+    reference VARCHAR(255) NOT NULL,
+    failed_attempts INTEGER NOT NULL,
+    last_failure_reason TEXT NOT NULL,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NULL,
+    last_execution_date TIMESTAMP WITH TIME ZONE NULL,
+
+    PRIMARY KEY (order_id)
+);
+
+-- speeds up the lookup of the due standing orders, which are due at their retry time when retried and at their next execution date otherwise
+CREATE INDEX standing_orders_due_idx ON sample.standing_orders(status, (COALESCE(next_attempt_at, next_execution_date)));
@@ -0,0 +1,16 @@
+-- account transactions relation
+CREATE TABLE sample.account_transactions(
+    account_id VARCHAR(255) NOT NULL,
+    revision_number INTEGER NOT NULL,
+    sequence INTEGER NOT NULL,
+    transaction_type VARCHAR(50) NOT NULL,
+    amount NUMERIC(19, 2) NOT NULL,
+    balance_after NUMERIC(19, 2) NOT NULL,
+    description TEXT NOT NULL,
+    booked_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    PRIMARY KEY (account_id, revision_number, sequence)
+);
+
+-- speeds up the statements generation
+CREATE INDEX account_transactions_booked_at_idx ON sample.account_transactions(account_id, booked_at);
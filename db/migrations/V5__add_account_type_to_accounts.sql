@@ -0,0 +1,2 @@
+-- accounts opened before the account types are checking accounts
+ALTER TABLE sample.accounts ADD COLUMN account_type VARCHAR(50) NOT NULL DEFAULT 'CHECKING';
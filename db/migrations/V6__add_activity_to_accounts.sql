@@ -0,0 +1,9 @@
+-- dormancy tracking
+ALTER TABLE sample.accounts ADD COLUMN is_dormant BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE sample.accounts ADD COLUMN last_activity_at TIMESTAMP WITH TIME ZONE;
+
+-- the inactivity of the existing accounts is counted from the migration
+UPDATE sample.accounts SET last_activity_at = NOW();
+
+-- speeds up the dormancy sweep
+CREATE INDEX accounts_last_activity_at_idx ON sample.accounts(last_activity_at, account_id) WHERE is_dormant = FALSE AND is_closed = FALSE;
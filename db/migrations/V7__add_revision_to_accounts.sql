@@ -0,0 +1,3 @@
+-- the CoS revision the account records are built from
+ALTER TABLE sample.accounts ADD COLUMN revision_number INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sample.accounts ADD COLUMN revision_date TIMESTAMP WITH TIME ZONE;
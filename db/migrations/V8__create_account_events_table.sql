@@ -0,0 +1,10 @@
+-- account events ledger relation
+CREATE TABLE sample.account_events(
+    account_id VARCHAR(255) NOT NULL,
+    revision_number INTEGER NOT NULL,
+    revision_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    event_type VARCHAR(255) NOT NULL,
+    event_data BYTEA NOT NULL,
+
+    PRIMARY KEY (account_id, revision_number)
+);
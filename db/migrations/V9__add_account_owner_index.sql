@@ -0,0 +1,2 @@
+-- speeds up the lookup of the accounts of an owner
+CREATE INDEX accounts_account_owner_idx ON sample.accounts(account_owner, account_id);